@@ -16,11 +16,13 @@ package utils
 
 import (
 	"flag"
-	"io/ioutil"
 	"maps"
+	"os"
 	"regexp"
 	"strings"
 
+	"test/golden"
+
 	"github.com/gruntwork-io/terratest/modules/helm"
 	"github.com/gruntwork-io/terratest/modules/k8s"
 	"github.com/gruntwork-io/terratest/modules/logger"
@@ -29,6 +31,11 @@ import (
 
 var update = flag.Bool("update-golden", false, "update golden test output files")
 
+// TemplateGoldenTest renders Templates and compares the result with
+// golden/<GoldenFileName>.golden.yaml object by object (see golden.CompareManifests).
+// IgnoredFields are field-path patterns left out of the golden file and the
+// comparison; IgnoredLines are legacy regexes removed from the rendered text
+// before either.
 type TemplateGoldenTest struct {
 	suite.Suite
 	ChartPath      string
@@ -37,6 +44,7 @@ type TemplateGoldenTest struct {
 	GoldenFileName string
 	Templates      []string
 	IgnoredLines   []string
+	IgnoredFields  []string
 	SetValues      map[string]string
 	ExtraHelmArgs  []string
 }
@@ -64,9 +72,9 @@ func goldenValues(setValues map[string]string) map[string]string {
 	return values
 }
 
-func goldenIgnoredLines(ignoredLines []string) []string {
-	lines := append([]string(nil), ignoredLines...)
-	return append(lines, `\s+helm.sh/chart:\s+.*`)
+func goldenIgnoredFields(ignoredFields []string) []string {
+	fields := append([]string(nil), ignoredFields...)
+	return append(fields, golden.DefaultIgnoredFields...)
 }
 
 func (s *TemplateGoldenTest) TestContainerGoldenTestDefaults() {
@@ -79,30 +87,39 @@ func (s *TemplateGoldenTest) TestContainerGoldenTestDefaults() {
 	output := helm.RenderTemplate(s.T(), options, s.ChartPath, s.Release, s.Templates, s.ExtraHelmArgs...)
 
 	bytes := []byte(output)
-	for _, ignoredLine := range goldenIgnoredLines(s.IgnoredLines) {
+	for _, ignoredLine := range s.IgnoredLines {
 		regex := regexp.MustCompile(ignoredLine)
 		bytes = regex.ReplaceAll(bytes, []byte(""))
 	}
-
-	// Normalize trailing whitespace to ensure exactly one trailing newline.
-	// Different Helm versions may produce varying trailing whitespace which
-	// causes yamllint failures (empty-lines max-end: 0).
-	for len(bytes) > 0 && bytes[len(bytes)-1] == '\n' {
-		bytes = bytes[:len(bytes)-1]
-	}
-	bytes = append(bytes, '\n')
-	output = string(bytes)
+	ignoredFields := goldenIgnoredFields(s.IgnoredFields)
 
 	goldenFile := "golden/" + s.GoldenFileName + ".golden.yaml"
 
 	if *update {
-		err := ioutil.WriteFile(goldenFile, bytes, 0644)
+		stripped, err := golden.StripIgnoredFields(string(bytes), ignoredFields)
+		s.Require().NoError(err, "Rendered output is not valid YAML")
+		err = os.WriteFile(goldenFile, normalizeTrailingNewlines([]byte(stripped)), 0644)
 		s.Require().NoError(err, "Golden file was not writable")
 	}
 
-	expected, err := ioutil.ReadFile(goldenFile)
+	expected, err := os.ReadFile(goldenFile)
 
 	// then
 	s.Require().NoError(err, "Golden file doesn't exist or was not readable")
-	s.Require().Equal(string(expected), output)
+	diffs, err := golden.CompareManifests(string(expected), string(bytes), ignoredFields)
+	s.Require().NoError(err)
+	if len(diffs) > 0 {
+		s.Failf("rendered output differs from "+goldenFile,
+			"%d difference(s) (run `make go.update-golden-only` to accept):\n%s", len(diffs), strings.Join(diffs, "\n"))
+	}
+}
+
+// normalizeTrailingNewlines ensures exactly one trailing newline. Different
+// Helm versions may produce varying trailing whitespace which causes yamllint
+// failures (empty-lines max-end: 0).
+func normalizeTrailingNewlines(bytes []byte) []byte {
+	for len(bytes) > 0 && bytes[len(bytes)-1] == '\n' {
+		bytes = bytes[:len(bytes)-1]
+	}
+	return append(bytes, '\n')
 }
@@ -89,16 +89,16 @@ func TestGoldenValuesDefaultsStorageWhenSelectorsAreDisabled(t *testing.T) {
 	require.Equal(t, "elasticsearch", values["orchestration.data.secondaryStorage.type"])
 }
 
-func TestGoldenIgnoredLinesClonesInput(t *testing.T) {
+func TestGoldenIgnoredFieldsClonesInput(t *testing.T) {
 	t.Parallel()
 
 	input := make([]string, 1, 4)
 	input[0] = "caller-pattern"
-	ignoredLines := goldenIgnoredLines(input)
+	ignoredFields := goldenIgnoredFields(input)
 
 	require.Equal(t, []string{"caller-pattern"}, input)
-	require.Equal(t, []string{"caller-pattern", `\s+helm.sh/chart:\s+.*`}, ignoredLines)
+	require.Equal(t, []string{"caller-pattern", `**.labels["helm.sh/chart"]`}, ignoredFields)
 
-	ignoredLines[0] = "changed"
+	ignoredFields[0] = "changed"
 	require.Equal(t, "caller-pattern", input[0])
 }
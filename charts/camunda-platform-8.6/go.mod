@@ -10,6 +10,7 @@ require (
 	github.com/stretchr/testify v1.11.1
 	gopkg.in/yaml.v3 v3.0.1
 	k8s.io/api v0.35.0
+	test/golden v0.0.0
 )
 
 require k8s.io/apimachinery v0.35.0 // indirect
@@ -126,3 +127,5 @@ require (
 	sigs.k8s.io/structured-merge-diff/v6 v6.3.0 // indirect
 	sigs.k8s.io/yaml v1.6.0 // indirect
 )
+
+replace test/golden => ../../test/golden
@@ -19,13 +19,21 @@ import (
 	"flag"
 	"os"
 	"regexp"
+	"strings"
 	"testing"
 
+	"test/golden"
+
 	"github.com/stretchr/testify/suite"
 )
 
 var update = flag.Bool("update-golden", false, "update golden test output files")
 
+// TemplateGoldenTest renders Templates and compares the result with
+// golden/<GoldenFileName>.golden.yaml object by object (see golden.CompareManifests).
+// IgnoredFields are field-path patterns left out of the golden file and the
+// comparison; IgnoredLines are legacy regexes removed from the rendered text
+// before either.
 type TemplateGoldenTest struct {
 	suite.Suite
 	ChartPath      string
@@ -34,10 +42,16 @@ type TemplateGoldenTest struct {
 	GoldenFileName string
 	Templates      []string
 	IgnoredLines   []string
+	IgnoredFields  []string
 	SetValues      map[string]string
 	ExtraHelmArgs  []string
 }
 
+func goldenIgnoredFields(ignoredFields []string) []string {
+	fields := append([]string(nil), ignoredFields...)
+	return append(fields, golden.DefaultIgnoredFields...)
+}
+
 func (s *TemplateGoldenTest) TestDifferentValuesInputs() {
 	testCases := []testhelpers.TestCase{
 		{
@@ -46,18 +60,19 @@ func (s *TemplateGoldenTest) TestDifferentValuesInputs() {
 			RenderTemplateExtraArgs: s.ExtraHelmArgs,
 			Verifier: func(t *testing.T, output string, err error) {
 				s.Require().NoError(err, "Failed to render")
-				s.IgnoredLines = append(s.IgnoredLines, `\s+helm.sh/chart:\s+.*`)
 				bytes := []byte(output)
 				for _, ignoredLine := range s.IgnoredLines {
 					regex := regexp.MustCompile(ignoredLine)
 					bytes = regex.ReplaceAll(bytes, []byte(""))
 				}
-				output = string(bytes)
+				ignoredFields := goldenIgnoredFields(s.IgnoredFields)
 
 				goldenFile := "golden/" + s.GoldenFileName + ".golden.yaml"
 
 				if *update {
-					err := os.WriteFile(goldenFile, bytes, 0644)
+					stripped, err := golden.StripIgnoredFields(string(bytes), ignoredFields)
+					s.Require().NoError(err, "Rendered output is not valid YAML")
+					err = os.WriteFile(goldenFile, []byte(stripped), 0644)
 					s.Require().NoError(err, "Golden file was not writable")
 				}
 
@@ -65,7 +80,12 @@ func (s *TemplateGoldenTest) TestDifferentValuesInputs() {
 
 				// then
 				s.Require().NoError(e, "Golden file doesn't exist or was not readable")
-				s.Require().Equal(string(expected), output)
+				diffs, e := golden.CompareManifests(string(expected), string(bytes), ignoredFields)
+				s.Require().NoError(e)
+				if len(diffs) > 0 {
+					s.Failf("rendered output differs from "+goldenFile,
+						"%d difference(s) (run `make go.update-golden-only` to accept):\n%s", len(diffs), strings.Join(diffs, "\n"))
+				}
 			},
 		},
 	}
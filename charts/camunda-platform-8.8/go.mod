@@ -11,6 +11,8 @@ require (
 	gopkg.in/yaml.v3 v3.0.1
 	k8s.io/api v0.36.2
 	k8s.io/client-go v0.36.2
+	test/golden v0.0.0
+	test/parity v0.0.0
 )
 
 require k8s.io/apimachinery v0.36.2
@@ -127,3 +129,7 @@ require (
 	sigs.k8s.io/structured-merge-diff/v6 v6.3.2 // indirect
 	sigs.k8s.io/yaml v1.6.0 // indirect
 )
+
+replace test/golden => ../../test/golden
+
+replace test/parity => ../../test/parity
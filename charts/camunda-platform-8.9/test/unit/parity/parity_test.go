@@ -0,0 +1,50 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parity
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"camunda-platform/test/unit/testhelpers"
+	shared "test/parity"
+
+	"github.com/gruntwork-io/terratest/modules/random"
+	"github.com/stretchr/testify/require"
+)
+
+// TestBehaviourParity runs the cross-version behaviour catalogue (test/parity)
+// against this chart. Behaviours are declared there, once for every active
+// chart version; see `make go.parity-report` for the matrix.
+func TestBehaviourParity(t *testing.T) {
+	t.Parallel()
+
+	chartPath, err := filepath.Abs("../../../")
+	require.NoError(t, err)
+	version, err := shared.VersionFromChartPath(chartPath)
+	require.NoError(t, err)
+	namespace := "camunda-platform-" + strings.ToLower(random.UniqueId())
+
+	shared.Run(t, version, func(t *testing.T, c shared.Case) {
+		testhelpers.RunTestCasesE(t, chartPath, "camunda-platform-test", namespace, nil, []testhelpers.TestCase{{
+			Name:       version,
+			Template:   c.Template,
+			Values:     c.Values,
+			Expected:   c.Expected,
+			Unexpected: c.Unexpected,
+		}})
+	})
+}
@@ -46,17 +46,22 @@ import (
 	"scripts/camunda-core/pkg/scenarios"
 )
 
-// componentSpec locates a declared component image: the overlay path (component
-// holding `.image`) and the base path whose `.image` provides fallbacks
-// (global for components; the parent component for split sub-images).
-type componentSpec struct {
-	overlay string
-	base    string
+// ComponentSpec locates a declared component image: Path is the component
+// holding `.image` (dot-separated, e.g. "webModeler.restapi") and Base the
+// path whose `.image` provides fallbacks (global for components; the parent
+// component for split sub-images).
+type ComponentSpec struct {
+	Path string
+	Base string
 }
 
 const globalBase = "global"
 
-var componentSpecs = []componentSpec{
+// ComponentSpecs is the single table of component image paths across chart
+// generations: the image set is derived from it, and valuesinjector edits the
+// same paths. A chart declares a subset (8.6/8.7 the classic components, 8.8+
+// orchestration); paths a chart does not declare are skipped.
+var ComponentSpecs = []ComponentSpec{
 	{"orchestration", globalBase},
 	{"zeebe", globalBase},
 	{"zeebeGateway", globalBase},
@@ -118,7 +123,7 @@ func ImageSet(chartDir string, overlays ...string) ([]string, error) {
 	}
 
 	// Camunda component images (explicit declared paths, no over-inclusion).
-	for _, spec := range componentSpecs {
+	for _, spec := range ComponentSpecs {
 		if ref, ok := resolveComponent(values, spec); ok {
 			emit(ref)
 		}
@@ -153,9 +158,9 @@ func ImageSet(chartDir string, overlays ...string) ([]string, error) {
 }
 
 // resolveComponent applies the chart's imageByParams helper for one component.
-func resolveComponent(values map[string]any, spec componentSpec) (string, bool) {
-	overlay := imageMapAt(values, spec.overlay)
-	base := imageMapAt(values, spec.base)
+func resolveComponent(values map[string]any, spec ComponentSpec) (string, bool) {
+	overlay := imageMapAt(values, spec.Path)
+	base := imageMapAt(values, spec.Base)
 
 	repository := firstNonEmpty(overlay["repository"], base["repository"])
 	if repository == "" {
@@ -0,0 +1,262 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package failures classifies failure output — helm stderr, Kubernetes API
+// errors, pod events, test output or a whole CI job log — against a catalogue
+// of known failure signatures.
+//
+// The catalogue is the data file catalogue.yaml, embedded in the binary. Each
+// signature carries a category, whether a retry can succeed and a remediation
+// hint, so the helm and kube retry helpers, the matrix run report, triage and
+// the integration-tests-gate all name and handle a failure the same way.
+package failures
+
+import (
+	_ "embed"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed catalogue.yaml
+var catalogueYAML []byte
+
+// Category is the broad class of a failure cause.
+type Category string
+
+const (
+	// InfraTransient is a cluster, network or API hiccup that a retry fixes.
+	InfraTransient Category = "infra-transient"
+	// ChartBug is a defect in the chart or the scenario values.
+	ChartBug Category = "chart-bug"
+	// TestFlake is a timing-dependent test failure.
+	TestFlake Category = "test-flake"
+	// Readiness is a workload that did not become ready in time. Either the
+	// cluster was slow or a component never starts; only the diagnostics
+	// tell which, so it is neither a test flake nor a chart bug.
+	Readiness Category = "readiness"
+	// Quota is an exhausted rate limit, quota or cluster capacity.
+	Quota Category = "quota"
+	// Auth is a rejected credential.
+	Auth Category = "auth"
+)
+
+var categories = map[Category]bool{InfraTransient: true, ChartBug: true, TestFlake: true, Readiness: true, Quota: true, Auth: true}
+
+// Source is the kind of text being classified.
+type Source string
+
+const (
+	SourceHelm   Source = "helm"
+	SourceKube   Source = "kube"
+	SourceEvents Source = "events"
+	SourceTest   Source = "test"
+	// SourceLog is a whole CI job log, which interleaves all other sources.
+	// Signatures opt into it explicitly, since some patterns are only
+	// meaningful in isolated output.
+	SourceLog Source = "log"
+)
+
+var sources = map[Source]bool{SourceHelm: true, SourceKube: true, SourceEvents: true, SourceTest: true, SourceLog: true}
+
+// WebhookNotReady is the ID of the signature for an admission webhook that
+// is not serving yet.
+const WebhookNotReady = "webhook-not-ready"
+
+// RegistryRateLimit is the ID of the signature for a registry or API rate
+// limit.
+const RegistryRateLimit = "registry-rate-limit"
+
+// Signature is one known failure cause.
+type Signature struct {
+	ID          string   `yaml:"id"`
+	Category    Category `yaml:"category"`
+	Retryable   bool     `yaml:"retryable"`
+	Sources     []Source `yaml:"sources"`
+	Require     []string `yaml:"require"`
+	Match       []string `yaml:"match"`
+	Remediation string   `yaml:"remediation"`
+
+	require []*regexp.Regexp
+	match   []*regexp.Regexp
+}
+
+// Catalogue is an ordered list of signatures; the first match wins.
+type Catalogue struct {
+	Signatures []*Signature
+}
+
+// Match is a classified failure.
+type Match struct {
+	*Signature
+	// Line is the line of the classified text that matched.
+	Line string
+}
+
+// Transient reports whether the failure is an infrastructure hiccup that is
+// safe to retry right away. A rate limit counts: the retry helpers back off
+// between attempts, which is what a "too many requests" answer asks for.
+func (m Match) Transient() bool {
+	if m.Signature == nil || !m.Retryable {
+		return false
+	}
+	return m.Category == InfraTransient || m.ID == RegistryRateLimit
+}
+
+// Parse reads and validates a catalogue.
+func Parse(data []byte) (*Catalogue, error) {
+	var sigs []*Signature
+	if err := yaml.Unmarshal(data, &sigs); err != nil {
+		return nil, fmt.Errorf("parse failure catalogue: %w", err)
+	}
+
+	seen := make(map[string]bool, len(sigs))
+	for i, s := range sigs {
+		if s.ID == "" {
+			return nil, fmt.Errorf("failure signature %d: id is required", i)
+		}
+		if seen[s.ID] {
+			return nil, fmt.Errorf("failure signature %s: duplicate id", s.ID)
+		}
+		seen[s.ID] = true
+		if !categories[s.Category] {
+			return nil, fmt.Errorf("failure signature %s: unknown category %q", s.ID, s.Category)
+		}
+		for _, src := range s.Sources {
+			if !sources[src] {
+				return nil, fmt.Errorf("failure signature %s: unknown source %q", s.ID, src)
+			}
+		}
+		if len(s.Match) == 0 {
+			return nil, fmt.Errorf("failure signature %s: at least one match pattern is required", s.ID)
+		}
+		var err error
+		if s.require, err = compileAll(s.Require); err != nil {
+			return nil, fmt.Errorf("failure signature %s: %w", s.ID, err)
+		}
+		if s.match, err = compileAll(s.Match); err != nil {
+			return nil, fmt.Errorf("failure signature %s: %w", s.ID, err)
+		}
+	}
+	return &Catalogue{Signatures: sigs}, nil
+}
+
+func compileAll(patterns []string) ([]*regexp.Regexp, error) {
+	out := make([]*regexp.Regexp, 0, len(patterns))
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("pattern %q: %w", p, err)
+		}
+		out = append(out, re)
+	}
+	return out, nil
+}
+
+var (
+	defaultOnce      sync.Once
+	defaultCatalogue *Catalogue
+)
+
+// Default returns the embedded catalogue. The catalogue is validated by the
+// package tests, so a parse error here is a build defect and panics.
+func Default() *Catalogue {
+	defaultOnce.Do(func() {
+		c, err := Parse(catalogueYAML)
+		if err != nil {
+			panic(err)
+		}
+		defaultCatalogue = c
+	})
+	return defaultCatalogue
+}
+
+// Classify classifies text from source against the embedded catalogue.
+func Classify(source Source, text string) (Match, bool) {
+	return Default().Classify(source, text)
+}
+
+// Classify returns the first signature that applies to source and matches
+// text.
+func (c *Catalogue) Classify(source Source, text string) (Match, bool) {
+	if text == "" {
+		return Match{}, false
+	}
+	for _, s := range c.Signatures {
+		if line, ok := s.matches(source, text); ok {
+			return Match{Signature: s, Line: line}, true
+		}
+	}
+	return Match{}, false
+}
+
+// appliesTo reports whether s is meant for text from source.
+func (s *Signature) appliesTo(source Source) bool {
+	if len(s.Sources) == 0 {
+		return true
+	}
+	for _, src := range s.Sources {
+		if src == source {
+			return true
+		}
+	}
+	return false
+}
+
+// matches returns the line of text that matched s. Require patterns must
+// match on that same line, so unrelated lines of a long log cannot combine
+// into a match.
+func (s *Signature) matches(source Source, text string) (string, bool) {
+	if !s.appliesTo(source) {
+		return "", false
+	}
+	for _, re := range s.match {
+		if len(s.require) == 0 {
+			if loc := re.FindStringIndex(text); loc != nil {
+				return lineAt(text, loc[0]), true
+			}
+			continue
+		}
+		for _, loc := range re.FindAllStringIndex(text, -1) {
+			if line := lineAt(text, loc[0]); matchesAll(s.require, line) {
+				return line, true
+			}
+		}
+	}
+	return "", false
+}
+
+func matchesAll(res []*regexp.Regexp, s string) bool {
+	for _, re := range res {
+		if !re.MatchString(s) {
+			return false
+		}
+	}
+	return true
+}
+
+// lineAt returns the trimmed line of text containing offset i.
+func lineAt(text string, i int) string {
+	start := strings.LastIndexByte(text[:i], '\n') + 1
+	end := strings.IndexByte(text[i:], '\n')
+	if end < 0 {
+		end = len(text)
+	} else {
+		end += i
+	}
+	return strings.TrimSpace(text[start:end])
+}
@@ -0,0 +1,153 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package failures
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultCatalogueIsValid(t *testing.T) {
+	c, err := Parse(catalogueYAML)
+	if err != nil {
+		t.Fatalf("embedded catalogue: %v", err)
+	}
+	for _, s := range c.Signatures {
+		if strings.TrimSpace(s.Remediation) == "" {
+			t.Errorf("signature %s has no remediation", s.ID)
+		}
+	}
+	for _, id := range []string{WebhookNotReady, RegistryRateLimit} {
+		found := false
+		for _, s := range c.Signatures {
+			found = found || s.ID == id
+		}
+		if !found {
+			t.Errorf("catalogue has no %s signature", id)
+		}
+	}
+}
+
+func TestClassify(t *testing.T) {
+	tests := []struct {
+		name      string
+		source    Source
+		text      string
+		wantID    string // "" means unclassified
+		transient bool
+	}{
+		// Helm stderr.
+		{"helm api hiccup", SourceHelm, "Error: UPGRADE FAILED: an error on the server (\"Internal Server Error\") has prevented the request", "api-transient", true},
+		{"helm connection reset", SourceHelm, "Error: read tcp 10.0.0.1:443: connection reset by peer", "api-transient", true},
+		{"helm unexpected eof", SourceHelm, "Error: INSTALLATION FAILED: unexpected EOF", "api-transient", true},
+		{"helm rate limited", SourceHelm, "Error: the server has received too many requests", "api-transient", true},
+		{"helm registry rate limited", SourceHelm, "Error: failed to fetch oci chart: toomanyrequests: too many requests", RegistryRateLimit, true},
+		{"helm wait timeout is not transient", SourceHelm, "Error: UPGRADE FAILED: context deadline exceeded", "rollout-timeout", false},
+		{"helm template error", SourceHelm, "Error: INSTALLATION FAILED: template: camunda-platform/templates/zeebe/statefulset.yaml:12:3: executing \"x\" at <.Values.x>: nil pointer evaluating interface {}.y", "chart-render", false},
+		{"helm immutable field", SourceHelm, "Error: UPGRADE FAILED: cannot patch \"zeebe\" with kind StatefulSet: StatefulSet.apps \"zeebe\" is invalid: spec: Forbidden", "chart-render", false},
+		{"helm webhook not ready", SourceHelm, `Error: Internal error occurred: failed calling webhook "validate.externalsecret": no endpoints available for service "external-secrets-webhook"`, WebhookNotReady, true},
+		{"helm unknown error", SourceHelm, "Error: something odd", "", false},
+
+		// Kubernetes API errors.
+		{"kube deadline is transient", SourceKube, "Post \"https://api/apis\": context deadline exceeded", "api-deadline", true},
+		{"kube temporarily unavailable", SourceKube, "the server is temporarily unavailable", "api-transient", true},
+		{"kube webhook refused", SourceKube, `failed to call webhook: Post "https://cert-manager-webhook.svc:443/mutate": connection refused`, WebhookNotReady, true},
+		{"kube refused without webhook", SourceKube, "dial tcp 10.0.0.1:443: connect: connection refused", "", false},
+		{"kube unauthorized", SourceKube, "Unauthorized", "auth", false},
+
+		// Pod events.
+		{"events image pull", SourceEvents, "Warning  Failed  kubelet  Error: ImagePullBackOff", "image-pull", true},
+		{"events missing tag", SourceEvents, "Failed to pull image \"camunda/zeebe:8.9.99\": manifest unknown", "image-not-found", false},
+		{"events capacity", SourceEvents, "0/3 nodes are available: 3 Insufficient cpu.", "cluster-capacity", false},
+		{"events multi-attach", SourceEvents, "Multi-Attach error for volume \"pvc-1\" Volume is already exclusively attached to one node", "multi-attach", true},
+		{"events oom", SourceEvents, "Last State: Terminated Reason: OOMKilled", "pod-oom", false},
+
+		// Test output.
+		{"test timeout", SourceTest, "Test timeout of 30000ms exceeded.", "test-timeout", false},
+
+		// Whole job logs.
+		{
+			"log: readiness timeout wins over crash-looping dependency",
+			SourceLog,
+			"zeebe-0  0/1  CrashLoopBackOff\n2026-03-01T10:00:00Z Error: UPGRADE FAILED: context deadline exceeded\n",
+			"rollout-timeout", false,
+		},
+		{
+			"log: webhook and refusal on different lines do not combine",
+			SourceLog,
+			"installing webhook chart\ncurl: (7) Failed to connect: connection refused\n",
+			"", false,
+		},
+		{
+			"log: heredoc is not an eof error",
+			SourceLog,
+			"cat <<EOF > values.yaml\nEOF\n",
+			"", false,
+		},
+		{
+			"log: kube-only deadline does not apply",
+			SourceLog,
+			"Post \"https://api/apis\": context deadline exceeded",
+			"rollout-timeout", false,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			m, ok := Classify(tc.source, tc.text)
+			if tc.wantID == "" {
+				if ok {
+					t.Fatalf("expected no classification, got %s (line %q)", m.ID, m.Line)
+				}
+				return
+			}
+			if !ok {
+				t.Fatalf("expected %s, got no classification", tc.wantID)
+			}
+			if m.ID != tc.wantID {
+				t.Errorf("classified as %s, want %s (line %q)", m.ID, tc.wantID, m.Line)
+			}
+			if m.Transient() != tc.transient {
+				t.Errorf("Transient() = %v, want %v", m.Transient(), tc.transient)
+			}
+			if m.Line == "" || strings.Contains(m.Line, "\n") {
+				t.Errorf("expected the single matching line, got %q", m.Line)
+			}
+		})
+	}
+}
+
+func TestParseRejectsInvalidCatalogues(t *testing.T) {
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{"missing id", "- category: auth\n  match: [x]\n", "id is required"},
+		{"duplicate id", "- id: a\n  category: auth\n  match: [x]\n- id: a\n  category: auth\n  match: [y]\n", "duplicate id"},
+		{"unknown category", "- id: a\n  category: flaky\n  match: [x]\n", "unknown category"},
+		{"unknown source", "- id: a\n  category: auth\n  sources: [stdout]\n  match: [x]\n", "unknown source"},
+		{"no match", "- id: a\n  category: auth\n", "at least one match pattern"},
+		{"bad regexp", "- id: a\n  category: auth\n  match: ['(']\n", "pattern"},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse([]byte(tc.yaml))
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("Parse error = %v, want it to contain %q", err, tc.wantErr)
+			}
+		})
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"scripts/camunda-core/pkg/executil"
+	"scripts/camunda-core/pkg/failures"
 	"scripts/camunda-core/pkg/logging"
 	"strings"
 	"sync"
@@ -21,30 +22,11 @@ func RunCaptureStderr(ctx context.Context, args []string, workDir string) (strin
 }
 
 // IsTransientHelmError reports whether the stderr text from a failed helm command
-// indicates a transient infrastructure error that is safe to retry.
+// indicates a transient infrastructure error that is safe to retry, according to
+// the failure-signature catalogue.
 func IsTransientHelmError(stderr string) bool {
-	if stderr == "" {
-		return false
-	}
-	lower := strings.ToLower(stderr)
-	transientHints := []string{
-		"internal server error",
-		"server is currently unable to handle the request",
-		"connection reset by peer",
-		"i/o timeout",
-		"tls handshake timeout",
-		"service unavailable",
-		"too many requests",
-		"etcdserver: request timed out",
-		"net/http: request canceled",
-		"eof",
-	}
-	for _, hint := range transientHints {
-		if strings.Contains(lower, hint) {
-			return true
-		}
-	}
-	return false
+	m, ok := failures.Classify(failures.SourceHelm, stderr)
+	return ok && m.Transient()
 }
 
 var (
@@ -0,0 +1,167 @@
+package kube
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// helmReleaseDataKey is the key Helm's secrets storage driver writes the
+// encoded release record under.
+const helmReleaseDataKey = "release"
+
+// HelmRelease is the subset of a Helm release record that callers read
+// without the helm binary. Field names and JSON tags follow the shape
+// `helm status -o json` prints, so consumers that used to parse the CLI
+// output can decode this unchanged. Manifest and user values are left out
+// on purpose: they can be large and carry secrets.
+type HelmRelease struct {
+	Name      string          `json:"name"`
+	Namespace string          `json:"namespace"`
+	Version   int             `json:"version"`
+	Info      HelmReleaseInfo `json:"info"`
+	Chart     struct {
+		Metadata struct {
+			Name       string `json:"name"`
+			Version    string `json:"version"`
+			AppVersion string `json:"appVersion,omitempty"`
+		} `json:"metadata"`
+	} `json:"chart"`
+}
+
+// HelmReleaseContent adds the rendered manifest and the user-supplied values
+// (what `helm get manifest` and `helm get values` print) to a release. It is
+// read separately from HelmReleaseStatus because both fields can be large
+// and the values carry secrets: callers must redact before persisting them.
+type HelmReleaseContent struct {
+	HelmRelease
+	Config   map[string]any `json:"config,omitempty"`
+	Manifest string         `json:"manifest,omitempty"`
+}
+
+// HelmReleaseInfo carries the lifecycle fields of a Helm release.
+type HelmReleaseInfo struct {
+	Status        string    `json:"status"`
+	Description   string    `json:"description,omitempty"`
+	FirstDeployed time.Time `json:"first_deployed"`
+	LastDeployed  time.Time `json:"last_deployed"`
+}
+
+// HelmReleaseStatus reads the latest revision of release from Helm's
+// secrets storage driver (the Helm 3/4 default) instead of running
+// `helm status`. It returns an error when no revision exists, mirroring
+// the CLI's "release: not found".
+func (c *Client) HelmReleaseStatus(ctx context.Context, namespace, release string) (*HelmRelease, error) {
+	secrets, err := c.clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "owner=helm,name=" + release,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list helm storage secrets for release %q in namespace %q: %w", release, namespace, err)
+	}
+	if len(secrets.Items) == 0 {
+		return nil, fmt.Errorf("helm release %q not found in namespace %q", release, namespace)
+	}
+
+	// Revisions are labelled version=<n>; the highest one is what
+	// `helm status` reports.
+	items := secrets.Items
+	sort.SliceStable(items, func(i, j int) bool {
+		return helmRevision(items[i]) > helmRevision(items[j])
+	})
+	latest := items[0]
+
+	rel, err := decodeHelmRelease(latest.Data[helmReleaseDataKey])
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode helm release secret %q: %w", latest.Name, err)
+	}
+	return rel, nil
+}
+
+// ListHelmReleaseContents returns the latest revision of every Helm release
+// in namespace, with manifest and user values, sorted by release name.
+func (c *Client) ListHelmReleaseContents(ctx context.Context, namespace string) ([]HelmReleaseContent, error) {
+	secrets, err := c.clientset.CoreV1().Secrets(namespace).List(ctx, metav1.ListOptions{
+		LabelSelector: "owner=helm",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list helm storage secrets in namespace %q: %w", namespace, err)
+	}
+
+	latest := map[string]corev1.Secret{}
+	for _, s := range secrets.Items {
+		name := s.Labels["name"]
+		if cur, ok := latest[name]; !ok || helmRevision(s) > helmRevision(cur) {
+			latest[name] = s
+		}
+	}
+	names := make([]string, 0, len(latest))
+	for name := range latest {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	out := make([]HelmReleaseContent, 0, len(names))
+	for _, name := range names {
+		var rel HelmReleaseContent
+		if err := decodeHelmRecord(latest[name].Data[helmReleaseDataKey], &rel); err != nil {
+			return nil, fmt.Errorf("failed to decode helm release secret %q: %w", latest[name].Name, err)
+		}
+		out = append(out, rel)
+	}
+	return out, nil
+}
+
+// helmRevision extracts the numeric revision from a storage secret's
+// "version" label. Unparseable labels sort last.
+func helmRevision(secret corev1.Secret) int {
+	n, err := strconv.Atoi(secret.Labels["version"])
+	if err != nil {
+		return -1
+	}
+	return n
+}
+
+// decodeHelmRelease reverses Helm's storage encoding: base64 over an
+// (optionally gzipped) JSON release record.
+func decodeHelmRelease(data []byte) (*HelmRelease, error) {
+	var rel HelmRelease
+	if err := decodeHelmRecord(data, &rel); err != nil {
+		return nil, err
+	}
+	return &rel, nil
+}
+
+// decodeHelmRecord decodes a storage secret payload into out.
+func decodeHelmRecord(data []byte, out any) error {
+	if len(data) == 0 {
+		return fmt.Errorf("empty %q key", helmReleaseDataKey)
+	}
+	raw, err := base64.StdEncoding.DecodeString(string(data))
+	if err != nil {
+		return fmt.Errorf("base64 decode: %w", err)
+	}
+	if bytes.HasPrefix(raw, []byte{0x1f, 0x8b}) {
+		zr, err := gzip.NewReader(bytes.NewReader(raw))
+		if err != nil {
+			return fmt.Errorf("gzip reader: %w", err)
+		}
+		defer zr.Close()
+		if raw, err = io.ReadAll(zr); err != nil {
+			return fmt.Errorf("gunzip: %w", err)
+		}
+	}
+	if err := json.Unmarshal(raw, out); err != nil {
+		return fmt.Errorf("unmarshal release: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,126 @@
+package kube
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func helmStorageSecret(t *testing.T, release, namespace string, revision, status string, compress bool) *corev1.Secret {
+	t.Helper()
+	payload := []byte(`{"name":"` + release + `","namespace":"` + namespace + `","version":` + revision +
+		`,"info":{"status":"` + status + `"},"chart":{"metadata":{"name":"camunda-platform","version":"13.0.0"}}}`)
+	if compress {
+		var buf bytes.Buffer
+		zw := gzip.NewWriter(&buf)
+		if _, err := zw.Write(payload); err != nil {
+			t.Fatalf("gzip write: %v", err)
+		}
+		if err := zw.Close(); err != nil {
+			t.Fatalf("gzip close: %v", err)
+		}
+		payload = buf.Bytes()
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sh.helm.release.v1." + release + ".v" + revision,
+			Namespace: namespace,
+			Labels:    map[string]string{"owner": "helm", "name": release, "version": revision, "status": status},
+		},
+		Type: "helm.sh/release.v1",
+		Data: map[string][]byte{helmReleaseDataKey: []byte(base64.StdEncoding.EncodeToString(payload))},
+	}
+}
+
+func TestHelmReleaseStatus_PicksLatestRevision(t *testing.T) {
+	client := newTestClient(
+		helmStorageSecret(t, "integration", "ns", "1", "superseded", true),
+		helmStorageSecret(t, "integration", "ns", "10", "deployed", true),
+		helmStorageSecret(t, "integration", "ns", "2", "superseded", false),
+		helmStorageSecret(t, "other", "ns", "11", "failed", true),
+	)
+
+	rel, err := client.HelmReleaseStatus(context.Background(), "ns", "integration")
+	if err != nil {
+		t.Fatalf("HelmReleaseStatus: %v", err)
+	}
+	if rel.Version != 10 || rel.Info.Status != "deployed" {
+		t.Fatalf("expected revision 10 deployed, got revision %d %q", rel.Version, rel.Info.Status)
+	}
+	if rel.Chart.Metadata.Name != "camunda-platform" {
+		t.Fatalf("expected chart metadata to decode, got %+v", rel.Chart.Metadata)
+	}
+}
+
+func TestHelmReleaseStatus_NotFound(t *testing.T) {
+	client := newTestClient()
+	_, err := client.HelmReleaseStatus(context.Background(), "ns", "integration")
+	if err == nil || !strings.Contains(err.Error(), "not found") {
+		t.Fatalf("expected not-found error, got %v", err)
+	}
+}
+
+func TestHelmReleaseStatus_CorruptPayload(t *testing.T) {
+	secret := helmStorageSecret(t, "integration", "ns", "1", "deployed", false)
+	secret.Data[helmReleaseDataKey] = []byte("%%% not base64")
+	client := newTestClient(secret)
+	if _, err := client.HelmReleaseStatus(context.Background(), "ns", "integration"); err == nil {
+		t.Fatal("expected decode error for corrupt payload")
+	}
+}
+
+func TestListPods_SetsListTypeMeta(t *testing.T) {
+	client := newTestClient(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "zeebe-0", Namespace: "ns"}})
+	pods, err := client.ListPods(context.Background(), "ns")
+	if err != nil {
+		t.Fatalf("ListPods: %v", err)
+	}
+	if len(pods.Items) != 1 || pods.Kind != "List" {
+		t.Fatalf("expected one pod in a v1 List, got %d items kind=%q", len(pods.Items), pods.Kind)
+	}
+}
+
+func TestListHelmReleaseContents(t *testing.T) {
+	withContent := helmStorageSecret(t, "integration", "ns", "2", "failed", true)
+	payload := `{"name":"integration","namespace":"ns","version":2,"info":{"status":"failed"},` +
+		`"config":{"global":{"host":"example.com"}},"manifest":"---\nkind: StatefulSet\n"}`
+	withContent.Data[helmReleaseDataKey] = []byte(base64.StdEncoding.EncodeToString([]byte(payload)))
+
+	client := newTestClient(
+		helmStorageSecret(t, "integration", "ns", "1", "superseded", true),
+		withContent,
+		helmStorageSecret(t, "elasticsearch", "ns", "1", "deployed", false),
+	)
+
+	releases, err := client.ListHelmReleaseContents(context.Background(), "ns")
+	if err != nil {
+		t.Fatalf("ListHelmReleaseContents: %v", err)
+	}
+	if len(releases) != 2 || releases[0].Name != "elasticsearch" || releases[1].Name != "integration" {
+		t.Fatalf("expected the two releases sorted by name, got %+v", releases)
+	}
+	rel := releases[1]
+	if rel.Version != 2 || rel.Manifest != "---\nkind: StatefulSet\n" {
+		t.Errorf("expected the latest revision with its manifest, got revision %d manifest %q", rel.Version, rel.Manifest)
+	}
+	if host := rel.Config["global"].(map[string]any)["host"]; host != "example.com" {
+		t.Errorf("expected user values to decode, got %v", rel.Config)
+	}
+}
+
+func TestPodContainerLogs(t *testing.T) {
+	client := newTestClient(&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "zeebe-0", Namespace: "ns"}})
+	logs, err := client.PodContainerLogs(context.Background(), "ns", "zeebe-0", "zeebe", true, 100)
+	if err != nil {
+		t.Fatalf("PodContainerLogs: %v", err)
+	}
+	if logs != "fake logs" {
+		t.Errorf("logs = %q", logs)
+	}
+}
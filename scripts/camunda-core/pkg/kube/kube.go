@@ -9,6 +9,7 @@ import (
 	"io"
 	"os"
 	"os/exec"
+	"scripts/camunda-core/pkg/failures"
 	"scripts/camunda-core/pkg/logging"
 	"strings"
 	"time"
@@ -136,6 +137,14 @@ func NewClient(kubeconfig, kubeContext string) (*Client, error) {
 	}, nil
 }
 
+// NewClientForClientset wraps an existing typed clientset, such as
+// k8s.io/client-go/kubernetes/fake, in a Client. Only the typed-clientset
+// methods (secrets, namespaces, pods, events, Helm storage reads) work on
+// the result; dynamic and discovery-backed calls need NewClient.
+func NewClientForClientset(clientset kubernetes.Interface, kubeContext string) *Client {
+	return &Client{clientset: clientset, kubeContext: kubeContext}
+}
+
 // ListNamespacedResources lists arbitrary Kubernetes resources through the
 // dynamic client. Callers provide the exact group, version, and resource name.
 func (c *Client) ListNamespacedResources(
@@ -728,9 +737,10 @@ func (c *Client) patchNamespaceLabelsAndAnnotations(ctx context.Context, namespa
 }
 
 const (
-	platformGKE  = "gke"
-	platformROSA = "rosa"
-	platformEKS  = "eks"
+	platformGKE   = "gke"
+	platformROSA  = "rosa"
+	platformEKS   = "eks"
+	platformLocal = "local"
 
 	secretNameTLS = "aws-camunda-cloud-tls"
 )
@@ -746,6 +756,12 @@ func ApplyExternalSecretsAndCerts(ctx context.Context, kubeconfig, kubeContext,
 		Str("externalSecretsStore", externalSecretsStore).
 		Msg("applying external secrets/certs")
 
+	// Local clusters never carry the ExternalSecrets CRD; skip the probe and
+	// its "configure TLS manually" warning, which does not apply there.
+	if platform == platformLocal {
+		return (&LocalSecretsProvider{}).Apply(ctx, nil, namespace)
+	}
+
 	client, err := NewClient(kubeconfig, kubeContext)
 	if err != nil {
 		return err
@@ -953,39 +969,15 @@ func (c *Client) resourceForGVK(gvk schema.GroupVersionKind) (schema.GroupVersio
 }
 
 // isWebhookNotReadyError checks if the error is due to a webhook not being ready.
-// This typically happens when the external-secrets webhook hasn't registered its endpoints yet.
-//
-// Common error patterns include:
-//   - "Internal error occurred: failed calling webhook ... no endpoints available for service"
-//   - "failed to call webhook: Post ... connection refused"
-//   - "failed to call webhook: Post ... service unavailable"
+// This typically happens when the external-secrets webhook hasn't registered its endpoints yet,
+// e.g. "Internal error occurred: failed calling webhook ... no endpoints available for service".
+// The patterns live in the failure-signature catalogue.
 func isWebhookNotReadyError(err error) bool {
 	if err == nil {
 		return false
 	}
-	errStr := err.Error()
-
-	// Must contain "webhook" to be a webhook-related error
-	if !strings.Contains(errStr, "webhook") {
-		return false
-	}
-
-	// Check for specific webhook unavailability patterns
-	webhookUnavailablePatterns := []string{
-		"no endpoints available",
-		"connection refused",
-		"failed to call webhook",
-		"service unavailable",
-		"Internal error occurred",
-	}
-
-	for _, pattern := range webhookUnavailablePatterns {
-		if strings.Contains(errStr, pattern) {
-			return true
-		}
-	}
-
-	return false
+	m, ok := failures.Classify(failures.SourceKube, err.Error())
+	return ok && m.ID == failures.WebhookNotReady
 }
 
 func checkIfExternalSecretsCRDExists(ctx context.Context, client *Client) (bool, error) {
@@ -1161,25 +1153,12 @@ func CheckConnectivity(ctx context.Context, kubeContext string) error {
 	return nil
 }
 
+// isTransientKubeApplyError reports whether an API error is an infrastructure
+// hiccup worth retrying, according to the failure-signature catalogue.
 func isTransientKubeApplyError(err error) bool {
 	if err == nil {
 		return false
 	}
-	msg := strings.ToLower(err.Error())
-	transientHints := []string{
-		"connection reset by peer",
-		"i/o timeout",
-		"tls handshake timeout",
-		"context deadline exceeded",
-		"temporarily unavailable",
-		"service unavailable",
-		"too many requests",
-		"eof",
-	}
-	for _, hint := range transientHints {
-		if strings.Contains(msg, hint) {
-			return true
-		}
-	}
-	return false
+	m, ok := failures.Classify(failures.SourceKube, err.Error())
+	return ok && m.Transient()
 }
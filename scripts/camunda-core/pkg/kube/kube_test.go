@@ -133,3 +133,29 @@ func TestApplyManifestGatewayUsesDiscoveredResource(t *testing.T) {
 		t.Fatalf("applied resource = %q, want %q", appliedResource, "gateways")
 	}
 }
+
+func TestNewPlatformSecretsProviderLocal(t *testing.T) {
+	provider, err := NewPlatformSecretsProvider("local", "/repo", "/repo/charts/camunda-platform-8.9", "", "vault-backend")
+	if err != nil {
+		t.Fatalf("NewPlatformSecretsProvider(local): %v", err)
+	}
+	if _, ok := provider.(*LocalSecretsProvider); !ok {
+		t.Fatalf("provider = %T, want *LocalSecretsProvider", provider)
+	}
+	if err := provider.Apply(context.Background(), nil, "local-ns"); err != nil {
+		t.Fatalf("Apply: %v", err)
+	}
+
+	_, err = NewPlatformSecretsProvider("aks", "", "", "", "")
+	if err == nil || !strings.Contains(err.Error(), "local") {
+		t.Fatalf("unsupported platform error should list local, got %v", err)
+	}
+}
+
+func TestApplyExternalSecretsAndCertsLocalSkipsCluster(t *testing.T) {
+	// No kubeconfig or context is needed: the local platform never talks to
+	// the cluster for external secrets.
+	if err := ApplyExternalSecretsAndCerts(context.Background(), "/does/not/exist", "missing-ctx", " Local ", "", "", "ns", "", ""); err != nil {
+		t.Fatalf("ApplyExternalSecretsAndCerts(local): %v", err)
+	}
+}
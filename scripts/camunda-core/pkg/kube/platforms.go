@@ -47,6 +47,17 @@ func (p *EKSSecretsProvider) Apply(ctx context.Context, client *Client, namespac
 	return applySecretsForEKS(ctx, client, p.RepoRoot, p.ChartPath, namespace, p.NamespacePrefix, p.ExternalSecretsStore)
 }
 
+// LocalSecretsProvider is used for kind/k3d clusters created by
+// `deploy-camunda --platform local`. There is no external-secrets operator or
+// Vault there: credentials come from the generated test secrets and TLS from
+// the ingress controller's default certificate, so there is nothing to apply.
+type LocalSecretsProvider struct{}
+
+func (p *LocalSecretsProvider) Apply(ctx context.Context, client *Client, namespace string) error {
+	logging.Logger.Debug().Str("namespace", namespace).Msg("local platform: skipping external secrets, using generated test secrets")
+	return nil
+}
+
 func NewPlatformSecretsProvider(platform, repoRoot, chartPath, namespacePrefix, externalSecretsStore string) (PlatformSecretsProvider, error) {
 	switch platform {
 	case platformGKE:
@@ -68,8 +79,10 @@ func NewPlatformSecretsProvider(platform, repoRoot, chartPath, namespacePrefix,
 			ChartPath:            chartPath,
 			ExternalSecretsStore: externalSecretsStore,
 		}, nil
+	case platformLocal:
+		return &LocalSecretsProvider{}, nil
 	default:
-		return nil, fmt.Errorf("unsupported platform %q (supported: gke, rosa, eks)", platform)
+		return nil, fmt.Errorf("unsupported platform %q (supported: gke, rosa, eks, local)", platform)
 	}
 }
 
@@ -0,0 +1,99 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"fmt"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListPods returns every pod in namespace.
+func (c *Client) ListPods(ctx context.Context, namespace string) (*corev1.PodList, error) {
+	pods, err := c.clientset.CoreV1().Pods(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in namespace %q: %w", namespace, err)
+	}
+	pods.APIVersion, pods.Kind = "v1", "List"
+	return pods, nil
+}
+
+// ListEvents returns every core/v1 event in namespace.
+func (c *Client) ListEvents(ctx context.Context, namespace string) (*corev1.EventList, error) {
+	events, err := c.clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events in namespace %q: %w", namespace, err)
+	}
+	events.APIVersion, events.Kind = "v1", "List"
+	return events, nil
+}
+
+// ListPVCs returns every PersistentVolumeClaim in namespace.
+func (c *Client) ListPVCs(ctx context.Context, namespace string) (*corev1.PersistentVolumeClaimList, error) {
+	pvcs, err := c.clientset.CoreV1().PersistentVolumeClaims(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pvcs in namespace %q: %w", namespace, err)
+	}
+	pvcs.APIVersion, pvcs.Kind = "v1", "List"
+	return pvcs, nil
+}
+
+// ListServices returns every Service in namespace.
+func (c *Client) ListServices(ctx context.Context, namespace string) (*corev1.ServiceList, error) {
+	services, err := c.clientset.CoreV1().Services(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list services in namespace %q: %w", namespace, err)
+	}
+	services.APIVersion, services.Kind = "v1", "List"
+	return services, nil
+}
+
+// ListStatefulSets returns every StatefulSet in namespace.
+func (c *Client) ListStatefulSets(ctx context.Context, namespace string) (*appsv1.StatefulSetList, error) {
+	sets, err := c.clientset.AppsV1().StatefulSets(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list statefulsets in namespace %q: %w", namespace, err)
+	}
+	sets.APIVersion, sets.Kind = "v1", "List"
+	return sets, nil
+}
+
+// ListDeployments returns every Deployment in namespace.
+func (c *Client) ListDeployments(ctx context.Context, namespace string) (*appsv1.DeploymentList, error) {
+	deployments, err := c.clientset.AppsV1().Deployments(namespace).List(ctx, metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list deployments in namespace %q: %w", namespace, err)
+	}
+	deployments.APIVersion, deployments.Kind = "v1", "List"
+	return deployments, nil
+}
+
+// PodContainerLogs returns the last tailLines lines of one container's log,
+// or of its previous instance when previous is set. tailLines <= 0 returns
+// the whole log.
+func (c *Client) PodContainerLogs(ctx context.Context, namespace, pod, container string, previous bool, tailLines int64) (string, error) {
+	opts := &corev1.PodLogOptions{Container: container, Previous: previous, Timestamps: true}
+	if tailLines > 0 {
+		opts.TailLines = &tailLines
+	}
+	out, err := c.clientset.CoreV1().Pods(namespace).GetLogs(pod, opts).DoRaw(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to get logs of %s/%s in namespace %q: %w", pod, container, namespace, err)
+	}
+	return string(out), nil
+}
@@ -0,0 +1,286 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// crashReasons are container waiting reasons worth surfacing in the feed.
+var crashReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+// StreamOptions configures StreamNamespace.
+type StreamOptions struct {
+	// Namespace to watch. It does not have to exist yet: pods and events are
+	// picked up as soon as it is created.
+	Namespace string
+	// Dir receives events.log (the namespace event timeline) and
+	// pods/<pod>/<container>.<restart>.log (one file per container instance,
+	// with kubelet timestamps on every line).
+	Dir string
+	// OnFeed receives condensed one-line notices: warning events, container
+	// restarts, crash or image-pull loops and pod deletions. Nil disables it.
+	OnFeed func(line string)
+}
+
+// Streamer follows the events and container logs of a namespace from the
+// moment it is started, so that logs of pods restarted or deleted before a
+// failure are still on disk afterwards. Stop it before deleting the namespace.
+type Streamer struct {
+	opts    StreamOptions
+	client  *Client
+	factory informers.SharedInformerFactory
+	ctx     context.Context
+	cancel  context.CancelFunc
+	wg      sync.WaitGroup
+
+	mu        sync.Mutex
+	events    *os.File
+	eventSeen map[string]int32  // event name -> last written count
+	following map[string]bool   // container IDs whose logs are (being) written
+	restarts  map[string]int32  // pod/container -> last seen restart count
+	waiting   map[string]string // pod/container -> last reported waiting reason
+}
+
+// StreamNamespace starts streaming the events and container logs of
+// opts.Namespace into opts.Dir. It returns once the informers are started;
+// call Stop to end the stream and flush the files.
+func (c *Client) StreamNamespace(ctx context.Context, opts StreamOptions) (*Streamer, error) {
+	if opts.Namespace == "" {
+		return nil, errors.New("namespace must not be empty")
+	}
+	if err := os.MkdirAll(filepath.Join(opts.Dir, "pods"), 0o755); err != nil {
+		return nil, fmt.Errorf("create stream directory: %w", err)
+	}
+	events, err := os.OpenFile(filepath.Join(opts.Dir, "events.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("create events log: %w", err)
+	}
+
+	s := &Streamer{
+		opts:      opts,
+		client:    c,
+		events:    events,
+		eventSeen: map[string]int32{},
+		following: map[string]bool{},
+		restarts:  map[string]int32{},
+		waiting:   map[string]string{},
+		factory:   informers.NewSharedInformerFactoryWithOptions(c.clientset, 0, informers.WithNamespace(opts.Namespace)),
+	}
+	s.ctx, s.cancel = context.WithCancel(ctx)
+
+	if _, err := s.factory.Core().V1().Events().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { s.onEvent(obj) },
+		UpdateFunc: func(_, obj any) { s.onEvent(obj) },
+	}); err != nil {
+		s.cancel()
+		events.Close()
+		return nil, fmt.Errorf("watch events: %w", err)
+	}
+	if _, err := s.factory.Core().V1().Pods().Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc:    func(obj any) { s.onPod(obj) },
+		UpdateFunc: func(_, obj any) { s.onPod(obj) },
+		DeleteFunc: s.onPodDelete,
+	}); err != nil {
+		s.cancel()
+		events.Close()
+		return nil, fmt.Errorf("watch pods: %w", err)
+	}
+	s.factory.Start(s.ctx.Done())
+	return s, nil
+}
+
+// Dir returns the directory the stream is written to.
+func (s *Streamer) Dir() string { return s.opts.Dir }
+
+// Stop ends the stream, waits for log followers to finish and closes the
+// events log. It is safe to call more than once.
+func (s *Streamer) Stop() {
+	s.cancel()
+	s.factory.Shutdown()
+	s.wg.Wait()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.events != nil {
+		s.events.Close()
+		s.events = nil
+	}
+}
+
+func (s *Streamer) feed(format string, args ...any) {
+	if s.opts.OnFeed != nil {
+		s.opts.OnFeed(fmt.Sprintf(format, args...))
+	}
+}
+
+func (s *Streamer) onEvent(obj any) {
+	ev, ok := obj.(*corev1.Event)
+	if !ok {
+		return
+	}
+	count := ev.Count
+	if count == 0 {
+		count = 1
+	}
+
+	s.mu.Lock()
+	if s.events == nil || s.eventSeen[ev.Name] >= count {
+		s.mu.Unlock()
+		return
+	}
+	s.eventSeen[ev.Name] = count
+	object := fmt.Sprintf("%s/%s", ev.InvolvedObject.Kind, ev.InvolvedObject.Name)
+	fmt.Fprintf(s.events, "%s %s %s %s (x%d): %s\n",
+		eventTime(ev).UTC().Format(time.RFC3339), ev.Type, ev.Reason, object, count, ev.Message)
+	s.mu.Unlock()
+
+	if ev.Type == corev1.EventTypeWarning {
+		s.feed("%s %s: %s", ev.Reason, object, ev.Message)
+	}
+}
+
+// eventTime returns the most recent timestamp an event carries.
+func eventTime(ev *corev1.Event) time.Time {
+	switch {
+	case !ev.LastTimestamp.IsZero():
+		return ev.LastTimestamp.Time
+	case !ev.EventTime.IsZero():
+		return ev.EventTime.Time
+	default:
+		return ev.CreationTimestamp.Time
+	}
+}
+
+func (s *Streamer) onPod(obj any) {
+	pod, ok := obj.(*corev1.Pod)
+	if !ok {
+		return
+	}
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, cs := range statuses {
+		key := pod.Name + "/" + cs.Name
+
+		s.mu.Lock()
+		if s.events == nil {
+			s.mu.Unlock()
+			return
+		}
+		prevRestarts, known := s.restarts[key]
+		s.restarts[key] = cs.RestartCount
+		reason := ""
+		if cs.State.Waiting != nil && crashReasons[cs.State.Waiting.Reason] {
+			reason = cs.State.Waiting.Reason
+		}
+		newReason := reason != "" && s.waiting[key] != reason
+		s.waiting[key] = reason
+		s.mu.Unlock()
+
+		if known && cs.RestartCount > prevRestarts {
+			s.feed("pod/%s container %s restarted (%d)%s", pod.Name, cs.Name, cs.RestartCount, lastExit(cs))
+		}
+		if newReason {
+			s.feed("pod/%s container %s: %s", pod.Name, cs.Name, reason)
+		}
+
+		// The previous instance is only retrievable while it is the last
+		// one, so grab it whenever it was not followed live.
+		if last := cs.LastTerminationState.Terminated; last != nil && last.ContainerID != "" && cs.RestartCount > 0 {
+			s.follow(pod.Name, cs.Name, last.ContainerID, cs.RestartCount-1, true)
+		}
+		if cs.ContainerID != "" && (cs.State.Running != nil || cs.State.Terminated != nil) {
+			s.follow(pod.Name, cs.Name, cs.ContainerID, cs.RestartCount, false)
+		}
+	}
+}
+
+// lastExit describes how the previous instance of a container ended.
+func lastExit(cs corev1.ContainerStatus) string {
+	last := cs.LastTerminationState.Terminated
+	if last == nil {
+		return ""
+	}
+	return fmt.Sprintf(", last exit %d %s", last.ExitCode, last.Reason)
+}
+
+func (s *Streamer) onPodDelete(obj any) {
+	if tombstone, ok := obj.(cache.DeletedFinalStateUnknown); ok {
+		obj = tombstone.Obj
+	}
+	if pod, ok := obj.(*corev1.Pod); ok {
+		s.feed("pod/%s deleted", pod.Name)
+	}
+}
+
+// follow writes the logs of one container instance to
+// pods/<pod>/<container>.<restart>.log, once per container ID. previous
+// fetches the logs of the last terminated instance instead of following.
+func (s *Streamer) follow(pod, container, containerID string, restart int32, previous bool) {
+	s.mu.Lock()
+	if s.events == nil || s.following[containerID] {
+		s.mu.Unlock()
+		return
+	}
+	s.following[containerID] = true
+	s.wg.Add(1)
+	s.mu.Unlock()
+
+	go func() {
+		defer s.wg.Done()
+		dir := filepath.Join(s.opts.Dir, "pods", pod)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return
+		}
+		f, err := os.OpenFile(filepath.Join(dir, fmt.Sprintf("%s.%d.log", container, restart)),
+			os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+		if err != nil {
+			return
+		}
+		defer f.Close()
+
+		req := s.client.clientset.CoreV1().Pods(s.opts.Namespace).GetLogs(pod, &corev1.PodLogOptions{
+			Container:  container,
+			Follow:     !previous,
+			Previous:   previous,
+			Timestamps: true,
+		})
+		stream, err := req.Stream(s.ctx)
+		if err != nil {
+			if s.ctx.Err() == nil {
+				fmt.Fprintf(f, "# log stream unavailable: %v\n", err)
+			}
+			return
+		}
+		defer stream.Close()
+		_, _ = io.Copy(f, stream)
+	}()
+}
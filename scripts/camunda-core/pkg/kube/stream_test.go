@@ -0,0 +1,148 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package kube
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestStreamNamespace(t *testing.T) {
+	const ns = "matrix-eske"
+	ctx := context.Background()
+	clientset := fake.NewSimpleClientset()
+	dir := t.TempDir()
+
+	var mu sync.Mutex
+	var feed []string
+	s, err := NewClientForClientset(clientset, "test").StreamNamespace(ctx, StreamOptions{
+		Namespace: ns,
+		Dir:       dir,
+		OnFeed: func(line string) {
+			mu.Lock()
+			defer mu.Unlock()
+			feed = append(feed, line)
+		},
+	})
+	if err != nil {
+		t.Fatalf("StreamNamespace: %v", err)
+	}
+	defer s.Stop()
+
+	waitFor := func(what string, cond func() bool) {
+		t.Helper()
+		deadline := time.Now().Add(5 * time.Second)
+		for !cond() {
+			if time.Now().After(deadline) {
+				mu.Lock()
+				defer mu.Unlock()
+				t.Fatalf("timed out waiting for %s; feed=%q", what, feed)
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+	fileExists := func(rel string) func() bool {
+		return func() bool {
+			_, err := os.Stat(filepath.Join(dir, rel))
+			return err == nil
+		}
+	}
+	feedHas := func(substr string) func() bool {
+		return func() bool {
+			mu.Lock()
+			defer mu.Unlock()
+			for _, line := range feed {
+				if strings.Contains(line, substr) {
+					return true
+				}
+			}
+			return false
+		}
+	}
+
+	pod := &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "zeebe-0", Namespace: ns},
+		Status: corev1.PodStatus{ContainerStatuses: []corev1.ContainerStatus{{
+			Name:        "zeebe",
+			ContainerID: "containerd://first",
+			State:       corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+		}}},
+	}
+	if _, err := clientset.CoreV1().Pods(ns).Create(ctx, pod, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the first instance log", fileExists("pods/zeebe-0/zeebe.0.log"))
+
+	// The container restarts into a crash loop.
+	pod.Status.ContainerStatuses[0] = corev1.ContainerStatus{
+		Name:         "zeebe",
+		RestartCount: 1,
+		State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+		LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+			ContainerID: "containerd://first", ExitCode: 137, Reason: "OOMKilled",
+		}},
+	}
+	if _, err := clientset.CoreV1().Pods(ns).UpdateStatus(ctx, pod, metav1.UpdateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the restart notice", feedHas("pod/zeebe-0 container zeebe restarted (1), last exit 137 OOMKilled"))
+	waitFor("the crash loop notice", feedHas("pod/zeebe-0 container zeebe: CrashLoopBackOff"))
+
+	event := &corev1.Event{
+		ObjectMeta:     metav1.ObjectMeta{Name: "zeebe-0.backoff", Namespace: ns},
+		InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "zeebe-0"},
+		Type:           corev1.EventTypeWarning,
+		Reason:         "BackOff",
+		Message:        "Back-off restarting failed container",
+		Count:          1,
+	}
+	if _, err := clientset.CoreV1().Events(ns).Create(ctx, event, metav1.CreateOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the warning event", feedHas("BackOff Pod/zeebe-0: Back-off restarting failed container"))
+
+	if err := clientset.CoreV1().Pods(ns).Delete(ctx, "zeebe-0", metav1.DeleteOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("the deletion notice", feedHas("pod/zeebe-0 deleted"))
+
+	s.Stop()
+
+	// The deleted pod's logs survive, and the already followed instance is
+	// not fetched a second time as "previous".
+	logs, err := os.ReadFile(filepath.Join(dir, "pods", "zeebe-0", "zeebe.0.log"))
+	if err != nil {
+		t.Fatalf("read pod log: %v", err)
+	}
+	if got := string(logs); got != "fake logs" {
+		t.Errorf("pod log = %q, want the single followed stream", got)
+	}
+	events, err := os.ReadFile(filepath.Join(dir, "events.log"))
+	if err != nil {
+		t.Fatalf("read events log: %v", err)
+	}
+	if !strings.Contains(string(events), "Warning BackOff Pod/zeebe-0 (x1): Back-off restarting failed container") {
+		t.Errorf("events log missing the warning:\n%s", events)
+	}
+}
@@ -18,7 +18,8 @@
 //     across the Helm v3→v4 migration by Camunda minor.
 //   - CliffGroups / ArtifactHubChanges: the artifacthub.io/changes annotation
 //     block parsed from the git-cliff RELEASE-NOTES.md sections via the
-//     keep-a-changelog map.
+//     keep-a-changelog map, plus one entry per image change of the chart's
+//     version-matrix image diff.
 //   - ParseChartIdentity / AppMinor / AppStripLastSegment: Chart.yaml field reads.
 package releasenotes
 
@@ -29,6 +30,7 @@ import (
 	"strings"
 
 	"gopkg.in/yaml.v3"
+	"scripts/camunda-core/pkg/versionmatrix"
 )
 
 // ChartIdentity holds the Chart.yaml fields the release-notes flow reads.
@@ -148,9 +150,12 @@ var trailingParen = regexp.MustCompile(` \(.+\)$`)
 //	    - kind: added
 //	      description: "..."
 //
+// imageChanges (the `version-matrix --diff --json` changes against the previous
+// chart version) are appended after the commit entries, one per image.
+//
 // hasItems reports whether any change entries were produced; callers decide what
 // an empty set means.
-func ArtifactHubChanges(releaseNotesMD string, orderedGroups []string) (block string, hasItems bool) {
+func ArtifactHubChanges(releaseNotesMD string, orderedGroups []string, imageChanges ...versionmatrix.ImageChange) (block string, hasItems bool) {
 	var b strings.Builder
 	b.WriteString("annotations:\n  artifacthub.io/changes: |\n")
 	for _, group := range orderedGroups {
@@ -163,9 +168,33 @@ func ArtifactHubChanges(releaseNotesMD string, orderedGroups []string) (block st
 			hasItems = true
 		}
 	}
+	for _, c := range imageChanges {
+		kind, desc := imageChangeEntry(c)
+		fmt.Fprintf(&b, "    - kind: %s\n      description: \"%s\"\n", kind, desc)
+		hasItems = true
+	}
 	return b.String(), hasItems
 }
 
+// imageChangeEntry maps an image change onto an artifacthub.io/changes kind
+// and description.
+func imageChangeEntry(c versionmatrix.ImageChange) (kind, description string) {
+	suffix := ""
+	if c.Enterprise {
+		suffix = " (enterprise)"
+	}
+	switch c.Kind {
+	case versionmatrix.ImageAdded:
+		return "added", fmt.Sprintf("Add image %s%s", c.To, suffix)
+	case versionmatrix.ImageRemoved:
+		return "removed", fmt.Sprintf("Remove image %s%s", c.From, suffix)
+	case versionmatrix.ImageDigestOnly:
+		return "changed", fmt.Sprintf("Update image %s%s to digest %s", c.Image, suffix, versionmatrix.ImageVersion(c.To))
+	default:
+		return "changed", fmt.Sprintf("Update image %s%s from %s to %s", c.Image, suffix, versionmatrix.ImageVersion(c.From), versionmatrix.ImageVersion(c.To))
+	}
+}
+
 // sectionBullets returns the bullet lines of the RELEASE-NOTES.md section whose
 // heading matches `^#+\s<group>`, up to the next heading, with the leading
 // "- " stripped from each bullet.
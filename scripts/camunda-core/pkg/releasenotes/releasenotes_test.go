@@ -17,6 +17,8 @@ package releasenotes
 import (
 	"strings"
 	"testing"
+
+	"scripts/camunda-core/pkg/versionmatrix"
 )
 
 func TestHelmCLIVersion(t *testing.T) {
@@ -116,6 +118,33 @@ func TestArtifactHubChangesEmpty(t *testing.T) {
 	}
 }
 
+func TestArtifactHubChangesWithImageChanges(t *testing.T) {
+	md := "### Documentation\n\n- update README (#6400)\n"
+	changes := []versionmatrix.ImageChange{
+		{Kind: versionmatrix.ImageTagBump, Image: "docker.io/camunda/camunda", From: "docker.io/camunda/camunda:8.8.0", To: "docker.io/camunda/camunda:8.8.1"},
+		{Kind: versionmatrix.ImageAdded, Image: "docker.io/camunda/hub", To: "docker.io/camunda/hub:8.8.1"},
+		{Kind: versionmatrix.ImageRemoved, Image: "registry.camunda.cloud/vendor-ee/os-shell", From: "registry.camunda.cloud/vendor-ee/os-shell:12", Enterprise: true},
+	}
+	block, has := ArtifactHubChanges(md, []string{"Features", "Documentation"}, changes...)
+	if !has {
+		t.Fatal("image changes alone must produce change items")
+	}
+	want := strings.Join([]string{
+		"annotations:",
+		"  artifacthub.io/changes: |",
+		"    - kind: changed",
+		"      description: \"Update image docker.io/camunda/camunda from 8.8.0 to 8.8.1\"",
+		"    - kind: added",
+		"      description: \"Add image docker.io/camunda/hub:8.8.1\"",
+		"    - kind: removed",
+		"      description: \"Remove image registry.camunda.cloud/vendor-ee/os-shell:12 (enterprise)\"",
+		"",
+	}, "\n")
+	if block != want {
+		t.Errorf("block mismatch:\n got:\n%s\nwant:\n%s", block, want)
+	}
+}
+
 func TestCleanDescription(t *testing.T) {
 	cases := map[string]string{
 		"foo (#1234)":              "foo",
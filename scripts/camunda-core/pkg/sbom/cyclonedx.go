@@ -0,0 +1,235 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+)
+
+// SpecVersion is the CycloneDX specification version emitted.
+const SpecVersion = "1.5"
+
+// BOM is the subset of a CycloneDX JSON document the chart SBOM uses.
+type BOM struct {
+	BOMFormat       string          `json:"bomFormat"`
+	SpecVersion     string          `json:"specVersion"`
+	SerialNumber    string          `json:"serialNumber"`
+	Version         int             `json:"version"`
+	Metadata        Metadata        `json:"metadata"`
+	Components      []Component     `json:"components"`
+	Vulnerabilities []Vulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// Metadata describes the subject of the BOM: the chart.
+type Metadata struct {
+	Component Component `json:"component"`
+}
+
+// Component is a CycloneDX component (the chart, or one of its images).
+type Component struct {
+	BOMRef   string          `json:"bom-ref"`
+	Type     string          `json:"type"`
+	Name     string          `json:"name"`
+	Version  string          `json:"version,omitempty"`
+	PURL     string          `json:"purl,omitempty"`
+	Hashes   []Hash          `json:"hashes,omitempty"`
+	Licenses []LicenseChoice `json:"licenses,omitempty"`
+}
+
+// Hash is a component content hash.
+type Hash struct {
+	Alg     string `json:"alg"`
+	Content string `json:"content"`
+}
+
+// LicenseChoice is either a single SPDX license id or an SPDX expression.
+type LicenseChoice struct {
+	License    *License `json:"license,omitempty"`
+	Expression string   `json:"expression,omitempty"`
+}
+
+// License identifies a license by SPDX id.
+type License struct {
+	ID string `json:"id"`
+}
+
+// Vulnerability is a CycloneDX vulnerability affecting one or more images.
+type Vulnerability struct {
+	ID             string   `json:"id"`
+	Source         *Source  `json:"source,omitempty"`
+	Ratings        []Rating `json:"ratings,omitempty"`
+	Recommendation string   `json:"recommendation,omitempty"`
+	Affects        []Affect `json:"affects"`
+}
+
+// Source names the scanner a vulnerability was imported from.
+type Source struct {
+	Name string `json:"name"`
+}
+
+// Rating carries a vulnerability severity.
+type Rating struct {
+	Severity string `json:"severity"`
+}
+
+// Affect references an affected component by bom-ref.
+type Affect struct {
+	Ref string `json:"ref"`
+}
+
+// Chart identifies the chart the BOM describes.
+type Chart struct {
+	Name    string
+	Version string
+	License string // SPDX id or expression; empty omits it
+}
+
+// Build assembles the chart BOM. Images become container components keyed by
+// their recorded reference; findings are grouped per vulnerability id. The
+// output is deterministic for identical input (sorted components and
+// vulnerabilities, content-derived serial number) so regenerating an
+// unchanged SBOM produces no diff.
+func Build(chart Chart, images []Image, findings []Finding) *BOM {
+	chartComponent := Component{
+		BOMRef:   "pkg:helm/" + chart.Name + "@" + chart.Version,
+		Type:     "application",
+		Name:     chart.Name,
+		Version:  chart.Version,
+		PURL:     "pkg:helm/" + chart.Name + "@" + chart.Version,
+		Licenses: licenseChoices(nilIfEmpty(chart.License)),
+	}
+
+	components := make([]Component, 0, len(images))
+	for _, img := range images {
+		components = append(components, imageComponent(img))
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i].BOMRef < components[j].BOMRef })
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s@%s\n", chart.Name, chart.Version)
+	for _, c := range components {
+		fmt.Fprintf(h, "%s %s\n", c.BOMRef, c.PURL)
+	}
+
+	return &BOM{
+		BOMFormat:       "CycloneDX",
+		SpecVersion:     SpecVersion,
+		SerialNumber:    "urn:uuid:" + uuidFromHash(h.Sum(nil)),
+		Version:         1,
+		Metadata:        Metadata{Component: chartComponent},
+		Components:      components,
+		Vulnerabilities: groupFindings(findings),
+	}
+}
+
+func imageComponent(img Image) Component {
+	c := Component{BOMRef: img.Ref, Type: "container", Name: img.Ref, Licenses: licenseChoices(img.Licenses)}
+	ref, err := ParseReference(img.Ref)
+	if err != nil {
+		return c
+	}
+	c.Name = ref.Name()
+	c.Version = ref.Tag
+	digest := img.Digest
+	if digest == "" {
+		digest = ref.Digest
+	}
+	if digest == "" {
+		return c
+	}
+	if alg, sum, ok := strings.Cut(digest, ":"); ok && alg == "sha256" {
+		c.Hashes = []Hash{{Alg: "SHA-256", Content: sum}}
+	}
+	// pkg:oci/<name>@<digest>?repository_url=<registry/repository>&tag=<tag>
+	segments := strings.Split(ref.Repository, "/")
+	q := url.Values{"repository_url": {ref.Name()}}
+	if ref.Tag != "" {
+		q.Set("tag", ref.Tag)
+	}
+	c.PURL = "pkg:oci/" + segments[len(segments)-1] + "@" + url.QueryEscape(digest) + "?" + q.Encode()
+	return c
+}
+
+// licenseChoices maps declared licenses onto CycloneDX: a plain SPDX id
+// becomes a license entry, anything composite (AND/OR/WITH) an expression.
+func licenseChoices(licenses []string) []LicenseChoice {
+	var out []LicenseChoice
+	for _, l := range licenses {
+		l = strings.TrimSpace(l)
+		switch {
+		case l == "":
+		case strings.ContainsAny(l, " ()"):
+			out = append(out, LicenseChoice{Expression: l})
+		default:
+			out = append(out, LicenseChoice{License: &License{ID: l}})
+		}
+	}
+	return out
+}
+
+func nilIfEmpty(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return []string{s}
+}
+
+// uuidFromHash formats the first 16 bytes of a hash as a name-based (version
+// 5 layout) UUID.
+func uuidFromHash(sum []byte) string {
+	b := make([]byte, 16)
+	copy(b, sum)
+	b[6] = (b[6] & 0x0f) | 0x50
+	b[8] = (b[8] & 0x3f) | 0x80
+	s := hex.EncodeToString(b)
+	return s[0:8] + "-" + s[8:12] + "-" + s[12:16] + "-" + s[16:20] + "-" + s[20:32]
+}
+
+// Marshal renders the BOM as 2-space-indented JSON without HTML escaping,
+// with a trailing newline.
+func (b *BOM) Marshal() ([]byte, error) {
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(b); err != nil {
+		return nil, fmt.Errorf("encode sbom: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ReadBOM loads a CycloneDX JSON document written by Marshal.
+func ReadBOM(path string) (*BOM, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read sbom %s: %w", path, err)
+	}
+	var b BOM
+	if err := json.Unmarshal(data, &b); err != nil {
+		return nil, fmt.Errorf("parse sbom %s: %w", path, err)
+	}
+	if b.BOMFormat != "CycloneDX" {
+		return nil, fmt.Errorf("parse sbom %s: not a CycloneDX document", path)
+	}
+	return &b, nil
+}
@@ -0,0 +1,344 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package sbom builds a CycloneDX software bill of materials for a packaged
+// Camunda chart: the chart itself plus every image of its chartmeta image set,
+// each pinned to the registry digest its tag resolves to and carrying the
+// license declared in the image's OCI annotations.
+//
+// Vulnerability data is never fetched live. Findings are imported from Trivy or
+// Grype JSON reports produced offline (against a pinned vulnerability DB) so a
+// release PR can show a reproducible CVE delta against the previous chart
+// version's SBOM.
+//
+// The registry transport is injectable (the Do field) so digest and license
+// resolution can be exercised with canned responses and no network.
+package sbom
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// LicensesAnnotation is the OCI annotation (and image config label) carrying
+// the image's SPDX license expression.
+const LicensesAnnotation = "org.opencontainers.image.licenses"
+
+// Manifest media types accepted when resolving a tag. An index is preferred so
+// the recorded digest is the multi-arch digest users pull.
+var manifestAccept = strings.Join([]string{
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.oci.image.manifest.v1+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+}, ", ")
+
+// Reference is a parsed image reference.
+type Reference struct {
+	Registry   string // e.g. docker.io, registry.camunda.cloud
+	Repository string // e.g. camunda/zeebe, library/postgres
+	Tag        string
+	Digest     string
+}
+
+// ParseReference splits an image reference into registry, repository, tag and
+// digest. A reference without a registry host is a Docker Hub reference, and a
+// single-segment Docker Hub repository lives under library/.
+func ParseReference(ref string) (Reference, error) {
+	var r Reference
+	rest := ref
+	if i := strings.Index(rest, "@"); i >= 0 {
+		r.Digest = rest[i+1:]
+		rest = rest[:i]
+	}
+	if i := strings.LastIndex(rest, ":"); i > strings.LastIndex(rest, "/") {
+		r.Tag = rest[i+1:]
+		rest = rest[:i]
+	}
+	if first, remainder, ok := strings.Cut(rest, "/"); ok && (strings.ContainsAny(first, ".:") || first == "localhost") {
+		r.Registry, rest = first, remainder
+	} else {
+		r.Registry = "docker.io"
+	}
+	if r.Registry == "docker.io" && !strings.Contains(rest, "/") {
+		rest = "library/" + rest
+	}
+	r.Repository = rest
+	if r.Repository == "" || (r.Tag == "" && r.Digest == "") {
+		return Reference{}, fmt.Errorf("invalid image reference %q: need a repository and a tag or digest", ref)
+	}
+	return r, nil
+}
+
+// Name returns the registry-qualified repository without tag or digest.
+func (r Reference) Name() string { return r.Registry + "/" + r.Repository }
+
+// apiHost maps a registry to the host serving its distribution API.
+func (r Reference) apiHost() string {
+	if r.Registry == "docker.io" {
+		return "registry-1.docker.io"
+	}
+	return r.Registry
+}
+
+// Image is an image reference resolved against its registry.
+type Image struct {
+	Ref      string   // the reference as recorded in the chart image set
+	Digest   string   // manifest (or index) digest the tag resolved to
+	Licenses []string // from the org.opencontainers.image.licenses annotation/label
+}
+
+// Resolver resolves image tags to digests and reads their license annotations
+// via the OCI distribution API.
+type Resolver struct {
+	// Credentials returns basic-auth credentials for a registry, or empty
+	// strings for anonymous access.
+	Credentials func(registry string) (user, pass string)
+	// Do issues an HTTP request. Defaults to http.DefaultClient.Do; tests
+	// inject a fake registry.
+	Do func(*http.Request) (*http.Response, error)
+
+	tokens map[string]string // registry+repository → bearer token
+}
+
+// NewResolver returns a Resolver using the default HTTP client. Enterprise
+// images on registry.camunda.cloud are pulled with HARBOR_REGISTRY_USER /
+// HARBOR_REGISTRY_PASSWORD; every other registry is accessed anonymously.
+func NewResolver() *Resolver {
+	return &Resolver{
+		Credentials: func(registry string) (string, string) {
+			if registry == "registry.camunda.cloud" {
+				return os.Getenv("HARBOR_REGISTRY_USER"), os.Getenv("HARBOR_REGISTRY_PASSWORD")
+			}
+			return "", ""
+		},
+		Do: http.DefaultClient.Do,
+	}
+}
+
+// manifest is the subset of an OCI manifest or index we read.
+type manifest struct {
+	MediaType   string            `json:"mediaType"`
+	Annotations map[string]string `json:"annotations"`
+	Config      struct {
+		Digest string `json:"digest"`
+	} `json:"config"`
+	Manifests []struct {
+		Digest   string `json:"digest"`
+		Platform struct {
+			OS           string `json:"os"`
+			Architecture string `json:"architecture"`
+		} `json:"platform"`
+	} `json:"manifests"`
+}
+
+// imageConfig is the subset of an image config blob we read.
+type imageConfig struct {
+	Config struct {
+		Labels map[string]string `json:"Labels"`
+	} `json:"config"`
+}
+
+// Resolve returns ref's digest and licenses. An already-pinned reference keeps
+// its digest; its manifest is still read for the license. Licenses are taken
+// from the first of: the index/manifest annotations, the linux/amd64 manifest's
+// annotations, the image config labels.
+func (r *Resolver) Resolve(ref string) (Image, error) {
+	parsed, err := ParseReference(ref)
+	if err != nil {
+		return Image{}, err
+	}
+	reference := parsed.Digest
+	if reference == "" {
+		reference = parsed.Tag
+	}
+	body, digest, err := r.get(parsed, "manifests/"+reference, manifestAccept)
+	if err != nil {
+		return Image{}, fmt.Errorf("resolve %s: %w", ref, err)
+	}
+	if parsed.Digest != "" {
+		digest = parsed.Digest
+	}
+	img := Image{Ref: ref, Digest: digest}
+
+	var m manifest
+	if err := json.Unmarshal(body, &m); err != nil {
+		return Image{}, fmt.Errorf("decode manifest of %s: %w", ref, err)
+	}
+	if lic := m.Annotations[LicensesAnnotation]; lic != "" {
+		img.Licenses = []string{lic}
+		return img, nil
+	}
+	if len(m.Manifests) > 0 {
+		child := m.Manifests[0].Digest
+		for _, d := range m.Manifests {
+			if d.Platform.OS == "linux" && d.Platform.Architecture == "amd64" {
+				child = d.Digest
+				break
+			}
+		}
+		if body, _, err = r.get(parsed, "manifests/"+child, manifestAccept); err != nil {
+			return Image{}, fmt.Errorf("read platform manifest of %s: %w", ref, err)
+		}
+		m = manifest{}
+		if err := json.Unmarshal(body, &m); err != nil {
+			return Image{}, fmt.Errorf("decode platform manifest of %s: %w", ref, err)
+		}
+		if lic := m.Annotations[LicensesAnnotation]; lic != "" {
+			img.Licenses = []string{lic}
+			return img, nil
+		}
+	}
+	if m.Config.Digest == "" {
+		return img, nil
+	}
+	if body, _, err = r.get(parsed, "blobs/"+m.Config.Digest, ""); err != nil {
+		return Image{}, fmt.Errorf("read config of %s: %w", ref, err)
+	}
+	var cfg imageConfig
+	if err := json.Unmarshal(body, &cfg); err != nil {
+		return Image{}, fmt.Errorf("decode config of %s: %w", ref, err)
+	}
+	if lic := cfg.Config.Labels[LicensesAnnotation]; lic != "" {
+		img.Licenses = []string{lic}
+	}
+	return img, nil
+}
+
+// get fetches /v2/<repository>/<path> and returns the body and its content
+// digest (Docker-Content-Digest, or the sha256 of the body when the registry
+// omits the header). A 401 triggers the bearer token flow once.
+func (r *Resolver) get(ref Reference, path, accept string) ([]byte, string, error) {
+	url := fmt.Sprintf("https://%s/v2/%s/%s", ref.apiHost(), ref.Repository, path)
+	resp, err := r.do(ref, url, accept)
+	if err != nil {
+		return nil, "", err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		challenge := resp.Header.Get("WWW-Authenticate")
+		resp.Body.Close()
+		if err := r.authorize(ref, challenge); err != nil {
+			return nil, "", err
+		}
+		if resp, err = r.do(ref, url, accept); err != nil {
+			return nil, "", err
+		}
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return nil, "", fmt.Errorf("GET %s: HTTP %d", url, resp.StatusCode)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("read %s: %w", url, err)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		sum := sha256.Sum256(body)
+		digest = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return body, digest, nil
+}
+
+func (r *Resolver) do(ref Reference, url, accept string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if token := r.tokens[ref.Name()]; token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	} else if user, pass := r.credentials(ref.Registry); user != "" || pass != "" {
+		req.SetBasicAuth(user, pass)
+	}
+	return r.Do(req)
+}
+
+func (r *Resolver) credentials(registry string) (string, string) {
+	if r.Credentials == nil {
+		return "", ""
+	}
+	return r.Credentials(registry)
+}
+
+// authorize answers a `Bearer realm=...,service=...,scope=...` challenge by
+// fetching a pull token (with basic auth when credentials are configured) and
+// caching it for the repository.
+func (r *Resolver) authorize(ref Reference, challenge string) error {
+	scheme, params, _ := strings.Cut(challenge, " ")
+	if !strings.EqualFold(scheme, "Bearer") {
+		return fmt.Errorf("registry %s: unsupported auth challenge %q", ref.Registry, challenge)
+	}
+	fields := map[string]string{}
+	for _, part := range strings.Split(params, ",") {
+		k, v, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if ok {
+			fields[k] = strings.Trim(v, `"`)
+		}
+	}
+	if fields["realm"] == "" {
+		return fmt.Errorf("registry %s: auth challenge without realm", ref.Registry)
+	}
+	req, err := http.NewRequest(http.MethodGet, fields["realm"], nil)
+	if err != nil {
+		return err
+	}
+	q := req.URL.Query()
+	if fields["service"] != "" {
+		q.Set("service", fields["service"])
+	}
+	scope := fields["scope"]
+	if scope == "" {
+		scope = "repository:" + ref.Repository + ":pull"
+	}
+	q.Set("scope", scope)
+	req.URL.RawQuery = q.Encode()
+	if user, pass := r.credentials(ref.Registry); user != "" || pass != "" {
+		req.SetBasicAuth(user, pass)
+	}
+	resp, err := r.Do(req)
+	if err != nil {
+		return fmt.Errorf("fetch token for %s: %w", ref.Name(), err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("fetch token for %s: HTTP %d", ref.Name(), resp.StatusCode)
+	}
+	var tok struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&tok); err != nil {
+		return fmt.Errorf("decode token for %s: %w", ref.Name(), err)
+	}
+	if tok.Token == "" {
+		tok.Token = tok.AccessToken
+	}
+	if tok.Token == "" {
+		return fmt.Errorf("fetch token for %s: empty token", ref.Name())
+	}
+	if r.tokens == nil {
+		r.tokens = map[string]string{}
+	}
+	r.tokens[ref.Name()] = tok.Token
+	return nil
+}
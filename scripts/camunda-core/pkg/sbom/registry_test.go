@@ -0,0 +1,137 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestParseReference(t *testing.T) {
+	cases := []struct {
+		in   string
+		want Reference
+	}{
+		{"docker.io/camunda/zeebe:8.8.0", Reference{"docker.io", "camunda/zeebe", "8.8.0", ""}},
+		{"postgres:16", Reference{"docker.io", "library/postgres", "16", ""}},
+		{"registry.camunda.cloud/vendor-ee/elasticsearch:8.19.0", Reference{"registry.camunda.cloud", "vendor-ee/elasticsearch", "8.19.0", ""}},
+		{"localhost:5000/x/y@sha256:abc", Reference{"localhost:5000", "x/y", "", "sha256:abc"}},
+		{"quay.io/keycloak/keycloak:26.0@sha256:def", Reference{"quay.io", "keycloak/keycloak", "26.0", "sha256:def"}},
+	}
+	for _, c := range cases {
+		got, err := ParseReference(c.in)
+		if err != nil {
+			t.Fatalf("ParseReference(%q): %v", c.in, err)
+		}
+		if got != c.want {
+			t.Errorf("ParseReference(%q) = %+v, want %+v", c.in, got, c.want)
+		}
+	}
+	if _, err := ParseReference("camunda/zeebe"); err == nil {
+		t.Error("a reference without tag or digest must be rejected")
+	}
+}
+
+func respond(code int, body string, header http.Header) (*http.Response, error) {
+	if header == nil {
+		header = make(http.Header)
+	}
+	return &http.Response{StatusCode: code, Body: io.NopCloser(strings.NewReader(body)), Header: header}, nil
+}
+
+// fakeRegistry serves a Docker Hub-style registry: manifests require a bearer
+// token, the tag resolves to an index without annotations, and the license is
+// only on the amd64 image's config labels.
+func fakeRegistry(t *testing.T) (*Resolver, *[]string) {
+	t.Helper()
+	var seen []string
+	r := &Resolver{Do: func(req *http.Request) (*http.Response, error) {
+		seen = append(seen, req.URL.Host+req.URL.Path)
+		if req.URL.Host == "auth.docker.io" {
+			if got := req.URL.Query().Get("scope"); got != "repository:camunda/zeebe:pull" {
+				t.Errorf("token scope = %q", got)
+			}
+			return respond(200, `{"token":"t0k"}`, nil)
+		}
+		if req.Header.Get("Authorization") != "Bearer t0k" {
+			return respond(401, "", http.Header{"Www-Authenticate": {`Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:camunda/zeebe:pull"`}})
+		}
+		switch req.URL.Path {
+		case "/v2/camunda/zeebe/manifests/8.8.0":
+			return respond(200, `{"mediaType":"application/vnd.oci.image.index.v1+json","manifests":[
+				{"digest":"sha256:arm","platform":{"os":"linux","architecture":"arm64"}},
+				{"digest":"sha256:amd","platform":{"os":"linux","architecture":"amd64"}}]}`,
+				http.Header{"Docker-Content-Digest": {"sha256:index"}})
+		case "/v2/camunda/zeebe/manifests/sha256:amd":
+			return respond(200, `{"config":{"digest":"sha256:cfg"}}`, nil)
+		case "/v2/camunda/zeebe/blobs/sha256:cfg":
+			return respond(200, `{"config":{"Labels":{"org.opencontainers.image.licenses":"Camunda License 1.0"}}}`, nil)
+		}
+		return respond(404, "", nil)
+	}}
+	return r, &seen
+}
+
+func TestResolveFollowsIndexToConfigLabels(t *testing.T) {
+	r, seen := fakeRegistry(t)
+	img, err := r.Resolve("docker.io/camunda/zeebe:8.8.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Digest != "sha256:index" {
+		t.Errorf("digest = %q, want the index digest", img.Digest)
+	}
+	if len(img.Licenses) != 1 || img.Licenses[0] != "Camunda License 1.0" {
+		t.Errorf("licenses = %v", img.Licenses)
+	}
+	if (*seen)[0] != "registry-1.docker.io/v2/camunda/zeebe/manifests/8.8.0" {
+		t.Errorf("docker.io must be served from registry-1.docker.io, first request %q", (*seen)[0])
+	}
+	tokens := 0
+	for _, s := range *seen {
+		if strings.HasPrefix(s, "auth.docker.io") {
+			tokens++
+		}
+	}
+	if tokens != 1 {
+		t.Errorf("expected the token to be fetched once and cached, got %d fetches", tokens)
+	}
+}
+
+func TestResolvePrefersManifestAnnotationAndPinnedDigest(t *testing.T) {
+	r := &Resolver{Do: func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path != "/v2/camunda/connectors/manifests/sha256:pinned" {
+			t.Errorf("unexpected request %s", req.URL.Path)
+		}
+		return respond(200, `{"annotations":{"org.opencontainers.image.licenses":"Apache-2.0"}}`,
+			http.Header{"Docker-Content-Digest": {"sha256:other"}})
+	}}
+	img, err := r.Resolve("camunda/connectors:8.8.0@sha256:pinned")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if img.Digest != "sha256:pinned" || len(img.Licenses) != 1 || img.Licenses[0] != "Apache-2.0" {
+		t.Errorf("unexpected image %+v", img)
+	}
+}
+
+func TestResolveReportsMissingTag(t *testing.T) {
+	r := &Resolver{Do: func(*http.Request) (*http.Response, error) { return respond(404, "", nil) }}
+	if _, err := r.Resolve("camunda/zeebe:0.0.0"); err == nil || !strings.Contains(err.Error(), "HTTP 404") {
+		t.Fatalf("expected a 404 error, got %v", err)
+	}
+}
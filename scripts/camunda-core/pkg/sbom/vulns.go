@@ -0,0 +1,331 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Finding is one vulnerable package in one image, as reported by a scanner.
+type Finding struct {
+	ID        string // CVE/GHSA id
+	Severity  string // CycloneDX severity: critical, high, medium, low, info, unknown
+	Package   string
+	Installed string
+	FixedIn   string
+	Image     string // chart image reference (bom-ref) the finding belongs to
+	Scanner   string // trivy | grype
+}
+
+// Report is an imported scanner report: the scanned image and its findings.
+type Report struct {
+	Target   string
+	Findings []Finding
+}
+
+// trivyReport is the subset of `trivy image --format json` we read.
+type trivyReport struct {
+	ArtifactName string `json:"ArtifactName"`
+	Results      []struct {
+		Vulnerabilities []struct {
+			VulnerabilityID  string `json:"VulnerabilityID"`
+			PkgName          string `json:"PkgName"`
+			InstalledVersion string `json:"InstalledVersion"`
+			FixedVersion     string `json:"FixedVersion"`
+			Severity         string `json:"Severity"`
+		} `json:"Vulnerabilities"`
+	} `json:"Results"`
+}
+
+// grypeReport is the subset of `grype -o json` we read.
+type grypeReport struct {
+	Matches []struct {
+		Vulnerability struct {
+			ID       string `json:"id"`
+			Severity string `json:"severity"`
+			Fix      struct {
+				Versions []string `json:"versions"`
+			} `json:"fix"`
+		} `json:"vulnerability"`
+		Artifact struct {
+			Name    string `json:"name"`
+			Version string `json:"version"`
+		} `json:"artifact"`
+	} `json:"matches"`
+	Source struct {
+		Target json.RawMessage `json:"target"`
+	} `json:"source"`
+}
+
+// ParseReport decodes a Trivy or Grype JSON report, detected by its top-level
+// keys. The reports are expected to come from an offline scan against a pinned
+// vulnerability DB (trivy --skip-db-update / grype with GRYPE_DB_AUTO_UPDATE=false).
+func ParseReport(data []byte) (Report, error) {
+	var keys map[string]json.RawMessage
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return Report{}, fmt.Errorf("decode scanner report: %w", err)
+	}
+	switch {
+	case keys["ArtifactName"] != nil:
+		var tr trivyReport
+		if err := json.Unmarshal(data, &tr); err != nil {
+			return Report{}, fmt.Errorf("decode trivy report: %w", err)
+		}
+		r := Report{Target: tr.ArtifactName}
+		for _, res := range tr.Results {
+			for _, v := range res.Vulnerabilities {
+				r.Findings = append(r.Findings, Finding{
+					ID: v.VulnerabilityID, Severity: normalizeSeverity(v.Severity),
+					Package: v.PkgName, Installed: v.InstalledVersion, FixedIn: v.FixedVersion,
+					Scanner: "trivy",
+				})
+			}
+		}
+		return r, nil
+	case keys["matches"] != nil:
+		var gr grypeReport
+		if err := json.Unmarshal(data, &gr); err != nil {
+			return Report{}, fmt.Errorf("decode grype report: %w", err)
+		}
+		r := Report{Target: grypeTarget(gr.Source.Target)}
+		for _, m := range gr.Matches {
+			r.Findings = append(r.Findings, Finding{
+				ID: m.Vulnerability.ID, Severity: normalizeSeverity(m.Vulnerability.Severity),
+				Package: m.Artifact.Name, Installed: m.Artifact.Version,
+				FixedIn: strings.Join(m.Vulnerability.Fix.Versions, ", "),
+				Scanner: "grype",
+			})
+		}
+		return r, nil
+	default:
+		return Report{}, fmt.Errorf("unrecognized scanner report: expected a Trivy (ArtifactName) or Grype (matches) document")
+	}
+}
+
+// grypeTarget reads the scanned image name from source.target, which is an
+// object with userInput for image scans and a plain string otherwise.
+func grypeTarget(raw json.RawMessage) string {
+	var obj struct {
+		UserInput string `json:"userInput"`
+	}
+	if json.Unmarshal(raw, &obj) == nil && obj.UserInput != "" {
+		return obj.UserInput
+	}
+	var s string
+	_ = json.Unmarshal(raw, &s)
+	return s
+}
+
+func normalizeSeverity(s string) string {
+	switch s = strings.ToLower(s); s {
+	case "critical", "high", "medium", "low", "info", "none":
+		return s
+	case "negligible":
+		return "info"
+	default:
+		return "unknown"
+	}
+}
+
+// LoadReports reads every *.json scanner report in dir (or the single file
+// when path is a file).
+func LoadReports(path string) ([]Report, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("read vulnerability reports: %w", err)
+	}
+	files := []string{path}
+	if info.IsDir() {
+		if files, err = filepath.Glob(filepath.Join(path, "*.json")); err != nil {
+			return nil, err
+		}
+		sort.Strings(files)
+	}
+	var reports []Report
+	for _, f := range files {
+		data, err := os.ReadFile(f)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", f, err)
+		}
+		r, err := ParseReport(data)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", f, err)
+		}
+		reports = append(reports, r)
+	}
+	return reports, nil
+}
+
+// MatchFindings attributes each report to the chart image it scanned and
+// returns the findings tagged with that image's reference. Report targets are
+// compared after reference normalization, so `camunda/zeebe:8.8.0` matches
+// `docker.io/camunda/zeebe:8.8.0`. Reports for images outside the chart's
+// image set are returned as unmatched targets.
+func MatchFindings(images []Image, reports []Report) (findings []Finding, unmatched []string) {
+	byKey := map[string]string{}
+	for _, img := range images {
+		if ref, err := ParseReference(img.Ref); err == nil {
+			byKey[referenceKey(ref)] = img.Ref
+			if img.Digest != "" {
+				byKey[ref.Name()+"@"+img.Digest] = img.Ref
+			}
+		}
+	}
+	for _, r := range reports {
+		ref, err := ParseReference(r.Target)
+		image, ok := byKey[referenceKey(ref)]
+		if err != nil || !ok {
+			unmatched = append(unmatched, r.Target)
+			continue
+		}
+		for _, f := range r.Findings {
+			f.Image = image
+			findings = append(findings, f)
+		}
+	}
+	return findings, unmatched
+}
+
+func referenceKey(r Reference) string {
+	if r.Digest != "" {
+		return r.Name() + "@" + r.Digest
+	}
+	return r.Name() + ":" + r.Tag
+}
+
+var severityRank = map[string]int{"critical": 0, "high": 1, "medium": 2, "low": 3, "info": 4, "none": 5, "unknown": 6}
+
+// groupFindings folds findings into one CycloneDX vulnerability per id,
+// affecting every image that reported it, rated at the highest severity seen.
+func groupFindings(findings []Finding) []Vulnerability {
+	byID := map[string]*Vulnerability{}
+	var ids []string
+	for _, f := range findings {
+		v, ok := byID[f.ID]
+		if !ok {
+			v = &Vulnerability{ID: f.ID, Source: &Source{Name: f.Scanner}, Ratings: []Rating{{Severity: f.Severity}}}
+			byID[f.ID] = v
+			ids = append(ids, f.ID)
+		}
+		if severityRank[f.Severity] < severityRank[v.Ratings[0].Severity] {
+			v.Ratings[0].Severity = f.Severity
+		}
+		if !affects(*v, f.Image) {
+			v.Affects = append(v.Affects, Affect{Ref: f.Image})
+		}
+		if f.FixedIn != "" && v.Recommendation == "" {
+			v.Recommendation = fmt.Sprintf("Upgrade %s to %s", f.Package, f.FixedIn)
+		}
+	}
+	sort.Strings(ids)
+	out := make([]Vulnerability, 0, len(ids))
+	for _, id := range ids {
+		v := byID[id]
+		sort.Slice(v.Affects, func(i, j int) bool { return v.Affects[i].Ref < v.Affects[j].Ref })
+		out = append(out, *v)
+	}
+	return out
+}
+
+func affects(v Vulnerability, ref string) bool {
+	for _, a := range v.Affects {
+		if a.Ref == ref {
+			return true
+		}
+	}
+	return false
+}
+
+// Severity returns the vulnerability's rated severity ("unknown" when unrated).
+func (v Vulnerability) Severity() string {
+	if len(v.Ratings) == 0 {
+		return "unknown"
+	}
+	return v.Ratings[0].Severity
+}
+
+// Delta is the CVE difference between two chart SBOMs.
+type Delta struct {
+	Added     []Vulnerability // present now, absent before
+	Fixed     []Vulnerability // present before, absent now
+	Unchanged int
+}
+
+// Diff compares the vulnerabilities of the previous and current chart SBOMs by
+// id. A nil prev yields every current vulnerability as added.
+func Diff(prev, cur *BOM) Delta {
+	before := map[string]Vulnerability{}
+	if prev != nil {
+		for _, v := range prev.Vulnerabilities {
+			before[v.ID] = v
+		}
+	}
+	var d Delta
+	for _, v := range cur.Vulnerabilities {
+		if _, ok := before[v.ID]; ok {
+			d.Unchanged++
+			delete(before, v.ID)
+			continue
+		}
+		d.Added = append(d.Added, v)
+	}
+	for _, v := range before {
+		d.Fixed = append(d.Fixed, v)
+	}
+	bySeverity := func(vs []Vulnerability) {
+		sort.Slice(vs, func(i, j int) bool {
+			ri, rj := severityRank[vs[i].Severity()], severityRank[vs[j].Severity()]
+			if ri != rj {
+				return ri < rj
+			}
+			return vs[i].ID < vs[j].ID
+		})
+	}
+	bySeverity(d.Added)
+	bySeverity(d.Fixed)
+	return d
+}
+
+// Markdown renders the delta for a release PR comment or job summary.
+func (d Delta) Markdown(prevVersion, curVersion string) string {
+	var b strings.Builder
+	if prevVersion == "" {
+		fmt.Fprintf(&b, "### CVE report for chart %s\n\n", curVersion)
+	} else {
+		fmt.Fprintf(&b, "### CVE delta: chart %s → %s\n\n", prevVersion, curVersion)
+	}
+	fmt.Fprintf(&b, "%d new, %d fixed, %d unchanged.\n", len(d.Added), len(d.Fixed), d.Unchanged)
+	section := func(title string, vs []Vulnerability) {
+		if len(vs) == 0 {
+			return
+		}
+		fmt.Fprintf(&b, "\n#### %s\n\n| Severity | ID | Images |\n|---|---|---|\n", title)
+		for _, v := range vs {
+			refs := make([]string, 0, len(v.Affects))
+			for _, a := range v.Affects {
+				refs = append(refs, "`"+a.Ref+"`")
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s |\n", v.Severity(), v.ID, strings.Join(refs, "<br>"))
+		}
+	}
+	section("New", d.Added)
+	section("Fixed", d.Fixed)
+	return b.String()
+}
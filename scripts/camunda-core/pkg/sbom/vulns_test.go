@@ -0,0 +1,135 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package sbom
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const trivyJSON = `{"ArtifactName":"camunda/zeebe:8.8.0","Results":[{"Vulnerabilities":[
+	{"VulnerabilityID":"CVE-2026-0001","PkgName":"openssl","InstalledVersion":"3.0.1","FixedVersion":"3.0.2","Severity":"HIGH"},
+	{"VulnerabilityID":"CVE-2026-0002","PkgName":"zlib","InstalledVersion":"1.2","Severity":"LOW"}]}]}`
+
+const grypeJSON = `{"matches":[
+	{"vulnerability":{"id":"CVE-2026-0001","severity":"Critical","fix":{"versions":["3.0.2"]}},"artifact":{"name":"openssl","version":"3.0.1"}}],
+	"source":{"type":"image","target":{"userInput":"registry.camunda.cloud/vendor-ee/elasticsearch:8.19.0"}}}`
+
+var testImages = []Image{
+	{Ref: "docker.io/camunda/zeebe:8.8.0", Digest: "sha256:aaa", Licenses: []string{"Camunda License 1.0"}},
+	{Ref: "registry.camunda.cloud/vendor-ee/elasticsearch:8.19.0", Digest: "sha256:bbb", Licenses: []string{"Elastic-2.0"}},
+}
+
+func TestParseReportDetectsScanner(t *testing.T) {
+	tr, err := ParseReport([]byte(trivyJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tr.Target != "camunda/zeebe:8.8.0" || len(tr.Findings) != 2 || tr.Findings[0].Severity != "high" || tr.Findings[0].Scanner != "trivy" {
+		t.Errorf("unexpected trivy report %+v", tr)
+	}
+	gr, err := ParseReport([]byte(grypeJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gr.Target != "registry.camunda.cloud/vendor-ee/elasticsearch:8.19.0" || gr.Findings[0].FixedIn != "3.0.2" || gr.Findings[0].Severity != "critical" {
+		t.Errorf("unexpected grype report %+v", gr)
+	}
+	if _, err := ParseReport([]byte(`{"foo":1}`)); err == nil {
+		t.Error("expected an error for an unknown report format")
+	}
+}
+
+func TestBuildGroupsFindingsAndPinsDigests(t *testing.T) {
+	dir := t.TempDir()
+	for name, body := range map[string]string{"zeebe.json": trivyJSON, "es.json": grypeJSON,
+		"other.json": `{"ArtifactName":"busybox:1.36","Results":[]}`} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	reports, err := LoadReports(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	findings, unmatched := MatchFindings(testImages, reports)
+	if len(unmatched) != 1 || unmatched[0] != "busybox:1.36" {
+		t.Errorf("unmatched = %v", unmatched)
+	}
+
+	bom := Build(Chart{Name: "camunda-platform", Version: "13.4.0", License: "Apache-2.0"}, testImages, findings)
+	if bom.Metadata.Component.PURL != "pkg:helm/camunda-platform@13.4.0" {
+		t.Errorf("chart purl = %q", bom.Metadata.Component.PURL)
+	}
+	zeebe := bom.Components[0]
+	if zeebe.PURL != "pkg:oci/zeebe@sha256%3Aaaa?repository_url=docker.io%2Fcamunda%2Fzeebe&tag=8.8.0" {
+		t.Errorf("zeebe purl = %q", zeebe.PURL)
+	}
+	if zeebe.Licenses[0].Expression != "Camunda License 1.0" || bom.Components[1].Licenses[0].License.ID != "Elastic-2.0" {
+		t.Errorf("unexpected licenses %+v / %+v", zeebe.Licenses, bom.Components[1].Licenses)
+	}
+	if len(bom.Vulnerabilities) != 2 {
+		t.Fatalf("expected 2 grouped vulnerabilities, got %+v", bom.Vulnerabilities)
+	}
+	shared := bom.Vulnerabilities[0]
+	if shared.ID != "CVE-2026-0001" || shared.Severity() != "critical" || len(shared.Affects) != 2 {
+		t.Errorf("shared CVE should take the highest severity and affect both images: %+v", shared)
+	}
+
+	out, err := bom.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	again, _ := Build(Chart{Name: "camunda-platform", Version: "13.4.0", License: "Apache-2.0"}, testImages, findings).Marshal()
+	if !bytes.Equal(out, again) {
+		t.Error("Build must be deterministic for identical input")
+	}
+	path := filepath.Join(dir, "sbom.cdx.json")
+	if err := os.WriteFile(path, out, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadBOM(path)
+	if err != nil || read.SerialNumber != bom.SerialNumber || len(read.Vulnerabilities) != 2 {
+		t.Fatalf("ReadBOM round trip: %v %+v", err, read)
+	}
+}
+
+func TestDiffMarkdown(t *testing.T) {
+	vuln := func(id, sev string) Vulnerability {
+		return Vulnerability{ID: id, Ratings: []Rating{{Severity: sev}}, Affects: []Affect{{Ref: "docker.io/camunda/zeebe:8.8.0"}}}
+	}
+	prev := &BOM{Vulnerabilities: []Vulnerability{vuln("CVE-1", "high"), vuln("CVE-2", "low")}}
+	cur := &BOM{Vulnerabilities: []Vulnerability{vuln("CVE-2", "low"), vuln("CVE-4", "low"), vuln("CVE-3", "critical")}}
+
+	d := Diff(prev, cur)
+	if d.Unchanged != 1 || len(d.Fixed) != 1 || d.Fixed[0].ID != "CVE-1" {
+		t.Errorf("unexpected delta %+v", d)
+	}
+	if len(d.Added) != 2 || d.Added[0].ID != "CVE-3" {
+		t.Errorf("added CVEs must be sorted by severity, got %+v", d.Added)
+	}
+	md := d.Markdown("13.3.0", "13.4.0")
+	for _, want := range []string{"chart 13.3.0 → 13.4.0", "2 new, 1 fixed, 1 unchanged", "| critical | CVE-3 | `docker.io/camunda/zeebe:8.8.0` |", "#### Fixed"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown is missing %q:\n%s", want, md)
+		}
+	}
+	if got := len(Diff(nil, cur).Added); got != 3 {
+		t.Errorf("without a previous SBOM every CVE is new, got %d", got)
+	}
+}
@@ -173,7 +173,9 @@ func (c *DeploymentConfig) ResolvePaths(scenariosDir string) ([]string, error) {
 	// The suffix follows the legacy Taskfile convention:
 	//   - For EKS: "eks-<infraType>" (e.g., "eks-preemptible")
 	//   - For other platforms: "<infraType>" (e.g., "preemptible", "distroci")
-	if c.InfraType != "" {
+	// The local platform (kind/k3d) has a single unlabelled node, where the
+	// pool nodeSelectors would leave every pod Pending, so it gets no infra layer.
+	if c.InfraType != "" && c.Platform != "local" {
 		infraSuffix := c.InfraType
 		if c.Platform == "eks" {
 			infraSuffix = "eks-" + c.InfraType
@@ -994,3 +994,39 @@ func TestBuildDeploymentConfig_ImageTagsAutoDetection(t *testing.T) {
 		})
 	}
 }
+
+func TestDeploymentConfigResolvePathsLocalSkipsInfra(t *testing.T) {
+	root := t.TempDir()
+	scenariosDir := filepath.Join(root, "chart-full-setup")
+	for _, f := range []string{
+		filepath.Join(scenariosDir, "values", "base.yaml"),
+		filepath.Join(scenariosDir, "values", "platform", "gke.yaml"),
+		filepath.Join(scenariosDir, "values", "platform", "local.yaml"),
+		filepath.Join(root, "infra", "values-infra-standard.yaml"),
+	} {
+		if err := os.MkdirAll(filepath.Dir(f), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(f, []byte("# test"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, tc := range []struct {
+		platform  string
+		wantInfra bool
+	}{{"gke", true}, {"local", false}} {
+		cfg := &DeploymentConfig{Platform: tc.platform, InfraType: "standard"}
+		paths, err := cfg.ResolvePaths(scenariosDir)
+		if err != nil {
+			t.Fatalf("%s: ResolvePaths() error = %v", tc.platform, err)
+		}
+		joined := strings.Join(paths, ",")
+		if !strings.Contains(joined, "platform/"+tc.platform+".yaml") {
+			t.Errorf("%s: platform layer missing: %v", tc.platform, paths)
+		}
+		if got := strings.Contains(joined, "values-infra-standard.yaml"); got != tc.wantInfra {
+			t.Errorf("%s: infra layer included = %v, want %v (%v)", tc.platform, got, tc.wantInfra, paths)
+		}
+	}
+}
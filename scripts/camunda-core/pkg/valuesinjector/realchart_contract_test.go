@@ -15,41 +15,57 @@
 package valuesinjector
 
 import (
-	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v3"
 )
 
+// TestRealChartContract injects a sentinel tag into every component each real
+// chart declares and checks that exactly the expected components exist and
+// that nothing but their image tags changed.
 func TestRealChartContract(t *testing.T) {
 	cases := []struct {
 		version    string
 		components []string
 	}{
+		{
+			version: "8.6",
+			components: []string{
+				"zeebe", "zeebeGateway", "operate", "tasklist", "identity", "optimize", "connectors",
+				"console", "webModeler", "webModeler.restapi", "webModeler.webapp", "webModeler.websockets",
+			},
+		},
 		{
 			version: "8.7",
 			components: []string{
-				"console", "zeebe", "zeebeGateway", "operate", "tasklist",
-				"optimize", "identity", "webModeler", "connectors",
+				"zeebe", "zeebeGateway", "operate", "tasklist", "identity", "optimize", "connectors",
+				"console", "webModeler", "webModeler.restapi", "webModeler.webapp", "webModeler.websockets",
 			},
 		},
 		{
 			version: "8.8",
 			components: []string{
-				"identity", "console", "webModeler", "connectors", "orchestration", "optimize",
+				"orchestration", "identity", "optimize", "connectors", "console",
+				"webModeler", "webModeler.restapi", "webModeler.webapp", "webModeler.websockets",
 			},
 		},
 		{
 			version: "8.9",
 			components: []string{
-				"identity", "console", "webModeler", "connectors", "orchestration", "optimize",
+				"orchestration", "identity", "optimize", "connectors", "console",
+				"webModeler", "webModeler.restapi", "webModeler.websockets",
 			},
 		},
 		{
 			version: "8.10",
 			components: []string{
-				"identity", "webModeler", "connectors", "orchestration", "optimize",
+				"orchestration", "identity", "optimize", "connectors",
+				"webModeler", "webModeler.restapi", "webModeler.websockets",
 			},
 		},
 	}
@@ -62,30 +78,60 @@ func TestRealChartContract(t *testing.T) {
 				t.Skipf("chart values.yaml absent at %s", valuesPath)
 			}
 
-			valuesYAML, err := os.ReadFile(valuesPath)
+			raw, err := os.ReadFile(valuesPath)
 			if err != nil {
 				t.Fatalf("read values.yaml: %v", err)
 			}
+			valuesYAML := string(raw)
 
-			result, err := mergeRealChartContract(tc.version, string(valuesYAML))
+			var declared []string
+			var overrides []Override
+			for _, component := range Components() {
+				ok, err := Declares(valuesYAML, component)
+				if err != nil {
+					t.Fatalf("Declares(%s): %v", component, err)
+				}
+				if ok {
+					declared = append(declared, component)
+					overrides = append(overrides, Override{Component: component, Tag: contractSentinel(component)})
+				}
+			}
+			slices.Sort(declared)
+			want := slices.Clone(tc.components)
+			slices.Sort(want)
+			if !slices.Equal(declared, want) {
+				t.Fatalf("version %s: values.yaml declares image components %v, expected %v — "+
+					"update chartmeta.ComponentSpecs or this contract to match the chart", tc.version, declared, want)
+			}
+
+			result, err := Inject(valuesYAML, overrides)
 			if err != nil {
-				t.Fatalf("version %s: injector targets a component missing from values.yaml — "+
-					"update the injector's component list for this version to match the chart: %v",
-					tc.version, err)
+				t.Fatalf("version %s: inject: %v", tc.version, err)
 			}
 
 			for _, component := range tc.components {
-				sentinel := contractSentinel(component)
-				if !strings.Contains(result, sentinel) {
-					t.Errorf("version %s: injector did not bump component %q (sentinel %q missing)",
-						tc.version, component, sentinel)
+				if !strings.Contains(result, contractSentinel(component)) {
+					t.Errorf("version %s: injector did not bump component %q", tc.version, component)
 				}
 			}
 
-			if tc.version == "8.10" {
-				if strings.Contains(result, contractSentinel("console")) {
-					t.Errorf("version 8.10: console must not be injected (no standalone console in values.yaml)")
+			// Apart from the injected tags, the values must be unchanged.
+			var before, after map[string]any
+			if err := yaml.Unmarshal(raw, &before); err != nil {
+				t.Fatalf("parse values.yaml: %v", err)
+			}
+			if err := yaml.Unmarshal([]byte(result), &after); err != nil {
+				t.Fatalf("parse injected values.yaml: %v", err)
+			}
+			for _, component := range tc.components {
+				image := before
+				for _, key := range strings.Split(component, ".") {
+					image = image[key].(map[string]any)
 				}
+				image["image"].(map[string]any)["tag"] = contractSentinel(component)
+			}
+			if !reflect.DeepEqual(before, after) {
+				t.Errorf("version %s: injection changed values beyond the image tags", tc.version)
 			}
 		})
 	}
@@ -94,47 +140,3 @@ func TestRealChartContract(t *testing.T) {
 func contractSentinel(component string) string {
 	return "CONTRACT-" + component
 }
-
-func contractTag(component string) *ComponentImage {
-	return &ComponentImage{Image: ImageTag{Tag: contractSentinel(component)}}
-}
-
-func buildOverrides86() *ValuesYAML86 {
-	return &ValuesYAML86{
-		Console:      contractTag("console"),
-		Zeebe:        contractTag("zeebe"),
-		ZeebeGateway: contractTag("zeebeGateway"),
-		Operate:      contractTag("operate"),
-		Tasklist:     contractTag("tasklist"),
-		Optimize:     contractTag("optimize"),
-		Identity:     contractTag("identity"),
-		WebModeler:   contractTag("webModeler"),
-		Connectors:   contractTag("connectors"),
-	}
-}
-
-func buildOverrides88() *ValuesYAML88 {
-	return &ValuesYAML88{
-		Identity:      contractTag("identity"),
-		Console:       contractTag("console"),
-		WebModeler:    contractTag("webModeler"),
-		Connectors:    contractTag("connectors"),
-		Orchestration: contractTag("orchestration"),
-		Optimize:      contractTag("optimize"),
-	}
-}
-
-func mergeRealChartContract(version, valuesYAML string) (string, error) {
-	switch version {
-	case "8.7":
-		return MergeImageTags87(valuesYAML, buildOverrides86())
-	case "8.8":
-		return MergeImageTags88(valuesYAML, buildOverrides88())
-	case "8.9":
-		return MergeImageTags89(valuesYAML, buildOverrides88())
-	case "8.10":
-		return MergeImageTags810(valuesYAML, buildOverrides88())
-	default:
-		return "", fmt.Errorf("unsupported chart version for contract test: %s", version)
-	}
-}
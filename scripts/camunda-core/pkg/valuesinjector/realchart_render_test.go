@@ -45,9 +45,7 @@ func TestRealChart810RendersHubImage(t *testing.T) {
 	}
 
 	const sentinel = "hub-render-sentinel"
-	injected, err := MergeImageTags810(string(raw), &ValuesYAML810{
-		WebModeler: &ComponentImage{Image: ImageTag{Tag: sentinel}},
-	})
+	injected, err := Inject(string(raw), []Override{{Component: "webModeler", Tag: sentinel}})
 	if err != nil {
 		t.Fatalf("inject webModeler tag: %v", err)
 	}
@@ -12,284 +12,284 @@
 // See the License for the specific language governing permissions and
 // limitations under the License.
 
-// Package valuesinjector overrides component image tags in a chart's values.yaml
-// while preserving the file's formatting. The set of overridable components is
-// version-gated (8.6/8.7 classic: zeebe/zeebeGateway/operate/tasklist/...; 8.8+
-// orchestration). Each override is validated against the parsed YAML (the
-// component, its image section, and the tag must exist) before a line-scoped
-// regex replaces the tag, keeping comments/indentation intact.
+// Package valuesinjector overrides component images (registry, repository,
+// tag, digest) in a chart's values.yaml while preserving the file's
+// formatting. Components are addressed by their chartmeta.ComponentSpecs path
+// (e.g. "orchestration", "webModeler.restapi"), so no chart generation needs
+// its own code: a new minor works as long as its components live at known
+// paths. A name outside that table is rejected.
+//
+// Each field is located through the parsed yaml.Node tree and only that
+// scalar's bytes are rewritten (or, for a field the image map does not
+// declare yet, one line is inserted), so comments, ordering, blank lines and
+// quoting of the rest of the file are untouched.
 package valuesinjector
 
 import (
 	"fmt"
-	"regexp"
+	"strconv"
 	"strings"
 
 	"gopkg.in/yaml.v3"
+	"scripts/camunda-core/pkg/chartmeta"
 )
 
-// ImageTag is the tag field within an image configuration.
-type ImageTag struct {
-	Tag string `yaml:"tag,omitempty"`
+// Override sets fields of one component's image. Empty fields are left as
+// they are.
+type Override struct {
+	Component  string // chartmeta.ComponentSpecs path, e.g. "orchestration"
+	Registry   string
+	Repository string
+	Tag        string
+	Digest     string
 }
 
-// ComponentImage is a component's image configuration.
-type ComponentImage struct {
-	Image ImageTag `yaml:"image,omitempty"`
+// fields returns the override's non-empty image fields in a fixed order.
+func (o Override) fields() [][2]string {
+	var out [][2]string
+	for _, f := range [][2]string{
+		{"registry", o.Registry}, {"repository", o.Repository}, {"tag", o.Tag}, {"digest", o.Digest},
+	} {
+		if f[1] != "" {
+			out = append(out, f)
+		}
+	}
+	return out
 }
 
-// ValuesYAML86 is the set of image-bearing components for charts 8.6 / 8.7.
-type ValuesYAML86 struct {
-	Console      *ComponentImage `yaml:"console,omitempty"`
-	Zeebe        *ComponentImage `yaml:"zeebe,omitempty"`
-	ZeebeGateway *ComponentImage `yaml:"zeebeGateway,omitempty"`
-	Operate      *ComponentImage `yaml:"operate,omitempty"`
-	Tasklist     *ComponentImage `yaml:"tasklist,omitempty"`
-	Optimize     *ComponentImage `yaml:"optimize,omitempty"`
-	Identity     *ComponentImage `yaml:"identity,omitempty"`
-	WebModeler   *ComponentImage `yaml:"webModeler,omitempty"`
-	Connectors   *ComponentImage `yaml:"connectors,omitempty"`
+// Components returns the known component paths, in table order.
+func Components() []string {
+	names := make([]string, len(chartmeta.ComponentSpecs))
+	for i, spec := range chartmeta.ComponentSpecs {
+		names[i] = spec.Path
+	}
+	return names
 }
 
-// ValuesYAML87 has the same component structure as 8.6.
-type ValuesYAML87 = ValuesYAML86
-
-// ValuesYAML88 is the set of image-bearing components for charts 8.8+; the
-// 8.6/8.7 zeebe/zeebeGateway/operate/tasklist components are replaced by
-// orchestration.
-type ValuesYAML88 struct {
-	Identity      *ComponentImage `yaml:"identity,omitempty"`
-	Console       *ComponentImage `yaml:"console,omitempty"`
-	WebModeler    *ComponentImage `yaml:"webModeler,omitempty"`
-	Connectors    *ComponentImage `yaml:"connectors,omitempty"`
-	Orchestration *ComponentImage `yaml:"orchestration,omitempty"`
-	Optimize      *ComponentImage `yaml:"optimize,omitempty"`
+func checkComponent(component string) error {
+	for _, spec := range chartmeta.ComponentSpecs {
+		if spec.Path == component {
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown component '%s' (known: %s)", component, strings.Join(Components(), ", "))
 }
 
-// ValuesYAML89 has the same component structure as 8.8.
-type ValuesYAML89 = ValuesYAML88
-
-// ValuesYAML810 has the same component structure as 8.8.
-type ValuesYAML810 = ValuesYAML88
-
-// override pairs a component name with its (optional) image-tag override.
-type override struct {
-	name string
-	comp *ComponentImage
+// Declares reports whether valuesYAML declares an image map for component,
+// i.e. whether this chart generation ships it. It fails on an unknown
+// component or unparsable YAML.
+func Declares(valuesYAML, component string) (bool, error) {
+	if err := checkComponent(component); err != nil {
+		return false, err
+	}
+	root, err := parseRoot(valuesYAML)
+	if err != nil {
+		return false, err
+	}
+	image, err := imageNode(root, component)
+	return image != nil && err == nil, nil
 }
 
-// applyOverrides replaces each present override's image.tag in valuesYAML,
-// preserving formatting. It errors if an override names a component that has no
-// image.tag.
-func applyOverrides(valuesYAML string, overrides []override) (string, error) {
+// Inject applies the overrides to valuesYAML. Every override must name a known
+// component that the values declare with a block-style `image` map; a field
+// the map lacks (e.g. digest) is added under it.
+func Inject(valuesYAML string, overrides []Override) (string, error) {
 	result := valuesYAML
 	for _, o := range overrides {
-		if o.comp == nil {
-			continue
-		}
-		var err error
-		if result, err = replaceImageTag(result, o.name, o.comp.Image.Tag); err != nil {
+		if err := checkComponent(o.Component); err != nil {
 			return "", err
 		}
+		for _, f := range o.fields() {
+			var err error
+			if result, err = setImageField(result, o.Component, f[0], f[1]); err != nil {
+				return "", err
+			}
+		}
 	}
 	return result, nil
 }
 
-// MergeImageTags86 applies the 8.6/8.7 image-tag overrides to a values.yaml string.
-func MergeImageTags86(valuesYAML string, overrides *ValuesYAML86) (string, error) {
-	if overrides == nil {
-		return valuesYAML, nil
+func parseRoot(content string) (*yaml.Node, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(content), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse values.yaml: %w", err)
 	}
-	return applyOverrides(valuesYAML, []override{
-		{"console", overrides.Console},
-		{"zeebe", overrides.Zeebe},
-		{"zeebeGateway", overrides.ZeebeGateway},
-		{"operate", overrides.Operate},
-		{"tasklist", overrides.Tasklist},
-		{"optimize", overrides.Optimize},
-		{"identity", overrides.Identity},
-		{"webModeler", overrides.WebModeler},
-		{"connectors", overrides.Connectors},
-	})
-}
-
-// MergeImageTags87 applies the 8.7 overrides (same structure as 8.6).
-func MergeImageTags87(valuesYAML string, overrides *ValuesYAML87) (string, error) {
-	return MergeImageTags86(valuesYAML, overrides)
-}
-
-// MergeImageTags88 applies the 8.8+ image-tag overrides to a values.yaml string.
-func MergeImageTags88(valuesYAML string, overrides *ValuesYAML88) (string, error) {
-	if overrides == nil {
-		return valuesYAML, nil
+	if doc.Kind != yaml.DocumentNode || len(doc.Content) == 0 {
+		return nil, fmt.Errorf("empty YAML document")
 	}
-	return applyOverrides(valuesYAML, []override{
-		{"identity", overrides.Identity},
-		{"console", overrides.Console},
-		{"webModeler", overrides.WebModeler},
-		{"connectors", overrides.Connectors},
-		{"orchestration", overrides.Orchestration},
-		{"optimize", overrides.Optimize},
-	})
-}
-
-// MergeImageTags89 applies the 8.9 overrides (same structure as 8.8).
-func MergeImageTags89(valuesYAML string, overrides *ValuesYAML89) (string, error) {
-	return MergeImageTags88(valuesYAML, overrides)
-}
-
-// MergeImageTags810 applies the 8.10 image-tag overrides; no standalone console component.
-func MergeImageTags810(valuesYAML string, overrides *ValuesYAML810) (string, error) {
-	if overrides == nil {
-		return valuesYAML, nil
+	if doc.Content[0].Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("expected mapping node at root")
 	}
-	return applyOverrides(valuesYAML, []override{
-		{"identity", overrides.Identity},
-		{"webModeler", overrides.WebModeler},
-		{"connectors", overrides.Connectors},
-		{"orchestration", overrides.Orchestration},
-		{"optimize", overrides.Optimize},
-	})
+	return doc.Content[0], nil
 }
 
-// replaceImageTag validates that componentName.image.tag exists, then replaces
-// the tag line within that component's image section by regex (preserving the
-// surrounding formatting).
-func replaceImageTag(content string, componentName string, newTag string) (string, error) {
-	if err := validateComponentImageTag(content, componentName); err != nil {
-		return "", err
+// imageNode walks the dot-separated component path and returns its `image`
+// mapping node.
+func imageNode(root *yaml.Node, component string) (*yaml.Node, error) {
+	node := root
+	for _, key := range strings.Split(component, ".") {
+		_, next := findMapEntry(node, key)
+		if next == nil {
+			return nil, fmt.Errorf("component '%s' not found in values.yaml", component)
+		}
+		if next.Kind != yaml.MappingNode {
+			return nil, fmt.Errorf("component '%s' is not a valid map in values.yaml", component)
+		}
+		node = next
 	}
-
-	lines := strings.Split(content, "\n")
-	componentStart, componentEnd := findComponentRange(lines, componentName)
-	if componentStart == -1 {
-		return "", fmt.Errorf("component '%s' not found in values.yaml", componentName)
+	_, image := findMapEntry(node, "image")
+	if image == nil {
+		return nil, fmt.Errorf("component '%s' does not have an 'image' section in values.yaml", component)
 	}
-
-	imageStart, imageEnd := findImageRange(lines, componentStart, componentEnd)
-	if imageStart == -1 {
-		return "", fmt.Errorf("component '%s' does not have an 'image' section in values.yaml", componentName)
+	if image.Kind != yaml.MappingNode || image.Style&yaml.FlowStyle != 0 || len(image.Content) == 0 {
+		return nil, fmt.Errorf("component '%s.image' is not a block map in values.yaml", component)
 	}
+	return image, nil
+}
 
-	tagReplaced := false
-	tagRegex := regexp.MustCompile(`^(\s*tag:\s*)(.*)$`)
-	for i := imageStart; i <= imageEnd && i < len(lines); i++ {
-		if tagRegex.MatchString(lines[i]) {
-			lines[i] = tagRegex.ReplaceAllString(lines[i], "${1}"+newTag)
-			tagReplaced = true
-			break
-		}
+// findMapEntry returns the key and value nodes for key in a mapping node.
+func findMapEntry(node *yaml.Node, key string) (*yaml.Node, *yaml.Node) {
+	if node.Kind != yaml.MappingNode {
+		return nil, nil
 	}
-	if !tagReplaced {
-		return "", fmt.Errorf("component '%s.image' does not have a 'tag' field in values.yaml", componentName)
+	for i := 0; i < len(node.Content)-1; i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i], node.Content[i+1]
+		}
 	}
-	return strings.Join(lines, "\n"), nil
+	return nil, nil
 }
 
-// validateComponentImageTag confirms componentName.image.tag exists via YAML parse.
-func validateComponentImageTag(content string, componentName string) error {
-	var root yaml.Node
-	if err := yaml.Unmarshal([]byte(content), &root); err != nil {
-		return fmt.Errorf("failed to parse values.yaml: %w", err)
+// setImageField rewrites component.image.<field> to value in place.
+func setImageField(content, component, field, value string) (string, error) {
+	root, err := parseRoot(content)
+	if err != nil {
+		return "", err
+	}
+	image, err := imageNode(root, component)
+	if err != nil {
+		return "", err
 	}
-	rootNode := &root
-	if root.Kind == yaml.DocumentNode {
-		if len(root.Content) == 0 {
-			return fmt.Errorf("empty YAML document")
+	lines := strings.SplitAfter(content, "\n")
+
+	key, current := findMapEntry(image, field)
+	if key == nil {
+		// Insert "<field>: <value>" after the map's last line, at its key indent.
+		indent := strings.Repeat(" ", image.Content[0].Column-1)
+		at := lastLine(image) // 1-based; insert after it
+		if !strings.HasSuffix(lines[at-1], "\n") {
+			lines[at-1] += "\n"
 		}
-		rootNode = root.Content[0]
+		line := indent + field + ": " + renderScalar(value, 0) + "\n"
+		lines = append(lines[:at], append([]string{line}, lines[at:]...)...)
+		return strings.Join(lines, ""), nil
 	}
-	if rootNode.Kind != yaml.MappingNode {
-		return fmt.Errorf("expected mapping node at root")
+
+	if current.Kind != yaml.ScalarNode || current.Style&(yaml.LiteralStyle|yaml.FoldedStyle) != 0 {
+		return "", fmt.Errorf("component '%s.image.%s' is not a single-line scalar in values.yaml", component, field)
 	}
-	componentNode := findMapValue(rootNode, componentName)
-	if componentNode == nil {
-		return fmt.Errorf("component '%s' not found in values.yaml", componentName)
+	line := []rune(lines[key.Line-1])
+	colon := key.Column - 1 + len([]rune(key.Value))
+	for colon < len(line) && line[colon] != ':' {
+		colon++
 	}
-	if componentNode.Kind != yaml.MappingNode {
-		return fmt.Errorf("component '%s' is not a valid map in values.yaml", componentName)
+	if colon >= len(line) {
+		return "", fmt.Errorf("component '%s.image.%s': cannot locate the key in values.yaml", component, field)
 	}
-	imageNode := findMapValue(componentNode, "image")
-	if imageNode == nil {
-		return fmt.Errorf("component '%s' does not have an 'image' section in values.yaml", componentName)
+	start := colon + 1
+	for start < len(line) && line[start] == ' ' {
+		start++
 	}
-	if imageNode.Kind != yaml.MappingNode {
-		return fmt.Errorf("component '%s.image' is not a valid map in values.yaml", componentName)
+	end := scalarEnd(line, start)
+	if current.Line != key.Line && end > start {
+		return "", fmt.Errorf("component '%s.image.%s': value must be on the key's line in values.yaml", component, field)
 	}
-	if findMapValue(imageNode, "tag") == nil {
-		return fmt.Errorf("component '%s.image' does not have a 'tag' field in values.yaml", componentName)
+	replacement := renderScalar(value, current.Style)
+	if end == start {
+		// Empty value ("tag:" or "tag: # comment"): keep one space after the colon.
+		replacement = " " + replacement
+		start, end = colon+1, colon+1
+		if end < len(line) && line[end] == ' ' {
+			end++
+			if end < len(line) && line[end] == '#' {
+				replacement += " "
+			}
+		}
 	}
-	return nil
+	lines[key.Line-1] = string(line[:start]) + replacement + string(line[end:])
+	return strings.Join(lines, ""), nil
 }
 
-// findMapValue returns the value node for key in a mapping node, or nil.
-func findMapValue(node *yaml.Node, key string) *yaml.Node {
-	if node.Kind != yaml.MappingNode {
-		return nil
+// scalarEnd returns the index just past the scalar token starting at start:
+// the closing quote of a quoted scalar, or the end of a plain scalar (before
+// a " #" comment or the line break, trailing spaces excluded).
+func scalarEnd(line []rune, start int) int {
+	if start >= len(line) {
+		return start
 	}
-	for i := 0; i < len(node.Content)-1; i += 2 {
-		if node.Content[i].Value == key {
-			return node.Content[i+1]
+	switch line[start] {
+	case '"':
+		for i := start + 1; i < len(line); i++ {
+			switch line[i] {
+			case '\\':
+				i++
+			case '"':
+				return i + 1
+			}
 		}
-	}
-	return nil
-}
-
-// findComponentRange returns the [start,end] line indices of a top-level
-// component block, or -1,-1 if not found.
-func findComponentRange(lines []string, componentName string) (int, int) {
-	componentPattern := regexp.MustCompile(`^` + regexp.QuoteMeta(componentName) + `:`)
-	start := -1
-	for i, line := range lines {
-		if componentPattern.MatchString(line) {
-			start = i
-			break
+		return len(line)
+	case '\'':
+		for i := start + 1; i < len(line); i++ {
+			if line[i] == '\'' {
+				if i+1 < len(line) && line[i+1] == '\'' {
+					i++
+					continue
+				}
+				return i + 1
+			}
 		}
+		return len(line)
+	case '#', '\n', '\r':
+		return start
 	}
-	if start == -1 {
-		return -1, -1
-	}
-	topLevelPattern := regexp.MustCompile(`^[a-zA-Z].*:`)
-	end := len(lines) - 1
-	for i := start + 1; i < len(lines); i++ {
-		if topLevelPattern.MatchString(lines[i]) {
-			end = i - 1
+	end := start
+	for i := start; i < len(line); i++ {
+		if line[i] == '\n' || line[i] == '\r' || (line[i] == '#' && i > start && line[i-1] == ' ') {
 			break
 		}
+		if line[i] != ' ' {
+			end = i + 1
+		}
 	}
-	return start, end
+	return end
 }
 
-// findImageRange returns the [start,end] line indices of the image section
-// within a component block, or -1,-1 if not found.
-func findImageRange(lines []string, componentStart, componentEnd int) (int, int) {
-	imagePattern := regexp.MustCompile(`^(\s+)image:\s*$`)
-	start := -1
-	var imageIndent string
-	for i := componentStart + 1; i <= componentEnd && i < len(lines); i++ {
-		match := imagePattern.FindStringSubmatch(lines[i])
-		if match != nil {
-			start = i
-			imageIndent = match[1]
-			break
-		}
+// renderScalar renders value in the existing scalar's quoting style. A plain
+// value that YAML would not read back as the same string (e.g. 8.10, true, or
+// one containing ": ") is double-quoted.
+func renderScalar(value string, style yaml.Style) string {
+	switch {
+	case style&yaml.DoubleQuotedStyle != 0:
+		return strconv.Quote(value)
+	case style&yaml.SingleQuotedStyle != 0:
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'"
 	}
-	if start == -1 {
-		return -1, -1
+	var back struct {
+		V any `yaml:"v"`
 	}
-	end := componentEnd
-	imageIndentLen := len(imageIndent)
-	for i := start + 1; i <= componentEnd && i < len(lines); i++ {
-		line := lines[i]
-		trimmed := strings.TrimSpace(line)
-		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
-			continue
-		}
-		lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
-		if lineIndent <= imageIndentLen {
-			end = i - 1
-			break
+	if err := yaml.Unmarshal([]byte("v: "+value), &back); err != nil || back.V != value {
+		return strconv.Quote(value)
+	}
+	return value
+}
+
+// lastLine returns the last source line a node (and its children) occupies.
+func lastLine(n *yaml.Node) int {
+	last := n.Line
+	for _, c := range n.Content {
+		if l := lastLine(c); l > last {
+			last = l
 		}
 	}
-	return start, end
+	return last
 }
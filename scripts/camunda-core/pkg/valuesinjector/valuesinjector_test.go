@@ -143,19 +143,13 @@ optimize:
     tag: 8.10.0
 `
 
-func TestMergeImageTags86_SingleComponent(t *testing.T) {
-	overrides := &ValuesYAML86{
-		Console: &ComponentImage{
-			Image: ImageTag{Tag: "1.2.3"},
-		},
-	}
-
-	result, err := MergeImageTags86(sampleValues86, overrides)
+func TestInject_SingleComponent(t *testing.T) {
+	result, err := Inject(sampleValues86, []Override{{Component: "console", Tag: "1.2.3"}})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if !strings.Contains(result, "tag: \"1.2.3\"") && !strings.Contains(result, "tag: 1.2.3") {
+	if !strings.Contains(result, "repository: camunda/console\n    tag: 1.2.3\n") {
 		t.Errorf("expected console tag to be updated to 1.2.3, got:\n%s", result)
 	}
 
@@ -165,305 +159,228 @@ func TestMergeImageTags86_SingleComponent(t *testing.T) {
 	}
 }
 
-func TestMergeImageTags86_ZeebeOverrideAlsoUpdatesGateway(t *testing.T) {
-	overrides := &ValuesYAML86{
-		Zeebe: &ComponentImage{
-			Image: ImageTag{Tag: "8.6.99"},
-		},
-		ZeebeGateway: &ComponentImage{
-			Image: ImageTag{Tag: "8.6.99"},
-		},
-	}
-
-	result, err := MergeImageTags86(sampleValues86, overrides)
+func TestInject_OnlyTouchesTheEditedLine(t *testing.T) {
+	result, err := Inject(sampleValues88, []Override{{Component: "orchestration", Tag: "orchestration-new"}})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// Both zeebe and zeebeGateway tags should be updated
-	lines := strings.Split(result, "\n")
-	updatedCount := 0
-	for _, line := range lines {
-		if strings.TrimSpace(line) == "tag: 8.6.99" {
-			updatedCount++
-		}
-	}
-	if updatedCount != 2 {
-		t.Errorf("expected both zeebe and zeebeGateway tags to be 8.6.99, found %d occurrences", updatedCount)
+	want := strings.Replace(sampleValues88, "camunda/camunda\n    tag: 8.8.8", "camunda/camunda\n    tag: orchestration-new", 1)
+	if result != want {
+		t.Errorf("expected only the orchestration tag line to change, got:\n%s", result)
 	}
 }
 
-func TestMergeImageTags86_MultipleComponents(t *testing.T) {
-	overrides := &ValuesYAML86{
-		Console: &ComponentImage{
-			Image: ImageTag{Tag: "console-new"},
-		},
-		Zeebe: &ComponentImage{
-			Image: ImageTag{Tag: "zeebe-new"},
-		},
-		Identity: &ComponentImage{
-			Image: ImageTag{Tag: "identity-new"},
-		},
-	}
-
-	result, err := MergeImageTags86(sampleValues86, overrides)
+func TestInject_MultipleComponents(t *testing.T) {
+	result, err := Inject(sampleValues86, []Override{
+		{Component: "console", Tag: "console-new"},
+		{Component: "zeebe", Tag: "zeebe-new"},
+		{Component: "zeebeGateway", Tag: "zeebe-new"},
+		{Component: "identity", Tag: "identity-new"},
+	})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if !strings.Contains(result, "console-new") {
-		t.Errorf("expected console tag to be updated")
-	}
-	if !strings.Contains(result, "zeebe-new") {
-		t.Errorf("expected zeebe tag to be updated")
+	for _, want := range []string{"console-new", "identity-new"} {
+		if !strings.Contains(result, want) {
+			t.Errorf("expected %s in result", want)
+		}
 	}
-	if !strings.Contains(result, "identity-new") {
-		t.Errorf("expected identity tag to be updated")
+	if n := strings.Count(result, "tag: zeebe-new"); n != 2 {
+		t.Errorf("expected both zeebe and zeebeGateway tags to be updated, found %d occurrences", n)
 	}
 }
 
-func TestMergeImageTags86_NoOverrides(t *testing.T) {
-	result, err := MergeImageTags86(sampleValues86, nil)
+func TestInject_AllImageFields(t *testing.T) {
+	result, err := Inject(sampleValues810, []Override{{
+		Component:  "connectors",
+		Registry:   "registry.camunda.cloud",
+		Repository: "team-connectors/connectors-bundle",
+		Tag:        "8.10.1",
+		Digest:     "sha256:abc",
+	}})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if result != sampleValues86 {
-		t.Errorf("expected no changes when overrides is nil")
+	want := `connectors:
+  enabled: true
+  image:
+    registry: "registry.camunda.cloud"
+    repository: team-connectors/connectors-bundle
+    tag: 8.10.1
+    digest: sha256:abc
+orchestration:`
+	if !strings.Contains(result, want) {
+		t.Errorf("expected connectors image to be rewritten as\n%s\ngot:\n%s", want, result)
 	}
 }
 
-func TestMergeImageTags86_EmptyOverrides(t *testing.T) {
-	overrides := &ValuesYAML86{}
-
-	result, err := MergeImageTags86(sampleValues86, overrides)
+func TestInject_QuotesValuesYAMLWouldRetype(t *testing.T) {
+	values := "identity:\n  image:\n    tag: 8.9.0 # keep me\n"
+	result, err := Inject(values, []Override{{Component: "identity", Tag: "8.10"}})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	// Should still contain original tags
-	if !strings.Contains(result, "8.6.94") {
-		t.Errorf("expected console tag to remain unchanged")
+	// 8.10 would parse as the float 8.1 if written plain.
+	if want := "identity:\n  image:\n    tag: \"8.10\" # keep me\n"; result != want {
+		t.Errorf("expected %q, got %q", want, result)
 	}
 }
 
-func TestMergeImageTags86_ComponentNotFound(t *testing.T) {
-	// YAML without the console component
-	yamlWithoutConsole := `
-zeebe:
-  image:
-    tag: 8.6.34
-`
-
-	overrides := &ValuesYAML86{
-		Console: &ComponentImage{
-			Image: ImageTag{Tag: "1.2.3"},
-		},
+func TestInject_KeepsQuoteStyle(t *testing.T) {
+	values := "identity:\n  image:\n    tag: 'old'\n    registry:\n"
+	result, err := Inject(values, []Override{{Component: "identity", Tag: "new", Registry: "docker.io"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
 	}
 
-	_, err := MergeImageTags86(yamlWithoutConsole, overrides)
-	if err == nil {
-		t.Fatal("expected error when component not found")
-	}
-
-	if !strings.Contains(err.Error(), "console") {
-		t.Errorf("expected error message to mention 'console', got: %v", err)
+	if want := "identity:\n  image:\n    tag: 'new'\n    registry: docker.io\n"; result != want {
+		t.Errorf("expected %q, got %q", want, result)
 	}
 }
 
-func TestMergeImageTags86_ComponentWithoutImageSection(t *testing.T) {
-	// YAML with console but no image section
-	yamlWithoutImage := `
-console:
-  enabled: true
-zeebe:
+func TestInject_NestedComponent(t *testing.T) {
+	values := `webModeler:
   image:
-    tag: 8.6.34
+    tag: 8.10.0
+  restapi:
+    image:
+      repository: camunda/hub
+      # tag inherits webModeler.image.tag
+  webapp:
+    enabled: false
 `
-
-	overrides := &ValuesYAML86{
-		Console: &ComponentImage{
-			Image: ImageTag{Tag: "1.2.3"},
-		},
-	}
-
-	_, err := MergeImageTags86(yamlWithoutImage, overrides)
-	if err == nil {
-		t.Fatal("expected error when image section not found")
-	}
-
-	if !strings.Contains(err.Error(), "image") {
-		t.Errorf("expected error message to mention 'image', got: %v", err)
-	}
-}
-
-func TestMergeImageTags87_UsesChart86Logic(t *testing.T) {
-	overrides := &ValuesYAML87{
-		Console: &ComponentImage{
-			Image: ImageTag{Tag: "8.7-test"},
-		},
-	}
-
-	result, err := MergeImageTags87(sampleValues86, overrides)
+	result, err := Inject(values, []Override{{Component: "webModeler.restapi", Tag: "hub-test"}})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if !strings.Contains(result, "8.7-test") {
-		t.Errorf("expected console tag to be updated")
+	want := `webModeler:
+  image:
+    tag: 8.10.0
+  restapi:
+    image:
+      repository: camunda/hub
+      tag: hub-test
+      # tag inherits webModeler.image.tag
+  webapp:
+    enabled: false
+`
+	if result != want {
+		t.Errorf("expected tag inserted under webModeler.restapi.image, got:\n%s", result)
 	}
 }
 
-func TestMergeImageTags88_SingleComponent(t *testing.T) {
-	overrides := &ValuesYAML88{
-		Orchestration: &ComponentImage{
-			Image: ImageTag{Tag: "orchestration-new"},
-		},
-	}
-
-	result, err := MergeImageTags88(sampleValues88, overrides)
+func TestInject_NoOverrides(t *testing.T) {
+	result, err := Inject(sampleValues86, nil)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if !strings.Contains(result, "orchestration-new") {
-		t.Errorf("expected orchestration tag to be updated")
+	if result != sampleValues86 {
+		t.Errorf("expected no changes when overrides is nil")
 	}
 }
 
-func TestMergeImageTags88_MultipleComponents(t *testing.T) {
-	overrides := &ValuesYAML88{
-		Identity: &ComponentImage{
-			Image: ImageTag{Tag: "identity-88"},
-		},
-		Console: &ComponentImage{
-			Image: ImageTag{Tag: "console-88"},
-		},
-		Orchestration: &ComponentImage{
-			Image: ImageTag{Tag: "orchestration-88"},
-		},
-	}
-
-	result, err := MergeImageTags88(sampleValues88, overrides)
+func TestInject_EmptyOverride(t *testing.T) {
+	result, err := Inject(sampleValues86, []Override{{Component: "console"}})
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
 
-	if !strings.Contains(result, "identity-88") {
-		t.Errorf("expected identity tag to be updated")
-	}
-	if !strings.Contains(result, "console-88") {
-		t.Errorf("expected console tag to be updated")
-	}
-	if !strings.Contains(result, "orchestration-88") {
-		t.Errorf("expected orchestration tag to be updated")
+	if result != sampleValues86 {
+		t.Errorf("expected no changes for an override without fields")
 	}
 }
 
-func TestMergeImageTags88_NoOverrides(t *testing.T) {
-	result, err := MergeImageTags88(sampleValues88, nil)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func TestInject_UnknownComponent(t *testing.T) {
+	_, err := Inject(sampleValues88, []Override{{Component: "operator", Tag: "1.2.3"}})
+	if err == nil {
+		t.Fatal("expected error for a component outside chartmeta.ComponentSpecs")
 	}
 
-	if result != sampleValues88 {
-		t.Errorf("expected no changes when overrides is nil")
+	if !strings.Contains(err.Error(), "unknown component 'operator'") || !strings.Contains(err.Error(), "orchestration") {
+		t.Errorf("expected error to name the component and list the known ones, got: %v", err)
 	}
 }
 
-func TestMergeImageTags89_UsesChart88Logic(t *testing.T) {
-	overrides := &ValuesYAML89{
-		Orchestration: &ComponentImage{
-			Image: ImageTag{Tag: "8.9-test"},
-		},
-	}
-
-	result, err := MergeImageTags89(sampleValues88, overrides)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func TestInject_ComponentNotFound(t *testing.T) {
+	_, err := Inject(sampleValues810, []Override{{Component: "console", Tag: "1.2.3"}})
+	if err == nil {
+		t.Fatal("expected error when component not found")
 	}
 
-	if !strings.Contains(result, "8.9-test") {
-		t.Errorf("expected orchestration tag to be updated")
+	if !strings.Contains(err.Error(), "console") {
+		t.Errorf("expected error message to mention 'console', got: %v", err)
 	}
 }
 
-func TestMergeImageTags810_UpdatesOrchestration(t *testing.T) {
-	overrides := &ValuesYAML810{
-		Orchestration: &ComponentImage{
-			Image: ImageTag{Tag: "8.10-test"},
-		},
-	}
+func TestInject_ComponentWithoutImageSection(t *testing.T) {
+	// YAML with console but no image section
+	yamlWithoutImage := `
+console:
+  enabled: true
+zeebe:
+  image:
+    tag: 8.6.34
+`
 
-	result, err := MergeImageTags810(sampleValues88, overrides)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+	_, err := Inject(yamlWithoutImage, []Override{{Component: "console", Tag: "1.2.3"}})
+	if err == nil {
+		t.Fatal("expected error when image section not found")
 	}
 
-	if !strings.Contains(result, "8.10-test") {
-		t.Errorf("expected orchestration tag to be updated")
+	if !strings.Contains(err.Error(), "image") {
+		t.Errorf("expected error message to mention 'image', got: %v", err)
 	}
 }
 
-func TestMergeImageTags810_IgnoresConsole(t *testing.T) {
-	overrides := &ValuesYAML810{
-		Console: &ComponentImage{
-			Image: ImageTag{Tag: "console-should-be-ignored"},
-		},
-		WebModeler: &ComponentImage{
-			Image: ImageTag{Tag: "wm-810"},
-		},
-	}
-
-	result, err := MergeImageTags810(sampleValues810, overrides)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
-	}
-
-	if !strings.Contains(result, "wm-810") {
-		t.Errorf("expected webModeler tag to be updated to wm-810, got:\n%s", result)
-	}
-	if strings.Contains(result, "console-should-be-ignored") {
-		t.Errorf("expected console override to be ignored, got:\n%s", result)
+func TestInject_RejectsFlowStyleImage(t *testing.T) {
+	_, err := Inject("console:\n  image: {tag: 1.0.0}\n", []Override{{Component: "console", Tag: "1.2.3"}})
+	if err == nil {
+		t.Fatal("expected error for a flow-style image map")
 	}
 }
 
-func TestMergeImageTags_InvalidYAML(t *testing.T) {
+func TestInject_InvalidYAML(t *testing.T) {
 	invalidYAML := `
 this is not valid yaml:
   - missing colon here
     nested: [unclosed
 `
 
-	overrides := &ValuesYAML86{
-		Console: &ComponentImage{
-			Image: ImageTag{Tag: "1.2.3"},
-		},
-	}
-
-	_, err := MergeImageTags86(invalidYAML, overrides)
+	_, err := Inject(invalidYAML, []Override{{Component: "console", Tag: "1.2.3"}})
 	if err == nil {
 		t.Fatal("expected error for invalid YAML")
 	}
 }
 
-func TestMergeImageTags_PreservesOtherFields(t *testing.T) {
-	overrides := &ValuesYAML86{
-		Console: &ComponentImage{
-			Image: ImageTag{Tag: "new-tag"},
-		},
-	}
-
-	result, err := MergeImageTags86(sampleValues86, overrides)
-	if err != nil {
-		t.Fatalf("unexpected error: %v", err)
+func TestDeclares(t *testing.T) {
+	cases := []struct {
+		values    string
+		component string
+		want      bool
+	}{
+		{sampleValues86, "zeebeGateway", true},
+		{sampleValues86, "orchestration", false},
+		{sampleValues88, "console", true},
+		{sampleValues810, "console", false},
+		{"console:\n  enabled: true\n", "console", false},
+	}
+	for _, tc := range cases {
+		got, err := Declares(tc.values, tc.component)
+		if err != nil {
+			t.Fatalf("Declares(%s): %v", tc.component, err)
+		}
+		if got != tc.want {
+			t.Errorf("Declares(%s) = %v, want %v", tc.component, got, tc.want)
+		}
 	}
 
-	// Check that other fields are preserved
-	if !strings.Contains(result, "repository: camunda/console") {
-		t.Errorf("expected repository field to be preserved")
-	}
-	if !strings.Contains(result, "enabled: true") {
-		t.Errorf("expected enabled field to be preserved")
+	if _, err := Declares(sampleValues88, "operator"); err == nil {
+		t.Error("expected error for an unknown component")
 	}
 }
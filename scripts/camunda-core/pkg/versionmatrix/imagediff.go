@@ -0,0 +1,281 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versionmatrix
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// ImageChangeKind classifies how one image repository changed between two
+// chart versions.
+type ImageChangeKind string
+
+const (
+	ImageAdded      ImageChangeKind = "added"
+	ImageRemoved    ImageChangeKind = "removed"
+	ImageTagBump    ImageChangeKind = "tag-bump"
+	ImageDigestOnly ImageChangeKind = "digest-only" // same tag, different pinned digest
+)
+
+// Image components, in the order diffs are grouped and rendered. Every image
+// not owned by a Camunda component is a bundled subchart (or its helper).
+const (
+	ComponentOrchestration = "orchestration"
+	ComponentConnectors    = "connectors"
+	ComponentIdentity      = "identity"
+	ComponentOptimize      = "optimize"
+	ComponentWebModeler    = "web-modeler"
+	ComponentConsole       = "console"
+	ComponentSubcharts     = "subcharts"
+)
+
+var componentOrder = []string{
+	ComponentOrchestration, ComponentConnectors, ComponentIdentity, ComponentOptimize,
+	ComponentWebModeler, ComponentConsole, ComponentSubcharts,
+}
+
+// componentTitles are the headings used when rendering a diff.
+var componentTitles = map[string]string{
+	ComponentOrchestration: "Orchestration",
+	ComponentConnectors:    "Connectors",
+	ComponentIdentity:      "Identity",
+	ComponentOptimize:      "Optimize",
+	ComponentWebModeler:    "Web Modeler",
+	ComponentConsole:       "Console",
+	ComponentSubcharts:     "Subcharts",
+}
+
+// ImageChange is one classified image change. Image is the repository
+// (registry/path, no tag); From/To are the full references on either side
+// (From empty for added, To empty for removed).
+type ImageChange struct {
+	Component  string          `json:"component"`
+	Kind       ImageChangeKind `json:"kind"`
+	Image      string          `json:"image"`
+	From       string          `json:"from,omitempty"`
+	To         string          `json:"to,omitempty"`
+	Enterprise bool            `json:"enterprise,omitempty"`
+}
+
+// ImageDiff is the image-set difference between two chart versions. It is
+// also the JSON document `release-tools version-matrix --diff --json` emits
+// for the release-notes pipeline.
+type ImageDiff struct {
+	From    string        `json:"from"`
+	To      string        `json:"to"`
+	Changes []ImageChange `json:"changes"`
+}
+
+// ImageComponent maps an image reference to the chart component that ships
+// it, by repository name. Anything not owned by a Camunda component
+// (Elasticsearch, Keycloak, PostgreSQL, os-shell, …) is a subchart image.
+func ImageComponent(ref string) string {
+	repo, _, _ := splitImageRef(ref)
+	name := repo[strings.LastIndex(repo, "/")+1:]
+	switch {
+	case name == "camunda" || name == "zeebe" || name == "operate" || name == "tasklist":
+		return ComponentOrchestration
+	case strings.HasPrefix(name, "connectors"):
+		return ComponentConnectors
+	case name == "identity":
+		return ComponentIdentity
+	case name == "optimize":
+		return ComponentOptimize
+	case strings.HasPrefix(name, "web-modeler") || strings.HasPrefix(name, "modeler-") || strings.HasPrefix(name, "hub"):
+		return ComponentWebModeler
+	case strings.HasPrefix(name, "console"):
+		return ComponentConsole
+	default:
+		return ComponentSubcharts
+	}
+}
+
+// splitImageRef splits a reference into repository, tag and digest.
+func splitImageRef(ref string) (repo, tag, digest string) {
+	repo = ref
+	if i := strings.Index(repo, "@"); i >= 0 {
+		repo, digest = repo[:i], repo[i+1:]
+	}
+	if i := strings.LastIndex(repo, ":"); i > strings.LastIndex(repo, "/") {
+		repo, tag = repo[:i], repo[i+1:]
+	}
+	return repo, tag, digest
+}
+
+// DiffImages classifies the image changes from one chart version to another.
+// Standard and enterprise image sets are compared separately and matched by
+// repository: a repository only on one side is added/removed, a changed tag
+// is a tag bump, and an unchanged tag with a different digest is digest-only.
+// Changes are ordered by component (componentOrder), then image.
+func DiffImages(from, to ChartEntry) ImageDiff {
+	changes := diffImageSet(from.ChartImages, to.ChartImages, false)
+	changes = append(changes, diffImageSet(from.ChartEnterpriseImages, to.ChartEnterpriseImages, true)...)
+	slices.SortStableFunc(changes, func(a, b ImageChange) int {
+		if c := slices.Index(componentOrder, a.Component) - slices.Index(componentOrder, b.Component); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Image, b.Image); c != 0 {
+			return c
+		}
+		if a.Enterprise != b.Enterprise {
+			if a.Enterprise {
+				return 1
+			}
+			return -1
+		}
+		return strings.Compare(a.From+" "+a.To, b.From+" "+b.To)
+	})
+	if changes == nil {
+		changes = []ImageChange{}
+	}
+	return ImageDiff{From: from.ChartVersion, To: to.ChartVersion, Changes: changes}
+}
+
+func diffImageSet(from, to []string, enterprise bool) []ImageChange {
+	byRepo := func(refs []string) map[string][]string {
+		m := map[string][]string{}
+		for _, ref := range validImageRefs(refs) {
+			repo, _, _ := splitImageRef(ref)
+			m[repo] = append(m[repo], ref)
+		}
+		return m
+	}
+	before, after := byRepo(from), byRepo(to)
+
+	var changes []ImageChange
+	add := func(kind ImageChangeKind, repo, fromRef, toRef string) {
+		ref := toRef
+		if ref == "" {
+			ref = fromRef
+		}
+		changes = append(changes, ImageChange{
+			Component: ImageComponent(ref), Kind: kind, Image: repo,
+			From: fromRef, To: toRef, Enterprise: enterprise,
+		})
+	}
+	for repo, olds := range before {
+		news, ok := after[repo]
+		if !ok {
+			for _, ref := range olds {
+				add(ImageRemoved, repo, ref, "")
+			}
+			continue
+		}
+		// Refs present on both sides are unchanged; pair the leftovers in
+		// sorted order (a repository normally appears once per set).
+		olds, news = without(olds, news), without(news, olds)
+		slices.Sort(olds)
+		slices.Sort(news)
+		for i := 0; i < len(olds) || i < len(news); i++ {
+			switch {
+			case i >= len(news):
+				add(ImageRemoved, repo, olds[i], "")
+			case i >= len(olds):
+				add(ImageAdded, repo, "", news[i])
+			default:
+				_, oldTag, _ := splitImageRef(olds[i])
+				_, newTag, _ := splitImageRef(news[i])
+				kind := ImageTagBump
+				if oldTag == newTag {
+					kind = ImageDigestOnly
+				}
+				add(kind, repo, olds[i], news[i])
+			}
+		}
+	}
+	for repo, news := range after {
+		if _, ok := before[repo]; !ok {
+			for _, ref := range news {
+				add(ImageAdded, repo, "", ref)
+			}
+		}
+	}
+	return changes
+}
+
+// without returns the refs of a that are not in b.
+func without(a, b []string) []string {
+	var out []string
+	for _, ref := range a {
+		if !slices.Contains(b, ref) {
+			out = append(out, ref)
+		}
+	}
+	return out
+}
+
+// ImageVersion renders the changing part of a reference: its tag, plus the
+// short digest when pinned.
+func ImageVersion(ref string) string {
+	_, tag, digest := splitImageRef(ref)
+	if digest != "" {
+		if _, hex, ok := strings.Cut(digest, ":"); ok && len(hex) > 12 {
+			digest = digest[:len(digest)-len(hex)+12]
+		}
+		if tag == "" {
+			return digest
+		}
+		return tag + "@" + digest
+	}
+	return tag
+}
+
+// Markdown renders the changes grouped by component, one bullet per change.
+// An empty diff renders a single "no image changes" line.
+func (d ImageDiff) Markdown() string {
+	if len(d.Changes) == 0 {
+		return fmt.Sprintf("_No image changes between %s and %s._\n", d.From, d.To)
+	}
+	var b strings.Builder
+	current := ""
+	for _, c := range d.Changes {
+		if c.Component != current {
+			if current != "" {
+				b.WriteString("\n")
+			}
+			fmt.Fprintf(&b, "%s:\n\n", componentTitles[c.Component])
+			current = c.Component
+		}
+		suffix := ""
+		if c.Enterprise {
+			suffix = " (enterprise)"
+		}
+		switch c.Kind {
+		case ImageAdded:
+			fmt.Fprintf(&b, "- Added `%s`%s\n", c.To, suffix)
+		case ImageRemoved:
+			fmt.Fprintf(&b, "- Removed `%s`%s\n", c.From, suffix)
+		case ImageTagBump:
+			fmt.Fprintf(&b, "- `%s` %s → %s%s\n", c.Image, ImageVersion(c.From), ImageVersion(c.To), suffix)
+		case ImageDigestOnly:
+			fmt.Fprintf(&b, "- `%s` %s → %s (digest only)%s\n", c.Image, ImageVersion(c.From), ImageVersion(c.To), suffix)
+		}
+	}
+	return b.String()
+}
+
+// whatChangedSection renders the per-minor README's "What changed" section:
+// the newest chart version's image diff against the one before it. sorted is
+// newest-first; returns "" when there is nothing to compare.
+func whatChangedSection(sorted []ChartEntry) string {
+	if len(sorted) < 2 {
+		return ""
+	}
+	diff := DiffImages(sorted[1], sorted[0])
+	return fmt.Sprintf("## What changed\n\nImages in [%s](#helm-chart-%s) compared to [%s](#helm-chart-%s):\n\n%s",
+		diff.To, readmeAnchor(diff.To), diff.From, readmeAnchor(diff.From), diff.Markdown())
+}
@@ -0,0 +1,147 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versionmatrix
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestImageComponent(t *testing.T) {
+	cases := map[string]string{
+		"docker.io/camunda/camunda:8.8.1":                            ComponentOrchestration,
+		"docker.io/camunda/zeebe:8.7.30":                             ComponentOrchestration,
+		"docker.io/camunda/connectors-bundle:8.8.1":                  ComponentConnectors,
+		"docker.io/camunda/identity:8.8.1":                           ComponentIdentity,
+		"docker.io/camunda/optimize:8.8.1":                           ComponentOptimize,
+		"docker.io/camunda/web-modeler-restapi:8.8.1":                ComponentWebModeler,
+		"registry.camunda.cloud/web-modeler-ee/modeler-webapp:8.7.9": ComponentWebModeler,
+		"docker.io/camunda/hub:8.10.0":                               ComponentWebModeler,
+		"registry.camunda.cloud/console/console-sm:8.8.10":           ComponentConsole,
+		"docker.io/camunda/keycloak:26.3.3":                          ComponentSubcharts,
+		"docker.io/bitnamilegacy/elasticsearch:8.18.0":               ComponentSubcharts,
+	}
+	for ref, want := range cases {
+		if got := ImageComponent(ref); got != want {
+			t.Errorf("ImageComponent(%q) = %q, want %q", ref, got, want)
+		}
+	}
+}
+
+func TestDiffImages(t *testing.T) {
+	from := ChartEntry{
+		ChartVersion: "13.3.0",
+		ChartImages: []string{
+			"docker.io/camunda/camunda:8.8.0",
+			"docker.io/camunda/connectors-bundle:8.8.0",
+			"docker.io/camunda/optimize:8.8.0@sha256:1111111111111111111111",
+			"docker.io/bitnamilegacy/os-shell:12",
+			"docker.io/camunda/identity:8.8.0",
+		},
+		ChartEnterpriseImages: []string{"registry.camunda.cloud/vendor-ee/elasticsearch:8.18.0"},
+	}
+	to := ChartEntry{
+		ChartVersion: "13.4.0",
+		ChartImages: []string{
+			"docker.io/camunda/camunda:8.8.1",
+			"docker.io/camunda/connectors-bundle:8.8.0",
+			"docker.io/camunda/optimize:8.8.0@sha256:2222222222222222222222",
+			"docker.io/camunda/web-modeler-webapp:8.8.1",
+			"docker.io/camunda/identity:8.8.0",
+			"docker.io/camunda/hub:", // empty tag refs are ignored like in the README
+		},
+		ChartEnterpriseImages: []string{"registry.camunda.cloud/vendor-ee/elasticsearch:8.19.0"},
+	}
+	diff := DiffImages(from, to)
+
+	var got []string
+	for _, c := range diff.Changes {
+		got = append(got, c.Component+" "+string(c.Kind)+" "+c.Image)
+	}
+	want := []string{
+		"orchestration tag-bump docker.io/camunda/camunda",
+		"optimize digest-only docker.io/camunda/optimize",
+		"web-modeler added docker.io/camunda/web-modeler-webapp",
+		"subcharts removed docker.io/bitnamilegacy/os-shell",
+		"subcharts tag-bump registry.camunda.cloud/vendor-ee/elasticsearch",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("DiffImages changes:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if !diff.Changes[4].Enterprise || diff.Changes[0].Enterprise {
+		t.Errorf("enterprise flag must follow the image set: %+v", diff.Changes)
+	}
+
+	md := diff.Markdown()
+	for _, c := range []string{
+		"Orchestration:\n\n- `docker.io/camunda/camunda` 8.8.0 → 8.8.1\n",
+		"- `docker.io/camunda/optimize` 8.8.0@sha256:111111111111 → 8.8.0@sha256:222222222222 (digest only)",
+		"- Added `docker.io/camunda/web-modeler-webapp:8.8.1`",
+		"- Removed `docker.io/bitnamilegacy/os-shell:12`",
+		"- `registry.camunda.cloud/vendor-ee/elasticsearch` 8.18.0 → 8.19.0 (enterprise)",
+	} {
+		if !strings.Contains(md, c) {
+			t.Errorf("Markdown missing %q:\n%s", c, md)
+		}
+	}
+
+	data, err := json.Marshal(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var back ImageDiff
+	if err := json.Unmarshal(data, &back); err != nil || len(back.Changes) != 5 || back.From != "13.3.0" {
+		t.Errorf("JSON round trip: %v %s", err, data)
+	}
+}
+
+func TestDiffImagesEmpty(t *testing.T) {
+	e := ChartEntry{ChartVersion: "13.3.0", ChartImages: []string{"docker.io/camunda/camunda:8.8.0"}}
+	diff := DiffImages(e, ChartEntry{ChartVersion: "13.3.1", ChartImages: e.ChartImages})
+	if len(diff.Changes) != 0 {
+		t.Fatalf("expected no changes, got %+v", diff.Changes)
+	}
+	if data, _ := json.Marshal(diff); !strings.Contains(string(data), `"changes":[]`) {
+		t.Errorf("an empty diff must serialize changes as [], got %s", data)
+	}
+	if md := diff.Markdown(); md != "_No image changes between 13.3.0 and 13.3.1._\n" {
+		t.Errorf("unexpected empty markdown %q", md)
+	}
+}
+
+func TestRenderMinorReadmeWhatChanged(t *testing.T) {
+	entries := []ChartEntry{
+		{ChartVersion: "14.6.1", ChartImages: []string{"docker.io/camunda/camunda:8.9.11"}},
+		{ChartVersion: "14.7.0", ChartImages: []string{"docker.io/camunda/camunda:8.9.12"}},
+	}
+	got := RenderMinorReadme("8.9", entries, BucketSupportStandard, Lifecycle{StdSupportUntil: "2027-10-13"}, nil)
+	want := "## What changed\n\nImages in [14.7.0](#helm-chart-1470) compared to [14.6.1](#helm-chart-1461):\n\n" +
+		"Orchestration:\n\n- `docker.io/camunda/camunda` 8.9.11 → 8.9.12\n"
+	if !strings.Contains(got, want) {
+		t.Errorf("RenderMinorReadme missing the What changed section:\n%s", got)
+	}
+	if strings.Index(got, "## What changed") > strings.Index(got, "## Helm chart 14.7.0") {
+		t.Error("What changed must precede the per-version sections")
+	}
+	if _, ok := ParseReadmeSections(got)["What changed"]; ok {
+		t.Error("What changed must not be parsed as a chart version section")
+	}
+
+	single := RenderMinorReadme("8.9", entries[:1], BucketSupportStandard, Lifecycle{}, nil)
+	if strings.Contains(single, "## What changed") {
+		t.Error("a single chart version has nothing to compare against")
+	}
+}
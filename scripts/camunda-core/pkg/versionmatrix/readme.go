@@ -272,7 +272,8 @@ func RenderIndex(cfg *ChartVersionsConfig, entriesByApp map[string][]ChartEntry)
 
 // RenderMinorReadme renders a full per-minor version-matrix README from its
 // entries: back-link, title, lifecycle status line, summary table over every
-// chart version, then the per-version sections — newest first, anchors
+// chart version, the "What changed" image diff of the newest chart version
+// against its predecessor, then the per-version sections — newest first, anchors
 // preserved as "## Helm chart <v>" headings.
 //
 // existingSections maps chart version → its current "## Helm chart <v>…"
@@ -297,6 +298,9 @@ func RenderMinorReadme(app string, entries []ChartEntry, bucket string, lc Lifec
 	}
 	b.WriteString(chartTable("", sorted, 0))
 	b.WriteString("\n" + enterpriseImagesNote + "\n")
+	if section := whatChangedSection(sorted); section != "" {
+		b.WriteString("\n" + section)
+	}
 	b.WriteString("\n---\n")
 	for _, e := range sorted {
 		section, ok := existingSections[e.ChartVersion]
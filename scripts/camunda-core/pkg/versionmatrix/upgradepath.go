@@ -0,0 +1,81 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versionmatrix
+
+import (
+	"fmt"
+)
+
+// UpgradeHop is one step of a multi-minor upgrade path: the app version
+// installed (first hop) or upgraded to (later hops), and the released chart
+// version that carries it.
+type UpgradeHop struct {
+	AppVersion string `json:"appVersion"`
+	// ChartVersion is the newest released chart for AppVersion. Empty only
+	// for the final hop when that minor has no release yet (e.g. an alpha
+	// that exists solely as the on-disk chart).
+	ChartVersion string `json:"chartVersion,omitempty"`
+	// Bucket is the lifecycle bucket from charts/chart-versions.yaml.
+	Bucket string `json:"bucket"`
+}
+
+// PlanUpgradePath returns the hops needed to go from app version from to app
+// version to, e.g. "8.6" → "8.10" yields 8.6, 8.7, 8.8, 8.9, 8.10. Camunda only
+// supports sequential minor upgrades, so every intermediate minor is a hop;
+// the first hop is the install.
+//
+// Each minor must be classified in charts/chart-versions.yaml. Chart versions
+// come from version-matrix/camunda-<minor>/version-matrix.json (latest stable,
+// else latest pre-release), falling back to the lifecycle latestChart for
+// minors without a matrix file.
+func PlanUpgradePath(repoRoot, from, to string) ([]UpgradeHop, error) {
+	fromMajor, fromMinor, err := splitAppVersion(from)
+	if err != nil {
+		return nil, fmt.Errorf("upgrade path from: %w", err)
+	}
+	toMajor, toMinor, err := splitAppVersion(to)
+	if err != nil {
+		return nil, fmt.Errorf("upgrade path to: %w", err)
+	}
+	if fromMajor != toMajor {
+		return nil, fmt.Errorf("upgrade path %s → %s crosses major versions", from, to)
+	}
+	if fromMinor >= toMinor {
+		return nil, fmt.Errorf("upgrade path %s → %s: from must be an older minor than to", from, to)
+	}
+
+	cfg, err := LoadChartVersionsConfig(ChartVersionsPath(repoRoot))
+	if err != nil {
+		return nil, err
+	}
+
+	var hops []UpgradeHop
+	for minor := fromMinor; minor <= toMinor; minor++ {
+		appVersion := fmt.Sprintf("%d.%d", fromMajor, minor)
+		bucket := cfg.BucketOf(appVersion)
+		if bucket == "" {
+			return nil, fmt.Errorf("upgrade path %s → %s: %s is not listed in %s", from, to, appVersion, ChartVersionsPath(repoRoot))
+		}
+		chartVersion, err := LatestChartVersion(repoRoot, appVersion)
+		if err != nil {
+			chartVersion = cfg.CamundaSupportLifecycle[appVersion].LatestChart
+		}
+		if chartVersion == "" && minor != toMinor {
+			return nil, fmt.Errorf("upgrade path %s → %s: no released chart for %s: %w", from, to, appVersion, err)
+		}
+		hops = append(hops, UpgradeHop{AppVersion: appVersion, ChartVersion: chartVersion, Bucket: bucket})
+	}
+	return hops, nil
+}
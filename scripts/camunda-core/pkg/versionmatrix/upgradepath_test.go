@@ -0,0 +1,148 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package versionmatrix
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// writeUpgradePathRepo lays out a minimal repo: chart-versions.yaml from
+// validYAML plus 8.7/8.8 so the path 8.6 → 8.10 is contiguous, and
+// version-matrix files for the given minors.
+func writeUpgradePathRepo(t *testing.T, matrices map[string]string) string {
+	t.Helper()
+	root := t.TempDir()
+	cv := strings.Replace(validYAML(), `    - "8.9"`, `    - "8.9"
+    - "8.8"
+    - "8.7"`, 1)
+	cv = strings.Replace(cv, `  "8.6":`, `  "8.8":  { released: "2025-10-14", stdSupportUntil: "2027-04-13" }
+  "8.7":  { released: "2025-04-08", stdSupportUntil: "2026-10-13" }
+  "8.6":`, 1)
+	if err := os.MkdirAll(filepath.Join(root, "charts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(ChartVersionsPath(root), []byte(cv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	for minor, content := range matrices {
+		dir := filepath.Join(root, "version-matrix", "camunda-"+minor)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "version-matrix.json"), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return root
+}
+
+func TestPlanUpgradePath(t *testing.T) {
+	root := writeUpgradePathRepo(t, map[string]string{
+		"8.6": `[{"chart_version":"11.9.0"},{"chart_version":"11.10.1"}]`,
+		"8.7": `[{"chart_version":"12.7.0"}]`,
+		"8.8": `[{"chart_version":"13.3.0"},{"chart_version":"13.4.0-alpha1"}]`,
+		"8.9": `[{"chart_version":"14.0.0-alpha4"}]`,
+		// 8.10 has no matrix: unreleased final hop.
+	})
+
+	hops, err := PlanUpgradePath(root, "8.6", "8.10")
+	if err != nil {
+		t.Fatalf("PlanUpgradePath: %v", err)
+	}
+	want := []UpgradeHop{
+		{AppVersion: "8.6", ChartVersion: "11.10.1", Bucket: BucketSupportExtended},
+		{AppVersion: "8.7", ChartVersion: "12.7.0", Bucket: BucketSupportStandard},
+		{AppVersion: "8.8", ChartVersion: "13.3.0", Bucket: BucketSupportStandard},
+		{AppVersion: "8.9", ChartVersion: "14.0.0-alpha4", Bucket: BucketSupportStandard},
+		{AppVersion: "8.10", ChartVersion: "", Bucket: BucketAlpha},
+	}
+	if !reflect.DeepEqual(hops, want) {
+		t.Errorf("hops =\n  %+v\nwant\n  %+v", hops, want)
+	}
+}
+
+func TestPlanUpgradePathErrors(t *testing.T) {
+	root := writeUpgradePathRepo(t, map[string]string{
+		"8.6": `[{"chart_version":"11.10.1"}]`,
+		"8.8": `[{"chart_version":"13.3.0"}]`,
+	})
+	tests := []struct {
+		from, to string
+		want     string
+	}{
+		{"8.9", "8.6", "must be an older minor"},
+		{"8.8", "8.8", "must be an older minor"},
+		{"7.9", "8.8", "crosses major versions"},
+		{"8", "8.8", "invalid app version format"},
+		{"8.5", "8.6", "8.5 is not listed"},
+		// 8.7 is an intermediate hop with neither a matrix nor latestChart.
+		{"8.6", "8.8", "no released chart for 8.7"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.from+"-"+tt.to, func(t *testing.T) {
+			_, err := PlanUpgradePath(root, tt.from, tt.to)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("PlanUpgradePath(%s, %s) error = %v, want containing %q", tt.from, tt.to, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPlanUpgradePathLatestChartFallback(t *testing.T) {
+	// EOL minors keep their last chart in the lifecycle block once the
+	// version-matrix directory is gone.
+	root := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(root, "charts"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	cv := `
+camundaVersions:
+  endOfLife:
+    - "8.2"
+    - "8.1"
+camundaSupportLifecycle:
+  "8.2": { released: "2023-04-11", eolSince: "2024-10-08", latestChart: "8.2.34" }
+  "8.1": { released: "2022-10-11", eolSince: "2024-04-09", latestChart: "8.1.29" }
+`
+	if err := os.WriteFile(ChartVersionsPath(root), []byte(cv), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	hops, err := PlanUpgradePath(root, "8.1", "8.2")
+	if err != nil {
+		t.Fatalf("PlanUpgradePath: %v", err)
+	}
+	if len(hops) != 2 || hops[0].ChartVersion != "8.1.29" || hops[1].ChartVersion != "8.2.34" {
+		t.Errorf("hops = %+v", hops)
+	}
+}
+
+func TestPlanUpgradePathRepo(t *testing.T) {
+	hops, err := PlanUpgradePath(repoRootFromTest(t), "8.6", "8.10")
+	if err != nil {
+		t.Fatalf("PlanUpgradePath on checked-in data: %v", err)
+	}
+	if len(hops) != 5 || hops[0].AppVersion != "8.6" || hops[4].AppVersion != "8.10" {
+		t.Errorf("hops = %+v", hops)
+	}
+	for _, h := range hops[:len(hops)-1] {
+		if h.ChartVersion == "" {
+			t.Errorf("intermediate hop %s has no chart version", h.AppVersion)
+		}
+	}
+}
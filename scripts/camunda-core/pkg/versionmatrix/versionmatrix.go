@@ -45,6 +45,8 @@ func HasPreSetupScript(repoRoot, appVersion, filename string) bool {
 // ReleaseDate, HelmCLI, and ReleaseTag are release-time facts written once
 // (promotion writes helm_cli/release_tag, the public-release pipeline stamps
 // release_date from the published GitHub release) and never re-derived.
+// ChartSBOM is the path, relative to the version-matrix.json, of the chart's
+// CycloneDX SBOM written by release-tools image-report.
 type ChartEntry struct {
 	ChartVersion          string   `json:"chart_version"`
 	ChartImages           []string `json:"chart_images"`
@@ -52,6 +54,7 @@ type ChartEntry struct {
 	ReleaseDate           string   `json:"release_date,omitempty"`
 	HelmCLI               string   `json:"helm_cli,omitempty"`
 	ReleaseTag            string   `json:"release_tag,omitempty"`
+	ChartSBOM             string   `json:"chart_sbom,omitempty"`
 }
 
 // LoadVersionMatrix reads and parses the version-matrix.json for the given app version.
@@ -123,22 +126,31 @@ func LatestVersion(entries []ChartEntry) (string, error) {
 // "8.8" -> "8.7", "8.2" -> "8.1".
 // Returns an error if the minor version is 0 or the format is invalid.
 func PreviousAppVersion(appVersion string) (string, error) {
+	major, minor, err := splitAppVersion(appVersion)
+	if err != nil {
+		return "", err
+	}
+	if minor <= 0 {
+		return "", fmt.Errorf("cannot compute previous app version for %q: minor version is %d", appVersion, minor)
+	}
+	return fmt.Sprintf("%d.%d", major, minor-1), nil
+}
+
+// splitAppVersion parses a "major.minor" app version.
+func splitAppVersion(appVersion string) (major, minor int, err error) {
 	parts := strings.SplitN(appVersion, ".", 2)
 	if len(parts) != 2 {
-		return "", fmt.Errorf("invalid app version format %q: expected major.minor", appVersion)
+		return 0, 0, fmt.Errorf("invalid app version format %q: expected major.minor", appVersion)
 	}
-	major, err := strconv.Atoi(parts[0])
+	major, err = strconv.Atoi(parts[0])
 	if err != nil {
-		return "", fmt.Errorf("invalid major version %q: %w", parts[0], err)
+		return 0, 0, fmt.Errorf("invalid major version %q: %w", parts[0], err)
 	}
-	minor, err := strconv.Atoi(parts[1])
+	minor, err = strconv.Atoi(parts[1])
 	if err != nil {
-		return "", fmt.Errorf("invalid minor version %q: %w", parts[1], err)
+		return 0, 0, fmt.Errorf("invalid minor version %q: %w", parts[1], err)
 	}
-	if minor <= 0 {
-		return "", fmt.Errorf("cannot compute previous app version for %q: minor version is %d", appVersion, minor)
-	}
-	return fmt.Sprintf("%d.%d", major, minor-1), nil
+	return major, minor, nil
 }
 
 // ResolveUpgradeFromVersion determines the "from" chart version for upgrade flows.
@@ -168,22 +180,29 @@ func ResolveUpgradeFromVersion(repoRoot, appVersion, flow string) (string, error
 		return "", fmt.Errorf("unsupported upgrade flow %q: expected upgrade-patch, upgrade-minor, or modular-upgrade-minor", flow)
 	}
 
-	entries, err := LoadVersionMatrix(repoRoot, lookupVersion)
+	version, err := LatestChartVersion(repoRoot, lookupVersion)
 	if err != nil {
-		return "", fmt.Errorf("load version-matrix for %s (flow %s): %w", lookupVersion, flow, err)
+		return "", fmt.Errorf("%w (flow %s)", err, flow)
 	}
+	return version, nil
+}
 
+// LatestChartVersion returns the newest released chart version for an app
+// version from its version-matrix.json. Stable versions are preferred; when
+// none exists (alpha-only minors such as a not-yet-GA 8.x), the latest
+// pre-release is returned instead. Once a stable version is published it wins.
+func LatestChartVersion(repoRoot, appVersion string) (string, error) {
+	entries, err := LoadVersionMatrix(repoRoot, appVersion)
+	if err != nil {
+		return "", fmt.Errorf("load version-matrix for %s: %w", appVersion, err)
+	}
 	version, err := LatestStableVersion(entries)
 	if err != nil {
-		// No stable version available — fall back to latest version including pre-release.
-		// This handles app versions that only have alpha/RC chart releases (e.g., 8.9).
-		// Once a stable version is published, LatestStableVersion will return it instead.
 		version, err = LatestVersion(entries)
 		if err != nil {
-			return "", fmt.Errorf("find latest version for %s (flow %s): %w", lookupVersion, flow, err)
+			return "", fmt.Errorf("find latest version for %s: %w", appVersion, err)
 		}
 	}
-
 	return version, nil
 }
 
@@ -20,6 +20,10 @@ against cached results, and outputs an annotated matrix with a "cached" field
 on each entry. The input is the value of the matrix output emitted by
 deploy-camunda matrix plan.
 
+Each entry is checked against a hash of its own scenario's inputs, so a
+change to one scenario's values layers or hooks leaves the other entries of
+the same chart version cached.
+
 Entries where cached=true can be routed to a fast-path job that skips
 the full GKE deploy+test cycle.
 
@@ -35,11 +39,9 @@ var (
 )
 
 func init() {
-	annotateMatrixCmd.Flags().StringVar(&annotateSHA, "sha", "", "PR HEAD commit SHA to check cached results against (required)")
+	annotateMatrixCmd.Flags().StringVar(&annotateSHA, "sha", "", "PR HEAD commit SHA to check cached results against (required for the status store)")
 	annotateMatrixCmd.Flags().StringVar(&annotateRepoRoot, "repo-root", ".", "Repository root directory")
 	annotateMatrixCmd.Flags().DurationVar(&annotateTTL, "ttl", cache.DefaultTTL, "Maximum age of cached results")
-
-	_ = annotateMatrixCmd.MarkFlagRequired("sha")
 }
 
 // matrixJSON represents the top-level matrix structure: {"include": [...]}
@@ -64,31 +66,23 @@ func runAnnotateMatrix(cmd *cobra.Command, args []string) error {
 		return json.NewEncoder(os.Stdout).Encode(matrix)
 	}
 
-	// Create GitHub client.
-	client, err := cache.NewGitHubClient()
+	// Create the result store.
+	store, err := newStore(annotateSHA)
 	if err != nil {
-		// If we can't connect to GitHub (e.g., running locally), pass through uncached.
-		fmt.Fprintf(os.Stderr, "Warning: cannot create GitHub client (%v), marking all entries as uncached\n", err)
+		// If we can't reach the store (e.g., running locally), pass through uncached.
+		fmt.Fprintf(os.Stderr, "Warning: cannot create result store (%v), marking all entries as uncached\n", err)
 		for i := range matrix.Include {
 			matrix.Include[i]["cached"] = "false"
 		}
 		return json.NewEncoder(os.Stdout).Encode(matrix)
 	}
 
-	// Fetch all ci-cache statuses for this SHA (one API call).
-	statuses, err := client.GetStatuses(annotateSHA)
-	if err != nil {
-		fmt.Fprintf(os.Stderr, "Warning: cannot fetch statuses (%v), marking all entries as uncached\n", err)
-		for i := range matrix.Include {
-			matrix.Include[i]["cached"] = "false"
-		}
-		return json.NewEncoder(os.Stdout).Encode(matrix)
-	}
-
-	// Pre-compute content hashes per version (avoid recomputing for each entry).
-	hashCache := make(map[string]string)
+	// Content hashes per scenario and flow; entries that differ only by
+	// platform share one.
+	hashCache := make(map[hash.Scenario]string)
 
 	cachedCount := 0
+	var storeErr error
 	for i, entry := range matrix.Include {
 		version, _ := entry["version"].(string)
 		shortname, _ := entry["shortname"].(string)
@@ -99,21 +93,36 @@ func runAnnotateMatrix(cmd *cobra.Command, args []string) error {
 			continue
 		}
 
-		// Get or compute the content hash for this version.
-		contentHash, ok := hashCache[version]
+		// Get or compute the content hash for this scenario.
+		scenario := hash.Scenario{Version: version, Shortname: shortname, Flow: flow}
+		contentHash, ok := hashCache[scenario]
 		if !ok {
 			var hashErr error
-			contentHash, hashErr = hash.Compute(annotateRepoRoot, version)
+			contentHash, hashErr = hash.ComputeScenario(annotateRepoRoot, scenario)
 			if hashErr != nil {
-				fmt.Fprintf(os.Stderr, "Warning: cannot compute hash for version %s (%v), marking as uncached\n", version, hashErr)
+				fmt.Fprintf(os.Stderr, "Warning: cannot compute hash for %s (%v), marking as uncached\n", cache.StatusContext(version, shortname, flow), hashErr)
 				matrix.Include[i]["cached"] = "false"
 				continue
 			}
-			hashCache[version] = contentHash
+			hashCache[scenario] = contentHash
 		}
 
-		context := cache.StatusContext(version, shortname, flow)
-		if cache.Check(statuses, context, contentHash, annotateTTL) {
+		// The status store fetches all statuses of the SHA once; after a
+		// failed lookup the remaining entries are not looked up either.
+		cached := false
+		if storeErr == nil {
+			cached, storeErr = cache.Lookup(store, cache.Key{
+				SHA:       annotateSHA,
+				Version:   version,
+				Shortname: shortname,
+				Flow:      flow,
+				Hash:      contentHash,
+			}, annotateTTL)
+			if storeErr != nil {
+				fmt.Fprintf(os.Stderr, "Warning: cannot look up cached results (%v), marking remaining entries as uncached\n", storeErr)
+			}
+		}
+		if cached {
 			matrix.Include[i]["cached"] = "true"
 			cachedCount++
 		} else {
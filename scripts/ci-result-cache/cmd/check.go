@@ -15,8 +15,9 @@ var checkCmd = &cobra.Command{
 	Short: "Check if a scenario result is cached and valid",
 	Long: `Check verifies whether a scenario has a valid cached result by:
 
-1. Computing the current content hash for the chart version
-2. Looking up the commit status on the PR HEAD commit
+1. Computing the current content hash over the scenario's inputs
+2. Looking up the result in the store (the commit status on the PR HEAD
+   commit, or the content-addressed record for the hash)
 3. Verifying the hash matches and the result is within TTL
 
 Exit codes:
@@ -35,38 +36,45 @@ var (
 )
 
 func init() {
-	checkCmd.Flags().StringVar(&checkSHA, "sha", "", "PR HEAD commit SHA to check (required)")
+	checkCmd.Flags().StringVar(&checkSHA, "sha", "", "PR HEAD commit SHA to check (required for the status store)")
 	checkCmd.Flags().StringVar(&checkVersion, "version", "", "Chart version (e.g., 8.9) (required)")
 	checkCmd.Flags().StringVar(&checkShortname, "shortname", "", "Scenario shortname (e.g., oske) (required)")
 	checkCmd.Flags().StringVar(&checkFlow, "flow", "", "Flow name (e.g., install, upgrade-minor) (required)")
 	checkCmd.Flags().StringVar(&checkRepoRoot, "repo-root", ".", "Repository root directory")
 	checkCmd.Flags().DurationVar(&checkTTL, "ttl", cache.DefaultTTL, "Maximum age of cached results (e.g., 24h, 12h, 0 to disable)")
 
-	_ = checkCmd.MarkFlagRequired("sha")
 	_ = checkCmd.MarkFlagRequired("version")
 	_ = checkCmd.MarkFlagRequired("shortname")
 	_ = checkCmd.MarkFlagRequired("flow")
 }
 
 func runCheck(cmd *cobra.Command, args []string) error {
-	contentHash, err := hash.Compute(checkRepoRoot, checkVersion)
+	contentHash, err := hash.ComputeScenario(checkRepoRoot, hash.Scenario{
+		Version:   checkVersion,
+		Shortname: checkShortname,
+		Flow:      checkFlow,
+	})
 	if err != nil {
 		return fmt.Errorf("computing content hash: %w", err)
 	}
 
-	client, err := cache.NewGitHubClient()
+	store, err := newStore(checkSHA)
 	if err != nil {
-		return fmt.Errorf("creating GitHub client: %w", err)
+		return err
 	}
 
-	statuses, err := client.GetStatuses(checkSHA)
+	context := cache.StatusContext(checkVersion, checkShortname, checkFlow)
+	cached, err := cache.Lookup(store, cache.Key{
+		SHA:       checkSHA,
+		Version:   checkVersion,
+		Shortname: checkShortname,
+		Flow:      checkFlow,
+		Hash:      contentHash,
+	}, checkTTL)
 	if err != nil {
-		return fmt.Errorf("fetching statuses: %w", err)
+		return fmt.Errorf("looking up cached result: %w", err)
 	}
 
-	context := cache.StatusContext(checkVersion, checkShortname, checkFlow)
-	cached := cache.Check(statuses, context, contentHash, checkTTL)
-
 	if cached {
 		fmt.Printf("CACHED: %s (hash: %s)\n", context, contentHash[:12])
 		return nil
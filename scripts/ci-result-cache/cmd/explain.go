@@ -0,0 +1,109 @@
+package cmd
+
+import (
+	"fmt"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"scripts/ci-result-cache/pkg/cache"
+	"scripts/ci-result-cache/pkg/hash"
+
+	"github.com/spf13/cobra"
+)
+
+var explainCmd = &cobra.Command{
+	Use:   "explain",
+	Short: "Show which inputs of a scenario changed since a git ref",
+	Long: `Explain resolves the inputs of one scenario — the values layers it deploys
+with, its registry entry, hooks and companion values, the chart templates that
+render for it, and the shared tooling and workflow files — and lists the files
+changed since --since that belong to them.
+
+Use it to understand why a scenario was not served from cache, e.g. with
+--since set to the commit the cached result was recorded for.
+
+Usage:
+  ci-result-cache explain --version 8.9 --shortname eske --flow install --since origin/main`,
+	RunE: runExplain,
+}
+
+var (
+	explainVersion   string
+	explainShortname string
+	explainFlow      string
+	explainSince     string
+	explainRepoRoot  string
+)
+
+func init() {
+	explainCmd.Flags().StringVar(&explainVersion, "version", "", "Chart version (e.g., 8.9) (required)")
+	explainCmd.Flags().StringVar(&explainShortname, "shortname", "", "Scenario shortname (e.g., oske) (required)")
+	explainCmd.Flags().StringVar(&explainFlow, "flow", "", "Flow name (e.g., install, upgrade-minor) (required)")
+	explainCmd.Flags().StringVar(&explainSince, "since", "", "Git ref to compare the working tree against (required)")
+	explainCmd.Flags().StringVar(&explainRepoRoot, "repo-root", ".", "Repository root directory")
+
+	_ = explainCmd.MarkFlagRequired("version")
+	_ = explainCmd.MarkFlagRequired("shortname")
+	_ = explainCmd.MarkFlagRequired("flow")
+	_ = explainCmd.MarkFlagRequired("since")
+}
+
+func runExplain(cmd *cobra.Command, args []string) error {
+	scenario := hash.Scenario{Version: explainVersion, Shortname: explainShortname, Flow: explainFlow}
+	inputs, err := hash.ResolveInputs(explainRepoRoot, scenario)
+	if err != nil {
+		return fmt.Errorf("resolving inputs: %w", err)
+	}
+	files, err := inputs.Files(explainRepoRoot)
+	if err != nil {
+		return fmt.Errorf("listing inputs: %w", err)
+	}
+	contentHash, err := hash.ComputeScenario(explainRepoRoot, scenario)
+	if err != nil {
+		return fmt.Errorf("computing content hash: %w", err)
+	}
+
+	out, err := exec.Command("git", "-C", explainRepoRoot, "diff", "--name-only", explainSince).Output()
+	if err != nil {
+		return fmt.Errorf("listing files changed since %s: %w", explainSince, err)
+	}
+
+	var changed, unrelated []string
+	for _, line := range strings.Split(strings.TrimSpace(string(out)), "\n") {
+		if line == "" {
+			continue
+		}
+		if inputs.Includes(filepath.FromSlash(line)) {
+			changed = append(changed, line)
+		} else {
+			unrelated = append(unrelated, line)
+		}
+	}
+
+	fmt.Printf("Scenario: %s (hash: %s)\n", cache.StatusContext(explainVersion, explainShortname, explainFlow), contentHash[:12])
+	if inputs.Scoped {
+		fmt.Printf("Inputs: %d files\n", len(files))
+	} else {
+		fmt.Printf("Inputs: %d files, whole chart version (%s)\n", len(files), inputs.Reason)
+	}
+	if len(inputs.Layers) > 0 {
+		fmt.Println("Values layers:")
+		for _, layer := range inputs.Layers {
+			fmt.Printf("  %s\n", layer)
+		}
+	}
+
+	if len(changed) == 0 {
+		fmt.Printf("No inputs changed since %s.\n", explainSince)
+	} else {
+		fmt.Printf("Inputs changed since %s (%d):\n", explainSince, len(changed))
+		for _, f := range changed {
+			fmt.Printf("  %s\n", f)
+		}
+	}
+	if len(unrelated) > 0 {
+		fmt.Printf("Changed files that do not affect this scenario: %d\n", len(unrelated))
+	}
+	return nil
+}
@@ -13,12 +13,14 @@ import (
 var recordCmd = &cobra.Command{
 	Use:   "record",
 	Short: "Record a passing scenario result as a commit status",
-	Long: `Record writes a GitHub commit status to the PR HEAD commit indicating that
-a scenario passed. The status includes a content hash and timestamp so that
-future cache checks can verify validity.
+	Long: `Record stores a passing scenario result together with its content hash and
+timestamp so that future cache checks can verify validity. The hash covers
+only the inputs of this scenario (see "ci-result-cache explain").
 
-The status is written to the PR HEAD commit (not the merge queue commit) so
-it persists across queue ejections.`,
+With the status store the result is a commit status on the PR HEAD commit
+(not the merge queue commit) so it persists across queue ejections. The fs
+and s3 stores key it by the content hash instead, so any PR or main with
+identical inputs reuses it.`,
 	RunE: runRecord,
 }
 
@@ -32,37 +34,44 @@ var (
 )
 
 func init() {
-	recordCmd.Flags().StringVar(&recordSHA, "sha", "", "PR HEAD commit SHA to record the status on (required)")
+	recordCmd.Flags().StringVar(&recordSHA, "sha", "", "PR HEAD commit SHA to record the status on (required for the status store)")
 	recordCmd.Flags().StringVar(&recordVersion, "version", "", "Chart version (e.g., 8.9) (required)")
 	recordCmd.Flags().StringVar(&recordShortname, "shortname", "", "Scenario shortname (e.g., oske) (required)")
 	recordCmd.Flags().StringVar(&recordFlow, "flow", "", "Flow name (e.g., install, upgrade-minor) (required)")
 	recordCmd.Flags().StringVar(&recordRepoRoot, "repo-root", ".", "Repository root directory")
 	recordCmd.Flags().StringVar(&recordTargetURL, "target-url", "", "URL to the CI run (optional, shown in GitHub UI)")
 
-	_ = recordCmd.MarkFlagRequired("sha")
 	_ = recordCmd.MarkFlagRequired("version")
 	_ = recordCmd.MarkFlagRequired("shortname")
 	_ = recordCmd.MarkFlagRequired("flow")
 }
 
 func runRecord(cmd *cobra.Command, args []string) error {
-	contentHash, err := hash.Compute(recordRepoRoot, recordVersion)
+	contentHash, err := hash.ComputeScenario(recordRepoRoot, hash.Scenario{
+		Version:   recordVersion,
+		Shortname: recordShortname,
+		Flow:      recordFlow,
+	})
 	if err != nil {
 		return fmt.Errorf("computing content hash: %w", err)
 	}
 
-	client, err := cache.NewGitHubClient()
+	store, err := newStore(recordSHA)
 	if err != nil {
-		return fmt.Errorf("creating GitHub client: %w", err)
+		return err
 	}
 
-	context := cache.StatusContext(recordVersion, recordShortname, recordFlow)
-	description := cache.FormatDescription(contentHash, time.Now())
-
-	if err := client.SetStatus(recordSHA, "success", context, description, recordTargetURL); err != nil {
-		return fmt.Errorf("setting commit status: %w", err)
+	key := cache.Key{
+		SHA:       recordSHA,
+		Version:   recordVersion,
+		Shortname: recordShortname,
+		Flow:      recordFlow,
+		Hash:      contentHash,
+	}
+	if err := store.Record(key, recordTargetURL, time.Now()); err != nil {
+		return fmt.Errorf("recording result: %w", err)
 	}
 
-	fmt.Printf("Recorded: %s = %s (hash: %s)\n", context, "success", contentHash[:12])
+	fmt.Printf("Recorded: %s = %s (hash: %s)\n", key.Context(), "success", contentHash[:12])
 	return nil
 }
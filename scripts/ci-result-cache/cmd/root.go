@@ -2,6 +2,9 @@ package cmd
 
 import (
 	"errors"
+	"fmt"
+
+	"scripts/ci-result-cache/pkg/cache"
 
 	"github.com/spf13/cobra"
 )
@@ -13,29 +16,60 @@ var ErrNotCached = errors.New("not cached")
 var rootCmd = &cobra.Command{
 	Use:   "ci-result-cache",
 	Short: "Progressive CI result caching for merge queue optimization",
-	Long: `ci-result-cache manages cached CI test results using GitHub commit statuses.
+	Long: `ci-result-cache manages cached CI test results.
 
 It enables progressive accumulation of test results across merge queue attempts:
 scenarios that already passed (for the same content hash) are skipped on re-queue,
 saving CI time and reducing flaky failure impact.
 
+Stores (--store):
+  status  GitHub commit statuses on the PR HEAD commit (default)
+  fs      Content-addressed files below --store-path, shared across commits
+  s3      Content-addressed objects in an S3-compatible bucket, shared across commits
+
 Commands:
   record           Record a passing scenario result as a commit status
   check            Check if a scenario result is cached and valid
   invalidate       Invalidate cached results for a scenario or version
-  annotate-matrix  Annotate a CI matrix JSON with cached/uncached flags`,
+  annotate-matrix  Annotate a CI matrix JSON with cached/uncached flags
+  explain          Show which inputs of a scenario changed since a git ref`,
 	SilenceErrors: true,
 	SilenceUsage:  true,
 }
 
+// storeConfig holds the result store selected by the persistent flags,
+// defaulting to the CI_CACHE_* environment variables.
+var storeConfig = cache.StoreConfigFromEnv()
+
 // Execute runs the root command.
 func Execute() error {
 	return rootCmd.Execute()
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(&storeConfig.Backend, "store", storeConfig.Backend, "Result store: status, fs or s3 (env CI_CACHE_STORE)")
+	rootCmd.PersistentFlags().StringVar(&storeConfig.Path, "store-path", storeConfig.Path, "Root directory of the fs store (env CI_CACHE_PATH)")
+	rootCmd.PersistentFlags().StringVar(&storeConfig.S3.Endpoint, "s3-endpoint", storeConfig.S3.Endpoint, "S3-compatible endpoint URL (env CI_CACHE_S3_ENDPOINT)")
+	rootCmd.PersistentFlags().StringVar(&storeConfig.S3.Bucket, "s3-bucket", storeConfig.S3.Bucket, "S3 bucket (env CI_CACHE_S3_BUCKET)")
+	rootCmd.PersistentFlags().StringVar(&storeConfig.S3.Region, "s3-region", storeConfig.S3.Region, "S3 region (env CI_CACHE_S3_REGION)")
+	rootCmd.PersistentFlags().StringVar(&storeConfig.S3.Prefix, "s3-prefix", storeConfig.S3.Prefix, "Key prefix inside the bucket (env CI_CACHE_S3_PREFIX)")
+
 	rootCmd.AddCommand(recordCmd)
 	rootCmd.AddCommand(checkCmd)
 	rootCmd.AddCommand(invalidateCmd)
 	rootCmd.AddCommand(annotateMatrixCmd)
+	rootCmd.AddCommand(explainCmd)
+}
+
+// newStore creates the configured result store. The status store keeps
+// results on a commit, so it needs --sha.
+func newStore(sha string) (cache.Store, error) {
+	if storeConfig.Backend == cache.StoreStatus && sha == "" {
+		return nil, fmt.Errorf("--sha is required with the %s store", cache.StoreStatus)
+	}
+	store, err := cache.NewStore(storeConfig)
+	if err != nil {
+		return nil, fmt.Errorf("creating %s store: %w", storeConfig.Backend, err)
+	}
+	return store, nil
 }
@@ -31,11 +31,27 @@ func StatusContext(version, shortname, flow string) string {
 
 // Entry represents a cached CI result.
 type Entry struct {
+	Context   string
 	Hash      string
 	Timestamp time.Time
 	State     string // "success", "pending", "failure", "error"
 }
 
+// Valid reports whether the entry is a passing result for currentHash that
+// has not expired. A ttl of 0 disables expiry.
+func (e Entry) Valid(currentHash string, ttl time.Duration) bool {
+	if e.State != "success" {
+		return false // explicitly invalidated or failed
+	}
+	if e.Hash != currentHash {
+		return false // content changed
+	}
+	if ttl > 0 && time.Since(e.Timestamp) > ttl {
+		return false // expired
+	}
+	return true
+}
+
 // ParseDescription extracts hash and timestamp from a status description.
 // Format: "hash:{sha256},ts:{unix_timestamp}"
 func ParseDescription(desc string) (Entry, error) {
@@ -208,24 +224,16 @@ func Check(statuses []commitStatus, context, currentHash string, ttl time.Durati
 		if s.Context != context {
 			continue
 		}
-		if s.State != "success" {
-			return false // explicitly invalidated or failed
-		}
-
-		entry, err := ParseDescription(s.Description)
-		if err != nil {
-			return false // malformed description
-		}
-
-		if entry.Hash != currentHash {
-			return false // content changed
-		}
-
-		if ttl > 0 && time.Since(entry.Timestamp) > ttl {
-			return false // expired
-		}
-
-		return true
+		return statusEntry(s).Valid(currentHash, ttl)
 	}
 	return false // no status found
 }
+
+// statusEntry converts a commit status into an Entry. A malformed
+// description yields an entry without hash, which is never valid.
+func statusEntry(s commitStatus) Entry {
+	entry, _ := ParseDescription(s.Description)
+	entry.Context = s.Context
+	entry.State = s.State
+	return entry
+}
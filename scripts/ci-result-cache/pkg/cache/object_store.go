@@ -0,0 +1,221 @@
+package cache
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Blob is a flat object storage addressed by slash-separated keys.
+type Blob interface {
+	// Get returns the object stored at key, and false when there is none.
+	Get(key string) ([]byte, bool, error)
+	// Put stores data at key, replacing any existing object.
+	Put(key string, data []byte) error
+	// List returns the keys starting with prefix in lexical order.
+	List(prefix string) ([]string, error)
+}
+
+// ObjectStore keeps results content-addressed in a Blob, one object per
+// scenario and content hash:
+//
+//	ci-cache/{version}/{shortname}/{flow}/{hash}.json
+//
+// Because the key does not involve the commit, a result recorded on one PR
+// is reused by any other PR or by main whose scenario inputs are identical,
+// and it survives force-pushes.
+type ObjectStore struct {
+	Blob Blob
+}
+
+// NewObjectStore creates a content-addressed store on blob.
+func NewObjectStore(blob Blob) *ObjectStore {
+	return &ObjectStore{Blob: blob}
+}
+
+// record is the JSON document stored per result.
+type record struct {
+	Context   string `json:"context"`
+	Hash      string `json:"hash"`
+	State     string `json:"state"`
+	Timestamp int64  `json:"ts"`
+	// SHA and TargetURL record where the result came from.
+	SHA       string `json:"sha,omitempty"`
+	TargetURL string `json:"target_url,omitempty"`
+}
+
+func (r record) entry() Entry {
+	return Entry{Context: r.Context, Hash: r.Hash, State: r.State, Timestamp: time.Unix(r.Timestamp, 0)}
+}
+
+func objectKey(context, hash string) string {
+	return path.Join(context, hash+".json")
+}
+
+func (s *ObjectStore) put(key string, r record) error {
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling record: %w", err)
+	}
+	if err := s.Blob.Put(key, data); err != nil {
+		return fmt.Errorf("storing %s: %w", key, err)
+	}
+	return nil
+}
+
+func (s *ObjectStore) get(key string) (record, bool, error) {
+	data, found, err := s.Blob.Get(key)
+	if err != nil {
+		return record{}, false, fmt.Errorf("reading %s: %w", key, err)
+	}
+	if !found {
+		return record{}, false, nil
+	}
+	var r record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return record{}, false, fmt.Errorf("decoding %s: %w", key, err)
+	}
+	return r, true, nil
+}
+
+// Record stores a "success" result under the key's context and hash.
+func (s *ObjectStore) Record(key Key, targetURL string, ts time.Time) error {
+	return s.put(objectKey(key.Context(), key.Hash), record{
+		Context:   key.Context(),
+		Hash:      key.Hash,
+		State:     "success",
+		Timestamp: ts.Unix(),
+		SHA:       key.SHA,
+		TargetURL: targetURL,
+	})
+}
+
+// Lookup returns the result stored for the key's context and hash,
+// regardless of the commit it was recorded on.
+func (s *ObjectStore) Lookup(key Key) (Entry, bool, error) {
+	r, found, err := s.get(objectKey(key.Context(), key.Hash))
+	if err != nil || !found {
+		return Entry{}, false, err
+	}
+	return r.entry(), true, nil
+}
+
+// Invalidate rewrites every matching "success" result with an "error" state.
+// Results are not bound to a commit, so filter.SHA is ignored: a result
+// invalidated from one PR is invalid for every PR sharing its inputs.
+func (s *ObjectStore) Invalidate(filter Filter) ([]Entry, error) {
+	prefix := "ci-cache/"
+	switch {
+	case filter.Version != "" && filter.Shortname != "" && filter.Flow != "":
+		prefix = StatusContext(filter.Version, filter.Shortname, filter.Flow) + "/"
+	case filter.Version != "":
+		prefix = fmt.Sprintf("ci-cache/%s/", filter.Version)
+	}
+
+	keys, err := s.Blob.List(prefix)
+	if err != nil {
+		return nil, fmt.Errorf("listing %s: %w", prefix, err)
+	}
+
+	var invalidated []Entry
+	for _, key := range keys {
+		r, found, err := s.get(key)
+		if err != nil {
+			return invalidated, err
+		}
+		if !found || r.State != "success" || !filter.Matches(r.Context) {
+			continue
+		}
+		r.State = "error"
+		if err := s.put(key, r); err != nil {
+			return invalidated, err
+		}
+		invalidated = append(invalidated, r.entry())
+	}
+	return invalidated, nil
+}
+
+// FSBlob stores objects as files below Root, e.g. a directory shared between
+// runners or restored from a CI cache.
+type FSBlob struct {
+	Root string
+}
+
+func (b *FSBlob) path(key string) string {
+	return filepath.Join(b.Root, filepath.FromSlash(key))
+}
+
+// Get reads the file for key.
+func (b *FSBlob) Get(key string) ([]byte, bool, error) {
+	data, err := os.ReadFile(b.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return data, true, nil
+}
+
+// Put writes the file for key through a temporary file, so concurrent
+// readers never see a partial object.
+func (b *FSBlob) Put(key string, data []byte) error {
+	target := b.path(key)
+	if err := os.MkdirAll(filepath.Dir(target), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(target), ".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), target)
+}
+
+// List walks the directory of prefix and returns the matching keys.
+func (b *FSBlob) List(prefix string) ([]string, error) {
+	dir := prefix
+	if !strings.HasSuffix(dir, "/") {
+		dir = path.Dir(dir)
+	}
+
+	var keys []string
+	err := filepath.WalkDir(b.path(dir), func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if d.IsDir() || strings.HasPrefix(d.Name(), ".tmp-") {
+			return nil
+		}
+		rel, err := filepath.Rel(b.Root, p)
+		if err != nil {
+			return err
+		}
+		if key := filepath.ToSlash(rel); strings.HasPrefix(key, prefix) {
+			keys = append(keys, key)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
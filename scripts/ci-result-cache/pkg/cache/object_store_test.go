@@ -0,0 +1,135 @@
+package cache
+
+import (
+	"testing"
+	"time"
+)
+
+// testObjectStore runs the backend-independent store behavior against blob.
+func testObjectStore(t *testing.T, newBlob func(t *testing.T) Blob) {
+	key := Key{SHA: "pr-a", Version: "8.9", Shortname: "eske", Flow: "install", Hash: "abc123def4567890"}
+
+	t.Run("record and lookup", func(t *testing.T) {
+		store := NewObjectStore(newBlob(t))
+		if cached, err := Lookup(store, key, DefaultTTL); err != nil || cached {
+			t.Fatalf("expected miss before record, got cached=%v err=%v", cached, err)
+		}
+		if err := store.Record(key, "https://ci/run/1", time.Now()); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+		if cached, err := Lookup(store, key, DefaultTTL); err != nil || !cached {
+			t.Fatalf("expected hit after record, got cached=%v err=%v", cached, err)
+		}
+
+		changed := key
+		changed.Hash = "fff000"
+		if cached, _ := Lookup(store, changed, DefaultTTL); cached {
+			t.Error("expected miss for a different content hash")
+		}
+	})
+
+	t.Run("reused across commits", func(t *testing.T) {
+		store := NewObjectStore(newBlob(t))
+		if err := store.Record(key, "", time.Now()); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+		other := key
+		other.SHA = "main"
+		if cached, err := Lookup(store, other, DefaultTTL); err != nil || !cached {
+			t.Errorf("expected a result recorded on another commit to be reused, got cached=%v err=%v", cached, err)
+		}
+	})
+
+	t.Run("ttl", func(t *testing.T) {
+		store := NewObjectStore(newBlob(t))
+		if err := store.Record(key, "", time.Now().Add(-48*time.Hour)); err != nil {
+			t.Fatalf("Record: %v", err)
+		}
+		if cached, _ := Lookup(store, key, 24*time.Hour); cached {
+			t.Error("expected miss for an expired result")
+		}
+		if cached, _ := Lookup(store, key, 0); !cached {
+			t.Error("expected hit with TTL disabled")
+		}
+	})
+
+	t.Run("invalidate", func(t *testing.T) {
+		store := NewObjectStore(newBlob(t))
+		keys := []Key{
+			key,
+			{Version: "8.9", Shortname: "eske", Flow: "install", Hash: "second"},
+			{Version: "8.9", Shortname: "oske", Flow: "install", Hash: "h1"},
+			{Version: "8.8", Shortname: "eske", Flow: "install", Hash: "h2"},
+			{Version: "8.10", Shortname: "eske", Flow: "install", Hash: "h3"},
+		}
+		for _, k := range keys {
+			if err := store.Record(k, "", time.Now()); err != nil {
+				t.Fatalf("Record: %v", err)
+			}
+		}
+
+		invalidated, err := store.Invalidate(Filter{Version: "8.9", Shortname: "eske", Flow: "install"})
+		if err != nil {
+			t.Fatalf("Invalidate: %v", err)
+		}
+		if len(invalidated) != 2 {
+			t.Errorf("expected both hashes of the scenario invalidated, got %+v", invalidated)
+		}
+		if cached, _ := Lookup(store, key, DefaultTTL); cached {
+			t.Error("expected miss after invalidation")
+		}
+		if cached, _ := Lookup(store, keys[2], DefaultTTL); !cached {
+			t.Error("expected another scenario to stay cached")
+		}
+
+		invalidated, err = store.Invalidate(Filter{Version: "8.9"})
+		if err != nil {
+			t.Fatalf("Invalidate: %v", err)
+		}
+		if len(invalidated) != 1 || invalidated[0].Context != "ci-cache/8.9/oske/install" {
+			t.Errorf("expected only the remaining 8.9 result invalidated, got %+v", invalidated)
+		}
+
+		invalidated, err = store.Invalidate(Filter{SHA: "ignored"})
+		if err != nil {
+			t.Fatalf("Invalidate: %v", err)
+		}
+		if len(invalidated) != 2 {
+			t.Errorf("expected the 8.8 and 8.10 results invalidated, got %+v", invalidated)
+		}
+		for _, k := range keys {
+			if cached, _ := Lookup(store, k, DefaultTTL); cached {
+				t.Errorf("expected %s to be invalidated", k.Context())
+			}
+		}
+	})
+}
+
+func TestObjectStore_FS(t *testing.T) {
+	testObjectStore(t, func(t *testing.T) Blob {
+		return &FSBlob{Root: t.TempDir()}
+	})
+}
+
+func TestFilterMatches(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  Filter
+		context string
+		want    bool
+	}{
+		{"exact match", Filter{Version: "8.9", Shortname: "eske", Flow: "install"}, "ci-cache/8.9/eske/install", true},
+		{"exact mismatch", Filter{Version: "8.9", Shortname: "eske", Flow: "install"}, "ci-cache/8.9/eske/upgrade-minor", false},
+		{"version match", Filter{Version: "8.9"}, "ci-cache/8.9/oske/install", true},
+		{"version is not a prefix of another version", Filter{Version: "8.1"}, "ci-cache/8.10/oske/install", false},
+		{"shortname without version matches all", Filter{Shortname: "eske"}, "ci-cache/8.8/oske/install", true},
+		{"not a cache context", Filter{}, "ci/unit-tests", false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := tc.filter.Matches(tc.context); got != tc.want {
+				t.Errorf("Matches(%q) = %v, want %v", tc.context, got, tc.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,264 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config configures an S3-compatible object store (AWS S3, MinIO, GCS in
+// interoperability mode, ...). Requests use path-style addressing.
+type S3Config struct {
+	// Endpoint is the base URL, e.g. "https://s3.eu-west-1.amazonaws.com" or
+	// "http://minio:9000". It defaults to the AWS endpoint of Region.
+	Endpoint string
+	Bucket   string
+	// Region is used for request signing and defaults to "us-east-1".
+	Region string
+	// Prefix is prepended to every object key, e.g. "camunda-platform-helm/".
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	// SessionToken accompanies temporary credentials (AWS STS, OIDC role
+	// assumption). It is sent and signed as x-amz-security-token.
+	SessionToken string
+}
+
+// S3Blob is a Blob on an S3-compatible service, signed with AWS Signature
+// Version 4.
+type S3Blob struct {
+	Config     S3Config
+	HTTPClient *http.Client
+
+	endpoint *url.URL
+}
+
+// NewS3Blob validates cfg and creates the blob.
+func NewS3Blob(cfg S3Config) (*S3Blob, error) {
+	if cfg.Bucket == "" {
+		return nil, fmt.Errorf("the s3 store requires a bucket (--s3-bucket or CI_CACHE_S3_BUCKET)")
+	}
+	if cfg.AccessKey == "" || cfg.SecretKey == "" {
+		return nil, fmt.Errorf("the s3 store requires AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if cfg.Endpoint == "" {
+		cfg.Endpoint = fmt.Sprintf("https://s3.%s.amazonaws.com", cfg.Region)
+	}
+	endpoint, err := url.Parse(strings.TrimSuffix(cfg.Endpoint, "/"))
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid s3 endpoint %q", cfg.Endpoint)
+	}
+
+	return &S3Blob{
+		Config:     cfg,
+		HTTPClient: &http.Client{Timeout: 30 * time.Second},
+		endpoint:   endpoint,
+	}, nil
+}
+
+// Get downloads the object at key.
+func (b *S3Blob) Get(key string) ([]byte, bool, error) {
+	resp, err := b.do(http.MethodGet, b.Config.Prefix+key, nil, nil)
+	if err != nil {
+		return nil, false, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, false, nil
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, false, fmt.Errorf("reading response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, false, fmt.Errorf("S3 GET %s returned %d: %s", key, resp.StatusCode, string(body))
+	}
+	return body, true, nil
+}
+
+// Put uploads data to key.
+func (b *S3Blob) Put(key string, data []byte) error {
+	resp, err := b.do(http.MethodPut, b.Config.Prefix+key, nil, data)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("S3 PUT %s returned %d: %s", key, resp.StatusCode, string(body))
+	}
+	return nil
+}
+
+// listBucketResult is the ListObjectsV2 response.
+type listBucketResult struct {
+	Contents []struct {
+		Key string `xml:"Key"`
+	} `xml:"Contents"`
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+}
+
+// List pages through ListObjectsV2 for prefix.
+func (b *S3Blob) List(prefix string) ([]string, error) {
+	var keys []string
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {b.Config.Prefix + prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+
+		resp, err := b.do(http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading response: %w", err)
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("S3 list %s returned %d: %s", prefix, resp.StatusCode, string(body))
+		}
+
+		var result listBucketResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("decoding list response: %w", err)
+		}
+		for _, c := range result.Contents {
+			keys = append(keys, strings.TrimPrefix(c.Key, b.Config.Prefix))
+		}
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			break
+		}
+		token = result.NextContinuationToken
+	}
+	sort.Strings(keys)
+	return keys, nil
+}
+
+// do sends a signed request for the object key (or the bucket when key is
+// empty).
+func (b *S3Blob) do(method, key string, query url.Values, body []byte) (*http.Response, error) {
+	canonicalURI := b.endpoint.EscapedPath() + "/" + uriEncode(b.Config.Bucket, false)
+	if key != "" {
+		canonicalURI += "/" + uriEncode(key, true)
+	}
+	canonicalQuery := canonicalQueryString(query)
+
+	target := b.endpoint.Scheme + "://" + b.endpoint.Host + canonicalURI
+	if canonicalQuery != "" {
+		target += "?" + canonicalQuery
+	}
+	req, err := http.NewRequest(method, target, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+
+	payloadHash := sha256Hex(body)
+	amzDate := time.Now().UTC().Format("20060102T150405Z")
+	req.Header.Set("x-amz-date", amzDate)
+	req.Header.Set("x-amz-content-sha256", payloadHash)
+	if b.Config.SessionToken != "" {
+		req.Header.Set("x-amz-security-token", b.Config.SessionToken)
+	}
+	req.Header.Set("Authorization", signV4(b.Config, method, b.endpoint.Host, canonicalURI, canonicalQuery, payloadHash, amzDate))
+
+	resp, err := b.HTTPClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("S3 %s: %w", method, err)
+	}
+	return resp, nil
+}
+
+// signV4 returns the Authorization header value for a request whose signed
+// headers are host, x-amz-content-sha256 and x-amz-date, plus
+// x-amz-security-token when cfg has a session token.
+func signV4(cfg S3Config, method, host, canonicalURI, canonicalQuery, payloadHash, amzDate string) string {
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalHeaders := "host:" + host + "\n" + "x-amz-content-sha256:" + payloadHash + "\n" + "x-amz-date:" + amzDate + "\n"
+	if cfg.SessionToken != "" {
+		signedHeaders += ";x-amz-security-token"
+		canonicalHeaders += "x-amz-security-token:" + cfg.SessionToken + "\n"
+	}
+	canonicalRequest := strings.Join([]string{
+		method,
+		canonicalURI,
+		canonicalQuery,
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	date := amzDate[:8]
+	scope := date + "/" + cfg.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + sha256Hex([]byte(canonicalRequest))
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretKey), date)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	return fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		cfg.AccessKey, scope, signedHeaders, signature)
+}
+
+// canonicalQueryString sorts and encodes query parameters as SigV4 requires.
+func canonicalQueryString(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k, false)+"="+uriEncode(v, false))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// uriEncode percent-encodes everything but RFC 3986 unreserved characters,
+// keeping "/" when encoding an object key path.
+func uriEncode(s string, keepSlash bool) string {
+	var sb strings.Builder
+	for _, c := range []byte(s) {
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9',
+			c == '-', c == '_', c == '.', c == '~', keepSlash && c == '/':
+			sb.WriteByte(c)
+		default:
+			fmt.Fprintf(&sb, "%%%02X", c)
+		}
+	}
+	return sb.String()
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
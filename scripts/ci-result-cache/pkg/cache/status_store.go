@@ -0,0 +1,99 @@
+package cache
+
+import (
+	"fmt"
+	"time"
+)
+
+// StatusStore keeps results as commit statuses on the PR HEAD commit, with
+// the content hash and timestamp in the status description. Results are
+// bound to the commit they were recorded on.
+type StatusStore struct {
+	Client *GitHubClient
+
+	// statuses caches the ci-cache statuses per SHA so that looking up a
+	// whole matrix costs one API call.
+	statuses map[string][]commitStatus
+}
+
+// NewStatusStore creates a store backed by GitHub commit statuses.
+func NewStatusStore(client *GitHubClient) *StatusStore {
+	return &StatusStore{Client: client, statuses: make(map[string][]commitStatus)}
+}
+
+func (s *StatusStore) fetch(sha string) ([]commitStatus, error) {
+	if statuses, ok := s.statuses[sha]; ok {
+		return statuses, nil
+	}
+	statuses, err := s.Client.GetStatuses(sha)
+	if err != nil {
+		return nil, fmt.Errorf("fetching statuses: %w", err)
+	}
+	s.statuses[sha] = statuses
+	return statuses, nil
+}
+
+// Record sets a "success" status carrying the hash on key.SHA.
+func (s *StatusStore) Record(key Key, targetURL string, ts time.Time) error {
+	if key.SHA == "" {
+		return fmt.Errorf("the status store requires a commit SHA")
+	}
+	delete(s.statuses, key.SHA)
+	if err := s.Client.SetStatus(key.SHA, "success", key.Context(), FormatDescription(key.Hash, ts), targetURL); err != nil {
+		return fmt.Errorf("setting commit status: %w", err)
+	}
+	return nil
+}
+
+// Lookup returns the most recent status for the key's context on key.SHA.
+func (s *StatusStore) Lookup(key Key) (Entry, bool, error) {
+	if key.SHA == "" {
+		return Entry{}, false, fmt.Errorf("the status store requires a commit SHA")
+	}
+	statuses, err := s.fetch(key.SHA)
+	if err != nil {
+		return Entry{}, false, err
+	}
+	// GitHub returns statuses in reverse chronological order.
+	for _, status := range statuses {
+		if status.Context == key.Context() {
+			return statusEntry(status), true, nil
+		}
+	}
+	return Entry{}, false, nil
+}
+
+// Invalidate overwrites the matching statuses on filter.SHA with an "error"
+// state. "error" is used rather than "pending" because GitHub treats pending
+// statuses as in-progress checks that block PR merge.
+func (s *StatusStore) Invalidate(filter Filter) ([]Entry, error) {
+	if filter.SHA == "" {
+		return nil, fmt.Errorf("the status store requires a commit SHA")
+	}
+	statuses, err := s.fetch(filter.SHA)
+	if err != nil {
+		return nil, err
+	}
+	delete(s.statuses, filter.SHA)
+
+	// GitHub keeps every status entry (append-only), so the same context may
+	// appear several times. Only the most recent entry of each context counts;
+	// contexts whose latest entry is already invalidated are skipped.
+	seen := make(map[string]bool)
+	var invalidated []Entry
+	for _, status := range statuses {
+		if seen[status.Context] {
+			continue
+		}
+		seen[status.Context] = true
+
+		if status.State != "success" || !filter.Matches(status.Context) {
+			continue
+		}
+		if err := s.Client.SetStatus(filter.SHA, "error", status.Context, "invalidated", ""); err != nil {
+			return invalidated, fmt.Errorf("invalidating %s: %w", status.Context, err)
+		}
+		invalidated = append(invalidated, statusEntry(status))
+	}
+	return invalidated, nil
+}
@@ -0,0 +1,134 @@
+package cache
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+)
+
+// Key identifies one cached scenario result.
+type Key struct {
+	// SHA is the commit the result is recorded for. Only the status store
+	// keys results by it; the object stores keep it for provenance.
+	SHA       string
+	Version   string
+	Shortname string
+	Flow      string
+	// Hash is the content hash of the scenario's inputs.
+	Hash string
+}
+
+// Context returns the status context of the key's scenario.
+func (k Key) Context() string {
+	return StatusContext(k.Version, k.Shortname, k.Flow)
+}
+
+// Filter selects cached results to invalidate. Empty fields match anything,
+// but Shortname and Flow only narrow the scope together with Version, as
+// documented on the invalidate command.
+type Filter struct {
+	SHA       string
+	Version   string
+	Shortname string
+	Flow      string
+}
+
+// Matches reports whether a status context is in the filter's scope.
+func (f Filter) Matches(context string) bool {
+	switch {
+	case f.Version != "" && f.Shortname != "" && f.Flow != "":
+		return context == StatusContext(f.Version, f.Shortname, f.Flow)
+	case f.Version != "":
+		return strings.HasPrefix(context, fmt.Sprintf("ci-cache/%s/", f.Version))
+	default:
+		return strings.HasPrefix(context, "ci-cache/")
+	}
+}
+
+// Store persists cached scenario results.
+type Store interface {
+	// Record stores a passing result for key.
+	Record(key Key, targetURL string, ts time.Time) error
+	// Lookup returns the most recent entry recorded for key, and false when
+	// there is none.
+	Lookup(key Key) (Entry, bool, error)
+	// Invalidate marks every valid result in the filter's scope as invalid
+	// and returns the entries it invalidated.
+	Invalidate(filter Filter) ([]Entry, error)
+}
+
+// Lookup reports whether a valid result for key is cached in store.
+func Lookup(store Store, key Key, ttl time.Duration) (bool, error) {
+	entry, found, err := store.Lookup(key)
+	if err != nil || !found {
+		return false, err
+	}
+	return entry.Valid(key.Hash, ttl), nil
+}
+
+// Store backends.
+const (
+	StoreStatus = "status"
+	StoreFS     = "fs"
+	StoreS3     = "s3"
+)
+
+// StoreConfig selects and configures a store backend.
+type StoreConfig struct {
+	// Backend is one of StoreStatus, StoreFS or StoreS3.
+	Backend string
+	// Path is the root directory of the fs backend.
+	Path string
+	// S3 configures the s3 backend.
+	S3 S3Config
+}
+
+// StoreConfigFromEnv returns the store configuration given by the
+// CI_CACHE_STORE, CI_CACHE_PATH and CI_CACHE_S3_* environment variables and
+// the standard AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+// credentials, defaulting to commit statuses. Command-line flags override it.
+func StoreConfigFromEnv() StoreConfig {
+	cfg := StoreConfig{
+		Backend: os.Getenv("CI_CACHE_STORE"),
+		Path:    os.Getenv("CI_CACHE_PATH"),
+		S3: S3Config{
+			Endpoint:     os.Getenv("CI_CACHE_S3_ENDPOINT"),
+			Bucket:       os.Getenv("CI_CACHE_S3_BUCKET"),
+			Region:       os.Getenv("CI_CACHE_S3_REGION"),
+			Prefix:       os.Getenv("CI_CACHE_S3_PREFIX"),
+			AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+			SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+			SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+		},
+	}
+	if cfg.Backend == "" {
+		cfg.Backend = StoreStatus
+	}
+	return cfg
+}
+
+// NewStore creates the configured store.
+func NewStore(cfg StoreConfig) (Store, error) {
+	switch cfg.Backend {
+	case StoreStatus:
+		client, err := NewGitHubClient()
+		if err != nil {
+			return nil, err
+		}
+		return NewStatusStore(client), nil
+	case StoreFS:
+		if cfg.Path == "" {
+			return nil, fmt.Errorf("the fs store requires a path (--store-path or CI_CACHE_PATH)")
+		}
+		return NewObjectStore(&FSBlob{Root: cfg.Path}), nil
+	case StoreS3:
+		blob, err := NewS3Blob(cfg.S3)
+		if err != nil {
+			return nil, err
+		}
+		return NewObjectStore(blob), nil
+	default:
+		return nil, fmt.Errorf("unknown store %q: must be one of %s, %s, %s", cfg.Backend, StoreStatus, StoreFS, StoreS3)
+	}
+}
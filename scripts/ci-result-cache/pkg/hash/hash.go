@@ -10,6 +10,10 @@
 //     and the playwright-e2e-tests composite action
 //
 // If any of these files change, the hash changes, invalidating cached results.
+//
+// ComputeScenario narrows this to the inputs of a single matrix scenario, as
+// resolved from the chart version's CI registry (see scenario.go), so changes
+// that only affect other scenarios keep its cached result valid.
 package hash
 
 import (
@@ -35,6 +39,15 @@ var WorkflowFiles = []string{
 	".github/actions/playwright-e2e-tests/action.yaml",
 }
 
+// toolingPaths are the deployment tooling and e2e scripts shared by every
+// scenario of every chart version.
+var toolingPaths = []string{
+	filepath.Join("scripts", "deploy-camunda"),
+	filepath.Join("scripts", "camunda-core"),
+	filepath.Join("scripts", "run-e2e-tests.sh"),
+	filepath.Join("scripts", "render-e2e-env.sh"),
+}
+
 // Compute calculates a SHA-256 content hash for a given chart version.
 // It hashes all relevant files that could affect integration test outcomes.
 //
@@ -44,13 +57,9 @@ func Compute(repoRoot, version string) (string, error) {
 	h := sha256.New()
 
 	// Hash all paths to include — order matters for determinism.
-	paths := []string{
+	paths := append([]string{
 		filepath.Join("charts", fmt.Sprintf("camunda-platform-%s", version)),
-		filepath.Join("scripts", "deploy-camunda"),
-		filepath.Join("scripts", "camunda-core"),
-		filepath.Join("scripts", "run-e2e-tests.sh"),
-		filepath.Join("scripts", "render-e2e-env.sh"),
-	}
+	}, toolingPaths...)
 
 	for _, relPath := range paths {
 		absPath := filepath.Join(repoRoot, relPath)
@@ -0,0 +1,757 @@
+package hash
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"scripts/camunda-core/pkg/scenarios"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CompanionValuesDir holds the values files of companion charts (OpenSearch,
+// Keycloak, PostgreSQL, ...) that registry dependencies point at.
+const CompanionValuesDir = "test/integration/companion-values"
+
+// Scenario identifies one cell of the CI matrix, which is also the unit a
+// result is cached for.
+type Scenario struct {
+	Version   string
+	Shortname string
+	Flow      string
+}
+
+// Inputs is the set of files that can affect the result of one scenario:
+// every file below Roots that is not below one of the Skip paths.
+type Inputs struct {
+	// Roots are repo-relative files or directories.
+	Roots []string
+	// Skip holds repo-relative files or directories below Roots that cannot
+	// affect the scenario: other scenarios' registry entries, hooks and
+	// dependencies, values layers it does not select, and templates that
+	// render nothing with its values.
+	Skip map[string]bool
+	// Layers are the repo-relative values files the scenario deploys with,
+	// in merge order. With several platforms they are concatenated.
+	Layers []string
+	// Scoped is false when the scenario could not be resolved from the
+	// registry. The inputs then cover the whole chart version, like Compute.
+	Scoped bool
+	// Reason explains why the inputs are not scoped.
+	Reason string
+}
+
+// ComputeScenario calculates a SHA-256 content hash over the inputs of a
+// single scenario, so a change that only affects other scenarios of the same
+// chart version leaves its cached result valid.
+func ComputeScenario(repoRoot string, s Scenario) (string, error) {
+	inputs, err := ResolveInputs(repoRoot, s)
+	if err != nil {
+		return "", err
+	}
+	files, err := inputs.Files(repoRoot)
+	if err != nil {
+		return "", err
+	}
+
+	h := sha256.New()
+	for _, relPath := range files {
+		if err := hashFile(h, repoRoot, filepath.Join(repoRoot, relPath)); err != nil {
+			return "", fmt.Errorf("hashing file %s: %w", relPath, err)
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// Includes reports whether the repo-relative path rel is an input.
+func (in *Inputs) Includes(rel string) bool {
+	rel = filepath.Clean(rel)
+	for p := rel; p != "." && p != string(filepath.Separator); p = filepath.Dir(p) {
+		if in.Skip[p] {
+			return false
+		}
+	}
+	for _, root := range in.Roots {
+		if rel == root || strings.HasPrefix(rel, root+string(filepath.Separator)) {
+			return true
+		}
+	}
+	return false
+}
+
+// Files returns the repo-relative paths of all input files in sorted order.
+// Hidden directories are skipped, as in Compute.
+func (in *Inputs) Files(repoRoot string) ([]string, error) {
+	seen := make(map[string]bool)
+	for _, root := range in.Roots {
+		absRoot := filepath.Join(repoRoot, root)
+		if _, err := os.Stat(absRoot); err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("stat %s: %w", root, err)
+		}
+		err := filepath.WalkDir(absRoot, func(path string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(repoRoot, path)
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if (path != absRoot && strings.HasPrefix(d.Name(), ".")) || in.Skip[rel] {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if in.Includes(rel) {
+				seen[rel] = true
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("walking %s: %w", root, err)
+		}
+	}
+
+	files := make([]string, 0, len(seen))
+	for f := range seen {
+		files = append(files, f)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// registryManifest mirrors the parts of test/ci/registry/manifest.yaml that
+// decide which files a scenario reads. See deploy-camunda/matrix/registry.go.
+type registryManifest struct {
+	Integration struct {
+		Flows     map[string]map[string]*registryHook `yaml:"flows"`
+		Scenarios []struct {
+			ID        string `yaml:"id"`
+			Shortname string `yaml:"shortname"`
+		} `yaml:"scenarios"`
+	} `yaml:"integration"`
+}
+
+type registryScenario struct {
+	Name          string            `yaml:"name"`
+	Platforms     []string          `yaml:"platforms"`
+	InfraType     map[string]string `yaml:"infra-type"`
+	Identity      string            `yaml:"identity"`
+	Persistence   string            `yaml:"persistence"`
+	Features      []string          `yaml:"features"`
+	ExtraValues   []string          `yaml:"extra-values"`
+	QA            bool              `yaml:"qa"`
+	ImageTags     bool              `yaml:"image-tags"`
+	Upgrade       bool              `yaml:"upgrade"`
+	PreInstallID  string            `yaml:"pre-install"`
+	PostInfraID   string            `yaml:"post-infra"`
+	PostDeployID  string            `yaml:"post-deploy"`
+	DependencyIDs []string          `yaml:"dependencies"`
+}
+
+type registryHook struct {
+	Fixtures []string `yaml:"fixtures"`
+	Script   string   `yaml:"script"`
+}
+
+type registryDependency struct {
+	ValuesFile string `yaml:"values-file"`
+}
+
+// ResolveInputs determines the inputs of a scenario from the chart version's
+// CI registry. When the chart has no registry, the shortname is unknown or the
+// scenario's deployment config does not resolve, the returned inputs are not
+// scoped and cover the whole chart version.
+func ResolveInputs(repoRoot string, s Scenario) (*Inputs, error) {
+	chartRel := filepath.Join("charts", fmt.Sprintf("camunda-platform-%s", s.Version))
+	in := &Inputs{
+		Roots: append(append([]string{chartRel, CompanionValuesDir}, toolingPaths...), WorkflowFiles...),
+		Skip:  make(map[string]bool),
+	}
+
+	r := &resolver{
+		repoRoot:     repoRoot,
+		chartRel:     chartRel,
+		registryRel:  filepath.Join(chartRel, "test", "ci", "registry"),
+		scenariosRel: filepath.Join(chartRel, "test", "integration", "scenarios"),
+		in:           in,
+	}
+	reason, err := r.resolve(s)
+	if err != nil {
+		return nil, err
+	}
+	if reason != "" {
+		return &Inputs{Roots: in.Roots, Skip: map[string]bool{}, Reason: reason}, nil
+	}
+	in.Scoped = true
+	return in, nil
+}
+
+type resolver struct {
+	repoRoot     string
+	chartRel     string
+	registryRel  string
+	scenariosRel string
+	in           *Inputs
+	// layerSets holds the resolved values layers per platform.
+	layerSets [][]string
+}
+
+// resolve fills r.in.Skip and r.in.Layers. A non-empty reason means the
+// scenario cannot be scoped; an error means a registry file is unreadable.
+func (r *resolver) resolve(s Scenario) (string, error) {
+	var manifest registryManifest
+	found, err := r.readYAML(filepath.Join(r.registryRel, "manifest.yaml"), &manifest)
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "chart version has no CI registry", nil
+	}
+
+	id := ""
+	for _, entry := range manifest.Integration.Scenarios {
+		if entry.Shortname == s.Shortname {
+			id = entry.ID
+			break
+		}
+	}
+	if id == "" {
+		return fmt.Sprintf("shortname %q is not in the registry", s.Shortname), nil
+	}
+
+	var scenario registryScenario
+	if found, err := r.readYAML(filepath.Join(r.registryRel, "scenarios", id+".yaml"), &scenario); err != nil {
+		return "", err
+	} else if !found {
+		return fmt.Sprintf("registry scenario %q has no definition", id), nil
+	}
+	if scenario.Name == "" {
+		scenario.Name = id
+	}
+
+	if err := r.skipOtherEntries("scenarios", []string{id}); err != nil {
+		return "", err
+	}
+	if err := r.skipUnusedHooks(manifest, scenario, s.Flow); err != nil {
+		return "", err
+	}
+	if err := r.skipUnusedDependencies(scenario); err != nil {
+		return "", err
+	}
+	if reason, err := r.skipUnselectedLayers(scenario, s.Flow); reason != "" || err != nil {
+		return reason, err
+	}
+	if err := r.skipDisabledTemplates(); err != nil {
+		return "", err
+	}
+
+	// Unit tests of the chart never run in the integration path.
+	r.in.Skip[filepath.Join(r.chartRel, "test", "unit")] = true
+	return "", nil
+}
+
+// skipOtherEntries skips the files of a registry subdirectory except those
+// of the given ids.
+func (r *resolver) skipOtherEntries(dir string, ids []string) error {
+	keep := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		keep[id+".yaml"] = true
+	}
+	entries, err := os.ReadDir(filepath.Join(r.repoRoot, r.registryRel, dir))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("reading registry %s: %w", dir, err)
+	}
+	for _, entry := range entries {
+		if !entry.IsDir() && !keep[entry.Name()] {
+			r.in.Skip[filepath.Join(r.registryRel, dir, entry.Name())] = true
+		}
+	}
+	return nil
+}
+
+// skipUnusedHooks skips the hooks the scenario does not run, together with
+// the pre-setup scripts and fixtures only those hooks reference. Scripts no
+// hook references stay inputs since other scripts may source them.
+func (r *resolver) skipUnusedHooks(manifest registryManifest, scenario registryScenario, flow string) error {
+	var usedIDs []string
+	for _, id := range []string{scenario.PreInstallID, scenario.PostInfraID, scenario.PostDeployID} {
+		if id != "" {
+			usedIDs = append(usedIDs, id)
+		}
+	}
+	if err := r.skipOtherEntries("hooks", usedIDs); err != nil {
+		return err
+	}
+
+	used := make(map[string]bool)
+	referenced := make(map[string]bool)
+	collect := func(hook *registryHook, isUsed bool) {
+		if hook == nil {
+			return
+		}
+		var files []string
+		if hook.Script != "" {
+			files = append(files, filepath.Join(r.scenariosRel, "pre-setup-scripts", hook.Script))
+		}
+		for _, fixture := range hook.Fixtures {
+			files = append(files, filepath.Join(r.scenariosRel, "common", "resources", fixture))
+		}
+		for _, f := range files {
+			referenced[f] = true
+			used[f] = used[f] || isUsed
+		}
+	}
+
+	for name, hooks := range manifest.Integration.Flows {
+		for _, hook := range hooks {
+			collect(hook, name == flow)
+		}
+	}
+	entries, err := os.ReadDir(filepath.Join(r.repoRoot, r.registryRel, "hooks"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading registry hooks: %w", err)
+	}
+	for _, entry := range entries {
+		id := strings.TrimSuffix(entry.Name(), ".yaml")
+		var hook registryHook
+		if _, err := r.readYAML(filepath.Join(r.registryRel, "hooks", entry.Name()), &hook); err != nil {
+			return err
+		}
+		collect(&hook, contains(usedIDs, id))
+	}
+
+	for f := range referenced {
+		if !used[f] {
+			r.in.Skip[f] = true
+		}
+	}
+	return nil
+}
+
+// skipUnusedDependencies skips the companion charts the scenario does not
+// install and the companion values files only they reference.
+func (r *resolver) skipUnusedDependencies(scenario registryScenario) error {
+	if err := r.skipOtherEntries("dependencies", scenario.DependencyIDs); err != nil {
+		return err
+	}
+
+	entries, err := os.ReadDir(filepath.Join(r.repoRoot, r.registryRel, "dependencies"))
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("reading registry dependencies: %w", err)
+	}
+	used := make(map[string]bool)
+	unused := make(map[string]bool)
+	for _, entry := range entries {
+		var dep registryDependency
+		if _, err := r.readYAML(filepath.Join(r.registryRel, "dependencies", entry.Name()), &dep); err != nil {
+			return err
+		}
+		if dep.ValuesFile == "" {
+			continue
+		}
+		valuesFile := filepath.Clean(dep.ValuesFile)
+		if contains(scenario.DependencyIDs, strings.TrimSuffix(entry.Name(), ".yaml")) {
+			used[valuesFile] = true
+		} else {
+			unused[valuesFile] = true
+		}
+	}
+	for f := range unused {
+		if !used[f] {
+			r.in.Skip[f] = true
+		}
+	}
+	return nil
+}
+
+// skipUnselectedLayers resolves the values layers of every platform the
+// scenario runs on with the same builder deploy-camunda uses, and skips the
+// selectable layers none of them picks. Base layers are always inputs since
+// CI may enable QA or image tags regardless of the registry.
+func (r *resolver) skipUnselectedLayers(scenario registryScenario, flow string) (string, error) {
+	scenariosDir := filepath.Join(r.repoRoot, r.scenariosRel, "chart-full-setup")
+	platforms := scenario.Platforms
+	if len(platforms) == 0 {
+		platforms = []string{"gke"}
+	}
+
+	selected := make(map[string]bool)
+	var layerSets [][]string
+	for _, platform := range platforms {
+		infraType := scenario.InfraType[platform]
+		if infraType == "" {
+			infraType = "preemptible"
+		}
+		cfg, err := scenarios.BuildDeploymentConfig(scenariosDir, scenario.Name, scenarios.BuilderOverrides{
+			Identity:    scenario.Identity,
+			Persistence: scenario.Persistence,
+			Platform:    platform,
+			Features:    scenario.Features,
+			InfraType:   infraType,
+			Flow:        flow,
+			QA:          scenario.QA,
+			ImageTags:   scenario.ImageTags,
+			Upgrade:     scenario.Upgrade || strings.HasPrefix(flow, "upgrade"),
+		})
+		if err != nil {
+			return err.Error(), nil
+		}
+		paths, err := cfg.ResolvePaths(scenariosDir)
+		if err != nil {
+			return err.Error(), nil
+		}
+		for _, extra := range scenario.ExtraValues {
+			paths = append(paths, filepath.Join(scenariosDir, extra))
+		}
+
+		var layers []string
+		for _, p := range paths {
+			rel, err := filepath.Rel(r.repoRoot, p)
+			if err != nil {
+				return "", fmt.Errorf("computing relative path: %w", err)
+			}
+			rel = filepath.Clean(rel)
+			selected[rel] = true
+			layers = append(layers, rel)
+		}
+		r.in.Layers = append(r.in.Layers, layers...)
+		layerSets = append(layerSets, layers)
+	}
+
+	var candidates []string
+	valuesRel := filepath.Join(r.scenariosRel, "chart-full-setup", scenarios.ValuesDir)
+	for _, dir := range []string{scenarios.IdentityDir, scenarios.PersistenceDir, scenarios.PlatformDir, scenarios.FeaturesDir} {
+		files, err := r.walkFiles(filepath.Join(valuesRel, dir))
+		if err != nil {
+			return "", err
+		}
+		candidates = append(candidates, files...)
+	}
+	infra, err := filepath.Glob(filepath.Join(r.repoRoot, r.scenariosRel, scenarios.InfraDir, "values-infra-*.yaml"))
+	if err != nil {
+		return "", err
+	}
+	for _, p := range infra {
+		rel, _ := filepath.Rel(r.repoRoot, p)
+		candidates = append(candidates, rel)
+	}
+	for _, f := range candidates {
+		if !selected[f] {
+			r.in.Skip[f] = true
+		}
+	}
+
+	r.layerSets = layerSets
+	return "", nil
+}
+
+// templateGate matches a template action that is a condition on a single
+// values key, e.g. "if .Values.webModeler.enabled" or
+// "if and .Values.connectors.enabled .Values.connectors.serviceAccount.enabled".
+var templateGate = regexp.MustCompile(`^if\s+(?:and\s+)?\.Values\.([A-Za-z0-9_.]+)`)
+
+// templateAction matches a template action and captures its body.
+var templateAction = regexp.MustCompile(`(?s)\{\{-?(.*?)-?\}\}`)
+
+// helmSetFlag matches a values key set on the Helm command line in CI, e.g.
+// "--set global.host=..." or "--extra-helm-arg=--set-file=global.license.key=...".
+var helmSetFlag = regexp.MustCompile(`--(?:extra-helm-)?set(?:-file|-string|-json)?[= ]+["']?([A-Za-z0-9_.]+)=`)
+
+// skipDisabledTemplates skips the templates that render nothing for the
+// scenario: those wrapped in a condition on a values key that is false for
+// every platform. A template stays an input whenever the gating is uncertain:
+// the condition does not wrap the whole file, CI sets the key with --set, or
+// a chart-level values file CI may add (values-enterprise.yaml,
+// values-digest.yaml, ...) sets it. Named-template files (prefixed with "_")
+// stay inputs since enabled components may include their helpers.
+func (r *resolver) skipDisabledTemplates() error {
+	defaults := make(map[string]any)
+	if _, err := r.readYAML(filepath.Join(r.chartRel, "values.yaml"), &defaults); err != nil {
+		return err
+	}
+	var merged []map[string]any
+	for _, layers := range r.layerSets {
+		values := make(map[string]any)
+		mergeValues(values, defaults)
+		for _, layer := range layers {
+			doc := make(map[string]any)
+			if _, err := r.readYAML(layer, &doc); err != nil {
+				return err
+			}
+			mergeValues(values, doc)
+		}
+		merged = append(merged, values)
+	}
+
+	overlays, err := r.chartOverlays()
+	if err != nil {
+		return err
+	}
+	setKeys, err := r.ciSetKeys()
+	if err != nil {
+		return err
+	}
+
+	templates, err := r.walkFiles(filepath.Join(r.chartRel, "templates"))
+	if err != nil {
+		return err
+	}
+	for _, rel := range templates {
+		if strings.HasPrefix(filepath.Base(rel), "_") {
+			continue
+		}
+		key, err := r.gateKey(rel)
+		if err != nil {
+			return err
+		}
+		if key == "" || overlapsAny(key, setKeys) {
+			continue
+		}
+		disabled := len(merged) > 0
+		for _, values := range merged {
+			if v, ok := lookup(values, key).(bool); !ok || v {
+				disabled = false
+				break
+			}
+		}
+		for _, overlay := range overlays {
+			if lookup(overlay, key) != nil {
+				disabled = false
+				break
+			}
+		}
+		if disabled {
+			r.in.Skip[rel] = true
+		}
+	}
+	return nil
+}
+
+// chartOverlays reads the values-*.yaml files at the chart root, which CI
+// may pass with --values on top of the scenario layers.
+func (r *resolver) chartOverlays() ([]map[string]any, error) {
+	paths, err := filepath.Glob(filepath.Join(r.repoRoot, r.chartRel, "values-*.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	var overlays []map[string]any
+	for _, p := range paths {
+		rel, err := filepath.Rel(r.repoRoot, p)
+		if err != nil {
+			return nil, fmt.Errorf("computing relative path: %w", err)
+		}
+		doc := make(map[string]any)
+		if _, err := r.readYAML(rel, &doc); err != nil {
+			return nil, err
+		}
+		overlays = append(overlays, doc)
+	}
+	return overlays, nil
+}
+
+// ciSetKeys returns the values keys the CI workflows and actions set on the
+// Helm command line.
+func (r *resolver) ciSetKeys() ([]string, error) {
+	sources := append([]string(nil), WorkflowFiles...)
+	actions, err := filepath.Glob(filepath.Join(r.repoRoot, ".github", "actions", "*", "action.y*ml"))
+	if err != nil {
+		return nil, err
+	}
+	for _, p := range actions {
+		rel, err := filepath.Rel(r.repoRoot, p)
+		if err != nil {
+			return nil, fmt.Errorf("computing relative path: %w", err)
+		}
+		sources = append(sources, rel)
+	}
+
+	seen := make(map[string]bool)
+	var keys []string
+	for _, rel := range sources {
+		data, err := os.ReadFile(filepath.Join(r.repoRoot, rel))
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return nil, fmt.Errorf("reading %s: %w", rel, err)
+		}
+		for _, m := range helmSetFlag.FindAllStringSubmatch(string(data), -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				keys = append(keys, m[1])
+			}
+		}
+	}
+	return keys, nil
+}
+
+// overlapsAny reports whether key equals, contains or is contained in one of
+// the keys.
+func overlapsAny(key string, keys []string) bool {
+	for _, k := range keys {
+		if key == k || strings.HasPrefix(key, k+".") || strings.HasPrefix(k, key+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// gateKey returns the values key the template is gated on, or "" when the
+// gating is uncertain: the file renders content or actions outside a single
+// leading "if", or the condition is piped or has an else branch.
+func (r *resolver) gateKey(rel string) (string, error) {
+	data, err := os.ReadFile(filepath.Join(r.repoRoot, rel))
+	if err != nil {
+		return "", fmt.Errorf("reading %s: %w", rel, err)
+	}
+	body := string(data)
+
+	key := ""
+	depth := 0
+	closed := false
+	prev := 0
+	for _, loc := range templateAction.FindAllStringSubmatchIndex(body, -1) {
+		text := body[prev:loc[0]]
+		prev = loc[1]
+		action := strings.TrimSpace(body[loc[2]:loc[3]])
+		if depth == 0 && !rendersNothing(text) {
+			return "", nil
+		}
+		if strings.HasPrefix(action, "/*") {
+			continue
+		}
+		if closed {
+			return "", nil
+		}
+		if depth == 0 {
+			m := templateGate.FindStringSubmatch(action)
+			if m == nil || strings.Contains(action, "|") {
+				return "", nil
+			}
+			key = m[1]
+			depth = 1
+			continue
+		}
+		switch strings.Fields(action + " ")[0] {
+		case "if", "range", "with", "define", "block":
+			depth++
+		case "else":
+			if depth == 1 {
+				return "", nil
+			}
+		case "end":
+			depth--
+			closed = depth == 0
+		}
+	}
+	if !closed || !rendersNothing(body[prev:]) {
+		return "", nil
+	}
+	return key, nil
+}
+
+// rendersNothing reports whether template text outside any action holds only
+// blank lines and YAML comments.
+func rendersNothing(text string) bool {
+	for _, line := range strings.Split(text, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			return false
+		}
+	}
+	return true
+}
+
+// lookup returns the value at the dotted key path, or nil.
+func lookup(values map[string]any, key string) any {
+	var current any = values
+	for _, part := range strings.Split(key, ".") {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+	return current
+}
+
+// mergeValues deep-merges src into dst the way Helm merges values files.
+func mergeValues(dst, src map[string]any) {
+	for k, v := range src {
+		if srcMap, ok := v.(map[string]any); ok {
+			if dstMap, ok := dst[k].(map[string]any); ok {
+				mergeValues(dstMap, srcMap)
+				continue
+			}
+			copied := make(map[string]any)
+			mergeValues(copied, srcMap)
+			dst[k] = copied
+			continue
+		}
+		dst[k] = v
+	}
+}
+
+// readYAML decodes a repo-relative YAML file into out. A missing file is
+// reported as found=false rather than an error.
+func (r *resolver) readYAML(rel string, out any) (bool, error) {
+	data, err := os.ReadFile(filepath.Join(r.repoRoot, rel))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("reading %s: %w", rel, err)
+	}
+	if err := yaml.Unmarshal(data, out); err != nil {
+		return false, fmt.Errorf("parsing %s: %w", rel, err)
+	}
+	return true, nil
+}
+
+// walkFiles returns the repo-relative paths of all files below dir.
+func (r *resolver) walkFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(filepath.Join(r.repoRoot, dir), func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if os.IsNotExist(err) {
+				return nil
+			}
+			return err
+		}
+		if !d.IsDir() {
+			rel, err := filepath.Rel(r.repoRoot, path)
+			if err != nil {
+				return err
+			}
+			files = append(files, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walking %s: %w", dir, err)
+	}
+	return files, nil
+}
+
+func contains(slice []string, item string) bool {
+	for _, s := range slice {
+		if s == item {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,290 @@
+package hash
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setupRegistryRepo creates a repo with a 8.9 chart whose CI registry holds
+// two scenarios: "eske" (keycloak + elasticsearch, pre-install hook) and
+// "esoi" (oidc + elasticsearch, opensearch dependency).
+func setupRegistryRepo(t *testing.T) string {
+	t.Helper()
+	tmpDir := t.TempDir()
+	chartDir := filepath.Join(tmpDir, "charts", "camunda-platform-8.9")
+	registryDir := filepath.Join(chartDir, "test", "ci", "registry")
+	scenariosDir := filepath.Join(chartDir, "test", "integration", "scenarios")
+	valuesDir := filepath.Join(scenariosDir, "chart-full-setup", "values")
+
+	files := map[string]string{
+		filepath.Join(chartDir, "Chart.yaml"):                                  "name: test\n",
+		filepath.Join(chartDir, "values.yaml"):                                 "webModeler:\n  enabled: false\nconsole:\n  enabled: true\n",
+		filepath.Join(chartDir, "templates", "web-modeler", "_helpers.tpl"):    "{{- define \"webModeler.name\" -}}wm{{- end -}}\n",
+		filepath.Join(chartDir, "templates", "web-modeler", "deployment.yaml"): "{{- if .Values.webModeler.enabled -}}\nkind: Deployment\n{{- end }}\n",
+		filepath.Join(chartDir, "templates", "console", "deployment.yaml"):     "{{- if .Values.console.enabled -}}\nkind: Deployment\n{{- end }}\n",
+		filepath.Join(chartDir, "test", "unit", "console_test.go"):             "package unit\n",
+
+		filepath.Join(registryDir, "manifest.yaml"): `integration:
+  flows:
+    upgrade-patch:
+      pre-upgrade:
+        script: pre-upgrade-patch.sh
+  scenarios:
+    - id: elasticsearch
+      shortname: eske
+    - id: oidc
+      shortname: esoi
+`,
+		filepath.Join(registryDir, "scenarios", "elasticsearch.yaml"): `name: elasticsearch
+identity: keycloak
+persistence: elasticsearch
+platforms: [gke]
+pre-install: cnpg
+`,
+		filepath.Join(registryDir, "scenarios", "oidc.yaml"): `name: oidc
+identity: oidc
+persistence: elasticsearch
+platforms: [gke]
+dependencies: [opensearch]
+`,
+		filepath.Join(registryDir, "hooks", "cnpg.yaml"):                         "script: pre-install-cnpg.sh\ndescription: cnpg\n",
+		filepath.Join(registryDir, "dependencies", "opensearch.yaml"):            "values-file: test/integration/companion-values/opensearch.yaml\n",
+		filepath.Join(scenariosDir, "pre-setup-scripts", "pre-install-cnpg.sh"):  "#!/bin/bash\n",
+		filepath.Join(scenariosDir, "pre-setup-scripts", "pre-upgrade-patch.sh"): "#!/bin/bash\n",
+		filepath.Join(scenariosDir, "infra", "values-infra-preemptible.yaml"):    "nodeSelector: {}\n",
+		filepath.Join(scenariosDir, "infra", "values-infra-arm.yaml"):            "nodeSelector: {}\n",
+		filepath.Join(valuesDir, "base.yaml"):                                    "global: {}\n",
+		filepath.Join(valuesDir, "identity", "keycloak.yaml"):                    "identity:\n  enabled: true\n",
+		filepath.Join(valuesDir, "identity", "oidc.yaml"):                        "identity:\n  enabled: false\n",
+		filepath.Join(valuesDir, "persistence", "elasticsearch.yaml"):            "elasticsearch:\n  enabled: true\n",
+		filepath.Join(valuesDir, "platform", "gke.yaml"):                         "global: {}\n",
+		filepath.Join(valuesDir, "features", "multitenancy.yaml"):                "global: {}\n",
+
+		filepath.Join(tmpDir, CompanionValuesDir, "opensearch.yaml"): "replicas: 1\n",
+	}
+	for path, content := range files {
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		writeFile(t, path, content)
+	}
+	return tmpDir
+}
+
+func TestComputeScenario_ScopedChanges(t *testing.T) {
+	chartDir := filepath.Join("charts", "camunda-platform-8.9")
+	scenariosDir := filepath.Join(chartDir, "test", "integration", "scenarios")
+	valuesDir := filepath.Join(scenariosDir, "chart-full-setup", "values")
+
+	tests := []struct {
+		name       string
+		file       string
+		eskeChange bool
+		esoiChange bool
+	}{
+		{"shared chart values", filepath.Join(chartDir, "values.yaml"), true, true},
+		{"shared base layer", filepath.Join(valuesDir, "base.yaml"), true, true},
+		{"eske identity layer", filepath.Join(valuesDir, "identity", "keycloak.yaml"), true, false},
+		{"esoi identity layer", filepath.Join(valuesDir, "identity", "oidc.yaml"), false, true},
+		{"unselected feature layer", filepath.Join(valuesDir, "features", "multitenancy.yaml"), false, false},
+		{"unselected infra pool", filepath.Join(scenariosDir, "infra", "values-infra-arm.yaml"), false, false},
+		{"eske registry entry", filepath.Join(chartDir, "test", "ci", "registry", "scenarios", "elasticsearch.yaml"), true, false},
+		{"eske hook script", filepath.Join(scenariosDir, "pre-setup-scripts", "pre-install-cnpg.sh"), true, false},
+		{"other flow hook script", filepath.Join(scenariosDir, "pre-setup-scripts", "pre-upgrade-patch.sh"), false, false},
+		{"esoi companion values", filepath.Join(CompanionValuesDir, "opensearch.yaml"), false, true},
+		{"disabled component template", filepath.Join(chartDir, "templates", "web-modeler", "deployment.yaml"), false, false},
+		{"disabled component helpers", filepath.Join(chartDir, "templates", "web-modeler", "_helpers.tpl"), true, true},
+		{"enabled component template", filepath.Join(chartDir, "templates", "console", "deployment.yaml"), true, true},
+		{"chart unit tests", filepath.Join(chartDir, "test", "unit", "console_test.go"), false, false},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := setupRegistryRepo(t)
+			eske := Scenario{Version: "8.9", Shortname: "eske", Flow: "install"}
+			esoi := Scenario{Version: "8.9", Shortname: "esoi", Flow: "install"}
+
+			eske1, err := ComputeScenario(tmpDir, eske)
+			if err != nil {
+				t.Fatalf("ComputeScenario eske: %v", err)
+			}
+			esoi1, err := ComputeScenario(tmpDir, esoi)
+			if err != nil {
+				t.Fatalf("ComputeScenario esoi: %v", err)
+			}
+
+			path := filepath.Join(tmpDir, tc.file)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			writeFile(t, path, string(content)+"# changed\n")
+
+			eske2, err := ComputeScenario(tmpDir, eske)
+			if err != nil {
+				t.Fatalf("ComputeScenario eske: %v", err)
+			}
+			esoi2, err := ComputeScenario(tmpDir, esoi)
+			if err != nil {
+				t.Fatalf("ComputeScenario esoi: %v", err)
+			}
+
+			if got := eske1 != eske2; got != tc.eskeChange {
+				t.Errorf("eske hash changed = %v, want %v", got, tc.eskeChange)
+			}
+			if got := esoi1 != esoi2; got != tc.esoiChange {
+				t.Errorf("esoi hash changed = %v, want %v", got, tc.esoiChange)
+			}
+		})
+	}
+}
+
+func TestResolveInputs_Layers(t *testing.T) {
+	tmpDir := setupRegistryRepo(t)
+
+	inputs, err := ResolveInputs(tmpDir, Scenario{Version: "8.9", Shortname: "eske", Flow: "install"})
+	if err != nil {
+		t.Fatalf("ResolveInputs: %v", err)
+	}
+	if !inputs.Scoped {
+		t.Fatalf("expected scoped inputs, got fallback: %s", inputs.Reason)
+	}
+
+	valuesDir := filepath.Join("charts", "camunda-platform-8.9", "test", "integration", "scenarios", "chart-full-setup", "values")
+	expected := []string{
+		filepath.Join(valuesDir, "base.yaml"),
+		filepath.Join(valuesDir, "identity", "keycloak.yaml"),
+		filepath.Join(valuesDir, "persistence", "elasticsearch.yaml"),
+		filepath.Join(valuesDir, "platform", "gke.yaml"),
+		filepath.Join("charts", "camunda-platform-8.9", "test", "integration", "scenarios", "infra", "values-infra-preemptible.yaml"),
+	}
+	if len(inputs.Layers) != len(expected) {
+		t.Fatalf("expected layers %v, got %v", expected, inputs.Layers)
+	}
+	for i := range expected {
+		if inputs.Layers[i] != expected[i] {
+			t.Errorf("layer %d: expected %s, got %s", i, expected[i], inputs.Layers[i])
+		}
+	}
+}
+
+func TestResolveInputs_UncertainGatesKeepTemplates(t *testing.T) {
+	chartDir := filepath.Join("charts", "camunda-platform-8.9")
+	template := filepath.Join(chartDir, "templates", "web-modeler", "deployment.yaml")
+
+	tests := []struct {
+		name  string
+		files map[string]string
+		skip  bool
+	}{
+		{
+			name: "gate wraps the template",
+			files: map[string]string{
+				template: "# header\n{{- if .Values.webModeler.enabled -}}\nkind: Deployment\n{{- if .Values.webModeler.x }}x: 1{{- end }}\n{{- end }}\n",
+			},
+			skip: true,
+		},
+		{
+			name: "content after the gate",
+			files: map[string]string{
+				template: "{{- if .Values.webModeler.enabled -}}\nkind: Deployment\n{{- end }}\n---\nkind: Service\n",
+			},
+		},
+		{
+			name: "else branch",
+			files: map[string]string{
+				template: "{{- if .Values.webModeler.enabled -}}\nkind: Deployment\n{{- else }}\nkind: ConfigMap\n{{- end }}\n",
+			},
+		},
+		{
+			name: "piped condition",
+			files: map[string]string{
+				template: "{{- if .Values.webModeler.enabled | not -}}\nkind: Deployment\n{{- end }}\n",
+			},
+		},
+		{
+			name: "CI sets the key",
+			files: map[string]string{
+				WorkflowFiles[0]: "run: deploy-camunda --extra-helm-arg=--set=webModeler.enabled=true\n",
+			},
+		},
+		{
+			name: "CI sets a parent key",
+			files: map[string]string{
+				filepath.Join(".github", "actions", "setup", "action.yaml"): "run: echo \"arg=--set-json webModeler={}\"\n",
+			},
+		},
+		{
+			name: "chart overlay sets the key",
+			files: map[string]string{
+				filepath.Join(chartDir, "values-enterprise.yaml"): "webModeler:\n  enabled: true\n",
+			},
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpDir := setupRegistryRepo(t)
+			for rel, content := range tc.files {
+				path := filepath.Join(tmpDir, rel)
+				if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				writeFile(t, path, content)
+			}
+
+			inputs, err := ResolveInputs(tmpDir, Scenario{Version: "8.9", Shortname: "eske", Flow: "install"})
+			if err != nil {
+				t.Fatalf("ResolveInputs: %v", err)
+			}
+			if got := !inputs.Includes(template); got != tc.skip {
+				t.Errorf("template skipped = %v, want %v", got, tc.skip)
+			}
+		})
+	}
+}
+
+func TestResolveInputs_FallsBackToWholeChart(t *testing.T) {
+	tmpDir := setupRegistryRepo(t)
+
+	inputs, err := ResolveInputs(tmpDir, Scenario{Version: "8.9", Shortname: "unknown", Flow: "install"})
+	if err != nil {
+		t.Fatalf("ResolveInputs: %v", err)
+	}
+	if inputs.Scoped {
+		t.Fatal("expected unscoped inputs for an unknown shortname")
+	}
+	if inputs.Reason == "" {
+		t.Error("expected a reason for the fallback")
+	}
+
+	unitTest := filepath.Join("charts", "camunda-platform-8.9", "test", "unit", "console_test.go")
+	if !inputs.Includes(unitTest) {
+		t.Errorf("expected %s to be an input of the whole chart version", unitTest)
+	}
+}
+
+func TestResolveInputs_NoRegistry(t *testing.T) {
+	tmpDir := t.TempDir()
+	chartDir := filepath.Join(tmpDir, "charts", "camunda-platform-8.6")
+	if err := os.MkdirAll(chartDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	writeFile(t, filepath.Join(chartDir, "Chart.yaml"), "name: test\n")
+
+	inputs, err := ResolveInputs(tmpDir, Scenario{Version: "8.6", Shortname: "eske", Flow: "install"})
+	if err != nil {
+		t.Fatalf("ResolveInputs: %v", err)
+	}
+	if inputs.Scoped {
+		t.Error("expected unscoped inputs for a chart without registry")
+	}
+	files, err := inputs.Files(tmpDir)
+	if err != nil {
+		t.Fatalf("Files: %v", err)
+	}
+	if len(files) != 1 || files[0] != filepath.Join("charts", "camunda-platform-8.6", "Chart.yaml") {
+		t.Errorf("expected only Chart.yaml, got %v", files)
+	}
+}
@@ -17,8 +17,8 @@ import (
 // inline summary so the watcher works even without the skill installed.
 const DefaultPrompt = `You are watching a Helm install of the Camunda 8 Self-Managed platform.
 Read the JSON snapshot piped in via stdin. It contains the namespace, release
-name, and the raw output of: kubectl get pods, kubectl get events,
-kubectl get pvc, and helm status.
+name, the pod, event and PVC lists (in kubectl get -o json shape), and the
+helm status of the release.
 
 Use the debug-failing-pods skill if it is available. Whether or not it is,
 respond with a single JSON object matching this schema and nothing else:
@@ -97,8 +97,13 @@ const DefaultMaxErrors = 5
 
 // Options configures a Watch run.
 type Options struct {
-	// CLI is the agent CLI to invoke. Use DetectCLI to populate.
+	// CLI is the agent CLI to invoke. Use DetectCLI to populate. Required
+	// unless Source is set.
 	CLI AgentCLI
+	// Source produces the per-tick verdicts (see NewVerdictSource). When
+	// nil, Watch asks the agent CLI on every tick, i.e. AgentSource{CLI,
+	// Prompt}.
+	Source VerdictSource
 	// Snapshot configures the per-tick cluster gather.
 	Snapshot SnapshotOptions
 	// Prompt is the system-style instruction passed on every tick. If empty,
@@ -131,7 +136,8 @@ type Options struct {
 }
 
 // Watch polls the cluster on a fixed interval, hands each snapshot to the
-// agent CLI, and returns the final decision and the last verdict it acted
+// verdict source (the agent CLI unless Options.Source says otherwise), and
+// returns the final decision and the last verdict it acted
 // on. The function returns when:
 //   - The snapshot shows the Helm release deployed and every pod Ready
 //     (DecisionContinue, last verdict).
@@ -144,8 +150,11 @@ type Options struct {
 // Each tick is a paid LLM call; the bounds above are independent kill
 // switches so no single misconfiguration can produce an unbounded run.
 func Watch(ctx context.Context, opts Options) (Decision, *Verdict, error) {
-	if opts.CLI.Name == "" {
-		return DecisionContinue, nil, errors.New("agentwatch: Options.CLI is empty (call DetectCLI first)")
+	if opts.Source == nil {
+		if opts.CLI.Name == "" {
+			return DecisionContinue, nil, errors.New("agentwatch: Options.CLI is empty (call DetectCLI first)")
+		}
+		opts.Source = AgentSource{CLI: opts.CLI, Prompt: opts.Prompt}
 	}
 	if opts.Interval <= 0 {
 		opts.Interval = DefaultInterval
@@ -159,9 +168,15 @@ func Watch(ctx context.Context, opts Options) (Decision, *Verdict, error) {
 	if opts.MaxErrors == 0 {
 		opts.MaxErrors = DefaultMaxErrors
 	}
-	prompt := opts.Prompt
-	if prompt == "" {
-		prompt = DefaultPrompt
+	// Build the cluster client once instead of per tick. A failure here is
+	// not fatal: GatherSnapshot retries the construction on every tick, so
+	// a kubeconfig that shows up mid-watch is still picked up and a
+	// permanently broken one is bounded by MaxErrors like any other
+	// snapshot failure.
+	if opts.Snapshot.Reader == nil {
+		if reader, err := NewClusterReader(opts.Snapshot.KubeContext); err == nil {
+			opts.Snapshot.Reader = reader
+		}
 	}
 
 	if opts.MaxDuration > 0 {
@@ -225,9 +240,9 @@ func Watch(ctx context.Context, opts Options) (Decision, *Verdict, error) {
 			return DecisionContinue, lastVerdict, fmt.Errorf("marshal snapshot: %w", err)
 		}
 
-		verdictBytes, err := Invoke(ctx, opts.CLI, prompt, snapshotBytes)
+		verdictBytes, err := opts.Source.Decide(ctx, snapshot, snapshotBytes)
 		if err != nil {
-			logging.Logger.Warn().Err(err).Msg("agentwatch: agent invocation failed; will retry")
+			logging.Logger.Warn().Err(err).Msg("agentwatch: verdict source failed; will retry")
 			persistTick(opts.CorpusDir, snapshotBytes, verdictBytes, nil, err)
 			if capErr := recordFailure(); capErr != nil {
 				return DecisionContinue, lastVerdict, capErr
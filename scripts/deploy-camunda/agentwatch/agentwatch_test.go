@@ -20,21 +20,34 @@ import (
 	"errors"
 	"os"
 	"path/filepath"
+	"scripts/camunda-core/pkg/kube"
 	"strings"
 	"testing"
 	"time"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 func nowSuffix() string { return time.Now().UTC().Format("20060102T150405") }
 
-func useFailingKubectl(t *testing.T) {
-	t.Helper()
-	dir := t.TempDir()
-	path := filepath.Join(dir, "kubectl")
-	if err := os.WriteFile(path, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
-		t.Fatalf("write failing kubectl: %v", err)
-	}
-	t.Setenv("PATH", dir+string(os.PathListSeparator)+os.Getenv("PATH"))
+// failingReader is a ClusterReader whose every call fails, standing in for
+// an unreachable apiserver.
+type failingReader struct{}
+
+func (failingReader) ListPods(context.Context, string) (*corev1.PodList, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingReader) ListEvents(context.Context, string) (*corev1.EventList, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingReader) ListPVCs(context.Context, string) (*corev1.PersistentVolumeClaimList, error) {
+	return nil, errors.New("connection refused")
+}
+
+func (failingReader) HelmReleaseStatus(context.Context, string, string) (*kube.HelmRelease, error) {
+	return nil, errors.New("connection refused")
 }
 
 func TestParseVerdict_StrictJSON(t *testing.T) {
@@ -318,12 +331,9 @@ func contains(haystack []string, needle string) bool {
 }
 
 func TestWatch_SnapshotFailureRespectsMaxTicks(t *testing.T) {
-	useFailingKubectl(t)
-
 	// Regression: snapshot-fail path used to skip tick++, so MaxTicks
-	// could not bound the loop when kubectl was permanently broken.
-	// We simulate "always-broken kubectl" by pointing GatherSnapshot at a
-	// nonexistent namespace with no kubeconfig; it returns quickly with
+	// could not bound the loop when the apiserver was permanently
+	// unreachable. failingReader makes every snapshot return quickly with
 	// an error. With MaxTicks=2 and a sub-second interval the loop must
 	// exit within a small wall-clock budget.
 	opts := Options{
@@ -332,6 +342,7 @@ func TestWatch_SnapshotFailureRespectsMaxTicks(t *testing.T) {
 			Namespace:      "definitely-does-not-exist-" + nowSuffix(),
 			SkipHelmStatus: true,
 			KubeContext:    "definitely-does-not-exist-context",
+			Reader:         failingReader{},
 		},
 		Interval: 10 * time.Millisecond,
 		MaxTicks: 2,
@@ -485,7 +496,7 @@ func TestSnapshot_InstallCompleteRequiresDeployedRelease(t *testing.T) {
 }
 
 func TestWatch_MaxDurationStopsLoop(t *testing.T) {
-	// With a sub-second wall-clock cap and no real cluster, snapshot
+	// With a sub-second wall-clock cap and an unreachable cluster, snapshot
 	// gathering will fail repeatedly. The MaxDuration timeout must close
 	// the loop and surface context.DeadlineExceeded.
 	opts := Options{
@@ -494,6 +505,7 @@ func TestWatch_MaxDurationStopsLoop(t *testing.T) {
 			Namespace:      "definitely-does-not-exist-" + nowSuffix(),
 			SkipHelmStatus: true,
 			KubeContext:    "definitely-does-not-exist-context",
+			Reader:         failingReader{},
 		},
 		Interval:    10 * time.Millisecond,
 		MaxTicks:    -1, // disable tick bound to isolate duration check
@@ -514,14 +526,13 @@ func TestWatch_MaxDurationStopsLoop(t *testing.T) {
 }
 
 func TestWatch_MaxErrorsStopsRetryStorm(t *testing.T) {
-	useFailingKubectl(t)
-
 	opts := Options{
 		CLI: AgentCLI{Name: "claude", Path: "/nonexistent-but-required-by-validation"},
 		Snapshot: SnapshotOptions{
 			Namespace:      "definitely-does-not-exist-" + nowSuffix(),
 			SkipHelmStatus: true,
 			KubeContext:    "definitely-does-not-exist-context",
+			Reader:         failingReader{},
 		},
 		Interval:  10 * time.Millisecond,
 		MaxTicks:  -1,
@@ -0,0 +1,120 @@
+package agentwatch
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"scripts/camunda-core/pkg/logging"
+)
+
+// Engine selects where per-tick verdicts come from.
+type Engine string
+
+const (
+	// EngineAgent hands every snapshot to the local agent CLI. Each tick is
+	// a paid LLM call.
+	EngineAgent Engine = "agent"
+	// EngineRules evaluates the built-in failure signatures only. It is
+	// free, deterministic and works offline, but only recognises what its
+	// rules describe.
+	EngineRules Engine = "rules"
+	// EngineHybrid evaluates the rules first and calls the agent only on
+	// ticks where no rule fired.
+	EngineHybrid Engine = "hybrid"
+)
+
+// Engines lists the supported engines, for flag help and validation.
+var Engines = []Engine{EngineAgent, EngineRules, EngineHybrid}
+
+// ParseEngine validates an --engine value. An empty value selects
+// EngineAgent, the historical behaviour.
+func ParseEngine(s string) (Engine, error) {
+	if s == "" {
+		return EngineAgent, nil
+	}
+	for _, e := range Engines {
+		if string(e) == s {
+			return e, nil
+		}
+	}
+	return "", fmt.Errorf("unsupported engine %q; supported: %v", s, Engines)
+}
+
+// UsesAgent reports whether e may invoke the agent CLI.
+func (e Engine) UsesAgent() bool {
+	return e == EngineAgent || e == EngineHybrid
+}
+
+// VerdictSource produces the raw verdict JSON for one snapshot. The output
+// goes through ParseVerdict like an agent response, so every source shares
+// the same validation and corpus format.
+type VerdictSource interface {
+	Decide(ctx context.Context, snap Snapshot, snapshotJSON []byte) ([]byte, error)
+}
+
+// AgentSource asks the local agent CLI for a verdict.
+type AgentSource struct {
+	CLI AgentCLI
+	// Prompt is the instruction passed on every call. Empty means
+	// DefaultPrompt.
+	Prompt string
+}
+
+// Decide implements VerdictSource.
+func (a AgentSource) Decide(ctx context.Context, _ Snapshot, snapshotJSON []byte) ([]byte, error) {
+	if a.CLI.Name == "" {
+		return nil, errors.New("agentwatch: agent CLI is empty (call DetectCLI first)")
+	}
+	prompt := a.Prompt
+	if prompt == "" {
+		prompt = DefaultPrompt
+	}
+	return Invoke(ctx, a.CLI, prompt, snapshotJSON)
+}
+
+// RulesSource answers from the built-in failure signatures and reports
+// "wait" when none fires.
+type RulesSource struct{}
+
+// Decide implements VerdictSource.
+func (RulesSource) Decide(_ context.Context, snap Snapshot, _ []byte) ([]byte, error) {
+	v, name, ok := EvaluateRules(snap)
+	if !ok {
+		v = noRuleVerdict()
+	} else {
+		logging.Logger.Debug().Str("rule", name).Msg("agentwatch: rule fired")
+	}
+	return json.Marshal(v)
+}
+
+// HybridSource answers from the rules when one fires and falls back to the
+// agent otherwise, so the paid call only happens on snapshots the rules
+// cannot explain.
+type HybridSource struct {
+	Agent AgentSource
+}
+
+// Decide implements VerdictSource.
+func (h HybridSource) Decide(ctx context.Context, snap Snapshot, snapshotJSON []byte) ([]byte, error) {
+	if v, name, ok := EvaluateRules(snap); ok {
+		logging.Logger.Debug().Str("rule", name).Msg("agentwatch: rule fired; skipping agent call")
+		return json.Marshal(v)
+	}
+	return h.Agent.Decide(ctx, snap, snapshotJSON)
+}
+
+// NewVerdictSource builds the VerdictSource for engine. cli is ignored by
+// EngineRules and may be the zero value there.
+func NewVerdictSource(engine Engine, cli AgentCLI, prompt string) (VerdictSource, error) {
+	switch engine {
+	case EngineAgent, "":
+		return AgentSource{CLI: cli, Prompt: prompt}, nil
+	case EngineRules:
+		return RulesSource{}, nil
+	case EngineHybrid:
+		return HybridSource{Agent: AgentSource{CLI: cli, Prompt: prompt}}, nil
+	default:
+		return nil, fmt.Errorf("unsupported engine %q; supported: %v", engine, Engines)
+	}
+}
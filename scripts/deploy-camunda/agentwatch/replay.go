@@ -23,10 +23,18 @@ type tickRecord struct {
 // ReplayResult is the outcome of replaying a single tick: the recorded and
 // freshly-produced verdicts side by side, plus a regression flag.
 type ReplayResult struct {
-	File          string
-	Recorded      *Verdict
-	Replayed      *Verdict
-	Error         string
+	File string
+	// Timestamp is when the tick was captured; zero when the record's
+	// timestamp is unparseable.
+	Timestamp time.Time
+	// Label is the ground truth from the corpus labels file, if the tick
+	// is labelled.
+	Label    *TickLabel
+	Recorded *Verdict
+	Replayed *Verdict
+	Error    string
+	// ActionChanged is true when the replayed action differs from the
+	// recorded one. Always false for ticks without a recorded verdict.
 	ActionChanged bool
 	// ConfidenceDelta is replayed.Confidence - recorded.Confidence. Useful
 	// for spotting silent calibration drift.
@@ -38,20 +46,30 @@ type ReplayReport struct {
 	CorpusDir   string
 	Results     []ReplayResult
 	Regressions int
-	Skipped     int
-	Duration    time.Duration
+	// Mismatches counts labelled ticks whose replayed action differs from
+	// the label.
+	Mismatches int
+	Skipped    int
+	Duration   time.Duration
 }
 
-// Replay re-runs the agent CLI over every tick record in dir and returns a
-// ReplayReport comparing recorded verdicts to fresh ones. Tick files that
-// have no recorded verdict (i.e. the original run failed to parse one) are
-// skipped — they are an input to prompt iteration, not regressions.
-func Replay(ctx context.Context, cli AgentCLI, dir string) (ReplayReport, error) {
+// Replay re-runs a verdict source over every tick record in dir and returns
+// a ReplayReport comparing recorded verdicts to fresh ones. Pass an
+// AgentSource to regression-test prompt or model changes, or RulesSource to
+// use a labelled corpus as fixtures for the rules engine. Tick files that
+// have neither a recorded verdict (i.e. the original run failed to parse
+// one) nor a label are skipped — they are an input to prompt iteration, not
+// regressions. Labels are read from LabelsFile in dir when present.
+func Replay(ctx context.Context, source VerdictSource, dir string) (ReplayReport, error) {
 	start := time.Now()
 	files, err := listTickFiles(dir)
 	if err != nil {
 		return ReplayReport{}, err
 	}
+	labels, err := LoadLabels(dir)
+	if err != nil {
+		return ReplayReport{}, err
+	}
 	report := ReplayReport{CorpusDir: dir, Results: make([]ReplayResult, 0, len(files))}
 
 	for _, f := range files {
@@ -68,36 +86,58 @@ func Replay(ctx context.Context, cli AgentCLI, dir string) (ReplayReport, error)
 			report.Skipped++
 			continue
 		}
-		if rec.Verdict == nil {
+		var label *TickLabel
+		if l, ok := labels[filepath.Base(f)]; ok {
+			label = &l
+		}
+		if rec.Verdict == nil && label == nil {
 			report.Skipped++
 			continue
 		}
 
-		raw, err := Invoke(ctx, cli, DefaultPrompt, rec.Snapshot)
+		var snap Snapshot
+		if err := json.Unmarshal(rec.Snapshot, &snap); err != nil {
+			report.Results = append(report.Results, ReplayResult{
+				File: f, Label: label, Recorded: rec.Verdict, Error: "snapshot: " + err.Error(),
+			})
+			continue
+		}
+		ts := parseTickTimestamp(rec.Timestamp)
+		if ts.IsZero() {
+			ts = snap.Timestamp
+		}
+		raw, err := source.Decide(ctx, snap, rec.Snapshot)
 		if err != nil {
 			report.Results = append(report.Results, ReplayResult{
-				File: f, Recorded: rec.Verdict, Error: err.Error(),
+				File: f, Timestamp: ts, Label: label, Recorded: rec.Verdict, Error: err.Error(),
 			})
 			continue
 		}
 		fresh, err := ParseVerdict(raw)
 		if err != nil {
 			report.Results = append(report.Results, ReplayResult{
-				File: f, Recorded: rec.Verdict, Error: "parse: " + err.Error(),
+				File: f, Timestamp: ts, Label: label, Recorded: rec.Verdict, Error: "parse: " + err.Error(),
 			})
 			continue
 		}
 
 		result := ReplayResult{
-			File:            f,
-			Recorded:        rec.Verdict,
-			Replayed:        &fresh,
-			ActionChanged:   fresh.RecommendedAction != rec.Verdict.RecommendedAction,
-			ConfidenceDelta: fresh.Confidence - rec.Verdict.Confidence,
+			File:      f,
+			Timestamp: ts,
+			Label:     label,
+			Recorded:  rec.Verdict,
+			Replayed:  &fresh,
+		}
+		if rec.Verdict != nil {
+			result.ActionChanged = fresh.RecommendedAction != rec.Verdict.RecommendedAction
+			result.ConfidenceDelta = fresh.Confidence - rec.Verdict.Confidence
 		}
 		if result.ActionChanged {
 			report.Regressions++
 		}
+		if result.LabelMismatch() {
+			report.Mismatches++
+		}
 		report.Results = append(report.Results, result)
 	}
 
@@ -105,12 +145,19 @@ func Replay(ctx context.Context, cli AgentCLI, dir string) (ReplayReport, error)
 	return report, nil
 }
 
-// Format renders a human-readable summary for stdout.
+// LabelMismatch reports whether the tick is labelled and the replayed action
+// differs from the label.
+func (res ReplayResult) LabelMismatch() bool {
+	return res.Label != nil && res.Replayed != nil && res.Replayed.RecommendedAction != res.Label.Action
+}
+
+// Format renders a human-readable summary for stdout. Labelled corpora get
+// an extra column with the expected action and a score section.
 func (r ReplayReport) Format() string {
 	var b strings.Builder
 	fmt.Fprintf(&b, "Replay corpus: %s\n", r.CorpusDir)
-	fmt.Fprintf(&b, "Total: %d   Regressions: %d   Skipped: %d   Duration: %s\n\n",
-		len(r.Results), r.Regressions, r.Skipped, r.Duration.Round(time.Millisecond))
+	fmt.Fprintf(&b, "Total: %d   Regressions: %d   Mismatches: %d   Skipped: %d   Duration: %s\n\n",
+		len(r.Results), r.Regressions, r.Mismatches, r.Skipped, r.Duration.Round(time.Millisecond))
 	for _, res := range r.Results {
 		base := filepath.Base(res.File)
 		if res.Error != "" {
@@ -118,13 +165,26 @@ func (r ReplayReport) Format() string {
 			continue
 		}
 		marker := "  ok  "
-		if res.ActionChanged {
+		switch {
+		case res.LabelMismatch():
+			marker = "MISMATCH"
+		case res.ActionChanged:
 			marker = "REGRESS"
 		}
-		fmt.Fprintf(&b, "  %s  %s  %s -> %s  Δconf=%+.2f\n",
-			marker, base,
-			res.Recorded.RecommendedAction, res.Replayed.RecommendedAction,
-			res.ConfidenceDelta)
+		recorded := "-"
+		if res.Recorded != nil {
+			recorded = string(res.Recorded.RecommendedAction)
+		}
+		fmt.Fprintf(&b, "  %s  %s  %s -> %s  Δconf=%+.2f",
+			marker, base, recorded, res.Replayed.RecommendedAction, res.ConfidenceDelta)
+		if res.Label != nil {
+			fmt.Fprintf(&b, "  expected=%s", res.Label.Action)
+		}
+		b.WriteString("\n")
+	}
+	if score := r.Score(); score.Labelled > 0 {
+		b.WriteString("\n")
+		b.WriteString(score.Format())
 	}
 	return b.String()
 }
@@ -0,0 +1,468 @@
+package agentwatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The rules engine turns well-known failure signatures into the same Verdict
+// schema the agent produces, without a model call. Rules run in priority
+// order and the first one that fires owns the verdict; a snapshot no rule
+// recognises yields ok=false from EvaluateRules, which the rules engine
+// reports as "wait" and the hybrid engine hands to the agent.
+
+// Confidence values the built-in rules report. They sit above the README's
+// recommended --abort-confidence (0.85) only for signatures that cannot
+// self-recover.
+const (
+	ruleConfidenceCertain   = 0.95
+	ruleConfidenceHigh      = 0.9
+	ruleConfidenceLikely    = 0.85
+	ruleConfidenceSuspected = 0.7
+	// noRuleConfidence is attached to the "wait" verdict the rules engine
+	// returns when nothing fires. It is deliberately middling: absence of
+	// a known signature is weak evidence that the install is healthy.
+	noRuleConfidence = 0.5
+)
+
+// oomKillWindow is how soon after container start an OOMKill counts as a
+// sizing problem rather than a load-dependent leak.
+const oomKillWindow = 30 * time.Second
+
+// pvcPendingGrace is how long a PVC may stay Pending before an unbound-claim
+// scheduling failure is reported. WaitForFirstConsumer classes keep claims
+// Pending until the first pod is scheduled, so a fresh Pending claim is
+// normal.
+const pvcPendingGrace = 2 * time.Minute
+
+// unschedulableGrace is how long a pod may keep failing to schedule for
+// insufficient resources before it is reported as a dead end when no
+// autoscaler has answered for it. Until then pods of the previous revision,
+// jobs and evictions may still free capacity.
+const unschedulableGrace = 5 * time.Minute
+
+var (
+	// missingImageRe matches registry responses for a tag or digest that does
+	// not exist, as surfaced in ErrImagePull/ImagePullBackOff messages.
+	missingImageRe = regexp.MustCompile(`(?i)manifest unknown|manifest for \S+ not found|code = NotFound|failed to resolve reference .*: not found`)
+	// missingSecretRe captures the secret name from kubelet's
+	// CreateContainerConfigError / FailedMount messages.
+	missingSecretRe = regexp.MustCompile(`secret "([^"]+)" not found`)
+	// insufficientResourceRe captures the resource name from the scheduler's
+	// FailedScheduling message ("3 Insufficient cpu").
+	insufficientResourceRe = regexp.MustCompile(`Insufficient (cpu|memory|ephemeral-storage)`)
+)
+
+// rule is one failure signature. evaluate returns ok=false when the
+// signature is absent from the snapshot.
+type rule struct {
+	name     string
+	evaluate func(clusterView) (Verdict, bool)
+}
+
+// builtinRules lists the signatures in priority order: the most specific,
+// never-self-recovering causes first.
+var builtinRules = []rule{
+	{name: "image-tag-missing", evaluate: ruleImageTagMissing},
+	{name: "secret-missing", evaluate: ruleSecretMissing},
+	{name: "oom-killed-at-start", evaluate: ruleOOMKilledAtStart},
+	{name: "pvc-unbound", evaluate: rulePVCUnbound},
+	{name: "insufficient-resources", evaluate: ruleInsufficientResources},
+}
+
+// RuleNames returns the built-in rule names in evaluation order. Exposed for
+// documentation and tests.
+func RuleNames() []string {
+	out := make([]string, 0, len(builtinRules))
+	for _, r := range builtinRules {
+		out = append(out, r.name)
+	}
+	return out
+}
+
+// EvaluateRules runs the built-in failure signatures over s and returns the
+// verdict of the first rule that fires together with its name. ok is false
+// when no rule matched.
+func EvaluateRules(s Snapshot) (verdict Verdict, ruleName string, ok bool) {
+	view := newClusterView(s)
+	for _, r := range builtinRules {
+		if v, fired := r.evaluate(view); fired {
+			return v, r.name, true
+		}
+	}
+	return Verdict{}, "", false
+}
+
+// noRuleVerdict is what the rules engine reports when nothing fired.
+func noRuleVerdict() Verdict {
+	return Verdict{
+		Diagnosis:         "No known failure signature in the snapshot; install appears to be progressing.",
+		CausalChain:       []string{},
+		Confidence:        noRuleConfidence,
+		RecommendedAction: ActionWait,
+	}
+}
+
+// clusterView is the typed form of a Snapshot the rules evaluate against.
+// Unparseable sections are left empty: a rule simply does not fire on data
+// it cannot read.
+type clusterView struct {
+	now     time.Time
+	release string
+	start   time.Time
+	pods    []corev1.Pod
+	events  []corev1.Event
+	pvcs    []corev1.PersistentVolumeClaim
+}
+
+func newClusterView(s Snapshot) clusterView {
+	v := clusterView{now: s.Timestamp, release: s.Release}
+	var pods corev1.PodList
+	if json.Unmarshal(s.Pods, &pods) == nil {
+		v.pods = pods.Items
+	}
+	var events corev1.EventList
+	if json.Unmarshal(s.Events, &events) == nil {
+		v.events = events.Items
+	}
+	var pvcs corev1.PersistentVolumeClaimList
+	if len(s.PVCs) > 0 && json.Unmarshal(s.PVCs, &pvcs) == nil {
+		v.pvcs = pvcs.Items
+	}
+	sort.SliceStable(v.events, func(i, j int) bool {
+		return eventTime(v.events[i]).Before(eventTime(v.events[j]))
+	})
+
+	// T+0 is the oldest object we can see, so causal-chain offsets read as
+	// "seconds into the install".
+	for _, p := range v.pods {
+		if t := p.CreationTimestamp.Time; !t.IsZero() && (v.start.IsZero() || t.Before(v.start)) {
+			v.start = t
+		}
+	}
+	for _, e := range v.events {
+		if t := eventTime(e); !t.IsZero() && (v.start.IsZero() || t.Before(v.start)) {
+			v.start = t
+		}
+	}
+	if v.now.IsZero() {
+		v.now = time.Now().UTC()
+	}
+	return v
+}
+
+// eventTime picks the most specific timestamp an event carries.
+func eventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.LastTimestamp.IsZero():
+		return e.LastTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.FirstTimestamp.Time
+	}
+}
+
+// firstEventTime is when an event was first seen, for age checks on events
+// the apiserver deduplicates into a count.
+func firstEventTime(e corev1.Event) time.Time {
+	switch {
+	case !e.FirstTimestamp.IsZero():
+		return e.FirstTimestamp.Time
+	case !e.EventTime.IsZero():
+		return e.EventTime.Time
+	default:
+		return e.LastTimestamp.Time
+	}
+}
+
+// offset renders t relative to the view's T+0 in the "t+NNs" form the agent
+// prompt asks for.
+func (v clusterView) offset(t time.Time) string {
+	if t.IsZero() || v.start.IsZero() {
+		return "t+?"
+	}
+	return fmt.Sprintf("t+%ds", int(t.Sub(v.start).Seconds()))
+}
+
+// eventsFor returns the events whose involved object is kind/name, oldest
+// first.
+func (v clusterView) eventsFor(kind, name string) []corev1.Event {
+	var out []corev1.Event
+	for _, e := range v.events {
+		if e.InvolvedObject.Kind == kind && e.InvolvedObject.Name == name {
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// inRelease reports whether an object belongs to the watched release: it
+// carries the release's instance label or its name contains the release
+// name, as every object the chart renders does. Without a release name
+// everything matches.
+func (v clusterView) inRelease(meta metav1.ObjectMeta) bool {
+	if v.release == "" || meta.Labels["app.kubernetes.io/instance"] == v.release {
+		return true
+	}
+	return strings.Contains(meta.Name, v.release)
+}
+
+// podsUsingClaim returns the names of the pods that mount claim.
+func (v clusterView) podsUsingClaim(claim string) map[string]bool {
+	out := map[string]bool{}
+	for _, p := range v.pods {
+		for _, vol := range p.Spec.Volumes {
+			if vol.PersistentVolumeClaim != nil && vol.PersistentVolumeClaim.ClaimName == claim {
+				out[p.Name] = true
+			}
+		}
+	}
+	return out
+}
+
+// chainFor renders an object's events as causal-chain lines.
+func (v clusterView) chainFor(events []corev1.Event) []string {
+	out := make([]string, 0, len(events))
+	for _, e := range events {
+		out = append(out, fmt.Sprintf("%s %s %s/%s: %s",
+			v.offset(eventTime(e)), e.Reason, strings.ToLower(e.InvolvedObject.Kind), e.InvolvedObject.Name,
+			strings.TrimSpace(e.Message)))
+	}
+	return out
+}
+
+// allContainerStatuses returns init and app container statuses of p.
+func allContainerStatuses(p corev1.Pod) []corev1.ContainerStatus {
+	out := make([]corev1.ContainerStatus, 0, len(p.Status.InitContainerStatuses)+len(p.Status.ContainerStatuses))
+	out = append(out, p.Status.InitContainerStatuses...)
+	return append(out, p.Status.ContainerStatuses...)
+}
+
+func ruleImageTagMissing(v clusterView) (Verdict, bool) {
+	for _, p := range v.pods {
+		for _, cs := range allContainerStatuses(p) {
+			w := cs.State.Waiting
+			if w == nil || (w.Reason != "ImagePullBackOff" && w.Reason != "ErrImagePull") {
+				continue
+			}
+			events := v.eventsFor("Pod", p.Name)
+			message := w.Message
+			for _, e := range events {
+				if e.Reason == "Failed" && missingImageRe.MatchString(e.Message) {
+					message = e.Message
+				}
+			}
+			if !missingImageRe.MatchString(message) {
+				continue
+			}
+			return Verdict{
+				Diagnosis: fmt.Sprintf("Pod %s container %s cannot start: image %s does not exist in the registry (%s). "+
+					"Fix the image tag/digest in the values for this component and redeploy.",
+					p.Name, cs.Name, cs.Image, strings.TrimSpace(message)),
+				CausalChain:       v.chainFor(events),
+				Confidence:        ruleConfidenceCertain,
+				RecommendedAction: ActionAbort,
+				Evidence:          []string{"pod=" + p.Name, "container=" + cs.Name, "image=" + cs.Image, "reason=" + w.Reason},
+			}, true
+		}
+	}
+	return Verdict{}, false
+}
+
+func ruleSecretMissing(v clusterView) (Verdict, bool) {
+	for _, p := range v.pods {
+		events := v.eventsFor("Pod", p.Name)
+		for _, cs := range allContainerStatuses(p) {
+			w := cs.State.Waiting
+			if w == nil || w.Reason != "CreateContainerConfigError" {
+				continue
+			}
+			m := missingSecretRe.FindStringSubmatch(w.Message)
+			if m == nil {
+				continue
+			}
+			return Verdict{
+				Diagnosis: fmt.Sprintf("Pod %s container %s references secret %q (env/envFrom), which does not exist in the namespace. "+
+					"Create the secret or fix the reference in the values; kubelet will not start the container until it exists.",
+					p.Name, cs.Name, m[1]),
+				CausalChain:       v.chainFor(events),
+				Confidence:        ruleConfidenceHigh,
+				RecommendedAction: ActionAbort,
+				Evidence:          []string{"pod=" + p.Name, "container=" + cs.Name, "secret=" + m[1], "reason=" + w.Reason},
+			}, true
+		}
+		for _, e := range events {
+			if e.Reason != "FailedMount" {
+				continue
+			}
+			m := missingSecretRe.FindStringSubmatch(e.Message)
+			if m == nil {
+				continue
+			}
+			return Verdict{
+				Diagnosis: fmt.Sprintf("Pod %s cannot mount secret %q, which does not exist in the namespace. "+
+					"Create the secret or fix the volume reference in the values.", p.Name, m[1]),
+				CausalChain:       v.chainFor(events),
+				Confidence:        ruleConfidenceHigh,
+				RecommendedAction: ActionAbort,
+				Evidence:          []string{"pod=" + p.Name, "secret=" + m[1], "event=FailedMount"},
+			}, true
+		}
+	}
+	return Verdict{}, false
+}
+
+func ruleOOMKilledAtStart(v clusterView) (Verdict, bool) {
+	for _, p := range v.pods {
+		for _, cs := range allContainerStatuses(p) {
+			for _, term := range []*corev1.ContainerStateTerminated{cs.State.Terminated, cs.LastTerminationState.Terminated} {
+				if term == nil || term.Reason != "OOMKilled" {
+					continue
+				}
+				if term.StartedAt.IsZero() || term.FinishedAt.IsZero() {
+					continue
+				}
+				lived := term.FinishedAt.Sub(term.StartedAt.Time)
+				if lived > oomKillWindow {
+					continue
+				}
+				chain := []string{
+					fmt.Sprintf("%s container %s started", v.offset(term.StartedAt.Time), cs.Name),
+					fmt.Sprintf("%s container %s OOMKilled after %s (exit %d, restarts %d)",
+						v.offset(term.FinishedAt.Time), cs.Name, lived.Round(time.Second), term.ExitCode, cs.RestartCount),
+				}
+				return Verdict{
+					Diagnosis: fmt.Sprintf("Pod %s container %s was OOMKilled %s after start, before it could serve anything. "+
+						"The memory limit is too small for the JVM/heap settings; raise resources.limits.memory or lower the heap.",
+						p.Name, cs.Name, lived.Round(time.Second)),
+					CausalChain:       chain,
+					Confidence:        ruleConfidenceHigh,
+					RecommendedAction: ActionAbort,
+					Evidence:          []string{"pod=" + p.Name, "container=" + cs.Name, "reason=OOMKilled"},
+				}, true
+			}
+		}
+	}
+	return Verdict{}, false
+}
+
+func rulePVCUnbound(v clusterView) (Verdict, bool) {
+	for _, pvc := range v.pvcs {
+		if pvc.Status.Phase != corev1.ClaimPending {
+			continue
+		}
+		events := v.eventsFor("PersistentVolumeClaim", pvc.Name)
+		for _, e := range events {
+			if e.Reason != "ProvisioningFailed" {
+				continue
+			}
+			return Verdict{
+				Diagnosis: fmt.Sprintf("PVC %s cannot be provisioned: %s. Pods using it will stay Pending; "+
+					"fix the storage class or provisioner before retrying.", pvc.Name, strings.TrimSpace(e.Message)),
+				CausalChain:       v.chainFor(events),
+				Confidence:        ruleConfidenceHigh,
+				RecommendedAction: ActionAbort,
+				Evidence:          []string{"pvc=" + pvc.Name, "event=ProvisioningFailed"},
+			}, true
+		}
+	}
+	// A release claim that stays Pending past the grace period while the
+	// scheduler reports unbound claims for a pod mounting it is suspicious
+	// but may still be waiting on a slow provisioner, so it is only
+	// surfaced. The scheduler message does not name the claim, so the pod's
+	// volumes tie the two together.
+	for _, pvc := range v.pvcs {
+		if pvc.Status.Phase != corev1.ClaimPending || pvc.CreationTimestamp.IsZero() || !v.inRelease(pvc.ObjectMeta) {
+			continue
+		}
+		if v.now.Sub(pvc.CreationTimestamp.Time) < pvcPendingGrace {
+			continue
+		}
+		users := v.podsUsingClaim(pvc.Name)
+		for _, e := range v.events {
+			if e.Reason != "FailedScheduling" || !strings.Contains(e.Message, "unbound immediate PersistentVolumeClaims") {
+				continue
+			}
+			if e.InvolvedObject.Kind != "Pod" || !users[e.InvolvedObject.Name] {
+				continue
+			}
+			return Verdict{
+				Diagnosis: fmt.Sprintf("PVC %s has been Pending for %s and pod %s cannot be scheduled because of unbound claims. "+
+					"Check that the storage class exists and its provisioner is running.",
+					pvc.Name, v.now.Sub(pvc.CreationTimestamp.Time).Round(time.Second), e.InvolvedObject.Name),
+				CausalChain: append(
+					[]string{fmt.Sprintf("%s pvc/%s created", v.offset(pvc.CreationTimestamp.Time), pvc.Name)},
+					v.chainFor([]corev1.Event{e})...),
+				Confidence:        ruleConfidenceSuspected,
+				RecommendedAction: ActionInvestigate,
+				Evidence:          []string{"pvc=" + pvc.Name, "pod=" + e.InvolvedObject.Name, "event=FailedScheduling"},
+			}, true
+		}
+	}
+	return Verdict{}, false
+}
+
+func ruleInsufficientResources(v clusterView) (Verdict, bool) {
+	for _, e := range v.events {
+		if e.Reason != "FailedScheduling" {
+			continue
+		}
+		m := insufficientResourceRe.FindStringSubmatch(e.Message)
+		if m == nil {
+			continue
+		}
+		pod := e.InvolvedObject.Name
+		// The cluster autoscaler may still fix this: a pod it scaled up for
+		// is waiting, one it declined to scale for is a dead end.
+		events := v.eventsFor("Pod", pod)
+		scaling, declined := false, false
+		for _, pe := range events {
+			switch pe.Reason {
+			case "TriggeredScaleUp":
+				scaling = true
+			case "NotTriggerScaleUp":
+				declined = true
+			}
+		}
+		if scaling {
+			continue
+		}
+		evidence := []string{"pod=" + pod, "event=FailedScheduling", "resource=" + m[1]}
+		pending := v.now.Sub(firstEventTime(e))
+		if declined || pending >= unschedulableGrace {
+			reason := "the autoscaler declined to add nodes"
+			if declined {
+				evidence = append(evidence, "event=NotTriggerScaleUp")
+			} else {
+				reason = fmt.Sprintf("it has been unschedulable for %s", pending.Round(time.Second))
+			}
+			return Verdict{
+				Diagnosis: fmt.Sprintf("Pod %s cannot be scheduled: the scheduler reports Insufficient %s and %s. "+
+					"The cluster is too small for the requested resources; lower the requests or add capacity.", pod, m[1], reason),
+				CausalChain:       v.chainFor(events),
+				Confidence:        ruleConfidenceLikely,
+				RecommendedAction: ActionAbort,
+				Evidence:          evidence,
+			}, true
+		}
+		// A fresh scheduling failure often clears once old pods terminate,
+		// so it is only surfaced.
+		return Verdict{
+			Diagnosis: fmt.Sprintf("Pod %s cannot be scheduled yet: the scheduler reports Insufficient %s. "+
+				"It may clear as other pods terminate; if it persists, lower the requests or add capacity.", pod, m[1]),
+			CausalChain:       v.chainFor(events),
+			Confidence:        ruleConfidenceSuspected,
+			RecommendedAction: ActionInvestigate,
+			Evidence:          evidence,
+		}, true
+	}
+	return Verdict{}, false
+}
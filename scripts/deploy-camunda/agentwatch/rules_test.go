@@ -0,0 +1,237 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentwatch
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const rulesCorpusDir = "testdata/corpus"
+
+// TestReplay_RulesEngineMatchesCorpus uses the labelled corpus as the rules
+// engine's regression fixtures: every recorded action must be reproduced.
+func TestReplay_RulesEngineMatchesCorpus(t *testing.T) {
+	report, err := Replay(context.Background(), RulesSource{}, rulesCorpusDir)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if len(report.Results) == 0 {
+		t.Fatal("expected corpus fixtures to be replayed")
+	}
+	for _, res := range report.Results {
+		if res.Error != "" {
+			t.Errorf("%s: %s", filepath.Base(res.File), res.Error)
+			continue
+		}
+		if res.ActionChanged {
+			t.Errorf("%s: recorded %s, rules engine said %s (%s)",
+				filepath.Base(res.File), res.Recorded.RecommendedAction,
+				res.Replayed.RecommendedAction, res.Replayed.Diagnosis)
+		}
+	}
+}
+
+func TestEvaluateRules_FiringRuleAndEvidence(t *testing.T) {
+	cases := []struct {
+		file     string
+		rule     string
+		evidence string
+	}{
+		{file: "02-image-tag-missing.json", rule: "image-tag-missing", evidence: "image=camunda/operate:8.9.99"},
+		{file: "03-oom-killed-at-start.json", rule: "oom-killed-at-start", evidence: "reason=OOMKilled"},
+		{file: "04-pvc-unbound.json", rule: "pvc-unbound", evidence: "pvc=data-integration-elasticsearch-master-0"},
+		{file: "05-insufficient-cpu.json", rule: "insufficient-resources", evidence: "resource=cpu"},
+		{file: "07-secret-missing-envfrom.json", rule: "secret-missing", evidence: "secret=integration-connectors-auth"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.rule, func(t *testing.T) {
+			v, name, ok := EvaluateRules(loadCorpusSnapshot(t, tc.file))
+			if !ok {
+				t.Fatal("expected a rule to fire")
+			}
+			if name != tc.rule {
+				t.Fatalf("rule = %q, want %q", name, tc.rule)
+			}
+			if err := v.Valid(); err != nil {
+				t.Fatalf("rule produced invalid verdict: %v", err)
+			}
+			if !contains(v.Evidence, tc.evidence) {
+				t.Fatalf("evidence %v missing %q", v.Evidence, tc.evidence)
+			}
+			if len(v.CausalChain) == 0 || !strings.HasPrefix(v.CausalChain[0], "t+") {
+				t.Fatalf("expected t+ offsets in causal chain, got %v", v.CausalChain)
+			}
+		})
+	}
+}
+
+func TestEvaluateRules_NoFireOnHealthyOrScalingCluster(t *testing.T) {
+	for _, file := range []string{"01-healthy-startup.json", "06-insufficient-cpu-scaling-up.json"} {
+		if _, name, ok := EvaluateRules(loadCorpusSnapshot(t, file)); ok {
+			t.Fatalf("%s: rule %q fired on a recoverable snapshot", file, name)
+		}
+	}
+}
+
+func TestEvaluateRules_OOMKillAfterWindowDoesNotFire(t *testing.T) {
+	snap := loadCorpusSnapshot(t, "03-oom-killed-at-start.json")
+	snap.Pods = json.RawMessage(strings.Replace(string(snap.Pods), "2026-05-04T10:00:52Z", "2026-05-04T10:05:52Z", 1))
+	if _, name, ok := EvaluateRules(snap); ok {
+		t.Fatalf("rule %q fired on an OOMKill minutes after start", name)
+	}
+}
+
+// TestEvaluateRules_InsufficientResourcesWaitsForAutoscalerOrGrace checks
+// that a scheduling failure no autoscaler has declined only aborts once it
+// outlives unschedulableGrace.
+func TestEvaluateRules_InsufficientResourcesWaitsForAutoscalerOrGrace(t *testing.T) {
+	snap := loadCorpusSnapshot(t, "05-insufficient-cpu.json")
+	var events corev1.EventList
+	if err := json.Unmarshal(snap.Events, &events); err != nil {
+		t.Fatal(err)
+	}
+	events.Items = slices.DeleteFunc(events.Items, func(e corev1.Event) bool { return e.Reason == "NotTriggerScaleUp" })
+	snap.Events = mustJSON(t, events)
+
+	v, name, ok := EvaluateRules(snap)
+	if !ok || name != "insufficient-resources" || v.RecommendedAction != ActionInvestigate {
+		t.Fatalf("fresh failure: rule %q ok=%v action %s, want insufficient-resources investigate", name, ok, v.RecommendedAction)
+	}
+
+	snap.Timestamp = snap.Timestamp.Add(unschedulableGrace)
+	if v, _, _ := EvaluateRules(snap); v.RecommendedAction != ActionAbort {
+		t.Fatalf("failure past the grace period: action %s, want abort", v.RecommendedAction)
+	}
+}
+
+// TestEvaluateRules_PVCFallbackIgnoresUnrelatedClaims checks that an
+// unbound-claim scheduling failure is only tied to a Pending claim the
+// failing pod mounts.
+func TestEvaluateRules_PVCFallbackIgnoresUnrelatedClaims(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 10, 0, 0, time.UTC)
+	created := metav1.NewTime(now.Add(-2 * pvcPendingGrace))
+	pvc := func(name string) corev1.PersistentVolumeClaim {
+		return corev1.PersistentVolumeClaim{
+			ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: created},
+			Status:     corev1.PersistentVolumeClaimStatus{Phase: corev1.ClaimPending},
+		}
+	}
+	pod := corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "integration-zeebe-0", CreationTimestamp: created},
+		Spec: corev1.PodSpec{Volumes: []corev1.Volume{{
+			Name: "data",
+			VolumeSource: corev1.VolumeSource{PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: "data-integration-zeebe-0",
+			}},
+		}}},
+	}
+	snap := Snapshot{
+		Timestamp: now,
+		Release:   "integration",
+		Pods:      mustJSON(t, corev1.PodList{Items: []corev1.Pod{pod}}),
+		Events: mustJSON(t, corev1.EventList{Items: []corev1.Event{{
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: pod.Name},
+			Reason:         "FailedScheduling",
+			Message:        "0/3 nodes are available: pod has unbound immediate PersistentVolumeClaims.",
+			LastTimestamp:  created,
+		}}}),
+	}
+
+	snap.PVCs = mustJSON(t, corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{pvc("data-integration-elasticsearch-master-0")}})
+	if _, name, ok := EvaluateRules(snap); ok {
+		t.Fatalf("rule %q fired for a claim the failing pod does not mount", name)
+	}
+
+	snap.PVCs = mustJSON(t, corev1.PersistentVolumeClaimList{Items: []corev1.PersistentVolumeClaim{pvc("data-integration-zeebe-0")}})
+	v, name, ok := EvaluateRules(snap)
+	if !ok || name != "pvc-unbound" || !contains(v.Evidence, "pvc=data-integration-zeebe-0") {
+		t.Fatalf("rule %q ok=%v evidence %v, want pvc-unbound for the mounted claim", name, ok, v.Evidence)
+	}
+}
+
+func TestHybridSource_CallsAgentOnlyWhenNoRuleFires(t *testing.T) {
+	dir := t.TempDir()
+	calls := filepath.Join(dir, "calls")
+	script := filepath.Join(dir, "opencode")
+	body := "#!/bin/sh\ncat >/dev/null\necho x >> " + calls + "\n" +
+		`echo '{"diagnosis":"agent looked","confidence":0.4,"recommended_action":"investigate"}'` + "\n"
+	if err := os.WriteFile(script, []byte(body), 0o755); err != nil {
+		t.Fatalf("write fake agent: %v", err)
+	}
+	source := HybridSource{Agent: AgentSource{CLI: AgentCLI{Name: "opencode", Path: script}}}
+
+	raw, err := source.Decide(context.Background(), loadCorpusSnapshot(t, "02-image-tag-missing.json"), nil)
+	if err != nil {
+		t.Fatalf("Decide: %v", err)
+	}
+	if v, _ := ParseVerdict(raw); v.RecommendedAction != ActionAbort {
+		t.Fatalf("expected rule verdict abort, got %s", raw)
+	}
+	if _, err := os.Stat(calls); !os.IsNotExist(err) {
+		t.Fatal("agent must not be called when a rule fires")
+	}
+
+	raw, err = source.Decide(context.Background(), loadCorpusSnapshot(t, "01-healthy-startup.json"), []byte("{}"))
+	if err != nil {
+		t.Fatalf("Decide: %v", err)
+	}
+	if v, _ := ParseVerdict(raw); v.Diagnosis != "agent looked" {
+		t.Fatalf("expected agent verdict, got %s", raw)
+	}
+}
+
+func TestParseEngine(t *testing.T) {
+	if e, err := ParseEngine(""); err != nil || e != EngineAgent {
+		t.Fatalf("empty engine should default to agent, got %q, %v", e, err)
+	}
+	if _, err := ParseEngine("llm"); err == nil {
+		t.Fatal("expected error for unknown engine")
+	}
+	if EngineRules.UsesAgent() || !EngineHybrid.UsesAgent() {
+		t.Fatal("UsesAgent: rules must not call the agent, hybrid must")
+	}
+}
+
+func mustJSON(t *testing.T, v any) json.RawMessage {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return data
+}
+
+func loadCorpusSnapshot(t *testing.T, name string) Snapshot {
+	t.Helper()
+	rec, err := readTickRecord(filepath.Join(rulesCorpusDir, name))
+	if err != nil {
+		t.Fatalf("read %s: %v", name, err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(rec.Snapshot, &snap); err != nil {
+		t.Fatalf("unmarshal %s snapshot: %v", name, err)
+	}
+	return snap
+}
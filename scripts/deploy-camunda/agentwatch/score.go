@@ -0,0 +1,379 @@
+package agentwatch
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"scripts/deploy-camunda/pkg/junit"
+
+	"gopkg.in/yaml.v3"
+)
+
+// LabelsFile is the optional file in a corpus directory that carries the
+// ground-truth outcome per tick, keyed by tick file name:
+//
+//	20260504T100130.000Z.json:
+//	  action: abort
+//	  culprit_pod: integration-operate-6d9f-abcde
+//	  scenario: operate-bad-tag
+//
+// Unlisted ticks are unlabelled and only take part in the recorded-vs-
+// replayed regression check.
+const LabelsFile = "labels.yaml"
+
+// TickLabel is the expected outcome for one tick.
+type TickLabel struct {
+	Action Action `yaml:"action" json:"action"`
+	// CulpritPod is the pod a correct diagnosis must name. Optional.
+	CulpritPod string `yaml:"culprit_pod,omitempty" json:"culprit_pod,omitempty"`
+	// Scenario groups the ticks of one watched install, so abort latency
+	// is measured within an install rather than across a corpus that
+	// mixes several. Optional; unnamed ticks form one scenario.
+	Scenario string `yaml:"scenario,omitempty" json:"scenario,omitempty"`
+}
+
+// LoadLabels reads LabelsFile from dir. A missing file is not an error and
+// yields a nil map.
+func LoadLabels(dir string) (map[string]TickLabel, error) {
+	path := filepath.Join(dir, LabelsFile)
+	raw, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read corpus labels %q: %w", path, err)
+	}
+	var labels map[string]TickLabel
+	if err := yaml.Unmarshal(raw, &labels); err != nil {
+		return nil, fmt.Errorf("parse corpus labels %q: %w", path, err)
+	}
+	for file, l := range labels {
+		switch l.Action {
+		case ActionWait, ActionInvestigate, ActionAbort:
+		default:
+			return nil, fmt.Errorf("corpus labels %q: %s has unknown action %q (want wait|investigate|abort)",
+				path, file, l.Action)
+		}
+	}
+	return labels, nil
+}
+
+// scoredActions fixes the row/column order of the confusion matrix.
+var scoredActions = []Action{ActionWait, ActionInvestigate, ActionAbort}
+
+// Score is the accuracy of a replay against the corpus labels.
+type Score struct {
+	// Labelled is the number of labelled ticks with a replayed verdict.
+	Labelled int `json:"labelled"`
+	// Confusion counts ticks by expected (outer key) and replayed (inner
+	// key) action.
+	Confusion map[Action]map[Action]int `json:"confusion"`
+	// AbortPrecision and AbortRecall are nil when undefined (no predicted
+	// or no expected aborts respectively).
+	AbortPrecision *float64 `json:"abort_precision"`
+	AbortRecall    *float64 `json:"abort_recall"`
+	// CulpritLabelled counts labelled non-wait ticks with a culprit pod
+	// that the engine flagged (investigate or abort); CulpritHits counts
+	// how many of those named the culprit in evidence or diagnosis.
+	CulpritLabelled int `json:"culprit_labelled"`
+	CulpritHits     int `json:"culprit_hits"`
+	// Latencies holds the abort latency of every scenario with a tick
+	// labelled investigate or abort, in corpus order.
+	Latencies []ScenarioLatency `json:"abort_latency"`
+}
+
+// ScenarioLatency is how long the engine took to abort one scenario.
+type ScenarioLatency struct {
+	Scenario string `json:"scenario"`
+	// FirstBadTick is the scenario's first tick labelled investigate or
+	// abort.
+	FirstBadTick string `json:"first_bad_tick"`
+	// FirstAbortTick is the first tick at or after FirstBadTick where the
+	// engine said abort. Empty when it never did.
+	FirstAbortTick string `json:"first_abort_tick,omitempty"`
+	// AbortLatency is the capture-time distance between FirstBadTick and
+	// FirstAbortTick, AbortLatencyTicks the same in ticks of the scenario.
+	// Nil when the engine never aborted after the first bad tick.
+	AbortLatency      *time.Duration `json:"-"`
+	AbortLatencyTicks *int           `json:"abort_latency_ticks"`
+}
+
+// MarshalJSON renders AbortLatency in seconds so the report is stable
+// across tooling that does not know Go durations.
+func (l ScenarioLatency) MarshalJSON() ([]byte, error) {
+	type plain ScenarioLatency
+	out := struct {
+		plain
+		AbortLatencySeconds *float64 `json:"abort_latency_seconds"`
+	}{plain: plain(l)}
+	if l.AbortLatency != nil {
+		secs := l.AbortLatency.Seconds()
+		out.AbortLatencySeconds = &secs
+	}
+	return json.Marshal(out)
+}
+
+// Score computes the confusion matrix, abort precision/recall, culprit hit
+// rate and abort latency over the labelled ticks. Results are expected in
+// capture order, which Replay guarantees by sorting the tick files.
+func (r ReplayReport) Score() Score {
+	s := Score{Confusion: map[Action]map[Action]int{}}
+	for _, a := range scoredActions {
+		s.Confusion[a] = map[Action]int{}
+	}
+
+	var tp, fp, fn int
+	// Latency is tracked per scenario: firstBad indexes the scenario's
+	// first bad tick in Results, latency its entry in s.Latencies.
+	type tracker struct{ firstBad, latency int }
+	scenarios := map[string]*tracker{}
+	for i, res := range r.Results {
+		if res.Label == nil || res.Replayed == nil {
+			continue
+		}
+		expected, got := res.Label.Action, res.Replayed.RecommendedAction
+		s.Labelled++
+		s.Confusion[expected][got]++
+
+		switch {
+		case expected == ActionAbort && got == ActionAbort:
+			tp++
+		case got == ActionAbort:
+			fp++
+		case expected == ActionAbort:
+			fn++
+		}
+
+		if expected != ActionWait && res.Label.CulpritPod != "" && got != ActionWait {
+			s.CulpritLabelled++
+			if namesPod(*res.Replayed, res.Label.CulpritPod) {
+				s.CulpritHits++
+			}
+		}
+
+		sc := scenarios[res.Label.Scenario]
+		if expected != ActionWait && sc == nil {
+			sc = &tracker{firstBad: i, latency: len(s.Latencies)}
+			scenarios[res.Label.Scenario] = sc
+			s.Latencies = append(s.Latencies, ScenarioLatency{
+				Scenario:     res.Label.Scenario,
+				FirstBadTick: filepath.Base(res.File),
+			})
+		}
+		if sc == nil || got != ActionAbort || s.Latencies[sc.latency].FirstAbortTick != "" {
+			continue
+		}
+		l := &s.Latencies[sc.latency]
+		l.FirstAbortTick = filepath.Base(res.File)
+		ticks := 0
+		for _, between := range r.Results[sc.firstBad:i] {
+			if between.Replayed != nil && between.Label != nil && between.Label.Scenario == res.Label.Scenario {
+				ticks++
+			}
+		}
+		l.AbortLatencyTicks = &ticks
+		if from, to := r.Results[sc.firstBad].Timestamp, res.Timestamp; !from.IsZero() && !to.IsZero() {
+			d := to.Sub(from)
+			l.AbortLatency = &d
+		}
+	}
+	if tp+fp > 0 {
+		p := float64(tp) / float64(tp+fp)
+		s.AbortPrecision = &p
+	}
+	if tp+fn > 0 {
+		rc := float64(tp) / float64(tp+fn)
+		s.AbortRecall = &rc
+	}
+	return s
+}
+
+// namesPod reports whether a verdict points at pod, either as a pod=
+// evidence entry or by name in the diagnosis.
+func namesPod(v Verdict, pod string) bool {
+	for _, e := range v.Evidence {
+		if e == "pod="+pod || e == pod {
+			return true
+		}
+	}
+	return strings.Contains(v.Diagnosis, pod)
+}
+
+// Format renders the score as the text block appended to ReplayReport.Format.
+func (s Score) Format() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Labelled ticks: %d\n\n", s.Labelled)
+	b.WriteString("  expected \\ replayed")
+	for _, a := range scoredActions {
+		fmt.Fprintf(&b, "  %11s", a)
+	}
+	b.WriteString("\n")
+	for _, expected := range scoredActions {
+		fmt.Fprintf(&b, "  %-20s", expected)
+		for _, got := range scoredActions {
+			fmt.Fprintf(&b, "  %11d", s.Confusion[expected][got])
+		}
+		b.WriteString("\n")
+	}
+	fmt.Fprintf(&b, "\nAbort precision: %s   Abort recall: %s\n", formatRatio(s.AbortPrecision), formatRatio(s.AbortRecall))
+	if s.CulpritLabelled > 0 {
+		fmt.Fprintf(&b, "Culprit named: %d/%d\n", s.CulpritHits, s.CulpritLabelled)
+	}
+	if len(s.Latencies) == 0 {
+		b.WriteString("Abort latency: n/a (no bad tick labelled)\n")
+	}
+	for _, l := range s.Latencies {
+		b.WriteString("Abort latency")
+		if l.Scenario != "" {
+			fmt.Fprintf(&b, " [%s]", l.Scenario)
+		}
+		if l.AbortLatencyTicks == nil {
+			fmt.Fprintf(&b, ": never aborted (first bad tick %s)\n", l.FirstBadTick)
+			continue
+		}
+		latency := "unknown"
+		if l.AbortLatency != nil {
+			latency = l.AbortLatency.Round(time.Second).String()
+		}
+		fmt.Fprintf(&b, ": %s / %d tick(s) (first bad tick %s, first abort %s)\n",
+			latency, *l.AbortLatencyTicks, l.FirstBadTick, l.FirstAbortTick)
+	}
+	return b.String()
+}
+
+func formatRatio(v *float64) string {
+	if v == nil {
+		return "n/a"
+	}
+	return fmt.Sprintf("%.2f", *v)
+}
+
+// replayJSON is the machine-readable replay report.
+type replayJSON struct {
+	CorpusDir       string           `json:"corpus_dir"`
+	Total           int              `json:"total"`
+	Regressions     int              `json:"regressions"`
+	Mismatches      int              `json:"mismatches"`
+	Skipped         int              `json:"skipped"`
+	DurationSeconds float64          `json:"duration_seconds"`
+	Ticks           []replayTickJSON `json:"ticks"`
+	Score           *Score           `json:"score,omitempty"`
+}
+
+type replayTickJSON struct {
+	File            string     `json:"file"`
+	Timestamp       *time.Time `json:"timestamp,omitempty"`
+	Label           *TickLabel `json:"label,omitempty"`
+	Recorded        *Verdict   `json:"recorded,omitempty"`
+	Replayed        *Verdict   `json:"replayed,omitempty"`
+	Error           string     `json:"error,omitempty"`
+	ActionChanged   bool       `json:"action_changed"`
+	LabelMismatch   bool       `json:"label_mismatch"`
+	ConfidenceDelta float64    `json:"confidence_delta"`
+}
+
+// FormatJSON renders the report, including the score for labelled corpora,
+// as indented JSON.
+func (r ReplayReport) FormatJSON() ([]byte, error) {
+	out := replayJSON{
+		CorpusDir:       r.CorpusDir,
+		Total:           len(r.Results),
+		Regressions:     r.Regressions,
+		Mismatches:      r.Mismatches,
+		Skipped:         r.Skipped,
+		DurationSeconds: r.Duration.Seconds(),
+		Ticks:           make([]replayTickJSON, 0, len(r.Results)),
+	}
+	for _, res := range r.Results {
+		tick := replayTickJSON{
+			File:            filepath.Base(res.File),
+			Label:           res.Label,
+			Recorded:        res.Recorded,
+			Replayed:        res.Replayed,
+			Error:           res.Error,
+			ActionChanged:   res.ActionChanged,
+			LabelMismatch:   res.LabelMismatch(),
+			ConfidenceDelta: res.ConfidenceDelta,
+		}
+		if !res.Timestamp.IsZero() {
+			ts := res.Timestamp
+			tick.Timestamp = &ts
+		}
+		out.Ticks = append(out.Ticks, tick)
+	}
+	if score := r.Score(); score.Labelled > 0 {
+		out.Score = &score
+	}
+	return json.MarshalIndent(out, "", "  ")
+}
+
+// FormatJUnit renders the report as JUnit XML with one testcase per tick.
+// A labelled tick fails when the replayed action differs from its label; an
+// unlabelled one fails when the action changed from the recording. The
+// score is attached as suite properties.
+func (r ReplayReport) FormatJUnit() ([]byte, error) {
+	suite := junit.Suite{
+		Name: "agentwatch replay " + r.CorpusDir,
+		Time: junit.Seconds(r.Duration.Seconds()),
+	}
+	for _, res := range r.Results {
+		tc := junit.Case{Name: filepath.Base(res.File), Classname: "agentwatch.replay"}
+		switch {
+		case res.Error != "":
+			tc.Error = &junit.Failure{Message: res.Error}
+			suite.Errors++
+		case res.LabelMismatch():
+			tc.Failure = &junit.Failure{
+				Message: fmt.Sprintf("expected %s, got %s", res.Label.Action, res.Replayed.RecommendedAction),
+				Body:    res.Replayed.Diagnosis,
+			}
+			suite.Failures++
+		case res.Label == nil && res.ActionChanged:
+			tc.Failure = &junit.Failure{
+				Message: fmt.Sprintf("recorded %s, replayed %s", res.Recorded.RecommendedAction, res.Replayed.RecommendedAction),
+				Body:    res.Replayed.Diagnosis,
+			}
+			suite.Failures++
+		}
+		suite.Cases = append(suite.Cases, tc)
+	}
+	suite.Tests = len(suite.Cases)
+	if score := r.Score(); score.Labelled > 0 {
+		suite.Properties = []junit.Property{
+			{Name: "labelled", Value: fmt.Sprint(score.Labelled)},
+			{Name: "abort_precision", Value: formatRatio(score.AbortPrecision)},
+			{Name: "abort_recall", Value: formatRatio(score.AbortRecall)},
+		}
+		for _, l := range score.Latencies {
+			suffix := ""
+			if l.Scenario != "" {
+				suffix = "/" + l.Scenario
+			}
+			if l.AbortLatencyTicks != nil {
+				suite.Properties = append(suite.Properties,
+					junit.Property{Name: "abort_latency_ticks" + suffix, Value: fmt.Sprint(*l.AbortLatencyTicks)})
+			}
+			if l.AbortLatency != nil {
+				suite.Properties = append(suite.Properties,
+					junit.Property{Name: "abort_latency_seconds" + suffix, Value: fmt.Sprintf("%.0f", l.AbortLatency.Seconds())})
+			}
+		}
+	}
+	return junit.Marshal(junit.Suites{Suites: []junit.Suite{suite}})
+}
+
+// parseTickTimestamp accepts the compact form persistTick writes and
+// RFC3339 for hand-written fixtures.
+func parseTickTimestamp(s string) time.Time {
+	for _, layout := range []string{"20060102T150405.000Z", time.RFC3339Nano} {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
@@ -0,0 +1,213 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentwatch
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"scripts/deploy-camunda/pkg/junit"
+)
+
+func labelledResult(file string, at time.Time, expected, got Action, evidence ...string) ReplayResult {
+	return ReplayResult{
+		File:      "/corpus/" + file,
+		Timestamp: at,
+		Label:     &TickLabel{Action: expected, CulpritPod: "zeebe-0"},
+		Replayed:  &Verdict{Diagnosis: "d", RecommendedAction: got, Confidence: 0.9, Evidence: evidence},
+	}
+}
+
+func TestReplayReport_Score(t *testing.T) {
+	t0 := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	report := ReplayReport{Results: []ReplayResult{
+		labelledResult("t1.json", t0, ActionWait, ActionWait),
+		labelledResult("t2.json", t0.Add(1*time.Minute), ActionWait, ActionAbort),                 // false positive
+		labelledResult("t3.json", t0.Add(2*time.Minute), ActionAbort, ActionInvestigate, "pod=x"), // first bad tick, miss
+		labelledResult("t4.json", t0.Add(3*time.Minute), ActionAbort, ActionAbort, "pod=zeebe-0"), // first abort
+		labelledResult("t5.json", t0.Add(4*time.Minute), ActionAbort, ActionAbort, "pod=zeebe-0"),
+		{File: "/corpus/t6.json", Replayed: &Verdict{RecommendedAction: ActionAbort}}, // unlabelled: ignored
+	}}
+
+	s := report.Score()
+	if s.Labelled != 5 {
+		t.Fatalf("Labelled = %d, want 5", s.Labelled)
+	}
+	if got := s.Confusion[ActionAbort][ActionAbort]; got != 2 {
+		t.Fatalf("confusion[abort][abort] = %d, want 2", got)
+	}
+	if got := s.Confusion[ActionWait][ActionAbort]; got != 1 {
+		t.Fatalf("confusion[wait][abort] = %d, want 1", got)
+	}
+	if s.AbortPrecision == nil || *s.AbortPrecision != 2.0/3.0 {
+		t.Fatalf("AbortPrecision = %v, want 2/3", s.AbortPrecision)
+	}
+	if s.AbortRecall == nil || *s.AbortRecall != 2.0/3.0 {
+		t.Fatalf("AbortRecall = %v, want 2/3", s.AbortRecall)
+	}
+	if len(s.Latencies) != 1 {
+		t.Fatalf("Latencies = %+v, want one scenario", s.Latencies)
+	}
+	l := s.Latencies[0]
+	if l.FirstBadTick != "t3.json" || l.FirstAbortTick != "t4.json" {
+		t.Fatalf("first bad/abort = %s/%s, want t3.json/t4.json", l.FirstBadTick, l.FirstAbortTick)
+	}
+	if l.AbortLatency == nil || *l.AbortLatency != time.Minute {
+		t.Fatalf("AbortLatency = %v, want 1m", l.AbortLatency)
+	}
+	if l.AbortLatencyTicks == nil || *l.AbortLatencyTicks != 1 {
+		t.Fatalf("AbortLatencyTicks = %v, want 1", l.AbortLatencyTicks)
+	}
+	if s.CulpritLabelled != 3 || s.CulpritHits != 2 {
+		t.Fatalf("culprit = %d/%d, want 2/3", s.CulpritHits, s.CulpritLabelled)
+	}
+}
+
+func TestReplayReport_ScoreNeverAborted(t *testing.T) {
+	report := ReplayReport{Results: []ReplayResult{
+		labelledResult("t1.json", time.Time{}, ActionAbort, ActionWait),
+	}}
+	s := report.Score()
+	if s.AbortPrecision != nil {
+		t.Fatalf("AbortPrecision should be undefined without predicted aborts, got %v", *s.AbortPrecision)
+	}
+	if len(s.Latencies) != 1 || s.Latencies[0].AbortLatencyTicks != nil {
+		t.Fatalf("expected no abort latency when the engine never aborted, got %+v", s.Latencies)
+	}
+	if !strings.Contains(s.Format(), "never aborted") {
+		t.Fatalf("expected never-aborted note, got:\n%s", s.Format())
+	}
+}
+
+// TestReplayReport_ScoreKeysLatencyByScenario checks that an abort in one
+// scenario does not end the latency window of another, and that ticks of
+// other scenarios are not counted.
+func TestReplayReport_ScoreKeysLatencyByScenario(t *testing.T) {
+	t0 := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	in := func(scenario string, res ReplayResult) ReplayResult {
+		res.Label.Scenario = scenario
+		return res
+	}
+	report := ReplayReport{Results: []ReplayResult{
+		in("oom", labelledResult("a1.json", t0, ActionAbort, ActionWait)),
+		in("bad-tag", labelledResult("b1.json", t0.Add(time.Minute), ActionAbort, ActionAbort)),
+		in("bad-tag", labelledResult("b2.json", t0.Add(2*time.Minute), ActionAbort, ActionAbort)),
+		in("oom", labelledResult("a2.json", t0.Add(5*time.Minute), ActionAbort, ActionAbort)),
+	}}
+
+	s := report.Score()
+	if len(s.Latencies) != 2 {
+		t.Fatalf("Latencies = %+v, want two scenarios", s.Latencies)
+	}
+	oom, badTag := s.Latencies[0], s.Latencies[1]
+	if oom.Scenario != "oom" || oom.FirstAbortTick != "a2.json" || *oom.AbortLatency != 5*time.Minute || *oom.AbortLatencyTicks != 1 {
+		t.Errorf("oom latency = %+v (ticks %v)", oom, oom.AbortLatencyTicks)
+	}
+	if badTag.Scenario != "bad-tag" || badTag.FirstAbortTick != "b1.json" || *badTag.AbortLatency != 0 || *badTag.AbortLatencyTicks != 0 {
+		t.Errorf("bad-tag latency = %+v", badTag)
+	}
+	if !strings.Contains(s.Format(), "Abort latency [oom]: 5m0s / 1 tick(s)") {
+		t.Errorf("format lacks per-scenario latency:\n%s", s.Format())
+	}
+}
+
+func TestLoadLabels(t *testing.T) {
+	labels, err := LoadLabels(rulesCorpusDir)
+	if err != nil {
+		t.Fatalf("LoadLabels: %v", err)
+	}
+	if labels["02-image-tag-missing.json"].Action != ActionAbort {
+		t.Fatalf("unexpected labels: %+v", labels)
+	}
+
+	if labels, err := LoadLabels(t.TempDir()); err != nil || labels != nil {
+		t.Fatalf("missing labels file should yield nil, nil; got %v, %v", labels, err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, LabelsFile), []byte("a.json:\n  action: panic\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := LoadLabels(dir); err == nil {
+		t.Fatal("expected error for unknown label action")
+	}
+}
+
+func TestReplay_LabelledCorpusOutputs(t *testing.T) {
+	report, err := Replay(context.Background(), RulesSource{}, rulesCorpusDir)
+	if err != nil {
+		t.Fatalf("Replay: %v", err)
+	}
+	if report.Mismatches != 0 {
+		t.Fatalf("rules engine disagrees with %d corpus label(s):\n%s", report.Mismatches, report.Format())
+	}
+	s := report.Score()
+	if s.AbortRecall == nil || *s.AbortRecall != 1 {
+		t.Fatalf("expected perfect abort recall on the fixture corpus, got %v", s.AbortRecall)
+	}
+	if s.CulpritHits != s.CulpritLabelled {
+		t.Fatalf("culprit named %d/%d", s.CulpritHits, s.CulpritLabelled)
+	}
+	if !strings.Contains(report.Format(), "Abort precision: 1.00") {
+		t.Fatalf("expected score section in text output:\n%s", report.Format())
+	}
+
+	rawJSON, err := report.FormatJSON()
+	if err != nil {
+		t.Fatalf("FormatJSON: %v", err)
+	}
+	var decoded struct {
+		Score struct {
+			Confusion map[string]map[string]int `json:"confusion"`
+		} `json:"score"`
+	}
+	if err := json.Unmarshal(rawJSON, &decoded); err != nil {
+		t.Fatalf("decode JSON report: %v", err)
+	}
+	if decoded.Score.Confusion["abort"]["abort"] != 5 {
+		t.Fatalf("unexpected JSON confusion matrix: %v", decoded.Score.Confusion)
+	}
+
+	rawXML, err := report.FormatJUnit()
+	if err != nil {
+		t.Fatalf("FormatJUnit: %v", err)
+	}
+	var suites junit.Suites
+	if err := xml.Unmarshal(rawXML, &suites); err != nil {
+		t.Fatalf("decode JUnit report: %v", err)
+	}
+	if len(suites.Suites) != 1 || suites.Suites[0].Tests != len(report.Results) || suites.Suites[0].Failures != 0 {
+		t.Fatalf("unexpected JUnit suite: %+v", suites.Suites)
+	}
+}
+
+func TestReplayReport_FormatJUnitFailsOnLabelMismatch(t *testing.T) {
+	report := ReplayReport{Results: []ReplayResult{
+		labelledResult("t1.json", time.Time{}, ActionAbort, ActionWait),
+	}}
+	out, err := report.FormatJUnit()
+	if err != nil {
+		t.Fatalf("FormatJUnit: %v", err)
+	}
+	if !strings.Contains(string(out), `<failure message="expected abort, got wait">`) {
+		t.Fatalf("expected failure element, got:\n%s", out)
+	}
+}
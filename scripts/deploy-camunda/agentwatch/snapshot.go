@@ -1,18 +1,19 @@
 package agentwatch
 
 import (
-	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
-	"os/exec"
+	"scripts/camunda-core/pkg/kube"
 	"time"
+
+	corev1 "k8s.io/api/core/v1"
 )
 
 // Snapshot bundles the cluster state we hand to the agent on each tick.
-// Fields are raw JSON so the agent sees exactly what `kubectl` and
-// `helm` produced, with no intermediate transformation that might hide a
-// signal.
+// Fields are raw JSON in the same shape `kubectl get -o json` and
+// `helm status -o json` produce, with no intermediate transformation that
+// might hide a signal.
 type Snapshot struct {
 	Timestamp     time.Time       `json:"timestamp"`
 	Namespace     string          `json:"namespace"`
@@ -24,55 +25,96 @@ type Snapshot struct {
 	HelmStatusErr string          `json:"helm_status_error,omitempty"`
 }
 
+// ClusterReader is the read-only cluster surface GatherSnapshot needs.
+// *kube.Client satisfies it through client-go, so the watcher runs from a
+// bare binary without kubectl or helm on PATH; tests back it with a
+// fake clientset.
+type ClusterReader interface {
+	ListPods(ctx context.Context, namespace string) (*corev1.PodList, error)
+	ListEvents(ctx context.Context, namespace string) (*corev1.EventList, error)
+	ListPVCs(ctx context.Context, namespace string) (*corev1.PersistentVolumeClaimList, error)
+	HelmReleaseStatus(ctx context.Context, namespace, release string) (*kube.HelmRelease, error)
+}
+
 // SnapshotOptions configures a snapshot collection.
 type SnapshotOptions struct {
 	Namespace   string
 	Release     string
 	KubeContext string
-	// SkipHelmStatus omits `helm status` from the snapshot. Useful in tests
-	// or when Helm credentials are not available locally.
+	// Reader serves the cluster reads. When nil, GatherSnapshot builds a
+	// kube.Client for KubeContext on every call; Watch builds one up
+	// front and reuses it across ticks.
+	Reader ClusterReader
+	// SkipHelmStatus omits the Helm release status from the snapshot.
+	// Useful in tests or when the Helm storage secrets are not readable
+	// with the current credentials.
 	SkipHelmStatus bool
 }
 
-// kubectlPerCallTimeout caps a single kubectl/helm invocation. Without a
-// per-call cap, an unreachable apiserver or hung admission webhook blocks
-// an entire poll tick on one command — destroying the diagnose-while-
-// running property the watcher exists to provide.
-const kubectlPerCallTimeout = 30 * time.Second
+// perCallTimeout caps a single apiserver read. Without a per-call cap, an
+// unreachable apiserver or hung admission webhook blocks an entire poll
+// tick on one request — destroying the diagnose-while-running property
+// the watcher exists to provide.
+const perCallTimeout = 30 * time.Second
+
+// NewClusterReader returns the default client-go backed ClusterReader for
+// kubeContext (empty means the current context).
+func NewClusterReader(kubeContext string) (ClusterReader, error) {
+	return kube.NewClient("", kubeContext)
+}
 
-// GatherSnapshot runs `kubectl get pods,events,pvcs` and `helm status` and
-// returns the combined snapshot. A non-fatal helm error is recorded in
-// Snapshot.HelmStatusErr rather than failing the whole collection — the
-// agent can still reason about pod/event state without helm metadata.
+// GatherSnapshot lists pods, events and PVCs and reads the Helm release
+// status, and returns the combined snapshot. A non-fatal helm error is
+// recorded in Snapshot.HelmStatusErr rather than failing the whole
+// collection — the agent can still reason about pod/event state without
+// helm metadata.
 func GatherSnapshot(ctx context.Context, opts SnapshotOptions) (Snapshot, error) {
 	if opts.Namespace == "" {
 		return Snapshot{}, fmt.Errorf("namespace is required")
 	}
 
+	reader := opts.Reader
+	if reader == nil {
+		r, err := NewClusterReader(opts.KubeContext)
+		if err != nil {
+			return Snapshot{}, fmt.Errorf("build cluster client: %w", err)
+		}
+		reader = r
+	}
+
 	snap := Snapshot{
 		Timestamp: time.Now().UTC(),
 		Namespace: opts.Namespace,
 		Release:   opts.Release,
 	}
 
-	pods, err := runKubectlJSON(ctx, opts.KubeContext, opts.Namespace, "pods")
+	pods, err := readJSON(ctx, func(ctx context.Context) (any, error) {
+		return reader.ListPods(ctx, opts.Namespace)
+	})
 	if err != nil {
-		return Snapshot{}, fmt.Errorf("kubectl get pods: %w", err)
+		return Snapshot{}, fmt.Errorf("list pods: %w", err)
 	}
 	snap.Pods = pods
 
-	events, err := runKubectlJSON(ctx, opts.KubeContext, opts.Namespace, "events")
+	events, err := readJSON(ctx, func(ctx context.Context) (any, error) {
+		return reader.ListEvents(ctx, opts.Namespace)
+	})
 	if err != nil {
-		return Snapshot{}, fmt.Errorf("kubectl get events: %w", err)
+		return Snapshot{}, fmt.Errorf("list events: %w", err)
 	}
 	snap.Events = events
 
-	if pvcs, err := runKubectlJSON(ctx, opts.KubeContext, opts.Namespace, "pvc"); err == nil {
+	if pvcs, err := readJSON(ctx, func(ctx context.Context) (any, error) {
+		return reader.ListPVCs(ctx, opts.Namespace)
+	}); err == nil {
 		snap.PVCs = pvcs
 	}
 
 	if !opts.SkipHelmStatus && opts.Release != "" {
-		if status, err := runHelmStatus(ctx, opts.KubeContext, opts.Namespace, opts.Release); err == nil {
+		status, err := readJSON(ctx, func(ctx context.Context) (any, error) {
+			return reader.HelmReleaseStatus(ctx, opts.Namespace, opts.Release)
+		})
+		if err == nil {
 			snap.HelmStatus = status
 		} else {
 			snap.HelmStatusErr = err.Error()
@@ -82,54 +124,21 @@ func GatherSnapshot(ctx context.Context, opts SnapshotOptions) (Snapshot, error)
 	return snap, nil
 }
 
-// runKubectlJSON shells out to `kubectl get <resource> -n <ns> -o json` and
-// returns the raw JSON bytes.
-func runKubectlJSON(ctx context.Context, kubeContext, namespace, resource string) (json.RawMessage, error) {
-	args := []string{"get", resource, "-n", namespace, "-o", "json"}
-	if kubeContext != "" {
-		args = append([]string{"--context", kubeContext}, args...)
-	}
-	out, err := runCmd(ctx, "kubectl", args...)
+// readJSON runs one reader call under its own perCallTimeout-bound child
+// context and marshals the result, so a single hung request cannot block
+// the watch loop indefinitely.
+func readJSON(ctx context.Context, read func(context.Context) (any, error)) (json.RawMessage, error) {
+	callCtx, cancel := context.WithTimeout(ctx, perCallTimeout)
+	defer cancel()
+	obj, err := read(callCtx)
 	if err != nil {
 		return nil, err
 	}
-	// json.RawMessage requires valid JSON; sanity-check the prefix.
-	trimmed := bytes.TrimSpace(out)
-	if len(trimmed) == 0 || trimmed[0] != '{' {
-		return nil, fmt.Errorf("kubectl get %s returned non-JSON output", resource)
-	}
-	return json.RawMessage(trimmed), nil
-}
-
-// runHelmStatus runs `helm status <release> -n <ns> -o json`.
-func runHelmStatus(ctx context.Context, kubeContext, namespace, release string) (json.RawMessage, error) {
-	args := []string{"status", release, "-n", namespace, "-o", "json"}
-	if kubeContext != "" {
-		args = append(args, "--kube-context", kubeContext)
-	}
-	out, err := runCmd(ctx, "helm", args...)
+	out, err := json.Marshal(obj)
 	if err != nil {
 		return nil, err
 	}
-	return json.RawMessage(bytes.TrimSpace(out)), nil
-}
-
-// runCmd executes a command and returns its stdout. Stderr is folded into
-// the error so the caller can surface it without a separate field. Each
-// call gets its own timeout-bound child context so a single hung
-// invocation cannot block the watch loop indefinitely.
-func runCmd(ctx context.Context, name string, args ...string) ([]byte, error) {
-	callCtx, cancel := context.WithTimeout(ctx, kubectlPerCallTimeout)
-	defer cancel()
-	cmd := exec.CommandContext(callCtx, name, args...)
-	var stdout, stderr bytes.Buffer
-	cmd.Stdout = &stdout
-	cmd.Stderr = &stderr
-	if err := cmd.Run(); err != nil {
-		return stdout.Bytes(), fmt.Errorf("%s %v failed: %w; stderr: %s",
-			name, args, err, truncate(stderr.String(), 1000))
-	}
-	return stdout.Bytes(), nil
+	return json.RawMessage(out), nil
 }
 
 // MarshalJSON ensures Snapshot serializes deterministically for the agent
@@ -201,7 +210,7 @@ func (s Snapshot) InstallComplete() bool {
 	return s.HelmReleaseDeployed()
 }
 
-// HelmReleaseDeployed reports whether `helm status -o json` says the watched
+// HelmReleaseDeployed reports whether the Helm release status says the watched
 // release is deployed. Missing/erroring Helm status is treated as incomplete so
 // the watch loop keeps collecting snapshots and can see later failing pods.
 func (s Snapshot) HelmReleaseDeployed() bool {
@@ -0,0 +1,209 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package agentwatch
+
+import (
+	"context"
+	"encoding/base64"
+	"scripts/camunda-core/pkg/kube"
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	testNamespace = "watch-test"
+	testRelease   = "integration"
+)
+
+func fakeReader(objects ...runtime.Object) ClusterReader {
+	return kube.NewClientForClientset(fake.NewSimpleClientset(objects...), "fake")
+}
+
+func readyPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+		},
+	}
+}
+
+func podInPhase(name string, phase corev1.PodPhase) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Status:     corev1.PodStatus{Phase: phase},
+	}
+}
+
+// releaseSecret builds a Helm secrets-driver record (uncompressed; the
+// decoder accepts both) for the given revision and status.
+func releaseSecret(revision, status string) *corev1.Secret {
+	payload := `{"name":"` + testRelease + `","version":` + revision + `,"info":{"status":"` + status + `"}}`
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sh.helm.release.v1." + testRelease + ".v" + revision,
+			Namespace: testNamespace,
+			Labels:    map[string]string{"owner": "helm", "name": testRelease, "version": revision},
+		},
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString([]byte(payload)))},
+	}
+}
+
+func gather(t *testing.T, objects ...runtime.Object) Snapshot {
+	t.Helper()
+	snap, err := GatherSnapshot(context.Background(), SnapshotOptions{
+		Namespace: testNamespace,
+		Release:   testRelease,
+		Reader:    fakeReader(objects...),
+	})
+	if err != nil {
+		t.Fatalf("GatherSnapshot: %v", err)
+	}
+	return snap
+}
+
+func TestGatherSnapshot_AllPodsReady(t *testing.T) {
+	cases := []struct {
+		name    string
+		objects []runtime.Object
+		want    bool
+	}{
+		{name: "empty namespace", want: false},
+		{name: "single running ready", objects: []runtime.Object{readyPod("zeebe-0")}, want: true},
+		{
+			name:    "pending pod blocks readiness",
+			objects: []runtime.Object{readyPod("zeebe-0"), podInPhase("operate-0", corev1.PodPending)},
+			want:    false,
+		},
+		{
+			name:    "succeeded job pod counts as ready",
+			objects: []runtime.Object{readyPod("zeebe-0"), podInPhase("migration-job-x", corev1.PodSucceeded)},
+			want:    true,
+		},
+		{
+			name:    "running without Ready condition",
+			objects: []runtime.Object{podInPhase("zeebe-0", corev1.PodRunning)},
+			want:    false,
+		},
+		{
+			name: "pods in other namespaces are ignored",
+			objects: []runtime.Object{readyPod("zeebe-0"), &corev1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "broken", Namespace: "elsewhere"},
+				Status:     corev1.PodStatus{Phase: corev1.PodFailed},
+			}},
+			want: true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := gather(t, tc.objects...).AllPodsReady(); got != tc.want {
+				t.Fatalf("AllPodsReady = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGatherSnapshot_HelmReleaseDeployed(t *testing.T) {
+	cases := []struct {
+		name       string
+		objects    []runtime.Object
+		want       bool
+		wantErrMsg string
+	}{
+		{name: "no release secret", want: false, wantErrMsg: "not found"},
+		{name: "pending install", objects: []runtime.Object{releaseSecret("1", "pending-install")}, want: false},
+		{name: "deployed", objects: []runtime.Object{releaseSecret("1", "deployed")}, want: true},
+		{
+			name:    "latest revision wins",
+			objects: []runtime.Object{releaseSecret("1", "deployed"), releaseSecret("2", "pending-upgrade")},
+			want:    false,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			snap := gather(t, tc.objects...)
+			if got := snap.HelmReleaseDeployed(); got != tc.want {
+				t.Fatalf("HelmReleaseDeployed = %v, want %v", got, tc.want)
+			}
+			if tc.wantErrMsg != "" && !strings.Contains(snap.HelmStatusErr, tc.wantErrMsg) {
+				t.Fatalf("HelmStatusErr = %q, want it to contain %q", snap.HelmStatusErr, tc.wantErrMsg)
+			}
+		})
+	}
+}
+
+func TestGatherSnapshot_InstallComplete(t *testing.T) {
+	cases := []struct {
+		name    string
+		objects []runtime.Object
+		want    bool
+	}{
+		{
+			name:    "ready pods but release still pending",
+			objects: []runtime.Object{readyPod("zeebe-0"), releaseSecret("1", "pending-install")},
+			want:    false,
+		},
+		{
+			name:    "deployed release with a pending pod",
+			objects: []runtime.Object{readyPod("zeebe-0"), podInPhase("operate-0", corev1.PodPending), releaseSecret("1", "deployed")},
+			want:    false,
+		},
+		{
+			name:    "deployed release and all pods ready",
+			objects: []runtime.Object{readyPod("zeebe-0"), releaseSecret("1", "deployed")},
+			want:    true,
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := gather(t, tc.objects...).InstallComplete(); got != tc.want {
+				t.Fatalf("InstallComplete = %v, want %v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestGatherSnapshot_CollectsEventsAndPVCs(t *testing.T) {
+	snap := gather(t,
+		&corev1.Event{
+			ObjectMeta: metav1.ObjectMeta{Name: "zeebe-0.1", Namespace: testNamespace},
+			Reason:     "FailedScheduling",
+			Message:    "0/3 nodes are available: 3 Insufficient cpu.",
+		},
+		&corev1.PersistentVolumeClaim{ObjectMeta: metav1.ObjectMeta{Name: "data-zeebe-0", Namespace: testNamespace}},
+	)
+	if !strings.Contains(string(snap.Events), "FailedScheduling") {
+		t.Fatalf("expected event reason in snapshot, got %s", snap.Events)
+	}
+	if !strings.Contains(string(snap.PVCs), "data-zeebe-0") {
+		t.Fatalf("expected PVC in snapshot, got %s", snap.PVCs)
+	}
+}
+
+func TestGatherSnapshot_ReaderErrorFailsCollection(t *testing.T) {
+	_, err := GatherSnapshot(context.Background(), SnapshotOptions{
+		Namespace: testNamespace,
+		Reader:    failingReader{},
+	})
+	if err == nil || !strings.Contains(err.Error(), "list pods") {
+		t.Fatalf("expected list pods error, got %v", err)
+	}
+}
@@ -23,7 +23,7 @@ func newEnvCommand() *cobra.Command {
 		Use:   "env",
 		Short: "Show the effective deploy environment and where each value came from",
 		Long: `Print the environment variables a deploy would resolve, layered as
-process-env → .env file → per-entry overrides, annotated with the winning
+process-env → secret sources → .env file → per-entry overrides, annotated with the winning
 source per key. Secret-looking values (key/secret/password/token) are masked
 unless --unmask is passed.`,
 		Args: cobra.NoArgs,
@@ -33,6 +33,9 @@ unless --unmask is passed.`,
 				return err
 			}
 
+			if err := deploy.LoadSecretSources(cmd.Context(), &flags); err != nil {
+				return err
+			}
 			entries := deploy.EnvProvenance(&flags)
 			out := cmd.OutOrStdout()
 			w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
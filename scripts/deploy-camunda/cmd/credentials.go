@@ -120,6 +120,26 @@ func resolveRegistryCredentials(docker *config.DockerFlags) error {
 	return nil
 }
 
+// resolveRegistryCredentialsWithSources is resolveRegistryCredentials with the
+// values loaded from the profile's secretSources consulted between the
+// environment and the OS keyring, matching their place in the deploy env.
+func resolveRegistryCredentialsWithSources(docker *config.DockerFlags, sourceEnv map[string]string) error {
+	if len(sourceEnv) > 0 {
+		if err := resolveRegistryCredentialsFromEnvironment(docker); err != nil {
+			return err
+		}
+		for _, item := range registryCredentialSources(docker) {
+			if !item.required || *item.username != "" {
+				continue
+			}
+			if err := mergeCredentialPair(item.registry, sourceEnv, item.username, item.password, item.envPairs); err != nil {
+				return fmt.Errorf("secret sources: %w", err)
+			}
+		}
+	}
+	return resolveRegistryCredentials(docker)
+}
+
 func resolveRegistryCredentialsFromEnvironment(docker *config.DockerFlags) error {
 	for _, item := range registryCredentialSources(docker) {
 		if !item.required {
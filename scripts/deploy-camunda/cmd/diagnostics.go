@@ -13,6 +13,7 @@ import (
 
 	"scripts/camunda-core/pkg/kube"
 	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/diagbundle"
 
 	"github.com/spf13/cobra"
 )
@@ -71,6 +72,43 @@ func newDiagnosticsCommand() *cobra.Command {
 		Short: "Namespace diagnostics helpers",
 	}
 	cmd.AddCommand(newDiagnosticsPrintCommand())
+	cmd.AddCommand(newDiagnosticsViewCommand())
+	return cmd
+}
+
+// newDiagnosticsViewCommand creates `diagnostics view`: a local viewer for the
+// bundle.tar.gz the matrix runner writes next to a failed entry's diagnostics.
+func newDiagnosticsViewCommand() *cobra.Command {
+	var addr string
+
+	cmd := &cobra.Command{
+		Use:   "view <bundle.tar.gz>",
+		Short: "Serve a local HTML viewer for a diagnostics bundle",
+		Long: `Serve a self-contained HTML viewer for a diagnostics bundle.
+
+The matrix runner writes diagnostics/<namespace>/<timestamp>/bundle.tar.gz for
+every failed entry, and CI uploads it with the diagnostics-* artifact. The
+viewer shows the pod status grid, the event timeline, the helm releases, the
+values chain and the environment the entry was deployed with, and links every
+log and resource in the bundle. It needs no cluster and no network access.`,
+		Args:          cobra.ExactArgs(1),
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			bundle, err := diagbundle.Open(args[0])
+			if err != nil {
+				return fmt.Errorf("open %s: %w", args[0], err)
+			}
+			return diagbundle.Serve(ctx, addr, bundle, func(url string) {
+				fmt.Fprintf(cmd.OutOrStdout(), "Serving %s at %s (Ctrl+C to stop)\n", args[0], url)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&addr, "addr", "127.0.0.1:0", "Address to serve the viewer on (port 0 picks a free port)")
 	return cmd
 }
 
@@ -70,7 +70,10 @@ Exits non-zero if any required check fails.`,
 			if err := env.Load(envFileToLoad); err != nil {
 				logging.Logger.Warn().Err(err).Str("envFile", envFileToLoad).Msg("Failed to load environment file")
 			}
-			if err := resolveRegistryCredentials(&flags.Docker); err != nil {
+			// A failing secret source is reported by the preflight's "secret
+			// sources" check rather than aborting the checklist here.
+			_ = deploy.LoadSecretSources(ctx, &flags)
+			if err := resolveRegistryCredentialsWithSources(&flags.Docker, flags.Secrets.SourceEnv); err != nil {
 				return err
 			}
 
@@ -119,7 +119,7 @@ func rootFlagGroups() map[string][]string {
 		grpInfrastructure: {
 			"namespace", "namespace-prefix", "platform", "repo-root",
 			"kube-context", "ingress-subdomain", "ingress-base-domain",
-			"ingress-hostname",
+			"ingress-hostname", "local-cluster", "local-cluster-name",
 		},
 		grpAuth: {
 			"auth", "keycloak-host", "keycloak-protocol", "keycloak-realm",
@@ -137,7 +137,7 @@ func rootFlagGroups() map[string][]string {
 		grpDeployment: {
 			"release", "flow", "ttl", "skip-preflight",
 			"skip-dependency-update", "delete-namespace", "render-templates",
-			"render-output-dir", "timeout", "test-e2e", "test-all",
+			"render-output-dir", "timeout", "test-e2e", "test-all", "smoke",
 			"upgrade-flow",
 		},
 		grpLogging: {
@@ -155,7 +155,7 @@ func matrixRunFlagGroups() map[string][]string {
 			"platform", "repo-root", "namespace-prefix",
 			"kube-context", "kube-context-gke", "kube-context-eks",
 			"ingress-base-domain", "ingress-base-domain-gke", "ingress-base-domain-eks",
-			"namespace-override",
+			"namespace-override", "local-cluster", "local-cluster-name",
 		},
 		grpAuth: {
 			"use-vault-backed-secrets", "use-vault-backed-secrets-gke", "use-vault-backed-secrets-eks",
@@ -178,7 +178,7 @@ func matrixRunFlagGroups() map[string][]string {
 			"max-parallel", "skip-dependency-update", "timeout",
 		},
 		grpLogging: {
-			"log-level", "log-dir",
+			"log-level", "log-dir", "report", "report-dir",
 		},
 	}
 }
@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/config"
+	"scripts/deploy-camunda/localcluster"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// ensureLocalCluster creates or reuses the kind/k3d cluster for
+// --platform local and returns its kube context.
+func ensureLocalCluster(ctx context.Context, provider, name string) (string, error) {
+	p, err := localcluster.ParseProvider(provider)
+	if err != nil {
+		return "", fmt.Errorf("--local-cluster: %w", err)
+	}
+	cluster, err := localcluster.Ensure(ctx, localcluster.Options{Provider: p, Name: name})
+	if err != nil {
+		return "", err
+	}
+	logging.Logger.Info().
+		Str("provider", string(cluster.Provider)).
+		Str("cluster", cluster.Name).
+		Str("kubeContext", cluster.KubeContext).
+		Bool("created", cluster.Created).
+		Msg("Local cluster ready")
+	return cluster.KubeContext, nil
+}
+
+// addLocalClusterFlags registers --local-cluster and --local-cluster-name,
+// shared by the root deploy command, `matrix run` and `local`.
+func addLocalClusterFlags(cmd *cobra.Command, provider, name *string) {
+	cmd.Flags().StringVar(provider, "local-cluster", "", "Local cluster provider for --platform local: kind or k3d (defaults to whichever is on PATH)")
+	cmd.Flags().StringVar(name, "local-cluster-name", localcluster.DefaultClusterName, "Name of the kind/k3d cluster to create or reuse for --platform local")
+	_ = cmd.RegisterFlagCompletionFunc("local-cluster", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		var out []string
+		for _, p := range localcluster.Providers {
+			out = append(out, string(p))
+		}
+		return filterByPrefix(out, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// newLocalCommand creates the "local" command group for managing the
+// kind/k3d cluster used by --platform local outside of a deploy.
+func newLocalCommand() *cobra.Command {
+	var provider, name, logLevel string
+
+	cmd := &cobra.Command{
+		Use:   "local",
+		Short: "Manage the kind/k3d cluster used by --platform local",
+		Long: `Manage the kind or k3d cluster that '--platform local' deploys into.
+
+Deploys with --platform local create or reuse the cluster on their own; use
+these commands to set it up ahead of time or to tear it down afterwards.
+The cluster forwards host ports 80/443 to ingress-nginx, and deploys are
+reachable at https://<namespace>.` + config.LocalIngressBaseDomain + ` (self-signed certificate).`,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			return logging.Setup(logging.Options{
+				LevelString:  logLevel,
+				ColorEnabled: logging.IsTerminal(os.Stdout.Fd()),
+			})
+		},
+	}
+	cmd.PersistentFlags().StringVarP(&logLevel, "log-level", "l", "info", "Log level")
+
+	up := &cobra.Command{
+		Use:   "up",
+		Short: "Create (or reuse) the local cluster and install ingress-nginx",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			kubeContext, err := ensureLocalCluster(ctx, provider, name)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stdout, "Local cluster ready: kube context %s\n", kubeContext)
+			return nil
+		},
+	}
+	addLocalClusterFlags(up, &provider, &name)
+
+	down := &cobra.Command{
+		Use:   "down",
+		Short: "Delete the local cluster",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			p, err := localcluster.ParseProvider(provider)
+			if err != nil {
+				return fmt.Errorf("--local-cluster: %w", err)
+			}
+			return localcluster.Delete(ctx, localcluster.Options{Provider: p, Name: name})
+		},
+	}
+	addLocalClusterFlags(down, &provider, &name)
+
+	cmd.AddCommand(up, down)
+	return cmd
+}
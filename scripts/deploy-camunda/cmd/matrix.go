@@ -32,6 +32,7 @@ func newMatrixCommand() *cobra.Command {
 
 	matrixCmd.AddCommand(newMatrixListCommand())
 	matrixCmd.AddCommand(newMatrixRunCommand())
+	matrixCmd.AddCommand(newMatrixResumeCommand())
 	matrixCmd.AddCommand(newMatrixPlanCommand())
 
 	return matrixCmd
@@ -48,6 +49,7 @@ func newMatrixPlanCommand() *cobra.Command {
 		manualTrigger    string
 		manualScenario   string
 		manualFlow       string
+		manualShortnames string
 		tier             string
 		repoRoot         string
 	)
@@ -85,12 +87,13 @@ version, and chart-only changes build just the affected versions.`,
 			}
 
 			result, err := matrix.Plan(repoRoot, matrix.PlanOptions{
-				ActiveVersions: strings.Fields(strings.ReplaceAll(activeVersions, ",", " ")),
-				ChangedFiles:   allModifiedFiles,
-				ManualTrigger:  manualTrigger,
-				ManualScenario: manualScenario,
-				ManualFlow:     manualFlow,
-				Tier:           tierValue,
+				ActiveVersions:   strings.Fields(strings.ReplaceAll(activeVersions, ",", " ")),
+				ChangedFiles:     allModifiedFiles,
+				ManualTrigger:    manualTrigger,
+				ManualScenario:   manualScenario,
+				ManualFlow:       manualFlow,
+				ManualShortnames: manualShortnames,
+				Tier:             tierValue,
 			})
 			if err != nil {
 				return err
@@ -125,6 +128,7 @@ version, and chart-only changes build just the affected versions.`,
 	f.StringVar(&manualTrigger, "manual-trigger", "none", "\"none\", \"all\", or a single chart version to build")
 	f.StringVar(&manualScenario, "manual-scenario", "none", "keep only this exact scenario (\"none\"/\"all\" keep everything)")
 	f.StringVar(&manualFlow, "manual-flow", "none", "override flows (comma-separated: install, upgrade-patch, upgrade-minor)")
+	f.StringVar(&manualShortnames, "manual-shortnames", "none", "keep only these exact scenario shortnames (comma-separated; \"none\" keeps everything)")
 	f.StringVar(&tier, "tier", "", "filter scenarios by tier (1=PR CI, 2=merge-queue only; empty or 0=all)")
 	f.StringVar(&repoRoot, "repo-root", "", "repository root path (default: auto-detect)")
 	_ = cmd.MarkFlagRequired("active-versions")
@@ -246,6 +250,7 @@ func newMatrixRunCommand() *cobra.Command {
 		coverage                 bool
 		testE2E                  bool
 		testAll                  bool
+		smoke                    bool
 		stopOnFailure            bool
 		namespacePrefix          string
 		cleanup                  bool
@@ -267,9 +272,13 @@ func newMatrixRunCommand() *cobra.Command {
 		useVaultBackedSecrets    bool
 		useVaultBackedSecretsGKE bool
 		useVaultBackedSecretsEKS bool
+		secretSources            []config.SecretSourceConfig
 		keycloakHost             string
 		keycloakProtocol         string
 		upgradeFromVersion       string
+		upgradePathFrom          string
+		upgradePathTo            string
+		testEachHop              bool
 		helmTimeout              int
 		dockerUsername           string
 		dockerPassword           string
@@ -292,6 +301,10 @@ func newMatrixRunCommand() *cobra.Command {
 		chartRefVersion          string
 		waitIngressReady         bool
 		ingressReadyTimeout      int
+		report                   string
+		reportDir                string
+		localCluster             string
+		localClusterName         string
 	)
 
 	cmd := &cobra.Command{
@@ -348,7 +361,10 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 
   # Config-file driven (recommended for repeat use):
   deploy-camunda config init --from-example getting-started
-  deploy-camunda matrix run --versions 8.10 --shortname-filter keyco`,
+  deploy-camunda matrix run --versions 8.10 --shortname-filter keyco
+
+  # Chain upgrades 8.6 → 8.7 → 8.8 → 8.9 → 8.10 for one scenario:
+  deploy-camunda matrix run --from 8.6 --to 8.10 --shortname-filter keyco --dry-run`,
 		PreRunE: func(cmd *cobra.Command, args []string) error {
 			return validateChartRefFlags(chartRef, chartRefVersion)
 		},
@@ -431,6 +447,7 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 					// Tests
 					TestE2E: &testE2E,
 					TestAll: &testAll,
+					Smoke:   &smoke,
 					// Kube contexts
 					KubeContext:    &kubeContext,
 					KubeContextGKE: &kubeContextGKE,
@@ -446,6 +463,7 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 					UseVaultBackedSecretsGKE: &useVaultBackedSecretsGKE,
 					UseVaultBackedSecretsEKS: &useVaultBackedSecretsEKS,
 					VaultBackedSecrets:       vaultBackedSecrets,
+					SecretSources:            &secretSources,
 					// Env files
 					EnvFile:   &envFile,
 					EnvFile86: &envFile86,
@@ -499,6 +517,32 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 				return fmt.Errorf("--repo-root is required (or set repoRoot in config, or run from within the repo)")
 			}
 
+			reportFormats, err := matrix.ParseReportFormats(report)
+			if err != nil {
+				return fmt.Errorf("--report: %w", err)
+			}
+
+			// --from/--to switch the run to the upgrade-path flow: the --to
+			// version's install scenarios are each installed at --from and
+			// upgraded one minor at a time.
+			var upgradePath *matrix.UpgradePath
+			if upgradePathFrom != "" || upgradePathTo != "" {
+				if upgradePathFrom == "" || upgradePathTo == "" {
+					return fmt.Errorf("--from and --to must be set together")
+				}
+				if len(versions) > 0 && (len(versions) != 1 || versions[0] != upgradePathTo) {
+					return fmt.Errorf("--versions %v conflicts with --to %s: an upgrade path runs the --to version's scenarios", versions, upgradePathTo)
+				}
+				versions = []string{upgradePathTo}
+				upgradePath, err = matrix.PlanUpgradePath(repoRoot, upgradePathFrom, upgradePathTo)
+				if err != nil {
+					return err
+				}
+				fmt.Fprintln(os.Stdout, upgradePath.String())
+			} else if testEachHop {
+				return fmt.Errorf("--test-each-hop requires --from and --to")
+			}
+
 			// Validate ingress base domains early so the user gets immediate feedback.
 			if ingressBaseDomain != "" {
 				if !config.IsValidIngressBaseDomain(ingressBaseDomain) {
@@ -524,6 +568,10 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 				return err
 			}
 
+			if upgradePath != nil {
+				entries = matrix.UpgradePathEntries(entries)
+			}
+
 			entries = matrix.Filter(entries, matrix.FilterOptions{
 				ScenarioFilter:  scenarioFilter,
 				ShortnameFilter: shortnameFilter,
@@ -589,6 +637,7 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 						MaxParallel:           maxParallel,
 						TestE2E:               testE2E,
 						TestAll:               testAll,
+						Smoke:                 smoke,
 						RepoRoot:              repoRoot,
 						EnvFiles:              envFiles,
 						EnvFile:               envFile,
@@ -598,9 +647,12 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 						SkipDependencyUpdate:  skipDependencyUpdate,
 						VaultBackedSecrets:    vaultBackedSecrets,
 						UseVaultBackedSecrets: useVaultBackedSecrets,
+						SecretSources:         secretSources,
 						KeycloakHost:          keycloakHost,
 						KeycloakProtocol:      keycloakProtocol,
 						UpgradeFromVersion:    upgradeFromVersion,
+						UpgradePath:           upgradePath,
+						TestEachHop:           testEachHop,
 						HelmTimeout:           helmTimeout,
 						DockerUsername:        dockerUsername,
 						DockerPassword:        dockerPassword,
@@ -670,6 +722,18 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 				}
 			}
 
+			// --platform local runs every entry in one kind/k3d cluster,
+			// created on first use.
+			if platform == config.PlatformLocal && !dryRun && !coverage && len(allEntries) > 0 {
+				localCtx, err := ensureLocalCluster(ctx, localCluster, localClusterName)
+				if err != nil {
+					return err
+				}
+				if kubeContext == "" && kubeContexts[config.PlatformLocal] == "" {
+					kubeContexts[config.PlatformLocal] = localCtx
+				}
+			}
+
 			// Show what will be run (only for non-dry-run/non-coverage; those modes print their own detailed output)
 			if !dryRun && !coverage {
 				output, _ := matrix.Print(entries, "table")
@@ -681,6 +745,7 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 			// --log-dir is not explicitly set, so each run gets its own logs.
 			var statusDisplay *matrix.StatusDisplay
 			var logFile io.Closer
+			var journal *matrix.Journal
 			stdoutIsTerminal := logging.IsTerminal(os.Stdout.Fd())
 			// When --log-dir is explicitly set, append a timestamp subdirectory
 			// so successive runs don't clobber each other's logs.
@@ -716,10 +781,18 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 				}
 
 				statusDisplay = matrix.NewStatusDisplay(os.Stdout, entries, stdoutIsTerminal, logDir)
+
+				// The journal is what `matrix resume` reads to pick up an
+				// interrupted run; failing to open it only costs resumability.
+				if j, err := matrix.OpenJournal(logDir); err != nil {
+					logging.Logger.Warn().Err(err).Msg("Run journal disabled; this run cannot be resumed")
+				} else {
+					journal = j
+					defer journal.Close()
+				}
 			}
 
-			runStart := time.Now()
-			results, err := matrix.Run(ctx, entries, matrix.RunOptions{
+			runOpts := matrix.RunOptions{
 				DryRun:                     dryRun,
 				Coverage:                   coverage,
 				StopOnFailure:              stopOnFailure,
@@ -732,6 +805,7 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 				MaxParallel:                maxParallel,
 				TestE2E:                    testE2E,
 				TestAll:                    testAll,
+				Smoke:                      smoke,
 				RepoRoot:                   repoRoot,
 				EnvFiles:                   envFiles,
 				EnvFile:                    envFile,
@@ -741,9 +815,12 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 				SkipDependencyUpdate:       skipDependencyUpdate,
 				VaultBackedSecrets:         vaultBackedSecrets,
 				UseVaultBackedSecrets:      useVaultBackedSecrets,
+				SecretSources:              secretSources,
 				KeycloakHost:               keycloakHost,
 				KeycloakProtocol:           keycloakProtocol,
 				UpgradeFromVersion:         upgradeFromVersion,
+				UpgradePath:                upgradePath,
+				TestEachHop:                testEachHop,
 				HelmTimeout:                helmTimeout,
 				DockerUsername:             dockerUsername,
 				DockerPassword:             dockerPassword,
@@ -777,8 +854,27 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 						statusDisplay.OnPhaseChange(entry, phase)
 					}
 				},
+				OnKubeEvent: func(entry matrix.Entry, line string) {
+					if statusDisplay != nil {
+						statusDisplay.OnKubeEvent(entry, line)
+					}
+				},
 				LogDir: logDir,
-			})
+			}
+			if journal != nil {
+				if err := matrix.SaveRunPlan(logDir, matrix.RunPlan{CreatedAt: time.Now().UTC(), Entries: entries, Options: runOpts}); err != nil {
+					logging.Logger.Warn().Err(err).Msg("Failed to save run plan; this run cannot be resumed")
+				}
+				runOpts = journal.Attach(ctx, runOpts)
+			}
+			var phaseTimer *matrix.PhaseTimer
+			if len(reportFormats) > 0 {
+				phaseTimer = matrix.NewPhaseTimer()
+				runOpts = phaseTimer.Attach(runOpts)
+			}
+
+			runStart := time.Now()
+			results, err := matrix.Run(ctx, entries, runOpts)
 
 			// Close the log file if we opened one.
 			if logFile != nil {
@@ -796,6 +892,20 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 					logging.ColorEnabled = stdoutIsTerminal
 				}
 				fmt.Fprintln(os.Stdout, matrix.PrintRunSummary(results, time.Since(runStart), logDir))
+
+				reportErr := writeMatrixReports(ctx, matrixReportRequest{
+					formats:   reportFormats,
+					dir:       reportDirFor(reportDir, logDir),
+					entries:   entries,
+					results:   results,
+					runErr:    err,
+					wallClock: time.Since(runStart),
+					logDir:    logDir,
+					timer:     phaseTimer,
+				})
+				if err == nil && reportErr != nil {
+					return reportErr
+				}
 			}
 
 			if err != nil {
@@ -818,17 +928,19 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 	f.StringVar(&shortnameFilter, "shortname-filter", "", "Filter entries by shortname substring match (comma-separated for multiple, e.g. eske,eshy)")
 	f.BoolVar(&shortnameExact, "shortname-exact", false, "Treat each --shortname-filter value as an exact match instead of a substring (recommended for per-scenario CI use)")
 	f.StringVar(&flowFilter, "flow-filter", "", "Filter entries by exact flow name")
-	f.StringVar(&platform, "platform", "", "Filter entries to those supporting this platform (also sets deploy platform)")
+	f.StringVar(&platform, "platform", "", "Filter entries to those supporting this platform (also sets deploy platform; local runs the GKE entries on a kind/k3d cluster)")
 	f.StringVar(&repoRoot, "repo-root", "", "Repository root path (or set repoRoot in config)")
 	f.BoolVar(&dryRun, "dry-run", false, "Log what would be deployed without actually deploying")
 	f.BoolVar(&coverage, "coverage", false, "Show a layer-breakdown report of what is tested in the matrix (no deployment)")
 	f.BoolVar(&testE2E, "test-e2e", false, "Run e2e tests after each deployment")
 	f.BoolVar(&testAll, "test-all", false, "Run all e2e tests after each deployment")
+	f.BoolVar(&smoke, "smoke", false, "Run the built-in smoke checks after each deployment (entries below 8.8 are skipped)")
 	f.BoolVar(&stopOnFailure, "stop-on-failure", false, "Stop the run on the first failure")
 	f.StringVar(&namespacePrefix, "namespace-prefix", "matrix", "Prefix for generated namespaces")
 	f.BoolVar(&cleanup, "cleanup", false, "Delete each entry's namespace after its deployment and tests complete")
 	f.BoolVar(&deleteNamespace, "delete-namespace", false, "Delete the namespace before deploying each entry (clean-slate deployment)")
 	f.StringVar(&kubeContext, "kube-context", "", "Default Kubernetes context for all platforms (overridden by --kube-context-gke/--kube-context-eks)")
+	addLocalClusterFlags(cmd, &localCluster, &localClusterName)
 	f.StringVar(&kubeContextGKE, "kube-context-gke", "", "Kubernetes context for GKE entries")
 	f.StringVar(&kubeContextEKS, "kube-context-eks", "", "Kubernetes context for EKS entries")
 	f.StringVar(&ingressBaseDomain, "ingress-base-domain", "", "Fallback base DNS zone used to compute each entry's public URL — joined into CAMUNDA_HOSTNAME as <namespace>.<base>. Set to the DNS zone the target cluster's ingress controller serves, e.g. `ci.distro.ultrawombat.com` (Camunda CI) or `apps.mycompany.example`. Overridden per-platform by --ingress-base-domain-gke/--ingress-base-domain-eks.")
@@ -848,6 +960,9 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 	f.StringVar(&keycloakHost, "keycloak-host", "", "Keycloak external host")
 	f.StringVar(&keycloakProtocol, "keycloak-protocol", "", "Keycloak protocol (defaults to "+config.DefaultKeycloakProtocol+")")
 	f.StringVar(&upgradeFromVersion, "upgrade-from-version", "", "Override the auto-resolved 'from' chart version for upgrade flows (e.g., 13.5.0)")
+	f.StringVar(&upgradePathFrom, "from", "", "Run the upgrade-path flow: install this minor (e.g., 8.6), then upgrade one minor at a time to --to")
+	f.StringVar(&upgradePathTo, "to", "", "Target minor of the upgrade-path flow (e.g., 8.10); its install scenarios are run, and the last hop uses the local chart")
+	f.BoolVar(&testEachHop, "test-each-hop", false, "With --from/--to, run tests after every hop instead of only after the last")
 	f.IntVar(&helmTimeout, "timeout", 10, "Timeout in minutes for Helm deployment (applies to all entries)")
 	f.StringVar(&dockerUsername, "docker-username", "", "Harbor registry username (defaults to HARBOR_USERNAME, TEST_DOCKER_USERNAME_CAMUNDA_CLOUD, or NEXUS_USERNAME env var)")
 	f.StringVar(&dockerPassword, "docker-password", "", "Harbor registry password (defaults to HARBOR_PASSWORD, TEST_DOCKER_PASSWORD_CAMUNDA_CLOUD, or NEXUS_PASSWORD env var)")
@@ -860,6 +975,8 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 	f.BoolVar(&forceImageOverrides, "force-image-overrides", false, "Bypass OCI immutability guard: allow chart-root image overlays when --chart-ref is set (env-file IMAGE_TAG keys stripped at the workflow layer are not restored).")
 	f.BoolVarP(&yes, "yes", "y", false, "Skip confirmation prompts (e.g., e2e threshold warning)")
 	f.StringVar(&logDir, "log-dir", "", "Write logs to this directory and show a live status table (auto-generated when running in a TTY)")
+	f.StringVar(&report, "report", "", "Write machine-readable results after the run (comma-separated: json, junit, markdown)")
+	f.StringVar(&reportDir, "report-dir", "", "Directory for --report files (defaults to the log directory, else the current directory)")
 	f.StringArrayVar(&extraHelmArgs, "extra-helm-arg", nil, "Extra argument appended to every helm command (repeatable, e.g. --extra-helm-arg=--set-file=global.license.secret.inlineSecret=/tmp/license.txt)")
 	f.StringSliceVar(&extraHelmSets, "extra-helm-set", nil, "Extra helm --set key=value pair applied to every entry (comma-separated or repeatable, e.g. orchestration.upgrade.allowPreReleaseImages=true)")
 	f.StringArrayVar(&extraValues, "extra-values", nil, "Additional Helm values files appended last for every entry (repeatable; not comma-split — use the flag multiple times for multiple files). Engages digest-overlay strip; prefer over --extra-helm-arg=--values=. In two-step upgrade flows, applied to Step 2 only.")
@@ -882,6 +999,7 @@ Under the hood this invokes deploy.Execute() for each matrix entry.`,
 	_ = cmd.RegisterFlagCompletionFunc("log-level", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
 		return completeLogLevels(toComplete)
 	})
+	_ = cmd.RegisterFlagCompletionFunc("report", completeReportFormats)
 
 	annotateFlagGroups(cmd, matrixRunFlagGroups())
 
@@ -1190,7 +1308,22 @@ func extractHelmSetValue(pairs []string, key string) string {
 	return value
 }
 
-func runTopologyEntry(ctx context.Context, entry matrix.Entry, opts matrix.RunOptions) error {
+// topologyPlan is the up-front part of a topology entry's fan-out: the
+// resolved platform, the cross-ref env every release shares and the deploy
+// order. Both runTopologyEntry and `render` build each release's flags from
+// it, so a rendered topology sees exactly the flags a deploy would.
+type topologyPlan struct {
+	platform           string
+	crossRefEnv        map[string]string
+	order              []int
+	orchestrationCount int
+	contexts           []*deploy.ScenarioContext
+}
+
+// planTopologyEntry validates a topology entry and computes its topologyPlan.
+// It is pure: namespaces and realms come from deploy.GenerateTopologyContexts
+// and nothing touches the cluster.
+func planTopologyEntry(entry matrix.Entry, opts matrix.RunOptions) (*topologyPlan, error) {
 	platform := entry.Platform
 	if platform == "" {
 		platform = opts.Platform
@@ -1200,14 +1333,14 @@ func runTopologyEntry(ctx context.Context, entry matrix.Entry, opts matrix.RunOp
 	}
 
 	if entry.Auth != "keycloak" || entry.Flow != "install" {
-		return fmt.Errorf("topology entry %s/%s: multi-namespace topology currently supports only auth=keycloak and flow=install (got auth=%q, flow=%q)", entry.Version, entry.Scenario, entry.Auth, entry.Flow)
+		return nil, fmt.Errorf("topology entry %s/%s: multi-namespace topology currently supports only auth=keycloak and flow=install (got auth=%q, flow=%q)", entry.Version, entry.Scenario, entry.Auth, entry.Flow)
 	}
 
 	if opts.ChartRef != "" {
-		return fmt.Errorf("topology entry %s/%s: --chart-ref is not supported on the multi-namespace topology path yet (applyChartRefOverride runs only in executeEntry); tracked in #6656", entry.Version, entry.Scenario)
+		return nil, fmt.Errorf("topology entry %s/%s: --chart-ref is not supported on the multi-namespace topology path yet (applyChartRefOverride runs only in executeEntry); tracked in #6656", entry.Version, entry.Scenario)
 	}
 	if opts.Cleanup {
-		return fmt.Errorf("topology entry %s/%s: --cleanup is not supported on the multi-namespace topology path yet (no per-release namespace teardown; the loop's cleanup() is BuildEntryFlags' temp-file cleanup); tracked in #6656", entry.Version, entry.Scenario)
+		return nil, fmt.Errorf("topology entry %s/%s: --cleanup is not supported on the multi-namespace topology path yet (no per-release namespace teardown; the loop's cleanup() is BuildEntryFlags' temp-file cleanup); tracked in #6656", entry.Version, entry.Scenario)
 	}
 
 	releases := make([]deploy.TopologyRelease, 0, len(entry.Topology.Releases))
@@ -1230,7 +1363,7 @@ func runTopologyEntry(ctx context.Context, entry matrix.Entry, opts matrix.RunOp
 		Deployment: config.DeploymentFlags{Namespace: baseNamespace},
 	})
 	if err != nil {
-		return fmt.Errorf("topology entry %s/%s: %w", entry.Version, entry.Scenario, err)
+		return nil, fmt.Errorf("topology entry %s/%s: %w", entry.Version, entry.Scenario, err)
 	}
 
 	hubIdx := -1
@@ -1244,7 +1377,7 @@ func runTopologyEntry(ctx context.Context, entry matrix.Entry, opts matrix.RunOp
 		}
 	}
 	if hubIdx == -1 {
-		return fmt.Errorf("topology entry %s/%s: topology has no \"hub\" release", entry.Version, entry.Scenario)
+		return nil, fmt.Errorf("topology entry %s/%s: topology has no \"hub\" release", entry.Version, entry.Scenario)
 	}
 
 	sharedStorageService := resolveSharedStorageServiceName(entry.Topology, entry.Topology.Releases[hubIdx].ResolvedDependencies)
@@ -1278,30 +1411,58 @@ func runTopologyEntry(ctx context.Context, entry matrix.Entry, opts matrix.RunOp
 	// orchestration releases depend on, therefore deploys first).
 	order, err := topologyDeployOrder(entry.Topology.Releases)
 	if err != nil {
-		return fmt.Errorf("topology entry %s/%s: %w", entry.Version, entry.Scenario, err)
+		return nil, fmt.Errorf("topology entry %s/%s: %w", entry.Version, entry.Scenario, err)
 	}
 
-	for _, i := range order {
-		rel := entry.Topology.Releases[i]
-		releaseCtx := contexts[i]
-
-		releaseEntry := synthesizeReleaseEntry(entry, rel, platform)
-		releaseOpts := synthesizeReleaseOpts(opts, platform, releaseCtx.Namespace)
-		if len(orchestrationIndices) > 1 {
-			hostKey := "HUB_HOST"
-			if rel.Role == "orchestration" {
-				hostKey = topologyEnvToken(rel.NamespaceSuffix) + "_HOST"
-			}
-			releaseOpts.ExtraHelmSets = append(releaseOpts.ExtraHelmSets, "global.host="+crossRefEnv[hostKey])
+	return &topologyPlan{
+		platform:           platform,
+		crossRefEnv:        crossRefEnv,
+		order:              order,
+		orchestrationCount: len(orchestrationIndices),
+		contexts:           contexts,
+	}, nil
+}
+
+// releaseFlags builds the RuntimeFlags of the i-th topology release via
+// matrix.BuildEntryFlags plus the topology overrides. The caller must invoke
+// the returned cleanup func, even on error.
+func (p *topologyPlan) releaseFlags(entry matrix.Entry, i int, opts matrix.RunOptions) (matrix.Entry, *config.RuntimeFlags, string, func(), error) {
+	rel := entry.Topology.Releases[i]
+	releaseCtx := p.contexts[i]
+
+	releaseEntry := synthesizeReleaseEntry(entry, rel, p.platform)
+	releaseOpts := synthesizeReleaseOpts(opts, p.platform, releaseCtx.Namespace)
+	if p.orchestrationCount > 1 {
+		hostKey := "HUB_HOST"
+		if rel.Role == "orchestration" {
+			hostKey = topologyEnvToken(rel.NamespaceSuffix) + "_HOST"
 		}
+		releaseOpts.ExtraHelmSets = append(releaseOpts.ExtraHelmSets, "global.host="+p.crossRefEnv[hostKey])
+	}
+
+	flags, namespace, _, _, cleanup, err := matrix.BuildEntryFlags(releaseEntry, releaseOpts)
+	if err != nil {
+		return releaseEntry, nil, namespace, cleanup, fmt.Errorf("topology release %s/%s (namespace-suffix %q): build flags: %w", entry.Scenario, rel.Role, rel.NamespaceSuffix, err)
+	}
+	applyTopologyReleaseOverrides(flags, buildTopologyReleaseEnv(p.crossRefEnv, rel))
+	return releaseEntry, flags, namespace, cleanup, nil
+}
+
+func runTopologyEntry(ctx context.Context, entry matrix.Entry, opts matrix.RunOptions) error {
+	plan, err := planTopologyEntry(entry, opts)
+	if err != nil {
+		return err
+	}
+
+	for _, i := range plan.order {
+		rel := entry.Topology.Releases[i]
 
-		flags, namespace, _, _, cleanup, buildErr := matrix.BuildEntryFlags(releaseEntry, releaseOpts)
+		releaseEntry, flags, namespace, cleanup, buildErr := plan.releaseFlags(entry, i, opts)
 		if buildErr != nil {
 			cleanup()
-			return fmt.Errorf("topology release %s/%s (namespace-suffix %q): build flags: %w", entry.Scenario, rel.Role, rel.NamespaceSuffix, buildErr)
+			return buildErr
 		}
 
-		applyTopologyReleaseOverrides(flags, buildTopologyReleaseEnv(crossRefEnv, rel))
 		if err := matrix.RegisterDeclarativePostDeployHook(flags, releaseEntry.PostDeploy, opts.RepoRoot, releaseEntry.Version, releaseEntry.Scenario); err != nil {
 			cleanup()
 			return fmt.Errorf("topology release %s/%s (namespace-suffix %q): register post-deploy hook: %w", entry.Scenario, rel.Role, rel.NamespaceSuffix, err)
@@ -0,0 +1,95 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/matrix"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// matrixReportRequest carries everything `matrix run` and `matrix resume`
+// need to write their --report documents.
+type matrixReportRequest struct {
+	formats   []matrix.ReportFormat
+	dir       string
+	entries   []matrix.Entry
+	results   []matrix.RunResult
+	runErr    error
+	wallClock time.Duration
+	logDir    string
+	timer     *matrix.PhaseTimer
+}
+
+// reportDirFor picks where reports go: the explicit --report-dir, else the
+// run's log directory, else the working directory.
+func reportDirFor(reportDir, logDir string) string {
+	if reportDir != "" {
+		return reportDir
+	}
+	if logDir != "" {
+		return logDir
+	}
+	return "."
+}
+
+// writeMatrixReports renders the requested report formats and prints where
+// they went. The Markdown report is also appended to $GITHUB_STEP_SUMMARY
+// when running in GitHub Actions, so the job page shows the matrix table.
+func writeMatrixReports(ctx context.Context, req matrixReportRequest) error {
+	if len(req.formats) == 0 {
+		return nil
+	}
+	report := matrix.BuildRunReport(ctx, req.entries, req.results, req.runErr, req.wallClock, req.logDir, req.timer)
+	paths, err := matrix.WriteReports(req.dir, report, req.formats)
+	for _, p := range paths {
+		fmt.Fprintf(os.Stdout, "Report written: %s\n", p)
+	}
+	if err != nil {
+		return err
+	}
+
+	summaryPath := os.Getenv("GITHUB_STEP_SUMMARY")
+	if summaryPath == "" {
+		return nil
+	}
+	for _, f := range req.formats {
+		if f != matrix.ReportMarkdown {
+			continue
+		}
+		sf, err := os.OpenFile(summaryPath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+		if err != nil {
+			logging.Logger.Warn().Err(err).Msg("Failed to open GITHUB_STEP_SUMMARY")
+			return nil
+		}
+		defer sf.Close()
+		if _, err := fmt.Fprintln(sf, report.Markdown()); err != nil {
+			logging.Logger.Warn().Err(err).Msg("Failed to write GITHUB_STEP_SUMMARY")
+		}
+	}
+	return nil
+}
+
+// completeReportFormats completes the comma-separated --report list,
+// offering only formats not already given.
+func completeReportFormats(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	prefix := ""
+	if i := strings.LastIndex(toComplete, ","); i >= 0 {
+		prefix = toComplete[:i+1]
+	}
+	used := map[string]bool{}
+	for _, part := range strings.Split(prefix, ",") {
+		used[part] = true
+	}
+	var out []string
+	for _, f := range matrix.ReportFormats {
+		if !used[string(f)] {
+			out = append(out, prefix+string(f))
+		}
+	}
+	return out, cobra.ShellCompDirectiveNoFileComp | cobra.ShellCompDirectiveNoSpace
+}
@@ -0,0 +1,241 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/matrix"
+	"scripts/prepare-helm-values/pkg/env"
+	"syscall"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newMatrixResumeCommand creates the "matrix resume" subcommand, which
+// continues a `matrix run` from the journal and run plan it left in its log
+// directory.
+func newMatrixResumeCommand() *cobra.Command {
+	var (
+		retryFailed  bool
+		halfFinished string
+		dryRun       bool
+		logLevel     string
+		envFile      string
+		report       string
+		reportDir    string
+		helmSets     []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "resume <log-dir>",
+		Short: "Continue an interrupted matrix run from its log directory",
+		Long: `Continue a 'matrix run' that was interrupted (Ctrl-C, laptop sleep, CI
+runner preemption) from the journal it wrote into its log directory.
+
+Every 'matrix run' with a log directory records run-plan.json (the entries
+and run options) and run-journal.jsonl (one record per entry start, phase
+change and completion). Resume reads both and:
+
+  - skips entries that already passed or failed (--retry-failed re-runs
+    the failed ones),
+  - handles entries that started but never finished according to
+    --half-finished: "cleanup" deletes their namespace and deploys from
+    scratch, "reattach" deploys into the existing namespace again,
+  - runs everything else with the original run options, appending to the
+    same journal so a resumed run can itself be resumed.
+
+<log-dir> is either the timestamped run directory or the --log-dir parent,
+in which case its "latest" run is used. Registry passwords are never
+written to the run plan; they are read from the environment (or the env
+file) exactly as 'matrix run' falls back to them. Secret --extra-helm-set
+and --extra-helm-arg --set values are written redacted; pass each of them
+again with --extra-helm-set key=value.`,
+		Example: `  # Resume the most recent run under /tmp/matrix-logs:
+  deploy-camunda matrix resume /tmp/matrix-logs
+
+  # Show what would be skipped and re-run, without touching the cluster:
+  deploy-camunda matrix resume /tmp/matrix-logs/20260504-101500 --dry-run
+
+  # Re-run failures too, keeping half-finished namespaces:
+  deploy-camunda matrix resume /tmp/matrix-logs --retry-failed --half-finished reattach`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			mode, err := matrix.ParseResumeMode(halfFinished)
+			if err != nil {
+				return err
+			}
+			reportFormats, err := matrix.ParseReportFormats(report)
+			if err != nil {
+				return fmt.Errorf("--report: %w", err)
+			}
+			logDir, err := matrix.ResolveRunLogDir(args[0])
+			if err != nil {
+				return err
+			}
+			plan, err := matrix.LoadRunPlan(logDir)
+			if err != nil {
+				return err
+			}
+			states, err := matrix.ReadJournal(logDir)
+			if err != nil {
+				return err
+			}
+
+			opts := plan.Options
+			if logLevel != "" {
+				opts.LogLevel = logLevel
+			}
+			if err := logging.Setup(logging.Options{
+				LevelString:  opts.LogLevel,
+				ColorEnabled: logging.IsTerminal(os.Stdout.Fd()),
+			}); err != nil {
+				return err
+			}
+
+			envFileToLoad := envFile
+			if envFileToLoad == "" {
+				envFileToLoad = opts.EnvFile
+			}
+			if envFileToLoad == "" {
+				envFileToLoad = ".env"
+			}
+			if err := env.Load(envFileToLoad); err != nil {
+				logging.Logger.Warn().Err(err).Str("envFile", envFileToLoad).Msg("Failed to load environment file")
+			}
+
+			rp := matrix.PlanResume(plan, states, retryFailed)
+			fmt.Fprint(os.Stdout, formatResumePlan(logDir, rp, mode))
+			if dryRun {
+				return nil
+			}
+			if err := matrix.RestoreRedactedHelmSets(&opts, helmSets); err != nil {
+				return err
+			}
+
+			if len(rp.Pending) > 0 {
+				output, _ := matrix.Print(rp.Pending, "table")
+				fmt.Fprintln(os.Stdout, output)
+			}
+
+			// Append to the original run's log so the whole history of the
+			// run stays in one file.
+			stdoutIsTerminal := logging.IsTerminal(os.Stdout.Fd())
+			f, err := os.OpenFile(filepath.Join(logDir, "matrix-run.log"), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+			if err != nil {
+				return fmt.Errorf("failed to open log file: %w", err)
+			}
+			defer f.Close()
+			if err := logging.Setup(logging.Options{
+				LevelString:  opts.LogLevel,
+				ColorEnabled: false,
+				Writer:       f,
+			}); err != nil {
+				return err
+			}
+
+			journal, err := matrix.OpenJournal(logDir)
+			if err != nil {
+				return err
+			}
+			defer journal.Close()
+
+			var statusDisplay *matrix.StatusDisplay
+			if len(rp.Pending) > 0 {
+				statusDisplay = matrix.NewStatusDisplay(os.Stdout, rp.Pending, stdoutIsTerminal, logDir)
+				opts.OnEntryStart = statusDisplay.OnEntryStart
+				opts.OnEntryComplete = statusDisplay.OnEntryComplete
+				opts.OnPhaseChange = statusDisplay.OnPhaseChange
+				opts.OnKubeEvent = statusDisplay.OnKubeEvent
+			}
+			opts.LogDir = logDir
+			opts = journal.Attach(ctx, opts)
+			var phaseTimer *matrix.PhaseTimer
+			if len(reportFormats) > 0 {
+				phaseTimer = matrix.NewPhaseTimer()
+				opts = phaseTimer.Attach(opts)
+			}
+
+			runStart := time.Now()
+			results, err := matrix.Resume(ctx, rp, opts, mode)
+
+			if statusDisplay != nil {
+				statusDisplay.Stop()
+				statusDisplay.Clear()
+			}
+			logging.ColorEnabled = stdoutIsTerminal
+			fmt.Fprintln(os.Stdout, matrix.PrintRunSummary(results, time.Since(runStart), logDir))
+
+			// Report on the whole run, not just the resumed part. Entries kept
+			// from the journal have no phase timings.
+			reportErr := writeMatrixReports(ctx, matrixReportRequest{
+				formats:   reportFormats,
+				dir:       reportDirFor(reportDir, logDir),
+				entries:   plan.Entries,
+				results:   results,
+				runErr:    err,
+				wallClock: time.Since(runStart),
+				logDir:    logDir,
+				timer:     phaseTimer,
+			})
+			if err != nil {
+				return err
+			}
+			if reportErr != nil {
+				return reportErr
+			}
+			if failed := countFailedResults(results); failed > 0 {
+				return fmt.Errorf("matrix resume: %d entr%s failed", failed, pluralEntry(failed))
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.BoolVar(&retryFailed, "retry-failed", false, "Re-run entries that already failed instead of keeping their recorded result")
+	f.StringVar(&halfFinished, "half-finished", string(matrix.ResumeCleanup), "What to do with namespaces of entries that started but never finished: cleanup (delete, then deploy from scratch) or reattach (deploy into the existing namespace)")
+	f.BoolVar(&dryRun, "dry-run", false, "Print which entries would be skipped and re-run without deploying")
+	f.StringVar(&report, "report", "", "Write machine-readable results for the whole run (comma-separated: json, junit, markdown)")
+	f.StringVar(&reportDir, "report-dir", "", "Directory for --report files (defaults to the run's log directory)")
+	f.StringVarP(&logLevel, "log-level", "l", "", "Log level (debug, info, warn, error); defaults to the original run's level")
+	f.StringVar(&envFile, "env-file", "", "Env file to load for registry credentials; defaults to the original run's --env-file, then .env")
+	f.StringSliceVar(&helmSets, "extra-helm-set", nil, "Secret --set key=value pair the run plan redacted (comma-separated or repeatable)")
+
+	_ = cmd.RegisterFlagCompletionFunc("half-finished", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return []string{string(matrix.ResumeCleanup), string(matrix.ResumeReattach)}, cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("report", completeReportFormats)
+	_ = cmd.RegisterFlagCompletionFunc("log-level", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeLogLevels(toComplete)
+	})
+
+	return cmd
+}
+
+// formatResumePlan renders the resume overview printed before any entry runs.
+func formatResumePlan(logDir string, rp matrix.ResumePlan, mode matrix.ResumeMode) string {
+	var passed, failed int
+	for _, r := range rp.Done {
+		if r.Error != nil {
+			failed++
+		} else {
+			passed++
+		}
+	}
+	out := fmt.Sprintf("Resuming %s: %d passed, %d failed (kept), %d to run\n",
+		logDir, passed, failed, len(rp.Pending))
+	for _, s := range rp.HalfFinished {
+		phase := s.Phase
+		if phase == "" {
+			phase = "preparing"
+		}
+		out += fmt.Sprintf("  half-finished: %s in %s (last phase %s) -> %s\n", s.Key, s.Namespace, phase, mode)
+	}
+	return out
+}
@@ -0,0 +1,376 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"scripts/camunda-core/pkg/executil"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/config"
+	"scripts/deploy-camunda/deploy"
+	"scripts/deploy-camunda/matrix"
+	"scripts/deploy-camunda/render"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+)
+
+// renderSelection is the matrix selection and per-entry options shared by
+// the working-tree render and the --diff-against git-ref render.
+type renderSelection struct {
+	versions        []string
+	includeDisabled bool
+	scenarioFilter  string
+	shortnameFilter string
+	shortnameExact  bool
+	flowFilter      string
+	platform        string
+	tier            int
+
+	namespacePrefix   string
+	envFile           string
+	ingressBaseDomain string
+	extraValues       []string
+	extraHelmSets     []string
+	useLatest         bool
+	logLevel          string
+
+	opts render.Options
+}
+
+// gitRun runs git and returns stdout. It is a package var so tests can stub
+// the worktree handling of --diff-against.
+var gitRun = func(ctx context.Context, dir string, args ...string) ([]byte, error) {
+	return executil.RunCommandCapture(ctx, "git", args, nil, dir)
+}
+
+// newRenderCommand creates the "render" command: the matrix's values chain
+// for every selected entry, templated in-process without a cluster.
+func newRenderCommand() *cobra.Command {
+	var (
+		sel         renderSelection
+		repoRoot    string
+		outputDir   string
+		diffAgainst string
+		exitCode    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render matrix scenarios to manifests offline and diff them against another tree",
+		Long: `Render the manifests every selected matrix entry would install, without a cluster.
+
+Each entry goes through the same values chain a deploy uses (common layers,
+chart-root overlays, --extra-values, scenario extra values, layered scenario
+values), and the Helm template engine runs in-process. Every release is
+written to <output-dir>/<version>/<shortname>/<flow>/<release>.yaml, including
+companion charts; topology releases get one more directory level named after
+their namespace suffix. Upgrade flows render the release they upgrade to.
+
+Env vars that are unset render as their literal ${NAME}, and no test
+secrets are generated, so two renders of the same tree are identical.
+
+With --diff-against, the same selection is rendered from a git ref (through a
+temporary git worktree) or read from an earlier render directory, and a
+resource-level diff is printed: added, removed and changed resources, with
+each changed field shown as path: old -> new.`,
+		Example: `  # Render every 8.8 scenario
+  deploy-camunda render --versions 8.8
+
+  # What does my values-layer change do to every scenario, compared to main?
+  deploy-camunda render --versions 8.8,8.9 --diff-against origin/main`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if err := logging.Setup(logging.Options{
+				LevelString:  sel.logLevel,
+				ColorEnabled: logging.IsTerminal(os.Stdout.Fd()),
+			}); err != nil {
+				return err
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			repoRoot = resolveRepoRoot(repoRoot)
+			if repoRoot == "" {
+				return fmt.Errorf("--repo-root is required (or set repoRoot in config, or run from within the repo)")
+			}
+
+			renderErr := renderMatrix(ctx, repoRoot, outputDir, sel)
+			if diffAgainst == "" {
+				return renderErr
+			}
+			if renderErr != nil {
+				logging.Logger.Warn().Err(renderErr).Msg("Some entries failed to render; they show up as removed in the diff")
+			}
+
+			baseDir, cleanup, err := resolveDiffBase(ctx, repoRoot, diffAgainst, sel)
+			defer cleanup()
+			if err != nil {
+				return err
+			}
+			diffs, err := render.Diff(baseDir, outputDir)
+			if err != nil {
+				return err
+			}
+			render.WriteDiff(cmd.OutOrStdout(), diffs)
+			if renderErr != nil {
+				return renderErr
+			}
+			if exitCode && len(diffs) > 0 {
+				return fmt.Errorf("%d resource(s) differ from %s", len(diffs), diffAgainst)
+			}
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringSliceVar(&sel.versions, "versions", nil, "Limit to specific chart versions (comma-separated, e.g., 8.8,8.9)")
+	f.BoolVar(&sel.includeDisabled, "include-disabled", false, "Include disabled scenarios")
+	f.StringVar(&sel.scenarioFilter, "scenario-filter", "", "Filter scenarios by substring match (comma-separated for multiple)")
+	f.StringVar(&sel.shortnameFilter, "shortname-filter", "", "Filter entries by shortname substring match (comma-separated for multiple)")
+	f.BoolVar(&sel.shortnameExact, "shortname-exact", false, "Treat each --shortname-filter value as an exact match")
+	f.StringVar(&sel.flowFilter, "flow-filter", "", "Filter entries by exact flow name")
+	f.StringVar(&sel.platform, "platform", "", "Render entries for this platform: gke, eks, rosa or local (default: each entry's own, else gke)")
+	f.IntVar(&sel.tier, "tier", 0, "Filter entries by tier (1=PR CI, 2=merge-queue only; 0=all)")
+	f.StringVar(&sel.namespacePrefix, "namespace-prefix", "matrix", "Prefix for generated namespaces (matches matrix run)")
+	f.StringVar(&sel.envFile, "env-file", "", ".env file used for placeholder substitution")
+	f.StringVar(&sel.ingressBaseDomain, "ingress-base-domain", "", "Base DNS zone used to compute each entry's hostname")
+	f.StringSliceVar(&sel.extraValues, "extra-values", nil, "Additional Helm values files applied to every entry")
+	f.StringArrayVar(&sel.extraHelmSets, "extra-helm-set", nil, "Extra key=value Helm --set pairs applied to every entry")
+	f.BoolVar(&sel.useLatest, "use-latest", false, "Use values-latest.yaml instead of values-digest.yaml for image versions")
+	f.StringVar(&sel.opts.KubeVersion, "kube-version", "", "Kubernetes version reported to templates (.Capabilities.KubeVersion)")
+	f.StringSliceVar(&sel.opts.APIVersions, "api-versions", nil, "Extra API versions reported to templates (.Capabilities.APIVersions), e.g. monitoring.coreos.com/v1")
+	f.StringVar(&repoRoot, "repo-root", "", "Repository root path (or set repoRoot in config)")
+	f.StringVarP(&outputDir, "output-dir", "o", "rendered", "Directory the manifests are written to (replaced on every run)")
+	f.StringVar(&diffAgainst, "diff-against", "", "Git ref or earlier render directory to diff the rendered resources against")
+	f.BoolVar(&exitCode, "exit-code", false, "With --diff-against, exit non-zero when any resource differs")
+	f.StringVarP(&sel.logLevel, "log-level", "l", "warn", "Log level (debug, info, warn, error)")
+
+	registerMatrixShortnameCompletion(cmd)
+	registerMatrixVersionsCompletion(cmd)
+	registerMatrixFlowCompletion(cmd)
+
+	return cmd
+}
+
+// renderMatrix renders every entry sel selects from repoRoot into outputDir.
+// A failing entry does not stop the others; the returned error lists them.
+func renderMatrix(ctx context.Context, repoRoot, outputDir string, sel renderSelection) error {
+	entries, err := matrix.Generate(repoRoot, matrix.GenerateOptions{
+		Versions:        sel.versions,
+		IncludeDisabled: sel.includeDisabled,
+	})
+	if err != nil {
+		return err
+	}
+	entries = matrix.Filter(entries, matrix.FilterOptions{
+		ScenarioFilter:  sel.scenarioFilter,
+		ShortnameFilter: sel.shortnameFilter,
+		ShortnameExact:  sel.shortnameExact,
+		FlowFilter:      sel.flowFilter,
+		Platform:        sel.platform,
+		Tier:            sel.tier,
+	})
+	if len(entries) == 0 {
+		return fmt.Errorf("no matrix entries matched the filters")
+	}
+
+	if err := resetRenderDir(outputDir); err != nil {
+		return err
+	}
+
+	opts := matrix.RunOptions{
+		NamespacePrefix:      sel.namespacePrefix,
+		Platform:             sel.platform,
+		RepoRoot:             repoRoot,
+		EnvFile:              sel.envFile,
+		IngressBaseDomain:    sel.ingressBaseDomain,
+		LogLevel:             sel.logLevel,
+		SkipDependencyUpdate: true,
+		UseLatest:            sel.useLatest,
+		ExtraValues:          sel.extraValues,
+		ExtraHelmSets:        sel.extraHelmSets,
+	}
+
+	var failed []string
+	for _, e := range entries {
+		dir := filepath.Join(outputDir, e.Version, entryRenderName(e), e.Flow)
+		var err error
+		if e.Topology != nil {
+			err = renderTopologyEntry(ctx, e, opts, dir, sel.opts)
+		} else {
+			err = renderEntry(ctx, e, opts, dir, sel.opts)
+		}
+		if err != nil {
+			logging.Logger.Error().Err(err).Str("version", e.Version).Str("scenario", e.Scenario).Str("flow", e.Flow).Msg("Render failed")
+			failed = append(failed, fmt.Sprintf("%s/%s/%s", e.Version, entryRenderName(e), e.Flow))
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "rendered %s\n", dir)
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d entries failed to render: %s", len(failed), len(entries), strings.Join(failed, ", "))
+	}
+	return nil
+}
+
+// renderMarker marks a directory as render output, so resetRenderDir never
+// clears a directory the user pointed --output-dir at by mistake.
+const renderMarker = ".deploy-camunda-render"
+
+// resetRenderDir empties outputDir so stale releases from an earlier run do
+// not leak into a diff. It refuses non-empty directories it did not create.
+func resetRenderDir(outputDir string) error {
+	entries, err := os.ReadDir(outputDir)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("failed to read render output dir %q: %w", outputDir, err)
+	}
+	if len(entries) > 0 {
+		if _, err := os.Stat(filepath.Join(outputDir, renderMarker)); err != nil {
+			return fmt.Errorf("render output dir %q is not empty and was not written by render; pick another --output-dir", outputDir)
+		}
+		if err := os.RemoveAll(outputDir); err != nil {
+			return fmt.Errorf("failed to clear render output dir %q: %w", outputDir, err)
+		}
+	}
+	if err := os.MkdirAll(outputDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create render output dir %q: %w", outputDir, err)
+	}
+	return os.WriteFile(filepath.Join(outputDir, renderMarker), nil, 0o644)
+}
+
+func entryRenderName(e matrix.Entry) string {
+	if e.Shortname != "" {
+		return e.Shortname
+	}
+	return e.Scenario
+}
+
+// renderEntry renders one single-namespace entry with the flags matrix run
+// would deploy it with.
+func renderEntry(ctx context.Context, e matrix.Entry, opts matrix.RunOptions, dir string, ropts render.Options) error {
+	flags, _, _, _, cleanup, err := matrix.BuildEntryFlags(e, opts)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	return renderFlags(ctx, flags, dir, ropts)
+}
+
+// renderTopologyEntry renders every release of a topology entry into its own
+// <dir>/<namespace-suffix> directory, with the flags runTopologyEntry uses.
+func renderTopologyEntry(ctx context.Context, e matrix.Entry, opts matrix.RunOptions, dir string, ropts render.Options) error {
+	plan, err := planTopologyEntry(e, opts)
+	if err != nil {
+		return err
+	}
+	for _, i := range plan.order {
+		_, flags, _, cleanup, err := plan.releaseFlags(e, i, opts)
+		if err == nil {
+			err = renderFlags(ctx, flags, filepath.Join(dir, e.Topology.Releases[i].NamespaceSuffix), ropts)
+		}
+		cleanup()
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func renderFlags(ctx context.Context, flags *config.RuntimeFlags, dir string, ropts render.Options) error {
+	releases, cleanup, err := deploy.RenderReleases(ctx, flags)
+	defer cleanup()
+	if err != nil {
+		return err
+	}
+	// Template every release before writing any, so a failing companion
+	// does not leave a half-rendered entry behind to be diffed.
+	manifests := make([]string, len(releases))
+	for i, rel := range releases {
+		if manifests[i], err = render.Template(ctx, rel, ropts); err != nil {
+			return err
+		}
+	}
+	for i, rel := range releases {
+		if _, err := render.WriteRelease(dir, rel.Name, manifests[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// resolveDiffBase returns the directory to diff against: diffAgainst itself
+// when it is a directory, otherwise a render of the same selection from a git
+// worktree checked out at that ref. cleanup removes the worktree and is safe
+// to call on error.
+func resolveDiffBase(ctx context.Context, repoRoot, diffAgainst string, sel renderSelection) (string, func(), error) {
+	noop := func() {}
+	if info, err := os.Stat(diffAgainst); err == nil && info.IsDir() {
+		return diffAgainst, noop, nil
+	}
+	if _, err := gitRun(ctx, repoRoot, "rev-parse", "--verify", "--quiet", diffAgainst+"^{commit}"); err != nil {
+		return "", noop, fmt.Errorf("--diff-against %q is neither a directory nor a git ref in %s", diffAgainst, repoRoot)
+	}
+
+	tmp, err := os.MkdirTemp("", "deploy-camunda-render-base-*")
+	if err != nil {
+		return "", noop, fmt.Errorf("failed to create temp dir: %w", err)
+	}
+	worktree := filepath.Join(tmp, "worktree")
+	cleanup := func() {
+		if _, err := gitRun(context.Background(), repoRoot, "worktree", "remove", "--force", worktree); err != nil {
+			logging.Logger.Warn().Err(err).Str("worktree", worktree).Msg("Failed to remove git worktree")
+		}
+		os.RemoveAll(tmp)
+	}
+	if _, err := gitRun(ctx, repoRoot, "worktree", "add", "--detach", worktree, diffAgainst); err != nil {
+		os.RemoveAll(tmp)
+		return "", noop, fmt.Errorf("failed to check out %s into a worktree: %w", diffAgainst, err)
+	}
+	if err := linkVendoredSubcharts(repoRoot, worktree); err != nil {
+		return "", cleanup, err
+	}
+
+	baseDir := filepath.Join(tmp, "rendered")
+	fmt.Fprintf(os.Stderr, "rendering %s for comparison\n", diffAgainst)
+	if err := renderMatrix(ctx, worktree, baseDir, sel); err != nil {
+		logging.Logger.Warn().Err(err).Str("ref", diffAgainst).Msg("Some entries failed to render at the base ref; they show up as added in the diff")
+	}
+	return baseDir, cleanup, nil
+}
+
+// linkVendoredSubcharts points each chart's charts/ directory in the worktree
+// at the working tree's copy when the ref has none. Subcharts are fetched by
+// `helm dependency build` and not committed, so a fresh worktree lacks them.
+func linkVendoredSubcharts(repoRoot, worktree string) error {
+	matches, err := filepath.Glob(filepath.Join(repoRoot, "charts", "*", "charts"))
+	if err != nil {
+		return err
+	}
+	for _, src := range matches {
+		rel, err := filepath.Rel(repoRoot, src)
+		if err != nil {
+			return err
+		}
+		dst := filepath.Join(worktree, rel)
+		if _, err := os.Stat(filepath.Dir(dst)); err != nil {
+			continue // chart version does not exist at the ref
+		}
+		if entries, err := os.ReadDir(dst); err == nil && len(entries) > 0 {
+			continue
+		} else if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		if err := os.RemoveAll(dst); err != nil {
+			return err
+		}
+		if err := os.Symlink(src, dst); err != nil {
+			return fmt.Errorf("failed to link subcharts for %s: %w", rel, err)
+		}
+	}
+	return nil
+}
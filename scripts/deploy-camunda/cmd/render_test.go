@@ -0,0 +1,45 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// resetRenderDir clears output it wrote itself but must never wipe a
+// directory the user pointed --output-dir at by mistake.
+func TestResetRenderDirRefusesForeignDir(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "keep.txt"), []byte("x"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := resetRenderDir(dir); err == nil {
+		t.Fatal("expected error for non-empty dir without marker")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "keep.txt")); err != nil {
+		t.Fatalf("foreign file was removed: %v", err)
+	}
+}
+
+func TestResetRenderDirClearsPreviousRender(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "rendered")
+	if err := resetRenderDir(dir); err != nil {
+		t.Fatalf("first reset: %v", err)
+	}
+	stale := filepath.Join(dir, "8.9", "old.yaml")
+	if err := os.MkdirAll(filepath.Dir(stale), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(stale, []byte("kind: ConfigMap\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := resetRenderDir(dir); err != nil {
+		t.Fatalf("second reset: %v", err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Fatalf("stale render survived: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, renderMarker)); err != nil {
+		t.Fatalf("marker missing: %v", err)
+	}
+}
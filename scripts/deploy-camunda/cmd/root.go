@@ -107,6 +107,20 @@ func NewRootCommand() *cobra.Command {
 				if cmd.Name() == "e2e-env" || (cmd.Parent() != nil && cmd.Parent().Name() == "e2e-env") {
 					return nil
 				}
+				// local manages the kind/k3d cluster itself; no chart config needed.
+				if cmd.Name() == "local" || (cmd.Parent() != nil && cmd.Parent().Name() == "local") {
+					return nil
+				}
+				// render builds its own flags per matrix entry and never
+				// touches a cluster; no chart/namespace/release config needed.
+				if cmd.Name() == "render" {
+					return nil
+				}
+				// values subcommands resolve their own flags and config; the
+				// deploy's namespace/release requirements do not apply.
+				if cmd.Name() == "values" || (cmd.Parent() != nil && cmd.Parent().Name() == "values") {
+					return nil
+				}
 				// topology subcommands are pure string-derivation utilities;
 				// no chart/namespace/release config needed.
 				if cmd.Name() == "topology" || (cmd.Parent() != nil && cmd.Parent().Name() == "topology") {
@@ -172,7 +186,12 @@ func NewRootCommand() *cobra.Command {
 			if err := env.Load(envFileToLoad); err != nil {
 				logging.Logger.Warn().Err(err).Str("envFile", envFileToLoad).Msg("Failed to load environment file")
 			}
-			if err := resolveRegistryCredentials(&flags.Docker); err != nil {
+			// Secret sources are fetched once here so registry credentials can
+			// come from them; deploy.Execute reuses the loaded values.
+			if err := deploy.LoadSecretSources(cmd.Context(), &flags); err != nil {
+				return err
+			}
+			if err := resolveRegistryCredentialsWithSources(&flags.Docker, flags.Secrets.SourceEnv); err != nil {
 				return err
 			}
 
@@ -221,6 +240,18 @@ func NewRootCommand() *cobra.Command {
 			// Log flags
 			format.PrintFlags(cmd.Flags())
 
+			// --platform local deploys into a kind/k3d cluster that is created
+			// on first use; rendering manifests needs no cluster.
+			if flags.Deployment.Platform == config.PlatformLocal && !flags.Deployment.RenderTemplates {
+				kubeContext, err := ensureLocalCluster(ctx, flags.Local.Provider, flags.Local.Name)
+				if err != nil {
+					return err
+				}
+				if flags.Test.KubeContext == "" {
+					flags.Test.KubeContext = kubeContext
+				}
+			}
+
 			// Execute deployment
 			return deploy.Execute(ctx, &flags)
 		},
@@ -240,7 +271,7 @@ func NewRootCommand() *cobra.Command {
 	f.StringVarP(&flags.Deployment.Scenario, "scenario", "s", "", "The name of the scenario to deploy (comma-separated for parallel deployment)")
 	f.StringVar(&flags.Deployment.ScenarioPath, "scenario-path", "", "Path to scenario files")
 	f.StringVar(&flags.Auth.Auth, "auth", "keycloak", "Auth scenario")
-	f.StringVar(&flags.Deployment.Platform, "platform", "gke", "Target platform: gke, rosa, eks")
+	f.StringVar(&flags.Deployment.Platform, "platform", "gke", "Target platform: gke, rosa, eks, local (kind/k3d on this machine)")
 	f.StringVarP(&flags.LogLevel, "log-level", "l", "info", "Log level")
 	f.BoolVar(&flags.Chart.SkipDependencyUpdate, "skip-dependency-update", true, "Skip Helm dependency update")
 	f.BoolVar(&flags.Secrets.ExternalSecrets, "external-secrets", true, "Enable external secrets")
@@ -285,7 +316,9 @@ func NewRootCommand() *cobra.Command {
 	// Test execution flags
 	f.BoolVar(&flags.Test.RunE2ETests, "test-e2e", false, "Run e2e tests after deployment")
 	f.BoolVar(&flags.Test.RunAllTests, "test-all", false, "Run all e2e tests after deployment")
+	f.BoolVar(&flags.Test.RunSmoke, "smoke", false, "Run the built-in smoke checks (token, BPMN deploy/start/search, component health) after deployment")
 	f.StringVar(&flags.Test.KubeContext, "kube-context", "", "Kubernetes context to use for deployment")
+	addLocalClusterFlags(rootCmd, &flags.Local.Provider, &flags.Local.Name)
 	f.StringVar(&flags.Test.TestExclude, "test-exclude", "", "Pipe-separated regex of test suites to exclude (passed as --grep-invert to Playwright)")
 	f.BoolVar(&flags.Secrets.UseVaultBackedSecrets, "use-vault-backed-secrets", false, "Use vault-backed external secrets (selects -vault.yaml suffix files)")
 	// Selection + composition model (new - preferred over deprecated --scenario)
@@ -576,6 +609,9 @@ func Execute() error {
 	rootCmd.AddCommand(newCICommand())
 	rootCmd.AddCommand(newE2EEnvCommand())
 	rootCmd.AddCommand(newTopologyCommand())
+	rootCmd.AddCommand(newLocalCommand())
+	rootCmd.AddCommand(newRenderCommand())
+	rootCmd.AddCommand(newValuesCommand())
 
 	err := rootCmd.Execute()
 	if err != nil {
@@ -10,8 +10,10 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
 
 	"scripts/camunda-core/pkg/executil"
+	"scripts/camunda-core/pkg/failures"
 	"scripts/camunda-core/pkg/logging"
 
 	"github.com/spf13/cobra"
@@ -36,11 +38,11 @@ type runRef struct {
 // matrixCell is the decoded integration matrix coordinate parsed from a job name
 // like "8.9 - cprst - install - pr - gke".
 type matrixCell struct {
-	Version   string
-	Shortname string
-	Flow      string
-	Case      string
-	Platform  string
+	Version   string `json:"version"`
+	Shortname string `json:"shortname"`
+	Flow      string `json:"flow"`
+	Case      string `json:"case,omitempty"`
+	Platform  string `json:"platform,omitempty"`
 }
 
 func (c matrixCell) empty() bool {
@@ -57,6 +59,9 @@ type failureRecord struct {
 	Reason         string
 	DiagnosticsDir string
 	Signals        []string
+	// Cause is the matching failure-signature catalogue entry, nil when the
+	// log matched none.
+	Cause *failures.Match
 }
 
 // ghJob is the subset of the GitHub Actions job object we consume.
@@ -65,7 +70,12 @@ type ghJob struct {
 	Name       string `json:"name"`
 	Status     string `json:"status"`
 	Conclusion string `json:"conclusion"`
-	Steps      []struct {
+	HTMLURL    string `json:"html_url,omitempty"`
+	// RunAttempt, StartedAt and CompletedAt are only consumed by `triage history`.
+	RunAttempt  int       `json:"run_attempt,omitempty"`
+	StartedAt   time.Time `json:"started_at"`
+	CompletedAt time.Time `json:"completed_at"`
+	Steps       []struct {
 		Name       string `json:"name"`
 		Conclusion string `json:"conclusion"`
 	} `json:"steps"`
@@ -182,6 +192,7 @@ Requires the GitHub CLI (gh) to be installed and authenticated.`,
 	f.BoolVar(&showLog, "show-log", false, "Also print the denoised job log after the summary")
 	f.StringVarP(&flags.LogLevel, "log-level", "l", "info", "Log level")
 
+	cmd.AddCommand(newTriageHistoryCommand())
 	return cmd
 }
 
@@ -374,6 +385,9 @@ func extractFailureRecord(rawLog string) failureRecord {
 	}
 	rec.Reason = extractReason(log)
 	rec.Signals = collectSignals(log)
+	if m, ok := failures.Classify(failures.SourceLog, stripLogNoise(log)); ok {
+		rec.Cause = &m
+	}
 	return rec
 }
 
@@ -544,6 +558,14 @@ func renderTriageReport(ref runRef, rec failureRecord) string {
 	if len(rec.Signals) > 0 {
 		fmt.Fprintf(&b, "Signals:      %s\n", strings.Join(rec.Signals, ", "))
 	}
+	if rec.Cause != nil {
+		retry := "not retryable"
+		if rec.Cause.Retryable {
+			retry = "retryable"
+		}
+		fmt.Fprintf(&b, "Cause:        %s (%s, %s)\n", rec.Cause.ID, rec.Cause.Category, retry)
+		fmt.Fprintf(&b, "Remediation:  %s\n", rec.Cause.Remediation)
+	}
 	if rec.HelmCommand != "" {
 		fmt.Fprintf(&b, "Helm command: %s\n", rec.HelmCommand)
 	}
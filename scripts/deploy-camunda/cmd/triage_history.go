@@ -0,0 +1,782 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"scripts/camunda-core/pkg/logging"
+
+	"github.com/spf13/cobra"
+)
+
+const defaultHistoryWorkflow = "test-chart-version.yaml"
+
+// ghRun is the subset of the GitHub Actions workflow run object we consume.
+type ghRun struct {
+	ID         int64     `json:"id"`
+	HeadSHA    string    `json:"head_sha"`
+	HeadBranch string    `json:"head_branch"`
+	Event      string    `json:"event"`
+	RunAttempt int       `json:"run_attempt"`
+	CreatedAt  time.Time `json:"created_at"`
+	HTMLURL    string    `json:"html_url"`
+}
+
+type ghRunsResponse struct {
+	TotalCount   int     `json:"total_count"`
+	WorkflowRuns []ghRun `json:"workflow_runs"`
+}
+
+// historyOptions selects the runs `triage history` ingests.
+type historyOptions struct {
+	Owner    string
+	Repo     string
+	Workflow string
+	Branch   string
+	Since    time.Time
+	Limit    int
+	CacheDir string
+	Offline  bool
+}
+
+// historyRun is one ingested workflow run with all jobs of all its attempts and
+// the raw logs of the failed matrix jobs.
+type historyRun struct {
+	Run  ghRun
+	Jobs []ghJob
+	Logs map[int64]string
+}
+
+// cellAttempt is the outcome of one matrix cell in one attempt of a run. A cell
+// spans several jobs (the caller job plus the nested reusable-workflow jobs); it
+// failed when any of them failed.
+type cellAttempt struct {
+	Cell       matrixCell
+	RunID      int64
+	RunAttempt int
+	HeadSHA    string
+	Failed     bool
+	// TimeToFailure runs from the first job start of the cell to the completion
+	// of its failed job.
+	TimeToFailure time.Duration
+	FailingStep   string
+	Reason        string
+	Signature     string
+	// Category is the failure-signature catalogue category, "" when the log
+	// matched none.
+	Category string
+	JobURL   string
+}
+
+// scenarioStats aggregates the attempts of one matrix cell.
+type scenarioStats struct {
+	Cell          matrixCell     `json:"cell"`
+	Attempts      int            `json:"attempts"`
+	Failures      int            `json:"failures"`
+	FlakyFailures int            `json:"flaky_failures"`
+	FailureRate   float64        `json:"failure_rate"`
+	FlakeRate     float64        `json:"flake_rate"`
+	MTTFSeconds   float64        `json:"mttf_seconds,omitempty"`
+	FailingSteps  map[string]int `json:"failing_steps,omitempty"`
+	TopSignature  string         `json:"top_signature,omitempty"`
+	LastFailure   string         `json:"last_failure,omitempty"`
+}
+
+// failureCluster groups failures sharing a normalised error signature.
+type failureCluster struct {
+	Signature   string       `json:"signature"`
+	Category    string       `json:"category,omitempty"`
+	FailingStep string       `json:"failing_step"`
+	Example     string       `json:"example"`
+	Count       int          `json:"count"`
+	Flaky       int          `json:"flaky"`
+	Cells       []matrixCell `json:"cells"`
+	LastFailure string       `json:"last_failure,omitempty"`
+}
+
+// historyReport is the ranked flakiness report.
+type historyReport struct {
+	Repo      string           `json:"repo"`
+	Workflow  string           `json:"workflow"`
+	Runs      int              `json:"runs"`
+	From      time.Time        `json:"from"`
+	To        time.Time        `json:"to"`
+	Scenarios []scenarioStats  `json:"scenarios"`
+	Clusters  []failureCluster `json:"clusters"`
+}
+
+// newTriageHistoryCommand creates `triage history`: ingest many runs of the
+// integration workflow and rank the matrix cells by flakiness, so the scenarios
+// worth stabilising first stand out.
+func newTriageHistoryCommand() *cobra.Command {
+	var (
+		opts      historyOptions
+		repoFlag  string
+		sinceFlag string
+		format    string
+		output    string
+		logLevel  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "history",
+		Short: "Rank integration scenarios by flakiness across many runs",
+		Long: `Ingest the completed runs of the integration workflow and aggregate their
+matrix cells:
+
+  - per cell: attempts, failures, failure rate, flaky failures, flake rate
+    and mean time to failure (MTTF),
+  - failures clustered by failing step and normalised error signature
+    (timestamps, hashes, pod names, IPs and numbers are masked).
+
+A failure counts as flaky when the same cell also passed on the same commit,
+either in a re-run attempt or in another run. Scenarios are ranked by flaky
+failures, then by failure rate.
+
+Runs are read through ` + "`gh api`" + `, including every re-run attempt. With
+--cache-dir, runs, job lists and failed job logs are stored as
+
+  <cache-dir>/<run-id>/run.json
+  <cache-dir>/<run-id>/jobs.json
+  <cache-dir>/<run-id>/logs/<job-id>.log
+
+and reused on the next invocation; --offline builds the report from the cache
+alone (GitHub deletes job logs after the retention period).`,
+		Args:          cobra.NoArgs,
+		SilenceUsage:  true,
+		SilenceErrors: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+
+			if err := logging.Setup(logging.Options{
+				LevelString:  logLevel,
+				ColorEnabled: logging.IsTerminal(os.Stderr.Fd()),
+			}); err != nil {
+				return err
+			}
+
+			if format != "markdown" && format != "json" {
+				return fmt.Errorf("--format must be markdown or json, got %q", format)
+			}
+			if repoFlag == "" {
+				repoFlag = defaultTriageRepo
+			}
+			owner, repo, ok := splitOwnerRepo(repoFlag)
+			if !ok {
+				return fmt.Errorf("--repo must be owner/repo, got %q", repoFlag)
+			}
+			opts.Owner, opts.Repo = owner, repo
+			if sinceFlag != "" {
+				since, err := time.Parse("2006-01-02", sinceFlag)
+				if err != nil {
+					return fmt.Errorf("--since must be a date (YYYY-MM-DD), got %q", sinceFlag)
+				}
+				opts.Since = since
+			}
+			if opts.Offline && opts.CacheDir == "" {
+				return fmt.Errorf("--offline requires --cache-dir")
+			}
+
+			runs, err := loadHistory(ctx, opts)
+			if err != nil {
+				return err
+			}
+			if len(runs) == 0 {
+				return fmt.Errorf("no completed runs of %s found", opts.Workflow)
+			}
+
+			report := buildHistoryReport(runs)
+			report.Repo = opts.Owner + "/" + opts.Repo
+			report.Workflow = opts.Workflow
+
+			var out []byte
+			if format == "json" {
+				out, err = json.MarshalIndent(report, "", "  ")
+				if err != nil {
+					return fmt.Errorf("marshal report: %w", err)
+				}
+				out = append(out, '\n')
+			} else {
+				out = []byte(renderHistoryMarkdown(report))
+			}
+			if output == "" {
+				_, err = os.Stdout.Write(out)
+				return err
+			}
+			if err := os.WriteFile(output, out, 0o644); err != nil {
+				return fmt.Errorf("write report: %w", err)
+			}
+			logging.Logger.Info().Str("path", output).Int("runs", report.Runs).Msg("Wrote flakiness report")
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&repoFlag, "repo", "", "owner/repo (default "+defaultTriageRepo+")")
+	f.StringVar(&opts.Workflow, "workflow", defaultHistoryWorkflow, "Workflow file name or ID whose runs are ingested")
+	f.StringVar(&opts.Branch, "branch", "", "Only ingest runs of this branch")
+	f.StringVar(&sinceFlag, "since", "", "Only ingest runs created on or after this date (YYYY-MM-DD)")
+	f.IntVar(&opts.Limit, "limit", 50, "Maximum number of runs to ingest (most recent first)")
+	f.StringVar(&opts.CacheDir, "cache-dir", "", "Directory caching runs, job lists and failed job logs")
+	f.BoolVar(&opts.Offline, "offline", false, "Only read runs from --cache-dir, without calling the GitHub API")
+	f.StringVar(&format, "format", "markdown", "Report format: markdown, json")
+	f.StringVarP(&output, "output", "o", "", "Write the report to this file instead of stdout")
+	f.StringVarP(&logLevel, "log-level", "l", "info", "Log level")
+	_ = cmd.RegisterFlagCompletionFunc("format", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterByPrefix([]string{"markdown", "json"}, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+
+	return cmd
+}
+
+// loadHistory returns the runs selected by opts, most recent first. Completed
+// runs only change when they are re-run, so cached job lists are reused unless
+// the run gained an attempt.
+func loadHistory(ctx context.Context, opts historyOptions) ([]historyRun, error) {
+	var runs []ghRun
+	var err error
+	if opts.Offline {
+		runs, err = cachedRuns(opts.CacheDir)
+	} else {
+		runs, err = listRuns(ctx, opts)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	runs = filterRuns(runs, opts)
+	history := make([]historyRun, 0, len(runs))
+	for _, run := range runs {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		h, err := loadRun(ctx, opts, run)
+		if err != nil {
+			return nil, err
+		}
+		history = append(history, h)
+	}
+	return history, nil
+}
+
+// filterRuns applies --branch, --since and --limit to runs and orders them
+// most recent first.
+func filterRuns(runs []ghRun, opts historyOptions) []ghRun {
+	sort.SliceStable(runs, func(i, j int) bool { return runs[i].CreatedAt.After(runs[j].CreatedAt) })
+	var out []ghRun
+	for _, run := range runs {
+		if opts.Branch != "" && run.HeadBranch != opts.Branch {
+			continue
+		}
+		if !opts.Since.IsZero() && run.CreatedAt.Before(opts.Since) {
+			continue
+		}
+		if opts.Limit > 0 && len(out) >= opts.Limit {
+			break
+		}
+		out = append(out, run)
+	}
+	return out
+}
+
+// listRuns pages through the completed runs of the workflow until opts.Limit
+// runs are collected.
+func listRuns(ctx context.Context, opts historyOptions) ([]ghRun, error) {
+	var runs []ghRun
+	for page := 1; ; page++ {
+		query := url.Values{
+			"status":   {"completed"},
+			"per_page": {"100"},
+			"page":     {strconv.Itoa(page)},
+		}
+		if opts.Branch != "" {
+			query.Set("branch", opts.Branch)
+		}
+		if !opts.Since.IsZero() {
+			query.Set("created", ">="+opts.Since.Format("2006-01-02"))
+		}
+		out, err := ghAPI(ctx, []string{
+			fmt.Sprintf("/repos/%s/%s/actions/workflows/%s/runs?%s", opts.Owner, opts.Repo, opts.Workflow, query.Encode()),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list runs of %s: %w (is `gh` installed and authenticated?)", opts.Workflow, err)
+		}
+		var resp ghRunsResponse
+		if err := json.Unmarshal(out, &resp); err != nil {
+			return nil, fmt.Errorf("parse runs response: %w", err)
+		}
+		runs = append(runs, resp.WorkflowRuns...)
+		if len(resp.WorkflowRuns) == 0 || len(runs) >= resp.TotalCount || (opts.Limit > 0 && len(runs) >= opts.Limit) {
+			return runs, nil
+		}
+	}
+}
+
+// listAllJobs pages through the jobs of every attempt of a run.
+func listAllJobs(ctx context.Context, owner, repo string, runID int64) ([]ghJob, error) {
+	var jobs []ghJob
+	for page := 1; ; page++ {
+		out, err := ghAPI(ctx, []string{
+			fmt.Sprintf("/repos/%s/%s/actions/runs/%d/jobs?filter=all&per_page=100&page=%d", owner, repo, runID, page),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("list jobs for run %d: %w", runID, err)
+		}
+		var resp ghJobsResponse
+		if err := json.Unmarshal(out, &resp); err != nil {
+			return nil, fmt.Errorf("parse jobs response: %w", err)
+		}
+		jobs = append(jobs, resp.Jobs...)
+		if len(resp.Jobs) == 0 || len(jobs) >= resp.TotalCount {
+			return jobs, nil
+		}
+	}
+}
+
+// loadRun returns the jobs and failed matrix job logs of run, from the cache
+// when possible.
+func loadRun(ctx context.Context, opts historyOptions, run ghRun) (historyRun, error) {
+	h := historyRun{Run: run, Logs: map[int64]string{}}
+	runDir := ""
+	if opts.CacheDir != "" {
+		runDir = filepath.Join(opts.CacheDir, strconv.FormatInt(run.ID, 10))
+	}
+
+	cached, hasCache := readCachedRun(runDir)
+	if hasCache && cached.Run.RunAttempt >= run.RunAttempt {
+		h.Jobs = cached.Jobs
+	} else if opts.Offline {
+		return h, fmt.Errorf("run %d is not cached in %s", run.ID, opts.CacheDir)
+	} else {
+		jobs, err := listAllJobs(ctx, opts.Owner, opts.Repo, run.ID)
+		if err != nil {
+			return h, err
+		}
+		h.Jobs = jobs
+		if runDir != "" {
+			if err := writeCachedRun(runDir, run, jobs); err != nil {
+				return h, err
+			}
+		}
+	}
+
+	for _, job := range h.Jobs {
+		if !failedConclusions[job.Conclusion] || parseMatrixCell(job.Name).empty() {
+			continue
+		}
+		logPath := ""
+		if runDir != "" {
+			logPath = filepath.Join(runDir, "logs", strconv.FormatInt(job.ID, 10)+".log")
+			if data, err := os.ReadFile(logPath); err == nil {
+				h.Logs[job.ID] = string(data)
+				continue
+			}
+		}
+		if opts.Offline {
+			continue
+		}
+		out, err := ghAPI(ctx, []string{
+			fmt.Sprintf("/repos/%s/%s/actions/jobs/%d/logs", opts.Owner, opts.Repo, job.ID),
+		})
+		if err != nil {
+			// Logs expire before runs do; the failure still counts, just without a
+			// reason to cluster on.
+			logging.Logger.Warn().Err(err).Int64("job", job.ID).Msg("Failed to fetch job log")
+			continue
+		}
+		h.Logs[job.ID] = string(out)
+		if logPath != "" {
+			if err := os.MkdirAll(filepath.Dir(logPath), 0o755); err != nil {
+				return h, fmt.Errorf("create cache dir: %w", err)
+			}
+			if err := os.WriteFile(logPath, out, 0o644); err != nil {
+				return h, fmt.Errorf("cache job log: %w", err)
+			}
+		}
+	}
+	return h, nil
+}
+
+func readCachedRun(runDir string) (historyRun, bool) {
+	if runDir == "" {
+		return historyRun{}, false
+	}
+	var h historyRun
+	runData, err := os.ReadFile(filepath.Join(runDir, "run.json"))
+	if err != nil || json.Unmarshal(runData, &h.Run) != nil {
+		return historyRun{}, false
+	}
+	jobsData, err := os.ReadFile(filepath.Join(runDir, "jobs.json"))
+	if err != nil || json.Unmarshal(jobsData, &h.Jobs) != nil {
+		return historyRun{}, false
+	}
+	return h, true
+}
+
+func writeCachedRun(runDir string, run ghRun, jobs []ghJob) error {
+	if err := os.MkdirAll(runDir, 0o755); err != nil {
+		return fmt.Errorf("create cache dir: %w", err)
+	}
+	// jobs.json goes first: run.json marks the cache entry complete.
+	for _, file := range []struct {
+		name string
+		v    any
+	}{{"jobs.json", jobs}, {"run.json", run}} {
+		data, err := json.MarshalIndent(file.v, "", "  ")
+		if err != nil {
+			return fmt.Errorf("marshal %s: %w", file.name, err)
+		}
+		if err := os.WriteFile(filepath.Join(runDir, file.name), data, 0o644); err != nil {
+			return fmt.Errorf("cache %s: %w", file.name, err)
+		}
+	}
+	return nil
+}
+
+// cachedRuns lists the runs stored in cacheDir.
+func cachedRuns(cacheDir string) ([]ghRun, error) {
+	entries, err := os.ReadDir(cacheDir)
+	if err != nil {
+		return nil, fmt.Errorf("read cache dir: %w", err)
+	}
+	var runs []ghRun
+	for _, e := range entries {
+		if !e.IsDir() || !isAllDigits(e.Name()) {
+			continue
+		}
+		if h, ok := readCachedRun(filepath.Join(cacheDir, e.Name())); ok {
+			runs = append(runs, h.Run)
+		}
+	}
+	return runs, nil
+}
+
+// cellAttempts folds the jobs of a run into one outcome per matrix cell and
+// attempt.
+func cellAttempts(h historyRun) []cellAttempt {
+	type key struct {
+		cell    matrixCell
+		attempt int
+	}
+	var order []key
+	byKey := map[key]*cellAttempt{}
+	starts := map[key]time.Time{}
+	failedAt := map[key]time.Time{}
+
+	for _, job := range h.Jobs {
+		cell := parseMatrixCell(job.Name)
+		if cell.empty() || job.Conclusion == "" || job.Conclusion == "skipped" || job.Conclusion == "cancelled" {
+			continue
+		}
+		attempt := job.RunAttempt
+		if attempt == 0 {
+			attempt = 1
+		}
+		k := key{cell, attempt}
+		a, ok := byKey[k]
+		if !ok {
+			a = &cellAttempt{Cell: cell, RunID: h.Run.ID, RunAttempt: attempt, HeadSHA: h.Run.HeadSHA}
+			byKey[k] = a
+			order = append(order, k)
+		}
+		if s, ok := starts[k]; !job.StartedAt.IsZero() && (!ok || job.StartedAt.Before(s)) {
+			starts[k] = job.StartedAt
+		}
+		if !failedConclusions[job.Conclusion] || a.Failed {
+			continue
+		}
+
+		a.Failed = true
+		a.JobURL = job.HTMLURL
+		a.FailingStep = firstFailedStep(job)
+		if log, ok := h.Logs[job.ID]; ok {
+			rec := extractFailureRecord(log)
+			a.Reason = rec.Reason
+			if a.Reason == "" && len(rec.Signals) > 0 {
+				a.Reason = strings.Join(rec.Signals, ", ")
+			}
+			if rec.Cause != nil {
+				a.Category = string(rec.Cause.Category)
+			}
+		}
+		a.Signature = failureSignature(a.FailingStep, a.Reason)
+		failedAt[k] = job.CompletedAt
+	}
+
+	out := make([]cellAttempt, 0, len(order))
+	for _, k := range order {
+		a := byKey[k]
+		// Measure from the first job of the cell, which may be an earlier setup
+		// job than the one that failed.
+		if end, start := failedAt[k], starts[k]; !end.IsZero() && !start.IsZero() {
+			a.TimeToFailure = end.Sub(start)
+		}
+		out = append(out, *a)
+	}
+	return out
+}
+
+var (
+	sigTimestampRe = regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?Z?`)
+	sigUUIDRe      = regexp.MustCompile(`(?i)\b[0-9a-f]{8}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{4}-[0-9a-f]{12}\b`)
+	sigIPRe        = regexp.MustCompile(`\b\d{1,3}(?:\.\d{1,3}){3}(?::\d+)?\b`)
+	// sigPodRe matches the ReplicaSet and pod hash suffixes of generated names,
+	// e.g. "zeebe-gateway-7d9f8c6b5-x2kqp".
+	sigPodRe = regexp.MustCompile(`-[a-z0-9]{8,10}-[a-z0-9]{5}\b`)
+	// sigHexRe matches commit SHAs, digests and other hex identifiers.
+	sigHexRe    = regexp.MustCompile(`\b[0-9a-f]{7,64}\b`)
+	sigNumberRe = regexp.MustCompile(`\d+`)
+)
+
+// normalizeReason masks the run-specific parts of an error line so the same
+// failure from different runs yields the same text.
+func normalizeReason(reason string) string {
+	s := stripTimestamp(stripANSI(reason))
+	s = sigTimestampRe.ReplaceAllString(s, "<ts>")
+	s = sigUUIDRe.ReplaceAllString(s, "<uuid>")
+	s = sigIPRe.ReplaceAllString(s, "<ip>")
+	s = sigPodRe.ReplaceAllString(s, "-<pod>")
+	s = sigHexRe.ReplaceAllStringFunc(s, func(m string) string {
+		if strings.ContainsAny(m, "0123456789") {
+			return "<hex>"
+		}
+		return m
+	})
+	s = sigNumberRe.ReplaceAllString(s, "N")
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) > 160 {
+		s = s[:160] + "…"
+	}
+	return s
+}
+
+// failureSignature identifies a failure by its failing step and normalised
+// reason.
+func failureSignature(step, reason string) string {
+	if step == "" {
+		step = "(unknown step)"
+	}
+	norm := normalizeReason(reason)
+	if norm == "" {
+		norm = "(no error line found)"
+	}
+	return step + ": " + norm
+}
+
+// buildHistoryReport aggregates the runs into the ranked report.
+func buildHistoryReport(runs []historyRun) historyReport {
+	report := historyReport{Runs: len(runs)}
+	var attempts []cellAttempt
+	for _, h := range runs {
+		if report.From.IsZero() || h.Run.CreatedAt.Before(report.From) {
+			report.From = h.Run.CreatedAt
+		}
+		if h.Run.CreatedAt.After(report.To) {
+			report.To = h.Run.CreatedAt
+		}
+		attempts = append(attempts, cellAttempts(h)...)
+	}
+
+	// A cell that both failed and passed on the same commit is flaky there.
+	type commitKey struct {
+		cell matrixCell
+		sha  string
+	}
+	passed := map[commitKey]bool{}
+	for _, a := range attempts {
+		if !a.Failed {
+			passed[commitKey{a.Cell, a.HeadSHA}] = true
+		}
+	}
+
+	stats := map[matrixCell]*scenarioStats{}
+	signatures := map[matrixCell]map[string]int{}
+	ttf := map[matrixCell][]time.Duration{}
+	clusters := map[string]*failureCluster{}
+	for _, a := range attempts {
+		s, ok := stats[a.Cell]
+		if !ok {
+			s = &scenarioStats{Cell: a.Cell}
+			stats[a.Cell] = s
+			signatures[a.Cell] = map[string]int{}
+		}
+		s.Attempts++
+		if !a.Failed {
+			continue
+		}
+		flaky := passed[commitKey{a.Cell, a.HeadSHA}]
+		s.Failures++
+		if flaky {
+			s.FlakyFailures++
+		}
+		if a.FailingStep != "" {
+			if s.FailingSteps == nil {
+				s.FailingSteps = map[string]int{}
+			}
+			s.FailingSteps[a.FailingStep]++
+		}
+		signatures[a.Cell][a.Signature]++
+		if a.TimeToFailure > 0 {
+			ttf[a.Cell] = append(ttf[a.Cell], a.TimeToFailure)
+		}
+		// Runs are ingested most recent first.
+		if s.LastFailure == "" {
+			s.LastFailure = a.JobURL
+		}
+
+		c, ok := clusters[a.Signature]
+		if !ok {
+			c = &failureCluster{Signature: a.Signature, Category: a.Category, FailingStep: a.FailingStep, Example: a.Reason, LastFailure: a.JobURL}
+			clusters[a.Signature] = c
+		}
+		c.Count++
+		if flaky {
+			c.Flaky++
+		}
+		if !containsCell(c.Cells, a.Cell) {
+			c.Cells = append(c.Cells, a.Cell)
+		}
+	}
+
+	for cell, s := range stats {
+		s.FailureRate = float64(s.Failures) / float64(s.Attempts)
+		s.FlakeRate = float64(s.FlakyFailures) / float64(s.Attempts)
+		if d := ttf[cell]; len(d) > 0 {
+			var total time.Duration
+			for _, v := range d {
+				total += v
+			}
+			s.MTTFSeconds = (total / time.Duration(len(d))).Seconds()
+		}
+		s.TopSignature = topKey(signatures[cell])
+		report.Scenarios = append(report.Scenarios, *s)
+	}
+	sort.Slice(report.Scenarios, func(i, j int) bool {
+		a, b := report.Scenarios[i], report.Scenarios[j]
+		if a.FlakyFailures != b.FlakyFailures {
+			return a.FlakyFailures > b.FlakyFailures
+		}
+		if a.FailureRate != b.FailureRate {
+			return a.FailureRate > b.FailureRate
+		}
+		return cellLabel(a.Cell) < cellLabel(b.Cell)
+	})
+
+	for _, c := range clusters {
+		sort.Slice(c.Cells, func(i, j int) bool { return cellLabel(c.Cells[i]) < cellLabel(c.Cells[j]) })
+		report.Clusters = append(report.Clusters, *c)
+	}
+	sort.Slice(report.Clusters, func(i, j int) bool {
+		a, b := report.Clusters[i], report.Clusters[j]
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.Signature < b.Signature
+	})
+	return report
+}
+
+func containsCell(cells []matrixCell, cell matrixCell) bool {
+	for _, c := range cells {
+		if c == cell {
+			return true
+		}
+	}
+	return false
+}
+
+// topKey returns the key with the highest count, breaking ties by name.
+func topKey(counts map[string]int) string {
+	best := ""
+	for k, n := range counts {
+		if best == "" || n > counts[best] || (n == counts[best] && k < best) {
+			best = k
+		}
+	}
+	return best
+}
+
+// cellLabel renders a cell the way job names do, e.g. "8.9 - eske - install - pr - gke".
+func cellLabel(c matrixCell) string {
+	parts := []string{c.Version, c.Shortname, c.Flow}
+	for _, p := range []string{c.Case, c.Platform} {
+		if p != "" {
+			parts = append(parts, p)
+		}
+	}
+	return strings.Join(parts, " - ")
+}
+
+// renderHistoryMarkdown formats the report for a PR comment or step summary.
+func renderHistoryMarkdown(r historyReport) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Integration flakiness report\n\n")
+	fmt.Fprintf(&b, "%s `%s`: %d runs", r.Repo, r.Workflow, r.Runs)
+	if !r.From.IsZero() {
+		fmt.Fprintf(&b, " from %s to %s", r.From.Format("2006-01-02"), r.To.Format("2006-01-02"))
+	}
+	b.WriteString(".\n\n")
+
+	b.WriteString("## Scenarios\n\n")
+	failing := 0
+	for _, s := range r.Scenarios {
+		if s.Failures > 0 {
+			failing++
+		}
+	}
+	if failing == 0 {
+		b.WriteString("No matrix cell failed.\n\n")
+	} else {
+		b.WriteString("| Scenario | Attempts | Failures | Flaky | Failure rate | Flake rate | MTTF | Top signature |\n")
+		b.WriteString("|---|---:|---:|---:|---:|---:|---:|---|\n")
+		for _, s := range r.Scenarios {
+			if s.Failures == 0 {
+				continue
+			}
+			mttf := "-"
+			if s.MTTFSeconds > 0 {
+				mttf = (time.Duration(s.MTTFSeconds) * time.Second).Round(time.Second).String()
+			}
+			fmt.Fprintf(&b, "| %s | %d | %d | %d | %.0f%% | %.0f%% | %s | %s |\n",
+				markdownCell(cellLabel(s.Cell)), s.Attempts, s.Failures, s.FlakyFailures,
+				100*s.FailureRate, 100*s.FlakeRate, mttf, markdownCell(s.TopSignature))
+		}
+		fmt.Fprintf(&b, "\n%d of %d scenarios never failed.\n\n", len(r.Scenarios)-failing, len(r.Scenarios))
+	}
+
+	if len(r.Clusters) > 0 {
+		b.WriteString("## Failure signatures\n\n")
+		b.WriteString("| # | Failures | Flaky | Category | Signature | Scenarios |\n")
+		b.WriteString("|---:|---:|---:|---|---|---|\n")
+		for i, c := range r.Clusters {
+			labels := make([]string, len(c.Cells))
+			for j, cell := range c.Cells {
+				labels[j] = cellLabel(cell)
+			}
+			category := c.Category
+			if category == "" {
+				category = "-"
+			}
+			fmt.Fprintf(&b, "| %d | %d | %d | %s | %s | %s |\n", i+1, c.Count, c.Flaky, category,
+				markdownCell(c.Signature), markdownCell(strings.Join(labels, "<br>")))
+		}
+	}
+	return b.String()
+}
+
+// markdownCell escapes the characters that would break a table cell.
+func markdownCell(s string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(s, "|", `\|`), "\n", " ")
+}
@@ -0,0 +1,234 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestNormalizeReason(t *testing.T) {
+	t.Parallel()
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{
+			name: "timestamps and durations",
+			a:    "2026-03-01T10:00:00.123Z Error: timed out waiting for the condition after 600s",
+			b:    "2026-03-09T22:41:07.9Z Error: timed out waiting for the condition after 1200s",
+		},
+		{
+			name: "pod names",
+			a:    `pod "integration-zeebe-gateway-7d9f8c6b5-x2kqp" is CrashLoopBackOff`,
+			b:    `pod "integration-zeebe-gateway-5b8c9d7f4d-q8wzz" is CrashLoopBackOff`,
+		},
+		{
+			name: "ips and digests",
+			a:    "dial tcp 10.12.0.4:26500: connect: connection refused (image sha256:3f2a9c0d1e)",
+			b:    "dial tcp 10.12.3.17:26500: connect: connection refused (image sha256:aa01bc99ef)",
+		},
+		{
+			name: "uuids",
+			a:    "process instance 0f8fad5b-d9cb-469f-a165-70867728950e not found",
+			b:    "process instance 7c9e6679-7425-40de-944b-e07fc1f90ae7 not found",
+		},
+	}
+	for _, tc := range tests {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			if a, b := normalizeReason(tc.a), normalizeReason(tc.b); a != b {
+				t.Errorf("expected equal signatures:\n  %q\n  %q", a, b)
+			}
+		})
+	}
+
+	if a, b := normalizeReason("UPGRADE FAILED: timed out"), normalizeReason("INSTALLATION FAILED: timed out"); a == b {
+		t.Errorf("different errors must not share a signature, both %q", a)
+	}
+	if got := failureSignature("", ""); got != "(unknown step): (no error line found)" {
+		t.Errorf("failureSignature of an empty failure = %q", got)
+	}
+}
+
+// historyJobs decodes a jobs.json-style fixture.
+func historyJobs(t *testing.T, raw string) []ghJob {
+	t.Helper()
+	var jobs []ghJob
+	if err := json.Unmarshal([]byte(raw), &jobs); err != nil {
+		t.Fatalf("decode jobs fixture: %v", err)
+	}
+	return jobs
+}
+
+func TestBuildHistoryReport(t *testing.T) {
+	t.Parallel()
+	day := func(d int) time.Time { return time.Date(2026, 3, d, 0, 0, 0, 0, time.UTC) }
+
+	runs := []historyRun{
+		{
+			// eske fails on the first attempt and passes on the re-run: flaky.
+			Run: ghRun{ID: 3, HeadSHA: "ccc", CreatedAt: day(3)},
+			Jobs: historyJobs(t, `[
+				{"id":31,"name":"8.9 - eske - install - pr - gke","conclusion":"failure","run_attempt":1,
+				 "html_url":"https://github.com/o/r/actions/runs/3/job/31",
+				 "started_at":"2026-03-03T10:00:00Z","completed_at":"2026-03-03T10:20:00Z",
+				 "steps":[{"name":"Setup","conclusion":"success"},{"name":"Deploy","conclusion":"failure"}]},
+				{"id":32,"name":"8.9 - eske - install - pr - gke","conclusion":"success","run_attempt":2},
+				{"id":33,"name":"8.9 - oske - install - pr - gke","conclusion":"success","run_attempt":1},
+				{"id":34,"name":"CI Gate","conclusion":"failure","run_attempt":1}
+			]`),
+			Logs: map[int64]string{31: "2026-03-03T10:19:59.1Z Error: pod \"zeebe-7d9f8c6b5-x2kqp\" CrashLoopBackOff after 600s\n"},
+		},
+		{
+			Run: ghRun{ID: 2, HeadSHA: "bbb", CreatedAt: day(2)},
+			Jobs: historyJobs(t, `[
+				{"id":21,"name":"8.9 - eske - install - pr - gke","conclusion":"success","run_attempt":1},
+				{"id":22,"name":"8.9 - oske - install - pr - gke / gke - ITs / setup","conclusion":"success","run_attempt":1,
+				 "started_at":"2026-03-02T10:00:00Z","completed_at":"2026-03-02T10:05:00Z"},
+				{"id":23,"name":"8.9 - oske - install - pr - gke / gke - ITs / deploy","conclusion":"failure","run_attempt":1,
+				 "html_url":"https://github.com/o/r/actions/runs/2/job/23",
+				 "started_at":"2026-03-02T10:05:00Z","completed_at":"2026-03-02T10:35:00Z",
+				 "steps":[{"name":"Deploy","conclusion":"failure"}]}
+			]`),
+			Logs: map[int64]string{23: "2026-03-02T10:34:00.5Z Error: pod \"zeebe-5b8c9d7f4d-q8wzz\" CrashLoopBackOff after 900s\n"},
+		},
+		{
+			// A failed job whose log has expired still counts.
+			Run: ghRun{ID: 1, HeadSHA: "aaa", CreatedAt: day(1)},
+			Jobs: historyJobs(t, `[
+				{"id":11,"name":"8.9 - oske - install - pr - gke","conclusion":"timed_out","run_attempt":1,
+				 "steps":[{"name":"Tests","conclusion":"timed_out"}]},
+				{"id":12,"name":"8.9 - eske - install - pr - gke","conclusion":"cancelled","run_attempt":1}
+			]`),
+		},
+	}
+
+	report := buildHistoryReport(runs)
+
+	if report.Runs != 3 || !report.From.Equal(day(1)) || !report.To.Equal(day(3)) {
+		t.Errorf("unexpected run window: runs=%d from=%s to=%s", report.Runs, report.From, report.To)
+	}
+	if len(report.Scenarios) != 2 {
+		t.Fatalf("expected the two matrix cells, got %+v", report.Scenarios)
+	}
+
+	eske, oske := report.Scenarios[0], report.Scenarios[1]
+	if eske.Cell.Shortname != "eske" {
+		t.Fatalf("expected the flaky eske cell ranked first, got %s", cellLabel(eske.Cell))
+	}
+	// Cancelled jobs are not attempts.
+	if eske.Attempts != 3 || eske.Failures != 1 || eske.FlakyFailures != 1 {
+		t.Errorf("eske: attempts=%d failures=%d flaky=%d, want 3/1/1", eske.Attempts, eske.Failures, eske.FlakyFailures)
+	}
+	if eske.MTTFSeconds != 1200 {
+		t.Errorf("eske MTTF = %vs, want 1200s", eske.MTTFSeconds)
+	}
+	if eske.LastFailure != "https://github.com/o/r/actions/runs/3/job/31" {
+		t.Errorf("eske last failure = %q", eske.LastFailure)
+	}
+
+	// The nested ITs jobs fold into one attempt per run.
+	if oske.Attempts != 3 || oske.Failures != 2 || oske.FlakyFailures != 0 {
+		t.Errorf("oske: attempts=%d failures=%d flaky=%d, want 3/2/0", oske.Attempts, oske.Failures, oske.FlakyFailures)
+	}
+	// Measured from the setup job: 35 minutes; the other failure has no timing.
+	if oske.MTTFSeconds != 35*60 {
+		t.Errorf("oske MTTF = %vs, want 2100s", oske.MTTFSeconds)
+	}
+
+	if len(report.Clusters) != 2 {
+		t.Fatalf("expected two clusters, got %+v", report.Clusters)
+	}
+	top := report.Clusters[0]
+	if top.Count != 2 || top.Flaky != 1 || len(top.Cells) != 2 || !strings.HasPrefix(top.Signature, "Deploy: ") || top.Category != "chart-bug" {
+		t.Errorf("expected the CrashLoopBackOff failures of both cells in one cluster, got %+v", top)
+	}
+	if got := report.Clusters[1].Signature; got != "Tests: (no error line found)" {
+		t.Errorf("second cluster = %q", got)
+	}
+
+	md := renderHistoryMarkdown(report)
+	for _, want := range []string{"| 8.9 - eske - install - pr - gke | 3 | 1 | 1 | 33% | 33% | 20m0s |", "## Failure signatures"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown report missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestLoadHistory(t *testing.T) {
+	cacheDir := t.TempDir()
+	opts := historyOptions{Owner: "o", Repo: "r", Workflow: "test-chart-version.yaml", Limit: 1, CacheDir: cacheDir}
+
+	orig := ghAPI
+	defer func() { ghAPI = orig }()
+	var calls []string
+	ghAPI = func(_ context.Context, args []string) ([]byte, error) {
+		arg := args[len(args)-1]
+		calls = append(calls, arg)
+		switch {
+		case strings.Contains(arg, "/workflows/test-chart-version.yaml/runs?"):
+			return []byte(`{"total_count":2,"workflow_runs":[
+				{"id":7,"head_sha":"aaa","run_attempt":1,"created_at":"2026-03-02T00:00:00Z"},
+				{"id":6,"head_sha":"bbb","run_attempt":1,"created_at":"2026-03-01T00:00:00Z"}]}`), nil
+		case strings.Contains(arg, "/runs/7/jobs?") && strings.Contains(arg, "page=1"):
+			return []byte(`{"total_count":2,"jobs":[{"id":71,"name":"8.9 - eske - install - pr - gke","conclusion":"failure"}]}`), nil
+		case strings.Contains(arg, "/runs/7/jobs?") && strings.Contains(arg, "page=2"):
+			return []byte(`{"total_count":2,"jobs":[{"id":72,"name":"CI Gate","conclusion":"failure"}]}`), nil
+		case strings.HasSuffix(arg, "/jobs/71/logs"):
+			return []byte("Error: boom\n"), nil
+		}
+		return nil, fmt.Errorf("unexpected api call: %s", arg)
+	}
+
+	runs, err := loadHistory(context.Background(), opts)
+	if err != nil {
+		t.Fatalf("loadHistory: %v", err)
+	}
+	if len(runs) != 1 || runs[0].Run.ID != 7 || len(runs[0].Jobs) != 2 {
+		t.Fatalf("expected the most recent run with both job pages, got %+v", runs)
+	}
+	if !strings.Contains(calls[0], "status=completed") {
+		t.Errorf("expected only completed runs listed, got %s", calls[0])
+	}
+	if runs[0].Logs[71] != "Error: boom\n" {
+		t.Errorf("expected the failed matrix job log, got %q", runs[0].Logs[71])
+	}
+	if _, ok := runs[0].Logs[72]; ok {
+		t.Error("logs of non-matrix jobs must not be fetched")
+	}
+	if _, err := os.Stat(filepath.Join(cacheDir, "7", "logs", "71.log")); err != nil {
+		t.Errorf("expected the log cached: %v", err)
+	}
+
+	t.Run("cached runs skip the jobs and logs api", func(t *testing.T) {
+		calls = nil
+		if _, err := loadHistory(context.Background(), opts); err != nil {
+			t.Fatalf("loadHistory: %v", err)
+		}
+		if len(calls) != 1 {
+			t.Errorf("expected only the runs listing, got %v", calls)
+		}
+	})
+
+	t.Run("offline reads the cache only", func(t *testing.T) {
+		ghAPI = func(_ context.Context, args []string) ([]byte, error) {
+			return nil, fmt.Errorf("offline mode called the api: %v", args)
+		}
+		offline := opts
+		offline.Offline = true
+		offline.Limit = 0
+		runs, err := loadHistory(context.Background(), offline)
+		if err != nil {
+			t.Fatalf("loadHistory: %v", err)
+		}
+		if len(runs) != 1 || runs[0].Run.ID != 7 || runs[0].Logs[71] != "Error: boom\n" {
+			t.Errorf("expected run 7 with its log from the cache, got %+v", runs)
+		}
+	})
+}
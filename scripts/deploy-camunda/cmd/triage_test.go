@@ -157,6 +157,9 @@ func TestExtractFailureRecord(t *testing.T) {
 	if len(wantSignals) != 0 {
 		t.Errorf("missing signals %v in %v", wantSignals, rec.Signals)
 	}
+	if rec.Cause == nil || rec.Cause.ID != "rollout-timeout" {
+		t.Errorf("Cause = %+v, want the rollout-timeout signature", rec.Cause)
+	}
 }
 
 func TestStripANSILeavesNonANSIIntact(t *testing.T) {
@@ -0,0 +1,283 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/config"
+	"scripts/deploy-camunda/deploy"
+	"strings"
+	"syscall"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// explainDefaultNamespace stands in for --namespace when neither the flag
+// nor the config sets one; it only feeds namespace-derived placeholders.
+const explainDefaultNamespace = "values-explain"
+
+// newValuesCommand creates the "values" command group.
+func newValuesCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "values",
+		Short: "Inspect the Helm values a deploy would use",
+	}
+	cmd.AddCommand(newValuesExplainCommand())
+	return cmd
+}
+
+// newValuesExplainCommand creates `values explain`: the values counterpart of
+// `config env --show-origin`. It prepares a scenario's values chain the way a
+// deploy does and prints, for every leaf of the merged values, the file and
+// line that set it and every layer it overrode.
+func newValuesExplainCommand() *cobra.Command {
+	var vf config.RuntimeFlags
+	var debugRaw []string
+	var output string
+
+	cmd := &cobra.Command{
+		Use:   "explain [key-path]",
+		Short: "Trace each merged Helm value back to the layer that set it",
+		Long: `Prepare the values of a scenario exactly as a deploy would (common values,
+chart-root overlays, --extra-values, scenario layers, debug values) and show
+where every key of the merged result came from.
+
+Each leaf lists the file and line that set its final value and, most recent
+first, the layers it overrode. Items of name-keyed lists such as env are
+addressed by name: orchestration.env[name=ZEEBE_LOG_LEVEL].value.
+
+Pass a key path to limit the output to that key and everything below it.
+Flags and the active config profile are resolved like a deploy; unset env
+vars are shown as ${NAME} placeholders instead of prompting. Chart defaults
+(the chart's own values.yaml) are not included.`,
+		Example: `  deploy-camunda values explain --scenario keycloak-original orchestration.env
+  deploy-camunda values explain --identity keycloak --persistence elasticsearch -o json`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != "text" && output != "json" {
+				return fmt.Errorf("invalid --output %q: must be text or json", output)
+			}
+			if err := logging.Setup(logging.Options{
+				LevelString:  vf.LogLevel,
+				ColorEnabled: logging.IsTerminal(os.Stderr.Fd()),
+				Writer:       os.Stderr,
+			}); err != nil {
+				return err
+			}
+			if err := resolveExplainFlags(cmd.Flags(), &vf, debugRaw); err != nil {
+				return err
+			}
+
+			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+			defer stop()
+			origins, err := deploy.ExplainScenarioValues(ctx, &vf)
+			if err != nil {
+				return err
+			}
+			keyPath := ""
+			if len(args) == 1 {
+				keyPath = args[0]
+			}
+			origins = deploy.FilterValueOrigins(origins, keyPath)
+			if len(origins) == 0 && keyPath != "" {
+				return fmt.Errorf("key %q is not set by any values layer", keyPath)
+			}
+
+			base := vf.Chart.RepoRoot
+			if base == "" {
+				base, _ = os.Getwd()
+			}
+			if output == "json" {
+				return writeValueOriginsJSON(cmd.OutOrStdout(), origins, base)
+			}
+			writeValueOrigins(cmd.OutOrStdout(), origins, base)
+			return nil
+		},
+	}
+
+	f := cmd.Flags()
+	f.StringVar(&vf.Chart.ChartPath, "chart-path", "", "Path to the Camunda chart directory")
+	f.StringVarP(&vf.Chart.Chart, "chart", "c", "", "Chart name")
+	f.StringVarP(&vf.Chart.ChartVersion, "version", "v", "", "Chart version (only valid with --chart; not allowed with --chart-path)")
+	f.StringVar(&vf.Chart.RepoRoot, "repo-root", "", "Repository root path")
+	f.StringVarP(&vf.Deployment.Scenario, "scenario", "s", "", "The name of the scenario to explain")
+	f.StringVar(&vf.Deployment.ScenarioPath, "scenario-path", "", "Path to scenario files")
+	f.StringVarP(&vf.Deployment.Namespace, "namespace", "n", "", "Kubernetes namespace the values are prepared for (default "+explainDefaultNamespace+")")
+	f.StringVar(&vf.Deployment.NamespacePrefix, "namespace-prefix", "", "Prefix to prepend to namespace")
+	f.StringVar(&vf.Auth.Auth, "auth", "keycloak", "Auth scenario")
+	f.StringVar(&vf.Deployment.Platform, "platform", "gke", "Target platform: gke, rosa, eks, local")
+	f.StringVar(&vf.Deployment.Flow, "flow", "install", "Flow type")
+	f.StringVar(&vf.EnvFile, "env-file", "", "Path to .env file (defaults to .env in current dir)")
+	f.StringSliceVar(&vf.Deployment.ExtraValues, "extra-values", nil, "Additional Helm values files to apply last (comma-separated or repeatable)")
+	f.StringSliceVar(&vf.Chart.ChartRootOverlays, "values-preset", nil, "Chart-root overlay files to apply (comma-separated or repeatable)")
+	f.StringVar(&vf.Ingress.IngressSubdomain, "ingress-subdomain", "", "Ingress subdomain (requires --ingress-base-domain)")
+	f.StringVar(&vf.Ingress.IngressBaseDomain, "ingress-base-domain", "", "Base DNS zone used to compute CAMUNDA_HOSTNAME")
+	f.StringVar(&vf.Ingress.IngressHostname, "ingress-hostname", "", "Full ingress hostname (overrides --ingress-subdomain)")
+	f.BoolVar(&vf.Secrets.UseVaultBackedSecrets, "use-vault-backed-secrets", false, "Use vault-backed external secrets (selects -vault.yaml suffix files)")
+	f.StringSliceVar(&debugRaw, "debug", nil, "Include JVM remote debugging values for component (repeatable, e.g., --debug orchestration:5005)")
+	f.IntVar(&vf.Debug.DebugPort, "debug-port", 5005, "Default JVM debug port (used when no port specified in --debug)")
+	f.BoolVar(&vf.Debug.DebugSuspend, "debug-suspend", false, "Suspend JVM on startup until debugger attaches")
+	f.StringVar(&vf.Selection.Identity, "identity", "", "Identity selection (see values/identity/ or shell completion)")
+	f.StringVar(&vf.Selection.Persistence, "persistence", "", "Persistence selection (see values/persistence/ or shell completion)")
+	f.StringVar(&vf.Selection.TestPlatform, "test-platform", "", "Test platform selection (see values/platform/ or shell completion)")
+	f.StringSliceVar(&vf.Selection.Features, "features", nil, "Feature selections, comma-separated (see values/features/ or shell completion)")
+	f.BoolVar(&vf.Selection.QA, "qa", false, "Enable QA configuration (test users, etc.)")
+	f.BoolVar(&vf.Selection.ImageTags, "image-tags", false, "Enable image tag overrides from env vars")
+	f.BoolVar(&vf.Selection.UpgradeFlow, "upgrade-flow", false, "Enable upgrade flow configuration")
+	f.StringVarP(&output, "output", "o", "text", "Output format: text or json")
+	f.StringVarP(&vf.LogLevel, "log-level", "l", "warn", "Log level")
+
+	registerScenarioCompletion(cmd, "scenario")
+	registerPlatformCompletion(cmd)
+	registerSelectionCompletion(cmd)
+	_ = cmd.RegisterFlagCompletionFunc("output", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return filterByPrefix([]string{"text", "json"}, toComplete), cobra.ShellCompDirectiveNoFileComp
+	})
+	_ = cmd.RegisterFlagCompletionFunc("log-level", func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return completeLogLevels(toComplete)
+	})
+
+	return cmd
+}
+
+// resolveExplainFlags merges the active config profile into vf the way the
+// root PersistentPreRunE does for a deploy, filling in the namespace and
+// release a deploy would require but values explain does not.
+func resolveExplainFlags(fs *pflag.FlagSet, vf *config.RuntimeFlags, debugRaw []string) error {
+	vf.ChangedFlags = make(map[string]bool)
+	fs.Visit(func(f *pflag.Flag) {
+		vf.ChangedFlags[f.Name] = true
+	})
+	// The deploy's log level must not override the quieter default here.
+	vf.ChangedFlags["log-level"] = true
+
+	_, cfgRes, err := config.LoadAndMerge(configFile, true, vf)
+	if err != nil {
+		return err
+	}
+	if vf.Chart.RepoRoot == "" {
+		if detected, _ := config.DetectRepoRoot(); detected != "" {
+			vf.Chart.RepoRoot = detected
+		}
+	}
+	if strings.TrimSpace(vf.Deployment.Namespace) == "" {
+		vf.Deployment.Namespace = explainDefaultNamespace
+	}
+	if strings.TrimSpace(vf.Deployment.Release) == "" {
+		vf.Deployment.Release = "integration"
+	}
+	if err := config.Validate(vf, cfgRes); err != nil {
+		return err
+	}
+	if len(vf.Deployment.Scenarios) > 1 {
+		return fmt.Errorf("values explain takes a single scenario, got %q", vf.Deployment.Scenario)
+	}
+
+	if strings.TrimSpace(vf.Chart.ChartPath) != "" && !filepath.IsAbs(vf.Chart.ChartPath) && vf.Chart.RepoRoot != "" {
+		if _, err := os.Stat(vf.Chart.ChartPath); err != nil {
+			resolved := filepath.Join(vf.Chart.RepoRoot, vf.Chart.ChartPath)
+			if fi, err := os.Stat(resolved); err == nil && fi.IsDir() {
+				vf.Chart.ChartPath = resolved
+			}
+		}
+	}
+
+	if len(debugRaw) > 0 {
+		vf.Debug.DebugComponents = make(map[string]config.DebugConfig)
+		for _, raw := range debugRaw {
+			component, port, err := config.ParseDebugFlag(raw, vf.Debug.DebugPort)
+			if err != nil {
+				return fmt.Errorf("invalid --debug flag %q: %w", raw, err)
+			}
+			vf.Debug.DebugComponents[component] = config.DebugConfig{Port: port}
+		}
+	}
+	return nil
+}
+
+// writeValueOrigins prints one block per leaf:
+//
+//	orchestration.env[name=ZEEBE_LOG_LEVEL].value = debug
+//	    set by    scenario  values/features/debug.yaml:7
+//	    overrode  common    ../common/values-base.yaml:40 = info
+func writeValueOrigins(w io.Writer, origins []deploy.ValueOrigin, base string) {
+	for i, o := range origins {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintf(w, "%s = %s\n", o.Path, formatExplainValue(o.Value))
+		fmt.Fprintf(w, "    set by    %-9s %s\n", o.Source.Layer, explainLocation(o.Source, base))
+		for _, prev := range o.Overridden {
+			fmt.Fprintf(w, "    overrode  %-9s %s = %s\n", prev.Layer, explainLocation(prev, base), formatExplainValue(prev.Value))
+		}
+	}
+}
+
+func writeValueOriginsJSON(w io.Writer, origins []deploy.ValueOrigin, base string) error {
+	out := make([]deploy.ValueOrigin, len(origins))
+	for i, o := range origins {
+		o.Source.File = explainPath(o.Source.File, base)
+		if len(o.Overridden) > 0 {
+			prev := make([]deploy.ValueSource, len(o.Overridden))
+			for j, p := range o.Overridden {
+				p.File = explainPath(p.File, base)
+				prev[j] = p
+			}
+			o.Overridden = prev
+		}
+		out[i] = o
+	}
+	if out == nil {
+		out = []deploy.ValueOrigin{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(out)
+}
+
+func explainLocation(s deploy.ValueSource, base string) string {
+	loc := explainPath(s.File, base)
+	if s.Line > 0 {
+		loc = fmt.Sprintf("%s:%d", loc, s.Line)
+	}
+	return loc
+}
+
+// explainPath shows file relative to base when it lies below it.
+func explainPath(file, base string) string {
+	if base == "" {
+		return file
+	}
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	rel, err := filepath.Rel(base, abs)
+	if err != nil || strings.HasPrefix(rel, "..") {
+		return file
+	}
+	return rel
+}
+
+func formatExplainValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", t)
+	case map[string]any, []any:
+		data, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprintf("%v", t)
+		}
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"scripts/deploy-camunda/deploy"
+)
+
+func TestWriteValueOrigins(t *testing.T) {
+	origins := []deploy.ValueOrigin{{
+		Path:   "orchestration.env[name=A].value",
+		Value:  "10",
+		Source: deploy.ValueSource{Layer: deploy.LayerScenario, File: "/repo/values/base.yaml", Line: 4, Value: "10"},
+		Overridden: []deploy.ValueSource{
+			{Layer: deploy.LayerCommon, File: "/elsewhere/common.yaml", Line: 9, Value: "1"},
+			{Layer: deploy.LayerDebug, File: "--debug (generated)", Value: nil},
+		},
+	}}
+
+	var buf bytes.Buffer
+	writeValueOrigins(&buf, origins, "/repo")
+	want := `orchestration.env[name=A].value = "10"
+    set by    scenario  values/base.yaml:4
+    overrode  common    /elsewhere/common.yaml:9 = "1"
+    overrode  debug     --debug (generated) = null
+`
+	if got := buf.String(); got != want {
+		t.Fatalf("text output =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	if err := writeValueOriginsJSON(&buf, origins, "/repo"); err != nil {
+		t.Fatalf("writeValueOriginsJSON: %v", err)
+	}
+	var decoded []deploy.ValueOrigin
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("decode JSON: %v\n%s", err, buf.String())
+	}
+	if decoded[0].Source.File != "values/base.yaml" || decoded[0].Overridden[0].File != "/elsewhere/common.yaml" {
+		t.Fatalf("JSON paths = %+v", decoded[0])
+	}
+	if origins[0].Source.File != "/repo/values/base.yaml" {
+		t.Fatal("writeValueOriginsJSON must not modify its input")
+	}
+}
@@ -37,18 +37,25 @@ func newWatchCommand() *cobra.Command {
 		maxErrors       int
 		logLevel        string
 		cliName         string
+		engineName      string
 	)
 
 	cmd := &cobra.Command{
 		Use:   "watch",
 		Short: "Watch a Helm install with a local agent CLI and surface diagnoses live",
-		Long: `Poll the cluster every few seconds, hand the snapshot to a local agent
-CLI (Claude Code or opencode), and act on the structured verdict it returns.
+		Long: `Poll the cluster every few seconds, hand the snapshot to a verdict engine,
+and act on the structured verdict it returns.
 
-The watcher detects which agent CLI is installed at startup. If neither is
-found, it errors out with installation pointers. Authentication, model
-choice, and rate limiting are the local CLI's responsibility — this command
-does not call any API directly.
+--engine selects the verdict source:
+
+  agent   a local agent CLI (Claude Code or opencode) on every tick (default)
+  rules   built-in failure signatures only; free, deterministic, offline
+  hybrid  the rules first, the agent only when no rule fires
+
+For agent and hybrid, the watcher detects which agent CLI is installed at
+startup. If neither is found, it errors out with installation pointers.
+Authentication, model choice, and rate limiting are the local CLI's
+responsibility — this command does not call any API directly.
 
 Typical use:
 
@@ -69,14 +76,14 @@ Typical use:
 				return fmt.Errorf("--namespace is required")
 			}
 
-			cli, err := agentwatch.ResolveCLI(cliName)
+			engine, err := agentwatch.ParseEngine(engineName)
+			if err != nil {
+				return err
+			}
+			source, err := resolveVerdictSource(engine, cliName)
 			if err != nil {
 				return err
 			}
-			logging.Logger.Info().
-				Str("cli", cli.Name).
-				Str("path", cli.Path).
-				Msg("agentwatch: using local agent CLI")
 
 			if corpusDir != "" {
 				fmt.Fprintf(os.Stderr,
@@ -108,7 +115,7 @@ Typical use:
 			}()
 
 			interval := time.Duration(intervalSeconds) * time.Second
-			if interval > 0 && interval < agentwatch.MinInterval {
+			if engine.UsesAgent() && interval > 0 && interval < agentwatch.MinInterval {
 				logging.Logger.Warn().
 					Dur("requested", interval).
 					Dur("floor", agentwatch.MinInterval).
@@ -117,7 +124,7 @@ Typical use:
 			}
 
 			opts := agentwatch.Options{
-				CLI: cli,
+				Source: source,
 				Snapshot: agentwatch.SnapshotOptions{
 					Namespace:   namespace,
 					Release:     release,
@@ -172,58 +179,125 @@ Typical use:
 		"Consecutive snapshot/agent/parse errors tolerated before giving up (0 = package default)")
 	cmd.Flags().StringVarP(&logLevel, "log-level", "l", "info", "Log level")
 	cmd.Flags().StringVar(&cliName, "cli", "", "Agent CLI to use: opencode or claude (auto-detected if empty)")
+	cmd.Flags().StringVar(&engineName, "engine", string(agentwatch.EngineAgent),
+		"Verdict engine: agent, rules, or hybrid (rules first, agent only when no rule fires)")
 
 	cmd.AddCommand(newWatchReplayCommand())
 
 	return cmd
 }
 
+// resolveVerdictSource builds the verdict source for engine, resolving the
+// agent CLI only when the engine can call it so `--engine rules` works on
+// machines without one.
+func resolveVerdictSource(engine agentwatch.Engine, cliName string) (agentwatch.VerdictSource, error) {
+	var cli agentwatch.AgentCLI
+	if engine.UsesAgent() {
+		resolved, err := agentwatch.ResolveCLI(cliName)
+		if err != nil {
+			return nil, err
+		}
+		cli = resolved
+		logging.Logger.Info().
+			Str("cli", cli.Name).
+			Str("path", cli.Path).
+			Msg("agentwatch: using local agent CLI")
+	}
+	logging.Logger.Info().Str("engine", string(engine)).Msg("agentwatch: verdict engine selected")
+	return agentwatch.NewVerdictSource(engine, cli, "")
+}
+
 // newWatchReplayCommand creates "watch replay" — the eval-on-corpus tool.
 // Given a directory of captured tick records (written by `watch --corpus-dir`),
-// it re-invokes the agent CLI on each snapshot, parses the new verdict, and
-// prints a summary diff against the recorded verdict. Exits non-zero if any
-// recorded verdict regresses on action class or drops below the operator's
-// confidence threshold.
+// it re-runs the selected engine on each snapshot, parses the new verdict, and
+// prints a summary diff against the recorded verdict. When the corpus carries
+// a labels file it also scores the engine against the labels (confusion
+// matrix, abort precision/recall, abort latency). Exits non-zero if any
+// recorded verdict regresses on action class or disagrees with its label.
 func newWatchReplayCommand() *cobra.Command {
 	var (
 		strict    bool
 		logLevel  string
 		replayCLI string
+		engineArg string
+		format    string
 	)
 	cmd := &cobra.Command{
 		Use:   "replay <corpus-dir>",
-		Short: "Re-run the agent skill over a captured corpus and diff verdicts",
-		Args:  cobra.ExactArgs(1),
+		Short: "Re-run a verdict engine over a captured corpus and diff verdicts",
+		Long: `Re-run a verdict engine over a corpus captured with ` + "`watch --corpus-dir`" + ` and diff
+the fresh verdicts against the recorded ones.
+
+If the corpus directory contains ` + agentwatch.LabelsFile + `, each listed tick is scored
+against its expected action (wait, investigate, abort) and culprit pod:
+
+  20260504T100130.000Z.json:
+    action: abort
+    culprit_pod: integration-operate-6d9f-abcde
+
+The report then includes a confusion matrix, precision/recall for abort and
+the abort latency from the first tick labelled investigate or abort.`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
+			switch format {
+			case "text", "json", "junit":
+			default:
+				return fmt.Errorf("unknown format %q (supported: text, json, junit)", format)
+			}
 			if err := logging.Setup(logging.Options{
 				LevelString:  logLevel,
 				ColorEnabled: logging.IsTerminal(os.Stdout.Fd()),
 			}); err != nil {
 				return err
 			}
-			cli, err := agentwatch.ResolveCLI(replayCLI)
+			engine, err := agentwatch.ParseEngine(engineArg)
+			if err != nil {
+				return err
+			}
+			source, err := resolveVerdictSource(engine, replayCLI)
 			if err != nil {
 				return err
 			}
 			ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 			defer stop()
 
-			report, err := agentwatch.Replay(ctx, cli, args[0])
+			report, err := agentwatch.Replay(ctx, source, args[0])
 			if err != nil {
 				if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
 					return nil
 				}
 				return err
 			}
-			fmt.Print(report.Format())
+			switch format {
+			case "json":
+				out, err := report.FormatJSON()
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(out))
+			case "junit":
+				out, err := report.FormatJUnit()
+				if err != nil {
+					return err
+				}
+				fmt.Println(string(out))
+			default:
+				fmt.Print(report.Format())
+			}
 			if strict && report.Regressions > 0 {
 				return fmt.Errorf("%d verdict regression(s) detected", report.Regressions)
 			}
+			if strict && report.Mismatches > 0 {
+				return fmt.Errorf("%d verdict(s) disagree with corpus labels", report.Mismatches)
+			}
 			return nil
 		},
 	}
-	cmd.Flags().BoolVar(&strict, "strict", true, "Exit non-zero if any verdict action class regresses")
+	cmd.Flags().BoolVar(&strict, "strict", true, "Exit non-zero if any verdict action class regresses or disagrees with its label")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format: text, json, junit")
 	cmd.Flags().StringVarP(&logLevel, "log-level", "l", "warn", "Log level (replay is noisy at info)")
 	cmd.Flags().StringVar(&replayCLI, "cli", "", "Agent CLI to use: opencode or claude (auto-detected if empty)")
+	cmd.Flags().StringVar(&engineArg, "engine", string(agentwatch.EngineAgent),
+		"Verdict engine to replay: agent, rules, or hybrid")
 	return cmd
 }
@@ -17,8 +17,17 @@ import (
 var ValidIngressBaseDomains = []string{
 	"ci.distro.ultrawombat.com",
 	"distribution.aws.camunda.cloud",
+	LocalIngressBaseDomain,
 }
 
+// PlatformLocal is the kind/k3d platform for running scenarios on a laptop.
+const PlatformLocal = "local"
+
+// LocalIngressBaseDomain resolves every subdomain to 127.0.0.1 (nip.io), where
+// the local cluster's ingress controller listens, so local deploys need no DNS
+// records or /etc/hosts entries.
+const LocalIngressBaseDomain = "127.0.0.1.nip.io"
+
 const (
 	// DefaultKeycloakHost is the default external Keycloak host. The shared CI
 	// Keycloak was decommissioned (#6245), so there is no default; external-keycloak
@@ -29,7 +38,7 @@ const (
 )
 
 // DeployPlatforms lists the valid deployment infrastructure platforms.
-var DeployPlatforms = []string{"gke", "eks", "rosa"}
+var DeployPlatforms = []string{"gke", "eks", "rosa", PlatformLocal}
 
 // KeycloakConfig holds Keycloak connection settings.
 type KeycloakConfig struct {
@@ -90,6 +99,11 @@ type DeploySpecConfig struct {
 	ScenarioRoot             string   `mapstructure:"scenarioRoot" yaml:"scenarioRoot,omitempty"`
 	ValuesPreset             string   `mapstructure:"valuesPreset" yaml:"valuesPreset,omitempty"`
 	RunE2ETests              *bool    `mapstructure:"runE2ETests" yaml:"runE2ETests,omitempty"`
+	RunSmoke                 *bool    `mapstructure:"runSmoke" yaml:"runSmoke,omitempty"`
+
+	// SecretSources load secrets from external stores into the deploy env,
+	// after the .env file and in list order (later sources win).
+	SecretSources []SecretSourceConfig `mapstructure:"secretSources" yaml:"secretSources,omitempty"`
 
 	// Selection + composition model fields (alternative to Scenario)
 	Identity     string   `mapstructure:"identity" yaml:"identity,omitempty"`
@@ -101,6 +115,39 @@ type DeploySpecConfig struct {
 	UpgradeFlow  *bool    `mapstructure:"upgradeFlow" yaml:"upgradeFlow,omitempty"`
 }
 
+// Secret source types accepted in SecretSourceConfig.Type.
+const (
+	SecretSourceVault   = "vault"
+	SecretSourceSOPS    = "sops"
+	SecretSourceExec    = "exec"
+	SecretSourceEnvFile = "envFile"
+)
+
+// SecretSourceConfig configures one secret backend. Which fields apply
+// depends on Type:
+//
+//   - vault: Address (default $VAULT_ADDR), Namespace, Mount (default
+//     "secret"), Path and TokenEnv (default VAULT_TOKEN, then ~/.vault-token).
+//     Reads one HashiCorp Vault KV v2 secret.
+//   - sops: File, AgeKeyFile. Decrypts a flat SOPS-encrypted YAML/JSON file
+//     with the sops binary.
+//   - exec: Command (argv), Format ("env" or "json", default detected),
+//     Timeout. Runs a command such as the 1Password CLI and reads its stdout.
+//   - envFile: File. Reads another KEY=value file.
+type SecretSourceConfig struct {
+	Type       string   `mapstructure:"type" yaml:"type"`
+	Address    string   `mapstructure:"address" yaml:"address,omitempty"`
+	Namespace  string   `mapstructure:"namespace" yaml:"namespace,omitempty"`
+	Mount      string   `mapstructure:"mount" yaml:"mount,omitempty"`
+	Path       string   `mapstructure:"path" yaml:"path,omitempty"`
+	TokenEnv   string   `mapstructure:"tokenEnv" yaml:"tokenEnv,omitempty"`
+	File       string   `mapstructure:"file" yaml:"file,omitempty"`
+	AgeKeyFile string   `mapstructure:"ageKeyFile" yaml:"ageKeyFile,omitempty"`
+	Command    []string `mapstructure:"command" yaml:"command,omitempty,flow"`
+	Format     string   `mapstructure:"format" yaml:"format,omitempty"`
+	Timeout    string   `mapstructure:"timeout" yaml:"timeout,omitempty"`
+}
+
 // MatrixConfig holds configuration specific to the "matrix" subcommand.
 // Fields here can be set in the deploy.yaml config file under a top-level
 // "matrix:" key. Shared fields (repoRoot, platform, logLevel, keycloak, etc.)
@@ -127,6 +174,7 @@ type MatrixConfig struct {
 	// Tests
 	TestE2E *bool `mapstructure:"testE2E" yaml:"testE2E,omitempty"`
 	TestAll *bool `mapstructure:"testAll" yaml:"testAll,omitempty"`
+	Smoke   *bool `mapstructure:"smoke" yaml:"smoke,omitempty"`
 
 	// Per-platform kube contexts
 	KubeContexts map[string]string `mapstructure:"kubeContexts" yaml:"kubeContexts,omitempty"`
@@ -464,6 +512,10 @@ func applyMatrixEnvOverrides(m *MatrixConfig, get func(string) string) {
 		b := parseBool(v)
 		m.TestAll = &b
 	}
+	if v := get("CAMUNDA_MATRIX_SMOKE"); v != "" {
+		b := parseBool(v)
+		m.Smoke = &b
+	}
 }
 
 // FirstNonEmpty returns the first non-empty string.
@@ -95,6 +95,7 @@ type MatrixRunFlags struct {
 	// Tests
 	TestE2E *bool
 	TestAll *bool
+	Smoke   *bool
 
 	// Kube contexts
 	KubeContext    *string
@@ -116,6 +117,9 @@ type MatrixRunFlags struct {
 	UseVaultBackedSecretsEKS *bool
 	// VaultBackedSecrets is the assembled map
 	VaultBackedSecrets map[string]bool
+	// SecretSources are taken from the active deployment profile, else the
+	// root config, like a single deploy's.
+	SecretSources *[]SecretSourceConfig
 
 	// Env files
 	EnvFile   *string
@@ -179,12 +183,16 @@ func ApplyMatrixRunConfig(rc *RootConfig, changedFlags map[string]bool, f *Matri
 	// CLI flag > matrix block (m.*) > root (rc.*) > active deployment profile.
 	rcPlatform, rcRepoRoot := rc.Platform, rc.RepoRoot
 	rcKubeContext, rcIngressBaseDomain, rcEnvFile := rc.KubeContext, rc.IngressBaseDomain, rc.EnvFile
+	rcSecretSources := rc.SecretSources
 	if dep := activeDeployment(rc); dep != nil {
 		rcPlatform = FirstNonEmpty(rc.Platform, dep.Platform)
 		rcRepoRoot = FirstNonEmpty(rc.RepoRoot, dep.RepoRoot)
 		rcKubeContext = FirstNonEmpty(rc.KubeContext, dep.KubeContext)
 		rcIngressBaseDomain = FirstNonEmpty(rc.IngressBaseDomain, dep.IngressBaseDomain)
 		rcEnvFile = FirstNonEmpty(rc.EnvFile, dep.EnvFile)
+		if len(dep.SecretSources) > 0 {
+			rcSecretSources = dep.SecretSources
+		}
 	}
 
 	// --- Filtering & generation ---
@@ -211,6 +219,7 @@ func ApplyMatrixRunConfig(rc *RootConfig, changedFlags map[string]bool, f *Matri
 	// --- Tests ---
 	MergeBoolField(f.TestE2E, m.TestE2E, nil, changedFlags, "test-e2e")
 	MergeBoolField(f.TestAll, m.TestAll, nil, changedFlags, "test-all")
+	MergeBoolField(f.Smoke, m.Smoke, nil, changedFlags, "smoke")
 
 	// --- Kube contexts ---
 	// Scalar fallbacks (default context for all platforms)
@@ -231,6 +240,9 @@ func ApplyMatrixRunConfig(rc *RootConfig, changedFlags map[string]bool, f *Matri
 	if f.VaultBackedSecrets != nil {
 		MergeBoolMapField(f.VaultBackedSecrets, m.VaultBackedSecrets)
 	}
+	if f.SecretSources != nil && len(*f.SecretSources) == 0 {
+		*f.SecretSources = rcSecretSources
+	}
 
 	// --- Env files ---
 	MergeStringField(f.EnvFile, m.EnvFile, rcEnvFile, changedFlags, "env-file")
@@ -111,3 +111,29 @@ func TestApplyMatrixRunConfigCLIFlagBeatsProfile(t *testing.T) {
 		t.Errorf("ingress = %q, want cli value (explicit flag wins)", *ingress)
 	}
 }
+
+func TestApplyMatrixRunConfigSecretSourcesFromProfile(t *testing.T) {
+	profileSources := []SecretSourceConfig{{Type: SecretSourceEnvFile, File: "profile.env"}}
+	rc := &RootConfig{
+		Current: "ci",
+		Deployments: map[string]DeploymentConfig{
+			"ci": {DeploySpecConfig: DeploySpecConfig{SecretSources: profileSources}},
+		},
+	}
+	rc.SecretSources = []SecretSourceConfig{{Type: SecretSourceEnvFile, File: "root.env"}}
+
+	f, _, _, _, _, _ := newMatrixRunFlags()
+	var sources []SecretSourceConfig
+	f.SecretSources = &sources
+	ApplyMatrixRunConfig(rc, map[string]bool{}, f)
+	if len(sources) != 1 || sources[0].File != "profile.env" {
+		t.Errorf("SecretSources = %+v, want the active profile's", sources)
+	}
+
+	delete(rc.Deployments, "ci")
+	sources = nil
+	ApplyMatrixRunConfig(rc, map[string]bool{}, f)
+	if len(sources) != 1 || sources[0].File != "root.env" {
+		t.Errorf("SecretSources = %+v, want the root config's", sources)
+	}
+}
@@ -114,6 +114,13 @@ type SecretsFlags struct {
 	// StrictSecrets makes vault-secret generation fail if any mapped env var is
 	// unset, instead of silently omitting it from the rendered Secret.
 	StrictSecrets bool
+	// Sources are the profile's secretSources, in precedence order.
+	Sources []SecretSourceConfig
+	// SourceEnv holds what Sources loaded (see deploy.LoadSecretSources) and
+	// SourceOrigins the name of the source that set each key. Both are nil
+	// until loaded.
+	SourceEnv     map[string]string
+	SourceOrigins map[string]string
 }
 
 // DebugFlags holds JVM debug configuration.
@@ -127,10 +134,12 @@ type DebugFlags struct {
 type TestFlags struct {
 	RunE2ETests       bool   // Run e2e tests after deployment
 	RunAllTests       bool   // Run all e2e tests after deployment
+	RunSmoke          bool   // Run the built-in Go smoke suite after deployment
 	TestExclude       string // Pipe-separated regex for test suites to exclude (passed as --grep-invert to Playwright)
 	OutputTestEnv     bool   // Generate .env file for E2E tests after deployment
 	OutputTestEnvPath string // Path for the test .env file output
 	KubeContext       string
+	ChartPath         string // Chart directory whose tests run; empty means Chart.ChartPath
 }
 
 // SelectionFlags holds selection + composition model flags.
@@ -173,6 +182,12 @@ type IndexPrefixFlags struct {
 	OperateIndexPrefix       string
 }
 
+// LocalClusterFlags selects the kind/k3d cluster used by --platform local.
+type LocalClusterFlags struct {
+	Provider string // kind or k3d; empty auto-detects from PATH
+	Name     string // cluster name; empty uses the localcluster default
+}
+
 // RuntimeFlags holds all CLI flag values that can be merged with config.
 // Fields are grouped into composed sub-structs by domain concern.
 type RuntimeFlags struct {
@@ -187,6 +202,7 @@ type RuntimeFlags struct {
 	Selection  SelectionFlags
 	Deprecated DeprecatedFlags
 	Index      IndexPrefixFlags
+	Local      LocalClusterFlags
 
 	// Cross-cutting / runtime fields that don't belong to a single domain.
 	LogLevel    string
@@ -254,6 +270,12 @@ type RuntimeFlags struct {
 	// e2e test script output. Used by the matrix runner to redirect
 	// e2e output to a per-entry log file instead of polluting the terminal.
 	E2EOutputWriter io.Writer
+
+	// ValuesSnapshotDir, when set, receives a copy of the values chain each
+	// deployment hands to helm (see deploy.SnapshotValuesChain), so that
+	// post-mortems can see the processed files after the scenario temp dir
+	// is gone. Each deployment replaces the previous snapshot.
+	ValuesSnapshotDir string
 }
 
 // CompanionChart represents a Helm chart to deploy as a separate release
@@ -389,6 +411,7 @@ func ApplyActiveDeployment(rc *RootConfig, active string, flags *RuntimeFlags) e
 
 	// Test execution flags
 	MergeBoolField(&flags.Test.RunE2ETests, dep.RunE2ETests, rc.RunE2ETests, changed, "test-e2e")
+	MergeBoolField(&flags.Test.RunSmoke, dep.RunSmoke, rc.RunSmoke, changed, "smoke")
 
 	// Selection + composition model fields
 	MergeStringField(&flags.Selection.Identity, dep.Identity, rc.Identity, changed, "identity")
@@ -401,6 +424,13 @@ func ApplyActiveDeployment(rc *RootConfig, active string, flags *RuntimeFlags) e
 
 	// Slice fields
 	MergeStringSliceField(&flags.Deployment.ExtraValues, dep.ExtraValues, rc.ExtraValues)
+	if len(flags.Secrets.Sources) == 0 {
+		if len(dep.SecretSources) > 0 {
+			flags.Secrets.Sources = dep.SecretSources
+		} else {
+			flags.Secrets.Sources = rc.SecretSources
+		}
+	}
 
 	// Keycloak
 	MergeStringField(&flags.Auth.KeycloakHost, "", rc.Keycloak.Host, changed, "keycloak-host")
@@ -469,6 +499,7 @@ func applyRootDefaults(rc *RootConfig, flags *RuntimeFlags) error {
 
 	// Test execution flags
 	MergeBoolField(&flags.Test.RunE2ETests, nil, rc.RunE2ETests, changed, "test-e2e")
+	MergeBoolField(&flags.Test.RunSmoke, nil, rc.RunSmoke, changed, "smoke")
 
 	// Selection + composition model fields
 	MergeStringField(&flags.Selection.Identity, "", rc.Identity, changed, "identity")
@@ -480,6 +511,9 @@ func applyRootDefaults(rc *RootConfig, flags *RuntimeFlags) error {
 	MergeStringSliceField(&flags.Selection.Features, nil, rc.Features)
 
 	MergeStringSliceField(&flags.Deployment.ExtraValues, nil, rc.ExtraValues)
+	if len(flags.Secrets.Sources) == 0 {
+		flags.Secrets.Sources = rc.SecretSources
+	}
 
 	MergeStringField(&flags.Auth.KeycloakHost, "", rc.Keycloak.Host, changed, "keycloak-host")
 	MergeStringField(&flags.Auth.KeycloakProtocol, "", rc.Keycloak.Protocol, changed, "keycloak-protocol")
@@ -538,6 +572,12 @@ func Validate(flags *RuntimeFlags, cfgRes ...*ConfigResolution) error {
 		return fmt.Errorf("no valid scenarios found in %q", flags.Deployment.Scenario)
 	}
 
+	if flags.Deployment.Platform == PlatformLocal {
+		if err := applyLocalPlatformDefaults(flags); err != nil {
+			return err
+		}
+	}
+
 	// Validate ingress configuration
 	// --ingress-hostname is mutually exclusive with --ingress-subdomain and --ingress-base-domain
 	if flags.Ingress.IngressHostname != "" && (flags.Ingress.IngressSubdomain != "" || flags.Ingress.IngressBaseDomain != "") {
@@ -555,6 +595,25 @@ func Validate(flags *RuntimeFlags, cfgRes ...*ConfigResolution) error {
 	return nil
 }
 
+// applyLocalPlatformDefaults adapts a deploy to a kind/k3d cluster: there is no
+// Vault or external-secrets operator, so test secrets are generated, and the
+// ingress host defaults to <namespace>.127.0.0.1.nip.io.
+func applyLocalPlatformDefaults(flags *RuntimeFlags) error {
+	if flags.Secrets.UseVaultBackedSecrets {
+		return fmt.Errorf("--use-vault-backed-secrets cannot be used with --platform local; local clusters have no Vault and use generated test secrets")
+	}
+	if !flags.ChangedFlags["auto-generate-secrets"] {
+		flags.Secrets.AutoGenerateSecrets = true
+	}
+	if flags.Ingress.IngressHostname == "" && flags.Ingress.IngressBaseDomain == "" {
+		flags.Ingress.IngressBaseDomain = LocalIngressBaseDomain
+		if flags.Ingress.IngressSubdomain == "" {
+			flags.Ingress.IngressSubdomain = flags.EffectiveNamespace()
+		}
+	}
+	return nil
+}
+
 // parseScenarios splits a comma-separated scenario string into a slice.
 func parseScenarios(scenario string) []string {
 	var scenarios []string
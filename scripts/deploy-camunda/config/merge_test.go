@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func localFlags() *RuntimeFlags {
+	return &RuntimeFlags{
+		Chart:        ChartFlags{ChartPath: "charts/camunda-platform-8.9"},
+		Deployment:   DeploymentFlags{Namespace: "dev", Release: "integration", Scenario: "elasticsearch", Platform: PlatformLocal},
+		ChangedFlags: map[string]bool{},
+	}
+}
+
+func TestValidateLocalPlatformDefaults(t *testing.T) {
+	flags := localFlags()
+	if err := Validate(flags); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if got := flags.ResolveIngressHostname(); got != "dev.127.0.0.1.nip.io" {
+		t.Errorf("ingress hostname = %q, want dev.127.0.0.1.nip.io", got)
+	}
+	if !flags.Secrets.AutoGenerateSecrets {
+		t.Error("local platform should generate test secrets")
+	}
+
+	// Explicit choices win over the local defaults.
+	flags = localFlags()
+	flags.Ingress.IngressHostname = "camunda.localhost"
+	flags.ChangedFlags["auto-generate-secrets"] = true
+	if err := Validate(flags); err != nil {
+		t.Fatalf("Validate: %v", err)
+	}
+	if flags.Ingress.IngressBaseDomain != "" || flags.Secrets.AutoGenerateSecrets {
+		t.Errorf("explicit flags overridden: ingress=%+v autoGenerate=%v", flags.Ingress, flags.Secrets.AutoGenerateSecrets)
+	}
+}
+
+func TestValidateLocalPlatformRejectsVault(t *testing.T) {
+	flags := localFlags()
+	flags.Secrets.UseVaultBackedSecrets = true
+	if err := Validate(flags); err == nil {
+		t.Fatal("expected --use-vault-backed-secrets to be rejected for --platform local")
+	}
+}
+
+func TestApplyActiveDeploymentSecretSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "deploy.yaml")
+	cfg := `secretSources:
+  - type: envFile
+    file: shared.env
+deployments:
+  partner:
+    secretSources:
+      - type: vault
+        address: http://127.0.0.1:8200
+        path: teams/partner
+      - type: exec
+        command: [op, inject, -i, secrets.tpl]
+  plain: {}
+`
+	if err := os.WriteFile(path, []byte(cfg), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	rc, err := Read(path, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The profile's list replaces the root one rather than appending to it.
+	flags := &RuntimeFlags{ChangedFlags: map[string]bool{}}
+	if err := ApplyActiveDeployment(rc, "partner", flags); err != nil {
+		t.Fatal(err)
+	}
+	got := flags.Secrets.Sources
+	if len(got) != 2 || got[0].Type != SecretSourceVault || got[0].Path != "teams/partner" ||
+		got[1].Type != SecretSourceExec || len(got[1].Command) != 4 {
+		t.Errorf("partner sources = %+v", got)
+	}
+
+	flags = &RuntimeFlags{ChangedFlags: map[string]bool{}}
+	if err := ApplyActiveDeployment(rc, "plain", flags); err != nil {
+		t.Fatal(err)
+	}
+	if got := flags.Secrets.Sources; len(got) != 1 || got[0].Type != SecretSourceEnvFile || got[0].File != "shared.env" {
+		t.Errorf("plain sources = %+v, want the root envFile source", got)
+	}
+}
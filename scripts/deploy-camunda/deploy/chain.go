@@ -1,5 +1,15 @@
 package deploy
 
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// valuesSnapshotIndex names the chain index SnapshotValuesChain writes.
+const valuesSnapshotIndex = "chain.json"
+
 // BuildValuesChain assembles the final ordered list of Helm values files.
 // This is the single canonical definition of values precedence for all code paths
 // (layered, legacy, prepare-values CLI).
@@ -25,3 +35,48 @@ func BuildValuesChain(common, overlays, extra, scenario []string, debugFile stri
 	}
 	return chain
 }
+
+// SnapshotValuesChain copies the files of a values chain into dir as
+// NN-<group>-<name>, in precedence order, and writes chain.json listing the
+// layers with File pointing at the copies (relative to dir). Whatever dir
+// held before is replaced.
+func SnapshotValuesChain(dir string, layers []ValuesLayer) error {
+	if err := os.RemoveAll(dir); err != nil {
+		return fmt.Errorf("clear values snapshot: %w", err)
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create values snapshot: %w", err)
+	}
+	index := make([]ValuesLayer, len(layers))
+	for i, l := range layers {
+		data, err := os.ReadFile(l.File)
+		if err != nil {
+			return fmt.Errorf("read values layer %s: %w", l.File, err)
+		}
+		name := fmt.Sprintf("%02d-%s-%s", i+1, l.Group, filepath.Base(l.File))
+		if err := os.WriteFile(filepath.Join(dir, name), data, 0o644); err != nil {
+			return fmt.Errorf("write values layer %s: %w", name, err)
+		}
+		l.File = name
+		index[i] = l
+	}
+	data, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode values chain: %w", err)
+	}
+	return os.WriteFile(filepath.Join(dir, valuesSnapshotIndex), data, 0o644)
+}
+
+// LoadValuesSnapshot reads the chain index written by SnapshotValuesChain.
+// File fields stay relative to dir.
+func LoadValuesSnapshot(dir string) ([]ValuesLayer, error) {
+	data, err := os.ReadFile(filepath.Join(dir, valuesSnapshotIndex))
+	if err != nil {
+		return nil, err
+	}
+	var layers []ValuesLayer
+	if err := json.Unmarshal(data, &layers); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", valuesSnapshotIndex, err)
+	}
+	return layers, nil
+}
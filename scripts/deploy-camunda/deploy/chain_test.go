@@ -1,6 +1,8 @@
 package deploy
 
 import (
+	"os"
+	"path/filepath"
 	"reflect"
 	"testing"
 )
@@ -77,3 +79,41 @@ func TestBuildValuesChain(t *testing.T) {
 		})
 	}
 }
+
+func TestSnapshotValuesChain(t *testing.T) {
+	src := t.TempDir()
+	common := writeLayer(t, src, "common.yaml", "global:\n  host: a\n")
+	scenario := writeLayer(t, src, "values.yaml", "global:\n  host: b\n")
+	layers := []ValuesLayer{
+		{Group: LayerCommon, Source: "values/common.yaml", File: common},
+		{Group: LayerScenario, Source: "values/layered/base.yaml", File: scenario, NameMerged: true},
+	}
+
+	dir := filepath.Join(t.TempDir(), "snapshot")
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	// A previous deployment's snapshot is replaced, not merged.
+	writeLayer(t, dir, "01-common-stale.yaml", "stale: true\n")
+
+	if err := SnapshotValuesChain(dir, layers); err != nil {
+		t.Fatalf("SnapshotValuesChain: %v", err)
+	}
+	got, err := LoadValuesSnapshot(dir)
+	if err != nil {
+		t.Fatalf("LoadValuesSnapshot: %v", err)
+	}
+	want := []ValuesLayer{
+		{Group: LayerCommon, Source: "values/common.yaml", File: "01-common-common.yaml"},
+		{Group: LayerScenario, Source: "values/layered/base.yaml", File: "02-scenario-values.yaml", NameMerged: true},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("chain index = %+v, want %+v", got, want)
+	}
+	if data, err := os.ReadFile(filepath.Join(dir, "02-scenario-values.yaml")); err != nil || string(data) != "global:\n  host: b\n" {
+		t.Errorf("scenario copy = %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "01-common-stale.yaml")); !os.IsNotExist(err) {
+		t.Errorf("expected the stale snapshot removed, stat err = %v", err)
+	}
+}
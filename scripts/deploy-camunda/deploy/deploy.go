@@ -126,6 +126,16 @@ func runFailFastPreflight(ctx context.Context, flags *config.RuntimeFlags) error
 
 // Execute performs the actual Camunda deployment based on the provided flags.
 func Execute(ctx context.Context, flags *config.RuntimeFlags) error {
+	// Secret sources feed both the preflight and every scenario env, so load
+	// them first; an unreachable store should stop the deploy here.
+	if err := LoadSecretSources(ctx, flags); err != nil {
+		return err
+	}
+
+	if err := checkSmokeSupported(flags); err != nil {
+		return err
+	}
+
 	// Fail-fast: validate secrets/env before any cluster mutation so a missing
 	// credential surfaces here rather than as an ImagePullBackOff minutes later.
 	if err := runFailFastPreflight(ctx, flags); err != nil {
@@ -255,6 +265,12 @@ func executeParallelDeployments(ctx context.Context, flags *config.RuntimeFlags)
 		return fmt.Errorf("one or more scenarios failed deployment")
 	}
 
+	for _, r := range results {
+		if err := smokeError(r.Smoke); err != nil {
+			return fmt.Errorf("post-deployment smoke checks failed for namespace %s: %w", r.Namespace, err)
+		}
+	}
+
 	// Run tests for each successful deployment (in parallel)
 	// For multi-scenario deployments, we run tests against the first successful namespace
 	// since all scenarios should be equivalent for testing purposes
@@ -308,6 +324,10 @@ func executeSingleDeployment(ctx context.Context, flags *config.RuntimeFlags) er
 	// Print single deployment summary
 	printDeploymentSummary(result, flags)
 
+	if err := smokeError(result.Smoke); err != nil {
+		return fmt.Errorf("post-deployment smoke checks failed: %w", err)
+	}
+
 	// Phase 3: Run tests if requested
 	if err := RunTests(ctx, flags, result.Namespace); err != nil {
 		return fmt.Errorf("post-deployment tests failed: %w", err)
@@ -342,6 +362,12 @@ func executeDeployment(ctx context.Context, prepared *PreparedScenario, flags *c
 		LayeredFiles:             prepared.LayeredFiles,
 	}
 
+	if flags.ValuesSnapshotDir != "" {
+		if err := SnapshotValuesChain(flags.ValuesSnapshotDir, prepared.ValuesLayers); err != nil {
+			logging.Logger.Warn().Err(err).Str("dir", flags.ValuesSnapshotDir).Msg("Failed to snapshot the values chain")
+		}
+	}
+
 	// Ensure temp directory is cleaned up when deployment completes
 	defer func() {
 		logging.Logger.Debug().
@@ -555,6 +581,23 @@ func executeDeployment(ctx context.Context, prepared *PreparedScenario, flags *c
 		}
 	}
 
+	// Run the built-in smoke suite. Failures are recorded on the result rather
+	// than as result.Error so the summary can still report every component.
+	if flags.Test.RunSmoke {
+		if flags.OnPhase != nil {
+			flags.OnPhase("smoke")
+		}
+		smokeHost, err := resolveIngressReadyHost(ctx, flags, scenarioCtx)
+		if err == nil && smokeHost == "" {
+			err = fmt.Errorf("no ingress host could be determined")
+		}
+		if err != nil {
+			result.Smoke = []SmokeCheck{{Component: "ingress", Status: SmokeFail, Detail: err.Error()}}
+		} else {
+			result.Smoke = runSmokeChecks(ctx, flags, prepared, smokeHost)
+		}
+	}
+
 	totalDuration := time.Since(startTime)
 	logging.Logger.Debug().
 		Str("scenario", scenarioCtx.ScenarioName).
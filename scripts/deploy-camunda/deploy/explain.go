@@ -0,0 +1,497 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"scripts/deploy-camunda/config"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Values layer groups, in BuildValuesChain precedence order.
+const (
+	LayerCommon   = "common"
+	LayerOverlay  = "overlay"
+	LayerExtra    = "extra"
+	LayerScenario = "scenario"
+	LayerDebug    = "debug"
+)
+
+// ValuesLayer is one values file in a scenario's chain.
+type ValuesLayer struct {
+	// Group is one of the Layer* constants.
+	Group string `json:"group"`
+	// Source is the file as it exists in the repo, before env substitution.
+	Source string `json:"source"`
+	// File is what the deploy actually reads: the processed copy in the
+	// scenario temp dir, or Source itself for files that are not processed.
+	File string `json:"file"`
+	// NameMerged marks scenario layers that MergeLayeredValues folds into one
+	// file, merging name-keyed arrays instead of letting Helm replace them.
+	NameMerged bool `json:"nameMerged,omitempty"`
+	// Rewritten means File was regenerated from Source (the digest overlay
+	// with overridden digests stripped), so its line numbers are not Source's.
+	Rewritten bool `json:"rewritten,omitempty"`
+}
+
+func layerFiles(layers []ValuesLayer) []string {
+	if len(layers) == 0 {
+		return nil
+	}
+	files := make([]string, len(layers))
+	for i, l := range layers {
+		files[i] = l.File
+	}
+	return files
+}
+
+// buildValuesLayers mirrors BuildValuesChain, keeping the source of each file.
+func buildValuesLayers(common, overlays []ValuesLayer, extra []string, scenario []ValuesLayer, debugFile string) []ValuesLayer {
+	layers := make([]ValuesLayer, 0, len(common)+len(overlays)+len(extra)+len(scenario)+1)
+	layers = append(layers, common...)
+	layers = append(layers, overlays...)
+	for _, f := range extra {
+		layers = append(layers, ValuesLayer{Group: LayerExtra, Source: f, File: f})
+	}
+	layers = append(layers, scenario...)
+	if debugFile != "" {
+		layers = append(layers, ValuesLayer{Group: LayerDebug, Source: "--debug (generated)", File: debugFile, Rewritten: true})
+	}
+	return layers
+}
+
+// ValueSource is one place a values key was set.
+type ValueSource struct {
+	Layer string `json:"layer"`
+	File  string `json:"file"`
+	// Line is 1-based; 0 when the file was generated or rewritten.
+	Line  int `json:"line,omitempty"`
+	Value any `json:"value"`
+}
+
+// ValueOrigin explains one leaf of the merged values: where its final value
+// was set and which earlier layers it overrode.
+type ValueOrigin struct {
+	// Path is the dotted key path. Items of name-keyed lists are addressed
+	// as [name=X], other list items by index.
+	Path   string      `json:"path"`
+	Value  any         `json:"value"`
+	Source ValueSource `json:"source"`
+	// Overridden lists earlier settings of this key, most recent first.
+	Overridden []ValueSource `json:"overridden,omitempty"`
+}
+
+// ExplainValues merges layers the way a deploy does and returns the
+// provenance of every leaf, sorted by path. Scenario layers marked NameMerged
+// are first merged with MergeYAMLFiles semantics (name-keyed arrays merged,
+// other arrays concatenated); the resulting chain is then merged the way Helm
+// merges -f files (maps merged, everything else replaced).
+//
+// Chart defaults (the chart's own values.yaml) are not part of the chain.
+func ExplainValues(layers []ValuesLayer) ([]ValueOrigin, error) {
+	merged, err := mergeValuesTrace(layers)
+	if err != nil {
+		return nil, err
+	}
+	var out []ValueOrigin
+	if merged != nil {
+		merged.flatten("", func(path string, n *traceNode) {
+			out = append(out, ValueOrigin{Path: path, Value: n.plain(), Source: n.source(), Overridden: n.overridden})
+		})
+	}
+	return out, nil
+}
+
+// mergeValuesTrace merges layers as ExplainValues describes and returns the
+// traced result, nil when every layer is empty.
+func mergeValuesTrace(layers []ValuesLayer) (*traceNode, error) {
+	// MergeYAMLFiles drops "imports" from every scenario layer, but only when
+	// there is more than one file to merge.
+	var nameMerged int
+	for _, l := range layers {
+		if l.NameMerged {
+			nameMerged++
+		}
+	}
+
+	var merged, group *traceNode
+	flush := func() {
+		if group != nil {
+			merged = helmMergeTrace(merged, group)
+			group = nil
+		}
+	}
+	for _, l := range layers {
+		node, err := loadTraceLayer(l)
+		if err != nil {
+			return nil, err
+		}
+		if !l.NameMerged {
+			flush()
+			if node != nil {
+				merged = helmMergeTrace(merged, node)
+			}
+			continue
+		}
+		if node == nil {
+			continue
+		}
+		if nameMerged > 1 {
+			delete(node.fields, "imports")
+		}
+		if group == nil {
+			group = node
+		} else {
+			group = layeredMergeTrace(group, node)
+		}
+	}
+	flush()
+	return merged, nil
+}
+
+// ExplainScenarioValues prepares the values of the first scenario in flags
+// exactly as a deploy would, with unset env vars rendered as placeholders
+// (see RenderReleases), and explains the result. flags is updated in place.
+func ExplainScenarioValues(ctx context.Context, flags *config.RuntimeFlags) ([]ValueOrigin, error) {
+	if len(flags.Deployment.Scenarios) == 0 {
+		return nil, fmt.Errorf("no scenario selected")
+	}
+	flags.Interactive = false
+	flags.Secrets.AutoGenerateSecrets = false
+
+	scenario := flags.Deployment.Scenarios[0]
+	scenarioCtx, err := generateScenarioContext(scenario, flags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate scenario context for %s: %w", scenario, err)
+	}
+	prepared, err := prepareRenderValues(ctx, scenarioCtx, flags)
+	if err != nil {
+		return nil, fmt.Errorf("scenario %q failed during preparation: %w", scenario, err)
+	}
+	defer os.RemoveAll(prepared.TempDir)
+	return ExplainValues(prepared.ValuesLayers)
+}
+
+// FilterValueOrigins keeps the origins at or below keyPath. An empty keyPath
+// keeps everything.
+func FilterValueOrigins(origins []ValueOrigin, keyPath string) []ValueOrigin {
+	keyPath = strings.TrimSpace(keyPath)
+	if keyPath == "" {
+		return origins
+	}
+	var out []ValueOrigin
+	for _, o := range origins {
+		if o.Path == keyPath || strings.HasPrefix(o.Path, keyPath+".") || strings.HasPrefix(o.Path, keyPath+"[") {
+			out = append(out, o)
+		}
+	}
+	return out
+}
+
+// traceNode is a values tree node that remembers where it was set.
+type traceNode struct {
+	kind       yaml.Kind
+	fields     map[string]*traceNode
+	items      []*traceNode
+	value      any
+	layer      string
+	file       string
+	line       int
+	overridden []ValueSource
+}
+
+func loadTraceLayer(l ValuesLayer) (*traceNode, error) {
+	data, err := os.ReadFile(l.File)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read values layer %q: %w", l.Source, err)
+	}
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse values layer %q: %w", l.Source, err)
+	}
+	if len(doc.Content) == 0 {
+		return nil, nil
+	}
+	root := doc.Content[0]
+	if root.Kind == yaml.ScalarNode && root.Tag == "!!null" {
+		return nil, nil
+	}
+	if root.Kind != yaml.MappingNode {
+		return nil, fmt.Errorf("values layer %q is not a YAML mapping", l.Source)
+	}
+	return newTraceNode(root, l)
+}
+
+func newTraceNode(n *yaml.Node, l ValuesLayer) (*traceNode, error) {
+	line := n.Line
+	if l.Rewritten {
+		line = 0
+	}
+	if n.Kind == yaml.AliasNode {
+		n = n.Alias
+	}
+	t := &traceNode{kind: n.Kind, layer: l.Group, file: l.Source, line: line}
+	switch n.Kind {
+	case yaml.MappingNode:
+		t.fields = map[string]*traceNode{}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			k, v := n.Content[i], n.Content[i+1]
+			if k.Tag == "!!merge" {
+				if err := t.mergeKey(v, l); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			child, err := newTraceNode(v, l)
+			if err != nil {
+				return nil, err
+			}
+			t.fields[k.Value] = child
+		}
+	case yaml.SequenceNode:
+		for _, item := range n.Content {
+			child, err := newTraceNode(item, l)
+			if err != nil {
+				return nil, err
+			}
+			t.items = append(t.items, child)
+		}
+	default:
+		if err := n.Decode(&t.value); err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", l.Source, n.Line, err)
+		}
+	}
+	return t, nil
+}
+
+// mergeKey applies a YAML "<<" merge key: fields from the referenced
+// mapping(s) that the node does not set itself.
+func (t *traceNode) mergeKey(v *yaml.Node, l ValuesLayer) error {
+	sources := []*yaml.Node{v}
+	if v.Kind == yaml.SequenceNode {
+		sources = v.Content
+	}
+	for _, src := range sources {
+		m, err := newTraceNode(src, l)
+		if err != nil {
+			return err
+		}
+		for k, child := range m.fields {
+			if _, ok := t.fields[k]; !ok {
+				t.fields[k] = child
+			}
+		}
+	}
+	return nil
+}
+
+// helmMergeTrace merges src over dst like Helm merges -f files: maps are
+// merged key by key, anything else in src replaces dst.
+func helmMergeTrace(dst, src *traceNode) *traceNode {
+	if dst == nil {
+		return src
+	}
+	if dst.kind != yaml.MappingNode || src.kind != yaml.MappingNode {
+		return overrideTrace(dst, src)
+	}
+	for k, sv := range src.fields {
+		if dv, ok := dst.fields[k]; ok {
+			dst.fields[k] = helmMergeTrace(dv, sv)
+		} else {
+			dst.fields[k] = sv
+		}
+	}
+	return dst
+}
+
+// layeredMergeTrace mirrors deepMergeMaps for the scenario layers.
+func layeredMergeTrace(dst, src *traceNode) *traceNode {
+	for k, sv := range src.fields {
+		dv, ok := dst.fields[k]
+		if !ok {
+			dst.fields[k] = sv
+			continue
+		}
+		switch {
+		case dv.kind == yaml.MappingNode && sv.kind == yaml.MappingNode:
+			dst.fields[k] = layeredMergeTrace(dv, sv)
+		case dv.kind == yaml.SequenceNode && sv.kind == yaml.SequenceNode:
+			dst.fields[k] = layeredMergeSeq(dv, sv)
+		default:
+			dst.fields[k] = overrideTrace(dv, sv)
+		}
+	}
+	return dst
+}
+
+// layeredMergeSeq mirrors mergeArrays: name-keyed arrays are merged by name,
+// others concatenated without duplicates.
+func layeredMergeSeq(dst, src *traceNode) *traceNode {
+	if !dst.nameKeyed() || !src.nameKeyed() {
+		seen := map[string]bool{}
+		for _, it := range dst.items {
+			seen[yamlFingerprint(it.plain())] = true
+		}
+		for _, it := range src.items {
+			if fp := yamlFingerprint(it.plain()); !seen[fp] {
+				dst.items = append(dst.items, it)
+				seen[fp] = true
+			}
+		}
+		return dst
+	}
+	index := map[string]int{}
+	for i, it := range dst.items {
+		if name, ok := it.fields["name"].plain().(string); ok {
+			index[name] = i
+		}
+	}
+	for _, it := range src.items {
+		name, ok := it.fields["name"].plain().(string)
+		if !ok {
+			dst.items = append(dst.items, it)
+			continue
+		}
+		if i, found := index[name]; found {
+			dst.items[i] = layeredMergeTrace(dst.items[i], it)
+		} else {
+			dst.items = append(dst.items, it)
+			index[name] = len(dst.items) - 1
+		}
+	}
+	return dst
+}
+
+// nameKeyed matches isNameKeyedArray.
+func (t *traceNode) nameKeyed() bool {
+	for _, it := range t.items {
+		if it.kind != yaml.MappingNode {
+			return false
+		}
+		if _, ok := it.fields["name"]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// overrideTrace returns src, which replaces dst, carrying dst's history onto
+// the leaves of src at the same paths. A leaf that replaces a whole map or
+// list records that container as what it overrode.
+func overrideTrace(dst, src *traceNode) *traceNode {
+	if src.isLeaf() {
+		src.overridden = append(src.overridden, dst.history()...)
+		return src
+	}
+	old := map[string]*traceNode{}
+	dst.flatten("", func(path string, n *traceNode) { old[path] = n })
+	src.flatten("", func(path string, n *traceNode) {
+		if o, ok := old[path]; ok {
+			n.overridden = append(n.overridden, o.history()...)
+		}
+	})
+	return src
+}
+
+// history is the node's own setting followed by what it overrode.
+func (t *traceNode) history() []ValueSource {
+	return append([]ValueSource{t.source()}, t.overridden...)
+}
+
+func (t *traceNode) source() ValueSource {
+	return ValueSource{Layer: t.layer, File: t.file, Line: t.line, Value: t.plain()}
+}
+
+func (t *traceNode) isLeaf() bool {
+	switch t.kind {
+	case yaml.MappingNode:
+		return len(t.fields) == 0
+	case yaml.SequenceNode:
+		return len(t.items) == 0
+	}
+	return true
+}
+
+// flatten calls fn for every leaf (scalar, null, empty map or empty list)
+// in path order.
+func (t *traceNode) flatten(path string, fn func(string, *traceNode)) {
+	if t.isLeaf() {
+		fn(path, t)
+		return
+	}
+	switch t.kind {
+	case yaml.MappingNode:
+		keys := make([]string, 0, len(t.fields))
+		for k := range t.fields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			t.fields[k].flatten(joinValuesPath(path, k), fn)
+		}
+	case yaml.SequenceNode:
+		names := t.uniqueNames()
+		for i, it := range t.items {
+			if names != nil {
+				it.flatten(fmt.Sprintf("%s[name=%s]", path, names[i]), fn)
+			} else {
+				it.flatten(fmt.Sprintf("%s[%d]", path, i), fn)
+			}
+		}
+	}
+}
+
+// uniqueNames returns each item's "name" when all items have a distinct
+// string name, or nil.
+func (t *traceNode) uniqueNames() []string {
+	names := make([]string, len(t.items))
+	seen := map[string]bool{}
+	for i, it := range t.items {
+		if it.kind != yaml.MappingNode {
+			return nil
+		}
+		name, ok := it.fields["name"].plain().(string)
+		if !ok || name == "" || seen[name] {
+			return nil
+		}
+		seen[name] = true
+		names[i] = name
+	}
+	return names
+}
+
+// plain converts the node back to the value yaml.Unmarshal would give.
+func (t *traceNode) plain() any {
+	if t == nil {
+		return nil
+	}
+	switch t.kind {
+	case yaml.MappingNode:
+		m := make(map[string]any, len(t.fields))
+		for k, v := range t.fields {
+			m[k] = v.plain()
+		}
+		return m
+	case yaml.SequenceNode:
+		l := make([]any, len(t.items))
+		for i, v := range t.items {
+			l[i] = v.plain()
+		}
+		return l
+	}
+	return t.value
+}
+
+func joinValuesPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return path + fmt.Sprintf("[%q]", key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
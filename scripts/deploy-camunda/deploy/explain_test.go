@@ -0,0 +1,264 @@
+package deploy
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func writeLayer(t *testing.T, dir, name, content string) string {
+	t.Helper()
+	p := filepath.Join(dir, name)
+	if err := os.WriteFile(p, []byte(content), 0o644); err != nil {
+		t.Fatalf("write %s: %v", name, err)
+	}
+	return p
+}
+
+func originByPath(t *testing.T, origins []ValueOrigin, path string) ValueOrigin {
+	t.Helper()
+	for _, o := range origins {
+		if o.Path == path {
+			return o
+		}
+	}
+	t.Fatalf("no origin for %q in %+v", path, origins)
+	return ValueOrigin{}
+}
+
+func TestExplainValuesScenarioLayersMergeByName(t *testing.T) {
+	dir := t.TempDir()
+	base := writeLayer(t, dir, "base.yaml", `imports: [x]
+orchestration:
+  env:
+    - name: A
+      value: "1"
+    - name: B
+      value: "2"
+  args: ["--one"]
+`)
+	feature := writeLayer(t, dir, "feature.yaml", `orchestration:
+  env:
+    - name: B
+      value: "20"
+    - name: C
+      value: "3"
+  args: ["--two"]
+`)
+	origins, err := ExplainValues([]ValuesLayer{
+		{Group: LayerScenario, Source: base, File: base, NameMerged: true},
+		{Group: LayerScenario, Source: feature, File: feature, NameMerged: true},
+	})
+	if err != nil {
+		t.Fatalf("ExplainValues: %v", err)
+	}
+
+	b := originByPath(t, origins, "orchestration.env[name=B].value")
+	if b.Value != "20" || b.Source.File != feature || b.Source.Line != 4 {
+		t.Fatalf("env B = %+v", b)
+	}
+	if want := []ValueSource{{Layer: LayerScenario, File: base, Line: 7, Value: "2"}}; !reflect.DeepEqual(b.Overridden, want) {
+		t.Fatalf("env B overridden = %+v, want %+v", b.Overridden, want)
+	}
+	if a := originByPath(t, origins, "orchestration.env[name=A].value"); a.Source.File != base || len(a.Overridden) != 0 {
+		t.Fatalf("env A = %+v", a)
+	}
+	originByPath(t, origins, "orchestration.env[name=C].value")
+
+	// Unnamed arrays are concatenated, as MergeYAMLFiles does.
+	originByPath(t, origins, "orchestration.args[0]")
+	if two := originByPath(t, origins, "orchestration.args[1]"); two.Value != "--two" {
+		t.Fatalf("args[1] = %+v", two)
+	}
+	for _, o := range origins {
+		if o.Path == "imports[0]" {
+			t.Fatal("imports should be dropped when scenario layers are merged")
+		}
+	}
+}
+
+func TestExplainValuesHelmReplacesListsAcrossLayers(t *testing.T) {
+	dir := t.TempDir()
+	common := writeLayer(t, dir, "common.yaml", `global:
+  image:
+    tag: "8.8.0"
+orchestration:
+  env:
+    - name: A
+      value: "1"
+    - name: B
+      value: "2"
+`)
+	scenario := writeLayer(t, dir, "scenario.yaml", `global:
+  image:
+    tag: "8.9.0"
+orchestration:
+  env:
+    - name: A
+      value: "10"
+`)
+	origins, err := ExplainValues([]ValuesLayer{
+		{Group: LayerCommon, Source: "common/values.yaml", File: common},
+		{Group: LayerScenario, Source: scenario, File: scenario},
+	})
+	if err != nil {
+		t.Fatalf("ExplainValues: %v", err)
+	}
+
+	tag := originByPath(t, origins, "global.image.tag")
+	if tag.Value != "8.9.0" || len(tag.Overridden) != 1 || tag.Overridden[0].File != "common/values.yaml" || tag.Overridden[0].Value != "8.8.0" {
+		t.Fatalf("tag = %+v", tag)
+	}
+	// Helm replaces lists wholesale: B is gone, A keeps A's history.
+	a := originByPath(t, origins, "orchestration.env[name=A].value")
+	if a.Value != "10" || len(a.Overridden) != 1 || a.Overridden[0].Value != "1" {
+		t.Fatalf("env A = %+v", a)
+	}
+	for _, o := range origins {
+		if o.Path == "orchestration.env[name=B].value" {
+			t.Fatal("env B should be replaced by the scenario list")
+		}
+	}
+}
+
+func TestExplainValuesRewrittenLayerHasNoLines(t *testing.T) {
+	dir := t.TempDir()
+	overlay := writeLayer(t, dir, "values-digest.yaml", "image:\n  digest: sha256:abc\n")
+	origins, err := ExplainValues([]ValuesLayer{{Group: LayerOverlay, Source: "values-digest.yaml", File: overlay, Rewritten: true}})
+	if err != nil {
+		t.Fatalf("ExplainValues: %v", err)
+	}
+	if got := originByPath(t, origins, "image.digest"); got.Source.Line != 0 {
+		t.Fatalf("line = %d, want 0 for a rewritten layer", got.Source.Line)
+	}
+}
+
+func TestExplainValuesScalarReplacesMap(t *testing.T) {
+	dir := t.TempDir()
+	first := writeLayer(t, dir, "a.yaml", "identity:\n  clients:\n    - id: venom\n")
+	second := writeLayer(t, dir, "b.yaml", "identity:\n  clients: null\n")
+	origins, err := ExplainValues([]ValuesLayer{
+		{Group: LayerCommon, Source: first, File: first},
+		{Group: LayerExtra, Source: second, File: second},
+	})
+	if err != nil {
+		t.Fatalf("ExplainValues: %v", err)
+	}
+	got := originByPath(t, origins, "identity.clients")
+	if got.Value != nil || len(got.Overridden) != 1 || got.Overridden[0].Line != 3 {
+		t.Fatalf("identity.clients = %+v", got)
+	}
+}
+
+func TestFilterValueOrigins(t *testing.T) {
+	origins := []ValueOrigin{
+		{Path: "orchestration.env[name=A].value"},
+		{Path: "orchestration.enabled"},
+		{Path: "orchestrationX.enabled"},
+		{Path: "global.host"},
+	}
+	got := FilterValueOrigins(origins, "orchestration")
+	if len(got) != 2 {
+		t.Fatalf("FilterValueOrigins = %+v", got)
+	}
+	if got := FilterValueOrigins(origins, "orchestration.env"); len(got) != 1 {
+		t.Fatalf("FilterValueOrigins(env) = %+v", got)
+	}
+	if got := FilterValueOrigins(origins, ""); len(got) != len(origins) {
+		t.Fatalf("empty filter dropped origins: %+v", got)
+	}
+}
+
+// TestExplainValuesTraceMatchesMergeYAMLFiles keeps the traced scenario merge
+// in step with MergeYAMLFiles, which is what a deploy actually hands to Helm:
+// the traced final values must equal the merged file for fixtures mixing
+// nested maps, name-keyed and plain lists, null overrides, type changes,
+// anchors and merge keys.
+func TestExplainValuesTraceMatchesMergeYAMLFiles(t *testing.T) {
+	dir := t.TempDir()
+	files := []string{
+		writeLayer(t, dir, "base.yaml", `imports: [base]
+defaults: &defaults
+  replicas: 1
+  resources:
+    limits: {cpu: "1", memory: 1Gi}
+orchestration:
+  <<: *defaults
+  replicas: 3
+  env:
+    - name: A
+      value: "1"
+    - name: B
+      valueFrom:
+        secretKeyRef: {name: s, key: k}
+  args: ["--one", "--two"]
+  tolerations: []
+  nodeSelector:
+    zone: a
+connectors:
+  enabled: true
+  image: {tag: "8.8.0"}
+`),
+		writeLayer(t, dir, "feature.yaml", `imports: [feature]
+orchestration:
+  env:
+    - name: B
+      value: "2"
+    - name: C
+      value: "3"
+  args: ["--two", "--three"]
+  tolerations:
+    - key: dedicated
+      operator: Exists
+  nodeSelector: null
+  resources:
+    limits:
+      memory: 2Gi
+connectors:
+  image: null
+  extra: [1, 2]
+`),
+		writeLayer(t, dir, "empty.yaml", "# nothing here\n"),
+		writeLayer(t, dir, "last.yaml", `orchestration:
+  replicas: null
+  env:
+    - name: A
+      value: null
+    - {name: D}
+  args: "--replaced"
+connectors:
+  enabled: "false"
+  extra: [2, 3]
+identity: {}
+`),
+	}
+
+	mergedPath, err := MergeYAMLFiles(files, filepath.Join(dir, "merged.yaml"))
+	if err != nil {
+		t.Fatalf("MergeYAMLFiles: %v", err)
+	}
+	data, err := os.ReadFile(mergedPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want map[string]any
+	if err := yaml.Unmarshal(data, &want); err != nil {
+		t.Fatal(err)
+	}
+
+	layers := make([]ValuesLayer, 0, len(files))
+	for _, f := range files {
+		layers = append(layers, ValuesLayer{Group: LayerScenario, Source: f, File: f, NameMerged: true})
+	}
+	trace, err := mergeValuesTrace(layers)
+	if err != nil {
+		t.Fatalf("mergeValuesTrace: %v", err)
+	}
+	if got := trace.plain(); !reflect.DeepEqual(got, any(want)) {
+		gotYAML, _ := yaml.Marshal(got)
+		t.Fatalf("traced values differ from MergeYAMLFiles\ntrace:\n%s\nmerged:\n%s", gotYAML, data)
+	}
+}
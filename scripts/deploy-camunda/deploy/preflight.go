@@ -87,7 +87,7 @@ type PreflightOptions struct {
 // Report. It has no side effects: it never writes files, mutates the process
 // environment, or contacts the cluster beyond an optional read-only readiness
 // ping. Sources are resolved exactly as a deploy would see them — process
-// environment overlaid with the configured .env file.
+// environment overlaid with secret sources and the configured .env file.
 func Preflight(ctx context.Context, flags *config.RuntimeFlags, opts PreflightOptions) *Report {
 	r := &Report{}
 	// baseEnv is process env + .env + ExtraEnv. deployEnv additionally includes the
@@ -100,12 +100,17 @@ func Preflight(ctx context.Context, flags *config.RuntimeFlags, opts PreflightOp
 	// the check both validates the path and rewrites flags.Chart.ChartPath to the
 	// resolved directory the scenario checks below depend on.
 	chartCheck := checkChartPath(flags)
+	// Load secret sources before computing the env so their keys count as set.
+	sourcesCheck := checkSecretSources(ctx, flags)
 
 	baseEnv := effectiveEnv(flags)
 	deployEnv := scenarioDeployEnv(flags, baseEnv)
 
 	r.Checks = append(r.Checks, checkConfigFile(opts))
 	r.Checks = append(r.Checks, chartCheck)
+	if sourcesCheck != nil {
+		r.Checks = append(r.Checks, *sourcesCheck)
+	}
 	r.Checks = append(r.Checks, checkKubeContext(ctx, flags, opts))
 	r.Checks = append(r.Checks, checkDockerCredentials(flags, baseEnv)...)
 	r.Checks = append(r.Checks, checkVaultMapping(flags, baseEnv))
@@ -216,6 +221,28 @@ func checkConfigFile(opts PreflightOptions) Check {
 	}
 }
 
+// checkSecretSources loads the configured secretSources (a read-only fetch)
+// and reports how many variables they supplied. It returns nil when the
+// profile configures none, so the checklist stays unchanged for .env users.
+func checkSecretSources(ctx context.Context, flags *config.RuntimeFlags) *Check {
+	if len(flags.Secrets.Sources) == 0 {
+		return nil
+	}
+	if err := LoadSecretSources(ctx, flags); err != nil {
+		return &Check{
+			Name:        "secret sources",
+			Status:      StatusFail,
+			Detail:      err.Error(),
+			Remediation: "check the secretSources entry in your config and that you are logged in to the store",
+		}
+	}
+	return &Check{
+		Name:   "secret sources",
+		Status: StatusOK,
+		Detail: fmt.Sprintf("%d variable(s) from %d source(s)", len(flags.Secrets.SourceEnv), len(flags.Secrets.Sources)),
+	}
+}
+
 func checkKubeContext(ctx context.Context, flags *config.RuntimeFlags, opts PreflightOptions) Check {
 	kubeCtx := flags.Test.KubeContext
 	if kubeCtx == "" {
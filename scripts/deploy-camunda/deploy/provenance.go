@@ -13,13 +13,23 @@ import (
 type EnvVar struct {
 	Name   string
 	Value  string
-	Origin string // "process-env", ".env (<path>)", or "extra-env"
+	Origin string // "process-env", "secret-source (<name>)", ".env (<path>)", or "extra-env"
 }
 
+// Origins of an EnvVar. Secret-source and .env origins are followed by the
+// source name or the file path in parentheses.
+const (
+	OriginProcessEnv   = "process-env"
+	OriginSecretSource = "secret-source"
+	OriginDotEnv       = ".env"
+	OriginExtraEnv     = "extra-env"
+)
+
 // EnvProvenance returns the effective environment a deploy would see, in the
 // same layering buildScenarioEnv uses, annotated with the winning source per
-// key. Later layers override earlier ones: process env → .env file → per-entry
-// ExtraEnv. The result is sorted by name. Values are returned unmasked; callers
+// key. Later layers override earlier ones: process env → secret sources → .env
+// file → per-entry ExtraEnv. Secret sources appear only once LoadSecretSources
+// has run. The result is sorted by name. Values are returned unmasked; callers
 // are responsible for masking secrets in human-facing output (see
 // values.IsSecretName).
 func EnvProvenance(flags *config.RuntimeFlags) []EnvVar {
@@ -29,16 +39,21 @@ func EnvProvenance(flags *config.RuntimeFlags) []EnvVar {
 	for _, e := range os.Environ() {
 		if k, v, ok := strings.Cut(e, "="); ok {
 			value[k] = v
-			origin[k] = "process-env"
+			origin[k] = OriginProcessEnv
 		}
 	}
 
+	for k, v := range flags.Secrets.SourceEnv {
+		value[k] = v
+		origin[k] = OriginSecretSource + " (" + flags.Secrets.SourceOrigins[k] + ")"
+	}
+
 	envFile := flags.EnvFile
 	if envFile == "" {
 		envFile = ".env"
 	}
 	if dotenv, err := env.ReadFile(envFile); err == nil {
-		src := ".env (" + envFile + ")"
+		src := OriginDotEnv + " (" + envFile + ")"
 		for k, v := range dotenv {
 			value[k] = v
 			origin[k] = src
@@ -47,7 +62,7 @@ func EnvProvenance(flags *config.RuntimeFlags) []EnvVar {
 
 	for k, v := range flags.ExtraEnv {
 		value[k] = v
-		origin[k] = "extra-env"
+		origin[k] = OriginExtraEnv
 	}
 
 	names := make([]string, 0, len(value))
@@ -54,3 +54,52 @@ func TestEnvProvenance(t *testing.T) {
 		}
 	}
 }
+
+func TestEnvProvenanceSecretSources(t *testing.T) {
+	t.Setenv("PROV_SRC_OVER_PROCESS", "from-process")
+	t.Setenv("PROV_SRC_ONLY", "")
+
+	dir := t.TempDir()
+	envFile := filepath.Join(dir, ".env")
+	if err := os.WriteFile(envFile, []byte("PROV_DOTENV_OVER_SRC=from-dotenv\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	flags := &config.RuntimeFlags{EnvFile: envFile}
+	flags.Secrets.SourceEnv = map[string]string{
+		"PROV_SRC_OVER_PROCESS": "from-vault",
+		"PROV_SRC_ONLY":         "v",
+		"PROV_DOTENV_OVER_SRC":  "from-vault",
+	}
+	flags.Secrets.SourceOrigins = map[string]string{
+		"PROV_SRC_OVER_PROCESS": "vault secret/ci",
+		"PROV_SRC_ONLY":         "vault secret/ci",
+		"PROV_DOTENV_OVER_SRC":  "vault secret/ci",
+	}
+
+	got := map[string]EnvVar{}
+	for _, e := range EnvProvenance(flags) {
+		got[e.Name] = e
+	}
+	want := map[string]EnvVar{
+		"PROV_SRC_OVER_PROCESS": {Value: "from-vault", Origin: "secret-source (vault secret/ci)"},
+		"PROV_SRC_ONLY":         {Value: "v", Origin: "secret-source (vault secret/ci)"},
+		// .env sits above secret sources so a developer can override one key.
+		"PROV_DOTENV_OVER_SRC": {Value: "from-dotenv", Origin: ".env (" + envFile + ")"},
+	}
+	for name, w := range want {
+		if got[name].Value != w.Value || got[name].Origin != w.Origin {
+			t.Errorf("%s = %+v, want value %q origin %q", name, got[name], w.Value, w.Origin)
+		}
+	}
+
+	// buildScenarioEnv layers the same way.
+	scenarioEnv, err := buildScenarioEnv(&ScenarioContext{}, flags)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, w := range want {
+		if scenarioEnv[name] != w.Value {
+			t.Errorf("buildScenarioEnv[%s] = %q, want %q", name, scenarioEnv[name], w.Value)
+		}
+	}
+}
@@ -0,0 +1,144 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/config"
+	"scripts/deploy-camunda/render"
+	"scripts/prepare-helm-values/pkg/values"
+)
+
+// renderPlaceholderRounds bounds how often RenderReleases retries values
+// preparation after filling in unset env vars. Each round covers the names
+// one values file reported, so this is far above any real layer count.
+const renderPlaceholderRounds = 32
+
+// RenderPlaceholder is the value an unset env var gets in an offline render:
+// its own ${NAME} reference, so the manifest shows which input was missing.
+func RenderPlaceholder(name string) string {
+	return "${" + name + "}"
+}
+
+// RenderReleases runs the values pipeline Execute uses for every scenario in
+// flags and returns the Helm releases a deploy would install — companion
+// charts first, then the Camunda chart — without contacting a cluster.
+//
+// Two things differ from a deploy so that rendering the same tree twice gives
+// the same manifests: env vars that are unset render as RenderPlaceholder
+// instead of failing, and no test secrets are generated (they only feed the
+// vault-mapped Secret, never Helm values). flags is updated in place.
+//
+// The returned cleanup removes the prepared values files; call it once the
+// releases have been rendered, even on error.
+func RenderReleases(ctx context.Context, flags *config.RuntimeFlags) ([]render.Release, func(), error) {
+	flags.Interactive = false
+	flags.Secrets.AutoGenerateSecrets = false
+
+	var tempDirs []string
+	cleanup := func() {
+		for _, dir := range tempDirs {
+			os.RemoveAll(dir)
+		}
+	}
+
+	var releases []render.Release
+	for _, scenario := range flags.Deployment.Scenarios {
+		scenarioCtx, err := generateScenarioContext(scenario, flags)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("failed to generate scenario context for %s: %w", scenario, err)
+		}
+		prepared, err := prepareRenderValues(ctx, scenarioCtx, flags)
+		if err != nil {
+			return nil, cleanup, fmt.Errorf("scenario %q failed during preparation: %w", scenario, err)
+		}
+		tempDirs = append(tempDirs, prepared.TempDir)
+
+		for _, cc := range prepared.CompanionCharts {
+			rel := render.Release{
+				Name:      cc.ReleaseName,
+				Namespace: scenarioCtx.Namespace,
+				Chart:     cc.ChartRef,
+				Version:   cc.Version,
+				RepoURL:   cc.RepoURL,
+				Companion: true,
+			}
+			if cc.ValuesFile != "" {
+				rel.ValuesFiles = []string{cc.ValuesFile}
+			}
+			releases = append(releases, rel)
+		}
+
+		main := render.Release{
+			Name:        scenarioCtx.Release,
+			Namespace:   scenarioCtx.Namespace,
+			Chart:       flags.Chart.ChartPath,
+			ValuesFiles: prepared.ValuesFiles,
+			Set:         sortedSetPairs(flags.Deployment.ExtraHelmSets),
+		}
+		if flags.Chart.Chart != "" {
+			main.Chart = flags.Chart.Chart
+			main.Version = flags.Chart.ChartVersion
+		}
+		releases = append(releases, main)
+	}
+	return releases, cleanup, nil
+}
+
+// prepareRenderValues is prepareScenarioValues with unset env vars filled in
+// as placeholders. Companion allowlists are known up front; scenario
+// placeholders are discovered from values.MissingEnvError one file at a time.
+func prepareRenderValues(ctx context.Context, scenarioCtx *ScenarioContext, flags *config.RuntimeFlags) (*PreparedScenario, error) {
+	if flags.ExtraEnv == nil {
+		flags.ExtraEnv = map[string]string{}
+	}
+	env, err := buildScenarioEnv(scenarioCtx, flags)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build scenario env: %w", err)
+	}
+	var filled []string
+	for _, cc := range flags.CompanionCharts {
+		for _, name := range cc.EnvVars {
+			if _, ok := env[name]; !ok {
+				flags.ExtraEnv[name] = RenderPlaceholder(name)
+				filled = append(filled, name)
+			}
+		}
+	}
+
+	for round := 0; ; round++ {
+		prepared, err := prepareScenarioValues(ctx, scenarioCtx, flags)
+		missingEnv, names := values.IsMissingEnv(err)
+		if !missingEnv || round == renderPlaceholderRounds {
+			if len(filled) > 0 {
+				sort.Strings(filled)
+				logging.Logger.Warn().
+					Str("scenario", scenarioCtx.ScenarioName).
+					Strs("vars", filled).
+					Msg("Unset env vars rendered as ${NAME} placeholders")
+			}
+			return prepared, err
+		}
+		for _, name := range names {
+			flags.ExtraEnv[name] = RenderPlaceholder(name)
+		}
+		filled = append(filled, names...)
+	}
+}
+
+// sortedSetPairs formats --extra-helm-set pairs as key=value, sorted so the
+// rendered output does not depend on map order.
+func sortedSetPairs(sets map[string]string) []string {
+	if len(sets) == 0 {
+		return nil
+	}
+	pairs := make([]string, 0, len(sets))
+	for k, v := range sets {
+		pairs = append(pairs, k+"="+v)
+	}
+	sort.Strings(pairs)
+	return pairs
+}
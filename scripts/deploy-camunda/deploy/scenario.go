@@ -45,21 +45,26 @@ type ScenarioResult struct {
 	KeycloakRealm            string
 	OptimizeIndexPrefix      string
 	OrchestrationIndexPrefix string
-	TestEnvFile              string   // Path to generated E2E test .env file
-	LayeredFiles             []string // Source values files resolved from layers (pre-processing)
+	TestEnvFile              string       // Path to generated E2E test .env file
+	LayeredFiles             []string     // Source values files resolved from layers (pre-processing)
+	Smoke                    []SmokeCheck // Per-component results of the --smoke suite
 	Error                    error
 }
 
 // PreparedScenario holds the result of values preparation for a scenario,
 // ready to be deployed in parallel.
 type PreparedScenario struct {
-	ScenarioCtx         *ScenarioContext
-	ValuesFiles         []string
-	LayeredFiles        []string // Source values files resolved from layers (pre-processing)
+	ScenarioCtx  *ScenarioContext
+	ValuesFiles  []string
+	LayeredFiles []string // Source values files resolved from layers (pre-processing)
+	// ValuesLayers records where every file that fed ValuesFiles came from,
+	// in precedence order, for ExplainValues.
+	ValuesLayers        []ValuesLayer
 	VaultSecretPath     string
 	CompanionCharts     []config.CompanionChart
 	TempDir             string
 	RealmName           string
+	Identity            string // Resolved identity selection (keycloak, oidc, auth0, basic, ...)
 	OptimizePrefix      string
 	OrchestrationPrefix string
 	// Secrets holds auto-generated test credentials (DISTRO_QA_* passwords, keycloak
@@ -0,0 +1,31 @@
+package deploy
+
+import (
+	"context"
+
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/config"
+	"scripts/deploy-camunda/secretsource"
+)
+
+// LoadSecretSources loads the profile's secretSources into
+// flags.Secrets.SourceEnv so buildScenarioEnv, EnvProvenance and the preflight
+// all see the same values. It runs at most once per flags: later calls (e.g.
+// Execute after the root pre-run already loaded them) are no-ops. The process
+// environment is never modified.
+func LoadSecretSources(ctx context.Context, flags *config.RuntimeFlags) error {
+	if len(flags.Secrets.Sources) == 0 || flags.Secrets.SourceEnv != nil {
+		return nil
+	}
+	values, origins, err := secretsource.LoadAll(ctx, flags.Secrets.Sources)
+	if err != nil {
+		return err
+	}
+	flags.Secrets.SourceEnv = values
+	flags.Secrets.SourceOrigins = origins
+	logging.Logger.Info().
+		Int("sources", len(flags.Secrets.Sources)).
+		Int("variables", len(values)).
+		Msg("Loaded secret sources")
+	return nil
+}
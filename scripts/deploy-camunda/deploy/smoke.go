@@ -0,0 +1,498 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"scripts/camunda-core/pkg/kube"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/auth0"
+	"scripts/deploy-camunda/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// Smoke check statuses.
+const (
+	SmokePass = "pass"
+	SmokeFail = "fail"
+	SmokeSkip = "skip"
+)
+
+// SmokeCheck is the outcome of one component's post-deploy smoke check.
+type SmokeCheck struct {
+	Component string
+	Status    string // SmokePass, SmokeFail or SmokeSkip
+	Detail    string
+}
+
+//go:embed data/smoke.bpmn
+var smokeBPMN []byte
+
+const (
+	smokeProcessID = "deploy-camunda-smoke"
+	// smokeKeycloakRealm is the realm every Keycloak-backed scenario layer
+	// provisions the venom client in.
+	smokeKeycloakRealm = "camunda-platform"
+	// smokeBasicUser / smokeBasicPassword are the chart's default
+	// orchestration.security.initialization admin user.
+	smokeBasicUser     = "demo"
+	smokeBasicPassword = "demo"
+	// smokeSearchTimeout bounds how long the suite waits for the started
+	// instance to be exported to secondary storage and become searchable.
+	smokeSearchTimeout  = 2 * time.Minute
+	smokeSearchInterval = 5 * time.Second
+)
+
+// smokeHealthChecks lists the optional components whose public health
+// endpoints the suite probes, in report order. A component is skipped when
+// no Deployment with the given name suffix exists in the namespace.
+var smokeHealthChecks = []struct {
+	component  string
+	deployment string
+	path       string
+	// redirectOK accepts a 3xx as healthy, for endpoints that sit behind a
+	// login redirect.
+	redirectOK bool
+}{
+	{component: "connectors", deployment: "connectors", path: "/connectors/actuator/health/readiness"},
+	{component: "optimize", deployment: "optimize", path: "/optimize/api/readyz"},
+	// Web Modeler's actuator listens on the management port, which the
+	// ingress does not route; its webapp root answering is the best public
+	// signal.
+	{component: "web-modeler", deployment: "web-modeler-restapi", path: "/modeler/", redirectOK: true},
+}
+
+// smokeCluster is the slice of the Kubernetes client the suite needs:
+// credential secrets and the list of deployed components. *kube.Client
+// satisfies it.
+type smokeCluster interface {
+	GetSecretData(ctx context.Context, namespace, secretName string) (map[string]string, error)
+	ListDeployments(ctx context.Context, namespace string) (*appsv1.DeploymentList, error)
+}
+
+// smokeDeps bundles the seams runSmokeChecksWithDeps needs so tests can run
+// the suite against an httptest server and a fake clientset.
+type smokeDeps struct {
+	cluster smokeCluster
+	client  *http.Client
+	sleep   func(ctx context.Context, d time.Duration) error
+}
+
+// smokeTarget describes the deployed scenario the suite runs against.
+type smokeTarget struct {
+	baseURL   string // https://<ingress host>, no trailing slash
+	namespace string
+	identity  string // keycloak, hybrid, oidc, auth0 or basic
+	// keycloakURL is the base URL of the Keycloak issuing tokens; empty
+	// means the bundled Keycloak behind baseURL.
+	keycloakURL   string
+	keycloakRealm string
+	// entraTokenURL is the Microsoft identity platform token endpoint for
+	// the tenant the venom app was registered in (oidc only).
+	entraTokenURL string
+}
+
+// smokeCredentials authorizes requests against the orchestration REST API.
+type smokeCredentials struct {
+	tokenURL string
+	form     url.Values // client_credentials grant; nil for basic auth
+	basic    string     // user:password for basic auth
+}
+
+// SmokeMinMinor is the first 8.x minor whose orchestration cluster serves the
+// unified /orchestration/v2 REST API the smoke suite drives; 8.6 and 8.7 split
+// Zeebe, Operate and Tasklist behind separate endpoints.
+const SmokeMinMinor = 8
+
+// checkSmokeSupported rejects --smoke for charts older than 8.8 before
+// anything is deployed. Charts whose version cannot be read from the path
+// (e.g. a remote --chart) are let through.
+func checkSmokeSupported(flags *config.RuntimeFlags) error {
+	if !flags.Test.RunSmoke {
+		return nil
+	}
+	minor, ok := chartMinor(flags.Chart.ChartPath)
+	if !ok || minor >= SmokeMinMinor {
+		return nil
+	}
+	return fmt.Errorf("--smoke requires chart 8.%d or later; %s (8.%d) has no /orchestration/v2 API", SmokeMinMinor, flags.Chart.ChartPath, minor)
+}
+
+// runSmokeChecks runs the built-in smoke suite against a deployed scenario
+// and returns one result per component. It never returns early: a failing
+// component is reported and the remaining checks still run.
+func runSmokeChecks(ctx context.Context, flags *config.RuntimeFlags, prepared *PreparedScenario, host string) []SmokeCheck {
+	client, err := kube.NewClient("", flags.Test.KubeContext)
+	if err != nil {
+		return []SmokeCheck{{Component: "identity", Status: SmokeFail, Detail: fmt.Sprintf("create Kubernetes client: %v", err)}}
+	}
+
+	target := smokeTarget{
+		baseURL:       "https://" + host,
+		namespace:     prepared.ScenarioCtx.Namespace,
+		identity:      config.FirstNonEmpty(prepared.Identity, "keycloak"),
+		keycloakRealm: smokeKeycloakRealm,
+	}
+	if flags.Auth.KeycloakHost != "" {
+		target.keycloakURL = config.FirstNonEmpty(flags.Auth.KeycloakProtocol, config.DefaultKeycloakProtocol) + "://" + flags.Auth.KeycloakHost
+		target.keycloakRealm = config.FirstNonEmpty(flags.Auth.KeycloakRealm, prepared.RealmName, smokeKeycloakRealm)
+	}
+	if tenant := os.Getenv("ENTRA_APP_DIRECTORY_ID"); tenant != "" {
+		target.entraTokenURL = "https://login.microsoftonline.com/" + tenant + "/oauth2/v2.0/token"
+	}
+
+	return runSmokeChecksWithDeps(ctx, smokeDeps{
+		cluster: client,
+		client:  &http.Client{Timeout: 30 * time.Second},
+		sleep:   sleepCtx,
+	}, target)
+}
+
+// runSmokeChecksWithDeps is runSmokeChecks with injectable dependencies.
+func runSmokeChecksWithDeps(ctx context.Context, deps smokeDeps, target smokeTarget) []SmokeCheck {
+	checks := make([]SmokeCheck, 0, 2+len(smokeHealthChecks))
+
+	authHeader, err := smokeAuthorize(ctx, deps, target)
+	if err != nil {
+		checks = append(checks,
+			SmokeCheck{Component: "identity", Status: SmokeFail, Detail: err.Error()},
+			SmokeCheck{Component: "orchestration", Status: SmokeSkip, Detail: "no credentials"})
+	} else {
+		checks = append(checks, SmokeCheck{Component: "identity", Status: SmokePass, Detail: target.identity})
+		if detail, err := smokeProcessRoundTrip(ctx, deps, target, authHeader); err != nil {
+			checks = append(checks, SmokeCheck{Component: "orchestration", Status: SmokeFail, Detail: err.Error()})
+		} else {
+			checks = append(checks, SmokeCheck{Component: "orchestration", Status: SmokePass, Detail: detail})
+		}
+	}
+
+	deployed, listErr := smokeDeployedComponents(ctx, deps.cluster, target.namespace)
+	for _, hc := range smokeHealthChecks {
+		check := SmokeCheck{Component: hc.component}
+		switch {
+		case listErr != nil:
+			check.Status, check.Detail = SmokeFail, listErr.Error()
+		case !deployed[hc.deployment]:
+			check.Status, check.Detail = SmokeSkip, "not deployed"
+		default:
+			if err := smokeProbeHealth(ctx, deps.client, target.baseURL+hc.path, hc.redirectOK); err != nil {
+				check.Status, check.Detail = SmokeFail, err.Error()
+			} else {
+				check.Status, check.Detail = SmokePass, hc.path
+			}
+		}
+		checks = append(checks, check)
+	}
+
+	for _, c := range checks {
+		logging.Logger.Info().
+			Str("component", c.Component).
+			Str("status", c.Status).
+			Str("detail", c.Detail).
+			Msg("Smoke check")
+	}
+	return checks
+}
+
+// smokeFailures returns the failed checks as "component: detail" lines.
+func smokeFailures(checks []SmokeCheck) []string {
+	var failures []string
+	for _, c := range checks {
+		if c.Status == SmokeFail {
+			failures = append(failures, fmt.Sprintf("%s: %s", c.Component, c.Detail))
+		}
+	}
+	return failures
+}
+
+// smokeError turns failed checks into a *TestError so callers (and the
+// matrix diagnostics) treat them like any other post-deploy test failure.
+func smokeError(checks []SmokeCheck) error {
+	failures := smokeFailures(checks)
+	if len(failures) == 0 {
+		return nil
+	}
+	var out strings.Builder
+	for _, c := range checks {
+		fmt.Fprintf(&out, "%-14s %-4s %s\n", c.Component, c.Status, c.Detail)
+	}
+	return &TestError{
+		Err:    fmt.Errorf("smoke checks failed:\n  - %s", strings.Join(failures, "\n  - ")),
+		Output: out.String(),
+	}
+}
+
+// smokeResolveCredentials reads the client the scenario's identity layer
+// provisions for tests from the namespace: the venom client for Keycloak and
+// Entra, the orchestration client for Auth0, and the chart's default demo
+// user for basic auth.
+func smokeResolveCredentials(ctx context.Context, deps smokeDeps, target smokeTarget) (smokeCredentials, error) {
+	read := func(secret string, keys ...string) ([]string, error) {
+		data, err := deps.cluster.GetSecretData(ctx, target.namespace, secret)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]string, len(keys))
+		for i, k := range keys {
+			if data[k] == "" {
+				return nil, fmt.Errorf("secret %s/%s has no %q key", target.namespace, secret, k)
+			}
+			values[i] = data[k]
+		}
+		return values, nil
+	}
+
+	switch target.identity {
+	case "basic":
+		return smokeCredentials{basic: smokeBasicUser + ":" + smokeBasicPassword}, nil
+
+	case "auth0":
+		v, err := read(auth0.DefaultSecretName, "auth0-info-issuer-url", "auth0-info-orchestration-client-id", "auth0-orchestration", "auth0-info-audience")
+		if err != nil {
+			return smokeCredentials{}, err
+		}
+		return smokeCredentials{
+			tokenURL: strings.TrimSuffix(v[0], "/") + "/oauth/token",
+			form: url.Values{
+				"grant_type":    {"client_credentials"},
+				"client_id":     {v[1]},
+				"client_secret": {v[2]},
+				"audience":      {v[3]},
+			},
+		}, nil
+
+	case "oidc":
+		if target.entraTokenURL == "" {
+			return smokeCredentials{}, fmt.Errorf("ENTRA_APP_DIRECTORY_ID is not set; cannot build the Entra token URL")
+		}
+		v, err := read("venom-entra-credentials", "client-id", "client-secret", "audience")
+		if err != nil {
+			return smokeCredentials{}, err
+		}
+		return smokeCredentials{
+			tokenURL: target.entraTokenURL,
+			form: url.Values{
+				"grant_type":    {"client_credentials"},
+				"client_id":     {v[0]},
+				"client_secret": {v[1]},
+				"scope":         {v[2] + "/.default"},
+			},
+		}, nil
+
+	default: // keycloak, hybrid
+		v, err := read("integration-test-credentials", "identity-admin-client-password")
+		if err != nil {
+			return smokeCredentials{}, err
+		}
+		base := config.FirstNonEmpty(target.keycloakURL, target.baseURL)
+		return smokeCredentials{
+			tokenURL: fmt.Sprintf("%s/auth/realms/%s/protocol/openid-connect/token", base, target.keycloakRealm),
+			form: url.Values{
+				"grant_type":    {"client_credentials"},
+				"client_id":     {"venom"},
+				"client_secret": {v[0]},
+			},
+		}, nil
+	}
+}
+
+// smokeAuthorize returns the Authorization header value for the
+// orchestration REST API: a bearer token from the identity provider, or
+// basic credentials verified against the topology endpoint.
+func smokeAuthorize(ctx context.Context, deps smokeDeps, target smokeTarget) (string, error) {
+	creds, err := smokeResolveCredentials(ctx, deps, target)
+	if err != nil {
+		return "", fmt.Errorf("resolve credentials: %w", err)
+	}
+
+	if creds.basic != "" {
+		header := "Basic " + base64.StdEncoding.EncodeToString([]byte(creds.basic))
+		if err := smokeDo(ctx, deps.client, http.MethodGet, target.baseURL+"/orchestration/v2/topology", header, "", nil, nil); err != nil {
+			return "", fmt.Errorf("basic auth as %s: %w", smokeBasicUser, err)
+		}
+		return header, nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, creds.tokenURL, strings.NewReader(creds.form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := smokeSend(deps.client, req, &token); err != nil {
+		return "", fmt.Errorf("token from %s: %w", creds.tokenURL, err)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("token from %s: response has no access_token", creds.tokenURL)
+	}
+	return "Bearer " + token.AccessToken, nil
+}
+
+// smokeProcessRoundTrip deploys the embedded one-step process, starts an
+// instance and waits for it to show up in the v2 search API.
+func smokeProcessRoundTrip(ctx context.Context, deps smokeDeps, target smokeTarget, authHeader string) (string, error) {
+	api := target.baseURL + "/orchestration/v2"
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	part, err := mw.CreateFormFile("resources", smokeProcessID+".bpmn")
+	if err != nil {
+		return "", err
+	}
+	if _, err := part.Write(smokeBPMN); err != nil {
+		return "", err
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	var deployment struct {
+		Deployments []struct {
+			ProcessDefinition struct {
+				ProcessDefinitionKey string `json:"processDefinitionKey"`
+			} `json:"processDefinition"`
+		} `json:"deployments"`
+	}
+	if err := smokeDo(ctx, deps.client, http.MethodPost, api+"/deployments", authHeader, mw.FormDataContentType(), &body, &deployment); err != nil {
+		return "", fmt.Errorf("deploy process: %w", err)
+	}
+	if len(deployment.Deployments) == 0 || deployment.Deployments[0].ProcessDefinition.ProcessDefinitionKey == "" {
+		return "", fmt.Errorf("deploy process: response has no process definition")
+	}
+
+	var instance struct {
+		ProcessInstanceKey string `json:"processInstanceKey"`
+	}
+	start := strings.NewReader(fmt.Sprintf(`{"processDefinitionId":%q}`, smokeProcessID))
+	if err := smokeDo(ctx, deps.client, http.MethodPost, api+"/process-instances", authHeader, "application/json", start, &instance); err != nil {
+		return "", fmt.Errorf("start instance: %w", err)
+	}
+	if instance.ProcessInstanceKey == "" {
+		return "", fmt.Errorf("start instance: response has no processInstanceKey")
+	}
+
+	// The search API reads secondary storage, which trails the engine by the
+	// exporter delay; poll until the instance appears.
+	searchCtx, cancel := context.WithTimeout(ctx, smokeSearchTimeout)
+	defer cancel()
+	query := fmt.Sprintf(`{"filter":{"processInstanceKey":%q}}`, instance.ProcessInstanceKey)
+	for {
+		var result struct {
+			Items []struct {
+				ProcessInstanceKey string `json:"processInstanceKey"`
+			} `json:"items"`
+		}
+		err := smokeDo(searchCtx, deps.client, http.MethodPost, api+"/process-instances/search", authHeader, "application/json", strings.NewReader(query), &result)
+		if err == nil && len(result.Items) > 0 {
+			return "instance " + instance.ProcessInstanceKey + " found via search", nil
+		}
+		if err == nil {
+			err = fmt.Errorf("instance %s not found yet", instance.ProcessInstanceKey)
+		}
+		if sleepErr := deps.sleep(searchCtx, smokeSearchInterval); sleepErr != nil {
+			return "", fmt.Errorf("search instance after %s: %w", smokeSearchTimeout, err)
+		}
+	}
+}
+
+// smokeDeployedComponents returns the component suffixes of every Deployment
+// in the namespace, e.g. "connectors" for "integration-connectors".
+func smokeDeployedComponents(ctx context.Context, cluster smokeCluster, namespace string) (map[string]bool, error) {
+	list, err := cluster.ListDeployments(ctx, namespace)
+	if err != nil {
+		return nil, fmt.Errorf("list deployments: %w", err)
+	}
+	deployed := map[string]bool{}
+	for _, d := range list.Items {
+		for _, hc := range smokeHealthChecks {
+			if strings.HasSuffix(d.Name, "-"+hc.deployment) {
+				deployed[hc.deployment] = true
+			}
+		}
+	}
+	return deployed, nil
+}
+
+// smokeProbeHealth expects a 2xx from url (or a 3xx when redirectOK).
+func smokeProbeHealth(ctx context.Context, client *http.Client, url string, redirectOK bool) error {
+	noRedirect := *client
+	noRedirect.CheckRedirect = func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := noRedirect.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 == 2 || (redirectOK && resp.StatusCode/100 == 3) {
+		return nil
+	}
+	return fmt.Errorf("GET %s: %s", url, resp.Status)
+}
+
+// smokeDo sends one request to the orchestration REST API and decodes the
+// JSON response into out (when non-nil).
+func smokeDo(ctx context.Context, client *http.Client, method, url, authHeader, contentType string, body io.Reader, out any) error {
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", authHeader)
+	req.Header.Set("Accept", "application/json")
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	return smokeSend(client, req, out)
+}
+
+func smokeSend(client *http.Client, req *http.Request, out any) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		snippet := strings.TrimSpace(string(data))
+		if len(snippet) > 200 {
+			snippet = snippet[:200] + "…"
+		}
+		return fmt.Errorf("%s %s: %s %s", req.Method, req.URL.Path, resp.Status, snippet)
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("%s %s: decode response: %w", req.Method, req.URL.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,341 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package deploy
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"scripts/camunda-core/pkg/kube"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/config"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func smokeFakeCluster(objects ...runtime.Object) *kube.Client {
+	return kube.NewClientForClientset(fake.NewSimpleClientset(objects...), "test")
+}
+
+func smokeSecret(name string, data map[string]string) *corev1.Secret {
+	s := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}, Data: map[string][]byte{}}
+	for k, v := range data {
+		s.Data[k] = []byte(v)
+	}
+	return s
+}
+
+func smokeDeployment(name string) *appsv1.Deployment {
+	return &appsv1.Deployment{ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "ns"}}
+}
+
+// fakeOrchestration serves the token endpoint, the v2 endpoints the suite
+// calls, and the component health endpoints.
+type fakeOrchestration struct {
+	t          *testing.T
+	wantAuth   string
+	searchMiss int // leading searches that return no items
+	searches   int
+	tokenForm  map[string]string
+	health     map[string]int
+}
+
+func (f *fakeOrchestration) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if status, ok := f.health[r.URL.Path]; ok {
+		w.WriteHeader(status)
+		return
+	}
+	if strings.HasSuffix(r.URL.Path, "/token") {
+		if err := r.ParseForm(); err != nil {
+			f.t.Errorf("parse token form: %v", err)
+		}
+		f.tokenForm = map[string]string{}
+		for k := range r.PostForm {
+			f.tokenForm[k] = r.PostForm.Get(k)
+		}
+		io.WriteString(w, `{"access_token":"tok","token_type":"Bearer"}`)
+		return
+	}
+	if got := r.Header.Get("Authorization"); got != f.wantAuth {
+		http.Error(w, "unauthorized: "+got, http.StatusUnauthorized)
+		return
+	}
+	switch r.URL.Path {
+	case "/orchestration/v2/topology":
+		io.WriteString(w, `{"brokers":[]}`)
+	case "/orchestration/v2/deployments":
+		file, _, err := r.FormFile("resources")
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		data, _ := io.ReadAll(file)
+		if !bytes.Contains(data, []byte(`id="deploy-camunda-smoke"`)) {
+			http.Error(w, "unexpected resource", http.StatusBadRequest)
+			return
+		}
+		io.WriteString(w, `{"deploymentKey":"1","deployments":[{"processDefinition":{"processDefinitionId":"deploy-camunda-smoke","processDefinitionKey":"2251799813685249"}}]}`)
+	case "/orchestration/v2/process-instances":
+		io.WriteString(w, `{"processInstanceKey":"2251799813685251"}`)
+	case "/orchestration/v2/process-instances/search":
+		var body struct {
+			Filter struct {
+				ProcessInstanceKey string `json:"processInstanceKey"`
+			} `json:"filter"`
+		}
+		json.NewDecoder(r.Body).Decode(&body) //nolint:errcheck // checked via the key below
+		f.searches++
+		if f.searches <= f.searchMiss || body.Filter.ProcessInstanceKey != "2251799813685251" {
+			io.WriteString(w, `{"items":[],"page":{"totalItems":0}}`)
+			return
+		}
+		io.WriteString(w, `{"items":[{"processInstanceKey":"2251799813685251"}],"page":{"totalItems":1}}`)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func smokeStatuses(checks []SmokeCheck) map[string]string {
+	statuses := map[string]string{}
+	for _, c := range checks {
+		statuses[c.Component] = c.Status
+	}
+	return statuses
+}
+
+func TestRunSmokeChecksKeycloak(t *testing.T) {
+	orch := &fakeOrchestration{
+		t:          t,
+		wantAuth:   "Bearer tok",
+		searchMiss: 2,
+		health: map[string]int{
+			"/connectors/actuator/health/readiness": http.StatusOK,
+			"/optimize/api/readyz":                  http.StatusServiceUnavailable,
+		},
+	}
+	srv := httptest.NewServer(orch)
+	defer srv.Close()
+
+	deps := smokeDeps{
+		cluster: smokeFakeCluster(
+			smokeSecret("integration-test-credentials", map[string]string{"identity-admin-client-password": "venom-secret"}),
+			smokeDeployment("integration-connectors"),
+			smokeDeployment("integration-optimize"),
+		),
+		client: srv.Client(),
+		sleep:  noSleep,
+	}
+	checks := runSmokeChecksWithDeps(context.Background(), deps, smokeTarget{
+		baseURL: srv.URL, namespace: "ns", identity: "keycloak", keycloakRealm: smokeKeycloakRealm,
+	})
+
+	want := map[string]string{
+		"identity":      SmokePass,
+		"orchestration": SmokePass,
+		"connectors":    SmokePass,
+		"optimize":      SmokeFail,
+		"web-modeler":   SmokeSkip,
+	}
+	if got := smokeStatuses(checks); len(got) != len(want) {
+		t.Fatalf("checks = %+v", checks)
+	} else {
+		for component, status := range want {
+			if got[component] != status {
+				t.Errorf("%s = %q, want %q (checks: %+v)", component, got[component], status, checks)
+			}
+		}
+	}
+	if orch.searches != 3 {
+		t.Errorf("searches = %d, want 3 (two misses, then a hit)", orch.searches)
+	}
+	if orch.tokenForm["client_id"] != "venom" || orch.tokenForm["client_secret"] != "venom-secret" {
+		t.Errorf("token form = %v", orch.tokenForm)
+	}
+
+	err := smokeError(checks)
+	var testErr *TestError
+	if !errors.As(err, &testErr) || !strings.Contains(err.Error(), "optimize: GET") {
+		t.Fatalf("smokeError() = %v, want a *TestError naming optimize", err)
+	}
+	if !strings.Contains(testErr.Output, "web-modeler") {
+		t.Errorf("TestError output should list every component:\n%s", testErr.Output)
+	}
+}
+
+func TestRunSmokeChecksBasicAuth(t *testing.T) {
+	orch := &fakeOrchestration{t: t, wantAuth: "Basic ZGVtbzpkZW1v"}
+	srv := httptest.NewServer(orch)
+	defer srv.Close()
+
+	checks := runSmokeChecksWithDeps(context.Background(), smokeDeps{
+		cluster: smokeFakeCluster(),
+		client:  srv.Client(),
+		sleep:   noSleep,
+	}, smokeTarget{baseURL: srv.URL, namespace: "ns", identity: "basic"})
+
+	got := smokeStatuses(checks)
+	if got["identity"] != SmokePass || got["orchestration"] != SmokePass {
+		t.Fatalf("checks = %+v", checks)
+	}
+	if err := smokeError(checks); err != nil {
+		t.Errorf("smokeError() = %v, want nil when optional components are skipped", err)
+	}
+}
+
+func TestRunSmokeChecksMissingCredentials(t *testing.T) {
+	srv := httptest.NewServer(&fakeOrchestration{t: t})
+	defer srv.Close()
+
+	checks := runSmokeChecksWithDeps(context.Background(), smokeDeps{
+		cluster: smokeFakeCluster(),
+		client:  srv.Client(),
+		sleep:   noSleep,
+	}, smokeTarget{baseURL: srv.URL, namespace: "ns", identity: "keycloak", keycloakRealm: smokeKeycloakRealm})
+
+	if checks[0].Component != "identity" || checks[0].Status != SmokeFail || !strings.Contains(checks[0].Detail, "integration-test-credentials") {
+		t.Errorf("identity check = %+v, want a failure naming the secret", checks[0])
+	}
+	if checks[1].Component != "orchestration" || checks[1].Status != SmokeSkip {
+		t.Errorf("orchestration check = %+v, want skipped without credentials", checks[1])
+	}
+}
+
+func TestCheckSmokeSupported(t *testing.T) {
+	tests := []struct {
+		chartPath string
+		smoke     bool
+		wantErr   bool
+	}{
+		{"charts/camunda-platform-8.8", true, false},
+		{"charts/camunda-platform-8.10", true, false},
+		{"charts/camunda-platform-8.7", true, true},
+		{"charts/camunda-platform-8.6", true, true},
+		{"charts/camunda-platform-8.7", false, false},
+		{"", true, false}, // remote chart: version unknown from the path
+	}
+	for _, tt := range tests {
+		flags := &config.RuntimeFlags{}
+		flags.Chart.ChartPath = tt.chartPath
+		flags.Test.RunSmoke = tt.smoke
+		err := checkSmokeSupported(flags)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("checkSmokeSupported(%q, smoke=%v) = %v, wantErr %v", tt.chartPath, tt.smoke, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSmokeResolveCredentials(t *testing.T) {
+	cluster := smokeFakeCluster(
+		smokeSecret("client-secret-for-components", map[string]string{
+			"auth0-info-issuer-url":              "https://tenant.auth0.com/",
+			"auth0-info-orchestration-client-id": "orch-id",
+			"auth0-orchestration":                "orch-secret",
+			"auth0-info-audience":                "camunda",
+		}),
+		smokeSecret("venom-entra-credentials", map[string]string{
+			"client-id": "venom-app", "client-secret": "entra-secret", "audience": "parent-app",
+		}),
+		smokeSecret("integration-test-credentials", map[string]string{"identity-admin-client-password": "venom-secret"}),
+	)
+	deps := smokeDeps{cluster: cluster}
+
+	for _, tc := range []struct {
+		name     string
+		target   smokeTarget
+		tokenURL string
+		form     map[string]string
+		wantErr  string
+	}{
+		{
+			name:     "auth0",
+			target:   smokeTarget{namespace: "ns", identity: "auth0"},
+			tokenURL: "https://tenant.auth0.com/oauth/token",
+			form:     map[string]string{"client_id": "orch-id", "audience": "camunda"},
+		},
+		{
+			name:     "oidc",
+			target:   smokeTarget{namespace: "ns", identity: "oidc", entraTokenURL: "https://login.example/tenant/oauth2/v2.0/token"},
+			tokenURL: "https://login.example/tenant/oauth2/v2.0/token",
+			form:     map[string]string{"client_id": "venom-app", "scope": "parent-app/.default"},
+		},
+		{
+			name:    "oidc without tenant",
+			target:  smokeTarget{namespace: "ns", identity: "oidc"},
+			wantErr: "ENTRA_APP_DIRECTORY_ID",
+		},
+		{
+			name:     "external keycloak",
+			target:   smokeTarget{baseURL: "https://orch.example", namespace: "ns", identity: "hybrid", keycloakURL: "https://kc.example", keycloakRealm: "realm-1"},
+			tokenURL: "https://kc.example/auth/realms/realm-1/protocol/openid-connect/token",
+			form:     map[string]string{"client_id": "venom", "client_secret": "venom-secret"},
+		},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			creds, err := smokeResolveCredentials(context.Background(), deps, tc.target)
+			if tc.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+					t.Fatalf("error = %v, want it to mention %q", err, tc.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if creds.tokenURL != tc.tokenURL {
+				t.Errorf("tokenURL = %q, want %q", creds.tokenURL, tc.tokenURL)
+			}
+			for k, v := range tc.form {
+				if creds.form.Get(k) != v {
+					t.Errorf("form[%s] = %q, want %q", k, creds.form.Get(k), v)
+				}
+			}
+		})
+	}
+}
+
+func TestDeploymentSummaryReportsSmokeChecks(t *testing.T) {
+	originalIsTerminal := isTerminal
+	isTerminal = func(uintptr) bool { return false }
+	t.Cleanup(func() { isTerminal = originalIsTerminal })
+
+	var output bytes.Buffer
+	if err := logging.Setup(logging.Options{Writer: &output, ColorEnabled: false}); err != nil {
+		t.Fatal(err)
+	}
+	printDeploymentSummary(&ScenarioResult{
+		Namespace: "ns",
+		Smoke: []SmokeCheck{
+			{Component: "orchestration", Status: SmokePass, Detail: "instance 1 found via search"},
+			{Component: "optimize", Status: SmokeFail, Detail: "GET /optimize/api/readyz: 503"},
+		},
+	}, &config.RuntimeFlags{})
+
+	for _, want := range []string{"smoke:", "orchestration: pass", "optimize: fail"} {
+		if !strings.Contains(output.String(), want) {
+			t.Errorf("summary is missing %q:\n%s", want, output.String())
+		}
+	}
+}
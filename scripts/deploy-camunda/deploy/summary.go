@@ -99,6 +99,7 @@ func printDeploymentSummary(result *ScenarioResult, flags *config.RuntimeFlags)
 		if testEnvFile != "" {
 			fmt.Fprintf(&out, "testEnvFile: %s\n", testEnvFile)
 		}
+		writeSmokePlain(&out, result.Smoke, "")
 		// Add debug port-forward instructions
 		if len(flags.Debug.DebugComponents) > 0 {
 			fmt.Fprintf(&out, "debug:\n")
@@ -150,6 +151,13 @@ func printDeploymentSummary(result *ScenarioResult, flags *config.RuntimeFlags)
 		fmt.Fprintf(&out, "  - %s: %s\n", styleKey(fmt.Sprintf("%-*s", maxKey, "E2E env file")), styleVal(testEnvFile))
 	}
 
+	if len(result.Smoke) > 0 {
+		out.WriteString("\n")
+		out.WriteString(styleHead("Smoke checks"))
+		out.WriteString("\n")
+		writeSmokePretty(&out, result.Smoke, "  ", maxKey)
+	}
+
 	// Add debug port-forward instructions if debug mode is enabled
 	if len(flags.Debug.DebugComponents) > 0 {
 		out.WriteString("\n")
@@ -221,6 +229,7 @@ func printMultiScenarioSummary(results []*ScenarioResult, flags *config.RuntimeF
 				if r.TestEnvFile != "" {
 					fmt.Fprintf(&out, "  testEnvFile: %s\n", r.TestEnvFile)
 				}
+				writeSmokePlain(&out, r.Smoke, "  ")
 			}
 		}
 		// Add debug port-forward instructions for machine-friendly output
@@ -300,6 +309,11 @@ func printMultiScenarioSummary(results []*ScenarioResult, flags *config.RuntimeF
 			if r.TestEnvFile != "" {
 				fmt.Fprintf(&out, "  %s: %s\n", styleKey(fmt.Sprintf("%-*s", maxKey, "E2E env file")), styleVal(r.TestEnvFile))
 			}
+			if len(r.Smoke) > 0 {
+				out.WriteString(styleHead("  Smoke checks:"))
+				out.WriteString("\n")
+				writeSmokePretty(&out, r.Smoke, "    ", maxKey)
+			}
 		}
 	}
 
@@ -333,3 +347,38 @@ func printMultiScenarioSummary(results []*ScenarioResult, flags *config.RuntimeF
 
 	logging.Logger.Info().Msg(out.String())
 }
+
+// writeSmokePlain appends the smoke check results in the machine-friendly
+// summary format, one "component: status" line per check.
+func writeSmokePlain(out *strings.Builder, checks []SmokeCheck, indent string) {
+	if len(checks) == 0 {
+		return
+	}
+	fmt.Fprintf(out, "%ssmoke:\n", indent)
+	for _, c := range checks {
+		fmt.Fprintf(out, "%s  %s: %s", indent, c.Component, c.Status)
+		if c.Detail != "" {
+			fmt.Fprintf(out, " (%s)", c.Detail)
+		}
+		out.WriteString("\n")
+	}
+}
+
+// writeSmokePretty appends the smoke check results with a colored status per
+// component.
+func writeSmokePretty(out *strings.Builder, checks []SmokeCheck, indent string, maxKey int) {
+	for _, c := range checks {
+		status := styleOk("✅ pass")
+		switch c.Status {
+		case SmokeFail:
+			status = styleErr("❌ fail")
+		case SmokeSkip:
+			status = styleWarn("⏭️  skip")
+		}
+		fmt.Fprintf(out, "%s- %s: %s", indent, styleKey(fmt.Sprintf("%-*s", maxKey, c.Component)), status)
+		if c.Detail != "" {
+			fmt.Fprintf(out, " %s", c.Detail)
+		}
+		out.WriteString("\n")
+	}
+}
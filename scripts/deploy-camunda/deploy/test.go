@@ -81,17 +81,21 @@ func RunTests(ctx context.Context, flags *config.RuntimeFlags, namespace string)
 		Msg("Post-deployment tests timeout configured")
 
 	// Resolve paths
+	testChartPath := flags.Test.ChartPath
+	if testChartPath == "" {
+		testChartPath = flags.Chart.ChartPath
+	}
 	repoRoot := flags.Chart.RepoRoot
 	if repoRoot == "" {
 		// Try to determine repo root from chart path
-		repoRoot = findRepoRoot(flags.Chart.ChartPath)
+		repoRoot = findRepoRoot(testChartPath)
 	}
 
 	if repoRoot == "" {
 		return fmt.Errorf("unable to determine repository root; set --repo-root flag")
 	}
 
-	chartPath, err := filepath.Abs(flags.Chart.ChartPath)
+	chartPath, err := filepath.Abs(testChartPath)
 	if err != nil {
 		return fmt.Errorf("failed to resolve chart path: %w", err)
 	}
@@ -188,13 +192,20 @@ func runE2ETests(ctx context.Context, repoRoot, chartPath, namespace, kubeContex
 
 // isFullSuiteChart returns true if the chart path indicates version 8.10 or
 // later, which should run the full E2E suite instead of just smoke tests.
-// Chart directories follow the naming pattern "camunda-platform-8.<minor>".
 func isFullSuiteChart(chartPath string) bool {
+	minor, ok := chartMinor(chartPath)
+	return ok && minor >= 10
+}
+
+// chartMinor returns the 8.x minor version a chart path refers to. Chart
+// directories follow the naming pattern "camunda-platform-8.<minor>"; ok is
+// false when the path does not follow it.
+func chartMinor(chartPath string) (int, bool) {
 	base := filepath.Base(chartPath)
 	const prefix = "camunda-platform-8."
 	idx := strings.Index(base, prefix)
 	if idx < 0 {
-		return false
+		return 0, false
 	}
 	minorStr := base[idx+len(prefix):]
 	// Trim any non-digit suffix (e.g., "-alpha1")
@@ -206,9 +217,9 @@ func isFullSuiteChart(chartPath string) bool {
 	}
 	minor, err := strconv.Atoi(minorStr)
 	if err != nil {
-		return false
+		return 0, false
 	}
-	return minor >= 10
+	return minor, true
 }
 
 // executeScript runs a shell script with the given arguments and returns the
@@ -23,8 +23,9 @@ import (
 // If platform is specified, it also processes files from the platform-specific subdirectory (e.g., common/eks/).
 // envOverrides, when non-nil, is passed through to values.Options.EnvOverrides so that
 // placeholder substitution uses the caller-supplied env map instead of the process environment.
-// Returns the list of processed file paths in the output directory.
-func processCommonValues(ctx context.Context, scenarioPath, outputDir, envFile, platform string, envOverrides map[string]string) ([]string, error) {
+// Returns one layer per common file: the processed file in the output directory
+// and the source it came from.
+func processCommonValues(ctx context.Context, scenarioPath, outputDir, envFile, platform string, envOverrides map[string]string) ([]ValuesLayer, error) {
 	// Common directory is a sibling to the scenario directory
 	commonDir := filepath.Join(filepath.Dir(scenarioPath), "..", "common")
 
@@ -64,7 +65,11 @@ func processCommonValues(ctx context.Context, scenarioPath, outputDir, envFile,
 			Err(err).
 			Str("commonDir", commonDir).
 			Msg("⚠️ [processCommonValues] failed to read common directory")
-		return sourceFiles, nil
+		layers := make([]ValuesLayer, 0, len(sourceFiles))
+		for _, f := range sourceFiles {
+			layers = append(layers, ValuesLayer{Group: LayerCommon, Source: f, File: f})
+		}
+		return layers, nil
 	}
 
 	predefinedSet := make(map[string]bool)
@@ -145,7 +150,7 @@ func processCommonValues(ctx context.Context, scenarioPath, outputDir, envFile,
 	}
 
 	// Process each common file
-	var processedFiles []string
+	var processedFiles []ValuesLayer
 	for _, srcFile := range sourceFiles {
 		logging.Logger.Debug().
 			Str("source", srcFile).
@@ -172,11 +177,11 @@ func processCommonValues(ctx context.Context, scenarioPath, outputDir, envFile,
 			Str("source", srcFile).
 			Str("output", outputPath).
 			Msg("✅ [processCommonValues] processed common values file")
-		processedFiles = append(processedFiles, outputPath)
+		processedFiles = append(processedFiles, ValuesLayer{Group: LayerCommon, Source: srcFile, File: outputPath})
 	}
 
 	logging.Logger.Debug().
-		Strs("processedFiles", processedFiles).
+		Strs("processedFiles", layerFiles(processedFiles)).
 		Int("count", len(processedFiles)).
 		Msg("✅ [processCommonValues] all common values files processed")
 
@@ -401,6 +406,13 @@ func buildScenarioEnv(scenarioCtx *ScenarioContext, flags *config.RuntimeFlags)
 		}
 	}
 
+	// 1b. Overlay values loaded from the profile's secretSources (see
+	// LoadSecretSources). They sit below .env so a developer can still override
+	// a single key from the shared store locally.
+	for k, v := range flags.Secrets.SourceEnv {
+		envMap[k] = v
+	}
+
 	// 2. Overlay .env file values (without modifying the process environment),
 	// defaulting to ".env" when unset to match EnvProvenance and the root.go
 	// loader. env.ReadFile returns an empty map (not an error) for an absent file.
@@ -629,6 +641,10 @@ func prepareScenarioValues(ctx context.Context, scenarioCtx *ScenarioContext, fl
 		return nil, err
 	}
 
+	// legacySources maps a processed legacy scenario file (by base name) back
+	// to its source, for ValuesLayers.
+	legacySources := map[string]string{}
+
 	// Helper function to process values files
 	processValues := func(scen string) error {
 		logging.Logger.Debug().
@@ -659,6 +675,7 @@ func prepareScenarioValues(ctx context.Context, scenarioCtx *ScenarioContext, fl
 		if err != nil {
 			return fmt.Errorf("failed to process scenario %q: %w", scen, err)
 		}
+		legacySources[filepath.Base(file)] = file
 		logging.Logger.Debug().
 			Str("scenario", scen).
 			Str("file", file).
@@ -672,11 +689,12 @@ func prepareScenarioValues(ctx context.Context, scenarioCtx *ScenarioContext, fl
 		Str("tempDir", tempDir).
 		Str("platform", flags.Deployment.Platform).
 		Msg("📋 [prepareScenarioValues] processing common values files")
-	processedCommonFiles, err := processCommonValues(ctx, flags.Deployment.ScenarioPath, tempDir, flags.EnvFile, flags.Deployment.Platform, envMap)
+	commonLayers, err := processCommonValues(ctx, flags.Deployment.ScenarioPath, tempDir, flags.EnvFile, flags.Deployment.Platform, envMap)
 	if err != nil {
 		os.RemoveAll(tempDir) // Cleanup on error
 		return nil, fmt.Errorf("failed to process common values: %w", err)
 	}
+	processedCommonFiles := layerFiles(commonLayers)
 
 	// Determine the effective scenario directory for resolution.
 	effectiveScenarioDir := flags.Deployment.ScenarioPath
@@ -691,6 +709,8 @@ func prepareScenarioValues(ctx context.Context, scenarioCtx *ScenarioContext, fl
 	// For legacy values, we use the existing processValues + BuildValuesList flow.
 	var scenarioValueFiles []string
 	var resolvedLayerFiles []string // source layer files before env var processing (for display)
+	var scenarioLayers []ValuesLayer
+	identity := flags.Auth.Auth
 	if isLayered {
 		logging.Logger.Debug().
 			Str("scenarioDir", effectiveScenarioDir).
@@ -721,6 +741,8 @@ func prepareScenarioValues(ctx context.Context, scenarioCtx *ScenarioContext, fl
 			return nil, fmt.Errorf("failed to build deployment config for scenario %q: %w", scenarioCtx.ScenarioName, err)
 		}
 
+		identity = deployConfig.Identity
+
 		layeredFiles, err := deployConfig.ResolvePaths(effectiveScenarioDir)
 		if err != nil {
 			os.RemoveAll(tempDir)
@@ -769,6 +791,7 @@ func prepareScenarioValues(ctx context.Context, scenarioCtx *ScenarioContext, fl
 				return nil, fmt.Errorf("failed to process layered values file %q: %w", srcFile, procErr)
 			}
 			scenarioValueFiles = append(scenarioValueFiles, outputPath)
+			scenarioLayers = append(scenarioLayers, ValuesLayer{Group: LayerScenario, Source: srcFile, File: outputPath, NameMerged: true})
 		}
 
 		logging.Logger.Debug().
@@ -862,12 +885,14 @@ func prepareScenarioValues(ctx context.Context, scenarioCtx *ScenarioContext, fl
 	// and are applied BEFORE scenario layers so that scenario-specific values take precedence.
 	// Not passed through values.Process() — these files contain literal values, no env placeholders.
 	var chartRootOverlayFiles []string
+	var overlayLayers []ValuesLayer
 	for _, overlay := range flags.Chart.ChartRootOverlays {
 		if flags.Chart.ChartPath == "" {
 			continue // repo-based installs (upgrade Step 1) have no local chart path
 		}
 		overlayPath := filepath.Join(flags.Chart.ChartPath, "values-"+overlay+".yaml")
 		if _, statErr := os.Stat(overlayPath); statErr == nil {
+			layer := ValuesLayer{Group: LayerOverlay, Source: overlayPath}
 			// The digest overlay pins image.digest, which the chart image helper
 			// prefers over tag. If --extra-values overrides a component's image
 			// coordinates (without its own digest), strip that component's digest
@@ -880,7 +905,10 @@ func prepareScenarioValues(ctx context.Context, scenarioCtx *ScenarioContext, fl
 					return nil, fmt.Errorf("failed to sanitize digest overlay: %w", sanErr)
 				}
 				overlayPath = sanitized
+				layer.Rewritten = true
 			}
+			layer.File = overlayPath
+			overlayLayers = append(overlayLayers, layer)
 			chartRootOverlayFiles = append(chartRootOverlayFiles, overlayPath)
 			logging.Logger.Info().
 				Str("overlay", overlay).
@@ -912,9 +940,17 @@ func prepareScenarioValues(ctx context.Context, scenarioCtx *ScenarioContext, fl
 		// BuildValuesList returns: common + auth + scenario.
 		// Extract the scenario portion (everything after common files).
 		scenarioFiles = legacyVals[len(processedCommonFiles):]
+		for _, f := range scenarioFiles {
+			src := legacySources[filepath.Base(f)]
+			if src == "" {
+				src = f
+			}
+			scenarioLayers = append(scenarioLayers, ValuesLayer{Group: LayerScenario, Source: src, File: f})
+		}
 	}
 
 	vals := BuildValuesChain(processedCommonFiles, chartRootOverlayFiles, flags.Deployment.ExtraValues, scenarioFiles, debugValuesFile)
+	layers := buildValuesLayers(commonLayers, overlayLayers, flags.Deployment.ExtraValues, scenarioLayers, debugValuesFile)
 
 	logging.Logger.Debug().
 		Str("scenario", scenarioCtx.ScenarioName).
@@ -931,10 +967,12 @@ func prepareScenarioValues(ctx context.Context, scenarioCtx *ScenarioContext, fl
 		ScenarioCtx:         scenarioCtx,
 		ValuesFiles:         vals,
 		LayeredFiles:        resolvedLayerFiles,
+		ValuesLayers:        layers,
 		VaultSecretPath:     vaultSecretPath,
 		CompanionCharts:     companionCharts,
 		TempDir:             tempDir,
 		RealmName:           realmName,
+		Identity:            identity,
 		OptimizePrefix:      optimizePrefix,
 		OrchestrationPrefix: orchestrationPrefix,
 		Secrets:             secrets,
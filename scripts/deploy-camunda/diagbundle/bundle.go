@@ -0,0 +1,257 @@
+// Package diagbundle defines the diagnostics bundle: a versioned tar.gz with
+// a manifest.json index that captures a failed entry's namespace (resources,
+// pod logs, events, helm releases, the values chain and the environment it
+// was deployed with). Bundles are self-contained, so one downloaded from a CI
+// artifact opens offline with `deploy-camunda diagnostics view`.
+package diagbundle
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"scripts/deploy-camunda/deploy"
+)
+
+// SchemaVersion is the manifest format this package writes. Readers accept
+// older bundles and refuse newer ones.
+const SchemaVersion = 1
+
+// ManifestName is the bundle's index; it is always the first archive entry.
+const ManifestName = "manifest.json"
+
+// FileName is the conventional bundle name inside a diagnostics run dir.
+const FileName = "bundle.tar.gz"
+
+// File kinds recorded in Manifest.Files.
+const (
+	KindResource     = "resource"
+	KindLog          = "log"
+	KindPreviousLog  = "previous-log"
+	KindHelmValues   = "helm-values"
+	KindHelmManifest = "helm-manifest"
+	KindValues       = "values"
+	KindStream       = "stream"
+	KindTestOutput   = "test-output"
+)
+
+// Manifest indexes a bundle. Pods, Events and Releases are condensed from the
+// resource files so the viewer does not need to parse Kubernetes JSON.
+type Manifest struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	CreatedAt     time.Time         `json:"createdAt"`
+	Namespace     string            `json:"namespace"`
+	KubeContext   string            `json:"kubeContext,omitempty"`
+	Labels        map[string]string `json:"labels,omitempty"`
+	Error         string            `json:"error,omitempty"`
+	Files         []File            `json:"files"`
+	Pods          []Pod             `json:"pods,omitempty"`
+	Events        []Event           `json:"events,omitempty"`
+	Releases      []Release         `json:"releases,omitempty"`
+	// ValuesChain is the values chain in helm precedence order, with File
+	// pointing at the copy inside the bundle.
+	ValuesChain []deploy.ValuesLayer `json:"valuesChain,omitempty"`
+	// Environment is the deploy's effective environment with secret values
+	// masked and the process environment reduced to an allowlist.
+	Environment []EnvVar `json:"environment,omitempty"`
+	// Errors lists what could not be collected.
+	Errors []string `json:"errors,omitempty"`
+}
+
+// File is one archive entry other than the manifest.
+type File struct {
+	Path      string `json:"path"`
+	Kind      string `json:"kind"`
+	Pod       string `json:"pod,omitempty"`
+	Container string `json:"container,omitempty"`
+	Size      int    `json:"size"`
+}
+
+// Pod is one row of the pod status grid.
+type Pod struct {
+	Name       string      `json:"name"`
+	Phase      string      `json:"phase"`
+	Ready      bool        `json:"ready"`
+	Node       string      `json:"node,omitempty"`
+	Containers []Container `json:"containers"`
+}
+
+// Container is one cell of the pod status grid.
+type Container struct {
+	Name     string `json:"name"`
+	Init     bool   `json:"init,omitempty"`
+	State    string `json:"state"` // running, waiting, terminated
+	Reason   string `json:"reason,omitempty"`
+	Ready    bool   `json:"ready"`
+	Restarts int32  `json:"restarts"`
+	LastExit string `json:"lastExit,omitempty"`
+}
+
+// Event is one entry of the event timeline.
+type Event struct {
+	Time    time.Time `json:"time"`
+	Type    string    `json:"type"`
+	Reason  string    `json:"reason"`
+	Object  string    `json:"object"`
+	Message string    `json:"message"`
+	Count   int32     `json:"count"`
+}
+
+// Release is a Helm release in the namespace.
+type Release struct {
+	Name       string `json:"name"`
+	Chart      string `json:"chart"`
+	Version    string `json:"version"`
+	AppVersion string `json:"appVersion,omitempty"`
+	Status     string `json:"status"`
+	Revision   int    `json:"revision"`
+}
+
+// EnvVar is one effective environment variable and the layer it came from.
+type EnvVar struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Origin string `json:"origin"`
+}
+
+// Bundle is a manifest and the contents of the files it lists.
+type Bundle struct {
+	Manifest Manifest
+	files    map[string][]byte
+}
+
+// New returns an empty bundle for namespace.
+func New(namespace string) *Bundle {
+	return &Bundle{
+		Manifest: Manifest{SchemaVersion: SchemaVersion, CreatedAt: time.Now().UTC(), Namespace: namespace},
+		files:    map[string][]byte{},
+	}
+}
+
+// Add stores data under f.Path and lists it in the manifest. Adding a path
+// twice replaces the earlier content.
+func (b *Bundle) Add(f File, data []byte) {
+	f.Size = len(data)
+	if _, ok := b.files[f.Path]; ok {
+		for i := range b.Manifest.Files {
+			if b.Manifest.Files[i].Path == f.Path {
+				b.Manifest.Files[i] = f
+			}
+		}
+	} else {
+		b.Manifest.Files = append(b.Manifest.Files, f)
+	}
+	b.files[f.Path] = data
+}
+
+// File returns the content stored under path.
+func (b *Bundle) File(path string) ([]byte, bool) {
+	data, ok := b.files[path]
+	return data, ok
+}
+
+// Write encodes the bundle as a gzipped tar: manifest.json first, then the
+// files in manifest order.
+func (b *Bundle) Write(w io.Writer) error {
+	manifest, err := json.MarshalIndent(b.Manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode manifest: %w", err)
+	}
+	zw := gzip.NewWriter(w)
+	tw := tar.NewWriter(zw)
+	add := func(name string, data []byte) error {
+		hdr := &tar.Header{Name: name, Mode: 0o644, Size: int64(len(data)), ModTime: b.Manifest.CreatedAt}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+		if _, err := tw.Write(data); err != nil {
+			return fmt.Errorf("write %s: %w", name, err)
+		}
+		return nil
+	}
+	if err := add(ManifestName, manifest); err != nil {
+		return err
+	}
+	for _, f := range b.Manifest.Files {
+		if err := add(f.Path, b.files[f.Path]); err != nil {
+			return err
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return fmt.Errorf("close tar: %w", err)
+	}
+	return zw.Close()
+}
+
+// WriteFile writes the bundle to path.
+func (b *Bundle) WriteFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("create bundle: %w", err)
+	}
+	if err := b.Write(f); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// Read decodes a bundle written by Write.
+func Read(r io.Reader) (*Bundle, error) {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return nil, fmt.Errorf("not a diagnostics bundle: %w", err)
+	}
+	defer zr.Close()
+
+	b := &Bundle{files: map[string][]byte{}}
+	var manifest []byte
+	tr := tar.NewReader(zr)
+	for {
+		hdr, err := tr.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("read bundle: %w", err)
+		}
+		if hdr.Typeflag != tar.TypeReg {
+			continue
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", hdr.Name, err)
+		}
+		if hdr.Name == ManifestName {
+			manifest = data
+			continue
+		}
+		b.files[hdr.Name] = data
+	}
+	if manifest == nil {
+		return nil, fmt.Errorf("not a diagnostics bundle: no %s", ManifestName)
+	}
+	if err := json.Unmarshal(manifest, &b.Manifest); err != nil {
+		return nil, fmt.Errorf("decode %s: %w", ManifestName, err)
+	}
+	if b.Manifest.SchemaVersion > SchemaVersion {
+		return nil, fmt.Errorf("bundle schema version %d is newer than this deploy-camunda supports (%d); update deploy-camunda",
+			b.Manifest.SchemaVersion, SchemaVersion)
+	}
+	return b, nil
+}
+
+// Open reads the bundle at path.
+func Open(path string) (*Bundle, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
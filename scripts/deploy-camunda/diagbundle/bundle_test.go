@@ -0,0 +1,307 @@
+package diagbundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"io"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"scripts/camunda-core/pkg/kube"
+	"scripts/deploy-camunda/deploy"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testSecret = "s3cr3t-password"
+
+func helmSecret(t *testing.T) *corev1.Secret {
+	t.Helper()
+	release := map[string]any{
+		"name": "integration", "namespace": "ns", "version": 3,
+		"info":   map[string]any{"status": "failed"},
+		"chart":  map[string]any{"metadata": map[string]any{"name": "camunda-platform", "version": "13.0.0"}},
+		"config": map[string]any{"identity": map[string]any{"firstUser": map[string]any{"password": testSecret}}},
+		"manifest": "---\n# Source: camunda-platform/templates/secret.yaml\napiVersion: v1\nkind: Secret\n" +
+			"metadata:\n  name: integration-secret\ndata:\n  password: czNjcjN0LXBhc3N3b3Jk\n" +
+			"---\n# Source: camunda-platform/templates/zeebe/statefulset.yaml\napiVersion: apps/v1\nkind: StatefulSet\n" +
+			"metadata:\n  name: integration-zeebe\n",
+	}
+	payload, err := json.Marshal(release)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "sh.helm.release.v1.integration.v3",
+			Namespace: "ns",
+			Labels:    map[string]string{"owner": "helm", "name": "integration", "version": "3"},
+		},
+		Data: map[string][]byte{"release": []byte(base64.StdEncoding.EncodeToString(payload))},
+	}
+}
+
+func collectFixture(t *testing.T) *Bundle {
+	t.Helper()
+	return collectFixtureWith(t, nil)
+}
+
+// collectFixtureWith collects the fixture cluster with opts adjusted by edit.
+func collectFixtureWith(t *testing.T, edit func(opts *CollectOptions)) *Bundle {
+	t.Helper()
+	at := func(s int) metav1.Time { return metav1.NewTime(time.Date(2026, 3, 1, 10, 0, s, 0, time.UTC)) }
+	clientset := fake.NewSimpleClientset(
+		&corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "zeebe-0", Namespace: "ns"},
+			Status: corev1.PodStatus{Phase: corev1.PodRunning, ContainerStatuses: []corev1.ContainerStatus{{
+				Name: "zeebe", ContainerID: "containerd://b", RestartCount: 2,
+				State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff"}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{
+					ExitCode: 137, Reason: "OOMKilled",
+				}},
+			}}},
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "late", Namespace: "ns"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "zeebe-0"},
+			Type:           corev1.EventTypeWarning, Reason: "BackOff", Message: "Back-off restarting failed container",
+			LastTimestamp: at(90), Count: 4,
+		},
+		&corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: "early", Namespace: "ns"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Pod", Name: "zeebe-0"},
+			Type:           corev1.EventTypeNormal, Reason: "Scheduled", Message: "Successfully assigned ns/zeebe-0",
+			LastTimestamp: at(0),
+		},
+		helmSecret(t),
+	)
+
+	snapshot := t.TempDir()
+	values := filepath.Join(t.TempDir(), "values.yaml")
+	if err := os.WriteFile(values, []byte("identity:\n  firstUser:\n    password: "+testSecret+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := deploy.SnapshotValuesChain(snapshot, []deploy.ValuesLayer{{Group: deploy.LayerScenario, Source: "values/layered/base.yaml", File: values}}); err != nil {
+		t.Fatal(err)
+	}
+	stream := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(stream, "pods", "zeebe-0"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(stream, "pods", "zeebe-0", "zeebe.0.log"), []byte("first instance\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	opts := CollectOptions{
+		Namespace:         "ns",
+		KubeContext:       "test",
+		Labels:            map[string]string{"version": "8.9", "scenario": "eske"},
+		Error:             "deploy failed",
+		ValuesSnapshotDir: snapshot,
+		StreamDir:         stream,
+		Environment: []deploy.EnvVar{
+			{Name: "DISTRO_QA_E2E_TESTS_IDENTITY_FIRSTUSER_PASSWORD", Value: testSecret, Origin: ".env (.env)"},
+			{Name: "CAMUNDA_HOSTNAME", Value: "example.com", Origin: "process-env"},
+		},
+		TestOutput: "FAIL login as " + testSecret,
+	}
+	if edit != nil {
+		edit(&opts)
+	}
+	return Collect(context.Background(), kube.NewClientForClientset(clientset, "test"), opts)
+}
+
+func TestCollectRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	if err := collectFixture(t).Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	b, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	m := b.Manifest
+
+	if m.SchemaVersion != SchemaVersion || m.Namespace != "ns" || m.Labels["scenario"] != "eske" || len(m.Errors) != 0 {
+		t.Fatalf("unexpected manifest header: %+v", m)
+	}
+	if len(m.Pods) != 1 || m.Pods[0].Containers[0].Reason != "CrashLoopBackOff" || m.Pods[0].Containers[0].LastExit != "137 OOMKilled" {
+		t.Errorf("unexpected pod grid: %+v", m.Pods)
+	}
+	if len(m.Events) != 2 || m.Events[0].Reason != "Scheduled" || m.Events[1].Count != 4 {
+		t.Errorf("expected the events in time order, got %+v", m.Events)
+	}
+	if len(m.Releases) != 1 || m.Releases[0].Status != "failed" || m.Releases[0].Revision != 3 {
+		t.Errorf("unexpected releases: %+v", m.Releases)
+	}
+	if len(m.ValuesChain) != 1 || m.ValuesChain[0].File != "values/01-scenario-values.yaml" {
+		t.Errorf("unexpected values chain: %+v", m.ValuesChain)
+	}
+
+	for _, path := range []string{
+		"resources/pods.json", "resources/events.json", "resources/statefulsets.json",
+		"logs/zeebe-0/zeebe.log", "logs/zeebe-0/zeebe.previous.log",
+		"helm/integration/values.yaml", "helm/integration/manifest.yaml",
+		"values/01-scenario-values.yaml", "stream/pods/zeebe-0/zeebe.0.log", "test-output.txt",
+	} {
+		data, ok := b.File(path)
+		if !ok {
+			t.Errorf("bundle is missing %s", path)
+			continue
+		}
+		if bytes.Contains(data, []byte(testSecret)) {
+			t.Errorf("%s leaks the secret:\n%s", path, data)
+		}
+	}
+
+	manifest, _ := b.File("helm/integration/manifest.yaml")
+	if bytes.Contains(manifest, []byte("czNjcjN0LXBhc3N3b3Jk")) || !bytes.Contains(manifest, []byte("# Source: camunda-platform/templates/secret.yaml")) {
+		t.Errorf("expected Secret data blanked and source comments kept:\n%s", manifest)
+	}
+	if !bytes.Contains(manifest, []byte("name: integration-zeebe")) {
+		t.Errorf("expected other objects untouched:\n%s", manifest)
+	}
+	for _, e := range m.Environment {
+		if e.Name == "CAMUNDA_HOSTNAME" && e.Value != "example.com" {
+			t.Errorf("non-secret variables must not be masked, got %q", e.Value)
+		}
+	}
+}
+
+func TestCollectRedactsSecretsByOrigin(t *testing.T) {
+	const (
+		serviceAccount = `{"type":"service_account","private_key_id":"odd-gcp-sa"}`
+		shortSecret    = "pw1"
+		processSecret  = "runner-scoped-credential"
+		pgPassword     = "pg-admin-from-cluster"
+	)
+	leaks := []string{serviceAccount, shortSecret, pgPassword}
+
+	b := collectFixtureWith(t, func(opts *CollectOptions) {
+		opts.Environment = []deploy.EnvVar{
+			{Name: "IDP_GCP_SERVICE_ACCOUNT", Value: serviceAccount, Origin: deploy.OriginSecretSource + " (vault secret/ci)"},
+			{Name: "CI_LOGIN", Value: shortSecret, Origin: deploy.OriginDotEnv + " (.env)"},
+			{Name: "RUNNER_CREDS", Value: processSecret, Origin: deploy.OriginProcessEnv},
+			{Name: "GITHUB_RUN_ID", Value: "4242", Origin: deploy.OriginProcessEnv},
+			{Name: "CAMUNDA_HOSTNAME", Value: "example.com", Origin: deploy.OriginExtraEnv},
+		}
+		opts.HelmSets = map[string]string{"identityKeycloak.postgresql.auth.postgresPassword": pgPassword}
+		opts.TestOutput = strings.Join(leaks, "\n")
+	})
+
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	read, err := Read(&buf)
+	if err != nil {
+		t.Fatalf("Read: %v", err)
+	}
+	manifest, err := json.Marshal(read.Manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leak := range leaks {
+		if bytes.Contains(manifest, []byte(leak)) {
+			t.Errorf("manifest leaks %q", leak)
+		}
+		for _, f := range read.Manifest.Files {
+			if data, _ := read.File(f.Path); bytes.Contains(data, []byte(leak)) {
+				t.Errorf("%s leaks %q:\n%s", f.Path, leak, data)
+			}
+		}
+	}
+
+	env := map[string]string{}
+	for _, e := range read.Manifest.Environment {
+		env[e.Name] = e.Value
+	}
+	if _, ok := env["RUNNER_CREDS"]; ok || bytes.Contains(manifest, []byte(processSecret)) {
+		t.Error("process variables outside the allowlist must be dropped")
+	}
+	if env["GITHUB_RUN_ID"] != "4242" || env["CAMUNDA_HOSTNAME"] != "example.com" {
+		t.Errorf("expected allowlisted and non-secret variables kept, got %v", env)
+	}
+	if env["IDP_GCP_SERVICE_ACCOUNT"] != redacted || env["CI_LOGIN"] != redacted {
+		t.Errorf("expected secret-source and .env values masked, got %v", env)
+	}
+}
+
+func TestRedactValuesYAML(t *testing.T) {
+	in := "# scenario values\n" +
+		"identityKeycloak:\n  postgresql:\n    auth:\n      password: hunter2\n      existingSecret: keycloak-pg\n" +
+		"orchestration:\n  env:\n    - name: CAMUNDA_DB_PASSWORD\n      value: hunter3\n    - name: CAMUNDA_DB_URL\n      value: jdbc:postgresql://db\n"
+	out := string(redactValuesYAML([]byte(in)))
+	for _, leak := range []string{"hunter2", "hunter3"} {
+		if strings.Contains(out, leak) {
+			t.Errorf("redacted values leak %q:\n%s", leak, out)
+		}
+	}
+	for _, keep := range []string{"# scenario values", "existingSecret: keycloak-pg", "value: jdbc:postgresql://db"} {
+		if !strings.Contains(out, keep) {
+			t.Errorf("redacted values lost %q:\n%s", keep, out)
+		}
+	}
+}
+
+func TestReadRejectsNewerSchema(t *testing.T) {
+	b := New("ns")
+	b.Manifest.SchemaVersion = SchemaVersion + 1
+	var buf bytes.Buffer
+	if err := b.Write(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Read(&buf); err == nil || !strings.Contains(err.Error(), "newer") {
+		t.Fatalf("expected a schema version error, got %v", err)
+	}
+	if _, err := Read(strings.NewReader("plain text")); err == nil {
+		t.Fatal("expected an error for a non-bundle")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	srv := httptest.NewServer(Handler(collectFixture(t)))
+	defer srv.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		resp, err := srv.Client().Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	code, page := get("/")
+	if code != 200 {
+		t.Fatalf("GET / = %d", code)
+	}
+	for _, want := range []string{
+		"zeebe waiting CrashLoopBackOff ↻2", // pod grid cell
+		"&#43;1:30</td>",                    // the BackOff event's offset from the first event
+		"Back-off restarting failed container",
+		`href="/file/values/01-scenario-values.yaml"`,
+		"deploy failed",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("viewer page is missing %q", want)
+		}
+	}
+
+	if code, body := get("/file/stream/pods/zeebe-0/zeebe.0.log"); code != 200 || body != "first instance\n" {
+		t.Errorf("GET stream file = %d %q", code, body)
+	}
+	if code, _ := get("/file/nope"); code != 404 {
+		t.Errorf("GET unknown file = %d, want 404", code)
+	}
+}
@@ -0,0 +1,455 @@
+package diagbundle
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"scripts/camunda-core/pkg/kube"
+	"scripts/deploy-camunda/deploy"
+	"scripts/prepare-helm-values/pkg/values"
+
+	"gopkg.in/yaml.v3"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// ClusterReader is the read-only cluster surface Collect needs. *kube.Client
+// satisfies it through client-go; tests back it with a fake clientset.
+type ClusterReader interface {
+	ListPods(ctx context.Context, namespace string) (*corev1.PodList, error)
+	ListEvents(ctx context.Context, namespace string) (*corev1.EventList, error)
+	ListPVCs(ctx context.Context, namespace string) (*corev1.PersistentVolumeClaimList, error)
+	ListServices(ctx context.Context, namespace string) (*corev1.ServiceList, error)
+	ListStatefulSets(ctx context.Context, namespace string) (*appsv1.StatefulSetList, error)
+	ListDeployments(ctx context.Context, namespace string) (*appsv1.DeploymentList, error)
+	ListHelmReleaseContents(ctx context.Context, namespace string) ([]kube.HelmReleaseContent, error)
+	PodContainerLogs(ctx context.Context, namespace, pod, container string, previous bool, tailLines int64) (string, error)
+}
+
+// CollectOptions configures Collect.
+type CollectOptions struct {
+	Namespace   string
+	KubeContext string
+	// Labels identify what was deployed (version, scenario, flow, ...).
+	Labels map[string]string
+	// Error is the failure the bundle is about.
+	Error string
+	// LogTailLines caps each container log; 0 keeps whole logs.
+	LogTailLines int64
+	// ValuesSnapshotDir is a deploy.SnapshotValuesChain directory.
+	ValuesSnapshotDir string
+	// Environment is the deploy's effective environment (deploy.EnvProvenance).
+	// The process environment is dropped except for processEnvAllowlist.
+	// Values from secret sources and .env files, and values of secret-named
+	// variables, are masked in the bundle and redacted from every other file.
+	Environment []deploy.EnvVar
+	// HelmSets are the --set pairs the deploy passed to helm. Values of
+	// secret-named keys are redacted from every file.
+	HelmSets map[string]string
+	// StreamDir is a kube.StreamNamespace directory; it is copied under stream/.
+	StreamDir string
+	// TestOutput is the failed test script's output.
+	TestOutput string
+}
+
+const redacted = "[redacted]"
+
+// processEnvAllowlist names the process environment variables the bundle
+// keeps. CI runners export secrets under arbitrary names, so the rest of the
+// process environment is dropped.
+var processEnvAllowlist = map[string]bool{
+	"CI":                 true,
+	"GITHUB_ACTIONS":     true,
+	"GITHUB_REPOSITORY":  true,
+	"GITHUB_WORKFLOW":    true,
+	"GITHUB_JOB":         true,
+	"GITHUB_RUN_ID":      true,
+	"GITHUB_RUN_ATTEMPT": true,
+	"GITHUB_REF":         true,
+	"GITHUB_HEAD_REF":    true,
+	"GITHUB_SHA":         true,
+	"RUNNER_OS":          true,
+	"RUNNER_ARCH":        true,
+}
+
+// Collect gathers a bundle for opts.Namespace. Collection is best-effort:
+// whatever fails is listed in Manifest.Errors and the rest is still bundled.
+func Collect(ctx context.Context, r ClusterReader, opts CollectOptions) *Bundle {
+	b := New(opts.Namespace)
+	b.Manifest.KubeContext = opts.KubeContext
+	b.Manifest.Labels = opts.Labels
+	b.Manifest.Error = opts.Error
+	fail := func(format string, args ...any) {
+		b.Manifest.Errors = append(b.Manifest.Errors, fmt.Sprintf(format, args...))
+	}
+
+	var secrets []string
+	for _, e := range opts.Environment {
+		fromSecretFile := strings.HasPrefix(e.Origin, deploy.OriginSecretSource) || strings.HasPrefix(e.Origin, deploy.OriginDotEnv)
+		secret := e.Value != "" && (fromSecretFile || values.IsSecretName(e.Name))
+		if secret && !trivialValue(e.Value) {
+			secrets = append(secrets, e.Value)
+		}
+		if e.Origin == deploy.OriginProcessEnv && !processEnvAllowlist[e.Name] {
+			continue
+		}
+		v := EnvVar{Name: e.Name, Value: e.Value, Origin: e.Origin}
+		if secret {
+			v.Value = redacted
+		}
+		b.Manifest.Environment = append(b.Manifest.Environment, v)
+	}
+	for k, v := range opts.HelmSets {
+		if SecretHelmSet(k, v) {
+			secrets = append(secrets, v)
+		}
+	}
+	redact := newRedactor(secrets)
+	add := func(f File, data []byte) { b.Add(f, redact(data)) }
+	addJSON := func(name string, v any) {
+		data, err := json.MarshalIndent(v, "", "  ")
+		if err != nil {
+			fail("encode %s: %v", name, err)
+			return
+		}
+		add(File{Path: "resources/" + name + ".json", Kind: KindResource}, data)
+	}
+
+	pods, err := r.ListPods(ctx, opts.Namespace)
+	if err != nil {
+		fail("%v", err)
+	} else {
+		addJSON("pods", pods)
+		b.Manifest.Pods = podGrid(pods.Items)
+		collectLogs(ctx, r, opts, pods.Items, add, fail)
+	}
+
+	if events, err := r.ListEvents(ctx, opts.Namespace); err != nil {
+		fail("%v", err)
+	} else {
+		addJSON("events", events)
+		b.Manifest.Events = timeline(events.Items)
+	}
+	if pvcs, err := r.ListPVCs(ctx, opts.Namespace); err != nil {
+		fail("%v", err)
+	} else {
+		addJSON("pvcs", pvcs)
+	}
+	if services, err := r.ListServices(ctx, opts.Namespace); err != nil {
+		fail("%v", err)
+	} else {
+		addJSON("services", services)
+	}
+	if sets, err := r.ListStatefulSets(ctx, opts.Namespace); err != nil {
+		fail("%v", err)
+	} else {
+		addJSON("statefulsets", sets)
+	}
+	if deployments, err := r.ListDeployments(ctx, opts.Namespace); err != nil {
+		fail("%v", err)
+	} else {
+		addJSON("deployments", deployments)
+	}
+
+	if releases, err := r.ListHelmReleaseContents(ctx, opts.Namespace); err != nil {
+		fail("%v", err)
+	} else {
+		for _, rel := range releases {
+			b.Manifest.Releases = append(b.Manifest.Releases, Release{
+				Name:       rel.Name,
+				Chart:      rel.Chart.Metadata.Name,
+				Version:    rel.Chart.Metadata.Version,
+				AppVersion: rel.Chart.Metadata.AppVersion,
+				Status:     rel.Info.Status,
+				Revision:   rel.Version,
+			})
+			if vals, err := yaml.Marshal(rel.Config); err != nil {
+				fail("encode values of release %s: %v", rel.Name, err)
+			} else {
+				add(File{Path: "helm/" + rel.Name + "/values.yaml", Kind: KindHelmValues}, redactValuesYAML(vals))
+			}
+			add(File{Path: "helm/" + rel.Name + "/manifest.yaml", Kind: KindHelmManifest}, redactSecretManifests(rel.Manifest))
+		}
+	}
+
+	if opts.ValuesSnapshotDir != "" {
+		if layers, err := deploy.LoadValuesSnapshot(opts.ValuesSnapshotDir); err != nil {
+			fail("values chain: %v", err)
+		} else {
+			for _, l := range layers {
+				data, err := os.ReadFile(filepath.Join(opts.ValuesSnapshotDir, l.File))
+				if err != nil {
+					fail("values chain: %v", err)
+					continue
+				}
+				l.File = "values/" + l.File
+				add(File{Path: l.File, Kind: KindValues}, redactValuesYAML(data))
+				b.Manifest.ValuesChain = append(b.Manifest.ValuesChain, l)
+			}
+		}
+	}
+
+	if opts.StreamDir != "" {
+		if err := addStream(opts.StreamDir, add); err != nil {
+			fail("namespace stream: %v", err)
+		}
+	}
+	if opts.TestOutput != "" {
+		add(File{Path: "test-output.txt", Kind: KindTestOutput}, []byte(opts.TestOutput))
+	}
+	return b
+}
+
+// collectLogs adds the current log of every started container and the
+// previous log of every restarted one.
+func collectLogs(ctx context.Context, r ClusterReader, opts CollectOptions, pods []corev1.Pod, add func(File, []byte), fail func(string, ...any)) {
+	for _, pod := range pods {
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, cs := range statuses {
+			dir := "logs/" + pod.Name + "/" + cs.Name
+			if cs.ContainerID != "" {
+				if logs, err := r.PodContainerLogs(ctx, opts.Namespace, pod.Name, cs.Name, false, opts.LogTailLines); err != nil {
+					fail("%v", err)
+				} else {
+					add(File{Path: dir + ".log", Kind: KindLog, Pod: pod.Name, Container: cs.Name}, []byte(logs))
+				}
+			}
+			if cs.RestartCount > 0 {
+				if logs, err := r.PodContainerLogs(ctx, opts.Namespace, pod.Name, cs.Name, true, opts.LogTailLines); err != nil {
+					fail("%v", err)
+				} else {
+					add(File{Path: dir + ".previous.log", Kind: KindPreviousLog, Pod: pod.Name, Container: cs.Name}, []byte(logs))
+				}
+			}
+		}
+	}
+}
+
+// addStream copies a namespace stream directory under stream/.
+func addStream(dir string, add func(File, []byte)) error {
+	return filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		rel, err := filepath.Rel(dir, p)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		f := File{Path: path.Join("stream", filepath.ToSlash(rel)), Kind: KindStream}
+		// pods/<pod>/<container>.<restart>.log
+		if parts := strings.Split(filepath.ToSlash(rel), "/"); len(parts) == 3 && parts[0] == "pods" {
+			f.Pod = parts[1]
+			f.Container, _, _ = strings.Cut(parts[2], ".")
+		}
+		add(f, data)
+		return nil
+	})
+}
+
+func podGrid(pods []corev1.Pod) []Pod {
+	grid := make([]Pod, 0, len(pods))
+	for _, p := range pods {
+		row := Pod{Name: p.Name, Phase: string(p.Status.Phase), Node: p.Spec.NodeName}
+		for _, c := range p.Status.Conditions {
+			if c.Type == corev1.PodReady {
+				row.Ready = c.Status == corev1.ConditionTrue
+			}
+		}
+		for i, statuses := range [][]corev1.ContainerStatus{p.Status.InitContainerStatuses, p.Status.ContainerStatuses} {
+			for _, cs := range statuses {
+				c := Container{Name: cs.Name, Init: i == 0, Ready: cs.Ready, Restarts: cs.RestartCount}
+				switch {
+				case cs.State.Running != nil:
+					c.State = "running"
+				case cs.State.Waiting != nil:
+					c.State, c.Reason = "waiting", cs.State.Waiting.Reason
+				case cs.State.Terminated != nil:
+					c.State, c.Reason = "terminated", cs.State.Terminated.Reason
+				}
+				if last := cs.LastTerminationState.Terminated; last != nil {
+					c.LastExit = fmt.Sprintf("%d %s", last.ExitCode, last.Reason)
+				}
+				row.Containers = append(row.Containers, c)
+			}
+		}
+		grid = append(grid, row)
+	}
+	sort.Slice(grid, func(i, j int) bool { return grid[i].Name < grid[j].Name })
+	return grid
+}
+
+func timeline(events []corev1.Event) []Event {
+	out := make([]Event, 0, len(events))
+	for _, ev := range events {
+		t := ev.LastTimestamp.Time
+		if t.IsZero() {
+			t = ev.EventTime.Time
+		}
+		if t.IsZero() {
+			t = ev.CreationTimestamp.Time
+		}
+		out = append(out, Event{
+			Time:    t.UTC(),
+			Type:    ev.Type,
+			Reason:  ev.Reason,
+			Object:  ev.InvolvedObject.Kind + "/" + ev.InvolvedObject.Name,
+			Message: ev.Message,
+			Count:   max(ev.Count, 1),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool { return out[i].Time.Before(out[j].Time) })
+	return out
+}
+
+// newRedactor returns a function that replaces every secret value in data.
+// Longer values are replaced first so a secret containing another is not
+// left half-redacted.
+func newRedactor(secrets []string) func([]byte) []byte {
+	if len(secrets) == 0 {
+		return func(data []byte) []byte { return data }
+	}
+	sort.Slice(secrets, func(i, j int) bool { return len(secrets[i]) > len(secrets[j]) })
+	pairs := make([]string, 0, 2*len(secrets))
+	for _, s := range secrets {
+		pairs = append(pairs, s, redacted)
+	}
+	r := strings.NewReplacer(pairs...)
+	return func(data []byte) []byte { return []byte(r.Replace(string(data))) }
+}
+
+// trivialValue reports values too common to redact from text without
+// mangling it, such as booleans and small numbers. Variables holding them
+// are still masked in the environment listing.
+func trivialValue(v string) bool {
+	switch strings.ToLower(v) {
+	case "true", "false", "yes", "no", "null":
+		return true
+	}
+	if len(v) > 4 {
+		return false
+	}
+	for _, r := range v {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// SecretHelmSet reports whether a --set pair carries a secret: a secret-named
+// key holding a value worth redacting. The matrix run plan applies the same
+// rule before it persists --set pairs.
+func SecretHelmSet(key, value string) bool {
+	return secretValuesKey(lastKey(key)) && value != "" && !trivialValue(value)
+}
+
+// lastKey returns the last segment of a dotted --set key.
+func lastKey(key string) string {
+	return key[strings.LastIndexByte(key, '.')+1:]
+}
+
+// secretValuesKey reports whether a values key holds a secret, as opposed to
+// naming a Kubernetes Secret (existingSecret, secretName) that holds it.
+func secretValuesKey(key string) bool {
+	lower := strings.ToLower(key)
+	if strings.HasPrefix(lower, "existingsecret") || strings.HasSuffix(lower, "secretname") {
+		return false
+	}
+	return values.IsSecretName(key)
+}
+
+// redactValuesYAML blanks the scalar values of secret-named keys in a Helm
+// values document, and the value of env entries whose name is secret-named
+// ("- name: X_PASSWORD\n  value: ..."). A document that does not parse is
+// returned as is; the plain-text redaction still applies to it.
+func redactValuesYAML(data []byte) []byte {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil || doc.Kind == 0 {
+		return data
+	}
+	if !redactValuesNode(&doc) {
+		return data
+	}
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&doc); err != nil {
+		return data
+	}
+	return buf.Bytes()
+}
+
+// redactValuesNode redacts n in place and reports whether it changed.
+func redactValuesNode(n *yaml.Node) bool {
+	changed := false
+	if n.Kind == yaml.MappingNode {
+		envName := ""
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == "name" && n.Content[i+1].Kind == yaml.ScalarNode {
+				envName = n.Content[i+1].Value
+			}
+		}
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			key, val := n.Content[i], n.Content[i+1]
+			secret := secretValuesKey(key.Value) || (key.Value == "value" && envName != "" && values.IsSecretName(envName))
+			if secret && val.Kind == yaml.ScalarNode && val.Value != "" && val.Tag != "!!null" {
+				val.Value, val.Tag, val.Style = redacted, "!!str", 0
+				changed = true
+			}
+		}
+	}
+	for _, c := range n.Content {
+		if redactValuesNode(c) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// redactSecretManifests blanks the data of every Secret in a rendered
+// manifest; the values are base64 and would escape plain-text redaction.
+func redactSecretManifests(manifest string) []byte {
+	docs := strings.Split(manifest, "\n---")
+	for i, doc := range docs {
+		var obj map[string]any
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil || obj["kind"] != "Secret" {
+			continue
+		}
+		for _, field := range []string{"data", "stringData"} {
+			if m, ok := obj[field].(map[string]any); ok {
+				for k := range m {
+					m[k] = redacted
+				}
+			}
+		}
+		var buf bytes.Buffer
+		enc := yaml.NewEncoder(&buf)
+		enc.SetIndent(2)
+		if err := enc.Encode(obj); err != nil {
+			continue
+		}
+		// Keep the separator and "# Source:" comment lines ahead of the object.
+		lines := strings.SplitAfter(doc, "\n")
+		n := 0
+		for n < len(lines) {
+			if t := strings.TrimSpace(lines[n]); t != "" && t != "---" && !strings.HasPrefix(t, "#") {
+				break
+			}
+			n++
+		}
+		docs[i] = strings.Join(lines[:n], "") + strings.TrimSuffix(buf.String(), "\n")
+	}
+	return []byte(strings.Join(docs, "\n---"))
+}
@@ -0,0 +1,134 @@
+package diagbundle
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+//go:embed viewer.html
+var viewerHTML string
+
+var viewerTemplate = template.Must(template.New("viewer").Funcs(template.FuncMap{
+	"offset": func(first, t time.Time) string {
+		d := t.Sub(first).Round(time.Second)
+		return fmt.Sprintf("+%d:%02d", int(d.Minutes()), int(d.Seconds())%60)
+	},
+	"clock": func(t time.Time) string { return t.UTC().Format("15:04:05") },
+	"inc":   func(i int) int { return i + 1 },
+	"cellClass": func(c Container) string {
+		switch {
+		case c.State == "running" && c.Ready:
+			return "ok"
+		case c.State == "terminated" && c.Reason == "Completed":
+			return "done"
+		case c.State == "waiting" && (c.Reason == "" || c.Reason == "PodInitializing" || c.Reason == "ContainerCreating"):
+			return "pending"
+		default:
+			return "bad"
+		}
+	},
+}).Parse(viewerHTML))
+
+// viewerData is what viewer.html renders.
+type viewerData struct {
+	Manifest   Manifest
+	FirstEvent time.Time
+	Labels     [][2]string
+	Groups     []fileGroup
+}
+
+type fileGroup struct {
+	Title string
+	Files []File
+}
+
+// fileGroupTitles orders the file list in the viewer.
+var fileGroupTitles = []struct{ kind, title string }{
+	{KindLog, "Container logs"},
+	{KindPreviousLog, "Previous container logs"},
+	{KindStream, "Namespace stream (logs of every container instance since the deploy started)"},
+	{KindTestOutput, "Test output"},
+	{KindHelmValues, "Helm release values"},
+	{KindHelmManifest, "Helm release manifests"},
+	{KindResource, "Resources"},
+}
+
+func newViewerData(m Manifest) viewerData {
+	d := viewerData{Manifest: m}
+	if len(m.Events) > 0 {
+		d.FirstEvent = m.Events[0].Time
+	}
+	for k, v := range m.Labels {
+		d.Labels = append(d.Labels, [2]string{k, v})
+	}
+	sort.Slice(d.Labels, func(i, j int) bool { return d.Labels[i][0] < d.Labels[j][0] })
+	for _, g := range fileGroupTitles {
+		group := fileGroup{Title: g.title}
+		for _, f := range m.Files {
+			if f.Kind == g.kind {
+				group.Files = append(group.Files, f)
+			}
+		}
+		if len(group.Files) > 0 {
+			d.Groups = append(d.Groups, group)
+		}
+	}
+	return d
+}
+
+// Handler serves the viewer page at / and the bundle's files as plain text
+// under /file/. Nothing is fetched from outside the bundle.
+func Handler(b *Bundle) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		if err := viewerTemplate.Execute(w, newViewerData(b.Manifest)); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	mux.HandleFunc("/file/", func(w http.ResponseWriter, r *http.Request) {
+		data, ok := b.File(strings.TrimPrefix(r.URL.Path, "/file/"))
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Write(data) //nolint:errcheck // client went away
+	})
+	return mux
+}
+
+// Serve serves the viewer for b on addr until ctx is done. ready is called
+// with the viewer URL once the listener is up.
+func Serve(ctx context.Context, addr string, b *Bundle, ready func(url string)) error {
+	ln, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("listen on %s: %w", addr, err)
+	}
+	srv := &http.Server{Handler: Handler(b), ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(shutdownCtx) //nolint:errcheck // best-effort on exit
+	}()
+	if ready != nil {
+		ready("http://" + ln.Addr().String() + "/")
+	}
+	if err := srv.Serve(ln); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
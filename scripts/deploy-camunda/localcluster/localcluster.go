@@ -0,0 +1,294 @@
+// Package localcluster creates or reuses a kind or k3d cluster on the
+// developer's machine and installs the ingress controller the integration
+// scenarios expect, so `deploy-camunda --platform local` can run a scenario
+// end to end without a cloud account.
+//
+// Like the rest of deploy-camunda it shells out to the provider CLIs (kind or
+// k3d) and helm rather than linking their libraries; whichever binary is on
+// PATH decides the provider when none is requested.
+package localcluster
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"scripts/camunda-core/pkg/executil"
+	"scripts/camunda-core/pkg/logging"
+)
+
+// Provider is the local Kubernetes distribution used for the cluster.
+type Provider string
+
+const (
+	ProviderKind Provider = "kind"
+	ProviderK3d  Provider = "k3d"
+)
+
+// Providers lists the supported providers in auto-detection order.
+var Providers = []Provider{ProviderKind, ProviderK3d}
+
+// DefaultClusterName is the cluster created when --local-cluster-name is unset.
+const DefaultClusterName = "camunda-local"
+
+const (
+	ingressNamespace = "ingress-nginx"
+	ingressRelease   = "ingress-nginx"
+	ingressChartRepo = "https://kubernetes.github.io/ingress-nginx"
+)
+
+// Options selects and shapes the local cluster.
+type Options struct {
+	// Provider is kind or k3d; empty picks the first one found on PATH.
+	Provider Provider
+	// Name is the cluster name; empty means DefaultClusterName.
+	Name string
+	// HTTPPort and HTTPSPort are the host ports forwarded to the ingress
+	// controller. Zero means 80 and 443, which the nip.io hostnames assume.
+	HTTPPort  int
+	HTTPSPort int
+}
+
+// Cluster describes a cluster that is ready for deploys.
+type Cluster struct {
+	Provider    Provider
+	Name        string
+	KubeContext string
+	// Created is true when Ensure created the cluster rather than reusing it.
+	Created bool
+}
+
+// runCapture and runStreamed run a CLI and are package vars so tests can
+// record invocations without kind, k3d or helm installed.
+var (
+	runCapture = func(ctx context.Context, name string, args ...string) ([]byte, error) {
+		return executil.RunCommandCapture(ctx, name, args, nil, "")
+	}
+	runStreamed = func(ctx context.Context, stdin []byte, name string, args ...string) error {
+		if stdin != nil {
+			return executil.RunCommandWithStdin(ctx, name, args, nil, "", stdin)
+		}
+		return executil.RunCommand(ctx, name, args, nil, "")
+	}
+	lookPath = exec.LookPath
+)
+
+// ParseProvider validates a --local-cluster value. Empty is allowed and means
+// auto-detect.
+func ParseProvider(s string) (Provider, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if s == "" {
+		return "", nil
+	}
+	for _, p := range Providers {
+		if string(p) == s {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("unknown local cluster provider %q (supported: kind, k3d)", s)
+}
+
+// KubeContext returns the kubeconfig context name the provider writes for a
+// cluster.
+func KubeContext(provider Provider, name string) string {
+	return string(provider) + "-" + name
+}
+
+// DetectProvider returns the first supported provider whose CLI is on PATH.
+func DetectProvider() (Provider, error) {
+	for _, p := range Providers {
+		if _, err := lookPath(string(p)); err == nil {
+			return p, nil
+		}
+	}
+	return "", fmt.Errorf("neither kind nor k3d found on PATH; install one of them (https://kind.sigs.k8s.io, https://k3d.io)")
+}
+
+// resolve fills in the provider and name defaults.
+func (o Options) resolve() (Options, error) {
+	if o.Provider == "" {
+		p, err := DetectProvider()
+		if err != nil {
+			return o, err
+		}
+		o.Provider = p
+	}
+	if o.Name == "" {
+		o.Name = DefaultClusterName
+	}
+	if o.HTTPPort == 0 {
+		o.HTTPPort = 80
+	}
+	if o.HTTPSPort == 0 {
+		o.HTTPSPort = 443
+	}
+	return o, nil
+}
+
+// Ensure reuses the named cluster if it exists, otherwise creates it, and
+// then installs (or upgrades) ingress-nginx so it serves on the host ports.
+// It is idempotent, so every local deploy can call it.
+func Ensure(ctx context.Context, opts Options) (Cluster, error) {
+	opts, err := opts.resolve()
+	if err != nil {
+		return Cluster{}, err
+	}
+	cluster := Cluster{Provider: opts.Provider, Name: opts.Name, KubeContext: KubeContext(opts.Provider, opts.Name)}
+
+	exists, err := clusterExists(ctx, opts.Provider, opts.Name)
+	if err != nil {
+		return cluster, err
+	}
+	if exists {
+		logging.Logger.Info().Str("provider", string(opts.Provider)).Str("cluster", opts.Name).Msg("Reusing local cluster")
+	} else {
+		logging.Logger.Info().Str("provider", string(opts.Provider)).Str("cluster", opts.Name).Msg("Creating local cluster")
+		if err := createCluster(ctx, opts); err != nil {
+			return cluster, err
+		}
+		cluster.Created = true
+	}
+
+	if err := installIngress(ctx, opts.Provider, cluster.KubeContext); err != nil {
+		return cluster, err
+	}
+	return cluster, nil
+}
+
+// Delete removes the named cluster. A missing cluster is not an error.
+func Delete(ctx context.Context, opts Options) error {
+	opts, err := opts.resolve()
+	if err != nil {
+		return err
+	}
+	exists, err := clusterExists(ctx, opts.Provider, opts.Name)
+	if err != nil {
+		return err
+	}
+	if !exists {
+		logging.Logger.Info().Str("cluster", opts.Name).Msg("Local cluster does not exist; nothing to delete")
+		return nil
+	}
+	switch opts.Provider {
+	case ProviderKind:
+		err = runStreamed(ctx, nil, "kind", "delete", "cluster", "--name", opts.Name)
+	case ProviderK3d:
+		err = runStreamed(ctx, nil, "k3d", "cluster", "delete", opts.Name)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete %s cluster %q: %w", opts.Provider, opts.Name, err)
+	}
+	return nil
+}
+
+func clusterExists(ctx context.Context, provider Provider, name string) (bool, error) {
+	switch provider {
+	case ProviderKind:
+		out, err := runCapture(ctx, "kind", "get", "clusters")
+		if err != nil {
+			return false, fmt.Errorf("failed to list kind clusters: %w", err)
+		}
+		for _, line := range strings.Split(string(out), "\n") {
+			if strings.TrimSpace(line) == name {
+				return true, nil
+			}
+		}
+		return false, nil
+	case ProviderK3d:
+		out, err := runCapture(ctx, "k3d", "cluster", "list", "-o", "json")
+		if err != nil {
+			return false, fmt.Errorf("failed to list k3d clusters: %w", err)
+		}
+		var clusters []struct {
+			Name string `json:"name"`
+		}
+		if err := json.Unmarshal(out, &clusters); err != nil {
+			return false, fmt.Errorf("failed to parse k3d cluster list: %w", err)
+		}
+		for _, c := range clusters {
+			if c.Name == name {
+				return true, nil
+			}
+		}
+		return false, nil
+	}
+	return false, fmt.Errorf("unknown local cluster provider %q", provider)
+}
+
+func createCluster(ctx context.Context, opts Options) error {
+	var err error
+	switch opts.Provider {
+	case ProviderKind:
+		err = runStreamed(ctx, []byte(kindConfig(opts)), "kind", "create", "cluster", "--name", opts.Name, "--config", "-", "--wait", "5m")
+	case ProviderK3d:
+		err = runStreamed(ctx, nil, "k3d", k3dCreateArgs(opts)...)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create %s cluster %q: %w", opts.Provider, opts.Name, err)
+	}
+	return nil
+}
+
+// kindConfig is a single-node cluster whose node forwards the ingress host
+// ports. A single node keeps the control-plane untainted, so workloads and
+// the ingress controller schedule without tolerations.
+func kindConfig(opts Options) string {
+	return fmt.Sprintf(`kind: Cluster
+apiVersion: kind.x-k8s.io/v1alpha4
+nodes:
+  - role: control-plane
+    extraPortMappings:
+      - containerPort: 80
+        hostPort: %d
+        protocol: TCP
+      - containerPort: 443
+        hostPort: %d
+        protocol: TCP
+`, opts.HTTPPort, opts.HTTPSPort)
+}
+
+// k3dCreateArgs maps the host ports onto k3d's load balancer and disables the
+// bundled Traefik, which would otherwise compete with ingress-nginx for them.
+func k3dCreateArgs(opts Options) []string {
+	return []string{
+		"cluster", "create", opts.Name,
+		"--wait",
+		"--port", fmt.Sprintf("%d:80@loadbalancer", opts.HTTPPort),
+		"--port", fmt.Sprintf("%d:443@loadbalancer", opts.HTTPSPort),
+		"--k3s-arg", "--disable=traefik@server:0",
+	}
+}
+
+// installIngress installs ingress-nginx as the default "nginx" class, which
+// is the class base.yaml asks for. On kind the controller binds the node's
+// host ports directly; on k3d the klipper load balancer forwards to its
+// LoadBalancer service.
+func installIngress(ctx context.Context, provider Provider, kubeContext string) error {
+	logging.Logger.Info().Str("kubeContext", kubeContext).Msg("Installing ingress-nginx")
+	if err := runStreamed(ctx, nil, "helm", ingressHelmArgs(provider, kubeContext)...); err != nil {
+		return fmt.Errorf("failed to install ingress-nginx: %w", err)
+	}
+	return nil
+}
+
+func ingressHelmArgs(provider Provider, kubeContext string) []string {
+	args := []string{
+		"upgrade", "--install", ingressRelease, "ingress-nginx",
+		"--repo", ingressChartRepo,
+		"--namespace", ingressNamespace, "--create-namespace",
+		"--kube-context", kubeContext,
+		"--wait", "--timeout", "5m",
+		"--set", "controller.ingressClassResource.name=nginx",
+		"--set", "controller.ingressClassResource.default=true",
+		"--set", "controller.admissionWebhooks.enabled=false",
+	}
+	if provider == ProviderKind {
+		args = append(args,
+			"--set", "controller.hostPort.enabled=true",
+			"--set", "controller.service.type=NodePort",
+		)
+	}
+	return args
+}
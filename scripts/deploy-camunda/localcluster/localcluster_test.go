@@ -0,0 +1,149 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package localcluster
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// fakeCLI records every command and answers list calls with canned output.
+type fakeCLI struct {
+	listOutput string
+	calls      []string
+	stdin      map[string]string
+}
+
+func (f *fakeCLI) install(t *testing.T) {
+	t.Helper()
+	origCapture, origStreamed := runCapture, runStreamed
+	t.Cleanup(func() { runCapture, runStreamed = origCapture, origStreamed })
+	f.stdin = map[string]string{}
+	runCapture = func(_ context.Context, name string, args ...string) ([]byte, error) {
+		f.calls = append(f.calls, name+" "+strings.Join(args, " "))
+		return []byte(f.listOutput), nil
+	}
+	runStreamed = func(_ context.Context, stdin []byte, name string, args ...string) error {
+		call := name + " " + strings.Join(args, " ")
+		f.calls = append(f.calls, call)
+		if stdin != nil {
+			f.stdin[call] = string(stdin)
+		}
+		return nil
+	}
+}
+
+func TestEnsureCreatesKindCluster(t *testing.T) {
+	cli := &fakeCLI{listOutput: "other\n"}
+	cli.install(t)
+
+	cluster, err := Ensure(context.Background(), Options{Provider: ProviderKind, HTTPPort: 8080})
+	if err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	if !cluster.Created || cluster.KubeContext != "kind-camunda-local" {
+		t.Errorf("cluster = %+v", cluster)
+	}
+	if len(cli.calls) != 3 {
+		t.Fatalf("calls = %v", cli.calls)
+	}
+	create := cli.calls[1]
+	if !strings.HasPrefix(create, "kind create cluster --name camunda-local --config -") {
+		t.Errorf("create call = %q", create)
+	}
+	cfg := cli.stdin[create]
+	if !strings.Contains(cfg, "hostPort: 8080") || !strings.Contains(cfg, "hostPort: 443") {
+		t.Errorf("kind config does not map ingress ports:\n%s", cfg)
+	}
+	helm := cli.calls[2]
+	for _, want := range []string{"upgrade --install ingress-nginx", "--kube-context kind-camunda-local", "controller.hostPort.enabled=true"} {
+		if !strings.Contains(helm, want) {
+			t.Errorf("helm call missing %q: %s", want, helm)
+		}
+	}
+}
+
+func TestEnsureReusesK3dCluster(t *testing.T) {
+	cli := &fakeCLI{listOutput: `[{"name":"dev"},{"name":"camunda-local"}]`}
+	cli.install(t)
+
+	cluster, err := Ensure(context.Background(), Options{Provider: ProviderK3d})
+	if err != nil {
+		t.Fatalf("Ensure: %v", err)
+	}
+	if cluster.Created || cluster.KubeContext != "k3d-camunda-local" {
+		t.Errorf("cluster = %+v", cluster)
+	}
+	for _, c := range cli.calls {
+		if strings.HasPrefix(c, "k3d cluster create") {
+			t.Errorf("existing cluster was re-created: %v", cli.calls)
+		}
+	}
+	if helm := cli.calls[len(cli.calls)-1]; !strings.HasPrefix(helm, "helm upgrade --install") || strings.Contains(helm, "hostPort") {
+		t.Errorf("k3d ingress install = %q", helm)
+	}
+}
+
+func TestK3dCreateArgsDisableTraefik(t *testing.T) {
+	got := strings.Join(k3dCreateArgs(Options{Name: "c", HTTPPort: 80, HTTPSPort: 8443}), " ")
+	for _, want := range []string{"--port 80:80@loadbalancer", "--port 8443:443@loadbalancer", "--disable=traefik@server:0"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("k3d args missing %q: %s", want, got)
+		}
+	}
+}
+
+func TestDeleteMissingClusterIsNoop(t *testing.T) {
+	cli := &fakeCLI{listOutput: "\n"}
+	cli.install(t)
+	if err := Delete(context.Background(), Options{Provider: ProviderKind, Name: "gone"}); err != nil {
+		t.Fatalf("Delete: %v", err)
+	}
+	if len(cli.calls) != 1 {
+		t.Errorf("expected only the list call, got %v", cli.calls)
+	}
+}
+
+func TestDetectProvider(t *testing.T) {
+	orig := lookPath
+	t.Cleanup(func() { lookPath = orig })
+	lookPath = func(file string) (string, error) {
+		if file == "k3d" {
+			return "/usr/local/bin/k3d", nil
+		}
+		return "", errors.New("not found")
+	}
+	if p, err := DetectProvider(); err != nil || p != ProviderK3d {
+		t.Fatalf("DetectProvider = %q, %v", p, err)
+	}
+	lookPath = func(string) (string, error) { return "", errors.New("not found") }
+	if _, err := DetectProvider(); err == nil {
+		t.Fatal("expected error when neither CLI is installed")
+	}
+}
+
+func TestParseProvider(t *testing.T) {
+	if p, err := ParseProvider(" K3D "); err != nil || p != ProviderK3d {
+		t.Errorf("ParseProvider(K3D) = %q, %v", p, err)
+	}
+	if p, err := ParseProvider(""); err != nil || p != "" {
+		t.Errorf("ParseProvider(\"\") = %q, %v", p, err)
+	}
+	if _, err := ParseProvider("minikube"); err == nil {
+		t.Error("expected error for minikube")
+	}
+}
@@ -0,0 +1,70 @@
+package matrix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"scripts/camunda-core/pkg/kube"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/config"
+	"scripts/deploy-camunda/deploy"
+	"scripts/deploy-camunda/diagbundle"
+)
+
+// diagnosticsBundleTailLines caps each container log in the bundle. The
+// namespace stream, when present, holds the complete logs.
+const diagnosticsBundleTailLines = 5000
+
+// writeDiagnosticsBundle collects a diagnostics bundle for a failed entry into
+// <runDir>/bundle.tar.gz and lists it in the run dir's README. Like
+// collectDiagnostics it is best-effort and uses a fresh context; failures are
+// logged, never returned.
+func writeDiagnosticsBundle(runDir string, entry Entry, namespace, kubeContext string, flags *config.RuntimeFlags, streamDir string, deployErr error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	client, err := kube.NewClient("", kubeContext)
+	if err != nil {
+		logging.Logger.Warn().Err(err).Str("namespace", namespace).Msg("Diagnostics bundle skipped")
+		return
+	}
+	testOutput, err := os.ReadFile(filepath.Join(runDir, "test-output.txt"))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		logging.Logger.Warn().Err(err).Msg("Diagnostics bundle: failed to read test output")
+	}
+
+	bundle := diagbundle.Collect(ctx, client, diagbundle.CollectOptions{
+		Namespace:   namespace,
+		KubeContext: kubeContext,
+		Labels: map[string]string{
+			"version":  entry.Version,
+			"scenario": entry.Scenario,
+			"flow":     entry.Flow,
+			"platform": entry.Platform,
+			"auth":     entry.Auth,
+		},
+		Error:             deployErr.Error(),
+		LogTailLines:      diagnosticsBundleTailLines,
+		ValuesSnapshotDir: flags.ValuesSnapshotDir,
+		Environment:       deploy.EnvProvenance(flags),
+		HelmSets:          flags.Deployment.ExtraHelmSets,
+		StreamDir:         streamDir,
+		TestOutput:        string(testOutput),
+	})
+	path := filepath.Join(runDir, diagbundle.FileName)
+	if err := bundle.WriteFile(path); err != nil {
+		logging.Logger.Warn().Err(err).Str("path", path).Msg("Failed to write diagnostics bundle")
+		return
+	}
+
+	readme, err := os.OpenFile(filepath.Join(runDir, "README.txt"), os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return
+	}
+	defer readme.Close()
+	fmt.Fprintf(readme, "\nBundle: %s (open with `deploy-camunda diagnostics view %s`)\n", diagbundle.FileName, path)
+}
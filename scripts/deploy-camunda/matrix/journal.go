@@ -0,0 +1,514 @@
+package matrix
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"scripts/camunda-core/pkg/kube"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/diagbundle"
+)
+
+const (
+	// JournalFileName is the append-only run journal written into --log-dir.
+	// One JSON record per line; a crash can at worst truncate the last line.
+	JournalFileName = "run-journal.jsonl"
+	// RunPlanFileName holds the entries and RunOptions of the original run so
+	// `matrix resume` can continue without re-resolving flags and config.
+	RunPlanFileName = "run-plan.json"
+)
+
+// JournalEvent is the kind of a journal record.
+type JournalEvent string
+
+const (
+	JournalStart    JournalEvent = "start"
+	JournalPhase    JournalEvent = "phase"
+	JournalComplete JournalEvent = "complete"
+)
+
+// Entry outcomes recorded on JournalComplete records.
+const (
+	OutcomePassed = "passed"
+	OutcomeFailed = "failed"
+	// OutcomeInterrupted marks an entry that returned because the run itself
+	// was cancelled (Ctrl-C, SIGTERM). Its result says nothing about the
+	// scenario, so resume runs it again.
+	OutcomeInterrupted = "interrupted"
+	// OutcomeSkipped marks an entry that was never dispatched because the run
+	// was cancelled before a slot freed up.
+	OutcomeSkipped = "skipped"
+)
+
+// JournalRecord is one line of the run journal.
+type JournalRecord struct {
+	Time            time.Time    `json:"time"`
+	Event           JournalEvent `json:"event"`
+	Key             string       `json:"key"`
+	Phase           string       `json:"phase,omitempty"`
+	Namespace       string       `json:"namespace,omitempty"`
+	KubeContext     string       `json:"kubeContext,omitempty"`
+	Outcome         string       `json:"outcome,omitempty"`
+	Error           string       `json:"error,omitempty"`
+	DurationSeconds float64      `json:"durationSeconds,omitempty"`
+	Diagnostics     string       `json:"diagnostics,omitempty"`
+}
+
+// Journal appends entry lifecycle records to JournalFileName. It is safe for
+// concurrent use by parallel entries.
+type Journal struct {
+	mu  sync.Mutex
+	f   *os.File
+	now func() time.Time
+}
+
+// OpenJournal opens (or creates) the journal in logDir for appending, so a
+// resumed run continues the same file.
+func OpenJournal(logDir string) (*Journal, error) {
+	f, err := os.OpenFile(filepath.Join(logDir, JournalFileName), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("open run journal: %w", err)
+	}
+	return &Journal{f: f, now: time.Now}, nil
+}
+
+// Close closes the journal file.
+func (j *Journal) Close() error {
+	return j.f.Close()
+}
+
+// append writes rec as one line and syncs it, so the record survives the
+// process being killed right after the call returns. Write errors are logged
+// rather than returned: a broken journal must not fail the run itself.
+func (j *Journal) append(rec JournalRecord) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if rec.Time.IsZero() {
+		rec.Time = j.now().UTC()
+	}
+	line, err := json.Marshal(rec)
+	if err != nil {
+		logging.Logger.Warn().Err(err).Str("key", rec.Key).Msg("Failed to encode run journal record")
+		return
+	}
+	if _, err := j.f.Write(append(line, '\n')); err != nil {
+		logging.Logger.Warn().Err(err).Str("key", rec.Key).Msg("Failed to write run journal record")
+		return
+	}
+	_ = j.f.Sync()
+}
+
+// Attach returns a copy of opts whose OnEntryStart, OnPhaseChange and
+// OnEntryComplete callbacks also write journal records. Existing callbacks
+// still run. ctx is the run's context: entries completing after it is
+// cancelled are recorded as interrupted rather than failed.
+func (j *Journal) Attach(ctx context.Context, opts RunOptions) RunOptions {
+	onStart, onPhase, onComplete := opts.OnEntryStart, opts.OnPhaseChange, opts.OnEntryComplete
+	kubeContextFor := func(entry Entry) string {
+		return resolveKubeContext(opts, resolvePlatform(opts, entry))
+	}
+
+	opts.OnEntryStart = func(entry Entry, namespace string) {
+		j.append(JournalRecord{Event: JournalStart, Key: entryID(entry), Namespace: namespace, KubeContext: kubeContextFor(entry)})
+		if onStart != nil {
+			onStart(entry, namespace)
+		}
+	}
+	opts.OnPhaseChange = func(entry Entry, phase string) {
+		j.append(JournalRecord{Event: JournalPhase, Key: entryID(entry), Phase: phase})
+		if onPhase != nil {
+			onPhase(entry, phase)
+		}
+	}
+	opts.OnEntryComplete = func(entry Entry, result RunResult) {
+		rec := JournalRecord{
+			Event:           JournalComplete,
+			Key:             entryID(entry),
+			Namespace:       result.Namespace,
+			KubeContext:     result.KubeContext,
+			Outcome:         resultOutcome(ctx, result),
+			DurationSeconds: result.Duration.Seconds(),
+			Diagnostics:     result.Diagnostics,
+		}
+		if result.Error != nil {
+			rec.Error = result.Error.Error()
+		}
+		j.append(rec)
+		if onComplete != nil {
+			onComplete(entry, result)
+		}
+	}
+	return opts
+}
+
+// resultOutcome classifies a finished entry for the journal.
+func resultOutcome(ctx context.Context, result RunResult) string {
+	switch {
+	case result.Error == nil:
+		return OutcomePassed
+	case isSkippedResult(result):
+		return OutcomeSkipped
+	case ctx.Err() != nil:
+		return OutcomeInterrupted
+	default:
+		return OutcomeFailed
+	}
+}
+
+// EntryState is the folded journal history of one entry: the latest attempt
+// wins.
+type EntryState struct {
+	Key         string
+	Namespace   string
+	KubeContext string
+	// Phase is the last phase reported before the entry completed or the run
+	// stopped.
+	Phase       string
+	StartedAt   time.Time
+	EndedAt     time.Time
+	Outcome     string
+	Error       string
+	Duration    time.Duration
+	Diagnostics string
+}
+
+// Completed reports whether the entry reached a final pass/fail outcome.
+func (s EntryState) Completed() bool {
+	return s.Outcome == OutcomePassed || s.Outcome == OutcomeFailed
+}
+
+// HalfFinished reports whether the entry started deploying but never reached
+// a final outcome, so its namespace may hold a partial release.
+func (s EntryState) HalfFinished() bool {
+	return !s.StartedAt.IsZero() && !s.Completed()
+}
+
+// ReadJournal folds the journal in logDir into per-entry state keyed by
+// entry ID. A truncated final line (the process died mid-write) is ignored;
+// a malformed line anywhere else is an error.
+func ReadJournal(logDir string) (map[string]EntryState, error) {
+	f, err := os.Open(filepath.Join(logDir, JournalFileName))
+	if err != nil {
+		return nil, fmt.Errorf("open run journal: %w", err)
+	}
+	defer f.Close()
+
+	var lines []string
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("read run journal: %w", err)
+	}
+
+	states := make(map[string]EntryState)
+	for i, line := range lines {
+		var rec JournalRecord
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			if i == len(lines)-1 {
+				logging.Logger.Warn().Err(err).Msg("Ignoring truncated last run journal record")
+				break
+			}
+			return nil, fmt.Errorf("run journal line %d: %w", i+1, err)
+		}
+		s := states[rec.Key]
+		s.Key = rec.Key
+		switch rec.Event {
+		case JournalStart:
+			s = EntryState{Key: rec.Key, Namespace: rec.Namespace, KubeContext: rec.KubeContext, StartedAt: rec.Time}
+		case JournalPhase:
+			s.Phase = rec.Phase
+		case JournalComplete:
+			if rec.Namespace != "" {
+				s.Namespace = rec.Namespace
+			}
+			if rec.KubeContext != "" {
+				s.KubeContext = rec.KubeContext
+			}
+			s.EndedAt = rec.Time
+			s.Outcome = rec.Outcome
+			s.Error = rec.Error
+			s.Duration = time.Duration(rec.DurationSeconds * float64(time.Second))
+			s.Diagnostics = rec.Diagnostics
+		}
+		states[rec.Key] = s
+	}
+	return states, nil
+}
+
+// RunPlan is what `matrix run` persists next to the journal: the entries it
+// was asked to run and the options it ran them with. Callbacks and registry
+// passwords are not persisted (see the json:"-" tags on RunOptions); resume
+// re-reads passwords from the environment like the deployer already does.
+// Secret --set values in ExtraHelmSets and ExtraHelmArgs are written as
+// RedactedHelmValue and must be passed again at resume (see
+// RestoreRedactedHelmSets).
+type RunPlan struct {
+	CreatedAt time.Time  `json:"createdAt"`
+	Entries   []Entry    `json:"entries"`
+	Options   RunOptions `json:"options"`
+}
+
+// RedactedHelmValue stands in for a secret --set value in the run plan.
+const RedactedHelmValue = "<redacted>"
+
+// helmSetFlags are the helm flags whose key=value pairs carry literal values.
+// --set-file is left alone: its value is a path, not the secret itself.
+var helmSetFlags = []string{"--set", "--set-string", "--set-json", "--set-literal"}
+
+// SaveRunPlan writes plan to RunPlanFileName in logDir, with secret --set
+// values replaced by RedactedHelmValue (diagbundle.SecretHelmSet decides, as
+// for the diagnostics bundle).
+func SaveRunPlan(logDir string, plan RunPlan) error {
+	plan.Options.ExtraHelmSets = mapHelmSetPairs(plan.Options.ExtraHelmSets, redactHelmSet)
+	plan.Options.ExtraHelmArgs = mapHelmArgs(plan.Options.ExtraHelmArgs, redactHelmSet)
+	data, err := json.MarshalIndent(plan, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode run plan: %w", err)
+	}
+	if err := os.WriteFile(filepath.Join(logDir, RunPlanFileName), append(data, '\n'), 0o600); err != nil {
+		return fmt.Errorf("write run plan: %w", err)
+	}
+	return nil
+}
+
+// LoadRunPlan reads the RunPlan persisted in logDir.
+func LoadRunPlan(logDir string) (RunPlan, error) {
+	var plan RunPlan
+	data, err := os.ReadFile(filepath.Join(logDir, RunPlanFileName))
+	if err != nil {
+		return plan, fmt.Errorf("read run plan: %w", err)
+	}
+	if err := json.Unmarshal(data, &plan); err != nil {
+		return plan, fmt.Errorf("parse run plan: %w", err)
+	}
+	return plan, nil
+}
+
+// RestoreRedactedHelmSets puts back the secret --set values SaveRunPlan
+// redacted, taking them from key=value pairs given at resume. It fails
+// naming every key that is still redacted.
+func RestoreRedactedHelmSets(opts *RunOptions, pairs []string) error {
+	given := parseHelmSetPairs(pairs)
+	var missing []string
+	restore := func(key, value string) string {
+		if value != RedactedHelmValue {
+			return value
+		}
+		if v, ok := given[key]; ok {
+			return v
+		}
+		missing = append(missing, key)
+		return value
+	}
+	opts.ExtraHelmSets = mapHelmSetPairs(opts.ExtraHelmSets, restore)
+	opts.ExtraHelmArgs = mapHelmArgs(opts.ExtraHelmArgs, restore)
+	if len(missing) > 0 {
+		return fmt.Errorf("the run plan redacts the --set values of %s; pass them again with --extra-helm-set key=value", strings.Join(missing, ", "))
+	}
+	return nil
+}
+
+func redactHelmSet(key, value string) string {
+	if diagbundle.SecretHelmSet(key, value) {
+		return RedactedHelmValue
+	}
+	return value
+}
+
+// mapHelmSetPairs applies fn to the value of every key=value pair; a pair
+// may hold several comma-separated assignments, as helm allows.
+func mapHelmSetPairs(pairs []string, fn func(key, value string) string) []string {
+	if pairs == nil {
+		return nil
+	}
+	out := make([]string, len(pairs))
+	for i, p := range pairs {
+		out[i] = mapHelmSetPair(p, fn)
+	}
+	return out
+}
+
+func mapHelmSetPair(pair string, fn func(key, value string) string) string {
+	parts := strings.Split(pair, ",")
+	for i, part := range parts {
+		if k, v, ok := strings.Cut(part, "="); ok && k != "" {
+			parts[i] = k + "=" + fn(k, v)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// mapHelmArgs applies fn to the values of --set style flags in raw helm
+// arguments, written either as "--set=k=v" or as "--set" followed by "k=v".
+func mapHelmArgs(args []string, fn func(key, value string) string) []string {
+	if args == nil {
+		return nil
+	}
+	out := make([]string, len(args))
+	copy(out, args)
+	for i := 0; i < len(out); i++ {
+		for _, flag := range helmSetFlags {
+			if out[i] == flag && i+1 < len(out) {
+				i++
+				out[i] = mapHelmSetPair(out[i], fn)
+				break
+			}
+			if rest, ok := strings.CutPrefix(out[i], flag+"="); ok {
+				out[i] = flag + "=" + mapHelmSetPair(rest, fn)
+				break
+			}
+		}
+	}
+	return out
+}
+
+// ResolveRunLogDir returns the run directory to resume from dir. dir may be
+// the run directory itself or the --log-dir parent, in which case its
+// "latest" symlink is followed.
+func ResolveRunLogDir(dir string) (string, error) {
+	for _, candidate := range []string{dir, filepath.Join(dir, "latest")} {
+		if _, err := os.Stat(filepath.Join(candidate, RunPlanFileName)); err == nil {
+			return filepath.EvalSymlinks(candidate)
+		}
+	}
+	return "", fmt.Errorf("%s not found in %s (or its latest/ run); was it written by `matrix run --log-dir`?", RunPlanFileName, dir)
+}
+
+// ResumeMode selects what resume does with half-finished namespaces.
+type ResumeMode string
+
+const (
+	// ResumeCleanup deletes a half-finished entry's namespace before running
+	// it again from scratch.
+	ResumeCleanup ResumeMode = "cleanup"
+	// ResumeReattach deploys into the existing namespace again; helm upgrade
+	// --install picks up whatever the interrupted attempt left behind.
+	ResumeReattach ResumeMode = "reattach"
+)
+
+// ParseResumeMode validates a --half-finished value. Empty means
+// ResumeCleanup.
+func ParseResumeMode(s string) (ResumeMode, error) {
+	switch ResumeMode(s) {
+	case "", ResumeCleanup:
+		return ResumeCleanup, nil
+	case ResumeReattach:
+		return ResumeReattach, nil
+	default:
+		return "", fmt.Errorf("unsupported half-finished mode %q; supported: %s, %s", s, ResumeCleanup, ResumeReattach)
+	}
+}
+
+// ResumePlan splits a persisted run into what is already done and what
+// still has to run.
+type ResumePlan struct {
+	// Done holds results reconstructed from the journal for entries that
+	// reached a final outcome and are not being retried.
+	Done []RunResult
+	// Pending are the entries still to run, in their original order.
+	Pending []Entry
+	// HalfFinished are the journal states of Pending entries that had
+	// started before the run stopped.
+	HalfFinished []EntryState
+}
+
+// PlanResume decides which entries of plan still need to run given the
+// journal states. Entries that passed are always skipped; failed entries are
+// skipped unless retryFailed is set.
+func PlanResume(plan RunPlan, states map[string]EntryState, retryFailed bool) ResumePlan {
+	var rp ResumePlan
+	for _, entry := range plan.Entries {
+		s, ok := states[entryID(entry)]
+		if ok && s.Completed() && !(retryFailed && s.Outcome == OutcomeFailed) {
+			rp.Done = append(rp.Done, s.result(entry))
+			continue
+		}
+		rp.Pending = append(rp.Pending, entry)
+		if ok && s.HalfFinished() {
+			rp.HalfFinished = append(rp.HalfFinished, s)
+		}
+	}
+	return rp
+}
+
+// result rebuilds the RunResult summary fields from a completed state.
+func (s EntryState) result(entry Entry) RunResult {
+	r := RunResult{
+		Entry:       entry,
+		Namespace:   s.Namespace,
+		KubeContext: s.KubeContext,
+		Duration:    s.Duration,
+		Diagnostics: s.Diagnostics,
+	}
+	if s.Outcome == OutcomeFailed {
+		r.Error = errors.New(s.Error)
+	}
+	return r
+}
+
+// halfFinishedDeleteTimeout bounds the namespace deletion resume performs
+// per half-finished entry. A full Camunda namespace takes far longer to
+// terminate than the 30s per-entry cleanup budget.
+const halfFinishedDeleteTimeout = 10 * time.Minute
+
+// deleteNamespace is kube.DeleteNamespace bound to the default kubeconfig;
+// a variable so tests can resume without a cluster.
+var deleteNamespace = func(ctx context.Context, kubeContext, namespace string) error {
+	return kube.DeleteNamespace(ctx, "", kubeContext, namespace)
+}
+
+// Resume continues a persisted run: it handles half-finished namespaces
+// according to mode, runs the pending entries with opts, and returns the
+// reconstructed results of finished entries followed by the new ones.
+//
+// With NamespaceOverride set the namespace was provisioned outside the
+// matrix (CI pre-creates it with secrets), so it is never deleted and
+// ResumeCleanup degrades to ResumeReattach. Entra and Auth0 registrations
+// made by an interrupted attempt are not recorded in the journal and are
+// not cleaned up here.
+func Resume(ctx context.Context, rp ResumePlan, opts RunOptions, mode ResumeMode) ([]RunResult, error) {
+	results := append([]RunResult{}, rp.Done...)
+	if len(rp.Pending) == 0 {
+		return results, nil
+	}
+
+	if mode == ResumeCleanup && opts.NamespaceOverride != "" && len(rp.HalfFinished) > 0 {
+		logging.Logger.Warn().
+			Str("namespace", opts.NamespaceOverride).
+			Msg("Namespace override is set; re-attaching to half-finished namespace instead of deleting it")
+		mode = ResumeReattach
+	}
+	for _, s := range rp.HalfFinished {
+		logEvent := logging.Logger.Info().
+			Str("entry", s.Key).
+			Str("namespace", s.Namespace).
+			Str("lastPhase", s.Phase)
+		if mode == ResumeReattach {
+			logEvent.Msg("Re-attaching to half-finished namespace")
+			continue
+		}
+		logEvent.Msg("Deleting half-finished namespace before re-running entry")
+		deleteCtx, cancel := context.WithTimeout(ctx, halfFinishedDeleteTimeout)
+		err := deleteNamespace(deleteCtx, s.KubeContext, s.Namespace)
+		cancel()
+		if err != nil {
+			return results, fmt.Errorf("delete half-finished namespace %q for %s: %w", s.Namespace, s.Key, err)
+		}
+	}
+
+	newResults, err := Run(ctx, rp.Pending, opts)
+	return append(results, newResults...), err
+}
@@ -0,0 +1,386 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	journalPassed  = Entry{Version: "8.9", Shortname: "eske", Flow: "install", Platform: "gke"}
+	journalFailed  = Entry{Version: "8.9", Shortname: "osba", Flow: "install", Platform: "gke"}
+	journalRunning = Entry{Version: "8.8", Shortname: "eske", Flow: "upgrade-patch", Platform: "gke"}
+	journalQueued  = Entry{Version: "8.8", Shortname: "rdbm", Flow: "install", Platform: "gke"}
+)
+
+// writeInterruptedJournal simulates a run that was killed while
+// journalRunning was deploying and before journalQueued started.
+func writeInterruptedJournal(t *testing.T, dir string) {
+	t.Helper()
+	j, err := OpenJournal(dir)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer j.Close()
+	opts := j.Attach(context.Background(), RunOptions{KubeContext: "gke-ctx", NamespacePrefix: "matrix"})
+
+	opts.OnEntryStart(journalPassed, "matrix-89-eske-inst")
+	opts.OnPhaseChange(journalPassed, "deploying")
+	opts.OnEntryComplete(journalPassed, RunResult{Namespace: "matrix-89-eske-inst", KubeContext: "gke-ctx", Duration: 90 * time.Second})
+
+	opts.OnEntryStart(journalFailed, "matrix-89-osba-inst")
+	opts.OnEntryComplete(journalFailed, RunResult{Namespace: "matrix-89-osba-inst", Error: errors.New("helm timed out"), Duration: time.Minute, Diagnostics: "/tmp/diag"})
+
+	opts.OnEntryStart(journalRunning, "matrix-88-eske-upgp")
+	opts.OnPhaseChange(journalRunning, "step-1")
+}
+
+func TestJournal_FoldsEntryHistory(t *testing.T) {
+	dir := t.TempDir()
+	writeInterruptedJournal(t, dir)
+
+	states, err := ReadJournal(dir)
+	if err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	}
+	if len(states) != 3 {
+		t.Fatalf("got %d states, want 3: %+v", len(states), states)
+	}
+
+	passed := states[entryID(journalPassed)]
+	if passed.Outcome != OutcomePassed || !passed.Completed() || passed.HalfFinished() {
+		t.Errorf("passed entry state = %+v", passed)
+	}
+	if passed.KubeContext != "gke-ctx" || passed.Duration != 90*time.Second {
+		t.Errorf("passed entry kubeContext/duration = %q/%s", passed.KubeContext, passed.Duration)
+	}
+
+	failed := states[entryID(journalFailed)]
+	if failed.Outcome != OutcomeFailed || failed.Error != "helm timed out" || failed.Diagnostics != "/tmp/diag" {
+		t.Errorf("failed entry state = %+v", failed)
+	}
+
+	running := states[entryID(journalRunning)]
+	if !running.HalfFinished() || running.Phase != "step-1" || running.Namespace != "matrix-88-eske-upgp" {
+		t.Errorf("running entry state = %+v", running)
+	}
+}
+
+func TestJournal_CancelledRunRecordsInterrupted(t *testing.T) {
+	dir := t.TempDir()
+	j, err := OpenJournal(dir)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	opts := j.Attach(ctx, RunOptions{})
+	opts.OnEntryStart(journalPassed, "ns")
+	cancel()
+	opts.OnEntryComplete(journalPassed, RunResult{Namespace: "ns", Error: errors.New("signal: killed"), Duration: time.Second})
+	opts.OnEntryComplete(journalQueued, RunResult{Namespace: "ns2", Error: fmt.Errorf("%w: run cancelled", errEntrySkipped)})
+	j.Close()
+
+	states, err := ReadJournal(dir)
+	if err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	}
+	if got := states[entryID(journalPassed)]; got.Outcome != OutcomeInterrupted || !got.HalfFinished() {
+		t.Errorf("cancelled entry state = %+v, want interrupted and half-finished", got)
+	}
+	if got := states[entryID(journalQueued)]; got.Outcome != OutcomeSkipped || got.HalfFinished() || got.Completed() {
+		t.Errorf("skipped entry state = %+v, want skipped and never started", got)
+	}
+}
+
+// TestJournal_HookRegistrationFailureCompletesEntry runs an entry whose
+// pre-install hook is invalid: executeEntry returns before deploying, and the
+// journal must still record it as finished so resume does not re-run it.
+func TestJournal_HookRegistrationFailureCompletesEntry(t *testing.T) {
+	t.Setenv("INFRA_INGRESS_HOSTNAME_BASE", "")
+	dir := t.TempDir()
+	j, err := OpenJournal(dir)
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	entry := Entry{
+		Version:    "8.9",
+		ChartPath:  "charts/camunda-platform-8.9",
+		Scenario:   "elasticsearch-basic",
+		Shortname:  "esba",
+		Flow:       "install",
+		Platform:   "gke",
+		PreInstall: &LifecycleHook{Script: "seed.sh"},
+	}
+	opts := j.Attach(context.Background(), RunOptions{})
+	result := executeEntry(context.Background(), entry, opts)
+	j.Close()
+	if result.Error == nil || !strings.Contains(result.Error.Error(), "description") {
+		t.Fatalf("executeEntry error = %v, want the hook validation error", result.Error)
+	}
+
+	states, err := ReadJournal(dir)
+	if err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	}
+	if got := states[entryID(entry)]; got.Outcome != OutcomeFailed || !got.Completed() || got.HalfFinished() {
+		t.Errorf("entry state = %+v, want failed and completed", got)
+	}
+}
+
+func TestJournal_AttachKeepsExistingCallbacks(t *testing.T) {
+	j, err := OpenJournal(t.TempDir())
+	if err != nil {
+		t.Fatalf("OpenJournal: %v", err)
+	}
+	defer j.Close()
+	var calls []string
+	opts := j.Attach(context.Background(), RunOptions{
+		OnEntryStart:    func(Entry, string) { calls = append(calls, "start") },
+		OnPhaseChange:   func(Entry, string) { calls = append(calls, "phase") },
+		OnEntryComplete: func(Entry, RunResult) { calls = append(calls, "complete") },
+	})
+	opts.OnEntryStart(journalPassed, "ns")
+	opts.OnPhaseChange(journalPassed, "deploying")
+	opts.OnEntryComplete(journalPassed, RunResult{})
+	if got := strings.Join(calls, ","); got != "start,phase,complete" {
+		t.Fatalf("callbacks = %s", got)
+	}
+}
+
+func TestReadJournal_TruncatedRecords(t *testing.T) {
+	dir := t.TempDir()
+	writeInterruptedJournal(t, dir)
+	path := filepath.Join(dir, JournalFileName)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(path, append(data, []byte(`{"event":"start","key":"8.8/rd`)...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadJournal(dir); err != nil {
+		t.Fatalf("truncated last record must be ignored, got %v", err)
+	}
+
+	if err := os.WriteFile(path, append([]byte("not json\n"), data...), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ReadJournal(dir); err == nil || !strings.Contains(err.Error(), "line 1") {
+		t.Fatalf("expected error for malformed first line, got %v", err)
+	}
+}
+
+func TestPlanResume(t *testing.T) {
+	dir := t.TempDir()
+	writeInterruptedJournal(t, dir)
+	states, err := ReadJournal(dir)
+	if err != nil {
+		t.Fatalf("ReadJournal: %v", err)
+	}
+	plan := RunPlan{Entries: []Entry{journalPassed, journalFailed, journalRunning, journalQueued}}
+
+	rp := PlanResume(plan, states, false)
+	if got := entryKeys(rp.Pending); got != "8.8/eske/upgrade-patch/gke,8.8/rdbm/install/gke" {
+		t.Errorf("pending = %s", got)
+	}
+	if len(rp.Done) != 2 || rp.Done[0].Error != nil || rp.Done[1].Error == nil || rp.Done[1].Error.Error() != "helm timed out" {
+		t.Errorf("done = %+v", rp.Done)
+	}
+	if len(rp.HalfFinished) != 1 || rp.HalfFinished[0].Key != entryID(journalRunning) {
+		t.Errorf("half-finished = %+v", rp.HalfFinished)
+	}
+
+	rp = PlanResume(plan, states, true)
+	if got := entryKeys(rp.Pending); got != "8.9/osba/install/gke,8.8/eske/upgrade-patch/gke,8.8/rdbm/install/gke" {
+		t.Errorf("pending with retry-failed = %s", got)
+	}
+	if len(rp.HalfFinished) != 1 {
+		t.Errorf("a failed entry finished; it must not count as half-finished: %+v", rp.HalfFinished)
+	}
+}
+
+func TestRunPlan_RoundTripOmitsPasswords(t *testing.T) {
+	dir := t.TempDir()
+	plan := RunPlan{
+		CreatedAt: time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC),
+		Entries:   []Entry{journalPassed},
+		Options: RunOptions{
+			KubeContexts:      map[string]string{"gke": "gke-ctx"},
+			HelmTimeout:       15,
+			DockerUsername:    "robot",
+			DockerPassword:    "s3cret",
+			DockerHubPassword: "hub-s3cret",
+			OnEntryStart:      func(Entry, string) {},
+		},
+	}
+	if err := SaveRunPlan(dir, plan); err != nil {
+		t.Fatalf("SaveRunPlan: %v", err)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, RunPlanFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "s3cret") {
+		t.Fatalf("run plan leaks a registry password:\n%s", raw)
+	}
+
+	got, err := LoadRunPlan(dir)
+	if err != nil {
+		t.Fatalf("LoadRunPlan: %v", err)
+	}
+	if got.Options.KubeContexts["gke"] != "gke-ctx" || got.Options.HelmTimeout != 15 || got.Options.DockerUsername != "robot" {
+		t.Errorf("options did not round-trip: %+v", got.Options)
+	}
+	if len(got.Entries) != 1 || entryID(got.Entries[0]) != entryID(journalPassed) {
+		t.Errorf("entries did not round-trip: %+v", got.Entries)
+	}
+}
+
+func TestRunPlan_RedactsSecretHelmSets(t *testing.T) {
+	dir := t.TempDir()
+	plan := RunPlan{Options: RunOptions{
+		ExtraHelmSets: []string{"orchestration.upgrade.allowPreReleaseImages=true", "identityPostgresql.auth.password=pg-s3cret"},
+		ExtraHelmArgs: []string{
+			"--set", "global.license.key=lic-s3cret,global.image.tag=8.9.0",
+			"--set-string=connectors.env.API_TOKEN=tok-s3cret",
+			"--set-file=global.license.secret.inlineSecret=/tmp/license.txt",
+		},
+	}}
+	if err := SaveRunPlan(dir, plan); err != nil {
+		t.Fatalf("SaveRunPlan: %v", err)
+	}
+	if plan.Options.ExtraHelmSets[1] != "identityPostgresql.auth.password=pg-s3cret" {
+		t.Errorf("SaveRunPlan modified the caller's options: %v", plan.Options.ExtraHelmSets)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, RunPlanFileName))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(raw), "s3cret") {
+		t.Fatalf("run plan leaks a --set secret:\n%s", raw)
+	}
+
+	got, err := LoadRunPlan(dir)
+	if err != nil {
+		t.Fatalf("LoadRunPlan: %v", err)
+	}
+	opts := got.Options
+	if opts.ExtraHelmSets[0] != "orchestration.upgrade.allowPreReleaseImages=true" || opts.ExtraHelmArgs[3] != "--set-file=global.license.secret.inlineSecret=/tmp/license.txt" {
+		t.Errorf("non-secret values were redacted: %v %v", opts.ExtraHelmSets, opts.ExtraHelmArgs)
+	}
+
+	err = RestoreRedactedHelmSets(&opts, []string{"identityPostgresql.auth.password=pg-s3cret"})
+	if err == nil || !strings.Contains(err.Error(), "global.license.key, connectors.env.API_TOKEN") {
+		t.Fatalf("RestoreRedactedHelmSets error = %v, want the still-redacted keys", err)
+	}
+
+	opts = got.Options
+	if err := RestoreRedactedHelmSets(&opts, []string{
+		"identityPostgresql.auth.password=pg-s3cret",
+		"global.license.key=lic-s3cret",
+		"connectors.env.API_TOKEN=tok-s3cret",
+	}); err != nil {
+		t.Fatalf("RestoreRedactedHelmSets: %v", err)
+	}
+	if !slices.Equal(opts.ExtraHelmSets, plan.Options.ExtraHelmSets) || !slices.Equal(opts.ExtraHelmArgs, plan.Options.ExtraHelmArgs) {
+		t.Errorf("restored options = %v %v, want %v %v", opts.ExtraHelmSets, opts.ExtraHelmArgs, plan.Options.ExtraHelmSets, plan.Options.ExtraHelmArgs)
+	}
+}
+
+func TestResolveRunLogDir_FollowsLatest(t *testing.T) {
+	parent := t.TempDir()
+	run := filepath.Join(parent, "20260504-101500")
+	if err := os.MkdirAll(run, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := SaveRunPlan(run, RunPlan{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(run, filepath.Join(parent, "latest")); err != nil {
+		t.Fatal(err)
+	}
+
+	want, _ := filepath.EvalSymlinks(run)
+	for _, dir := range []string{run, parent} {
+		got, err := ResolveRunLogDir(dir)
+		if err != nil || got != want {
+			t.Errorf("ResolveRunLogDir(%s) = %q, %v; want %q", dir, got, err, want)
+		}
+	}
+	if _, err := ResolveRunLogDir(t.TempDir()); err == nil {
+		t.Error("expected error for a directory without a run plan")
+	}
+}
+
+func TestResume_HalfFinishedNamespaces(t *testing.T) {
+	var deleted []string
+	orig := deleteNamespace
+	t.Cleanup(func() { deleteNamespace = orig })
+	deleteNamespace = func(_ context.Context, kubeContext, namespace string) error {
+		deleted = append(deleted, kubeContext+"/"+namespace)
+		return errors.New("forbidden")
+	}
+
+	rp := ResumePlan{
+		Pending:      []Entry{journalRunning},
+		HalfFinished: []EntryState{{Key: entryID(journalRunning), Namespace: "matrix-88-eske-upgp", KubeContext: "gke-ctx", StartedAt: time.Now()}},
+	}
+
+	_, err := Resume(context.Background(), rp, RunOptions{}, ResumeCleanup)
+	if err == nil || !strings.Contains(err.Error(), "forbidden") {
+		t.Fatalf("expected delete failure to stop the resume, got %v", err)
+	}
+	if strings.Join(deleted, ",") != "gke-ctx/matrix-88-eske-upgp" {
+		t.Fatalf("deleted = %v", deleted)
+	}
+
+	// A pre-provisioned namespace is never deleted, even in cleanup mode.
+	deleted = nil
+	results, err := Resume(context.Background(), rp, RunOptions{NamespaceOverride: "ci-ns", DryRun: true}, ResumeCleanup)
+	if err != nil {
+		t.Fatalf("Resume: %v", err)
+	}
+	if len(deleted) != 0 {
+		t.Fatalf("namespace override must not be deleted, deleted = %v", deleted)
+	}
+	if len(results) != 1 {
+		t.Fatalf("expected the pending entry to run, got %d results", len(results))
+	}
+}
+
+func TestResume_NothingPending(t *testing.T) {
+	done := []RunResult{{Entry: journalPassed}}
+	results, err := Resume(context.Background(), ResumePlan{Done: done}, RunOptions{}, ResumeCleanup)
+	if err != nil || len(results) != 1 {
+		t.Fatalf("Resume = %d results, %v; want the recorded result back", len(results), err)
+	}
+}
+
+func entryKeys(entries []Entry) string {
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		keys = append(keys, entryID(e))
+	}
+	return strings.Join(keys, ",")
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/jwalton/gchalk"
 
 	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/config"
 )
 
 // Entry represents a single matrix entry — one scenario + one flow + one platform combination.
@@ -321,16 +322,25 @@ func filterEntries(entries []Entry, opts FilterOptions) []Entry {
 		if opts.FlowFilter != "" && e.Flow != opts.FlowFilter {
 			continue
 		}
-		if opts.Platform != "" {
-			if e.Platform != "" && e.Platform != opts.Platform {
-				continue
-			}
+		if opts.Platform != "" && !platformMatches(e.Platform, opts.Platform) {
+			continue
 		}
 		filtered = append(filtered, e)
 	}
 	return filtered
 }
 
+// platformMatches reports whether an entry targeting entryPlatform runs on
+// the requested platform. Entries without a platform run anywhere. The local
+// (kind/k3d) platform has no entries of its own and runs the GKE ones, which
+// carry no cloud-specific networking.
+func platformMatches(entryPlatform, platform string) bool {
+	if entryPlatform == "" || entryPlatform == platform {
+		return true
+	}
+	return platform == config.PlatformLocal && entryPlatform == "gke"
+}
+
 // matchesAny reports whether s contains any of the given substrings.
 func matchesAny(s string, substrings []string) bool {
 	for _, sub := range substrings {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 	"time"
@@ -250,6 +251,19 @@ func TestFilter(t *testing.T) {
 		}
 	})
 
+	t.Run("platform filter local runs gke entries", func(t *testing.T) {
+		got := Filter(entries, FilterOptions{Platform: "local"})
+		// Local has no entries of its own: gke entries and unrestricted ones match, eks do not.
+		if len(got) != 4 {
+			t.Errorf("Filter(platform=local): got %d entries, want 4", len(got))
+		}
+		for _, e := range got {
+			if e.Platform == "eks" {
+				t.Errorf("Filter(platform=local) kept eks entry %+v", e)
+			}
+		}
+	})
+
 	t.Run("shortname filter single", func(t *testing.T) {
 		got := Filter(entries, FilterOptions{ShortnameFilter: "esoi"})
 		if len(got) != 1 || got[0].Shortname != "esoi" {
@@ -1000,6 +1014,89 @@ func TestBuildEntryFlagsPrefersExplicitGlobalHost(t *testing.T) {
 	}
 }
 
+func TestBuildEntryFlagsLocalPlatform(t *testing.T) {
+	t.Setenv("INFRA_INGRESS_HOSTNAME_BASE", "")
+	entry := Entry{
+		Version:   "8.9",
+		ChartPath: "charts/camunda-platform-8.9",
+		Scenario:  "elasticsearch-basic",
+		Shortname: "esba",
+		Flow:      "install",
+		Platform:  "gke",
+		InfraType: "standard",
+	}
+	opts := RunOptions{Platform: "local", UseVaultBackedSecrets: true, KubeContexts: map[string]string{"local": "kind-camunda-local"}}
+
+	flags, namespace, kubeCtx, _, cleanup, err := BuildEntryFlags(entry, opts)
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("BuildEntryFlags returned error: %v", err)
+	}
+	if flags.Deployment.Platform != "local" || kubeCtx != "kind-camunda-local" {
+		t.Errorf("platform=%q kubeContext=%q", flags.Deployment.Platform, kubeCtx)
+	}
+	if got, want := flags.ResolveIngressHostname(), namespace+".127.0.0.1.nip.io"; got != want {
+		t.Errorf("ResolveIngressHostname() = %q, want %q", got, want)
+	}
+	if flags.Secrets.UseVaultBackedSecrets || !flags.Secrets.AutoGenerateSecrets {
+		t.Errorf("local secrets = %+v, want generated secrets without vault", flags.Secrets)
+	}
+	if flags.Selection.InfraType != "" {
+		t.Errorf("InfraType = %q, want empty on the local platform", flags.Selection.InfraType)
+	}
+}
+
+func TestBuildEntryFlagsSmoke(t *testing.T) {
+	t.Setenv("INFRA_INGRESS_HOSTNAME_BASE", "")
+	for version, want := range map[string]bool{"8.7": false, "8.8": true, "8.10": true} {
+		entry := Entry{
+			Version:   version,
+			ChartPath: "charts/camunda-platform-" + version,
+			Scenario:  "elasticsearch-basic",
+			Shortname: "esba",
+			Flow:      "install",
+		}
+		flags, _, _, _, cleanup, err := BuildEntryFlags(entry, RunOptions{Smoke: true})
+		cleanup()
+		if err != nil {
+			t.Fatalf("BuildEntryFlags(%s) returned error: %v", version, err)
+		}
+		if flags.Test.RunSmoke != want {
+			t.Errorf("BuildEntryFlags(%s).Test.RunSmoke = %v, want %v", version, flags.Test.RunSmoke, want)
+		}
+	}
+}
+
+func TestBuildEntryFlagsCarriesSecretSources(t *testing.T) {
+	entry := Entry{
+		Version:   "8.9",
+		ChartPath: "charts/camunda-platform-8.9",
+		Scenario:  "elasticsearch-basic",
+		Shortname: "esba",
+		Flow:      "install",
+		Platform:  "gke",
+	}
+	sources := []config.SecretSourceConfig{{Type: config.SecretSourceEnvFile, File: "/secrets/ci.env"}}
+	opts := RunOptions{
+		SecretSources:       sources,
+		SecretSourceEnv:     map[string]string{"DISTRO_QA_E2E_TESTS_KEYCLOAK_PASSWORD": "from-store"},
+		SecretSourceOrigins: map[string]string{"DISTRO_QA_E2E_TESTS_KEYCLOAK_PASSWORD": "envFile:/secrets/ci.env"},
+	}
+
+	flags, _, _, _, cleanup, err := BuildEntryFlags(entry, opts)
+	defer cleanup()
+	if err != nil {
+		t.Fatalf("BuildEntryFlags returned error: %v", err)
+	}
+	if !reflect.DeepEqual(flags.Secrets.Sources, sources) {
+		t.Errorf("Secrets.Sources = %+v, want %+v", flags.Secrets.Sources, sources)
+	}
+	if flags.Secrets.SourceEnv["DISTRO_QA_E2E_TESTS_KEYCLOAK_PASSWORD"] != "from-store" ||
+		flags.Secrets.SourceOrigins["DISTRO_QA_E2E_TESTS_KEYCLOAK_PASSWORD"] != "envFile:/secrets/ci.env" {
+		t.Errorf("Secrets = %+v, want the values Run loaded", flags.Secrets)
+	}
+}
+
 func TestDryRunPrefersExplicitGlobalHost(t *testing.T) {
 	opts := RunOptions{
 		IngressBaseDomain: "ci.distro.ultrawombat.com",
@@ -1326,6 +1423,12 @@ func TestResolveUseVaultBackedSecrets(t *testing.T) {
 			platform: "eks",
 			want:     true,
 		},
+		{
+			name:     "local never uses vault",
+			opts:     RunOptions{VaultBackedSecrets: map[string]bool{"local": true}, UseVaultBackedSecrets: true},
+			platform: "local",
+			want:     false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -1389,6 +1492,18 @@ func TestResolveIngressBaseDomain(t *testing.T) {
 			platform: "gke",
 			want:     "fallback.example.com",
 		},
+		{
+			name:     "local defaults to nip.io",
+			opts:     RunOptions{},
+			platform: "local",
+			want:     "127.0.0.1.nip.io",
+		},
+		{
+			name:     "local honours an explicit domain",
+			opts:     RunOptions{IngressBaseDomain: "fallback.example.com"},
+			platform: "local",
+			want:     "fallback.example.com",
+		},
 	}
 
 	for _, tt := range tests {
@@ -1560,6 +1675,17 @@ func TestSynthesizeRunError(t *testing.T) {
 			wantErr:      true,
 			wantContains: "2 of 2 matrix entries failed",
 		},
+		{
+			name: "failures broken down by classified cause",
+			results: []RunResult{
+				{Namespace: "ns-1", Error: errors.New("helm upgrade failed: Error: UPGRADE FAILED: context deadline exceeded")},
+				{Namespace: "ns-2", Error: errors.New("apply secret: read tcp: connection reset by peer")},
+				{Namespace: "ns-3", Error: errors.New("deploy failed")},
+			},
+			totalEntries: 3,
+			wantErr:      true,
+			wantContains: "3 of 3 matrix entries failed (1 infra-transient, 1 readiness)",
+		},
 		{
 			name:         "context cancelled before any entry dispatched",
 			results:      nil,
@@ -2182,6 +2308,25 @@ func TestExtraValues_UpgradeStep1Cleared(t *testing.T) {
 	}
 }
 
+func TestUpgradeStep1FlagsDisablesTestsAndSmoke(t *testing.T) {
+	flags := &config.RuntimeFlags{}
+	flags.Chart.ChartPath = "/repo/charts/camunda-platform-8.9"
+	flags.Test.RunE2ETests = true
+	flags.Test.RunAllTests = true
+	flags.Test.RunSmoke = true
+
+	step1 := upgradeStep1Flags(flags, "13.4.0")
+	if step1.Test.RunE2ETests || step1.Test.RunAllTests || step1.Test.RunSmoke {
+		t.Errorf("Step 1 tests = %+v, want e2e, all and smoke disabled", step1.Test)
+	}
+	if !flags.Test.RunSmoke {
+		t.Error("Step 1 must not disable smoke on the Step 2 flags")
+	}
+	if step1.Chart.ChartPath != "" || step1.Chart.ChartVersion != "13.4.0" {
+		t.Errorf("Step 1 chart = %+v, want the repo chart at 13.4.0", step1.Chart)
+	}
+}
+
 // TestAppendScenarioExtraValues pins the #6312 per-scenario precedence: a
 // scenario's declared extra-values are appended AFTER any global --extra-values
 // (so the per-scenario file wins within the chain's `extra` slot), and relative
@@ -42,6 +42,10 @@ type PlanOptions struct {
 	// ManualFlow overrides the flows as a comma-separated list ("none"/""
 	// keeps the registry flows).
 	ManualFlow string
+	// ManualShortnames keeps only the scenarios whose shortname is in this
+	// comma-separated list ("none"/"" keep everything). The
+	// integration-tests-gate uses it to re-run just the failed cells.
+	ManualShortnames string
 	// Tier filters scenarios by tier (0 = all).
 	Tier int
 }
@@ -166,6 +170,10 @@ func Plan(repoRoot string, opts PlanOptions) (PlanResult, error) {
 
 	manualScenario := opts.ManualScenario
 	includeDisabled := manualScenario != "" && manualScenario != "none" && manualScenario != "all"
+	shortnames := opts.ManualShortnames
+	if shortnames == "none" {
+		shortnames = ""
+	}
 
 	var result PlanResult
 	for _, version := range versions {
@@ -176,7 +184,11 @@ func Plan(repoRoot string, opts PlanOptions) (PlanResult, error) {
 		if err != nil {
 			return PlanResult{}, err
 		}
-		entries = Filter(entries, FilterOptions{Tier: opts.Tier})
+		entries = Filter(entries, FilterOptions{
+			Tier:            opts.Tier,
+			ShortnameFilter: shortnames,
+			ShortnameExact:  true,
+		})
 
 		if includeDisabled {
 			var kept []Entry
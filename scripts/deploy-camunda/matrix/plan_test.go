@@ -172,6 +172,42 @@ func TestPlanManualScenarioIncludesDisabled(t *testing.T) {
 	}
 }
 
+func TestPlanManualShortnamesExact(t *testing.T) {
+	all, err := Plan(findRepoRoot(t), PlanOptions{ActiveVersions: planActiveVersions, ManualTrigger: "8.9"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all.Include) == 0 {
+		t.Fatal("expected 8.9 entries")
+	}
+	want := all.Include[0].Shortname
+
+	result, err := Plan(findRepoRoot(t), PlanOptions{
+		ActiveVersions:   planActiveVersions,
+		ManualTrigger:    "8.9",
+		ManualShortnames: want + ",no-such-shortname",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Include) == 0 {
+		t.Fatalf("expected %s entries", want)
+	}
+	for _, entry := range result.Include {
+		if entry.Shortname != want {
+			t.Errorf("shortname = %q, want %q", entry.Shortname, want)
+		}
+	}
+
+	none, err := Plan(findRepoRoot(t), PlanOptions{ActiveVersions: planActiveVersions, ManualTrigger: "8.9", ManualShortnames: "none"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(none.Include) != len(all.Include) {
+		t.Errorf("\"none\" kept %d entries, want all %d", len(none.Include), len(all.Include))
+	}
+}
+
 func TestPlanManualTriggerUnknownVersionFails(t *testing.T) {
 	_, err := Plan(findRepoRoot(t), PlanOptions{ActiveVersions: planActiveVersions, ManualTrigger: "9.99"})
 	if err == nil || !strings.Contains(err.Error(), "does not exist") {
@@ -0,0 +1,520 @@
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"scripts/camunda-core/pkg/failures"
+	"scripts/deploy-camunda/deploy"
+	"scripts/deploy-camunda/pkg/deployer"
+	"scripts/deploy-camunda/pkg/junit"
+)
+
+// ReportFormat is one machine-readable rendering of a RunReport.
+type ReportFormat string
+
+const (
+	ReportJSON     ReportFormat = "json"
+	ReportJUnit    ReportFormat = "junit"
+	ReportMarkdown ReportFormat = "markdown"
+)
+
+// ReportFormats lists the supported --report formats.
+var ReportFormats = []ReportFormat{ReportJSON, ReportJUnit, ReportMarkdown}
+
+// FileName returns the file a format is written to inside the report dir.
+func (f ReportFormat) FileName() string {
+	switch f {
+	case ReportJUnit:
+		return "matrix-report.xml"
+	case ReportMarkdown:
+		return "matrix-report.md"
+	default:
+		return "matrix-report.json"
+	}
+}
+
+// ParseReportFormats parses a comma-separated --report value. Duplicates are
+// dropped; an empty value yields no formats.
+func ParseReportFormats(s string) ([]ReportFormat, error) {
+	var formats []ReportFormat
+	seen := map[ReportFormat]bool{}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(strings.ToLower(part))
+		if part == "" {
+			continue
+		}
+		f := ReportFormat(part)
+		known := false
+		for _, k := range ReportFormats {
+			known = known || k == f
+		}
+		if !known {
+			return nil, fmt.Errorf("unsupported report format %q; supported: %v", part, ReportFormats)
+		}
+		if !seen[f] {
+			seen[f] = true
+			formats = append(formats, f)
+		}
+	}
+	return formats, nil
+}
+
+// PhaseTiming is how long an entry spent in one phase.
+type PhaseTiming struct {
+	Name            string    `json:"name"`
+	StartedAt       time.Time `json:"startedAt"`
+	DurationSeconds float64   `json:"durationSeconds"`
+}
+
+// PhaseTimer measures per-entry phase durations from the RunOptions phase
+// callbacks. It is safe for concurrent use by parallel entries.
+type PhaseTimer struct {
+	mu     sync.Mutex
+	now    func() time.Time
+	phases map[string][]PhaseTiming
+	open   map[string]bool
+}
+
+// NewPhaseTimer returns an empty PhaseTimer.
+func NewPhaseTimer() *PhaseTimer {
+	return &PhaseTimer{now: time.Now, phases: map[string][]PhaseTiming{}, open: map[string]bool{}}
+}
+
+// Attach returns a copy of opts whose OnPhaseChange and OnEntryComplete
+// callbacks also feed the timer. Existing callbacks still run.
+func (t *PhaseTimer) Attach(opts RunOptions) RunOptions {
+	onPhase, onComplete := opts.OnPhaseChange, opts.OnEntryComplete
+	opts.OnPhaseChange = func(entry Entry, phase string) {
+		t.enter(entryID(entry), phase)
+		if onPhase != nil {
+			onPhase(entry, phase)
+		}
+	}
+	opts.OnEntryComplete = func(entry Entry, result RunResult) {
+		t.enter(entryID(entry), "")
+		if onComplete != nil {
+			onComplete(entry, result)
+		}
+	}
+	return opts
+}
+
+// enter closes the key's open phase and, unless phase is empty, opens the
+// next one.
+func (t *PhaseTimer) enter(key, phase string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	if t.open[key] {
+		list := t.phases[key]
+		last := &list[len(list)-1]
+		last.DurationSeconds = now.Sub(last.StartedAt).Seconds()
+		t.open[key] = false
+	}
+	if phase == "" {
+		return
+	}
+	t.phases[key] = append(t.phases[key], PhaseTiming{Name: phase, StartedAt: now.UTC()})
+	t.open[key] = true
+}
+
+// Timings returns the recorded phases for entry, in order.
+func (t *PhaseTimer) Timings(entry Entry) []PhaseTiming {
+	if t == nil {
+		return nil
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]PhaseTiming(nil), t.phases[entryID(entry)]...)
+}
+
+// OutcomeNotStarted marks a report entry that produced no result at all:
+// the run stopped before dispatching it. The other report statuses reuse
+// the journal outcomes.
+const OutcomeNotStarted = "not-started"
+
+// Failure classes for failed entries.
+const (
+	FailureHelm      = "helm"
+	FailureTest      = "test"
+	FailureCancelled = "cancelled"
+	FailureError     = "error"
+)
+
+// RunReport is the machine-readable result of a matrix run: one record per
+// entry the run was asked to execute.
+type RunReport struct {
+	GeneratedAt      time.Time     `json:"generatedAt"`
+	WallClockSeconds float64       `json:"wallClockSeconds"`
+	Outcome          string        `json:"outcome"`
+	Error            string        `json:"error,omitempty"`
+	LogDir           string        `json:"logDir,omitempty"`
+	Summary          ReportSummary `json:"summary"`
+	Entries          []EntryReport `json:"entries"`
+}
+
+// ReportSummary counts entries per status.
+type ReportSummary struct {
+	Total      int `json:"total"`
+	Passed     int `json:"passed"`
+	Failed     int `json:"failed"`
+	Skipped    int `json:"skipped"`
+	NotStarted int `json:"notStarted"`
+}
+
+// EntryReport is one matrix cell in a RunReport. Cause, CauseCategory,
+// Retryable and Remediation come from the failure-signature catalogue and are
+// empty when no signature matched the failure.
+type EntryReport struct {
+	Key             string        `json:"key"`
+	Version         string        `json:"version"`
+	Scenario        string        `json:"scenario"`
+	Shortname       string        `json:"shortname"`
+	Flow            string        `json:"flow"`
+	Platform        string        `json:"platform,omitempty"`
+	Namespace       string        `json:"namespace,omitempty"`
+	KubeContext     string        `json:"kubeContext,omitempty"`
+	Status          string        `json:"status"`
+	Failure         string        `json:"failure,omitempty"`
+	Error           string        `json:"error,omitempty"`
+	HelmCommand     string        `json:"helmCommand,omitempty"`
+	FailedHop       string        `json:"failedHop,omitempty"`
+	Cause           string        `json:"cause,omitempty"`
+	CauseCategory   string        `json:"causeCategory,omitempty"`
+	Retryable       bool          `json:"retryable,omitempty"`
+	Remediation     string        `json:"remediation,omitempty"`
+	DurationSeconds float64       `json:"durationSeconds"`
+	Phases          []PhaseTiming `json:"phases,omitempty"`
+	Diagnostics     string        `json:"diagnostics,omitempty"`
+	DeployLog       string        `json:"deployLog,omitempty"`
+	E2ELog          string        `json:"e2eLog,omitempty"`
+}
+
+// BuildRunReport assembles the report for a finished run. entries is the
+// full list the run was given, so entries that never produced a result show
+// up as not-started; runErr is what Run returned. timer may be nil.
+func BuildRunReport(ctx context.Context, entries []Entry, results []RunResult, runErr error, wallClock time.Duration, logDir string, timer *PhaseTimer) RunReport {
+	report := RunReport{
+		GeneratedAt:      time.Now().UTC(),
+		WallClockSeconds: wallClock.Seconds(),
+		Outcome:          classifyRun(ctx, results, len(entries), runErr),
+		LogDir:           logDir,
+	}
+	if runErr != nil {
+		report.Error = runErr.Error()
+	}
+
+	byKey := make(map[string]RunResult, len(results))
+	for _, r := range results {
+		byKey[entryID(r.Entry)] = r
+	}
+	for _, e := range entries {
+		er := EntryReport{
+			Key:       entryID(e),
+			Version:   e.Version,
+			Scenario:  e.Scenario,
+			Shortname: e.Shortname,
+			Flow:      e.Flow,
+			Platform:  e.Platform,
+			Phases:    timer.Timings(e),
+		}
+		r, ok := byKey[er.Key]
+		switch {
+		case !ok:
+			er.Status = OutcomeNotStarted
+		case isSkippedResult(r):
+			er.Status = OutcomeSkipped
+		case r.Error != nil:
+			er.Status = OutcomeFailed
+			er.Failure = classifyFailure(r.Error)
+			er.Error = r.Error.Error()
+			var helmErr *deployer.HelmError
+			if errors.As(r.Error, &helmErr) {
+				er.HelmCommand = helmErr.ShortCommand()
+			}
+			var hopErr *UpgradeHopError
+			if errors.As(r.Error, &hopErr) {
+				er.FailedHop = hopErr.FailedHop()
+			}
+			deployLog := ""
+			if logDir != "" {
+				deployLog = filepath.Join(logDir, entryLogFileName(e)+".deploy.log")
+			}
+			if m, ok := classifyCause(r.Error, deployLog); ok {
+				er.Cause = m.ID
+				er.CauseCategory = string(m.Category)
+				er.Retryable = m.Retryable
+				er.Remediation = m.Remediation
+			}
+		default:
+			er.Status = OutcomePassed
+		}
+		if ok {
+			er.Namespace = r.Namespace
+			er.KubeContext = r.KubeContext
+			er.DurationSeconds = r.Duration.Seconds()
+			er.Diagnostics = r.Diagnostics
+			if logDir != "" && er.Status != OutcomeSkipped {
+				baseName := entryLogFileName(e)
+				er.DeployLog = filepath.Join(logDir, baseName+".deploy.log")
+				er.E2ELog = filepath.Join(logDir, baseName+".e2e.log")
+			}
+		}
+
+		switch er.Status {
+		case OutcomePassed:
+			report.Summary.Passed++
+		case OutcomeFailed:
+			report.Summary.Failed++
+		case OutcomeSkipped:
+			report.Summary.Skipped++
+		default:
+			report.Summary.NotStarted++
+		}
+		report.Entries = append(report.Entries, er)
+	}
+	report.Summary.Total = len(report.Entries)
+	return report
+}
+
+// classifyFailure buckets a failed entry's error by where it came from.
+func classifyFailure(err error) string {
+	var helmErr *deployer.HelmError
+	var testErr *deploy.TestError
+	switch {
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return FailureCancelled
+	case errors.As(err, &testErr):
+		return FailureTest
+	case errors.As(err, &helmErr):
+		return FailureHelm
+	default:
+		return FailureError
+	}
+}
+
+// causeLogTail bounds how much of a deploy log classifyCause reads.
+const causeLogTail = 256 << 10
+
+// classifyCause matches a failed entry against the failure-signature
+// catalogue: the error text first, then the tail of the entry's deploy log
+// (which carries the helm stderr the error only summarises). logPath may be
+// empty.
+func classifyCause(err error, logPath string) (failures.Match, bool) {
+	if m, ok := failures.Classify(failures.SourceLog, err.Error()); ok {
+		return m, true
+	}
+	if logPath == "" {
+		return failures.Match{}, false
+	}
+	f, openErr := os.Open(logPath)
+	if openErr != nil {
+		return failures.Match{}, false
+	}
+	defer f.Close()
+	if info, statErr := f.Stat(); statErr == nil && info.Size() > causeLogTail {
+		_, _ = f.Seek(-causeLogTail, io.SeekEnd)
+	}
+	data, readErr := io.ReadAll(f)
+	if readErr != nil {
+		return failures.Match{}, false
+	}
+	return failures.Classify(failures.SourceLog, string(data))
+}
+
+// WriteReports renders report in each format into dir and returns the
+// written paths.
+func WriteReports(dir string, report RunReport, formats []ReportFormat) ([]string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create report dir: %w", err)
+	}
+	var paths []string
+	for _, f := range formats {
+		data, err := report.Render(f)
+		if err != nil {
+			return paths, err
+		}
+		path := filepath.Join(dir, f.FileName())
+		if err := os.WriteFile(path, data, 0o644); err != nil {
+			return paths, fmt.Errorf("write %s report: %w", f, err)
+		}
+		paths = append(paths, path)
+	}
+	return paths, nil
+}
+
+// Render returns the report in format f.
+func (r RunReport) Render(f ReportFormat) ([]byte, error) {
+	switch f {
+	case ReportJSON:
+		data, err := json.MarshalIndent(r, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("encode json report: %w", err)
+		}
+		return append(data, '\n'), nil
+	case ReportJUnit:
+		return r.junit()
+	case ReportMarkdown:
+		return []byte(r.Markdown()), nil
+	default:
+		return nil, fmt.Errorf("unsupported report format %q; supported: %v", f, ReportFormats)
+	}
+}
+
+// junit renders one testsuite per chart version and one testcase per matrix
+// cell, named <shortname>/<flow>[/<platform>] so test reporters list cells
+// the same way the status table does.
+func (r RunReport) junit() ([]byte, error) {
+	root := junit.Suites{Name: "matrix run", Time: junit.Seconds(r.WallClockSeconds)}
+	suiteIndex := map[string]int{}
+	for _, e := range r.Entries {
+		idx, ok := suiteIndex[e.Version]
+		if !ok {
+			idx = len(root.Suites)
+			suiteIndex[e.Version] = idx
+			root.Suites = append(root.Suites, junit.Suite{Name: "matrix/" + e.Version})
+		}
+		suite := &root.Suites[idx]
+
+		name := e.Shortname + "/" + e.Flow
+		if e.Platform != "" {
+			name += "/" + e.Platform
+		}
+		tc := junit.Case{
+			Name:      name,
+			Classname: "matrix." + e.Version + "." + e.Scenario,
+			Time:      junit.Seconds(e.DurationSeconds),
+			SystemOut: e.systemOut(),
+		}
+		switch e.Status {
+		case OutcomeFailed:
+			tc.Failure = &junit.Failure{Message: firstLine(e.Error), Type: e.Failure, Body: e.Error}
+			suite.Failures++
+		case OutcomeSkipped, OutcomeNotStarted:
+			tc.Skipped = &junit.Skipped{Message: e.Status}
+			suite.Skipped++
+		}
+		suite.Tests++
+		root.Tests++
+		suite.Cases = append(suite.Cases, tc)
+	}
+	for i := range root.Suites {
+		var total float64
+		for _, e := range r.Entries {
+			if "matrix/"+e.Version == root.Suites[i].Name {
+				total += e.DurationSeconds
+			}
+		}
+		root.Suites[i].Time = junit.Seconds(total)
+		root.Failures += root.Suites[i].Failures
+		root.Skipped += root.Suites[i].Skipped
+	}
+
+	return junit.Marshal(root)
+}
+
+// systemOut lists the namespace, phase timings and where to look next.
+func (e EntryReport) systemOut() string {
+	var b strings.Builder
+	if e.Namespace != "" {
+		fmt.Fprintf(&b, "namespace: %s\n", e.Namespace)
+	}
+	for _, p := range e.Phases {
+		fmt.Fprintf(&b, "phase %s: %.1fs\n", p.Name, p.DurationSeconds)
+	}
+	if e.FailedHop != "" {
+		fmt.Fprintf(&b, "failed hop: %s\n", e.FailedHop)
+	}
+	if e.HelmCommand != "" {
+		fmt.Fprintf(&b, "helm command: %s\n", e.HelmCommand)
+	}
+	if e.Cause != "" {
+		fmt.Fprintf(&b, "cause: %s (%s)\n", e.Cause, e.CauseCategory)
+	}
+	if e.Diagnostics != "" {
+		fmt.Fprintf(&b, "diagnostics: %s\n", e.Diagnostics)
+	}
+	if e.DeployLog != "" {
+		fmt.Fprintf(&b, "deploy log: %s\n", e.DeployLog)
+	}
+	return b.String()
+}
+
+// Markdown renders the report as a GitHub-flavoured summary table, suitable
+// for $GITHUB_STEP_SUMMARY.
+func (r RunReport) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "## Matrix run: %s\n\n", r.Outcome)
+	fmt.Fprintf(&b, "%d entries: %d passed, %d failed, %d skipped, %d not started. Wall clock %s.\n\n",
+		r.Summary.Total, r.Summary.Passed, r.Summary.Failed, r.Summary.Skipped, r.Summary.NotStarted,
+		formatDuration(time.Duration(r.WallClockSeconds*float64(time.Second))))
+
+	b.WriteString("| Status | Version | Shortname | Flow | Platform | Namespace | Duration | Phases |\n")
+	b.WriteString("| --- | --- | --- | --- | --- | --- | --- | --- |\n")
+	for _, e := range r.Entries {
+		var phases []string
+		for _, p := range e.Phases {
+			phases = append(phases, fmt.Sprintf("%s %s", p.Name, formatDuration(time.Duration(p.DurationSeconds*float64(time.Second)))))
+		}
+		fmt.Fprintf(&b, "| %s | %s | %s | %s | %s | %s | %s | %s |\n",
+			markdownStatus(e.Status), e.Version, mdCell(e.Shortname), mdCell(e.Flow), mdCell(e.Platform),
+			mdCell(e.Namespace), formatDuration(time.Duration(e.DurationSeconds*float64(time.Second))),
+			mdCell(strings.Join(phases, ", ")))
+	}
+
+	var failed []EntryReport
+	for _, e := range r.Entries {
+		if e.Status == OutcomeFailed {
+			failed = append(failed, e)
+		}
+	}
+	if len(failed) > 0 {
+		b.WriteString("\n### Failures\n")
+		for _, e := range failed {
+			fmt.Fprintf(&b, "\n**%s** (%s)\n\n", e.Key, e.Failure)
+			if e.FailedHop != "" {
+				fmt.Fprintf(&b, "Failed hop: %s\n\n", e.FailedHop)
+			}
+			if e.Cause != "" {
+				fmt.Fprintf(&b, "Cause: `%s` (%s) — %s\n\n", e.Cause, e.CauseCategory, e.Remediation)
+			}
+			fmt.Fprintf(&b, "```\n%s\n```\n", e.Error)
+			if e.Diagnostics != "" {
+				fmt.Fprintf(&b, "\nDiagnostics: `%s`\n", e.Diagnostics)
+			}
+		}
+	}
+	return b.String()
+}
+
+func markdownStatus(status string) string {
+	switch status {
+	case OutcomePassed:
+		return "✅ passed"
+	case OutcomeFailed:
+		return "❌ failed"
+	default:
+		return "⏭️ " + status
+	}
+}
+
+// mdCell escapes table separators so a value cannot break the row.
+func mdCell(s string) string {
+	return strings.ReplaceAll(s, "|", `\|`)
+}
+
+func firstLine(s string) string {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,219 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"context"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"scripts/deploy-camunda/deploy"
+	"scripts/deploy-camunda/pkg/deployer"
+	"scripts/deploy-camunda/pkg/junit"
+)
+
+func TestParseReportFormats(t *testing.T) {
+	got, err := ParseReportFormats(" json, JUnit,markdown,json ")
+	if err != nil {
+		t.Fatalf("ParseReportFormats: %v", err)
+	}
+	if fmt.Sprint(got) != "[json junit markdown]" {
+		t.Fatalf("formats = %v", got)
+	}
+	if got, err := ParseReportFormats(""); err != nil || len(got) != 0 {
+		t.Fatalf("empty value = %v, %v; want no formats", got, err)
+	}
+	if _, err := ParseReportFormats("json,html"); err == nil {
+		t.Fatal("expected error for unknown format")
+	}
+}
+
+func TestPhaseTimer(t *testing.T) {
+	clock := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	timer := NewPhaseTimer()
+	timer.now = func() time.Time { return clock }
+	var forwarded []string
+	opts := timer.Attach(RunOptions{OnPhaseChange: func(_ Entry, phase string) { forwarded = append(forwarded, phase) }})
+
+	opts.OnPhaseChange(journalPassed, "preparing")
+	clock = clock.Add(5 * time.Second)
+	opts.OnPhaseChange(journalPassed, "deploying")
+	clock = clock.Add(2 * time.Minute)
+	opts.OnPhaseChange(journalPassed, "testing")
+	clock = clock.Add(30 * time.Second)
+	opts.OnEntryComplete(journalPassed, RunResult{})
+
+	got := timer.Timings(journalPassed)
+	want := []struct {
+		name string
+		secs float64
+	}{{"preparing", 5}, {"deploying", 120}, {"testing", 30}}
+	if len(got) != len(want) {
+		t.Fatalf("timings = %+v", got)
+	}
+	for i, w := range want {
+		if got[i].Name != w.name || got[i].DurationSeconds != w.secs {
+			t.Errorf("phase %d = %s/%.0fs, want %s/%.0fs", i, got[i].Name, got[i].DurationSeconds, w.name, w.secs)
+		}
+	}
+	if strings.Join(forwarded, ",") != "preparing,deploying,testing" {
+		t.Errorf("existing OnPhaseChange not called: %v", forwarded)
+	}
+	if (*PhaseTimer)(nil).Timings(journalPassed) != nil {
+		t.Error("nil timer must report no timings")
+	}
+}
+
+// reportFixture is a four-cell run: one pass, one helm failure, one test
+// failure and one entry the run never reached.
+func reportFixture(t *testing.T) RunReport {
+	t.Helper()
+	helmErr := &deployer.HelmError{Reason: "helm upgrade --install failed", Command: "helm upgrade --install integration /charts/camunda", Cause: errors.New("timed out waiting for the condition")}
+	results := []RunResult{
+		{Entry: journalPassed, Namespace: "matrix-89-eske-inst", KubeContext: "gke-ctx", Duration: 3 * time.Minute},
+		{Entry: journalFailed, Namespace: "matrix-89-osba-inst", Duration: 10 * time.Minute, Error: fmt.Errorf("step 2: %w", helmErr), Diagnostics: "/tmp/diag/osba"},
+		{Entry: journalRunning, Namespace: "matrix-88-eske-upgp", Duration: 4 * time.Minute, Error: &deploy.TestError{Err: errors.New("e2e tests failed"), Output: "1 failed"}},
+	}
+	entries := []Entry{journalPassed, journalFailed, journalRunning, journalQueued}
+	return BuildRunReport(context.Background(), entries, results, synthesizeRunError(context.Background(), results, len(entries)), 17*time.Minute, "/tmp/logs", nil)
+}
+
+func TestBuildRunReport(t *testing.T) {
+	report := reportFixture(t)
+
+	if report.Outcome != RunEntriesFailed {
+		t.Errorf("outcome = %q, want %q", report.Outcome, RunEntriesFailed)
+	}
+	if report.Summary != (ReportSummary{Total: 4, Passed: 1, Failed: 2, NotStarted: 1}) {
+		t.Errorf("summary = %+v", report.Summary)
+	}
+	byKey := map[string]EntryReport{}
+	for _, e := range report.Entries {
+		byKey[e.Key] = e
+	}
+
+	helm := byKey[entryID(journalFailed)]
+	if helm.Status != OutcomeFailed || helm.Failure != FailureHelm || helm.Diagnostics != "/tmp/diag/osba" {
+		t.Errorf("helm failure entry = %+v", helm)
+	}
+	if !strings.HasPrefix(helm.HelmCommand, "helm upgrade --install integration") {
+		t.Errorf("helm command = %q", helm.HelmCommand)
+	}
+	if helm.DeployLog != filepath.Join("/tmp/logs", "8.9-osba-install-gke.deploy.log") {
+		t.Errorf("deploy log = %q", helm.DeployLog)
+	}
+	if helm.Cause != "rollout-timeout" || helm.CauseCategory != "readiness" || !helm.Retryable || helm.Remediation == "" {
+		t.Errorf("helm failure cause = %q/%q retryable=%v", helm.Cause, helm.CauseCategory, helm.Retryable)
+	}
+	if got := byKey[entryID(journalRunning)].Failure; got != FailureTest {
+		t.Errorf("test failure classified as %q", got)
+	}
+	if got := byKey[entryID(journalRunning)].Cause; got != "" {
+		t.Errorf("unrecognised test failure got cause %q", got)
+	}
+	if got := byKey[entryID(journalQueued)]; got.Status != OutcomeNotStarted || got.DeployLog != "" {
+		t.Errorf("never-dispatched entry = %+v", got)
+	}
+}
+
+func TestClassifyRun(t *testing.T) {
+	failed := []RunResult{{Entry: journalPassed, Error: errors.New("boom")}}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	cases := []struct {
+		name    string
+		ctx     context.Context
+		results []RunResult
+		total   int
+		runErr  error
+		want    string
+	}{
+		{name: "all passed", ctx: context.Background(), results: []RunResult{{Entry: journalPassed}}, total: 1, want: RunPassed},
+		{name: "entries failed", ctx: context.Background(), results: failed, total: 1, want: RunEntriesFailed},
+		{name: "cancelled before dispatch", ctx: cancelled, results: nil, total: 2, want: RunCancelled},
+		{name: "stop on failure", ctx: context.Background(), results: failed, total: 3, runErr: fmt.Errorf("%w: %w", ErrStoppedOnFailure, failed[0].Error), want: RunStoppedOnFailure},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := classifyRun(tc.ctx, tc.results, tc.total, tc.runErr); got != tc.want {
+				t.Fatalf("classifyRun = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestRunReport_JUnit(t *testing.T) {
+	data, err := reportFixture(t).Render(ReportJUnit)
+	if err != nil {
+		t.Fatalf("Render: %v", err)
+	}
+	var suites junit.Suites
+	if err := xml.Unmarshal(data, &suites); err != nil {
+		t.Fatalf("junit output is not valid XML: %v\n%s", err, data)
+	}
+	if suites.Tests != 4 || suites.Failures != 2 || suites.Skipped != 1 {
+		t.Errorf("totals tests=%d failures=%d skipped=%d", suites.Tests, suites.Failures, suites.Skipped)
+	}
+	if len(suites.Suites) != 2 || suites.Suites[0].Name != "matrix/8.9" {
+		t.Fatalf("expected one suite per version, got %+v", suites.Suites)
+	}
+	tc := suites.Suites[0].Cases[1]
+	if tc.Name != "osba/install/gke" || tc.Failure == nil || tc.Failure.Type != FailureHelm {
+		t.Errorf("failed testcase = %+v", tc)
+	}
+	if !strings.Contains(tc.SystemOut, "diagnostics: /tmp/diag/osba") {
+		t.Errorf("system-out missing diagnostics: %q", tc.SystemOut)
+	}
+	if tc.Time != "600.000" {
+		t.Errorf("testcase time = %s, want 600.000", tc.Time)
+	}
+}
+
+func TestRunReport_MarkdownAndJSON(t *testing.T) {
+	report := reportFixture(t)
+	md := report.Markdown()
+	for _, want := range []string{"## Matrix run: entries-failed", "| ❌ failed | 8.9 | osba | install | gke |", "### Failures", "Diagnostics: `/tmp/diag/osba`", "Cause: `rollout-timeout` (readiness)"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+
+	dir := t.TempDir()
+	paths, err := WriteReports(dir, report, ReportFormats)
+	if err != nil {
+		t.Fatalf("WriteReports: %v", err)
+	}
+	if len(paths) != 3 {
+		t.Fatalf("paths = %v", paths)
+	}
+	raw, err := os.ReadFile(filepath.Join(dir, ReportJSON.FileName()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded RunReport
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		t.Fatalf("json report does not decode: %v", err)
+	}
+	if len(decoded.Entries) != 4 || decoded.Entries[1].Failure != FailureHelm {
+		t.Errorf("decoded entries = %+v", decoded.Entries)
+	}
+}
@@ -18,6 +18,7 @@ import (
 	"github.com/jwalton/gchalk"
 
 	"scripts/camunda-core/pkg/docker"
+	"scripts/camunda-core/pkg/failures"
 	"scripts/camunda-core/pkg/kube"
 	"scripts/camunda-core/pkg/logging"
 	"scripts/camunda-core/pkg/scenarios"
@@ -27,6 +28,7 @@ import (
 	"scripts/deploy-camunda/deploy"
 	"scripts/deploy-camunda/entra"
 	"scripts/deploy-camunda/pkg/deployer"
+	"scripts/deploy-camunda/secretsource"
 	"scripts/prepare-helm-values/pkg/env"
 )
 
@@ -179,6 +181,27 @@ func Run(ctx context.Context, entries []Entry, opts RunOptions) ([]RunResult, er
 		return coverageReport(entries, opts), nil
 	}
 
+	// Namespace streaming writes into the log directory; without one, say so
+	// once for the run rather than once per entry.
+	if opts.LogDir == "" {
+		logging.Logger.Warn().Msg("Namespace log streaming skipped: no log directory (--log-dir); diagnostics will lack the event timeline")
+	}
+
+	// Load secret sources ONCE before dispatching entries: parallel entries
+	// would otherwise each hit the store, and an unreachable store should
+	// stop the run before any namespace is touched.
+	if len(opts.SecretSources) > 0 && opts.SecretSourceEnv == nil {
+		values, origins, err := secretsource.LoadAll(ctx, opts.SecretSources)
+		if err != nil {
+			return nil, err
+		}
+		opts.SecretSourceEnv, opts.SecretSourceOrigins = values, origins
+		logging.Logger.Info().
+			Int("sources", len(opts.SecretSources)).
+			Int("variables", len(values)).
+			Msg("Loaded secret sources")
+	}
+
 	// Perform docker login ONCE before dispatching entries. Running `docker login`
 	// concurrently causes keychain conflicts on macOS ("item already exists" -25299).
 	// After this, each entry's deployer runs with SkipDockerLogin=true so it only
@@ -236,24 +259,85 @@ func Run(ctx context.Context, entries []Entry, opts RunOptions) ([]RunResult, er
 // when runParallel starts). This is an unexported helper so tests can exercise
 // the exact production logic.
 func synthesizeRunError(ctx context.Context, results []RunResult, totalEntries int) error {
+	switch classifyRun(ctx, results, totalEntries, nil) {
+	case RunCancelled:
+		return fmt.Errorf("run cancelled: %d of %d entries never started: %w",
+			totalEntries-len(results), totalEntries, ctx.Err())
+	case RunEntriesFailed:
+		var failCount int
+		causes := map[failures.Category]int{}
+		for _, r := range results {
+			if r.Error != nil {
+				failCount++
+				if m, ok := classifyCause(r.Error, ""); ok {
+					causes[m.Category]++
+				}
+			}
+		}
+		return fmt.Errorf("%d of %d matrix entries failed%s", failCount, len(results), formatCauses(causes))
+	}
+	return nil
+}
+
+// formatCauses renders per-category failure counts as " (2 infra-transient,
+// 1 chart-bug)", or "" when no failure was classified.
+func formatCauses(causes map[failures.Category]int) string {
+	if len(causes) == 0 {
+		return ""
+	}
+	cats := make([]string, 0, len(causes))
+	for c := range causes {
+		cats = append(cats, string(c))
+	}
+	sort.Strings(cats)
+	parts := make([]string, len(cats))
+	for i, c := range cats {
+		parts[i] = fmt.Sprintf("%d %s", causes[failures.Category(c)], c)
+	}
+	return " (" + strings.Join(parts, ", ") + ")"
+}
+
+// Run-level outcomes, as reported by classifyRun.
+const (
+	RunPassed           = "passed"
+	RunEntriesFailed    = "entries-failed"
+	RunCancelled        = "cancelled"
+	RunStoppedOnFailure = "stopped-on-failure"
+)
+
+// ErrStoppedOnFailure is wrapped into the error Run returns when
+// StopOnFailure ended the run early.
+var ErrStoppedOnFailure = errors.New("stopping on failure")
+
+// errEntrySkipped marks the RunResult of an entry that was never dispatched
+// because the run was cancelled first.
+var errEntrySkipped = errors.New("skipped")
+
+// classifyRun is the failure classification behind synthesizeRunError,
+// exposed so reports can state why a run did not pass. runErr is the error
+// Run returned, or nil when only the results are known.
+func classifyRun(ctx context.Context, results []RunResult, totalEntries int, runErr error) string {
+	if errors.Is(runErr, ErrStoppedOnFailure) {
+		return RunStoppedOnFailure
+	}
 	// If the context was cancelled and fewer results were produced than entries
 	// expected, report the cancellation — this catches the edge case where
 	// runParallel breaks out of its dispatch loop before any entry starts.
 	if ctx.Err() != nil && len(results) < totalEntries {
-		return fmt.Errorf("run cancelled: %d of %d entries never started: %w",
-			totalEntries-len(results), totalEntries, ctx.Err())
+		return RunCancelled
 	}
-
-	var failCount int
 	for _, r := range results {
 		if r.Error != nil {
-			failCount++
+			return RunEntriesFailed
 		}
 	}
-	if failCount > 0 {
-		return fmt.Errorf("%d of %d matrix entries failed", failCount, len(results))
-	}
-	return nil
+	return RunPassed
+}
+
+// isSkippedResult reports whether r stands for an entry that was never
+// dispatched because the run was cancelled first.
+func isSkippedResult(r RunResult) bool {
+	return errors.Is(r.Error, errEntrySkipped)
 }
 
 // dryRunEntry holds resolved details for one matrix entry in dry-run mode.
@@ -910,7 +994,7 @@ func runSequential(ctx context.Context, entries []Entry, opts RunOptions) ([]Run
 				logEvent.Msg("Matrix entry failed")
 
 				if opts.StopOnFailure {
-					return results, fmt.Errorf("stopping on failure: %w", result.Error)
+					return results, fmt.Errorf("%w: %w", ErrStoppedOnFailure, result.Error)
 				}
 			} else {
 				logging.Logger.Info().
@@ -966,7 +1050,7 @@ func runParallel(ctx context.Context, entries []Entry, opts RunOptions) ([]RunRe
 				results[idx] = RunResult{
 					Entry:     e,
 					Namespace: resolveNamespace(opts, e),
-					Error:     fmt.Errorf("skipped: run cancelled"),
+					Error:     fmt.Errorf("%w: run cancelled", errEntrySkipped),
 				}
 				if opts.OnEntryComplete != nil {
 					opts.OnEntryComplete(e, results[idx])
@@ -1017,7 +1101,7 @@ func runParallel(ctx context.Context, entries []Entry, opts RunOptions) ([]RunRe
 	}
 
 	if firstErr != nil {
-		return trimmed, fmt.Errorf("stopping on failure: %w", firstErr)
+		return trimmed, fmt.Errorf("%w: %w", ErrStoppedOnFailure, firstErr)
 	}
 	return trimmed, nil
 }
@@ -1073,6 +1157,7 @@ var flowAbbrevMap = map[string]string{
 	"upgrade-patch":         "upgp",
 	"upgrade-minor":         "upgm",
 	"modular-upgrade-minor": "mugm",
+	FlowUpgradePath:         "uppa",
 }
 
 func flowAbbrev(flow string) string {
@@ -1160,7 +1245,11 @@ func resolvePlatform(opts RunOptions, entry Entry) string {
 
 // resolveUseVaultBackedSecrets returns whether vault-backed secrets should be used for a given platform.
 // It checks VaultBackedSecrets (platform-specific map) first, then falls back to UseVaultBackedSecrets.
+// The local platform has no Vault, so it always uses generated secrets.
 func resolveUseVaultBackedSecrets(opts RunOptions, platform string) bool {
+	if platform == config.PlatformLocal {
+		return false
+	}
 	if v, ok := opts.VaultBackedSecrets[platform]; ok {
 		return v
 	}
@@ -1169,7 +1258,8 @@ func resolveUseVaultBackedSecrets(opts RunOptions, platform string) bool {
 
 // resolveIngressBaseDomain returns the ingress base domain for a given platform.
 // It checks IngressBaseDomains (platform-specific map) first, then falls back to
-// IngressBaseDomain, then to the INFRA_INGRESS_HOSTNAME_BASE env var
+// IngressBaseDomain, then (for the local platform) to the nip.io domain, then
+// to the INFRA_INGRESS_HOSTNAME_BASE env var
 // test-integration-runner.yaml exports. CI invokes deploy-camunda with
 // --extra-helm-set global.host=<precomputed-host> for the base (single-namespace)
 // deploy rather than --ingress-base-domain-<platform>, so neither flag-derived
@@ -1183,6 +1273,9 @@ func resolveIngressBaseDomain(opts RunOptions, platform string) string {
 	if opts.IngressBaseDomain != "" {
 		return opts.IngressBaseDomain
 	}
+	if platform == config.PlatformLocal {
+		return config.LocalIngressBaseDomain
+	}
 	return os.Getenv("INFRA_INGRESS_HOSTNAME_BASE")
 }
 
@@ -1217,6 +1310,7 @@ type diagnosticsSummary struct {
 	PVCs              string              `json:"pvcs,omitempty"`
 	PVCDescribe       string              `json:"pvcDescribe,omitempty"`
 	PodLogs           []diagnosticsPodLog `json:"podLogs,omitempty"`
+	Stream            string              `json:"stream,omitempty"`
 	TestOutputLast200 string              `json:"testOutputLast200,omitempty"`
 	Errors            []string            `json:"errors,omitempty"`
 }
@@ -1263,6 +1357,10 @@ func writeDiagnosticsReadme(runDir string, summary diagnosticsSummary) error {
 	if summary.TestOutputLast200 != "" {
 		fmt.Fprintf(&b, "- test-output.txt (last 200 lines)\n")
 	}
+	if summary.Stream != "" {
+		fmt.Fprintf(&b, "\nFull container logs (including restarted and deleted pods) and the\n")
+		fmt.Fprintf(&b, "event timeline, streamed since the deploy started: %s\n", summary.Stream)
+	}
 
 	readmePath := filepath.Join(runDir, "README.txt")
 	if err := os.WriteFile(readmePath, []byte(b.String()), 0o644); err != nil {
@@ -1278,9 +1376,11 @@ func writeDiagnosticsReadme(runDir string, summary diagnosticsSummary) error {
 // - logs/<pod>.log (last diagnosticsPodTailLines lines for every pod)
 // Uses a fresh background context so that diagnostics work even when the parent context
 // is cancelled (e.g., StopOnFailure).
+// streamDir, when set, is the entry's namespace stream directory and is
+// recorded in the summary; the stream already holds the complete logs.
 // Returns the run directory path on success, or empty string if collection/write fails.
 // Never returns an error — all failures are silently swallowed.
-func collectDiagnostics(namespace, kubeContext string) string {
+func collectDiagnostics(namespace, kubeContext, streamDir string) string {
 	// Use a fresh context with a generous timeout for the full collection.
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
@@ -1297,6 +1397,7 @@ func collectDiagnostics(namespace, kubeContext string) string {
 		KubeContext:     kubeContext,
 		CollectedAt:     time.Now().UTC().Format(time.RFC3339),
 		PodLogTailLines: diagnosticsPodTailLines,
+		Stream:          streamDir,
 	}
 
 	// Pods
@@ -1552,7 +1653,7 @@ func PrintRunSummary(results []RunResult, wallClock time.Duration, logDir string
 		sumDuration += r.Duration
 		if r.Error == nil {
 			successCount++
-		} else if r.Duration == 0 && strings.Contains(r.Error.Error(), "skipped") {
+		} else if isSkippedResult(r) {
 			skipCount++
 		} else {
 			failCount++
@@ -1576,7 +1677,7 @@ func PrintRunSummary(results []RunResult, wallClock time.Duration, logDir string
 	for _, r := range results {
 		var status string
 		if r.Error != nil {
-			if r.Duration == 0 && strings.Contains(r.Error.Error(), "skipped") {
+			if isSkippedResult(r) {
 				status = dryWarn("[SKIP]")
 			} else {
 				status = dryFail("[FAIL]")
@@ -1604,7 +1705,7 @@ func PrintRunSummary(results []RunResult, wallClock time.Duration, logDir string
 		fmt.Fprintf(&b, "\n%s\n", dryHead("Failed entries:"))
 		for _, r := range results {
 			if r.Error != nil {
-				if r.Duration == 0 && strings.Contains(r.Error.Error(), "skipped") {
+				if isSkippedResult(r) {
 					continue // Skip cancelled entries in the failure details.
 				}
 				fmt.Fprintf(&b, "\n  - %s/%s (%s, flow=%s)\n",
@@ -9,6 +9,7 @@ import (
 	"time"
 
 	"scripts/camunda-core/pkg/executil"
+	"scripts/camunda-core/pkg/kube"
 	"scripts/camunda-core/pkg/logging"
 	"scripts/camunda-core/pkg/versionmatrix"
 	"scripts/deploy-camunda/auth0"
@@ -229,6 +230,9 @@ func BuildEntryFlags(entry Entry, opts RunOptions) (flags *config.RuntimeFlags,
 			ExternalSecrets:       opts.NamespaceOverride == "",
 			AutoGenerateSecrets:   true,
 			UseVaultBackedSecrets: useVault,
+			Sources:               opts.SecretSources,
+			SourceEnv:             opts.SecretSourceEnv,
+			SourceOrigins:         opts.SecretSourceOrigins,
 		},
 		Test: config.TestFlags{
 			KubeContext: kubeCtx,
@@ -239,6 +243,7 @@ func BuildEntryFlags(entry Entry, opts RunOptions) (flags *config.RuntimeFlags,
 			// Setting RunAllTests would bypass the skip logic in deploy/test.go
 			// which ORs RunAllTests with RunE2ETests.
 			RunAllTests: false,
+			RunSmoke:    opts.Smoke && smokeSupported(entry.Version),
 		},
 		// Selection + Composition: pass explicit layer overrides from ci-test-config.yaml.
 		// When set, these override MapScenarioToConfig name-based derivation in deploy.go.
@@ -246,7 +251,7 @@ func BuildEntryFlags(entry Entry, opts RunOptions) (flags *config.RuntimeFlags,
 			Identity:    entry.Identity,
 			Persistence: entry.Persistence,
 			Features:    entry.Features,
-			InfraType:   entry.InfraType,
+			InfraType:   entryInfraType(entry, platform),
 			QA:          entry.QA || opts.UseQA,
 			ImageTags:   effectiveImageTags(entry, opts),
 			UpgradeFlow: entry.Upgrade,
@@ -269,10 +274,55 @@ func BuildEntryFlags(entry Entry, opts RunOptions) (flags *config.RuntimeFlags,
 	return flags, namespace, kubeCtx, envFile, cleanup, nil
 }
 
+// entryInfraType drops the node-pool selection on the local platform, whose
+// single kind/k3d node carries none of the pool labels.
+func entryInfraType(entry Entry, platform string) string {
+	if platform == config.PlatformLocal {
+		return ""
+	}
+	return entry.InfraType
+}
+
 func explicitIngressHost(opts RunOptions) string {
 	return parseHelmSetPairs(opts.ExtraHelmSets)["global.host"]
 }
 
+// smokeSupported reports whether the smoke suite can run against appVersion
+// (see deploy.SmokeMinMinor).
+func smokeSupported(appVersion string) bool {
+	return compareVersions(appVersion, fmt.Sprintf("8.%d", deploy.SmokeMinMinor)) >= 0
+}
+
+// startNamespaceStream starts streaming namespace events and container logs
+// into <LogDir>/<entry>.k8s/. It returns nil when LogDir is unset (Run warns
+// about that once) or the stream cannot be started; streaming is best-effort
+// and never fails an entry.
+func startNamespaceStream(ctx context.Context, entry Entry, namespace, kubeCtx string, opts RunOptions) *kube.Streamer {
+	if opts.LogDir == "" {
+		return nil
+	}
+	client, err := kube.NewClient("", kubeCtx)
+	if err != nil {
+		logging.Logger.Warn().Err(err).Str("namespace", namespace).Msg("Namespace log streaming disabled")
+		return nil
+	}
+	streamOpts := kube.StreamOptions{
+		Namespace: namespace,
+		Dir:       filepath.Join(opts.LogDir, entryLogFileName(entry)+".k8s"),
+	}
+	if opts.OnKubeEvent != nil {
+		streamOpts.OnFeed = func(line string) { opts.OnKubeEvent(entry, line) }
+	}
+	// Detached from ctx so a cancelled run (StopOnFailure) keeps streaming
+	// until the entry's own diagnostics are collected.
+	stream, err := client.StreamNamespace(context.WithoutCancel(ctx), streamOpts)
+	if err != nil {
+		logging.Logger.Warn().Err(err).Str("namespace", namespace).Msg("Namespace log streaming disabled")
+		return nil
+	}
+	return stream
+}
+
 // executeEntry deploys a single matrix entry by constructing RuntimeFlags and calling deploy.Execute().
 // The flow determines the execution strategy:
 //   - Two-step upgrade (upgrade-patch, upgrade-minor): Step 1 installs old version, Step 2 upgrades.
@@ -286,6 +336,19 @@ func executeEntry(ctx context.Context, entry Entry, opts RunOptions) RunResult {
 		opts.OnEntryStart(entry, namespace)
 	}
 
+	// finish reports the result to OnEntryComplete on every return path:
+	// OnEntryStart has already fired, and the status display and run journal
+	// would otherwise show the entry as still running.
+	finish := func(result RunResult) RunResult {
+		if result.Duration == 0 {
+			result.Duration = time.Since(start)
+		}
+		if opts.OnEntryComplete != nil {
+			opts.OnEntryComplete(entry, result)
+		}
+		return result
+	}
+
 	// Fire "preparing" phase and wire flags.OnPhase so deploy/test callbacks
 	// propagate back to the status display.
 	if opts.OnPhaseChange != nil {
@@ -295,12 +358,19 @@ func executeEntry(ctx context.Context, entry Entry, opts RunOptions) RunResult {
 	flags, namespace, kubeCtx, envFile, cleanupEnvFile, err := BuildEntryFlags(entry, opts)
 	defer cleanupEnvFile() // safe: cleanup is always a valid no-op func even on error
 	if err != nil {
-		return RunResult{Entry: entry, Namespace: namespace, KubeContext: kubeCtx, Error: err}
+		return finish(RunResult{Entry: entry, Namespace: namespace, KubeContext: kubeCtx, Error: err})
 	}
 	platform := resolvePlatform(opts, entry)
 	useVault := resolveUseVaultBackedSecrets(opts, platform)
 	logLevel := flags.LogLevel
 
+	// Keep a copy of the values chain helm receives for the diagnostics
+	// bundle; the deploy removes its own processed copies when it returns.
+	if valuesDir, err := os.MkdirTemp("", "values-chain-"); err == nil {
+		defer os.RemoveAll(valuesDir)
+		flags.ValuesSnapshotDir = valuesDir
+	}
+
 	// Wire phase reporting: deploy.Execute and RunTests call flags.OnPhase,
 	// which we forward to the matrix-level OnPhaseChange callback.
 	if opts.OnPhaseChange != nil {
@@ -403,7 +473,7 @@ func executeEntry(ctx context.Context, entry Entry, opts RunOptions) RunResult {
 
 		venomApp, err := entra.EnsureVenomApp(ctx, entraOpts)
 		if err != nil {
-			return RunResult{Entry: entry, Namespace: namespace, KubeContext: kubeCtx, Error: fmt.Errorf("entra: provision venom app: %w", err)}
+			return finish(RunResult{Entry: entry, Namespace: namespace, KubeContext: kubeCtx, Error: fmt.Errorf("entra: provision venom app: %w", err)})
 		}
 		venomOpts = &entraOpts
 
@@ -535,10 +605,7 @@ func executeEntry(ctx context.Context, entry Entry, opts RunOptions) RunResult {
 				}
 				cleanupEntry(ctx, result, opts)
 			}
-			if opts.OnEntryComplete != nil {
-				opts.OnEntryComplete(entry, result)
-			}
-			return result
+			return finish(result)
 		}
 
 		// Inject AUTH0_* env vars per-entry so buildScenarioEnv merges them
@@ -616,24 +683,25 @@ func executeEntry(ctx context.Context, entry Entry, opts RunOptions) RunResult {
 
 	// --- Lifecycle hook registration (single-step flows) ---
 	// Two-step upgrade flows register pre-install against step1Flags and
-	// post-deploy against step2Flags inside executeTwoStepUpgrade.
+	// post-deploy against step2Flags inside executeTwoStepUpgrade; the
+	// upgrade-path flow does the same per hop in executeUpgradePath.
 	// Upgrade-only flows skip pre-install entirely (no install step). We
 	// append to flags.{PreInstall,PostDeploy}Hooks rather than overwriting
 	// because earlier code (e.g. the OIDC venom-secret PreInstallHook
 	// registered above) may have populated those slots already.
-	isTwoStepUpgrade := versionmatrix.IsTwoStepUpgradeFlow(entry.Flow)
+	isTwoStepUpgrade := versionmatrix.IsTwoStepUpgradeFlow(entry.Flow) || entry.Flow == FlowUpgradePath
 	isUpgradeOnly := versionmatrix.IsUpgradeOnlyFlow(entry.Flow)
 	if !isTwoStepUpgrade && !isUpgradeOnly {
 		if err := registerDeclarativePreInstallHook(flags, entry.PreInstall, opts.RepoRoot, entry.Version, entry.Scenario); err != nil {
-			return RunResult{Entry: entry, Namespace: namespace, Error: err}
+			return finish(RunResult{Entry: entry, Namespace: namespace, KubeContext: kubeCtx, Error: err, venomOpts: venomOpts, auth0Opts: auth0Opts})
 		}
 		if err := registerDeclarativePostInfraHook(flags, entry.PostInfra, opts.RepoRoot, entry.Version, entry.Scenario); err != nil {
-			return RunResult{Entry: entry, Namespace: namespace, Error: err}
+			return finish(RunResult{Entry: entry, Namespace: namespace, KubeContext: kubeCtx, Error: err, venomOpts: venomOpts, auth0Opts: auth0Opts})
 		}
 	}
 	if !isTwoStepUpgrade {
 		if err := registerDeclarativePostDeployHook(flags, entry.PostDeploy, opts.RepoRoot, entry.Version, entry.Scenario); err != nil {
-			return RunResult{Entry: entry, Namespace: namespace, Error: err}
+			return finish(RunResult{Entry: entry, Namespace: namespace, KubeContext: kubeCtx, Error: err, venomOpts: venomOpts, auth0Opts: auth0Opts})
 		}
 	}
 
@@ -649,7 +717,7 @@ func executeEntry(ctx context.Context, entry Entry, opts RunOptions) RunResult {
 	// declare the same prefix-key.
 	if entry.PrefixKey != "" {
 		if err := deploy.PinScenarioPrefixes(entry.PrefixKey, flags); err != nil {
-			return RunResult{Entry: entry, Namespace: namespace, KubeContext: kubeCtx, Error: fmt.Errorf("pin scenario prefixes (prefix-key=%s): %w", entry.PrefixKey, err)}
+			return finish(RunResult{Entry: entry, Namespace: namespace, KubeContext: kubeCtx, Error: fmt.Errorf("pin scenario prefixes (prefix-key=%s): %w", entry.PrefixKey, err), venomOpts: venomOpts, auth0Opts: auth0Opts})
 		}
 	}
 
@@ -657,9 +725,19 @@ func executeEntry(ctx context.Context, entry Entry, opts RunOptions) RunResult {
 	// See applyChartRefOverride for details.
 	applyChartRefOverride(&flags.Chart, opts)
 
+	// Stream the namespace's events and container logs while helm runs, so a
+	// post-mortem also has the logs of pods restarted or replaced before the
+	// failure. Stopped after diagnostics are collected, before the bundle is
+	// written and cleanup deletes the namespace. Started after the last early
+	// return so every started stream reaches Stop.
+	stream := startNamespaceStream(ctx, entry, namespace, kubeCtx, opts)
+
 	// Two-step upgrade flow: install old version first, then upgrade to current.
 	if versionmatrix.IsTwoStepUpgradeFlow(entry.Flow) {
 		deployErr = executeTwoStepUpgrade(ctx, entry, flags, opts)
+	} else if entry.Flow == FlowUpgradePath {
+		// Multi-hop upgrade: install --from, then upgrade one minor at a time.
+		deployErr = executeUpgradePath(ctx, entry, flags, opts)
 	} else if versionmatrix.IsUpgradeOnlyFlow(entry.Flow) {
 		// Upgrade-only flow (modular-upgrade-minor): upgrade an already-running deployment.
 		// No Step 1 install — the prior "install" flow must have already deployed the old version.
@@ -669,11 +747,23 @@ func executeEntry(ctx context.Context, entry Entry, opts RunOptions) RunResult {
 		deployErr = deploy.Execute(ctx, flags)
 	}
 
-	// Collect diagnostics on failure (before cleanup deletes the namespace).
+	// Collect diagnostics on failure (before cleanup deletes the namespace)
+	// while the stream keeps recording. The stream is stopped before the
+	// bundle copies its files, so they are complete.
+	streamDir := ""
+	if stream != nil {
+		streamDir = stream.Dir()
+	}
 	if deployErr != nil {
-		diag = collectDiagnostics(namespace, kubeCtx)
+		diag = collectDiagnostics(namespace, kubeCtx, streamDir)
 		diag = appendTestOutputToDiagnostics(deployErr, namespace, diag)
 	}
+	if stream != nil {
+		stream.Stop()
+	}
+	if deployErr != nil && diag != "" {
+		writeDiagnosticsBundle(diag, entry, namespace, kubeCtx, flags, streamDir, deployErr)
+	}
 
 	result := RunResult{Entry: entry, Namespace: namespace, KubeContext: kubeCtx, Error: deployErr, Duration: time.Since(start), Diagnostics: diag, venomOpts: venomOpts, auth0Opts: auth0Opts}
 
@@ -686,9 +776,5 @@ func executeEntry(ctx context.Context, entry Entry, opts RunOptions) RunResult {
 		cleanupEntry(ctx, result, opts)
 	}
 
-	if opts.OnEntryComplete != nil {
-		opts.OnEntryComplete(entry, result)
-	}
-
-	return result
+	return finish(result)
 }
@@ -1,6 +1,10 @@
 package matrix
 
-// RunOptions controls matrix execution.
+import "scripts/deploy-camunda/config"
+
+// RunOptions controls matrix execution. It is persisted as JSON in the run
+// plan (see SaveRunPlan) so `matrix resume` reuses it; fields that must not
+// be written to disk are tagged json:"-".
 type RunOptions struct {
 	// DryRun logs what would be done without executing.
 	DryRun bool
@@ -31,6 +35,9 @@ type RunOptions struct {
 	TestE2E bool
 	// TestAll runs all e2e tests after each deployment.
 	TestAll bool
+	// Smoke runs the built-in smoke suite after each deployment. Entries below
+	// deploy.SmokeMinMinor are deployed without it.
+	Smoke bool
 	// RepoRoot is the repository root path.
 	RepoRoot string
 	// EnvFiles maps chart versions to .env file paths, e.g.,
@@ -70,6 +77,13 @@ type RunOptions struct {
 	// If both VaultBackedSecrets and UseVaultBackedSecrets are set, the platform-specific
 	// value takes priority.
 	UseVaultBackedSecrets bool
+	// SecretSources are the active profile's secretSources, in precedence
+	// order. Run loads them once before dispatch into SecretSourceEnv and
+	// SecretSourceOrigins, which every entry's deploy reuses instead of
+	// fetching again.
+	SecretSources       []config.SecretSourceConfig
+	SecretSourceEnv     map[string]string `json:"-"`
+	SecretSourceOrigins map[string]string `json:"-"`
 	// DeleteNamespaceFirst deletes the namespace before deploying each matrix entry.
 	// This ensures a clean-slate deployment by removing any existing resources in the namespace.
 	DeleteNamespaceFirst bool
@@ -81,6 +95,12 @@ type RunOptions struct {
 	// When set, this version is used instead of resolving from version-matrix JSON files.
 	// Only applies to entries with upgrade flows (upgrade-patch, upgrade-minor, modular-upgrade-minor).
 	UpgradeFromVersion string
+	// UpgradePath is the planned hop chain for upgrade-path entries (see
+	// PlanUpgradePath). Nil for every other flow.
+	UpgradePath *UpgradePath
+	// TestEachHop runs the configured tests after every upgrade-path hop
+	// instead of only after the last one.
+	TestEachHop bool
 	// HelmTimeout is the timeout in minutes for each Helm deployment.
 	// Applies uniformly to all matrix entries (install, upgrade Step 1, upgrade Step 2).
 	// When <= 0, deploy.Execute defaults to 5 minutes.
@@ -90,7 +110,7 @@ type RunOptions struct {
 	DockerUsername string
 	// DockerPassword is the Harbor registry password for pulling images.
 	// When empty, the deployer falls back to HARBOR_PASSWORD, TEST_DOCKER_PASSWORD_CAMUNDA_CLOUD, or NEXUS_PASSWORD env vars.
-	DockerPassword string
+	DockerPassword string `json:"-"`
 	// EnsureDockerRegistry creates a Harbor registry secret in each entry's namespace.
 	// When true, the deployer performs docker login and creates a registry-camunda-cloud
 	// Kubernetes secret of type kubernetes.io/dockerconfigjson.
@@ -100,7 +120,7 @@ type RunOptions struct {
 	DockerHubUsername string
 	// DockerHubPassword is the Docker Hub registry password.
 	// When empty, the deployer falls back to DOCKERHUB_PASSWORD or TEST_DOCKER_PASSWORD env vars.
-	DockerHubPassword string
+	DockerHubPassword string `json:"-"`
 	// EnsureDockerHub creates a Docker Hub pull secret (index-docker-io) in each entry's namespace.
 	// When true, the deployer performs docker login and creates an index-docker-io
 	// Kubernetes secret of type kubernetes.io/dockerconfigjson.
@@ -114,25 +134,34 @@ type RunOptions struct {
 	// OnEntryStart is called when a matrix entry begins execution.
 	// The callback receives the entry and its resolved namespace.
 	// Nil disables the callback (zero overhead for existing CLI behavior).
-	OnEntryStart func(entry Entry, namespace string)
+	OnEntryStart func(entry Entry, namespace string) `json:"-"`
 	// OnEntryComplete is called when a matrix entry finishes execution.
 	// The callback receives the entry and its full result (including error and duration).
 	// Nil disables the callback (zero overhead for existing CLI behavior).
-	OnEntryComplete func(entry Entry, result RunResult)
+	OnEntryComplete func(entry Entry, result RunResult) `json:"-"`
 	// OnPhaseChange is called when a matrix entry transitions to a new phase
 	// (e.g., "preparing", "deploying", "step-1", "step-2", "testing", "cleanup").
 	// Nil disables the callback.
-	OnPhaseChange func(entry Entry, phase string)
+	OnPhaseChange func(entry Entry, phase string) `json:"-"`
+	// OnKubeEvent is called with condensed cluster notices for a running entry
+	// (warning events, container restarts, crash loops, pod deletions). It
+	// only fires when LogDir is set, since that is what enables streaming.
+	// Nil disables the callback.
+	OnKubeEvent func(entry Entry, line string) `json:"-"`
 	// LogDir is the directory for per-entry log files. When set, test script
-	// output (IT/e2e) is redirected to per-entry files instead of the terminal.
+	// output (IT/e2e) is redirected to per-entry files instead of the terminal,
+	// and the events and container logs of each entry's namespace are streamed
+	// into <LogDir>/<entry>.k8s/ from before the deploy until cleanup.
 	LogDir string
 	// ExtraHelmArgs are appended to every helm command for every entry. CI uses
 	// this to inject license-key --set-file flags whose values would otherwise be
-	// shell-escaped incorrectly via --set.
+	// shell-escaped incorrectly via --set. Secret --set values in it are
+	// redacted in the run plan (see SaveRunPlan).
 	ExtraHelmArgs []string
 	// ExtraHelmSets are key=value strings applied as extra --set pairs for every
 	// entry. CI uses this for invariant flags like
-	// orchestration.upgrade.allowPreReleaseImages=true.
+	// orchestration.upgrade.allowPreReleaseImages=true. Secret values are
+	// redacted in the run plan (see SaveRunPlan).
 	ExtraHelmSets []string
 	// ExtraValues are values files plumbed into flags.Deployment.ExtraValues so
 	// neutralizeOverriddenDigests can see them. Forwarding via ExtraHelmArgs as
@@ -184,29 +184,7 @@ func executeTwoStepUpgrade(ctx context.Context, entry Entry, flags *config.Runti
 		return fmt.Errorf("step 1: helm repo update: %w", err)
 	}
 
-	// Clone flags for Step 1: deploy from repo instead of local chart path.
-	// Detach hook slices: a plain `*flags` copy shares the backing arrays with
-	// the parent, so a subsequent append (when cap > len) would mutate flags
-	// and leak Step 1-only hooks into Step 2's later shallow copy.
-	step1Flags := *flags
-	step1Flags.PreInstallHooks = append([]func(context.Context) error(nil), flags.PreInstallHooks...)
-	step1Flags.PostDeployHooks = append([]func(context.Context) error(nil), flags.PostDeployHooks...)
-	step1Flags.Chart.Chart = versionmatrix.DefaultHelmChartRef
-	step1Flags.Chart.ChartVersion = fromVersion
-	step1Flags.Chart.ChartPath = "" // Use repo chart, not local path.
-	step1Flags.Deployment.Flow = "install"
-	step1Flags.Selection.UpgradeFlow = false     // Step 1 is a fresh install, no base-upgrade.yaml.
-	step1Flags.Chart.ChartRootOverlays = nil     // Step 1 installs old version from repo — no chart-root overlays.
-	step1Flags.Chart.SkipDependencyUpdate = true // Repo charts don't need local dep update.
-	// Step 1 installs the previously released chart; both global --extra-values
-	// (e.g. per-PR image tag) and scenario-declared extra-values belong to Step 2
-	// only — they are combined in flags.Deployment.ExtraValues, which this nils.
-	step1Flags.Deployment.ExtraValues = nil
-	step1Flags.Test.RunE2ETests = false // Don't run tests after Step 1.
-	step1Flags.Test.RunAllTests = false
-	step1Flags.Deployment.WaitIngressReady = false // No ingress gate on the throwaway Step 1 install.
-	step1Flags.Deployment.IngressReadyTimeoutMinutes = 0
-	step1Flags.Deployment.DeleteNamespaceFirst = flags.Deployment.DeleteNamespaceFirst // Only delete on Step 1.
+	step1Flags := upgradeStep1Flags(flags, fromVersion)
 
 	// For upgrade-minor, Step 1 uses the PREVIOUS app version's values files.
 	// In CI, test-type-vars sets CHART_PATH to charts/camunda-platform-<previous> for
@@ -354,6 +332,36 @@ func executeTwoStepUpgrade(ctx context.Context, entry Entry, flags *config.Runti
 	return nil
 }
 
+// upgradeStep1Flags clones flags for Step 1 of a two-step upgrade: a fresh
+// install of fromVersion from the Helm repo instead of the local chart path,
+// with no tests, smoke checks or ingress gate.
+func upgradeStep1Flags(flags *config.RuntimeFlags, fromVersion string) config.RuntimeFlags {
+	// Detach hook slices: a plain `*flags` copy shares the backing arrays with
+	// the parent, so a subsequent append (when cap > len) would mutate flags
+	// and leak Step 1-only hooks into Step 2's later shallow copy.
+	step1Flags := *flags
+	step1Flags.PreInstallHooks = append([]func(context.Context) error(nil), flags.PreInstallHooks...)
+	step1Flags.PostDeployHooks = append([]func(context.Context) error(nil), flags.PostDeployHooks...)
+	step1Flags.Chart.Chart = versionmatrix.DefaultHelmChartRef
+	step1Flags.Chart.ChartVersion = fromVersion
+	step1Flags.Chart.ChartPath = "" // Use repo chart, not local path.
+	step1Flags.Deployment.Flow = "install"
+	step1Flags.Selection.UpgradeFlow = false     // Step 1 is a fresh install, no base-upgrade.yaml.
+	step1Flags.Chart.ChartRootOverlays = nil     // Step 1 installs old version from repo — no chart-root overlays.
+	step1Flags.Chart.SkipDependencyUpdate = true // Repo charts don't need local dep update.
+	// Step 1 installs the previously released chart; both global --extra-values
+	// (e.g. per-PR image tag) and scenario-declared extra-values belong to Step 2
+	// only — they are combined in flags.Deployment.ExtraValues, which this nils.
+	step1Flags.Deployment.ExtraValues = nil
+	step1Flags.Test.RunE2ETests = false // Don't run tests after Step 1.
+	step1Flags.Test.RunAllTests = false
+	step1Flags.Test.RunSmoke = false               // Smoke runs against the upgraded install only.
+	step1Flags.Deployment.WaitIngressReady = false // No ingress gate on the throwaway Step 1 install.
+	step1Flags.Deployment.IngressReadyTimeoutMinutes = 0
+	step1Flags.Deployment.DeleteNamespaceFirst = flags.Deployment.DeleteNamespaceFirst // Only delete on Step 1.
+	return step1Flags
+}
+
 // executeUpgradeOnly performs a single-step upgrade against an already-running deployment.
 // This is used for "modular-upgrade-minor" which, in CI, skips the install job entirely
 // and only runs the upgrade job against the namespace of a prior "install" flow.
@@ -167,6 +167,15 @@ func (d *StatusDisplay) OnPhaseChange(entry Entry, phase string) {
 	}
 }
 
+// OnKubeEvent adds a cluster notice for a running entry to the live feed.
+// Only the terminal display shows the feed; in CI the notices are already in
+// the entry's streamed events and logs under the log dir.
+func (d *StatusDisplay) OnKubeEvent(entry Entry, line string) {
+	if d.isTerminal {
+		d.program.Send(kubeEventMsg{Entry: entry, Line: line, At: time.Now()})
+	}
+}
+
 // OnEntryComplete updates the display when a matrix entry finishes execution.
 func (d *StatusDisplay) OnEntryComplete(entry Entry, result RunResult) {
 	if d.isTerminal {
@@ -204,7 +213,7 @@ func (d *StatusDisplay) OnEntryComplete(entry Entry, result RunResult) {
 func applyResult(s *entryState, result RunResult) {
 	s.Duration = result.Duration
 	if result.Error != nil {
-		if result.Duration == 0 && strings.Contains(result.Error.Error(), "skipped") {
+		if isSkippedResult(result) {
 			s.Status = StatusSkipped
 		} else {
 			s.Status = StatusFailed
@@ -8,6 +8,7 @@ import (
 
 	tea "charm.land/bubbletea/v2"
 	lipgloss "charm.land/lipgloss/v2"
+	"github.com/mattn/go-runewidth"
 )
 
 // ── Messages sent from StatusDisplay callbacks into the bubbletea program ──
@@ -27,6 +28,12 @@ type entryCompleteMsg struct {
 	Result RunResult
 }
 
+type kubeEventMsg struct {
+	Entry Entry
+	Line  string
+	At    time.Time
+}
+
 type stopMsg struct{}
 
 type tickMsg time.Time
@@ -44,11 +51,15 @@ var (
 
 // ── Model ──
 
+// feedSize is the number of cluster notices kept in the live feed.
+const feedSize = 6
+
 type statusModel struct {
 	states     map[string]*entryState
 	order      []string
 	startTime  time.Time
 	logDir     string
+	feed       []string // most recent cluster notices, oldest first
 	spinnerIdx int
 	quitting   bool
 }
@@ -101,6 +112,14 @@ func (m statusModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case kubeEventMsg:
+		line := fmt.Sprintf("%s  %s  %s", msg.At.Format("15:04:05"), entryID(msg.Entry), msg.Line)
+		m.feed = append(m.feed, line)
+		if len(m.feed) > feedSize {
+			m.feed = m.feed[len(m.feed)-feedSize:]
+		}
+		return m, nil
+
 	case stopMsg:
 		m.quitting = true
 		return m, tea.Quit
@@ -223,6 +242,15 @@ func (m statusModel) View() tea.View {
 		}
 	}
 
+	// ── Live cluster feed ──
+	if len(m.feed) > 0 {
+		fmt.Fprintln(&b)
+		fmt.Fprintf(&b, "  %s\n", styleBold.Render("live"))
+		for _, line := range m.feed {
+			fmt.Fprintf(&b, "  %s\n", styleYellow.Render(runewidth.Truncate(line, 110, "...")))
+		}
+	}
+
 	// ── Footer ──
 	fmt.Fprintln(&b)
 	if m.logDir != "" {
@@ -0,0 +1,428 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"scripts/camunda-core/pkg/helm"
+	"scripts/camunda-core/pkg/kube"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/camunda-core/pkg/scenarios"
+	"scripts/camunda-core/pkg/versionmatrix"
+	"scripts/deploy-camunda/config"
+	"scripts/deploy-camunda/deploy"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// FlowUpgradePath is the flow that chains installs and upgrades across
+// several chart minors (e.g. 8.6 → 8.7 → 8.8 → 8.9 → 8.10) in one namespace.
+const FlowUpgradePath = "upgrade-path"
+
+// Hop stages reported by UpgradeHopError.
+const (
+	HopStageInstall    = "install"
+	HopStagePreUpgrade = "pre-upgrade"
+	HopStageUpgrade    = "upgrade"
+	HopStageTest       = "test"
+	HopStageReadiness  = "readiness"
+)
+
+// UpgradePath is a planned upgrade-path run: the install at From followed by
+// one upgrade per minor up to To. It is part of RunOptions, so it is stored
+// in the run plan and `matrix resume` replays the same hops.
+type UpgradePath struct {
+	From string    `json:"from"`
+	To   string    `json:"to"`
+	Hops []PathHop `json:"hops"`
+}
+
+// PathHop is one step of an UpgradePath.
+type PathHop struct {
+	versionmatrix.UpgradeHop
+	// ChartDir is the hop's chart directory in the repo. With TestEachHop
+	// an intermediate hop runs this directory's tests, so each hop is
+	// checked by the suite of the minor it deployed.
+	ChartDir string `json:"chartDir"`
+	// ScenarioDir holds the hop's layered values (chart-full-setup of the
+	// hop's chart directory).
+	ScenarioDir string `json:"scenarioDir"`
+	// PreUpgrade runs before upgrading to this hop. Taken from the hop
+	// version's registry: flows.upgrade-path, else flows.upgrade-minor. Always
+	// nil on the first hop, which is an install.
+	PreUpgrade *LifecycleHook `json:"preUpgrade,omitempty"`
+}
+
+// Label names the hop for logs, phases and reports, e.g. "hop 3/5 (8.7 → 8.8)".
+func (p *UpgradePath) Label(i int) string {
+	if i == 0 {
+		return fmt.Sprintf("hop 1/%d (install %s)", len(p.Hops), p.Hops[0].AppVersion)
+	}
+	return fmt.Sprintf("hop %d/%d (%s → %s)", i+1, len(p.Hops), p.Hops[i-1].AppVersion, p.Hops[i].AppVersion)
+}
+
+// String renders the plan, one hop per line.
+func (p *UpgradePath) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Upgrade path %s → %s (%d hops):\n", p.From, p.To, len(p.Hops))
+	for i, h := range p.Hops {
+		chart := h.ChartVersion
+		if i == len(p.Hops)-1 {
+			chart = "local chart"
+		}
+		hook := ""
+		if h.PreUpgrade != nil {
+			hook = ", pre-upgrade: " + h.PreUpgrade.Script
+			if h.PreUpgrade.Script == "" {
+				hook = ", pre-upgrade: fixtures " + strings.Join(h.PreUpgrade.Fixtures, ",")
+			}
+		}
+		fmt.Fprintf(&b, "  %d. %-6s %s [%s]%s\n", i+1, h.AppVersion, chart, h.Bucket, hook)
+	}
+	return b.String()
+}
+
+// PlanUpgradePath resolves the hops from app version from to to. Hops and
+// their chart versions come from versionmatrix.PlanUpgradePath; each hop also
+// needs a chart directory for its values and may carry a pre-upgrade hook.
+func PlanUpgradePath(repoRoot, from, to string) (*UpgradePath, error) {
+	hops, err := versionmatrix.PlanUpgradePath(repoRoot, from, to)
+	if err != nil {
+		return nil, err
+	}
+	path := &UpgradePath{From: from, To: to}
+	for i, h := range hops {
+		chartDir := filepath.Join(repoRoot, "charts", "camunda-platform-"+h.AppVersion)
+		scenarioDir := filepath.Join(chartDir, "test/integration/scenarios/chart-full-setup")
+		if info, err := os.Stat(scenarioDir); err != nil || !info.IsDir() {
+			return nil, fmt.Errorf("upgrade path %s → %s: %s has no scenario values at %s", from, to, h.AppVersion, scenarioDir)
+		}
+		hop := PathHop{UpgradeHop: h, ChartDir: chartDir, ScenarioDir: scenarioDir}
+		if i > 0 && HasRegistry(chartDir) {
+			cfg, err := LoadRegistry(chartDir)
+			if err != nil {
+				return nil, fmt.Errorf("upgrade path %s → %s: load registry for %s: %w", from, to, h.AppVersion, err)
+			}
+			for _, flow := range []string{FlowUpgradePath, "upgrade-minor"} {
+				if fh := cfg.Integration.Flows[flow]; fh != nil && fh.PreUpgrade != nil {
+					hop.PreUpgrade = fh.PreUpgrade
+					break
+				}
+			}
+		}
+		path.Hops = append(path.Hops, hop)
+	}
+	return path, nil
+}
+
+// UpgradePathEntries turns generated entries into upgrade-path entries: one
+// per install-flow cell, deduplicated, with Flow set to FlowUpgradePath.
+// Topology entries are dropped; they deploy several releases and have no
+// single chain to upgrade.
+func UpgradePathEntries(entries []Entry) []Entry {
+	var out []Entry
+	seen := map[string]bool{}
+	for _, e := range entries {
+		if e.Flow != "install" || e.Topology != nil {
+			continue
+		}
+		e.Flow = FlowUpgradePath
+		e.PreUpgrade = nil
+		if key := entryID(e); !seen[key] {
+			seen[key] = true
+			out = append(out, e)
+		}
+	}
+	return out
+}
+
+// UpgradeHopError reports which hop of an upgrade-path run broke and at
+// which stage.
+type UpgradeHopError struct {
+	Path  *UpgradePath
+	Hop   int // zero-based index into Path.Hops
+	Stage string
+	Err   error
+}
+
+func (e *UpgradeHopError) Error() string {
+	return fmt.Sprintf("upgrade path %s → %s: %s failed at %s: %v", e.Path.From, e.Path.To, e.Path.Label(e.Hop), e.Stage, e.Err)
+}
+
+func (e *UpgradeHopError) Unwrap() error { return e.Err }
+
+// FailedHop returns the label of the failed hop and stage, e.g.
+// "hop 3/5 (8.7 → 8.8) at upgrade".
+func (e *UpgradeHopError) FailedHop() string {
+	return e.Path.Label(e.Hop) + " at " + e.Stage
+}
+
+// Seams for tests.
+var (
+	executeHop        = deploy.Execute
+	prepareHelmRepo   = defaultPrepareHelmRepo
+	listHopPods       = defaultListHopPods
+	hopReadyTimeout   = 3 * time.Minute
+	hopReadyPollEvery = 10 * time.Second
+)
+
+func defaultPrepareHelmRepo(ctx context.Context) error {
+	if err := helm.RepoAdd(ctx, versionmatrix.DefaultHelmRepoName, versionmatrix.DefaultHelmRepoURL); err != nil {
+		return fmt.Errorf("helm repo add: %w", err)
+	}
+	if err := helm.RepoUpdate(ctx); err != nil {
+		return fmt.Errorf("helm repo update: %w", err)
+	}
+	return nil
+}
+
+func defaultListHopPods(ctx context.Context, kubeContext, namespace string) ([]corev1.Pod, error) {
+	client, err := kube.NewClient("", kubeContext)
+	if err != nil {
+		return nil, err
+	}
+	pods, err := client.ListPods(ctx, namespace)
+	if err != nil {
+		return nil, err
+	}
+	return pods.Items, nil
+}
+
+// notReadyPods returns the names of pods that are not Ready. Completed pods
+// and Job pods (migrations, hooks) are ignored: they finish rather than
+// become Ready.
+func notReadyPods(pods []corev1.Pod) []string {
+	var names []string
+	for _, p := range pods {
+		if p.Status.Phase == corev1.PodSucceeded || ownedByJob(p) {
+			continue
+		}
+		ready := false
+		for _, c := range p.Status.Conditions {
+			if c.Type == corev1.PodReady {
+				ready = c.Status == corev1.ConditionTrue
+			}
+		}
+		if !ready {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+func ownedByJob(p corev1.Pod) bool {
+	for _, ref := range p.OwnerReferences {
+		if ref.Kind == "Job" {
+			return true
+		}
+	}
+	return false
+}
+
+// waitHopReady polls until every long-running pod in namespace is Ready.
+// helm --wait already gates each hop; this catches pods that crash-loop
+// after the release is marked deployed, before the next hop builds on them.
+func waitHopReady(ctx context.Context, kubeContext, namespace string) error {
+	deadline := time.Now().Add(hopReadyTimeout)
+	for {
+		pods, err := listHopPods(ctx, kubeContext, namespace)
+		if err != nil {
+			return err
+		}
+		notReady := notReadyPods(pods)
+		if len(notReady) == 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("pods not ready after %s: %s", hopReadyTimeout, strings.Join(notReady, ", "))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(hopReadyPollEvery):
+		}
+	}
+}
+
+// executeUpgradePath runs opts.UpgradePath in one namespace:
+//
+//	Hop 1:    install the From minor's released chart from the Helm repo.
+//	Hop 2..n: run the hop's pre-upgrade hook, then helm upgrade to the next
+//	          minor's released chart (the last hop upgrades to the on-disk
+//	          chart, or --chart-ref when set).
+//
+// Every hop uses its own minor's scenario values, so a hop renders exactly
+// what a user on that minor would. Pod readiness is verified after each hop;
+// tests run after the last hop, or after every hop with opts.TestEachHop,
+// each hop running its own minor's test suite. A failure stops the chain and
+// is returned as *UpgradeHopError.
+func executeUpgradePath(ctx context.Context, entry Entry, flags *config.RuntimeFlags, opts RunOptions) error {
+	path := opts.UpgradePath
+	if path == nil || len(path.Hops) < 2 {
+		return fmt.Errorf("%s flow requires a planned upgrade path (--from/--to)", FlowUpgradePath)
+	}
+
+	// Pin index prefixes and the Keycloak realm so every hop shares them;
+	// see executeTwoStepUpgrade.
+	if err := deploy.PinScenarioPrefixes(entry.Scenario, flags); err != nil {
+		return fmt.Errorf("pin scenario prefixes for upgrade path: %w", err)
+	}
+	if err := prepareHelmRepo(ctx); err != nil {
+		return &UpgradeHopError{Path: path, Hop: 0, Stage: HopStageInstall, Err: err}
+	}
+
+	namespace := flags.EffectiveNamespace()
+	for i, hop := range path.Hops {
+		label := path.Label(i)
+		final := i == len(path.Hops)-1
+		stage := HopStageUpgrade
+		if i == 0 {
+			stage = HopStageInstall
+		}
+		fail := func(at string, err error) error {
+			return &UpgradeHopError{Path: path, Hop: i, Stage: at, Err: err}
+		}
+		if flags.OnPhase != nil {
+			flags.OnPhase(label)
+		}
+		logging.Logger.Info().
+			Str("hop", label).
+			Str("appVersion", hop.AppVersion).
+			Str("chartVersion", hop.ChartVersion).
+			Str("bucket", hop.Bucket).
+			Bool("final", final).
+			Msg("Upgrade path: starting hop")
+
+		hopFlags, err := upgradePathHopFlags(ctx, entry, flags, opts, i)
+		if err != nil {
+			return fail(stage, err)
+		}
+
+		if i > 0 {
+			if err := runDeclarativePreUpgradeHook(ctx, flags, hop.PreUpgrade, opts.RepoRoot, hop.AppVersion, FlowUpgradePath); err != nil {
+				return fail(HopStagePreUpgrade, err)
+			}
+		}
+
+		if err := executeHop(ctx, hopFlags); err != nil {
+			var testErr *deploy.TestError
+			if errors.As(err, &testErr) {
+				stage = HopStageTest
+			}
+			return fail(stage, err)
+		}
+
+		if err := waitHopReady(ctx, flags.Test.KubeContext, namespace); err != nil {
+			return fail(HopStageReadiness, err)
+		}
+		logging.Logger.Info().
+			Str("hop", label).
+			Msg("Upgrade path: hop complete")
+	}
+	return nil
+}
+
+// upgradePathHopFlags builds the RuntimeFlags for hop i. Intermediate hops
+// deploy the hop's released chart from the Helm repo with that minor's
+// values; the final hop keeps the entry's chart and values. Hook slices are
+// detached so registrations stay scoped to one hop.
+func upgradePathHopFlags(ctx context.Context, entry Entry, flags *config.RuntimeFlags, opts RunOptions, i int) (*config.RuntimeFlags, error) {
+	path := opts.UpgradePath
+	hop := path.Hops[i]
+	final := i == len(path.Hops)-1
+
+	hf := *flags
+	hf.PreInstallHooks = append([]func(context.Context) error(nil), flags.PreInstallHooks...)
+	hf.PostInfraHooks = append([]func(context.Context) error(nil), flags.PostInfraHooks...)
+	hf.PostDeployHooks = append([]func(context.Context) error(nil), flags.PostDeployHooks...)
+	hf.Deployment.Flow = "install" // Same $FLOW on every hop so index prefixes resolve identically.
+	if flags.OnPhase != nil {
+		label := path.Label(i)
+		hf.OnPhase = func(phase string) { flags.OnPhase(label + " " + phase) }
+	}
+
+	if !final {
+		hf.Chart.Chart = versionmatrix.DefaultHelmChartRef
+		hf.Chart.ChartVersion = hop.ChartVersion
+		hf.Chart.ChartPath = ""
+		hf.Chart.ChartRootOverlays = nil
+		hf.Chart.SkipDependencyUpdate = true
+		hf.Deployment.ScenarioPath = hop.ScenarioDir
+		// --extra-values and scenario extra-values target the branch chart.
+		hf.Deployment.ExtraValues = nil
+		hf.Deployment.WaitIngressReady = false
+		hf.Deployment.IngressReadyTimeoutMinutes = 0
+		if len(hf.Selection.Features) > 0 {
+			available, err := scenarios.ListFeatures(hop.ScenarioDir)
+			if err != nil {
+				return nil, fmt.Errorf("list features for %s: %w", hop.AppVersion, err)
+			}
+			kept, dropped := filterKnownFeatures(hf.Selection.Features, available)
+			hf.Selection.Features = kept
+			if len(dropped) > 0 {
+				logging.Logger.Info().
+					Str("appVersion", hop.AppVersion).
+					Strs("dropped", dropped).
+					Msg("Upgrade path: dropped features absent from this minor")
+			}
+		}
+	}
+	if !final {
+		if opts.TestEachHop {
+			hf.Test.ChartPath = hop.ChartDir
+			hf.Test.RunSmoke = hf.Test.RunSmoke && smokeSupported(hop.AppVersion)
+		} else {
+			hf.Test.RunE2ETests = false
+			hf.Test.RunAllTests = false
+			hf.Test.RunSmoke = false
+		}
+	}
+
+	if i == 0 {
+		hf.Selection.UpgradeFlow = false
+		if err := registerDeclarativePreInstallHook(&hf, entry.PreInstall, opts.RepoRoot, hop.AppVersion, entry.Scenario); err != nil {
+			return nil, err
+		}
+	} else {
+		hf.Selection.UpgradeFlow = true
+		hf.Deployment.DeleteNamespaceFirst = false
+		hf.Deployment.ExtraHelmSets = mergeHelmSets(
+			flags.Deployment.ExtraHelmSets,
+			map[string]string{"orchestration.upgrade.allowPreReleaseImages": "true"},
+		)
+		if shouldExtractBitnamiPGPasswords(hop.AppVersion) {
+			for k, v := range extractBitnamiPGPasswords(ctx, flags.EffectiveNamespace(), flags.Test.KubeContext) {
+				hf.Deployment.ExtraHelmSets[k] = v
+			}
+		}
+	}
+
+	if final {
+		if err := registerDeclarativePostInfraHook(&hf, entry.PostInfra, opts.RepoRoot, entry.Version, entry.Scenario); err != nil {
+			return nil, err
+		}
+		if err := registerDeclarativePostDeployHook(&hf, entry.PostDeploy, opts.RepoRoot, entry.Version, entry.Scenario); err != nil {
+			return nil, err
+		}
+	}
+	return &hf, nil
+}
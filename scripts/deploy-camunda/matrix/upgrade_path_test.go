@@ -0,0 +1,354 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package matrix
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"scripts/camunda-core/pkg/versionmatrix"
+	"scripts/deploy-camunda/config"
+	"scripts/deploy-camunda/deploy"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPlanUpgradePathRepo(t *testing.T) {
+	repoRoot := findRepoRoot(t)
+	path, err := PlanUpgradePath(repoRoot, "8.7", "8.9")
+	if err != nil {
+		t.Fatalf("PlanUpgradePath: %v", err)
+	}
+	if len(path.Hops) != 3 {
+		t.Fatalf("hops = %d, want 3", len(path.Hops))
+	}
+	if path.Hops[0].PreUpgrade != nil {
+		t.Errorf("first hop is an install and must not carry a pre-upgrade hook")
+	}
+	// 8.8 declares flows.upgrade-minor.pre-upgrade in its registry.
+	if path.Hops[1].PreUpgrade == nil {
+		t.Errorf("hop 8.8 should pick up the upgrade-minor pre-upgrade hook")
+	}
+	for _, h := range path.Hops {
+		if !strings.HasSuffix(h.ScenarioDir, filepath.Join("camunda-platform-"+h.AppVersion, "test/integration/scenarios/chart-full-setup")) {
+			t.Errorf("hop %s ScenarioDir = %s", h.AppVersion, h.ScenarioDir)
+		}
+	}
+	out := path.String()
+	for _, want := range []string{"Upgrade path 8.7 → 8.9 (3 hops)", "3. 8.9    local chart"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("String() missing %q:\n%s", want, out)
+		}
+	}
+}
+
+func TestUpgradePathLabel(t *testing.T) {
+	path := testUpgradePath(t.TempDir(), "8.10", "8.11", "8.12")
+	if got := path.Label(0); got != "hop 1/3 (install 8.10)" {
+		t.Errorf("Label(0) = %q", got)
+	}
+	if got := path.Label(2); got != "hop 3/3 (8.11 → 8.12)" {
+		t.Errorf("Label(2) = %q", got)
+	}
+}
+
+func TestUpgradePathEntries(t *testing.T) {
+	entries := []Entry{
+		{Version: "8.9", Scenario: "es", Shortname: "es", Flow: "install", PreUpgrade: &LifecycleHook{Script: "x.sh"}},
+		{Version: "8.9", Scenario: "es", Shortname: "es", Flow: "upgrade-minor"},
+		{Version: "8.9", Scenario: "es", Shortname: "es", Flow: "install"},
+		{Version: "8.9", Scenario: "topo", Shortname: "topo", Flow: "install", Topology: &Topology{}},
+		{Version: "8.9", Scenario: "os", Shortname: "os", Flow: "install"},
+	}
+	got := UpgradePathEntries(entries)
+	if len(got) != 2 {
+		t.Fatalf("got %d entries, want 2: %+v", len(got), got)
+	}
+	for _, e := range got {
+		if e.Flow != FlowUpgradePath || e.PreUpgrade != nil {
+			t.Errorf("entry %s: flow=%q preUpgrade=%v", e.Scenario, e.Flow, e.PreUpgrade)
+		}
+	}
+	if flowAbbrev(FlowUpgradePath) != "uppa" {
+		t.Errorf("flowAbbrev(%s) = %q", FlowUpgradePath, flowAbbrev(FlowUpgradePath))
+	}
+}
+
+func TestNotReadyPods(t *testing.T) {
+	pod := func(name string, phase corev1.PodPhase, ready corev1.ConditionStatus, owner string) corev1.Pod {
+		p := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: name}}
+		p.Status.Phase = phase
+		p.Status.Conditions = []corev1.PodCondition{{Type: corev1.PodReady, Status: ready}}
+		if owner != "" {
+			p.OwnerReferences = []metav1.OwnerReference{{Kind: owner, Name: name}}
+		}
+		return p
+	}
+	got := notReadyPods([]corev1.Pod{
+		pod("zeebe-0", corev1.PodRunning, corev1.ConditionTrue, "StatefulSet"),
+		pod("operate-1", corev1.PodRunning, corev1.ConditionFalse, "ReplicaSet"),
+		pod("migration", corev1.PodFailed, corev1.ConditionFalse, "Job"),
+		pod("done", corev1.PodSucceeded, corev1.ConditionFalse, ""),
+		pod("pending", corev1.PodPending, corev1.ConditionFalse, ""),
+	})
+	if strings.Join(got, ",") != "operate-1,pending" {
+		t.Errorf("notReadyPods = %v", got)
+	}
+}
+
+// testUpgradePath builds a path with synthetic versions (>= 8.10, so no
+// Bitnami password extraction touches a cluster) and scenario dirs under root.
+func testUpgradePath(root string, versions ...string) *UpgradePath {
+	path := &UpgradePath{From: versions[0], To: versions[len(versions)-1]}
+	for i, v := range versions {
+		path.Hops = append(path.Hops, PathHop{
+			UpgradeHop:  versionmatrix.UpgradeHop{AppVersion: v, ChartVersion: "99." + string(rune('0'+i)) + ".0", Bucket: versionmatrix.BucketAlpha},
+			ChartDir:    filepath.Join(root, "charts", "camunda-platform-"+v),
+			ScenarioDir: filepath.Join(root, "charts", "camunda-platform-"+v, "scenarios"),
+		})
+	}
+	return path
+}
+
+// stubUpgradePathSeams replaces the cluster-facing seams and records every
+// hop's flags.
+func stubUpgradePathSeams(t *testing.T, hopErr func(i int) error, pods []corev1.Pod) *[]config.RuntimeFlags {
+	t.Helper()
+	origExec, origRepo, origPods, origPoll := executeHop, prepareHelmRepo, listHopPods, hopReadyPollEvery
+	origTimeout := hopReadyTimeout
+	t.Cleanup(func() {
+		executeHop, prepareHelmRepo, listHopPods, hopReadyPollEvery = origExec, origRepo, origPods, origPoll
+		hopReadyTimeout = origTimeout
+	})
+	var seen []config.RuntimeFlags
+	executeHop = func(_ context.Context, f *config.RuntimeFlags) error {
+		seen = append(seen, *f)
+		if hopErr != nil {
+			return hopErr(len(seen) - 1)
+		}
+		return nil
+	}
+	prepareHelmRepo = func(context.Context) error { return nil }
+	listHopPods = func(context.Context, string, string) ([]corev1.Pod, error) { return pods, nil }
+	hopReadyPollEvery = time.Millisecond
+	hopReadyTimeout = 5 * time.Millisecond
+	return &seen
+}
+
+func upgradePathFlags() *config.RuntimeFlags {
+	f := &config.RuntimeFlags{}
+	f.Deployment.Namespace = "uppa-ns"
+	f.Chart.ChartPath = "/repo/charts/camunda-platform-8.12"
+	f.Deployment.ScenarioPath = "/repo/charts/camunda-platform-8.12/scenarios"
+	f.Deployment.ExtraValues = []string{"pr-images.yaml"}
+	f.Deployment.DeleteNamespaceFirst = true
+	f.Test.RunE2ETests = true
+	return f
+}
+
+func TestExecuteUpgradePath(t *testing.T) {
+	seen := stubUpgradePathSeams(t, nil, nil)
+	root := t.TempDir()
+	opts := RunOptions{RepoRoot: root, UpgradePath: testUpgradePath(root, "8.10", "8.11", "8.12")}
+	var phases []string
+	flags := upgradePathFlags()
+	flags.OnPhase = func(p string) { phases = append(phases, p) }
+
+	if err := executeUpgradePath(context.Background(), Entry{Version: "8.12", Scenario: "es"}, flags, opts); err != nil {
+		t.Fatalf("executeUpgradePath: %v", err)
+	}
+	if len(*seen) != 3 {
+		t.Fatalf("executed %d hops, want 3", len(*seen))
+	}
+	install, mid, final := (*seen)[0], (*seen)[1], (*seen)[2]
+
+	if install.Chart.Chart != versionmatrix.DefaultHelmChartRef || install.Chart.ChartVersion != "99.0.0" || install.Chart.ChartPath != "" {
+		t.Errorf("install hop chart = %+v", install.Chart)
+	}
+	if install.Selection.UpgradeFlow || !install.Deployment.DeleteNamespaceFirst {
+		t.Errorf("install hop: upgradeFlow=%v deleteNamespaceFirst=%v", install.Selection.UpgradeFlow, install.Deployment.DeleteNamespaceFirst)
+	}
+	if install.Deployment.ExtraValues != nil || install.Test.RunE2ETests {
+		t.Errorf("install hop must not carry branch extra-values or run tests")
+	}
+	if mid.Deployment.ScenarioPath != opts.UpgradePath.Hops[1].ScenarioDir || !mid.Selection.UpgradeFlow || mid.Deployment.DeleteNamespaceFirst {
+		t.Errorf("middle hop: scenario=%s upgradeFlow=%v deleteNamespaceFirst=%v", mid.Deployment.ScenarioPath, mid.Selection.UpgradeFlow, mid.Deployment.DeleteNamespaceFirst)
+	}
+	if mid.Deployment.ExtraHelmSets["orchestration.upgrade.allowPreReleaseImages"] != "true" {
+		t.Errorf("middle hop ExtraHelmSets = %v", mid.Deployment.ExtraHelmSets)
+	}
+	if final.Chart.ChartPath != flags.Chart.ChartPath || final.Deployment.ScenarioPath != flags.Deployment.ScenarioPath || !final.Test.RunE2ETests {
+		t.Errorf("final hop should use the entry's chart, values and tests: %+v", final.Chart)
+	}
+	if len(final.Deployment.ExtraValues) != 1 {
+		t.Errorf("final hop ExtraValues = %v", final.Deployment.ExtraValues)
+	}
+	for _, f := range *seen {
+		if f.Deployment.Flow != "install" || f.Index.OrchestrationIndexPrefix != flags.Index.OrchestrationIndexPrefix || f.Index.OrchestrationIndexPrefix == "" {
+			t.Errorf("hops must share flow and pinned prefixes: flow=%q prefix=%q", f.Deployment.Flow, f.Index.OrchestrationIndexPrefix)
+		}
+	}
+	want := []string{"hop 1/3 (install 8.10)", "hop 2/3 (8.10 → 8.11)", "hop 3/3 (8.11 → 8.12)"}
+	if strings.Join(phases, "|") != strings.Join(want, "|") {
+		t.Errorf("phases = %v", phases)
+	}
+}
+
+func TestExecuteUpgradePathTestEachHop(t *testing.T) {
+	seen := stubUpgradePathSeams(t, nil, nil)
+	root := t.TempDir()
+	opts := RunOptions{RepoRoot: root, TestEachHop: true, UpgradePath: testUpgradePath(root, "8.10", "8.11")}
+	if err := executeUpgradePath(context.Background(), Entry{Version: "8.11", Scenario: "es"}, upgradePathFlags(), opts); err != nil {
+		t.Fatalf("executeUpgradePath: %v", err)
+	}
+	for i, f := range *seen {
+		if !f.Test.RunE2ETests {
+			t.Errorf("hop %d: tests disabled with TestEachHop", i+1)
+		}
+	}
+	if got, want := (*seen)[0].Test.ChartPath, opts.UpgradePath.Hops[0].ChartDir; got != want {
+		t.Errorf("install hop tests from %q, want the 8.10 chart %q", got, want)
+	}
+	if got := (*seen)[1].Test.ChartPath; got != "" {
+		t.Errorf("final hop tests from %q, want the entry's chart", got)
+	}
+}
+
+func TestExecuteUpgradePathSmoke(t *testing.T) {
+	for _, tt := range []struct {
+		testEachHop bool
+		want        []bool
+	}{
+		{false, []bool{false, false, true}},
+		{true, []bool{false, true, true}}, // 8.7 predates the smoke suite
+	} {
+		seen := stubUpgradePathSeams(t, nil, nil)
+		root := t.TempDir()
+		opts := RunOptions{RepoRoot: root, TestEachHop: tt.testEachHop, UpgradePath: testUpgradePath(root, "8.7", "8.10", "8.11")}
+		flags := upgradePathFlags()
+		flags.Test.RunSmoke = true
+		if err := executeUpgradePath(context.Background(), Entry{Version: "8.11", Scenario: "es"}, flags, opts); err != nil {
+			t.Fatalf("executeUpgradePath: %v", err)
+		}
+		for i, f := range *seen {
+			if f.Test.RunSmoke != tt.want[i] {
+				t.Errorf("TestEachHop=%v hop %d: RunSmoke = %v, want %v", tt.testEachHop, i+1, f.Test.RunSmoke, tt.want[i])
+			}
+		}
+	}
+}
+
+func failOnHop(hop int, err error) func(int) error {
+	return func(i int) error {
+		if i == hop {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestExecuteUpgradePathReportsFailedHop(t *testing.T) {
+	notReady := corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "zeebe-0"}}
+	tests := []struct {
+		name      string
+		hopErr    func(i int) error
+		pods      []corev1.Pod
+		preScript string
+		wantHop   int
+		wantStage string
+		wantExecs int
+	}{
+		{
+			name:      "upgrade fails",
+			hopErr:    failOnHop(1, errors.New("helm upgrade failed")),
+			wantHop:   1,
+			wantStage: HopStageUpgrade,
+			wantExecs: 2,
+		},
+		{
+			name:      "tests fail",
+			hopErr:    failOnHop(2, &deploy.TestError{Err: errors.New("e2e")}),
+			wantHop:   2,
+			wantStage: HopStageTest,
+			wantExecs: 3,
+		},
+		{
+			name:      "pods not ready",
+			pods:      []corev1.Pod{notReady},
+			wantHop:   0,
+			wantStage: HopStageReadiness,
+			wantExecs: 1,
+		},
+		{
+			name:      "pre-upgrade hook fails",
+			preScript: "exit 3",
+			wantHop:   1,
+			wantStage: HopStagePreUpgrade,
+			wantExecs: 1,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := stubUpgradePathSeams(t, tt.hopErr, tt.pods)
+			root := t.TempDir()
+			path := testUpgradePath(root, "8.10", "8.11", "8.12")
+			if tt.preScript != "" {
+				script := versionmatrix.PreSetupScriptPath(root, "8.11", "pre.sh")
+				if err := os.MkdirAll(filepath.Dir(script), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				if err := os.WriteFile(script, []byte(tt.preScript+"\n"), 0o755); err != nil {
+					t.Fatal(err)
+				}
+				path.Hops[1].PreUpgrade = &LifecycleHook{Script: "pre.sh"}
+			}
+			opts := RunOptions{RepoRoot: root, UpgradePath: path}
+
+			err := executeUpgradePath(context.Background(), Entry{Version: "8.12", Scenario: "es"}, upgradePathFlags(), opts)
+			var hopErr *UpgradeHopError
+			if !errors.As(err, &hopErr) {
+				t.Fatalf("error = %v, want *UpgradeHopError", err)
+			}
+			if hopErr.Hop != tt.wantHop || hopErr.Stage != tt.wantStage {
+				t.Errorf("failed hop = %d at %s, want %d at %s", hopErr.Hop, hopErr.Stage, tt.wantHop, tt.wantStage)
+			}
+			if len(*seen) != tt.wantExecs {
+				t.Errorf("executed %d hops, want %d (chain must stop at the failure)", len(*seen), tt.wantExecs)
+			}
+			if tt.wantStage == HopStageTest && classifyFailure(err) != FailureTest {
+				t.Errorf("classifyFailure = %s, want %s", classifyFailure(err), FailureTest)
+			}
+		})
+	}
+}
+
+func TestBuildRunReportFailedHop(t *testing.T) {
+	path := testUpgradePath(t.TempDir(), "8.10", "8.11")
+	entry := Entry{Version: "8.11", Scenario: "es", Shortname: "es", Flow: FlowUpgradePath}
+	err := &UpgradeHopError{Path: path, Hop: 1, Stage: HopStageUpgrade, Err: errors.New("boom")}
+	report := BuildRunReport(context.Background(), []Entry{entry}, []RunResult{{Entry: entry, Error: err}}, nil, time.Second, "", nil)
+	if got := report.Entries[0].FailedHop; got != "hop 2/2 (8.10 → 8.11) at upgrade" {
+		t.Errorf("FailedHop = %q", got)
+	}
+	if md := report.Markdown(); !strings.Contains(md, "Failed hop: hop 2/2") {
+		t.Errorf("markdown missing failed hop:\n%s", md)
+	}
+}
@@ -0,0 +1,92 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+
+// Package junit holds the JUnit XML schema the deploy-camunda reports emit
+// (matrix run --report junit, watch replay --format junit), so CI test
+// reporters read both the same way.
+package junit
+
+import (
+	"encoding/xml"
+	"fmt"
+)
+
+// Suites is the <testsuites> root. Its totals are optional: reporters that
+// need them set them, the rest leave them out.
+type Suites struct {
+	XMLName  xml.Name `xml:"testsuites"`
+	Name     string   `xml:"name,attr,omitempty"`
+	Tests    int      `xml:"tests,attr,omitempty"`
+	Failures int      `xml:"failures,attr,omitempty"`
+	Skipped  int      `xml:"skipped,attr,omitempty"`
+	Time     string   `xml:"time,attr,omitempty"`
+	Suites   []Suite  `xml:"testsuite"`
+}
+
+// Suite is one <testsuite>.
+type Suite struct {
+	Name       string     `xml:"name,attr"`
+	Tests      int        `xml:"tests,attr"`
+	Failures   int        `xml:"failures,attr"`
+	Errors     int        `xml:"errors,attr"`
+	Skipped    int        `xml:"skipped,attr"`
+	Time       string     `xml:"time,attr"`
+	Properties []Property `xml:"properties>property,omitempty"`
+	Cases      []Case     `xml:"testcase"`
+}
+
+// Property is a suite-level name/value pair.
+type Property struct {
+	Name  string `xml:"name,attr"`
+	Value string `xml:"value,attr"`
+}
+
+// Case is one <testcase>. At most one of Failure, Error and Skipped is set.
+type Case struct {
+	Name      string   `xml:"name,attr"`
+	Classname string   `xml:"classname,attr"`
+	Time      string   `xml:"time,attr,omitempty"`
+	Failure   *Failure `xml:"failure,omitempty"`
+	Error     *Failure `xml:"error,omitempty"`
+	Skipped   *Skipped `xml:"skipped,omitempty"`
+	SystemOut string   `xml:"system-out,omitempty"`
+}
+
+// Failure is a <failure> or <error> element.
+type Failure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Body    string `xml:",chardata"`
+}
+
+// Skipped is a <skipped> element.
+type Skipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// Seconds formats a duration in seconds the way JUnit time attributes
+// expect.
+func Seconds(s float64) string {
+	return fmt.Sprintf("%.3f", s)
+}
+
+// Marshal renders root as an indented XML document with its header.
+func Marshal(root Suites) ([]byte, error) {
+	out, err := xml.MarshalIndent(root, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("encode junit report: %w", err)
+	}
+	return append([]byte(xml.Header), append(out, '\n')...), nil
+}
@@ -0,0 +1,346 @@
+package render
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Resource is one Kubernetes object from a rendered release file.
+type Resource struct {
+	// File is the release file, relative to the render root.
+	File       string
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	Object     map[string]any
+}
+
+// ID identifies a resource within its release file. apiVersion is left out
+// on purpose: moving an Ingress from v1beta1 to v1 is a change, not a
+// remove-and-add.
+func (r Resource) ID() string {
+	id := r.Kind + "/" + r.Name
+	if r.Namespace != "" {
+		id = r.Kind + "/" + r.Namespace + "/" + r.Name
+	}
+	return id
+}
+
+func (r Resource) key() string { return r.File + "\x00" + r.ID() }
+
+// Status is how a resource differs between two renders.
+type Status string
+
+const (
+	StatusAdded   Status = "added"
+	StatusRemoved Status = "removed"
+	StatusChanged Status = "changed"
+)
+
+// FieldChange is one leaf value that differs inside a changed resource.
+// Old is empty for added fields and New is empty for removed ones.
+type FieldChange struct {
+	Path string
+	Old  string
+	New  string
+}
+
+// ResourceDiff describes one added, removed or changed resource.
+type ResourceDiff struct {
+	File    string
+	ID      string
+	Status  Status
+	Changes []FieldChange
+}
+
+// LoadDir reads every *.yaml file under dir (recursively) into resources.
+// A missing dir yields no resources, so a scenario that only exists on one
+// side of a diff shows up as entirely added or removed.
+func LoadDir(dir string) ([]Resource, error) {
+	var out []Resource
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) && path == dir {
+				return fs.SkipAll
+			}
+			return err
+		}
+		if d.IsDir() || filepath.Ext(path) != ".yaml" {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		res, err := ParseManifests(filepath.ToSlash(rel), data)
+		if err != nil {
+			return err
+		}
+		out = append(out, res...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rendered manifests from %s: %w", dir, err)
+	}
+	return out, nil
+}
+
+// ParseManifests splits a multi-document YAML stream into resources. Empty
+// documents (templates that rendered to nothing) are skipped.
+func ParseManifests(file string, data []byte) ([]Resource, error) {
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	var out []Resource
+	for {
+		var obj map[string]any
+		err := dec.Decode(&obj)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if len(obj) == 0 {
+			continue
+		}
+		r := Resource{File: file, Object: obj}
+		r.APIVersion, _ = obj["apiVersion"].(string)
+		r.Kind, _ = obj["kind"].(string)
+		if meta, ok := obj["metadata"].(map[string]any); ok {
+			r.Name, _ = meta["name"].(string)
+			r.Namespace, _ = meta["namespace"].(string)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Diff compares the renders under oldDir and newDir and returns one entry per
+// resource that was added, removed or changed, sorted by file and ID.
+func Diff(oldDir, newDir string) ([]ResourceDiff, error) {
+	oldRes, err := LoadDir(oldDir)
+	if err != nil {
+		return nil, err
+	}
+	newRes, err := LoadDir(newDir)
+	if err != nil {
+		return nil, err
+	}
+	return DiffResources(oldRes, newRes), nil
+}
+
+// DiffResources is Diff over already-loaded resources.
+func DiffResources(oldRes, newRes []Resource) []ResourceDiff {
+	oldByKey := make(map[string]Resource, len(oldRes))
+	for _, r := range oldRes {
+		oldByKey[r.key()] = r
+	}
+	newByKey := make(map[string]Resource, len(newRes))
+	for _, r := range newRes {
+		newByKey[r.key()] = r
+	}
+
+	var diffs []ResourceDiff
+	for k, n := range newByKey {
+		o, ok := oldByKey[k]
+		if !ok {
+			diffs = append(diffs, ResourceDiff{File: n.File, ID: n.ID(), Status: StatusAdded})
+			continue
+		}
+		var changes []FieldChange
+		diffValue("", o.Object, n.Object, &changes)
+		if len(changes) > 0 {
+			diffs = append(diffs, ResourceDiff{File: n.File, ID: n.ID(), Status: StatusChanged, Changes: changes})
+		}
+	}
+	for k, o := range oldByKey {
+		if _, ok := newByKey[k]; !ok {
+			diffs = append(diffs, ResourceDiff{File: o.File, ID: o.ID(), Status: StatusRemoved})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].File != diffs[j].File {
+			return diffs[i].File < diffs[j].File
+		}
+		return diffs[i].ID < diffs[j].ID
+	})
+	return diffs
+}
+
+// diffValue walks a and b in parallel and records every leaf that differs.
+// Lists whose items all carry a "name" (containers, env, volumes, ports) are
+// matched by name rather than index, so inserting one env var reports one
+// addition instead of shifting every later entry.
+func diffValue(path string, a, b any, out *[]FieldChange) {
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := make(map[string]struct{}, len(av)+len(bv))
+		for k := range av {
+			keys[k] = struct{}{}
+		}
+		for k := range bv {
+			keys[k] = struct{}{}
+		}
+		for _, k := range sortedKeys(keys) {
+			diffValue(joinPath(path, k), av[k], bv[k], out)
+		}
+		return
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		if an, bn := namedItems(av), namedItems(bv); an != nil && bn != nil {
+			keys := make(map[string]struct{}, len(an)+len(bn))
+			for k := range an {
+				keys[k] = struct{}{}
+			}
+			for k := range bn {
+				keys[k] = struct{}{}
+			}
+			for _, k := range sortedKeys(keys) {
+				diffValue(fmt.Sprintf("%s[name=%s]", path, k), an[k], bn[k], out)
+			}
+			return
+		}
+		n := max(len(av), len(bv))
+		for i := 0; i < n; i++ {
+			var ai, bi any
+			if i < len(av) {
+				ai = av[i]
+			}
+			if i < len(bv) {
+				bi = bv[i]
+			}
+			diffValue(fmt.Sprintf("%s[%d]", path, i), ai, bi, out)
+		}
+		return
+	}
+	if reflect.DeepEqual(a, b) {
+		return
+	}
+	*out = append(*out, FieldChange{Path: path, Old: formatValue(a), New: formatValue(b)})
+}
+
+// namedItems indexes a list by each item's "name" field, or returns nil when
+// any item lacks a unique string name.
+func namedItems(items []any) map[string]any {
+	if len(items) == 0 {
+		return nil
+	}
+	m := make(map[string]any, len(items))
+	for _, it := range items {
+		obj, ok := it.(map[string]any)
+		if !ok {
+			return nil
+		}
+		name, ok := obj["name"].(string)
+		if !ok || name == "" {
+			return nil
+		}
+		if _, dup := m[name]; dup {
+			return nil
+		}
+		m[name] = it
+	}
+	return m
+}
+
+func joinPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		key = fmt.Sprintf("[%q]", key)
+		return path + key
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func sortedKeys(m map[string]struct{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// formatValue renders a leaf for display; composite values (a whole map or
+// list added or removed) are shown as compact JSON.
+func formatValue(v any) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case map[string]any, []any:
+		data, err := json.Marshal(t)
+		if err != nil {
+			return fmt.Sprintf("%v", t)
+		}
+		return string(data)
+	default:
+		return fmt.Sprintf("%v", t)
+	}
+}
+
+// WriteDiff prints diffs grouped by release file, one line per field change.
+func WriteDiff(w io.Writer, diffs []ResourceDiff) {
+	if len(diffs) == 0 {
+		fmt.Fprintln(w, "No resource changes.")
+		return
+	}
+	var added, removed, changed int
+	file := ""
+	for _, d := range diffs {
+		if d.File != file {
+			if file != "" {
+				fmt.Fprintln(w)
+			}
+			file = d.File
+			fmt.Fprintf(w, "=== %s\n", file)
+		}
+		switch d.Status {
+		case StatusAdded:
+			added++
+			fmt.Fprintf(w, "+ %s\n", d.ID)
+		case StatusRemoved:
+			removed++
+			fmt.Fprintf(w, "- %s\n", d.ID)
+		case StatusChanged:
+			changed++
+			fmt.Fprintf(w, "~ %s\n", d.ID)
+			for _, c := range d.Changes {
+				switch {
+				case c.Old == "":
+					fmt.Fprintf(w, "    + %s: %s\n", c.Path, c.New)
+				case c.New == "":
+					fmt.Fprintf(w, "    - %s: %s\n", c.Path, c.Old)
+				default:
+					fmt.Fprintf(w, "    ~ %s: %s -> %s\n", c.Path, c.Old, c.New)
+				}
+			}
+		}
+	}
+	fmt.Fprintf(w, "\n%d added, %d removed, %d changed\n", added, removed, changed)
+}
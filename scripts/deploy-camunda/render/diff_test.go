@@ -0,0 +1,118 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"bytes"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func mustParse(t *testing.T, file, data string) []Resource {
+	t.Helper()
+	res, err := ParseManifests(file, []byte(data))
+	if err != nil {
+		t.Fatalf("ParseManifests: %v", err)
+	}
+	return res
+}
+
+const baseDeployment = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: ns
+spec:
+  template:
+    spec:
+      containers:
+        - name: app
+          env:
+            - name: A
+              value: "1"
+            - name: B
+              value: "2"
+`
+
+func TestParseManifestsSkipsEmptyDocuments(t *testing.T) {
+	res := mustParse(t, "r.yaml", "---\n# Source: empty\n---\n"+baseDeployment)
+	if len(res) != 1 {
+		t.Fatalf("got %d resources, want 1", len(res))
+	}
+	if got := res[0].ID(); got != "Deployment/ns/web" {
+		t.Fatalf("ID = %q", got)
+	}
+}
+
+func TestDiffResourcesMatchesNamedListItems(t *testing.T) {
+	changed := strings.Replace(baseDeployment, "            - name: B\n", "            - name: EXTRA\n              value: \"x\"\n            - name: B\n", 1)
+	diffs := DiffResources(mustParse(t, "r.yaml", baseDeployment), mustParse(t, "r.yaml", changed))
+	want := []ResourceDiff{{
+		File:   "r.yaml",
+		ID:     "Deployment/ns/web",
+		Status: StatusChanged,
+		Changes: []FieldChange{{
+			Path: "spec.template.spec.containers[name=app].env[name=EXTRA]",
+			New:  `{"name":"EXTRA","value":"x"}`,
+		}},
+	}}
+	if !reflect.DeepEqual(diffs, want) {
+		t.Fatalf("diffs = %+v, want %+v", diffs, want)
+	}
+}
+
+func TestDiffResourcesAddedRemovedAndAPIVersion(t *testing.T) {
+	oldRes := mustParse(t, "r.yaml", "apiVersion: networking.k8s.io/v1beta1\nkind: Ingress\nmetadata:\n  name: web\n---\napiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: gone\n")
+	newRes := mustParse(t, "r.yaml", "apiVersion: networking.k8s.io/v1\nkind: Ingress\nmetadata:\n  name: web\n---\napiVersion: v1\nkind: Service\nmetadata:\n  name: new\n")
+	diffs := DiffResources(oldRes, newRes)
+	got := make([]string, 0, len(diffs))
+	for _, d := range diffs {
+		got = append(got, string(d.Status)+" "+d.ID)
+	}
+	want := []string{"removed ConfigMap/gone", "changed Ingress/web", "added Service/new"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("diffs = %v, want %v", got, want)
+	}
+}
+
+func TestLoadDirMissingRoot(t *testing.T) {
+	res, err := LoadDir(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(res) != 0 {
+		t.Fatalf("LoadDir = %v, %v; want no resources and no error", res, err)
+	}
+}
+
+func TestWriteDiff(t *testing.T) {
+	var buf bytes.Buffer
+	WriteDiff(&buf, []ResourceDiff{
+		{File: "a.yaml", ID: "Service/s", Status: StatusAdded},
+		{File: "a.yaml", ID: "ConfigMap/c", Status: StatusChanged, Changes: []FieldChange{
+			{Path: "data.x", Old: "1", New: "2"},
+			{Path: "data.y", New: "3"},
+		}},
+	})
+	want := "=== a.yaml\n+ Service/s\n~ ConfigMap/c\n    ~ data.x: 1 -> 2\n    + data.y: 3\n\n1 added, 0 removed, 1 changed\n"
+	if got := buf.String(); got != want {
+		t.Fatalf("WriteDiff =\n%s\nwant\n%s", got, want)
+	}
+
+	buf.Reset()
+	WriteDiff(&buf, nil)
+	if got := buf.String(); got != "No resource changes.\n" {
+		t.Fatalf("WriteDiff(nil) = %q", got)
+	}
+}
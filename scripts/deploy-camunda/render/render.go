@@ -0,0 +1,232 @@
+// Package render templates Helm releases in-process, the way `helm template`
+// does, so a scenario's manifests can be produced without a cluster or a helm
+// binary. It also compares two rendered trees resource by resource (see
+// Diff), which is what `deploy-camunda render --diff-against` prints.
+package render
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/cli/values"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Release is one Helm release as a deploy would install it.
+type Release struct {
+	// Name is the Helm release name.
+	Name string
+	// Namespace is the namespace the release is rendered for.
+	Namespace string
+	// Chart is a local chart directory, a repo chart reference ("repo/chart"
+	// together with RepoURL) or an oci:// reference.
+	Chart string
+	// Version pins remote charts; ignored for local directories.
+	Version string
+	// RepoURL is the Helm repository that serves Chart, if any.
+	RepoURL string
+	// ValuesFiles are applied in order, last wins, like repeated -f flags.
+	ValuesFiles []string
+	// Set holds key=value pairs applied after ValuesFiles, like --set.
+	Set []string
+	// Companion marks a release deployed alongside the main Camunda chart.
+	Companion bool
+}
+
+// Options tunes the simulated cluster the templates are rendered against.
+type Options struct {
+	// KubeVersion overrides the Kubernetes version reported to templates
+	// (.Capabilities.KubeVersion). Empty uses Helm's default.
+	KubeVersion string
+	// APIVersions are extra API versions reported to templates
+	// (.Capabilities.APIVersions), e.g. monitoring.coreos.com/v1.
+	APIVersions []string
+}
+
+// Template renders rel and returns its manifests as a multi-document YAML
+// stream: the release manifest followed by its hooks, in the order
+// `helm template` prints them.
+//
+// Charts may call randAlphaNum, uuidv4 or genCA, so the release is rendered
+// twice and whatever differs between the two runs is replaced by
+// RandomMarker. Rendering the same inputs therefore always gives the same
+// text, and a diff only shows changes that come from the inputs.
+func Template(ctx context.Context, rel Release, opts Options) (string, error) {
+	settings := cli.New()
+	settings.SetNamespace(rel.Namespace)
+
+	chartPath, err := locateChart(newInstall(rel), rel, settings)
+	if err != nil {
+		return "", err
+	}
+	first, err := templateOnce(ctx, rel, opts, chartPath, settings)
+	if err != nil {
+		return "", err
+	}
+	second, err := templateOnce(ctx, rel, opts, chartPath, settings)
+	if err != nil {
+		return "", err
+	}
+	return maskVolatile(first, second), nil
+}
+
+func newInstall(rel Release) *action.Install {
+	install := action.NewInstall(&action.Configuration{})
+	install.DryRun = true
+	install.DryRunOption = "client"
+	install.ClientOnly = true
+	install.Replace = true
+	install.IncludeCRDs = true
+	install.ReleaseName = rel.Name
+	install.Namespace = rel.Namespace
+	return install
+}
+
+// templateOnce runs one client-only install of the chart at chartPath. The
+// chart and values are loaded afresh because rendering mutates both.
+func templateOnce(ctx context.Context, rel Release, opts Options, chartPath string, settings *cli.EnvSettings) (string, error) {
+	install := newInstall(rel)
+	install.APIVersions = chartutil.VersionSet(opts.APIVersions)
+	if opts.KubeVersion != "" {
+		kv, err := chartutil.ParseKubeVersion(opts.KubeVersion)
+		if err != nil {
+			return "", fmt.Errorf("invalid kube version %q: %w", opts.KubeVersion, err)
+		}
+		install.KubeVersion = kv
+	}
+
+	chrt, err := loader.Load(chartPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to load chart %q: %w", rel.Chart, err)
+	}
+	if deps := chrt.Metadata.Dependencies; deps != nil {
+		if err := action.CheckDependencies(chrt, deps); err != nil {
+			return "", fmt.Errorf("chart %q has missing dependencies (run helm dependency build): %w", rel.Chart, err)
+		}
+	}
+
+	valueOpts := values.Options{ValueFiles: rel.ValuesFiles, Values: rel.Set}
+	vals, err := valueOpts.MergeValues(getter.All(settings))
+	if err != nil {
+		return "", fmt.Errorf("failed to merge values for release %q: %w", rel.Name, err)
+	}
+
+	out, err := install.RunWithContext(ctx, chrt, vals)
+	if err != nil {
+		return "", fmt.Errorf("failed to render release %q: %w", rel.Name, err)
+	}
+	return manifestStream(out), nil
+}
+
+// RandomMarker replaces template output that changes from one render to the
+// next.
+const RandomMarker = "<random>"
+
+// maskVolatile compares two renders of the same inputs line by line and
+// replaces the differing token of each differing line with RandomMarker.
+// Renders with a different line count (a random multi-line value) are
+// returned unmasked.
+func maskVolatile(a, b string) string {
+	if a == b {
+		return a
+	}
+	al, bl := strings.Split(a, "\n"), strings.Split(b, "\n")
+	if len(al) != len(bl) {
+		return a
+	}
+	for i := range al {
+		if al[i] == bl[i] {
+			continue
+		}
+		x, y := al[i], bl[i]
+		p := 0
+		for p < len(x) && p < len(y) && x[p] == y[p] {
+			p++
+		}
+		q := 0
+		for q < len(x)-p && q < len(y)-p && x[len(x)-1-q] == y[len(y)-1-q] {
+			q++
+		}
+		// Widen to whole tokens: two random strings can share leading or
+		// trailing characters by chance.
+		for p > 0 && isTokenChar(x[p-1]) {
+			p--
+		}
+		for q > 0 && isTokenChar(x[len(x)-q]) {
+			q--
+		}
+		al[i] = x[:p] + RandomMarker + x[len(x)-q:]
+	}
+	return strings.Join(al, "\n")
+}
+
+// isTokenChar reports whether c can be part of a generated value: the
+// alphabets of randAlphaNum, base64 and UUIDs.
+func isTokenChar(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || strings.IndexByte("+/=-_", c) >= 0
+}
+
+// locateChart resolves rel.Chart to a directory or archive on disk, pulling
+// remote charts into Helm's cache the way `helm template` does.
+func locateChart(install *action.Install, rel Release, settings *cli.EnvSettings) (string, error) {
+	if info, err := os.Stat(rel.Chart); err == nil && info.IsDir() {
+		return rel.Chart, nil
+	}
+	name := rel.Chart
+	if rel.RepoURL != "" {
+		// "repo/chart" names a chart in an added repo; with RepoURL set the
+		// chart is fetched from the URL directly and only its name matters.
+		name = name[strings.LastIndex(name, "/")+1:]
+		install.ChartPathOptions.RepoURL = rel.RepoURL
+	}
+	install.ChartPathOptions.Version = rel.Version
+	if registry.IsOCI(name) {
+		client, err := registry.NewClient()
+		if err != nil {
+			return "", fmt.Errorf("failed to create registry client: %w", err)
+		}
+		install.SetRegistryClient(client)
+	}
+	path, err := install.ChartPathOptions.LocateChart(name, settings)
+	if err != nil {
+		return "", fmt.Errorf("failed to locate chart %q: %w", rel.Chart, err)
+	}
+	return path, nil
+}
+
+// manifestStream joins the release manifest and its hooks. Hooks are sorted
+// by path so the output is stable across runs.
+func manifestStream(rel *release.Release) string {
+	var b strings.Builder
+	b.WriteString(strings.TrimSpace(rel.Manifest))
+	b.WriteString("\n")
+	hooks := append([]*release.Hook(nil), rel.Hooks...)
+	sort.SliceStable(hooks, func(i, j int) bool { return hooks[i].Path < hooks[j].Path })
+	for _, h := range hooks {
+		fmt.Fprintf(&b, "---\n# Source: %s\n%s\n", h.Path, strings.TrimSpace(h.Manifest))
+	}
+	return b.String()
+}
+
+// WriteRelease writes a rendered manifest stream to <dir>/<release>.yaml and
+// returns the file path.
+func WriteRelease(dir, releaseName, manifest string) (string, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create render output dir %q: %w", dir, err)
+	}
+	path := filepath.Join(dir, releaseName+".yaml")
+	if err := os.WriteFile(path, []byte(manifest), 0o644); err != nil {
+		return "", fmt.Errorf("failed to write %s: %w", path, err)
+	}
+	return path, nil
+}
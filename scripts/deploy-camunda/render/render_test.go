@@ -0,0 +1,94 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package render
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestTemplateIsDeterministicAndAppliesValues(t *testing.T) {
+	dir := t.TempDir()
+	valuesFile := filepath.Join(dir, "values.yaml")
+	if err := os.WriteFile(valuesFile, []byte("replicas: 3\n"), 0o644); err != nil {
+		t.Fatalf("write values: %v", err)
+	}
+	rel := Release{
+		Name:        "demo",
+		Namespace:   "ns1",
+		Chart:       filepath.Join("testdata", "chart"),
+		ValuesFiles: []string{valuesFile},
+		Set:         []string{"image=demo:2.0"},
+	}
+
+	first, err := Template(context.Background(), rel, Options{})
+	if err != nil {
+		t.Fatalf("Template: %v", err)
+	}
+	second, err := Template(context.Background(), rel, Options{})
+	if err != nil {
+		t.Fatalf("Template: %v", err)
+	}
+	if first != second {
+		t.Fatalf("renders differ:\n%s\n---\n%s", first, second)
+	}
+	for _, want := range []string{"replicas: 3", "image: demo:2.0", "namespace: ns1", "token: \"" + RandomMarker + "\""} {
+		if !strings.Contains(first, want) {
+			t.Errorf("manifest missing %q:\n%s", want, first)
+		}
+	}
+}
+
+func TestTemplateMissingChart(t *testing.T) {
+	_, err := Template(context.Background(), Release{Name: "x", Chart: filepath.Join(t.TempDir(), "nope")}, Options{})
+	if err == nil {
+		t.Fatal("expected error for missing chart")
+	}
+}
+
+func TestMaskVolatileWidensToWholeToken(t *testing.T) {
+	a := "kind: Secret\n  token: \"abXc12\"\n"
+	b := "kind: Secret\n  token: \"abYc12\"\n"
+	got := maskVolatile(a, b)
+	want := "kind: Secret\n  token: \"" + RandomMarker + "\"\n"
+	if got != want {
+		t.Fatalf("maskVolatile = %q, want %q", got, want)
+	}
+}
+
+func TestMaskVolatileLineCountMismatchReturnsFirst(t *testing.T) {
+	a := "one\ntwo"
+	if got := maskVolatile(a, "one\ntwo\nthree"); got != a {
+		t.Fatalf("maskVolatile = %q, want %q", got, a)
+	}
+}
+
+func TestWriteRelease(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "out")
+	path, err := WriteRelease(dir, "camunda", "kind: ConfigMap\n")
+	if err != nil {
+		t.Fatalf("WriteRelease: %v", err)
+	}
+	if path != filepath.Join(dir, "camunda.yaml") {
+		t.Fatalf("path = %q", path)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil || string(data) != "kind: ConfigMap\n" {
+		t.Fatalf("read back %q, %v", data, err)
+	}
+}
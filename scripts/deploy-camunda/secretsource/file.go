@@ -0,0 +1,172 @@
+package secretsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"scripts/deploy-camunda/config"
+	"scripts/prepare-helm-values/pkg/env"
+
+	"github.com/joho/godotenv"
+)
+
+// execTimeout bounds exec providers so an interactive prompt (e.g. a locked
+// password manager) fails instead of hanging a CI job.
+const execTimeout = 2 * time.Minute
+
+// envFileSource reads an additional KEY=value file, e.g. a shared team file
+// kept outside the repo.
+type envFileSource struct {
+	file string
+}
+
+func newEnvFile(cfg config.SecretSourceConfig) (*envFileSource, error) {
+	if cfg.File == "" {
+		return nil, errors.New("envFile secret source requires file")
+	}
+	return &envFileSource{file: cfg.File}, nil
+}
+
+func (s *envFileSource) Name() string { return "envFile " + s.file }
+
+func (s *envFileSource) Load(context.Context) (map[string]string, error) {
+	// env.ReadFile treats a missing file as empty, which suits the implicit
+	// .env but not a file the profile names explicitly.
+	if _, err := os.Stat(s.file); err != nil {
+		return nil, err
+	}
+	return env.ReadFile(s.file)
+}
+
+// sopsSource decrypts a SOPS-encrypted YAML, JSON or dotenv file with the sops
+// binary. Keys come from the usual sops configuration; AgeKeyFile pins an age
+// identity for this source via SOPS_AGE_KEY_FILE.
+type sopsSource struct {
+	file       string
+	ageKeyFile string
+}
+
+func newSOPS(cfg config.SecretSourceConfig) (*sopsSource, error) {
+	if cfg.File == "" {
+		return nil, errors.New("sops secret source requires file")
+	}
+	return &sopsSource{file: cfg.File, ageKeyFile: cfg.AgeKeyFile}, nil
+}
+
+func (s *sopsSource) Name() string { return "sops " + s.file }
+
+func (s *sopsSource) Load(ctx context.Context) (map[string]string, error) {
+	var extraEnv []string
+	if s.ageKeyFile != "" {
+		extraEnv = append(extraEnv, "SOPS_AGE_KEY_FILE="+s.ageKeyFile)
+	}
+	out, err := runCapture(ctx, "sops", []string{"--decrypt", "--output-type", "json", s.file}, extraEnv)
+	if err != nil {
+		return nil, fmt.Errorf("sops --decrypt: %w", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(out, &m); err != nil {
+		return nil, fmt.Errorf("decode sops output: %w", err)
+	}
+	delete(m, "sops") // metadata block, present when decrypting dotenv files
+	return flatten(m)
+}
+
+// execSource runs a command and parses its stdout as JSON or dotenv. This
+// covers password-manager CLIs, e.g. `op inject -i secrets.env.tpl` for
+// 1Password, without linking their SDKs.
+type execSource struct {
+	command []string
+	format  string
+	timeout time.Duration
+}
+
+func newExec(cfg config.SecretSourceConfig) (*execSource, error) {
+	if len(cfg.Command) == 0 {
+		return nil, errors.New("exec secret source requires command")
+	}
+	switch cfg.Format {
+	case "", "env", "json":
+	default:
+		return nil, fmt.Errorf("exec secret source: unknown format %q (want env or json)", cfg.Format)
+	}
+	timeout := execTimeout
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("exec secret source: invalid timeout %q: %w", cfg.Timeout, err)
+		}
+		timeout = d
+	}
+	return &execSource{command: cfg.Command, format: cfg.Format, timeout: timeout}, nil
+}
+
+// Name shows only the program, not its arguments, which may carry item
+// references the user would rather not print.
+func (s *execSource) Name() string { return "exec " + s.command[0] }
+
+func (s *execSource) Load(ctx context.Context) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+	out, err := runCapture(ctx, s.command[0], s.command[1:], nil)
+	if err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return nil, fmt.Errorf("timed out after %s", s.timeout)
+		}
+		return nil, err
+	}
+	format := s.format
+	if format == "" {
+		format = "env"
+		if strings.HasPrefix(strings.TrimSpace(string(out)), "{") {
+			format = "json"
+		}
+	}
+	if format == "json" {
+		var m map[string]any
+		if err := json.Unmarshal(out, &m); err != nil {
+			return nil, fmt.Errorf("decode JSON output: %w", err)
+		}
+		return flatten(m)
+	}
+	m, err := godotenv.UnmarshalBytes(out)
+	if err != nil {
+		// godotenv quotes the offending line; never surface it.
+		return nil, errors.New("output is not valid KEY=value lines")
+	}
+	return m, nil
+}
+
+// flatten converts a decoded JSON object into env values. Scalars are
+// stringified; nested objects and arrays are rejected because there is no
+// unambiguous env name for them.
+func flatten(m map[string]any) (map[string]string, error) {
+	out := make(map[string]string, len(m))
+	var nested []string
+	for k, v := range m {
+		switch val := v.(type) {
+		case string:
+			out[k] = val
+		case bool:
+			out[k] = strconv.FormatBool(val)
+		case float64:
+			out[k] = strconv.FormatFloat(val, 'f', -1, 64)
+		case nil:
+			out[k] = ""
+		default:
+			nested = append(nested, k)
+		}
+	}
+	if len(nested) > 0 {
+		sort.Strings(nested)
+		return nil, fmt.Errorf("values must be scalars; nested keys: %s", strings.Join(nested, ", "))
+	}
+	return out, nil
+}
@@ -0,0 +1,93 @@
+// Package secretsource loads test secrets from external stores so a
+// deploy-camunda profile can pull credentials from HashiCorp Vault, a
+// SOPS-encrypted file, a password-manager CLI or a plain env file instead of
+// requiring every developer to keep them in a local .env.
+//
+// Every backend returns a flat KEY=value map that is layered into the deploy
+// environment right after the .env file, so the rest of the tool (values
+// substitution, vault-secret-mapper, preflight) sees the secrets exactly as if
+// they had been exported. Values never reach the log; only key counts and
+// source names do.
+package secretsource
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+
+	"scripts/camunda-core/pkg/executil"
+	"scripts/camunda-core/pkg/logging"
+	"scripts/deploy-camunda/config"
+)
+
+// SecretSource is one backend that yields environment variables.
+type SecretSource interface {
+	// Name identifies the source in provenance output and errors, e.g.
+	// "vault secret/ci/camunda". It must not contain secret material.
+	Name() string
+	// Load returns the source's variables. A source that is reachable but
+	// empty returns an empty map, not an error.
+	Load(ctx context.Context) (map[string]string, error)
+}
+
+// runCapture runs a CLI and returns its stdout; it is a package var so tests
+// can fake sops and exec providers without the binaries installed.
+var runCapture = func(ctx context.Context, name string, args []string, env []string) ([]byte, error) {
+	out, err := executil.RunCommandCapture(ctx, name, args, env, "")
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) && len(exitErr.Stderr) > 0 {
+		return nil, fmt.Errorf("%w: %s", err, strings.TrimSpace(string(exitErr.Stderr)))
+	}
+	return out, err
+}
+
+// New builds the source described by cfg.
+func New(cfg config.SecretSourceConfig) (SecretSource, error) {
+	switch cfg.Type {
+	case config.SecretSourceVault:
+		return newVault(cfg)
+	case config.SecretSourceSOPS:
+		return newSOPS(cfg)
+	case config.SecretSourceExec:
+		return newExec(cfg)
+	case config.SecretSourceEnvFile:
+		return newEnvFile(cfg)
+	case "":
+		return nil, fmt.Errorf("secret source has no type (want one of %s)", strings.Join(Types(), ", "))
+	default:
+		return nil, fmt.Errorf("unknown secret source type %q (want one of %s)", cfg.Type, strings.Join(Types(), ", "))
+	}
+}
+
+// Types lists the accepted SecretSourceConfig.Type values.
+func Types() []string {
+	return []string{config.SecretSourceVault, config.SecretSourceSOPS, config.SecretSourceExec, config.SecretSourceEnvFile}
+}
+
+// LoadAll loads every configured source in order and merges the results, with
+// later sources overriding earlier ones. origins maps each key to the Name of
+// the source that set it. The first failing source aborts the load: a profile
+// that names a secret store expects its secrets, and deploying without them
+// only fails later and less clearly.
+func LoadAll(ctx context.Context, cfgs []config.SecretSourceConfig) (values, origins map[string]string, err error) {
+	values = map[string]string{}
+	origins = map[string]string{}
+	for i, cfg := range cfgs {
+		src, err := New(cfg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("secretSources[%d]: %w", i, err)
+		}
+		m, err := src.Load(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("secret source %s: %w", src.Name(), err)
+		}
+		for k, v := range m {
+			values[k] = v
+			origins[k] = src.Name()
+		}
+		logging.Logger.Debug().Str("source", src.Name()).Int("count", len(m)).Msg("Loaded secret source")
+	}
+	return values, origins, nil
+}
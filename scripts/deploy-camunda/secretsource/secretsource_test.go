@@ -0,0 +1,164 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretsource
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"scripts/deploy-camunda/config"
+)
+
+// fakeRun replaces runCapture, recording the invocation and returning out.
+func fakeRun(t *testing.T, out string, err error) *[]string {
+	t.Helper()
+	orig := runCapture
+	t.Cleanup(func() { runCapture = orig })
+	var calls []string
+	runCapture = func(_ context.Context, name string, args []string, env []string) ([]byte, error) {
+		calls = append(calls, strings.Join(append(append([]string{}, env...), append([]string{name}, args...)...), " "))
+		return []byte(out), err
+	}
+	return &calls
+}
+
+func TestSOPSDecryptsWithAgeKey(t *testing.T) {
+	calls := fakeRun(t, `{"HARBOR_PASSWORD":"s3cret","sops":{"version":"3.9.0"}}`, nil)
+	src, err := New(config.SecretSourceConfig{Type: "sops", File: "secrets.enc.yaml", AgeKeyFile: "/keys/age.txt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := src.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got["HARBOR_PASSWORD"] != "s3cret" {
+		t.Errorf("Load() = %v", got)
+	}
+	want := "SOPS_AGE_KEY_FILE=/keys/age.txt sops --decrypt --output-type json secrets.enc.yaml"
+	if len(*calls) != 1 || (*calls)[0] != want {
+		t.Errorf("calls = %q, want %q", *calls, want)
+	}
+}
+
+func TestExecParsesEnvAndJSON(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		out    string
+	}{
+		{"dotenv detected", "", "# from op inject\nTOKEN=abc\nUSER=\"me\"\n"},
+		{"json detected", "", `{"TOKEN":"abc","USER":"me"}`},
+		{"explicit env", "env", "TOKEN=abc\nUSER=me\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := fakeRun(t, tt.out, nil)
+			src, err := New(config.SecretSourceConfig{
+				Type: "exec", Command: []string{"op", "inject", "-i", "secrets.tpl"}, Format: tt.format,
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := src.Load(context.Background())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got["TOKEN"] != "abc" || got["USER"] != "me" {
+				t.Errorf("Load() = %v", got)
+			}
+			if (*calls)[0] != "op inject -i secrets.tpl" {
+				t.Errorf("ran %q", (*calls)[0])
+			}
+			if src.Name() != "exec op" {
+				t.Errorf("Name() = %q", src.Name())
+			}
+		})
+	}
+}
+
+func TestExecErrorDoesNotLeakOutput(t *testing.T) {
+	fakeRun(t, "not a valid line with SECRET_VALUE\n=", nil)
+	src, _ := New(config.SecretSourceConfig{Type: "exec", Command: []string{"op"}, Format: "env"})
+	_, err := src.Load(context.Background())
+	if err == nil {
+		t.Fatal("expected parse error")
+	}
+	if strings.Contains(err.Error(), "SECRET_VALUE") {
+		t.Errorf("error leaks output: %v", err)
+	}
+}
+
+func TestEnvFileRequiresExistingFile(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "team.env")
+	if err := os.WriteFile(path, []byte("A=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	src, _ := New(config.SecretSourceConfig{Type: "envFile", File: path})
+	got, err := src.Load(context.Background())
+	if err != nil || got["A"] != "1" {
+		t.Fatalf("Load() = %v, %v", got, err)
+	}
+
+	missing, _ := New(config.SecretSourceConfig{Type: "envFile", File: filepath.Join(dir, "absent.env")})
+	if _, err := missing.Load(context.Background()); err == nil {
+		t.Error("expected error for a missing file")
+	}
+}
+
+func TestLoadAllLaterSourcesWin(t *testing.T) {
+	dir := t.TempDir()
+	first := filepath.Join(dir, "a.env")
+	second := filepath.Join(dir, "b.env")
+	_ = os.WriteFile(first, []byte("A=1\nB=1\n"), 0o600)
+	_ = os.WriteFile(second, []byte("B=2\n"), 0o600)
+
+	values, origins, err := LoadAll(context.Background(), []config.SecretSourceConfig{
+		{Type: "envFile", File: first},
+		{Type: "envFile", File: second},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if values["A"] != "1" || values["B"] != "2" {
+		t.Errorf("values = %v", values)
+	}
+	if origins["A"] != "envFile "+first || origins["B"] != "envFile "+second {
+		t.Errorf("origins = %v", origins)
+	}
+}
+
+func TestLoadAllFailsOnFirstError(t *testing.T) {
+	fakeRun(t, "", errors.New("exit status 1: no identity matched"))
+	_, _, err := LoadAll(context.Background(), []config.SecretSourceConfig{{Type: "sops", File: "x.yaml"}})
+	if err == nil || !strings.Contains(err.Error(), "sops x.yaml") || !strings.Contains(err.Error(), "no identity matched") {
+		t.Fatalf("err = %v", err)
+	}
+
+	_, _, err = LoadAll(context.Background(), []config.SecretSourceConfig{{Type: "keychain"}})
+	if err == nil || !strings.Contains(err.Error(), "secretSources[0]") {
+		t.Fatalf("err = %v", err)
+	}
+	for _, typ := range Types() {
+		if !strings.Contains(err.Error(), typ) {
+			t.Errorf("error does not list type %q: %v", typ, err)
+		}
+	}
+}
@@ -0,0 +1,158 @@
+package secretsource
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"scripts/deploy-camunda/config"
+)
+
+// vaultHTTPTimeout bounds a single KV read; a hung Vault should fail the
+// preflight rather than stall the deploy.
+const vaultHTTPTimeout = 30 * time.Second
+
+// vaultSource reads one HashiCorp Vault KV v2 secret over the HTTP API. It
+// talks to the API directly (no vault binary, no client library) so it works
+// anywhere a token is available, including against `vault server -dev`.
+type vaultSource struct {
+	address   string
+	namespace string
+	mount     string
+	path      string
+	tokenEnv  string
+	client    *http.Client
+}
+
+func newVault(cfg config.SecretSourceConfig) (*vaultSource, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("vault secret source requires path")
+	}
+	address := cfg.Address
+	if address == "" {
+		address = os.Getenv("VAULT_ADDR")
+	}
+	if address == "" {
+		return nil, errors.New("vault secret source requires address (or VAULT_ADDR)")
+	}
+	namespace := cfg.Namespace
+	if namespace == "" {
+		namespace = os.Getenv("VAULT_NAMESPACE")
+	}
+	mount := strings.Trim(cfg.Mount, "/")
+	if mount == "" {
+		mount = "secret"
+	}
+	tokenEnv := cfg.TokenEnv
+	if tokenEnv == "" {
+		tokenEnv = "VAULT_TOKEN"
+	}
+	timeout := vaultHTTPTimeout
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil {
+			return nil, fmt.Errorf("vault secret source: invalid timeout %q: %w", cfg.Timeout, err)
+		}
+		timeout = d
+	}
+	return &vaultSource{
+		address:   strings.TrimRight(address, "/"),
+		namespace: namespace,
+		mount:     mount,
+		path:      strings.Trim(cfg.Path, "/"),
+		tokenEnv:  tokenEnv,
+		client:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+func (v *vaultSource) Name() string {
+	return "vault " + v.mount + "/" + v.path
+}
+
+// token returns the Vault token from tokenEnv, falling back to the file the
+// vault CLI writes on `vault login`.
+func (v *vaultSource) token() (string, error) {
+	if t := os.Getenv(v.tokenEnv); t != "" {
+		return t, nil
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		if b, err := os.ReadFile(filepath.Join(home, ".vault-token")); err == nil {
+			if t := strings.TrimSpace(string(b)); t != "" {
+				return t, nil
+			}
+		}
+	}
+	return "", fmt.Errorf("no Vault token: set %s or run `vault login`", v.tokenEnv)
+}
+
+func (v *vaultSource) Load(ctx context.Context) (map[string]string, error) {
+	token, err := v.token()
+	if err != nil {
+		return nil, err
+	}
+	endpoint := v.address + "/v1/" + escapePath(v.mount) + "/data/" + escapePath(v.path)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("X-Vault-Token", token)
+	req.Header.Set("X-Vault-Request", "true")
+	if v.namespace != "" {
+		req.Header.Set("X-Vault-Namespace", v.namespace)
+	}
+
+	resp, err := v.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 4<<20))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("GET %s: %s%s", endpoint, resp.Status, vaultErrors(body))
+	}
+
+	var payload struct {
+		Data struct {
+			Data map[string]any `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &payload); err != nil {
+		return nil, fmt.Errorf("decode KV v2 response: %w", err)
+	}
+	if payload.Data.Data == nil {
+		// A deleted or destroyed latest version reads back as data: null.
+		return nil, fmt.Errorf("%s has no current version (deleted or destroyed?)", v.Name())
+	}
+	return flatten(payload.Data.Data)
+}
+
+// vaultErrors formats the "errors" array of a Vault error response. Vault
+// never echoes secret data there, so it is safe to surface.
+func vaultErrors(body []byte) string {
+	var e struct {
+		Errors []string `json:"errors"`
+	}
+	if json.Unmarshal(body, &e) != nil || len(e.Errors) == 0 {
+		return ""
+	}
+	return ": " + strings.Join(e.Errors, "; ")
+}
+
+func escapePath(p string) string {
+	parts := strings.Split(p, "/")
+	for i, s := range parts {
+		parts[i] = url.PathEscape(s)
+	}
+	return strings.Join(parts, "/")
+}
@@ -0,0 +1,206 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package secretsource
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"scripts/deploy-camunda/config"
+)
+
+const devRootToken = "dev-root-token"
+
+// devVault is the Vault a test talks to. By default it is an in-process
+// stand-in for `vault server -dev` that implements the KV v2 read/write
+// endpoints on the default "secret/" mount. Setting DEPLOY_CAMUNDA_VAULT_ADDR
+// (and DEPLOY_CAMUNDA_VAULT_TOKEN, default "root") runs the same tests against
+// a real dev server, e.g. `vault server -dev -dev-root-token-id=root`.
+type devVault struct {
+	addr  string
+	token string
+}
+
+func newDevVault(t *testing.T) *devVault {
+	t.Helper()
+	if addr := os.Getenv("DEPLOY_CAMUNDA_VAULT_ADDR"); addr != "" {
+		token := os.Getenv("DEPLOY_CAMUNDA_VAULT_TOKEN")
+		if token == "" {
+			token = "root"
+		}
+		return &devVault{addr: addr, token: token}
+	}
+
+	var mu sync.Mutex
+	store := map[string]map[string]any{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != devRootToken {
+			w.WriteHeader(http.StatusForbidden)
+			_, _ = w.Write([]byte(`{"errors":["permission denied"]}`))
+			return
+		}
+		key, ok := strings.CutPrefix(r.URL.Path, "/v1/secret/data/")
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"errors":["no handler for route"]}`))
+			return
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		switch r.Method {
+		case http.MethodPost, http.MethodPut:
+			var body struct {
+				Data map[string]any `json:"data"`
+			}
+			if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			store[key] = body.Data
+			_, _ = w.Write([]byte(`{"data":{"version":1}}`))
+		case http.MethodGet:
+			data, ok := store[key]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"errors":[]}`))
+				return
+			}
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"data": map[string]any{"data": data, "metadata": map[string]any{"version": 1}},
+			})
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	}))
+	t.Cleanup(srv.Close)
+	return &devVault{addr: srv.URL, token: devRootToken}
+}
+
+// put writes a KV v2 secret through the HTTP API, like `vault kv put`.
+func (v *devVault) put(t *testing.T, path string, data map[string]any) {
+	t.Helper()
+	body, _ := json.Marshal(map[string]any{"data": data})
+	req, _ := http.NewRequest(http.MethodPost, v.addr+"/v1/secret/data/"+path, bytes.NewReader(body))
+	req.Header.Set("X-Vault-Token", v.token)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("seed %s: %v", path, err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		t.Fatalf("seed %s: %s", path, resp.Status)
+	}
+}
+
+func TestVaultLoadReadsKVv2Secret(t *testing.T) {
+	v := newDevVault(t)
+	v.put(t, "deploy-camunda-test/ci", map[string]any{
+		"HARBOR_USERNAME": "robot",
+		"HARBOR_PASSWORD": "s3cret",
+		"RETRIES":         3,
+	})
+	t.Setenv("TEST_VAULT_TOKEN", v.token)
+
+	src, err := New(config.SecretSourceConfig{
+		Type: "vault", Address: v.addr, Path: "deploy-camunda-test/ci", TokenEnv: "TEST_VAULT_TOKEN",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := src.Load(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"HARBOR_USERNAME": "robot", "HARBOR_PASSWORD": "s3cret", "RETRIES": "3"}
+	if len(got) != len(want) {
+		t.Fatalf("got %d keys, want %d: %v", len(got), len(want), got)
+	}
+	for k, w := range want {
+		if got[k] != w {
+			t.Errorf("%s = %q, want %q", k, got[k], w)
+		}
+	}
+	if src.Name() != "vault secret/deploy-camunda-test/ci" {
+		t.Errorf("Name() = %q", src.Name())
+	}
+}
+
+func TestVaultLoadErrors(t *testing.T) {
+	v := newDevVault(t)
+	v.put(t, "deploy-camunda-test/nested", map[string]any{"obj": map[string]any{"a": "b"}})
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		want  string
+	}{
+		{"bad token", "deploy-camunda-test/ci", "wrong", "403"},
+		{"missing secret", "deploy-camunda-test/absent", v.token, "404"},
+		{"nested value", "deploy-camunda-test/nested", v.token, "nested keys: obj"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("TEST_VAULT_TOKEN", tt.token)
+			src, err := New(config.SecretSourceConfig{
+				Type: "vault", Address: v.addr, Path: tt.path, TokenEnv: "TEST_VAULT_TOKEN",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = src.Load(context.Background())
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("Load() error = %v, want it to contain %q", err, tt.want)
+			}
+			if strings.Contains(err.Error(), tt.token) {
+				t.Errorf("error leaks the token: %v", err)
+			}
+		})
+	}
+}
+
+func TestVaultTokenFallsBackToTokenFile(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("VAULT_TOKEN", "")
+	if err := os.WriteFile(home+"/.vault-token", []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	src, err := newVault(config.SecretSourceConfig{Address: "http://127.0.0.1:8200", Path: "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tok, err := src.token()
+	if err != nil || tok != "from-file" {
+		t.Fatalf("token() = %q, %v; want from-file", tok, err)
+	}
+}
+
+func TestNewVaultRequiresAddressAndPath(t *testing.T) {
+	t.Setenv("VAULT_ADDR", "")
+	if _, err := New(config.SecretSourceConfig{Type: "vault", Path: "x"}); err == nil {
+		t.Error("expected error without address")
+	}
+	if _, err := New(config.SecretSourceConfig{Type: "vault", Address: "http://x"}); err == nil {
+		t.Error("expected error without path")
+	}
+}
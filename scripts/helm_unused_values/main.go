@@ -5,7 +5,10 @@ import (
 	"os"
 	"path/filepath"
 
+	"camunda.com/helmunusedvalues/pkg/ast"
 	"camunda.com/helmunusedvalues/pkg/config"
+	"camunda.com/helmunusedvalues/pkg/helpers"
+	"camunda.com/helmunusedvalues/pkg/keys"
 	"camunda.com/helmunusedvalues/pkg/output"
 	"camunda.com/helmunusedvalues/pkg/patterns"
 	"camunda.com/helmunusedvalues/pkg/search"
@@ -40,16 +43,34 @@ Progress indicators are displayed during long-running operations.`,
 		},
 	}
 
+	helpersCmd := &cobra.Command{
+		Use:   "helpers <chart_dir> [chart_dir...]",
+		Short: "Check for unreferenced, duplicated and drifting named templates",
+		Long: `Builds the call graph of named templates (define blocks) in each chart and reports
+helpers that no template reaches, helpers within a chart that have identical bodies,
+and, when several charts are given, helpers whose body differs between them.`,
+		Example: "  helm-unused-values helpers charts/camunda-platform-8.8 charts/camunda-platform-8.9 charts/camunda-platform-8.10",
+		Args:    cobra.MinimumNArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			if err := runHelpers(cfg, args); err != nil {
+				fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+				os.Exit(1)
+			}
+		},
+	}
+	rootCmd.AddCommand(helpersCmd)
+
 	// Add command line flags
-	rootCmd.Flags().BoolVar(&cfg.NoColors, "no-colors", false, "Disable colored output")
+	rootCmd.PersistentFlags().BoolVar(&cfg.NoColors, "no-colors", false, "Disable colored output")
 	rootCmd.Flags().BoolVar(&cfg.ShowAllKeys, "show-all-keys", false, "Show all keys (used and unused), not just unused ones")
-	rootCmd.Flags().BoolVar(&cfg.JSONOutput, "json", false, "Output results in JSON format (useful for CI)")
-	rootCmd.Flags().IntVar(&cfg.ExitCodeOnUnused, "exit-code", 0, "Set exit code when unused values are found (default: 0)")
-	rootCmd.Flags().BoolVar(&cfg.QuietMode, "quiet", false, "Suppress all output except results and errors")
+	rootCmd.PersistentFlags().BoolVar(&cfg.JSONOutput, "json", false, "Output results in JSON format (useful for CI)")
+	rootCmd.PersistentFlags().IntVar(&cfg.ExitCodeOnUnused, "exit-code", 0, "Set exit code when unused values (or unreferenced helpers) are found (default: 0)")
+	rootCmd.PersistentFlags().BoolVar(&cfg.QuietMode, "quiet", false, "Suppress all output except results and errors")
 	rootCmd.Flags().StringVar(&cfg.FilterPattern, "filter", "", "Only show keys that match the specified pattern (works with --show-all-keys)")
-	rootCmd.Flags().BoolVar(&cfg.Debug, "debug", false, "Enable verbose debug logging")
+	rootCmd.PersistentFlags().BoolVar(&cfg.Debug, "debug", false, "Enable verbose debug logging")
 	rootCmd.Flags().StringVar(&cfg.SearchTool, "search-tool", "", "Search tool to use: 'ripgrep' or 'grep' (default: use ripgrep if available)")
 	rootCmd.Flags().IntVar(&cfg.Parallelism, "parallelism", 0, "Number of parallel workers (0 = auto based on CPU cores)")
+	rootCmd.Flags().StringVar(&cfg.Analyzer, "analyzer", "pattern", "Key usage analyzer: 'pattern' (grep + regex patterns), 'ast' (parsed templates) or 'combined' (used if either finds it)")
 
 	// Execute command
 	if err := rootCmd.Execute(); err != nil {
@@ -69,34 +90,20 @@ func run(cfg *config.Config) error {
 		return fmt.Errorf("invalid templates directory: %w", err)
 	}
 
+	switch cfg.Analyzer {
+	case "pattern", "ast", "combined":
+	default:
+		return fmt.Errorf("invalid analyzer %q: must be pattern, ast or combined", cfg.Analyzer)
+	}
+
 	depOk, missing := utils.CheckDependencies()
 	if !depOk {
 		display.PrintError(fmt.Sprintf("Missing required dependencies: %v", missing))
 		return fmt.Errorf("missing required dependencies")
 	}
 
-	ripgrepAvailable := utils.DetectRipgrep()
-
-	switch cfg.SearchTool {
-	case "grep":
-		cfg.UseRipgrep = false
-		display.PrintInfo("Using grep as specified")
-	case "ripgrep":
-		if !ripgrepAvailable {
-			display.PrintWarning("Ripgrep was specified but not found, falling back to grep")
-			cfg.UseRipgrep = false
-		} else {
-			cfg.UseRipgrep = true
-			display.PrintSuccess("Using ripgrep as specified")
-		}
-	default:
-		// Auto-detect
-		cfg.UseRipgrep = ripgrepAvailable
-		if cfg.UseRipgrep {
-			display.PrintSuccess("Using ripgrep for faster searching")
-		} else {
-			display.PrintWarning("Ripgrep not found, using grep instead")
-		}
+	if cfg.Analyzer != "ast" {
+		selectSearchTool(cfg, display)
 	}
 
 	patternRegistry := patterns.New()
@@ -116,7 +123,7 @@ func run(cfg *config.Config) error {
 		display.PrintInfo("Extracting keys from values.yaml...")
 	}
 
-	var keys []string
+	var valueKeys []string
 	var err error
 
 	if showProgress {
@@ -130,9 +137,9 @@ func run(cfg *config.Config) error {
 			progressbar.OptionSpinnerType(14),
 		)
 
-		keys, err = keyExtractor.ExtractKeysWithProgress(valuesFile, bar)
+		valueKeys, err = keyExtractor.ExtractKeysWithProgress(valuesFile, bar)
 	} else {
-		keys, err = keyExtractor.ExtractKeys(valuesFile)
+		valueKeys, err = keyExtractor.ExtractKeys(valuesFile)
 	}
 
 	if err != nil {
@@ -141,26 +148,42 @@ func run(cfg *config.Config) error {
 
 	if cfg.FilterPattern != "" {
 		display.PrintInfo(fmt.Sprintf("Filtering results to only show keys matching: %s", cfg.FilterPattern))
-		keys = keyExtractor.FilterKeys(keys, cfg.FilterPattern)
+		valueKeys = keyExtractor.FilterKeys(valueKeys, cfg.FilterPattern)
 	}
 
 	// Report total keys found
-	display.PrintWarning(fmt.Sprintf("\nTotal keys found: %d", len(keys)))
-
-	// Create finder
-	finder := search.NewFinder(cfg.TemplatesDir, patternRegistry, cfg.UseRipgrep, display)
-
-	// Set parallelism if configured
-	if cfg.Parallelism > 0 {
-		display.PrintInfo(fmt.Sprintf("Using %d parallel workers", cfg.Parallelism))
-		finder.Parallelism = cfg.Parallelism
-	}
+	display.PrintWarning(fmt.Sprintf("\nTotal keys found: %d", len(valueKeys)))
 
 	// Analyze key usage
 	display.PrintInfo("Analyzing key usage:")
-	usages, err := finder.FindUnusedKeys(keys, showProgress)
-	if err != nil {
-		return fmt.Errorf("find unused keys: %w", err)
+	var usages []keys.KeyUsage
+	if cfg.Analyzer != "pattern" {
+		display.PrintInfo("Parsing templates for AST analysis...")
+		analyzer := ast.NewAnalyzer(cfg.TemplatesDir, display)
+		usages, err = analyzer.FindUnusedKeys(valueKeys)
+		if err != nil {
+			return fmt.Errorf("analyze templates: %w", err)
+		}
+	}
+	if cfg.Analyzer != "ast" {
+		// Create finder
+		finder := search.NewFinder(cfg.TemplatesDir, patternRegistry, cfg.UseRipgrep, display)
+
+		// Set parallelism if configured
+		if cfg.Parallelism > 0 {
+			display.PrintInfo(fmt.Sprintf("Using %d parallel workers", cfg.Parallelism))
+			finder.Parallelism = cfg.Parallelism
+		}
+
+		patternUsages, err := finder.FindUnusedKeys(valueKeys, showProgress)
+		if err != nil {
+			return fmt.Errorf("find unused keys: %w", err)
+		}
+		if usages == nil {
+			usages = patternUsages
+		} else {
+			usages = keys.Merge(usages, patternUsages)
+		}
 	}
 
 	// Create reporter
@@ -184,3 +207,65 @@ func run(cfg *config.Config) error {
 
 	return nil
 }
+
+func runHelpers(cfg *config.Config, chartDirs []string) error {
+	if cfg.JSONOutput {
+		cfg.NoColors = true
+		cfg.QuietMode = true
+	}
+	display := output.NewDisplay(cfg.NoColors, cfg.QuietMode, cfg.Debug)
+	for _, chartDir := range chartDirs {
+		if err := utils.ValidateDirectory(filepath.Join(chartDir, "templates")); err != nil {
+			return fmt.Errorf("invalid chart directory: %w", err)
+		}
+	}
+
+	display.PrintInfo("Analyzing named templates...")
+	report, err := helpers.Analyze(chartDirs)
+	if err != nil {
+		return fmt.Errorf("analyze helpers: %w", err)
+	}
+
+	reporter := output.NewReporter(display, cfg.JSONOutput, cfg.ShowAllKeys)
+	if err := reporter.ReportHelpers(report); err != nil {
+		return fmt.Errorf("report helpers: %w", err)
+	}
+
+	unreferenced := 0
+	for _, chart := range report.Charts {
+		unreferenced += len(chart.Unreferenced)
+	}
+	if unreferenced > 0 && cfg.ExitCodeOnUnused != 0 {
+		display.DebugLog(fmt.Sprintf("Exiting with code %d (unreferenced helpers found)", cfg.ExitCodeOnUnused))
+		os.Exit(cfg.ExitCodeOnUnused)
+	}
+
+	return nil
+}
+
+// selectSearchTool decides between ripgrep and grep for the pattern analyzer.
+func selectSearchTool(cfg *config.Config, display *output.Display) {
+	ripgrepAvailable := utils.DetectRipgrep()
+
+	switch cfg.SearchTool {
+	case "grep":
+		cfg.UseRipgrep = false
+		display.PrintInfo("Using grep as specified")
+	case "ripgrep":
+		if !ripgrepAvailable {
+			display.PrintWarning("Ripgrep was specified but not found, falling back to grep")
+			cfg.UseRipgrep = false
+		} else {
+			cfg.UseRipgrep = true
+			display.PrintSuccess("Using ripgrep as specified")
+		}
+	default:
+		// Auto-detect
+		cfg.UseRipgrep = ripgrepAvailable
+		if cfg.UseRipgrep {
+			display.PrintSuccess("Using ripgrep for faster searching")
+		} else {
+			display.PrintWarning("Ripgrep not found, using grep instead")
+		}
+	}
+}
@@ -0,0 +1,148 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"camunda.com/helmunusedvalues/pkg/keys"
+	"camunda.com/helmunusedvalues/pkg/output"
+	"camunda.com/helmunusedvalues/pkg/templates"
+)
+
+// Analyzer decides key usage by parsing every template with text/template/parse
+// and evaluating .Values references symbolically, instead of grepping for
+// patterns. It follows values through $ variables, with/range scopes,
+// index/dig/get lookups and named-template calls (include/template), including
+// dicts built with dict/set/deepCopy and passed as the template argument.
+type Analyzer struct {
+	TemplatesDir string
+	Display      *output.Display
+
+	templates *templates.Templates
+	refs      []reference
+}
+
+func NewAnalyzer(templatesDir string, display *output.Display) *Analyzer {
+	return &Analyzer{
+		TemplatesDir: templatesDir,
+		Display:      display,
+	}
+}
+
+// reference is one place where a values path is read by a template.
+type reference struct {
+	path []string // "*" matches any single segment (range element, non-literal index)
+	kind refKind
+	// via names the mechanism that reached the path: "" for a literal
+	// .Values.a.b chain, otherwise "variable", "with", "range", "include",
+	// "index", "dig" or "get".
+	via      string
+	location string
+}
+
+type refKind int
+
+const (
+	// refValue means the value itself is consumed (printed, piped into a
+	// function, toYaml'd), so every key below the path counts as used.
+	refValue refKind = iota
+	// refTest means the value is only tested (if/with/range condition,
+	// hasKey), so only a key at exactly the path counts as used.
+	refTest
+)
+
+// FindUnusedKeys parses the templates and reports the usage of every key.
+func (a *Analyzer) FindUnusedKeys(valueKeys []string) ([]keys.KeyUsage, error) {
+	parsed, err := templates.Parse(a.TemplatesDir)
+	if err != nil {
+		return nil, err
+	}
+	a.templates = parsed
+	a.refs = nil
+	a.evaluate()
+	a.Display.DebugLog(fmt.Sprintf("AST analyzer: %d files, %d named templates, %d references",
+		len(parsed.Files), len(parsed.Defines), len(a.refs)))
+
+	byFirst := make(map[string][]reference)
+	for _, ref := range a.refs {
+		byFirst[ref.path[0]] = append(byFirst[ref.path[0]], ref)
+	}
+
+	usages := make([]keys.KeyUsage, 0, len(valueKeys))
+	for _, key := range valueKeys {
+		segments := strings.Split(key, ".")
+		var candidates []reference
+		candidates = append(candidates, byFirst[segments[0]]...)
+		candidates = append(candidates, byFirst["*"]...)
+		usages = append(usages, usageOf(key, segments, candidates))
+	}
+	return usages, nil
+}
+
+// usageOf picks the most specific reference covering key: an exact literal
+// chain, then an exact indirect reference, then a consumed parent subtree.
+func usageOf(key string, segments []string, candidates []reference) keys.KeyUsage {
+	var exactDirect, exactIndirect, subtree []reference
+	for _, ref := range candidates {
+		switch {
+		case matchPath(ref.path, segments, false):
+			if ref.via == "" {
+				exactDirect = append(exactDirect, ref)
+			} else {
+				exactIndirect = append(exactIndirect, ref)
+			}
+		case ref.kind == refValue && matchPath(ref.path, segments, true):
+			subtree = append(subtree, ref)
+		}
+	}
+
+	usage := keys.KeyUsage{Key: key}
+	switch {
+	case len(exactDirect) > 0:
+		usage.IsUsed = true
+		usage.UsageType = "direct"
+		usage.Locations = locations(exactDirect)
+	case len(exactIndirect) > 0:
+		usage.IsUsed = true
+		usage.UsageType = "pattern"
+		usage.PatternName = "ast:" + exactIndirect[0].via
+		usage.Locations = locations(exactIndirect)
+	case len(subtree) > 0:
+		usage.IsUsed = true
+		usage.UsageType = "pattern"
+		usage.PatternName = "ast:subtree"
+		usage.ParentKey = strings.Join(subtree[0].path, ".")
+		usage.Locations = locations(subtree)
+	default:
+		usage.UsageType = "unused"
+	}
+	return usage
+}
+
+// matchPath reports whether ref equals key, or with prefix set, whether ref is
+// a proper prefix of key.
+func matchPath(ref, key []string, prefix bool) bool {
+	if len(ref) > len(key) || (!prefix && len(ref) != len(key)) || (prefix && len(ref) == len(key)) {
+		return false
+	}
+	for i, segment := range ref {
+		if segment != "*" && segment != key[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func locations(refs []reference) []string {
+	seen := make(map[string]bool)
+	var result []string
+	for _, ref := range refs {
+		if !seen[ref.location] {
+			seen[ref.location] = true
+			result = append(result, ref.location)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
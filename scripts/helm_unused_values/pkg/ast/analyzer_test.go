@@ -0,0 +1,97 @@
+package ast_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"camunda.com/helmunusedvalues/pkg/ast"
+	"camunda.com/helmunusedvalues/pkg/output"
+)
+
+func TestFindUnusedKeys(t *testing.T) {
+	tests := []struct {
+		key             string
+		expectedType    string
+		expectedPattern string
+	}{
+		{key: "direct.name", expectedType: "direct"},
+		{key: "commented.key", expectedType: "unused"},
+		{key: "labels.team", expectedType: "direct"},
+		{key: "podAnnotations.example.com/owner", expectedType: "pattern", expectedPattern: "ast:subtree"},
+		{key: "viaVariable.replicas", expectedType: "pattern", expectedPattern: "ast:variable"},
+		{key: "withBlock.inner", expectedType: "pattern", expectedPattern: "ast:with"},
+		{key: "rootFromWith", expectedType: "direct"},
+		{key: "feature.enabled", expectedType: "unused"},
+		{key: "items.0.name", expectedType: "pattern", expectedPattern: "ast:range"},
+		{key: "items.0.unused", expectedType: "unused"},
+		{key: "indexed.a-b", expectedType: "pattern", expectedPattern: "ast:index"},
+		{key: "dug.x.y", expectedType: "pattern", expectedPattern: "ast:dig"},
+		{key: "global.image.repository", expectedType: "pattern", expectedPattern: "ast:include"},
+		{key: "app.image.repository", expectedType: "pattern", expectedPattern: "ast:include"},
+		{key: "app.image.tag", expectedType: "pattern", expectedPattern: "ast:include"},
+		{key: "app.image.digest", expectedType: "unused"},
+		{key: "sub.image.tag", expectedType: "pattern", expectedPattern: "ast:include"},
+		{key: "orphan.name", expectedType: "direct"},
+	}
+
+	valueKeys := make([]string, 0, len(tests))
+	for _, tc := range tests {
+		valueKeys = append(valueKeys, tc.key)
+	}
+
+	analyzer := ast.NewAnalyzer("./testdata/templates", output.NewDisplay(true, true, false))
+	usages, err := analyzer.FindUnusedKeys(valueKeys)
+	if err != nil {
+		t.Fatalf("FindUnusedKeys: %v", err)
+	}
+	if len(usages) != len(tests) {
+		t.Fatalf("Expected %d usages, got %d", len(tests), len(usages))
+	}
+
+	for i, tc := range tests {
+		t.Run(tc.key, func(t *testing.T) {
+			usage := usages[i]
+			if usage.Key != tc.key {
+				t.Fatalf("Expected key %s, got %s", tc.key, usage.Key)
+			}
+			if usage.UsageType != tc.expectedType {
+				t.Errorf("Expected usage type %q, got %q (locations %v)", tc.expectedType, usage.UsageType, usage.Locations)
+			}
+			if usage.PatternName != tc.expectedPattern {
+				t.Errorf("Expected pattern %q, got %q", tc.expectedPattern, usage.PatternName)
+			}
+			if usage.IsUsed != (tc.expectedType != "unused") {
+				t.Errorf("Expected IsUsed = %v, got %v", tc.expectedType != "unused", usage.IsUsed)
+			}
+			if usage.IsUsed && len(usage.Locations) == 0 {
+				t.Errorf("Expected locations for used key")
+			}
+		})
+	}
+}
+
+func TestFindUnusedKeysLocations(t *testing.T) {
+	analyzer := ast.NewAnalyzer("./testdata/templates", output.NewDisplay(true, true, false))
+	usages, err := analyzer.FindUnusedKeys([]string{"labels.team"})
+	if err != nil {
+		t.Fatalf("FindUnusedKeys: %v", err)
+	}
+
+	location := usages[0].Locations[0]
+	if !strings.HasPrefix(location, "testdata/templates/_helpers.tpl:6:") {
+		t.Errorf("Expected location in _helpers.tpl line 6, got %s", location)
+	}
+}
+
+func TestFindUnusedKeysParseError(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "broken.yaml"), []byte("{{ if .Values.a }}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	analyzer := ast.NewAnalyzer(dir, output.NewDisplay(true, true, false))
+	if _, err := analyzer.FindUnusedKeys([]string{"a"}); err == nil {
+		t.Error("Expected an error for an unterminated if")
+	}
+}
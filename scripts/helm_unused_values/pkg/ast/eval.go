@@ -0,0 +1,509 @@
+package ast
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// maxCallDepth bounds nested include/template calls so recursive helpers
+// terminate.
+const maxCallDepth = 32
+
+type valueKind int
+
+const (
+	valUnknown valueKind = iota
+	valRoot              // chart root context ($ at top level): .Values, .Release, ...
+	valValues            // a node of .Values
+	valDict              // a dict built in the template
+)
+
+// value is the symbolic result of evaluating a template expression.
+type value struct {
+	kind valueKind
+	path []string
+	via  string
+	// fields holds dict entries, or keys overridden with set on a values node.
+	fields map[string]value
+}
+
+func (v value) field(name string) value {
+	switch v.kind {
+	case valRoot:
+		if name == "Values" {
+			return value{kind: valValues, via: v.via}
+		}
+	case valValues:
+		if f, ok := v.fields[name]; ok {
+			return f
+		}
+		path := make([]string, len(v.path), len(v.path)+1)
+		copy(path, v.path)
+		return value{kind: valValues, path: append(path, name), via: v.via}
+	case valDict:
+		return v.fields[name]
+	}
+	return value{}
+}
+
+func (v value) fieldChain(names []string) value {
+	for _, name := range names {
+		v = v.field(name)
+	}
+	return v
+}
+
+// set returns a copy of v with name bound to field, mirroring sprig's set on
+// a dict or a (deep-copied) values node.
+func (v value) set(name string, field value) value {
+	if v.kind != valValues && v.kind != valDict {
+		return v
+	}
+	fields := make(map[string]value, len(v.fields)+1)
+	for k, f := range v.fields {
+		fields[k] = f
+	}
+	fields[name] = field
+	v.fields = fields
+	return v
+}
+
+// withVia marks values nodes reached through an indirection. The first
+// mechanism wins; the root context is never marked, so .Values chains below
+// it stay literal.
+func withVia(v value, via string) value {
+	switch v.kind {
+	case valValues:
+		if v.via == "" {
+			v.via = via
+		}
+		if len(v.fields) > 0 {
+			v.fields = fieldsWithVia(v.fields, via)
+		}
+	case valDict:
+		v.fields = fieldsWithVia(v.fields, via)
+	}
+	return v
+}
+
+func fieldsWithVia(fields map[string]value, via string) map[string]value {
+	result := make(map[string]value, len(fields))
+	for k, f := range fields {
+		result[k] = withVia(f, via)
+	}
+	return result
+}
+
+func (v value) signature() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d:%s:%s", v.kind, strings.Join(v.path, "."), v.via)
+	for _, k := range sortedKeys(v.fields) {
+		fmt.Fprintf(&b, "{%s=%s}", k, v.fields[k].signature())
+	}
+	return b.String()
+}
+
+func sortedKeys(fields map[string]value) []string {
+	names := make([]string, 0, len(fields))
+	for k := range fields {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// scope holds template variables; with, range and if open a child scope that
+// ends at {{ end }}.
+type scope struct {
+	vars   map[string]value
+	parent *scope
+}
+
+func (s *scope) child() *scope {
+	return &scope{vars: make(map[string]value), parent: s}
+}
+
+func (s *scope) declare(name string, v value) {
+	s.vars[name] = v
+}
+
+func (s *scope) assign(name string, v value) {
+	for sc := s; sc != nil; sc = sc.parent {
+		if _, ok := sc.vars[name]; ok {
+			sc.vars[name] = v
+			return
+		}
+	}
+	s.vars[name] = v
+}
+
+func (s *scope) lookup(name string) value {
+	for sc := s; sc != nil; sc = sc.parent {
+		if v, ok := sc.vars[name]; ok {
+			return v
+		}
+	}
+	return value{}
+}
+
+type evaluator struct {
+	a       *Analyzer
+	tree    *parse.Tree
+	loc     string
+	depth   int
+	called  map[string]bool
+	visited map[string]bool
+}
+
+// evaluate walks every template file with the chart root as dot and records
+// the values references it finds.
+func (a *Analyzer) evaluate() {
+	e := &evaluator{a: a, called: make(map[string]bool), visited: make(map[string]bool)}
+	root := value{kind: valRoot}
+	for _, tree := range a.templates.Files {
+		e.run(tree, root)
+	}
+
+	// Named templates never called with a literal name (dynamic include names,
+	// helpers only used by parent charts) get the root context, the usual
+	// Helm convention, so their references are not lost.
+	names := make([]string, 0, len(a.templates.Defines))
+	for name := range a.templates.Defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if !e.called[name] {
+			e.call(name, root)
+		}
+	}
+}
+
+func (e *evaluator) run(tree *parse.Tree, dot value) {
+	prevTree, prevLoc := e.tree, e.loc
+	e.tree = tree
+	e.walk(tree.Root, dot, &scope{vars: map[string]value{"$": dot}})
+	e.tree, e.loc = prevTree, prevLoc
+}
+
+func (e *evaluator) call(name string, arg value) {
+	tree, ok := e.a.templates.Defines[name]
+	if !ok {
+		// Defined outside the templates directory (e.g. a library chart):
+		// assume the whole argument is used.
+		e.consume(arg, refValue)
+		return
+	}
+	e.called[name] = true
+	key := name + "\x00" + arg.signature()
+	if e.visited[key] || e.depth >= maxCallDepth {
+		return
+	}
+	e.visited[key] = true
+	e.depth++
+	e.run(tree, withVia(arg, "include"))
+	e.depth--
+}
+
+// at sets the location recorded for references found while evaluating n.
+func (e *evaluator) at(n parse.Node) {
+	location, context := e.tree.ErrorContext(n)
+	if i := strings.LastIndex(location, ":"); i >= 0 {
+		location = location[:i]
+	}
+	e.loc = location + ":" + context
+}
+
+func (e *evaluator) record(v value, kind refKind) {
+	e.a.refs = append(e.a.refs, reference{path: v.path, kind: kind, via: v.via, location: e.loc})
+}
+
+// consume records v as read. The whole .Values map passed around (e.g.
+// toYaml .Values) is ignored rather than marking every key used.
+func (e *evaluator) consume(v value, kind refKind) {
+	switch v.kind {
+	case valValues:
+		if len(v.path) > 0 {
+			e.record(v, kind)
+		}
+		for _, k := range sortedKeys(v.fields) {
+			e.consume(v.fields[k], kind)
+		}
+	case valDict:
+		for _, k := range sortedKeys(v.fields) {
+			e.consume(v.fields[k], kind)
+		}
+	}
+}
+
+func (e *evaluator) walk(node parse.Node, dot value, sc *scope) {
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			e.walk(child, dot, sc)
+		}
+	case *parse.ActionNode:
+		e.at(n)
+		v := e.pipe(n.Pipe, dot, sc, false)
+		if len(n.Pipe.Decl) == 0 {
+			e.consume(v, refValue)
+		}
+	case *parse.IfNode:
+		e.at(n)
+		inner := sc.child()
+		e.consume(e.pipe(n.Pipe, dot, inner, true), refTest)
+		e.walk(n.List, dot, inner)
+		e.walk(n.ElseList, dot, sc.child())
+	case *parse.WithNode:
+		e.at(n)
+		inner := sc.child()
+		v := e.pipe(n.Pipe, dot, inner, true)
+		e.consume(v, refTest)
+		e.walk(n.List, withVia(v, "with"), inner)
+		e.walk(n.ElseList, dot, sc.child())
+	case *parse.RangeNode:
+		e.at(n)
+		inner := sc.child()
+		v := e.commands(n.Pipe, dot, inner, true)
+		e.consume(v, refTest)
+		elem := withVia(v.field("*"), "range")
+		switch len(n.Pipe.Decl) {
+		case 1:
+			inner.declare(n.Pipe.Decl[0].Ident[0], elem)
+		case 2:
+			inner.declare(n.Pipe.Decl[0].Ident[0], value{})
+			inner.declare(n.Pipe.Decl[1].Ident[0], elem)
+		}
+		e.walk(n.List, elem, inner)
+		e.walk(n.ElseList, dot, sc.child())
+	case *parse.TemplateNode:
+		e.at(n)
+		var arg value
+		if n.Pipe != nil {
+			arg = e.pipe(n.Pipe, dot, sc, false)
+		}
+		e.call(n.Name, arg)
+	}
+}
+
+// pipe evaluates a pipeline and binds its declared variable, if any.
+func (e *evaluator) pipe(p *parse.PipeNode, dot value, sc *scope, test bool) value {
+	v := e.commands(p, dot, sc, test)
+	if p != nil && len(p.Decl) > 0 {
+		name := p.Decl[0].Ident[0]
+		if p.IsAssign {
+			sc.assign(name, withVia(v, "variable"))
+		} else {
+			sc.declare(name, withVia(v, "variable"))
+		}
+	}
+	return v
+}
+
+func (e *evaluator) commands(p *parse.PipeNode, dot value, sc *scope, test bool) value {
+	if p == nil {
+		return value{}
+	}
+	var v value
+	for i, cmd := range p.Cmds {
+		var prev *value
+		if i > 0 {
+			prev = &v
+		}
+		v = e.command(cmd, dot, sc, prev, test)
+	}
+	return v
+}
+
+// command evaluates one pipeline stage. prev is the previous stage's result,
+// passed as the final argument.
+func (e *evaluator) command(cmd *parse.CommandNode, dot value, sc *scope, prev *value, test bool) value {
+	if id, ok := cmd.Args[0].(*parse.IdentifierNode); ok {
+		nodes := cmd.Args[1:]
+		args := make([]value, 0, len(nodes)+1)
+		for _, n := range nodes {
+			args = append(args, e.arg(n, dot, sc))
+		}
+		if prev != nil {
+			args = append(args, *prev)
+		}
+		return e.function(id.Ident, nodes, args, sc, test)
+	}
+
+	v := e.arg(cmd.Args[0], dot, sc)
+	if len(cmd.Args) == 1 && prev == nil {
+		return v
+	}
+	// A method call such as .Files.Get "x": the receiver is not a values
+	// node, but its arguments are read.
+	for _, n := range cmd.Args[1:] {
+		e.consume(e.arg(n, dot, sc), refValue)
+	}
+	if prev != nil {
+		e.consume(*prev, refValue)
+	}
+	return value{}
+}
+
+func (e *evaluator) arg(node parse.Node, dot value, sc *scope) value {
+	switch n := node.(type) {
+	case *parse.DotNode:
+		return dot
+	case *parse.FieldNode:
+		return dot.fieldChain(n.Ident)
+	case *parse.VariableNode:
+		return sc.lookup(n.Ident[0]).fieldChain(n.Ident[1:])
+	case *parse.ChainNode:
+		return e.arg(n.Node, dot, sc).fieldChain(n.Field)
+	case *parse.PipeNode:
+		return e.pipe(n, dot, sc, false)
+	case *parse.IdentifierNode:
+		return e.function(n.Ident, nil, nil, sc, false)
+	}
+	return value{}
+}
+
+// literal returns the text of a string or number literal argument.
+func literal(nodes []parse.Node, i int) (string, bool) {
+	if i >= len(nodes) {
+		return "", false
+	}
+	switch n := nodes[i].(type) {
+	case *parse.StringNode:
+		return n.Text, true
+	case *parse.NumberNode:
+		return n.Text, true
+	}
+	return "", false
+}
+
+// key returns the literal lookup key at position i, or "*" when it is computed;
+// a computed key is itself read.
+func (e *evaluator) key(nodes []parse.Node, args []value, i int) string {
+	if k, ok := literal(nodes, i); ok {
+		return k
+	}
+	e.consume(args[i], refValue)
+	return "*"
+}
+
+// function models the functions that move values around; any other function
+// reads all of its arguments and returns an unknown value.
+func (e *evaluator) function(name string, nodes []parse.Node, args []value, sc *scope, test bool) value {
+	switch name {
+	case "index":
+		if len(args) == 0 {
+			return value{}
+		}
+		v := args[0]
+		for i := 1; i < len(args); i++ {
+			v = v.field(e.key(nodes, args, i))
+		}
+		return withVia(v, "index")
+	case "get":
+		if len(args) != 2 {
+			break
+		}
+		return withVia(args[0].field(e.key(nodes, args, 1)), "get")
+	case "dig":
+		if len(args) < 3 {
+			break
+		}
+		e.consume(args[len(args)-2], refValue)
+		v := args[len(args)-1]
+		for i := 0; i < len(args)-2; i++ {
+			v = v.field(e.key(nodes, args, i))
+		}
+		return withVia(v, "dig")
+	case "hasKey":
+		if len(args) != 2 {
+			break
+		}
+		e.consume(args[0].field(e.key(nodes, args, 1)), refTest)
+		return value{}
+	case "dict":
+		v := value{kind: valDict, fields: make(map[string]value)}
+		for i := 0; i+1 < len(args); i += 2 {
+			if k, ok := literal(nodes, i); ok {
+				v.fields[k] = args[i+1]
+			} else {
+				e.consume(args[i], refValue)
+				e.consume(args[i+1], refValue)
+			}
+		}
+		return v
+	case "set":
+		if len(args) != 3 {
+			break
+		}
+		k, ok := literal(nodes, 1)
+		if !ok || (args[0].kind != valValues && args[0].kind != valDict) {
+			e.consume(args[2], refValue)
+			return args[0]
+		}
+		v := args[0].set(k, args[2])
+		// set mutates its dict, so {{ $_ := set $ctx "k" v }} changes $ctx.
+		if len(nodes) == 0 {
+			return v
+		}
+		if variable, ok := nodes[0].(*parse.VariableNode); ok && len(variable.Ident) == 1 {
+			sc.assign(variable.Ident[0], v)
+		}
+		return v
+	case "deepCopy", "mustDeepCopy":
+		if len(args) != 1 {
+			break
+		}
+		return args[0]
+	case "default", "required":
+		if len(args) == 0 {
+			return value{}
+		}
+		for _, arg := range args[:len(args)-1] {
+			e.consume(arg, refValue)
+		}
+		return args[len(args)-1]
+	case "include":
+		name, ok := literal(nodes, 0)
+		if !ok {
+			break
+		}
+		var arg value
+		if len(args) > 1 {
+			arg = args[1]
+		}
+		e.call(name, arg)
+		return value{}
+	case "tpl":
+		if len(args) != 2 {
+			break
+		}
+		// The second argument is the rendering context, not a value read.
+		e.consume(args[0], refValue)
+		return value{}
+	case "and", "or":
+		kind := refValue
+		if test {
+			kind = refTest
+		}
+		for _, arg := range args {
+			e.consume(arg, kind)
+		}
+		return value{}
+	case "not", "empty", "kindIs", "typeIs":
+		for _, arg := range args {
+			e.consume(arg, refTest)
+		}
+		return value{}
+	}
+
+	for _, arg := range args {
+		e.consume(arg, refValue)
+	}
+	return value{}
+}
@@ -0,0 +1,15 @@
+{{- define "test.image" -}}
+{{ .overlay.repository | default .base.repository }}:{{ get .overlay "tag" }}
+{{- end -}}
+
+{{- define "test.labels" -}}
+team: {{ .Values.labels.team }}
+{{- end -}}
+
+{{- define "test.subImage" -}}
+{{ .Values.image.tag }}
+{{- end -}}
+
+{{- define "test.orphan" -}}
+{{ .Values.orphan.name }}
+{{- end -}}
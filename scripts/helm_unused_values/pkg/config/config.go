@@ -13,6 +13,7 @@ type Config struct {
 	UseRipgrep       bool
 	SearchTool       string // Preferred search tool (ripgrep or grep)
 	Parallelism      int    // Number of parallel workers (0 = auto)
+	Analyzer         string // Key usage analyzer: pattern, ast or combined
 }
 
 // New creates a new configuration with default values
@@ -21,5 +22,6 @@ func New() *Config {
 		ExitCodeOnUnused: 0,  // Default: Don't fail on unused values
 		SearchTool:       "", // Empty means auto-detect (ripgrep if available)
 		Parallelism:      0,  // Default: Auto (set based on CPU cores)
+		Analyzer:         "pattern",
 	}
 }
@@ -0,0 +1,367 @@
+package helpers
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+
+	"camunda.com/helmunusedvalues/pkg/templates"
+)
+
+// Report is the result of analysing named templates in one or more charts.
+type Report struct {
+	Charts []ChartReport `json:"charts"`
+	// Drift lists helpers defined in several charts with different bodies.
+	Drift []Drift `json:"drift"`
+}
+
+// ChartReport holds the findings for a single chart.
+type ChartReport struct {
+	Chart        string   `json:"chart"`
+	Defines      int      `json:"defines"`
+	Unreferenced []Helper `json:"unreferenced"`
+	// Duplicates groups helpers of this chart that share an identical body.
+	Duplicates [][]Helper `json:"duplicates"`
+	// UnresolvedIncludes are include calls whose template name is computed
+	// without any literal part, so the helper they reach is unknown.
+	UnresolvedIncludes []string `json:"unresolved_includes"`
+}
+
+// Helper is a named template.
+type Helper struct {
+	Name     string `json:"name"`
+	Location string `json:"location"`
+	// Callers lists the helpers that still reference an unreferenced helper.
+	// They are unreferenced themselves; empty means nothing references it.
+	Callers []string `json:"callers,omitempty"`
+}
+
+// Drift is a helper whose body differs between charts.
+type Drift struct {
+	Name string `json:"name"`
+	// Variants groups the charts that share the same body.
+	Variants [][]string `json:"variants"`
+}
+
+// Analyze builds the named-template call graph of every chart directory and
+// compares helper bodies across them.
+func Analyze(chartDirs []string) (Report, error) {
+	report := Report{Charts: []ChartReport{}, Drift: []Drift{}}
+	bodies := make(map[string]map[string]string) // helper -> chart -> body
+	var charts []string
+
+	for _, chartDir := range chartDirs {
+		chart := filepath.Base(filepath.Clean(chartDir))
+		parsed, err := templates.Parse(filepath.Join(chartDir, "templates"))
+		if err != nil {
+			return Report{}, fmt.Errorf("chart %s: %w", chart, err)
+		}
+
+		report.Charts = append(report.Charts, analyzeChart(chart, parsed))
+		charts = append(charts, chart)
+		for name, tree := range parsed.Defines {
+			if bodies[name] == nil {
+				bodies[name] = make(map[string]string)
+			}
+			bodies[name][chart] = body(tree)
+		}
+	}
+
+	report.Drift = drift(charts, bodies)
+	return report, nil
+}
+
+// callees are the templates one template refers to.
+type callees struct {
+	names    []string
+	patterns []*regexp.Regexp
+}
+
+func analyzeChart(chart string, parsed *templates.Templates) ChartReport {
+	result := ChartReport{
+		Chart:              chart,
+		Defines:            len(parsed.Defines),
+		Unreferenced:       []Helper{},
+		Duplicates:         [][]Helper{},
+		UnresolvedIncludes: []string{},
+	}
+
+	graph := make(map[*parse.Tree]callees)
+	for _, tree := range parsed.Files {
+		graph[tree] = collectCallees(tree, &result.UnresolvedIncludes)
+	}
+	names := sortedNames(parsed.Defines)
+	for _, name := range names {
+		tree := parsed.Defines[name]
+		graph[tree] = collectCallees(tree, &result.UnresolvedIncludes)
+	}
+
+	// Everything reachable from a template file is in use.
+	reached := make(map[string]bool)
+	queue := append([]*parse.Tree(nil), parsed.Files...)
+	visit := func(name string) {
+		if tree, ok := parsed.Defines[name]; ok && !reached[name] {
+			reached[name] = true
+			queue = append(queue, tree)
+		}
+	}
+	for len(queue) > 0 {
+		c := graph[queue[0]]
+		queue = queue[1:]
+		for _, name := range c.names {
+			visit(name)
+		}
+		for _, pattern := range c.patterns {
+			for _, name := range names {
+				if pattern.MatchString(name) {
+					visit(name)
+				}
+			}
+		}
+	}
+
+	for _, name := range names {
+		if reached[name] {
+			continue
+		}
+		helper := helperOf(name, parsed)
+		for _, caller := range names {
+			if calls(graph[parsed.Defines[caller]], name) {
+				helper.Callers = append(helper.Callers, caller)
+			}
+		}
+		result.Unreferenced = append(result.Unreferenced, helper)
+	}
+
+	byBody := make(map[string][]Helper)
+	var order []string
+	for _, name := range names {
+		b := body(parsed.Defines[name])
+		if b == "" {
+			continue
+		}
+		if _, ok := byBody[b]; !ok {
+			order = append(order, b)
+		}
+		byBody[b] = append(byBody[b], helperOf(name, parsed))
+	}
+	for _, b := range order {
+		if len(byBody[b]) > 1 {
+			result.Duplicates = append(result.Duplicates, byBody[b])
+		}
+	}
+
+	return result
+}
+
+func calls(c callees, name string) bool {
+	for _, n := range c.names {
+		if n == name {
+			return true
+		}
+	}
+	for _, pattern := range c.patterns {
+		if pattern.MatchString(name) {
+			return true
+		}
+	}
+	return false
+}
+
+func drift(charts []string, bodies map[string]map[string]string) []Drift {
+	result := []Drift{}
+	for _, name := range sortedKeys(bodies) {
+		byChart := bodies[name]
+		if len(byChart) < 2 {
+			continue
+		}
+
+		var variants [][]string
+		variantOf := make(map[string]int)
+		for _, chart := range charts {
+			b, ok := byChart[chart]
+			if !ok {
+				continue
+			}
+			i, seen := variantOf[b]
+			if !seen {
+				i = len(variants)
+				variantOf[b] = i
+				variants = append(variants, nil)
+			}
+			variants[i] = append(variants[i], chart)
+		}
+		if len(variants) > 1 {
+			result = append(result, Drift{Name: name, Variants: variants})
+		}
+	}
+	return result
+}
+
+// collectCallees finds the include and template calls in tree. A computed
+// include name built with print/printf becomes a pattern over its literal
+// parts; one without any literal part is recorded in unresolved.
+func collectCallees(tree *parse.Tree, unresolved *[]string) callees {
+	var c callees
+	inspect(tree.Root, func(n parse.Node) {
+		switch n := n.(type) {
+		case *parse.TemplateNode:
+			c.names = append(c.names, n.Name)
+		case *parse.CommandNode:
+			id, ok := n.Args[0].(*parse.IdentifierNode)
+			if !ok || id.Ident != "include" || len(n.Args) < 2 {
+				return
+			}
+			if name, ok := n.Args[1].(*parse.StringNode); ok {
+				c.names = append(c.names, name.Text)
+				return
+			}
+			if pattern := namePattern(n.Args[1]); pattern != nil {
+				c.patterns = append(c.patterns, pattern)
+				return
+			}
+			*unresolved = append(*unresolved, location(tree, n)+": "+n.String())
+		}
+	})
+	return c
+}
+
+// namePattern turns (printf "%s.fullname" .component) or (print "a." $x)
+// into a regexp, or returns nil when the name has no literal part.
+func namePattern(node parse.Node) *regexp.Regexp {
+	pipe, ok := node.(*parse.PipeNode)
+	if !ok || len(pipe.Cmds) != 1 {
+		return nil
+	}
+	args := pipe.Cmds[0].Args
+	id, ok := args[0].(*parse.IdentifierNode)
+	if !ok || len(args) < 2 {
+		return nil
+	}
+
+	var expr string
+	literal := false
+	switch id.Ident {
+	case "printf":
+		format, ok := args[1].(*parse.StringNode)
+		if !ok {
+			return nil
+		}
+		for i, part := range regexp.MustCompile(`%[-+# 0-9.]*[a-zA-Z]`).Split(format.Text, -1) {
+			if i > 0 {
+				expr += ".*"
+			}
+			expr += regexp.QuoteMeta(part)
+			literal = literal || part != ""
+		}
+	case "print":
+		for _, arg := range args[1:] {
+			if s, ok := arg.(*parse.StringNode); ok {
+				expr += regexp.QuoteMeta(s.Text)
+				literal = literal || s.Text != ""
+			} else {
+				expr += ".*"
+			}
+		}
+	default:
+		return nil
+	}
+
+	if !literal {
+		return nil
+	}
+	return regexp.MustCompile("^" + expr + "$")
+}
+
+// inspect calls fn for every node below node.
+func inspect(node parse.Node, fn func(parse.Node)) {
+	if node == nil {
+		return
+	}
+	fn(node)
+	switch n := node.(type) {
+	case *parse.ListNode:
+		if n == nil {
+			return
+		}
+		for _, child := range n.Nodes {
+			inspect(child, fn)
+		}
+	case *parse.ActionNode:
+		inspect(n.Pipe, fn)
+	case *parse.IfNode:
+		inspectBranch(&n.BranchNode, fn)
+	case *parse.WithNode:
+		inspectBranch(&n.BranchNode, fn)
+	case *parse.RangeNode:
+		inspectBranch(&n.BranchNode, fn)
+	case *parse.TemplateNode:
+		if n.Pipe != nil {
+			inspect(n.Pipe, fn)
+		}
+	case *parse.PipeNode:
+		if n == nil {
+			return
+		}
+		for _, cmd := range n.Cmds {
+			inspect(cmd, fn)
+		}
+	case *parse.CommandNode:
+		for _, arg := range n.Args {
+			inspect(arg, fn)
+		}
+	case *parse.ChainNode:
+		inspect(n.Node, fn)
+	}
+}
+
+func inspectBranch(n *parse.BranchNode, fn func(parse.Node)) {
+	inspect(n.Pipe, fn)
+	inspect(n.List, fn)
+	inspect(n.ElseList, fn)
+}
+
+func helperOf(name string, parsed *templates.Templates) Helper {
+	loc, ok := parsed.DefineLocations[name]
+	if !ok {
+		tree := parsed.Defines[name]
+		loc = location(tree, tree.Root)
+	}
+	return Helper{Name: name, Location: loc}
+}
+
+// location returns "file:line" for node.
+func location(tree *parse.Tree, node parse.Node) string {
+	loc, _ := tree.ErrorContext(node)
+	if i := strings.LastIndex(loc, ":"); i >= 0 {
+		loc = loc[:i]
+	}
+	return loc
+}
+
+// body is the define's content with whitespace collapsed, so indentation-only
+// differences do not count as drift.
+func body(tree *parse.Tree) string {
+	return strings.Join(strings.Fields(tree.Root.String()), " ")
+}
+
+func sortedNames(defines map[string]*parse.Tree) []string {
+	names := make([]string, 0, len(defines))
+	for name := range defines {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedKeys(m map[string]map[string]string) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
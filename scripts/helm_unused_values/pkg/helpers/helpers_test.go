@@ -0,0 +1,62 @@
+package helpers_test
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"camunda.com/helmunusedvalues/pkg/helpers"
+)
+
+func TestAnalyze(t *testing.T) {
+	report, err := helpers.Analyze([]string{"./testdata/chart-a", "./testdata/chart-b"})
+	if err != nil {
+		t.Fatalf("Analyze: %v", err)
+	}
+	if len(report.Charts) != 2 {
+		t.Fatalf("Expected 2 chart reports, got %d", len(report.Charts))
+	}
+
+	chartA := report.Charts[0]
+	if chartA.Chart != "chart-a" || chartA.Defines != 8 {
+		t.Errorf("Expected chart-a with 8 helpers, got %s with %d", chartA.Chart, chartA.Defines)
+	}
+
+	var unreferenced []string
+	for _, helper := range chartA.Unreferenced {
+		unreferenced = append(unreferenced, helper.Name)
+	}
+	expected := []string{"dead.callee", "dead.caller", "test.commented"}
+	if !reflect.DeepEqual(unreferenced, expected) {
+		t.Errorf("Expected unreferenced helpers %v, got %v", expected, unreferenced)
+	}
+	if callers := chartA.Unreferenced[0].Callers; !reflect.DeepEqual(callers, []string{"dead.caller"}) {
+		t.Errorf("Expected dead.callee to be called by dead.caller, got %v", callers)
+	}
+	if len(chartA.Unreferenced[2].Callers) != 0 {
+		t.Errorf("Expected a helper mentioned only in a comment to have no callers, got %v", chartA.Unreferenced[2].Callers)
+	}
+	if location := chartA.Unreferenced[2].Location; location != "testdata/chart-a/templates/_helpers.tpl:2" {
+		t.Errorf("Expected location of the define line, got %s", location)
+	}
+
+	if len(chartA.Duplicates) != 1 || len(chartA.Duplicates[0]) != 2 ||
+		chartA.Duplicates[0][0].Name != "web.labels" || chartA.Duplicates[0][1].Name != "worker.labels" {
+		t.Errorf("Expected web.labels and worker.labels as duplicates, got %+v", chartA.Duplicates)
+	}
+
+	if len(chartA.UnresolvedIncludes) != 1 || !strings.Contains(chartA.UnresolvedIncludes[0], ".Values.helperName") {
+		t.Errorf("Expected the computed include to be unresolved, got %v", chartA.UnresolvedIncludes)
+	}
+
+	if chartB := report.Charts[1]; len(chartB.Unreferenced) != 0 {
+		t.Errorf("Expected no unreferenced helpers in chart-b, got %+v", chartB.Unreferenced)
+	}
+
+	expectedDrift := []helpers.Drift{
+		{Name: "test.suffix", Variants: [][]string{{"chart-a"}, {"chart-b"}}},
+	}
+	if !reflect.DeepEqual(report.Drift, expectedDrift) {
+		t.Errorf("Expected drift %+v, got %+v", expectedDrift, report.Drift)
+	}
+}
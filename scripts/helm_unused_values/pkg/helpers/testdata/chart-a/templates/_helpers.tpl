@@ -0,0 +1,32 @@
+{{/* Usage: {{ include "test.commented" . }} */}}
+{{- define "test.commented" -}}
+commented
+{{- end -}}
+
+{{- define "test.fullname" -}}
+{{ .Release.Name }}-{{ include "test.suffix" . }}
+{{- end -}}
+
+{{- define "test.suffix" -}}
+app
+{{- end -}}
+
+{{- define "web.fullname" -}}
+web
+{{- end -}}
+
+{{- define "dead.caller" -}}
+{{ include "dead.callee" . }}
+{{- end -}}
+
+{{- define "dead.callee" -}}
+callee
+{{- end -}}
+
+{{- define "web.labels" -}}
+app: {{ .Chart.Name }}
+{{- end -}}
+
+{{- define "worker.labels" -}}
+app:   {{ .Chart.Name }}
+{{- end -}}
@@ -0,0 +1,7 @@
+{{- define "test.fullname" -}}
+{{ .Release.Name }}-{{ include "test.suffix" . }}
+{{- end -}}
+
+{{- define "test.suffix" -}}
+application
+{{- end -}}
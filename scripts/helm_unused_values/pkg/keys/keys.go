@@ -9,3 +9,21 @@ type KeyUsage struct {
 	PatternName string
 	ChildKeys   []string
 }
+
+// Merge combines the results of two analyzers over the same keys. A key is
+// used if either analyzer found it used; primary's details win when both did.
+func Merge(primary, secondary []KeyUsage) []KeyUsage {
+	byKey := make(map[string]KeyUsage, len(secondary))
+	for _, usage := range secondary {
+		byKey[usage.Key] = usage
+	}
+
+	merged := make([]KeyUsage, 0, len(primary))
+	for _, usage := range primary {
+		if other, ok := byKey[usage.Key]; ok && !usage.IsUsed && other.IsUsed {
+			usage = other
+		}
+		merged = append(merged, usage)
+	}
+	return merged
+}
@@ -0,0 +1,103 @@
+package output
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"camunda.com/helmunusedvalues/pkg/helpers"
+	"github.com/fatih/color"
+)
+
+// HelpersJSONResult represents the JSON output format of the helpers mode
+type HelpersJSONResult struct {
+	Timestamp string `json:"timestamp"`
+	helpers.Report
+}
+
+// ReportHelpers reports unreferenced, duplicated and drifting named templates
+func (r *Reporter) ReportHelpers(report helpers.Report) error {
+	if r.JSONOutput {
+		jsonData, err := json.MarshalIndent(HelpersJSONResult{
+			Timestamp: time.Now().UTC().Format(time.RFC3339),
+			Report:    report,
+		}, "", "  ")
+		if err != nil {
+			return fmt.Errorf("error generating JSON: %w", err)
+		}
+		r.Display.PrintJson(string(jsonData))
+		return nil
+	}
+
+	for _, chart := range report.Charts {
+		r.reportChartHelpers(chart)
+	}
+	if len(report.Charts) > 1 {
+		r.reportDrift(report.Drift)
+	}
+	return nil
+}
+
+func (r *Reporter) reportChartHelpers(chart helpers.ChartReport) {
+	r.Display.PrintHighlight(fmt.Sprintf("%s (%d helpers)", chart.Chart, chart.Defines))
+	fmt.Println()
+
+	if len(chart.Unreferenced) == 0 {
+		r.Display.PrintSuccess("No unreferenced helpers found.")
+	} else {
+		r.Display.PrintBold(fmt.Sprintf("Unreferenced helpers (%d):", len(chart.Unreferenced)))
+		for _, helper := range chart.Unreferenced {
+			line := fmt.Sprintf("  %s → %s", helper.Name, helper.Location)
+			if len(helper.Callers) > 0 {
+				line += fmt.Sprintf(" (only called by %s)", strings.Join(helper.Callers, ", "))
+			}
+			r.Display.PrintError(line)
+		}
+	}
+	fmt.Println()
+
+	if len(chart.Duplicates) > 0 {
+		r.Display.PrintBold(fmt.Sprintf("Helpers with identical bodies (%d groups):", len(chart.Duplicates)))
+		for _, group := range chart.Duplicates {
+			for i, helper := range group {
+				prefix := "    "
+				if i == 0 {
+					prefix = "  - "
+				}
+				r.Display.PrintWarning(fmt.Sprintf("%s%s → %s", prefix, helper.Name, helper.Location))
+			}
+		}
+		fmt.Println()
+	}
+
+	if len(chart.UnresolvedIncludes) > 0 {
+		r.Display.PrintBold(fmt.Sprintf("Includes with computed names (%d), their targets may be reported as unreferenced:", len(chart.UnresolvedIncludes)))
+		for _, include := range chart.UnresolvedIncludes {
+			r.Display.PrintWarning("  " + include)
+		}
+		fmt.Println()
+	}
+}
+
+func (r *Reporter) reportDrift(drift []helpers.Drift) {
+	if len(drift) == 0 {
+		r.Display.PrintSuccess("No helper drift between charts.")
+		fmt.Println()
+		return
+	}
+
+	r.Display.PrintBold(fmt.Sprintf("Helpers that differ between charts (%d):", len(drift)))
+	cyan := color.New(color.FgCyan)
+	for _, d := range drift {
+		variants := make([]string, 0, len(d.Variants))
+		for _, charts := range d.Variants {
+			variants = append(variants, "["+strings.Join(charts, ", ")+"]")
+		}
+		if !r.Display.QuietMode {
+			fmt.Printf("  %s ", d.Name)
+			cyan.Println(strings.Join(variants, " vs "))
+		}
+	}
+	fmt.Println()
+}
@@ -0,0 +1,78 @@
+package templates
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"text/template/parse"
+)
+
+// Templates is a parsed templates directory.
+type Templates struct {
+	// Files holds one tree per template file, named by its path.
+	Files []*parse.Tree
+	// Defines holds the named templates declared with define. Like Helm, a
+	// later definition of the same name wins.
+	Defines map[string]*parse.Tree
+	// DefineLocations holds the "file:line" of each define in Defines.
+	DefineLocations map[string]string
+}
+
+var defineRegex = regexp.MustCompile(`\{\{-?\s*define\s+"([^"]+)"`)
+
+// Parse parses every file below dir. Function names are not checked, so
+// sprig and Helm functions need no registration.
+func Parse(dir string) (*Templates, error) {
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("walk templates: %w", err)
+	}
+	sort.Strings(paths)
+
+	templates := &Templates{
+		Defines:         make(map[string]*parse.Tree),
+		DefineLocations: make(map[string]string),
+	}
+	for _, path := range paths {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read template %s: %w", path, err)
+		}
+		treeSet := make(map[string]*parse.Tree)
+		tree := parse.New(path)
+		tree.Mode = parse.SkipFuncCheck
+		if _, err := tree.Parse(string(content), "", "", treeSet); err != nil {
+			return nil, fmt.Errorf("parse template %s: %w", path, err)
+		}
+		names := make([]string, 0, len(treeSet))
+		for name := range treeSet {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if name == path {
+				templates.Files = append(templates.Files, treeSet[name])
+				continue
+			}
+			templates.Defines[name] = treeSet[name]
+		}
+		for _, match := range defineRegex.FindAllSubmatchIndex(content, -1) {
+			line := 1 + strings.Count(string(content[:match[0]]), "\n")
+			templates.DefineLocations[string(content[match[2]:match[3]])] = fmt.Sprintf("%s:%d", path, line)
+		}
+	}
+	return templates, nil
+}
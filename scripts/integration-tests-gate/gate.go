@@ -17,7 +17,10 @@ package main
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"time"
+
+	"scripts/camunda-core/pkg/failures"
 )
 
 type ghClient interface {
@@ -27,7 +30,40 @@ type ghClient interface {
 	AttemptStatus(runID string, attempt int) (string, error)
 	AttemptConclusion(runID string, attempt int) (string, error)
 	AttemptJobConclusions(runID string, attempt int) ([]string, error)
+	FailedJobLogs(runID string, attempt int) ([]jobLog, error)
+	AttemptJobs(runID string, attempt int) ([]job, error)
 	Rerun(runID string) error
+	Dispatch(workflow, ref string, inputs map[string]string) error
+	DispatchedRuns(workflow, ref string) ([]dispatchedRun, error)
+	CreateCheckRun(sha string, check checkRun) error
+}
+
+// jobLog is the raw log of a failed or cancelled job.
+type jobLog struct {
+	Name string
+	Log  string
+}
+
+// job is a job of a run attempt. Conclusion is "pending" while it runs.
+type job struct {
+	Name       string
+	Conclusion string
+}
+
+// dispatchedRun is a workflow_dispatch run of the matrix workflow.
+type dispatchedRun struct {
+	ID      string
+	HeadSHA string
+	// Title is the run's display title (its run-name).
+	Title string
+}
+
+// checkRun is a completed check-run posted on the gated commit.
+type checkRun struct {
+	Name       string
+	Conclusion string
+	Title      string
+	Summary    string
 }
 
 type Gate struct {
@@ -49,12 +85,26 @@ type Gate struct {
 	RerunTries   int
 	RerunBackoff time.Duration
 
+	// HeadRef is the branch the matrix run was triggered on. When set, a
+	// retry dispatches the matrix workflow on it for just the failed
+	// integration cells instead of re-running the whole run.
+	HeadRef string
+	// CellRetryBudget caps the targeted reruns of one cell on the head
+	// SHA; PRRetryBudget caps the targeted cell reruns on the branch.
+	CellRetryBudget int
+	PRRetryBudget   int
+	// SummaryCheck names the check-run that records the attempt history.
+	// Empty disables it.
+	SummaryCheck string
+
 	Sleep func(time.Duration)
 	// Logf prints human-readable progress to stderr.
 	Logf func(format string, args ...any)
 	// Cmdf prints GitHub Actions workflow commands (::group::,
 	// ::endgroup::, ::warning::) to stdout.
 	Cmdf func(format string, args ...any)
+
+	attempts []attemptRecord
 }
 
 func ResolveSHA(event, prHeadSHA, mgHeadSHA string) (string, error) {
@@ -74,6 +124,19 @@ func ResolveSHA(event, prHeadSHA, mgHeadSHA string) (string, error) {
 	}
 }
 
+// ResolveHeadRef returns the branch the matrix run for event was triggered
+// on, or "" when it is unknown.
+func ResolveHeadRef(event, prHeadRef, mgHeadRef string) string {
+	switch event {
+	case "pull_request", "pull_request_target":
+		return prHeadRef
+	case "merge_group":
+		return strings.TrimPrefix(mgHeadRef, "refs/heads/")
+	default:
+		return ""
+	}
+}
+
 // ResolveDispatchOverride remaps the event and SHAs when the gate is
 // triggered manually via workflow_dispatch with an OVERRIDE_SHA. If
 // overrideSHA is empty the inputs pass through unchanged.
@@ -191,6 +254,12 @@ func (g *Gate) Run(event, prHeadSHA, mgHeadSHA string) error {
 	if err != nil {
 		return err
 	}
+	err = g.run(event, sha)
+	g.postSummary(sha, err)
+	return err
+}
+
+func (g *Gate) run(event, sha string) error {
 	g.Logf("gating event=%s sha=%s", event, sha)
 
 	g.Cmdf("::group::discover")
@@ -212,6 +281,7 @@ func (g *Gate) Run(event, prHeadSHA, mgHeadSHA string) error {
 	g.Cmdf("::group::watch attempt %d", current)
 	watchErr := g.watchAndDecide(runID, runURL, current)
 	g.Cmdf("::endgroup::")
+	g.record(runLink(runID, runURL, current), "whole run", resultOf(watchErr))
 	if watchErr == nil {
 		return nil
 	}
@@ -219,6 +289,14 @@ func (g *Gate) Run(event, prHeadSHA, mgHeadSHA string) error {
 		return watchErr
 	}
 
+	if g.HeadRef != "" {
+		err := g.RetryFailedCells(sha, runID, current)
+		if !errors.Is(err, errWholeRunRetry) {
+			return err
+		}
+		g.Logf("falling back to a retry of the whole run")
+	}
+
 	g.Logf("triggering retry of failed jobs on %s", runURL)
 	g.Cmdf("::group::rerun")
 	err = g.RerunWithBackoff(runID)
@@ -247,6 +325,7 @@ func (g *Gate) Run(event, prHeadSHA, mgHeadSHA string) error {
 		return err
 	}
 	g.Logf("attempt %d conclusion: %s", next, final)
+	g.record(runLink(runID, runURL, next), "whole run", final)
 	if final == "success" {
 		return nil
 	}
@@ -272,7 +351,7 @@ func (g *Gate) watchAndDecide(runID, runURL string, attempt int) error {
 	case "success":
 		return nil
 	case "failure":
-		return errNeedsRetry
+		return g.classifyFailures(runID, attempt)
 	}
 	// A job killed by timeout-minutes is recorded as cancelled, and one
 	// cancelled job makes the whole run conclude cancelled, hiding any job
@@ -284,9 +363,63 @@ func (g *Gate) watchAndDecide(runID, runURL string, attempt int) error {
 	}
 	for _, c := range jobs {
 		if c == "failure" || c == "cancelled" {
-			g.Logf("attempt %d concluded %s but job conclusions include %s; retrying", attempt, conclusion, c)
-			return errNeedsRetry
+			g.Logf("attempt %d concluded %s but job conclusions include %s", attempt, conclusion, c)
+			return g.classifyFailures(runID, attempt)
 		}
 	}
 	return fmt.Errorf("%w: %s", ErrNotRetryable, conclusion)
 }
+
+// classifyFailures matches the failure point of the attempt's failed jobs
+// (see failureTail) against the failure-signature catalogue. A job that
+// failed for a cause another attempt cannot fix (a chart bug, rejected
+// credentials, an exhausted quota) makes the attempt unretryable.
+// Unclassified failures keep the blind retry, as do attempts whose logs
+// cannot be read.
+func (g *Gate) classifyFailures(runID string, attempt int) error {
+	logs, err := g.Client.FailedJobLogs(runID, attempt)
+	if err != nil {
+		g.Logf("attempt %d: reading failed job logs: %v; retrying", attempt, err)
+		return errNeedsRetry
+	}
+	var blocking []string
+	for _, job := range logs {
+		m, ok := failures.Classify(failures.SourceLog, failureTail(job.Log))
+		if !ok {
+			g.Logf("job %q: unclassified failure", job.Name)
+			continue
+		}
+		g.Logf("job %q: %s (%s, retryable=%t): %s", job.Name, m.ID, m.Category, m.Retryable, m.Line)
+		if !m.Retryable {
+			g.Cmdf("::error title=%s::%s: %s", m.ID, job.Name, m.Remediation)
+			blocking = append(blocking, fmt.Sprintf("%s (%s)", job.Name, m.ID))
+		}
+	}
+	if len(blocking) > 0 {
+		return fmt.Errorf("%w: %s", ErrNotRetryable, strings.Join(blocking, ", "))
+	}
+	g.Logf("attempt %d: failures are retryable; retrying", attempt)
+	return errNeedsRetry
+}
+
+// failureTailLines is how many log lines up to the failing step's error are
+// classified.
+const failureTailLines = 100
+
+// failureTail returns the part of a job log that shows why the job failed:
+// the lines leading up to the "##[error]Process completed with exit code"
+// line GitHub writes when a step fails, or the end of the log when there is
+// none. A whole job log carries incidental matches (an "unauthorized" probe
+// that is retried, a rejected optional field) that would otherwise block a
+// retry the actual failure allows.
+func failureTail(log string) string {
+	lines := strings.Split(strings.TrimRight(log, "\n"), "\n")
+	end := len(lines)
+	for i, line := range lines {
+		if strings.Contains(line, "##[error]Process completed with exit code") {
+			end = i + 1
+			break
+		}
+	}
+	return strings.Join(lines[max(0, end-failureTailLines):end], "\n")
+}
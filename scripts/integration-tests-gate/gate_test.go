@@ -34,8 +34,21 @@ type fakeClient struct {
 
 	jobConclusionsByAttempt map[int][]string
 	jobConclusionsErr       map[int]error
+	failedJobLogsByAttempt  map[int][]jobLog
+	failedJobLogsByRun      map[string][]jobLog
+	failedJobLogsErr        map[int]error
 	rerunQueue              []error
 	rerunCalls              int
+
+	// jobsByRun holds the jobs of every run; a dispatched run takes the
+	// next ID of nextRunIDs.
+	jobsByRun   map[string][]job
+	dispatched  []dispatchedRun
+	nextRunIDs  []string
+	headSHA     string
+	dispatchErr error
+	dispatches  []map[string]string
+	checkRuns   []checkRun
 }
 
 type findRunResp struct {
@@ -97,6 +110,45 @@ func (f *fakeClient) AttemptJobConclusions(_ string, attempt int) ([]string, err
 	}
 	return f.jobConclusionsByAttempt[attempt], nil
 }
+func (f *fakeClient) FailedJobLogs(runID string, attempt int) ([]jobLog, error) {
+	if err, ok := f.failedJobLogsErr[attempt]; ok {
+		return nil, err
+	}
+	if logs, ok := f.failedJobLogsByRun[runID]; ok {
+		return logs, nil
+	}
+	return f.failedJobLogsByAttempt[attempt], nil
+}
+func (f *fakeClient) AttemptJobs(runID string, _ int) ([]job, error) {
+	jobs, ok := f.jobsByRun[runID]
+	if !ok {
+		return nil, fmt.Errorf("no jobs for run %s", runID)
+	}
+	return jobs, nil
+}
+func (f *fakeClient) Dispatch(_, _ string, inputs map[string]string) error {
+	if f.dispatchErr != nil {
+		return f.dispatchErr
+	}
+	if len(f.nextRunIDs) == 0 {
+		f.t.Fatalf("nextRunIDs exhausted")
+	}
+	f.dispatches = append(f.dispatches, inputs)
+	run := dispatchedRun{ID: f.nextRunIDs[0], HeadSHA: f.headSHA}
+	if cells := inputs["gate-cells"]; cells != "" {
+		run.Title = gateRunPrefix + cells
+	}
+	f.nextRunIDs = f.nextRunIDs[1:]
+	f.dispatched = append([]dispatchedRun{run}, f.dispatched...)
+	return nil
+}
+func (f *fakeClient) DispatchedRuns(_, _ string) ([]dispatchedRun, error) {
+	return f.dispatched, nil
+}
+func (f *fakeClient) CreateCheckRun(_ string, check checkRun) error {
+	f.checkRuns = append(f.checkRuns, check)
+	return nil
+}
 func (f *fakeClient) Rerun(string) error {
 	f.rerunCalls++
 	if len(f.rerunQueue) == 0 {
@@ -823,3 +875,342 @@ func TestWatchAndDecide_JobInspectionErrorIsNotRetryable(t *testing.T) {
 		t.Fatalf("expected ErrNotRetryable, got %v", err)
 	}
 }
+
+func TestRun_ChartBugIsNotRetried(t *testing.T) {
+	c := &fakeClient{
+		t:                   t,
+		findRunQueue:        []findRunResp{{id: "100"}},
+		runURL:              "url",
+		attemptsQueue:       attemptList(1),
+		statusByAttempt:     map[int][]statusResp{1: statusList("completed")},
+		conclusionByAttempt: map[int]string{1: "failure"},
+		failedJobLogsByAttempt: map[int][]jobLog{1: {
+			{Name: "8.9 - eske - install - pr - gke", Log: "Error: INSTALLATION FAILED: YAML parse error on camunda-platform/templates/zeebe/configmap.yaml"},
+			{Name: "8.9 - oske - install - pr - gke", Log: "Error: read tcp 10.0.0.1:443: connection reset by peer"},
+		}},
+	}
+	var cmds []string
+	g := newTestGate(c)
+	g.Cmdf = func(format string, args ...any) { cmds = append(cmds, fmt.Sprintf(format, args...)) }
+	err := g.Run("pull_request", "sha", "")
+	if !errors.Is(err, ErrNotRetryable) {
+		t.Fatalf("expected ErrNotRetryable, got %v", err)
+	}
+	if !strings.Contains(err.Error(), "8.9 - eske - install - pr - gke (chart-render)") {
+		t.Errorf("error should name the blocking job and cause, got %v", err)
+	}
+	if c.rerunCalls != 0 {
+		t.Fatalf("expected no rerun for a chart bug, got %d", c.rerunCalls)
+	}
+	found := false
+	for _, cmd := range cmds {
+		found = found || strings.HasPrefix(cmd, "::error title=chart-render::")
+	}
+	if !found {
+		t.Errorf("expected an ::error annotation for the chart bug, got %v", cmds)
+	}
+}
+
+func TestWatchAndDecide_TransientFailureIsRetryable(t *testing.T) {
+	c := newCompletedFake(t, "failure", nil)
+	c.failedJobLogsByAttempt = map[int][]jobLog{1: {
+		{Name: "8.9 - eske - install - pr - gke", Log: "Warning  Failed  kubelet  Error: ImagePullBackOff"},
+	}}
+	if err := newTestGate(c).watchAndDecide("r", "url", 1); !errors.Is(err, errNeedsRetry) {
+		t.Fatalf("expected errNeedsRetry, got %v", err)
+	}
+}
+
+func TestWatchAndDecide_ClassifiesTheFailurePoint(t *testing.T) {
+	earlier := "2026-03-01T10:00:00Z probe: 401 Unauthorized, retrying with token\n" +
+		strings.Repeat("2026-03-01T10:00:01Z waiting for pods\n", failureTailLines)
+	failed := "2026-03-01T10:05:00Z Error: read tcp 10.0.0.1:443: connection reset by peer\n" +
+		"2026-03-01T10:05:00Z ##[error]Process completed with exit code 1.\n"
+	cleanup := "2026-03-01T10:06:00Z Post job cleanup.\n2026-03-01T10:06:01Z Error: INSTALLATION FAILED: YAML parse error on x.yaml\n"
+
+	c := newCompletedFake(t, "failure", nil)
+	c.failedJobLogsByAttempt = map[int][]jobLog{1: {
+		{Name: "8.9 - eske - install - pr - gke", Log: earlier + failed + cleanup},
+	}}
+	if err := newTestGate(c).watchAndDecide("r", "url", 1); !errors.Is(err, errNeedsRetry) {
+		t.Fatalf("expected errNeedsRetry for a transient failure point, got %v", err)
+	}
+}
+
+func TestFailureTail(t *testing.T) {
+	lines := make([]string, 0, failureTailLines+20)
+	for i := 0; i < failureTailLines+10; i++ {
+		lines = append(lines, fmt.Sprintf("line %d", i))
+	}
+	lines = append(lines, "##[error]Process completed with exit code 1.", "after")
+
+	tail := strings.Split(failureTail(strings.Join(lines, "\n")+"\n"), "\n")
+	if len(tail) != failureTailLines {
+		t.Fatalf("tail has %d lines, want %d", len(tail), failureTailLines)
+	}
+	if last := tail[len(tail)-1]; !strings.Contains(last, "##[error]") {
+		t.Errorf("tail should end at the step error, ends with %q", last)
+	}
+
+	if got := failureTail("a\nb\n"); got != "a\nb" {
+		t.Errorf("log without a step error: tail = %q, want the whole log", got)
+	}
+}
+
+func TestWatchAndDecide_UnclassifiedFailureIsRetryable(t *testing.T) {
+	c := newCompletedFake(t, "cancelled", []string{"cancelled"})
+	c.failedJobLogsByAttempt = map[int][]jobLog{1: {{Name: "ITs", Log: "The operation was canceled."}}}
+	if err := newTestGate(c).watchAndDecide("r", "url", 1); !errors.Is(err, errNeedsRetry) {
+		t.Fatalf("expected errNeedsRetry, got %v", err)
+	}
+}
+
+func TestWatchAndDecide_LogReadErrorIsRetryable(t *testing.T) {
+	c := newCompletedFake(t, "failure", nil)
+	c.failedJobLogsErr = map[int]error{1: errors.New("410 gone")}
+	if err := newTestGate(c).watchAndDecide("r", "url", 1); !errors.Is(err, errNeedsRetry) {
+		t.Fatalf("expected errNeedsRetry, got %v", err)
+	}
+}
+
+func TestParseCell(t *testing.T) {
+	cases := []struct {
+		name string
+		job  string
+		want string
+		ok   bool
+	}{
+		{"cell", "8.9 - eske - install - pr - gke", "8.9 - eske - install - pr - gke", true},
+		{"nested job", "8.10 - oske - upgrade-minor - pr - gke / gke - ITs / deploy", "8.10 - oske - upgrade-minor - pr - gke", true},
+		{"unit test", "8.9 - Unit Test", "", false},
+		{"ci gate", "CI Gate", "", false},
+		{"not a version", "Local cluster - KIND 8.9 - x - y - z", "", false},
+		{"empty part", "8.9 -  - install - pr - gke", "", false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c, ok := parseCell(tc.job)
+			if ok != tc.ok {
+				t.Fatalf("ok=%v want %v", ok, tc.ok)
+			}
+			if ok && c.String() != tc.want {
+				t.Fatalf("got %q want %q", c, tc.want)
+			}
+		})
+	}
+}
+
+// newTargetedFake is a failed attempt 1 of run 100 whose failures are
+// transient and confined to integration cells.
+func newTargetedFake(t *testing.T, jobs []job, completedRuns int) *fakeClient {
+	statuses := make([]string, completedRuns)
+	for i := range statuses {
+		statuses[i] = "completed"
+	}
+	return &fakeClient{
+		t:                   t,
+		findRunQueue:        []findRunResp{{id: "100"}},
+		runURL:              "https://github.com/o/r/actions/runs/100",
+		attemptsQueue:       attemptList(1),
+		statusByAttempt:     map[int][]statusResp{1: statusList(statuses...)},
+		conclusionByAttempt: map[int]string{1: "failure"},
+		failedJobLogsByAttempt: map[int][]jobLog{1: {
+			{Name: "8.9 - eske - install - pr - gke", Log: "Error: read tcp 10.0.0.1:443: connection reset by peer"},
+		}},
+		jobsByRun:  map[string][]job{"100": jobs},
+		nextRunIDs: []string{"201", "202", "203"},
+		headSHA:    "sha",
+	}
+}
+
+func newTargetedGate(c *fakeClient) *Gate {
+	g := newTestGate(c)
+	g.HeadRef = "feature"
+	g.CellRetryBudget = 2
+	g.PRRetryBudget = 10
+	g.SummaryCheck = "Integration Tests Gate / attempts"
+	return g
+}
+
+func TestRun_TargetedRerunDispatchesOnlyFailedCells(t *testing.T) {
+	c := newTargetedFake(t, []job{
+		{Name: "8.9 - eske - install - pr - gke / gke - ITs / deploy", Conclusion: "failure"},
+		{Name: "8.9 - eske - install - pr - gke / gke - ITs / setup", Conclusion: "success"},
+		{Name: "8.9 - oske - install - pr - gke", Conclusion: "success"},
+		{Name: "8.10 - eske - upgrade-minor - pr - gke", Conclusion: "cancelled"},
+		{Name: "8.10 - eske - upgrade-patch - pr - gke", Conclusion: "timed_out"},
+		{Name: "8.9 - Unit Test", Conclusion: "success"},
+		{Name: "CI Gate", Conclusion: "failure"},
+	}, 3)
+	c.jobsByRun["201"] = []job{
+		{Name: "8.10 - eske - upgrade-patch - pr - gke", Conclusion: "success"},
+		{Name: "8.10 - eske - upgrade-minor - pr - gke", Conclusion: "success"},
+		{Name: "8.10 - eske - install - pr - gke", Conclusion: "failure"},
+	}
+	c.jobsByRun["202"] = []job{
+		{Name: "8.9 - eske - install - pr - gke / gke - ITs / deploy", Conclusion: "success"},
+		{Name: "8.9 - Unit Test", Conclusion: "failure"},
+	}
+
+	g := newTargetedGate(c)
+	if err := g.Run("pull_request", "sha", ""); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if c.rerunCalls != 0 {
+		t.Fatalf("expected no whole-run rerun, got %d", c.rerunCalls)
+	}
+	want := []map[string]string{
+		{"manual-trigger": "8.10", "scenario": "all", "flows": "install,upgrade-patch,upgrade-minor", "shortnames": "eske", "platforms": "matrix",
+			"gate-cells": "8.10 - eske - upgrade-minor - pr - gke, 8.10 - eske - upgrade-patch - pr - gke"},
+		{"manual-trigger": "8.9", "scenario": "all", "flows": "install", "shortnames": "eske", "platforms": "matrix",
+			"gate-cells": "8.9 - eske - install - pr - gke"},
+	}
+	if fmt.Sprint(c.dispatches) != fmt.Sprint(want) {
+		t.Fatalf("dispatches:\n got %v\nwant %v", c.dispatches, want)
+	}
+
+	if len(c.checkRuns) != 1 {
+		t.Fatalf("expected one summary check-run, got %d", len(c.checkRuns))
+	}
+	check := c.checkRuns[0]
+	if check.Conclusion != "success" || check.Title != "Passed after 2 retries" {
+		t.Errorf("check-run %q: %s", check.Title, check.Conclusion)
+	}
+	for _, want := range []string{
+		"| 1 | [run 100 attempt 1](https://github.com/o/r/actions/runs/100/attempts/1) | whole run | failure (retryable) | 8.10 - eske - upgrade-minor - pr - gke<br>8.10 - eske - upgrade-patch - pr - gke<br>8.9 - eske - install - pr - gke |",
+		"| 2 | [run 201](https://github.com/o/r/actions/runs/100) | 8.10 - eske - upgrade-minor - pr - gke<br>8.10 - eske - upgrade-patch - pr - gke | success |  |",
+		"| 3 | [run 202](https://github.com/o/r/actions/runs/100) | 8.9 - eske - install - pr - gke | success |  |",
+	} {
+		if !strings.Contains(check.Summary, want) {
+			t.Errorf("summary missing %q:\n%s", want, check.Summary)
+		}
+	}
+}
+
+func TestRun_TargetedRerunStopsAtCellBudget(t *testing.T) {
+	cellJob := job{Name: "8.9 - eske - install - pr - gke", Conclusion: "failure"}
+	c := newTargetedFake(t, []job{cellJob}, 3)
+	c.jobsByRun["201"] = []job{cellJob}
+	c.failedJobLogsByRun = map[string][]jobLog{"201": {{Name: cellJob.Name, Log: "Warning  Failed  kubelet  Error: ImagePullBackOff"}}}
+
+	g := newTargetedGate(c)
+	g.CellRetryBudget = 1
+	err := g.Run("pull_request", "sha", "")
+	if !errors.Is(err, ErrNotRetryable) || !strings.Contains(err.Error(), "retry budget of 1 per cell exhausted for 8.9 - eske - install - pr - gke") {
+		t.Fatalf("expected the cell budget to stop the gate, got %v", err)
+	}
+	if len(c.dispatches) != 1 {
+		t.Fatalf("expected exactly one targeted rerun, got %d", len(c.dispatches))
+	}
+	if len(c.checkRuns) != 1 || c.checkRuns[0].Conclusion != "failure" {
+		t.Fatalf("expected a failed summary check-run, got %+v", c.checkRuns)
+	}
+}
+
+func TestRun_TargetedRerunCountsEarlierRunsAgainstBudgets(t *testing.T) {
+	c := newTargetedFake(t, []job{
+		{Name: "8.9 - eske - install - pr - gke", Conclusion: "failure"},
+		{Name: "8.9 - oske - install - pr - gke", Conclusion: "failure"},
+	}, 1)
+	// Two cells re-run by the gate on an earlier commit of the branch. The
+	// manual dispatch and the cell run beside the targets do not count.
+	c.dispatched = []dispatchedRun{
+		{ID: "50", HeadSHA: "old", Title: gateRunPrefix + "8.8 - eske - install - pr - gke, 8.8 - oske - install - pr - gke"},
+		{ID: "51", HeadSHA: "sha", Title: "Test - Chart Version"},
+	}
+	c.jobsByRun["50"] = []job{
+		{Name: "8.8 - eske - install - pr - gke", Conclusion: "success"},
+		{Name: "8.8 - eske - upgrade-patch - pr - gke", Conclusion: "success"},
+		{Name: "8.8 - oske - install - pr - gke", Conclusion: "failure"},
+	}
+	c.jobsByRun["51"] = []job{
+		{Name: "8.9 - eske - install - pr - gke", Conclusion: "failure"},
+	}
+
+	g := newTargetedGate(c)
+	g.PRRetryBudget = 3
+	err := g.Run("pull_request", "sha", "")
+	if !errors.Is(err, ErrNotRetryable) || !strings.Contains(err.Error(), "retry budget of 3 per PR (2 used)") {
+		t.Fatalf("expected the PR budget to stop the gate, got %v", err)
+	}
+	if len(c.dispatches) != 0 || c.rerunCalls != 0 {
+		t.Fatalf("expected no retry, got %d dispatches and %d reruns", len(c.dispatches), c.rerunCalls)
+	}
+}
+
+func TestRun_TargetedRerunOfChartBugIsNotRetriedAgain(t *testing.T) {
+	cellJob := job{Name: "8.9 - eske - install - pr - gke", Conclusion: "failure"}
+	c := newTargetedFake(t, []job{cellJob}, 2)
+	c.jobsByRun["201"] = []job{cellJob}
+	c.failedJobLogsByRun = map[string][]jobLog{"201": {{Name: cellJob.Name, Log: "Error: INSTALLATION FAILED: YAML parse error on x.yaml"}}}
+
+	err := newTargetedGate(c).Run("pull_request", "sha", "")
+	if !errors.Is(err, ErrNotRetryable) || !strings.Contains(err.Error(), "(chart-render)") {
+		t.Fatalf("expected the chart bug to stop the gate, got %v", err)
+	}
+	if len(c.dispatches) != 1 {
+		t.Fatalf("expected one targeted rerun, got %d", len(c.dispatches))
+	}
+}
+
+func TestRun_TargetedCellThatDidNotRunFailsTheGate(t *testing.T) {
+	c := newTargetedFake(t, []job{{Name: "8.9 - eske - install - pr - gke", Conclusion: "failure"}}, 2)
+	c.jobsByRun["201"] = []job{{Name: "Generate chart matrix", Conclusion: "success"}}
+
+	err := newTargetedGate(c).Run("pull_request", "sha", "")
+	if !errors.Is(err, ErrNotRetryable) || !strings.Contains(err.Error(), "did not run") {
+		t.Fatalf("expected a missing-cell error, got %v", err)
+	}
+}
+
+func TestRun_TargetedRerunFallsBackToWholeRun(t *testing.T) {
+	cases := []struct {
+		name        string
+		jobs        []job
+		dispatchErr error
+	}{
+		{
+			name: "failure outside the matrix",
+			jobs: []job{
+				{Name: "8.9 - eske - install - pr - gke", Conclusion: "failure"},
+				{Name: "8.9 - Unit Test", Conclusion: "failure"},
+			},
+		},
+		{
+			name:        "dispatch rejected",
+			jobs:        []job{{Name: "8.9 - eske - install - pr - gke", Conclusion: "failure"}},
+			dispatchErr: errors.New(`HTTP 422: Unexpected inputs provided: ["shortnames"]`),
+		},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			c := newTargetedFake(t, tc.jobs, 1)
+			c.dispatchErr = tc.dispatchErr
+			c.attemptsQueue = attemptList(1, 2)
+			c.statusByAttempt[2] = statusList("completed")
+			c.conclusionByAttempt[2] = "success"
+
+			if err := newTargetedGate(c).Run("pull_request", "sha", ""); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if c.rerunCalls != 1 || len(c.dispatches) != 0 {
+				t.Fatalf("expected a whole-run rerun, got %d reruns and %d dispatches", c.rerunCalls, len(c.dispatches))
+			}
+		})
+	}
+}
+
+func TestResolveHeadRef(t *testing.T) {
+	cases := []struct {
+		event, pr, mg, want string
+	}{
+		{"pull_request", "feature", "", "feature"},
+		{"merge_group", "", "refs/heads/gh-readonly-queue/main/pr-1-abc", "gh-readonly-queue/main/pr-1-abc"},
+		{"workflow_dispatch", "feature", "x", ""},
+	}
+	for _, tc := range cases {
+		if got := ResolveHeadRef(tc.event, tc.pr, tc.mg); got != tc.want {
+			t.Errorf("ResolveHeadRef(%q) = %q, want %q", tc.event, got, tc.want)
+		}
+	}
+}
@@ -19,6 +19,7 @@ import (
 	"context"
 	"fmt"
 	"os/exec"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -116,6 +117,28 @@ func (c *ghCLI) AttemptJobConclusions(runID string, attempt int) ([]string, erro
 	return conclusions, nil
 }
 
+func (c *ghCLI) FailedJobLogs(runID string, attempt int) ([]jobLog, error) {
+	out, err := c.run("api", "--paginate",
+		fmt.Sprintf("repos/%s/actions/runs/%s/attempts/%d/jobs?per_page=100", c.repo, runID, attempt),
+		"--jq", `.jobs[] | select(.conclusion == "failure" or .conclusion == "timed_out" or .conclusion == "cancelled") | "\(.id)\t\(.name)"`)
+	if err != nil {
+		return nil, err
+	}
+	var logs []jobLog
+	for _, line := range strings.Split(out, "\n") {
+		id, name, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		log, err := c.run("api", fmt.Sprintf("repos/%s/actions/jobs/%s/logs", c.repo, id))
+		if err != nil {
+			return nil, err
+		}
+		logs = append(logs, jobLog{Name: name, Log: log})
+	}
+	return logs, nil
+}
+
 func (c *ghCLI) Rerun(runID string) error {
 	_, err := c.run("run", "rerun", runID,
 		"--repo", c.repo)
@@ -124,3 +147,76 @@ func (c *ghCLI) Rerun(runID string) error {
 	}
 	return err
 }
+
+func (c *ghCLI) AttemptJobs(runID string, attempt int) ([]job, error) {
+	out, err := c.run("api", "--paginate",
+		fmt.Sprintf("repos/%s/actions/runs/%s/attempts/%d/jobs?per_page=100", c.repo, runID, attempt),
+		"--jq", `.jobs[] | "\(.conclusion // "pending")\t\(.name)"`)
+	if err != nil {
+		return nil, err
+	}
+	var jobs []job
+	for _, line := range strings.Split(out, "\n") {
+		conclusion, name, ok := strings.Cut(strings.TrimSpace(line), "\t")
+		if !ok {
+			continue
+		}
+		jobs = append(jobs, job{Name: name, Conclusion: conclusion})
+	}
+	return jobs, nil
+}
+
+func (c *ghCLI) Dispatch(workflow, ref string, inputs map[string]string) error {
+	args := []string{"workflow", "run", workflow,
+		"--repo", c.repo,
+		"--ref", ref}
+	keys := make([]string, 0, len(inputs))
+	for k := range inputs {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		args = append(args, "-f", k+"="+inputs[k])
+	}
+	_, err := c.run(args...)
+	return err
+}
+
+func (c *ghCLI) DispatchedRuns(workflow, ref string) ([]dispatchedRun, error) {
+	out, err := c.run("run", "list",
+		"--repo", c.repo,
+		"--workflow", workflow,
+		"--branch", ref,
+		"--event", "workflow_dispatch",
+		"--limit", "100",
+		"--json", "databaseId,headSha,displayTitle",
+		"--jq", `.[] | "\(.databaseId)\t\(.headSha)\t\(.displayTitle)"`)
+	if err != nil {
+		return nil, err
+	}
+	var runs []dispatchedRun
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.SplitN(strings.TrimSpace(line), "\t", 3)
+		if len(fields) < 2 {
+			continue
+		}
+		run := dispatchedRun{ID: fields[0], HeadSHA: fields[1]}
+		if len(fields) == 3 {
+			run.Title = fields[2]
+		}
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+func (c *ghCLI) CreateCheckRun(sha string, check checkRun) error {
+	_, err := c.run("api", "--method", "POST",
+		fmt.Sprintf("repos/%s/check-runs", c.repo),
+		"-f", "name="+check.Name,
+		"-f", "head_sha="+sha,
+		"-f", "status=completed",
+		"-f", "conclusion="+check.Conclusion,
+		"-f", "output[title]="+check.Title,
+		"-f", "output[summary]="+check.Summary)
+	return err
+}
@@ -1,3 +1,9 @@
 module scripts/integration-tests-gate
 
 go 1.25.0
+
+require scripts/camunda-core v0.0.0
+
+require gopkg.in/yaml.v3 v3.0.1 // indirect
+
+replace scripts/camunda-core => ../camunda-core
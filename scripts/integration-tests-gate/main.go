@@ -17,6 +17,7 @@ package main
 import (
 	"fmt"
 	"os"
+	"strconv"
 	"time"
 )
 
@@ -29,6 +30,19 @@ func mustEnv(key string) string {
 	return v
 }
 
+func envInt(key string, def int) int {
+	v := os.Getenv(key)
+	if v == "" {
+		return def
+	}
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		fmt.Fprintf(os.Stderr, "error: %s=%q is not a non-negative integer\n", key, v)
+		os.Exit(2)
+	}
+	return n
+}
+
 func main() {
 	// Fork PRs lack actions:write on GITHUB_TOKEN, so rerun would 403.
 	// Return success so the required-check name stays green;
@@ -45,6 +59,10 @@ func main() {
 	prHead := os.Getenv("PR_HEAD_SHA")
 	mgHead := os.Getenv("MG_HEAD_SHA")
 
+	// Resolved before the override: a manually dispatched gate has no
+	// branch to dispatch targeted reruns on and re-runs the whole run.
+	headRef := ResolveHeadRef(event, os.Getenv("PR_HEAD_REF"), os.Getenv("MG_HEAD_REF"))
+
 	event, prHead, mgHead = ResolveDispatchOverride(
 		event, prHead, mgHead,
 		os.Getenv("OVERRIDE_SHA"),
@@ -64,6 +82,10 @@ func main() {
 		RunAttemptBackoff:    5 * time.Second,
 		RerunTries:           3,
 		RerunBackoff:         10 * time.Second,
+		HeadRef:              headRef,
+		CellRetryBudget:      envInt("RETRY_BUDGET_PER_CELL", 2),
+		PRRetryBudget:        envInt("RETRY_BUDGET_PER_PR", 10),
+		SummaryCheck:         "Integration Tests Gate / attempts",
 		Sleep:                time.Sleep,
 		Logf: func(format string, args ...any) {
 			fmt.Fprintf(os.Stderr, format+"\n", args...)
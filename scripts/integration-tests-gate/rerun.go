@@ -0,0 +1,409 @@
+// Copyright 2025 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// ciGateJob is the matrix workflow's aggregation job. It fails whenever a
+// cell fails and is never a failure of its own.
+const ciGateJob = "CI Gate"
+
+// errWholeRunRetry means the failed jobs cannot be re-run as targeted cells
+// and the gate falls back to re-running the whole run.
+var errWholeRunRetry = errors.New("targeted rerun not possible")
+
+// dispatchFlows are the flow combinations the matrix workflow's flows input
+// accepts, in its canonical order.
+var dispatchFlows = []string{"install", "upgrade-patch", "upgrade-minor"}
+
+var versionPattern = regexp.MustCompile(`^\d+\.\d+$`)
+
+// gateRunPrefix starts the run-name the matrix workflow gives runs
+// dispatched with the gate-cells input, followed by those cells.
+const gateRunPrefix = "Integration tests gate: "
+
+// cell is one integration-tests matrix cell, named by its job:
+// "<version> - <shortname> - <flow> - <case> - <platform>".
+type cell struct {
+	Version   string
+	Shortname string
+	Flow      string
+	Case      string
+	Platform  string
+}
+
+// parseCell parses the job name of a matrix cell. Jobs of the reusable
+// workflow the cell calls are named "<cell> / <job>" and belong to it.
+func parseCell(jobName string) (cell, bool) {
+	name, _, _ := strings.Cut(jobName, " / ")
+	parts := strings.Split(name, " - ")
+	if len(parts) != 5 || !versionPattern.MatchString(parts[0]) {
+		return cell{}, false
+	}
+	for _, p := range parts {
+		if p == "" || strings.TrimSpace(p) != p {
+			return cell{}, false
+		}
+	}
+	return cell{Version: parts[0], Shortname: parts[1], Flow: parts[2], Case: parts[3], Platform: parts[4]}, true
+}
+
+func (c cell) String() string {
+	return strings.Join([]string{c.Version, c.Shortname, c.Flow, c.Case, c.Platform}, " - ")
+}
+
+func jobFailed(conclusion string) bool {
+	return conclusion == "failure" || conclusion == "timed_out" || conclusion == "cancelled"
+}
+
+// cellResults folds the jobs of an attempt into one result per cell: true
+// when none of the cell's jobs failed. others lists the failed jobs outside
+// the integration matrix.
+func cellResults(jobs []job) (results map[cell]bool, others []string) {
+	results = map[cell]bool{}
+	for _, j := range jobs {
+		c, ok := parseCell(j.Name)
+		if !ok {
+			if jobFailed(j.Conclusion) && j.Name != ciGateJob {
+				others = append(others, j.Name)
+			}
+			continue
+		}
+		passed, seen := results[c]
+		results[c] = (passed || !seen) && !jobFailed(j.Conclusion)
+	}
+	return results, others
+}
+
+func failedCells(results map[cell]bool) []cell {
+	var out []cell
+	for c, passed := range results {
+		if !passed {
+			out = append(out, c)
+		}
+	}
+	sortCells(out)
+	return out
+}
+
+func sortCells(cells []cell) {
+	sort.Slice(cells, func(i, j int) bool { return cells[i].String() < cells[j].String() })
+}
+
+func cellNames(cells []cell) []string {
+	out := make([]string, len(cells))
+	for i, c := range cells {
+		out[i] = c.String()
+	}
+	return out
+}
+
+// targetedRun is a dispatched matrix run that re-runs cells of one version.
+type targetedRun struct {
+	ID    string
+	URL   string
+	Cells []cell
+}
+
+// RetryFailedCells re-runs only the failed integration cells of an attempt
+// by dispatching the matrix workflow with a shortname filter, one run per
+// chart version, until every cell passes, a cell fails for a cause a retry
+// cannot fix, or the retry budget is spent. It returns errWholeRunRetry when
+// the failures cannot be targeted: a failed job outside the matrix, or a
+// branch whose matrix workflow rejects the dispatch.
+func (g *Gate) RetryFailedCells(sha, runID string, attempt int) error {
+	jobs, err := g.Client.AttemptJobs(runID, attempt)
+	if err != nil {
+		g.Logf("attempt %d: reading jobs: %v", attempt, err)
+		return errWholeRunRetry
+	}
+	results, others := cellResults(jobs)
+	if len(others) > 0 {
+		g.Logf("failed jobs outside the integration matrix: %s", strings.Join(others, ", "))
+		return errWholeRunRetry
+	}
+	failed := failedCells(results)
+	if len(failed) == 0 {
+		g.Logf("attempt %d: no failed integration cell to target", attempt)
+		return errWholeRunRetry
+	}
+	g.attempts[len(g.attempts)-1].Failed = cellNames(failed)
+
+	perCell, perPR, err := g.retryHistory(sha)
+	if err != nil {
+		g.Logf("reading the retry history of %s: %v", g.HeadRef, err)
+		return errWholeRunRetry
+	}
+
+	for round := 1; len(failed) > 0; round++ {
+		if err := g.checkBudget(failed, perCell, perPR); err != nil {
+			g.Cmdf("::error title=retry budget::%v", err)
+			return err
+		}
+		g.Cmdf("::group::targeted rerun %d", round)
+		runs, err := g.dispatchCells(sha, failed)
+		g.Cmdf("::endgroup::")
+		if err != nil {
+			if len(runs) == 0 && round == 1 {
+				g.Logf("targeted rerun not dispatched: %v", err)
+				return errWholeRunRetry
+			}
+			return err
+		}
+		for _, c := range failed {
+			perCell[c]++
+		}
+		perPR += len(failed)
+
+		if failed, err = g.watchTargeted(runs); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// retryHistory counts the cells targeted by earlier gate dispatches, read
+// from their run-names: per cell on the head SHA, and in total on the branch.
+// A push starts a fresh per-cell budget but keeps spending the branch's.
+// Manual dispatches and cells a dispatch ran beside its targets do not
+// count.
+func (g *Gate) retryHistory(sha string) (perCell map[cell]int, perPR int, err error) {
+	runs, err := g.Client.DispatchedRuns(g.Workflow, g.HeadRef)
+	if err != nil {
+		return nil, 0, err
+	}
+	perCell = map[cell]int{}
+	for _, r := range runs {
+		targeted, ok := gateRunCells(r.Title)
+		if !ok {
+			continue
+		}
+		perPR += len(targeted)
+		if r.HeadSHA != sha {
+			continue
+		}
+		for _, c := range targeted {
+			perCell[c]++
+		}
+	}
+	g.Logf("retry history on %s: %d cell reruns", g.HeadRef, perPR)
+	return perCell, perPR, nil
+}
+
+// gateRunCells returns the cells a gate dispatch targeted, given its
+// run-name. ok is false for runs the gate did not dispatch.
+func gateRunCells(title string) (cells []cell, ok bool) {
+	list, ok := strings.CutPrefix(title, gateRunPrefix)
+	if !ok {
+		return nil, false
+	}
+	for _, name := range strings.Split(list, ",") {
+		if c, ok := parseCell(strings.TrimSpace(name)); ok {
+			cells = append(cells, c)
+		}
+	}
+	return cells, true
+}
+
+func (g *Gate) checkBudget(failed []cell, perCell map[cell]int, perPR int) error {
+	var exhausted []string
+	for _, c := range failed {
+		if perCell[c] >= g.CellRetryBudget {
+			exhausted = append(exhausted, c.String())
+		}
+	}
+	if len(exhausted) > 0 {
+		return fmt.Errorf("%w: retry budget of %d per cell exhausted for %s",
+			ErrNotRetryable, g.CellRetryBudget, strings.Join(exhausted, ", "))
+	}
+	if perPR+len(failed) > g.PRRetryBudget {
+		return fmt.Errorf("%w: re-running %d cells would exceed the retry budget of %d per PR (%d used)",
+			ErrNotRetryable, len(failed), g.PRRetryBudget, perPR)
+	}
+	return nil
+}
+
+// dispatchCells dispatches one matrix run per chart version of cells and
+// returns the runs dispatched so far.
+func (g *Gate) dispatchCells(sha string, cells []cell) ([]targetedRun, error) {
+	byVersion := map[string][]cell{}
+	var versions []string
+	for _, c := range cells {
+		if _, ok := byVersion[c.Version]; !ok {
+			versions = append(versions, c.Version)
+		}
+		byVersion[c.Version] = append(byVersion[c.Version], c)
+	}
+	sort.Strings(versions)
+
+	var runs []targetedRun
+	for _, version := range versions {
+		inputs, err := dispatchInputs(version, byVersion[version])
+		if err != nil {
+			return runs, err
+		}
+		run, err := g.dispatch(sha, inputs)
+		if err != nil {
+			return runs, err
+		}
+		run.Cells = byVersion[version]
+		g.Logf("dispatched run %s for %s", run.ID, strings.Join(cellNames(run.Cells), ", "))
+		runs = append(runs, run)
+	}
+	return runs, nil
+}
+
+// dispatchInputs are the matrix workflow inputs that re-run cells of one
+// version. The flows input applies to every shortname, so a shortname that
+// failed in one flow may also re-run in another; only the targeted cells,
+// listed in gate-cells, count.
+func dispatchInputs(version string, cells []cell) (map[string]string, error) {
+	shortnames := map[string]bool{}
+	flows := map[string]bool{}
+	for _, c := range cells {
+		if !slices.Contains(dispatchFlows, c.Flow) {
+			return nil, fmt.Errorf("flow %q of %s cannot be dispatched", c.Flow, c)
+		}
+		shortnames[c.Shortname] = true
+		flows[c.Flow] = true
+	}
+
+	var flowList []string
+	for _, f := range dispatchFlows {
+		if flows[f] {
+			flowList = append(flowList, f)
+		}
+	}
+	// The flows input has no "upgrade-patch,upgrade-minor" option.
+	if len(flowList) == 2 && flowList[0] == "upgrade-patch" {
+		flowList = dispatchFlows
+	}
+
+	names := make([]string, 0, len(shortnames))
+	for s := range shortnames {
+		names = append(names, s)
+	}
+	sort.Strings(names)
+
+	return map[string]string{
+		"manual-trigger": version,
+		"scenario":       "all",
+		"flows":          strings.Join(flowList, ","),
+		"shortnames":     strings.Join(names, ","),
+		"platforms":      "matrix",
+		"gate-cells":     strings.Join(cellNames(cells), ", "),
+	}, nil
+}
+
+// dispatch triggers the matrix workflow on HeadRef and waits for the new
+// run to appear. Runs on a head other than sha are ignored, so a push that
+// moved the branch meanwhile fails discovery instead of being gated.
+func (g *Gate) dispatch(sha string, inputs map[string]string) (targetedRun, error) {
+	before, err := g.Client.DispatchedRuns(g.Workflow, g.HeadRef)
+	if err != nil {
+		return targetedRun{}, err
+	}
+	known := make(map[string]bool, len(before))
+	for _, r := range before {
+		known[r.ID] = true
+	}
+
+	var last error
+	for i := 0; i < g.RerunTries; i++ {
+		if last = g.Client.Dispatch(g.Workflow, g.HeadRef, inputs); last == nil {
+			break
+		}
+		g.Logf("dispatch try %d/%d failed: %v", i+1, g.RerunTries, last)
+		g.Sleep(g.RerunBackoff)
+	}
+	if last != nil {
+		return targetedRun{}, last
+	}
+
+	for i := 0; i < g.DiscoveryTries; i++ {
+		runs, err := g.Client.DispatchedRuns(g.Workflow, g.HeadRef)
+		if err == nil {
+			for _, r := range runs {
+				if !known[r.ID] && r.HeadSHA == sha {
+					url, _ := g.Client.RunURL(r.ID)
+					return targetedRun{ID: r.ID, URL: url}, nil
+				}
+			}
+		}
+		g.Logf("dispatched run not yet visible (%d/%d)", i+1, g.DiscoveryTries)
+		g.Sleep(g.DiscoveryInterval)
+	}
+	return targetedRun{}, fmt.Errorf("dispatched %s on %s, but no new run @ %s appeared", g.Workflow, g.HeadRef, sha)
+}
+
+// watchTargeted waits for the dispatched runs and returns the targeted
+// cells that failed again. A targeted cell that did not run, or that failed
+// for a cause a retry cannot fix, fails the gate.
+func (g *Gate) watchTargeted(runs []targetedRun) ([]cell, error) {
+	var failed []cell
+	for _, run := range runs {
+		g.Logf("watching targeted run %s: %s", run.ID, run.URL)
+		g.Cmdf("::group::watch run %s", run.ID)
+		err := g.WaitForCompletion(run.ID, 1)
+		g.Cmdf("::endgroup::")
+		if err != nil {
+			return nil, err
+		}
+		jobs, err := g.Client.AttemptJobs(run.ID, 1)
+		if err != nil {
+			return nil, err
+		}
+		results, _ := cellResults(jobs)
+
+		var runFailed, missing []cell
+		for _, c := range run.Cells {
+			passed, ran := results[c]
+			switch {
+			case !ran:
+				missing = append(missing, c)
+			case !passed:
+				runFailed = append(runFailed, c)
+			}
+		}
+
+		result := "success"
+		if len(runFailed)+len(missing) > 0 {
+			result = "failure"
+		}
+		g.record(runLink(run.ID, run.URL, 0), strings.Join(cellNames(run.Cells), "<br>"), result)
+		g.attempts[len(g.attempts)-1].Failed = cellNames(append(runFailed, missing...))
+
+		if len(missing) > 0 {
+			return nil, fmt.Errorf("%w: %s did not run in %s",
+				ErrNotRetryable, strings.Join(cellNames(missing), ", "), run.URL)
+		}
+		if len(runFailed) == 0 {
+			continue
+		}
+		if err := g.classifyFailures(run.ID, 1); !errors.Is(err, errNeedsRetry) {
+			return nil, err
+		}
+		failed = append(failed, runFailed...)
+	}
+	sortCells(failed)
+	return failed, nil
+}
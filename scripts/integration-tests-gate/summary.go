@@ -0,0 +1,104 @@
+// Copyright 2025 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// attemptRecord is one row of the summary check-run: an attempt of the
+// matrix run or a targeted rerun.
+type attemptRecord struct {
+	Run    string
+	Scope  string
+	Result string
+	Failed []string
+}
+
+func (g *Gate) record(run, scope, result string) {
+	g.attempts = append(g.attempts, attemptRecord{Run: run, Scope: scope, Result: result})
+}
+
+// runLink renders a run as a Markdown link. attempt 0 omits the attempt.
+func runLink(runID, runURL string, attempt int) string {
+	label := "run " + runID
+	if attempt > 0 {
+		label = fmt.Sprintf("run %s attempt %d", runID, attempt)
+	}
+	if !strings.HasPrefix(runURL, "https://") {
+		return label
+	}
+	if attempt > 0 {
+		runURL = fmt.Sprintf("%s/attempts/%d", runURL, attempt)
+	}
+	return fmt.Sprintf("[%s](%s)", label, runURL)
+}
+
+func resultOf(err error) string {
+	switch {
+	case err == nil:
+		return "success"
+	case errors.Is(err, errNeedsRetry):
+		return "failure (retryable)"
+	case errors.Is(err, ErrNotRetryable):
+		return "failure (not retryable)"
+	default:
+		return "error"
+	}
+}
+
+// postSummary posts the attempt history as a check-run on sha. It is
+// informational: a failure to post is logged, not returned.
+func (g *Gate) postSummary(sha string, gateErr error) {
+	if g.SummaryCheck == "" || len(g.attempts) == 0 {
+		return
+	}
+	check := checkRun{
+		Name:       g.SummaryCheck,
+		Conclusion: "success",
+		Title:      "Passed",
+		Summary:    g.renderSummary(gateErr),
+	}
+	if len(g.attempts) > 1 {
+		check.Title = fmt.Sprintf("Passed after %d retries", len(g.attempts)-1)
+	}
+	if gateErr != nil {
+		check.Conclusion = "failure"
+		check.Title = "Failed"
+	}
+	if err := g.Client.CreateCheckRun(sha, check); err != nil {
+		g.Logf("posting the %q check-run: %v", g.SummaryCheck, err)
+	}
+}
+
+func (g *Gate) renderSummary(gateErr error) string {
+	var b strings.Builder
+	b.WriteString("| # | Run | Scope | Result | Failed cells |\n")
+	b.WriteString("|---|-----|-------|--------|--------------|\n")
+	for i, a := range g.attempts {
+		fmt.Fprintf(&b, "| %d | %s | %s | %s | %s |\n",
+			i+1, a.Run, a.Scope, a.Result, strings.Join(a.Failed, "<br>"))
+	}
+	if gateErr != nil {
+		fmt.Fprintf(&b, "\n**Gate failed:** %s\n", strings.ReplaceAll(gateErr.Error(), "\n", " "))
+	}
+	if g.HeadRef != "" {
+		fmt.Fprintf(&b, "\nTargeted reruns are limited to %d per cell and commit and %d cells per PR.\n",
+			g.CellRetryBudget, g.PRRetryBudget)
+	}
+	return b.String()
+}
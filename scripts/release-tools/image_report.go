@@ -0,0 +1,206 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"scripts/camunda-core/pkg/sbom"
+	"scripts/camunda-core/pkg/versionmatrix"
+)
+
+// imageReportOptions are the parsed image-report flags.
+type imageReportOptions struct {
+	matrixFile   string
+	chartVersion string
+	chartName    string
+	chartLicense string
+	out          string
+	vulnDB       string
+	previousSBOM string
+	deltaOut     string
+	dryRun       bool
+}
+
+// runImageReport writes a CycloneDX SBOM for a chart version and records its
+// path as chart_sbom on the version-matrix.json entry.
+//
+// The image set is the one update-matrix recorded on the entry (chart_images
+// plus chart_enterprise_images); each ref is resolved to its registry digest
+// and license (org.opencontainers.image.licenses). Registry credentials for
+// registry.camunda.cloud come from HARBOR_REGISTRY_USER /
+// HARBOR_REGISTRY_PASSWORD.
+//
+// --vuln-db points at Trivy/Grype JSON reports (a file or a directory of
+// *.json) produced by an offline scan; their findings are embedded in the
+// SBOM and a markdown CVE delta against the previous chart version's SBOM is
+// printed to stdout (or --delta-out). The previous SBOM is the chart_sbom of
+// the highest lower chart version in the matrix unless --previous-sbom is set.
+//
+// --dry-run prints the delta and writes nothing.
+func runImageReport(args []string) error {
+	fs := flag.NewFlagSet("image-report", flag.ContinueOnError)
+	var o imageReportOptions
+	fs.StringVar(&o.matrixFile, "matrix-file", "", "path to version-matrix.json holding the chart's recorded image set")
+	fs.StringVar(&o.chartVersion, "chart-version", "", "chart version whose entry to report on (e.g. 13.4.0)")
+	fs.StringVar(&o.chartName, "chart-name", "camunda-platform", "chart name recorded as the SBOM subject")
+	fs.StringVar(&o.chartLicense, "chart-license", "Apache-2.0", "SPDX license of the chart itself")
+	fs.StringVar(&o.out, "out", "", "SBOM output path (default: sbom/<chart-name>-<chart-version>.cdx.json next to --matrix-file)")
+	fs.StringVar(&o.vulnDB, "vuln-db", "", "Trivy/Grype JSON report file or directory from an offline scan")
+	fs.StringVar(&o.previousSBOM, "previous-sbom", "", "SBOM to diff CVEs against (default: the previous chart version's chart_sbom)")
+	fs.StringVar(&o.deltaOut, "delta-out", "", "write the markdown CVE delta here instead of stdout")
+	fs.BoolVar(&o.dryRun, "dry-run", false, "print the CVE delta without writing the SBOM or the matrix file")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if o.matrixFile == "" || o.chartVersion == "" {
+		return fmt.Errorf("--matrix-file and --chart-version are required")
+	}
+	return imageReport(o, sbom.NewResolver().Resolve, os.Stdout, os.Stderr)
+}
+
+// imageReport runs the report with an injectable image resolver. Progress
+// and warnings go to stderr.
+func imageReport(o imageReportOptions, resolve func(ref string) (sbom.Image, error), stdout, stderr io.Writer) error {
+	existing, err := os.ReadFile(o.matrixFile)
+	if err != nil {
+		return fmt.Errorf("read matrix file %s: %w", o.matrixFile, err)
+	}
+	entry, ok, err := versionmatrix.FindEntry(existing, o.chartVersion)
+	if err != nil {
+		return err
+	}
+	if !ok || len(entry.ChartImages) == 0 {
+		return fmt.Errorf("no recorded chart_images for %s in %s; run update-matrix first", o.chartVersion, o.matrixFile)
+	}
+
+	var images []sbom.Image
+	seen := map[string]bool{}
+	for _, ref := range append(append([]string{}, entry.ChartImages...), entry.ChartEnterpriseImages...) {
+		if seen[ref] {
+			continue
+		}
+		seen[ref] = true
+		img, err := resolve(ref)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(stderr, "resolved %s -> %s\n", ref, img.Digest)
+		images = append(images, img)
+	}
+
+	var findings []sbom.Finding
+	if o.vulnDB != "" {
+		reports, err := sbom.LoadReports(o.vulnDB)
+		if err != nil {
+			return err
+		}
+		var unmatched []string
+		findings, unmatched = sbom.MatchFindings(images, reports)
+		for _, target := range unmatched {
+			fmt.Fprintf(stderr, "warning: vulnerability report for %s matches no chart image; ignored\n", target)
+		}
+	}
+
+	bom := sbom.Build(sbom.Chart{Name: o.chartName, Version: o.chartVersion, License: o.chartLicense}, images, findings)
+	data, err := bom.Marshal()
+	if err != nil {
+		return err
+	}
+
+	matrixDir := filepath.Dir(o.matrixFile)
+	out := o.out
+	if out == "" {
+		out = filepath.Join(matrixDir, "sbom", o.chartName+"-"+o.chartVersion+".cdx.json")
+	}
+	recorded, err := filepath.Rel(matrixDir, out)
+	if err != nil {
+		return fmt.Errorf("record chart_sbom: %w", err)
+	}
+
+	if o.vulnDB != "" {
+		prevPath, err := previousSBOM(existing, o.chartVersion, matrixDir, o.previousSBOM)
+		if err != nil {
+			return err
+		}
+		var prev *sbom.BOM
+		var prevVersion string
+		if prevPath != "" {
+			if prev, err = sbom.ReadBOM(prevPath); err != nil {
+				return err
+			}
+			prevVersion = prev.Metadata.Component.Version
+		}
+		delta := sbom.Diff(prev, bom).Markdown(prevVersion, o.chartVersion)
+		if o.deltaOut != "" && !o.dryRun {
+			if err := os.WriteFile(o.deltaOut, []byte(delta), 0o644); err != nil {
+				return fmt.Errorf("write CVE delta %s: %w", o.deltaOut, err)
+			}
+		} else if _, err := io.WriteString(stdout, delta); err != nil {
+			return err
+		}
+	}
+
+	if o.dryRun {
+		fmt.Fprintf(stderr, "[dry-run] would write %s (%d images, %d vulnerabilities) and set chart_sbom=%s\n",
+			out, len(images), len(bom.Vulnerabilities), filepath.ToSlash(recorded))
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(out), 0o755); err != nil {
+		return fmt.Errorf("create sbom dir: %w", err)
+	}
+	if err := os.WriteFile(out, data, 0o644); err != nil {
+		return fmt.Errorf("write sbom %s: %w", out, err)
+	}
+	entry.ChartSBOM = filepath.ToSlash(recorded)
+	updated, err := versionmatrix.UpsertEntry(existing, entry)
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(o.matrixFile, updated, 0o644); err != nil {
+		return fmt.Errorf("write matrix file %s: %w", o.matrixFile, err)
+	}
+	fmt.Fprintf(stderr, "wrote %s (%d images, %d vulnerabilities); updated %s\n", out, len(images), len(bom.Vulnerabilities), o.matrixFile)
+	return nil
+}
+
+// previousSBOM picks the SBOM to diff against: the explicit override, else
+// the chart_sbom of the highest chart version below chartVersion that has
+// one (resolved relative to the matrix directory). Returns "" when there is
+// nothing to compare with.
+func previousSBOM(matrix []byte, chartVersion, matrixDir, override string) (string, error) {
+	if override != "" {
+		return override, nil
+	}
+	var entries []versionmatrix.ChartEntry
+	if err := json.Unmarshal(matrix, &entries); err != nil {
+		return "", fmt.Errorf("parse matrix: %w", err)
+	}
+	var version, path string
+	for _, e := range entries {
+		if e.ChartSBOM == "" || versionmatrix.CompareChartVersionsFull(e.ChartVersion, chartVersion) >= 0 {
+			continue
+		}
+		if version == "" || versionmatrix.CompareChartVersionsFull(e.ChartVersion, version) > 0 {
+			version, path = e.ChartVersion, filepath.Join(matrixDir, filepath.FromSlash(e.ChartSBOM))
+		}
+	}
+	return path, nil
+}
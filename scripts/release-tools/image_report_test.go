@@ -0,0 +1,148 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"scripts/camunda-core/pkg/sbom"
+	"scripts/camunda-core/pkg/versionmatrix"
+)
+
+func fakeResolve(ref string) (sbom.Image, error) {
+	return sbom.Image{Ref: ref, Digest: "sha256:" + strings.Repeat("a", 64), Licenses: []string{"Apache-2.0"}}, nil
+}
+
+// TestImageReportRecordsSBOMAndDiffsPreviousVersion runs two consecutive
+// releases: the second picks up the first's chart_sbom and reports the delta.
+func TestImageReportRecordsSBOMAndDiffsPreviousVersion(t *testing.T) {
+	dir := t.TempDir()
+	matrixFile := filepath.Join(dir, "version-matrix.json")
+	matrix := `[
+  {"chart_version": "13.4.0", "chart_images": ["docker.io/camunda/zeebe:8.8.1"], "release_tag": "camunda-platform-8.8-13.4.0"},
+  {"chart_version": "13.3.0", "chart_images": ["docker.io/camunda/zeebe:8.8.0"]}
+]`
+	if err := os.WriteFile(matrixFile, []byte(matrix), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	report := func(name, target, cve string) string {
+		d := filepath.Join(dir, name)
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		body := `{"ArtifactName":"` + target + `","Results":[{"Vulnerabilities":[{"VulnerabilityID":"` + cve + `","PkgName":"openssl","Severity":"HIGH"}]}]}`
+		if err := os.WriteFile(filepath.Join(d, "zeebe.json"), []byte(body), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return d
+	}
+
+	base := imageReportOptions{matrixFile: matrixFile, chartName: "camunda-platform", chartLicense: "Apache-2.0"}
+	prev := base
+	prev.chartVersion, prev.vulnDB = "13.3.0", report("scan-13.3.0", "camunda/zeebe:8.8.0", "CVE-2026-0001")
+	if err := imageReport(prev, fakeResolve, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cur := base
+	cur.chartVersion, cur.vulnDB = "13.4.0", report("scan-13.4.0", "camunda/zeebe:8.8.1", "CVE-2026-0002")
+	var stdout bytes.Buffer
+	if err := imageReport(cur, fakeResolve, &stdout, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(stdout.String(), "chart 13.3.0 → 13.4.0") || !strings.Contains(stdout.String(), "1 new, 1 fixed, 0 unchanged") {
+		t.Errorf("unexpected delta:\n%s", stdout.String())
+	}
+
+	data, err := os.ReadFile(matrixFile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	entry, _, err := versionmatrix.FindEntry(data, "13.4.0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry.ChartSBOM != "sbom/camunda-platform-13.4.0.cdx.json" || entry.ReleaseTag != "camunda-platform-8.8-13.4.0" {
+		t.Errorf("expected chart_sbom recorded and release facts preserved, got %+v", entry)
+	}
+	bom, err := sbom.ReadBOM(filepath.Join(dir, entry.ChartSBOM))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bom.Components) != 1 || len(bom.Vulnerabilities) != 1 || bom.Vulnerabilities[0].ID != "CVE-2026-0002" {
+		t.Errorf("unexpected SBOM %+v", bom)
+	}
+}
+
+func TestImageReportWarnsAboutUnmatchedReports(t *testing.T) {
+	dir := t.TempDir()
+	matrixFile := filepath.Join(dir, "version-matrix.json")
+	matrix := `[{"chart_version": "13.4.0", "chart_images": ["docker.io/camunda/zeebe:8.8.1"]}]`
+	if err := os.WriteFile(matrixFile, []byte(matrix), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	vulnDB := filepath.Join(dir, "scan")
+	if err := os.MkdirAll(vulnDB, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	body := `{"ArtifactName":"camunda/operate:8.7.0","Results":[{"Vulnerabilities":[{"VulnerabilityID":"CVE-2026-0003","PkgName":"openssl","Severity":"HIGH"}]}]}`
+	if err := os.WriteFile(filepath.Join(vulnDB, "operate.json"), []byte(body), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	o := imageReportOptions{matrixFile: matrixFile, chartVersion: "13.4.0", chartName: "camunda-platform", vulnDB: vulnDB, dryRun: true}
+	var stderr bytes.Buffer
+	if err := imageReport(o, fakeResolve, &bytes.Buffer{}, &stderr); err != nil {
+		t.Fatal(err)
+	}
+	if want := "warning: vulnerability report for camunda/operate:8.7.0 matches no chart image; ignored"; !strings.Contains(stderr.String(), want) {
+		t.Errorf("stderr missing %q:\n%s", want, stderr.String())
+	}
+}
+
+func TestImageReportRequiresRecordedImages(t *testing.T) {
+	dir := t.TempDir()
+	matrixFile := filepath.Join(dir, "version-matrix.json")
+	if err := os.WriteFile(matrixFile, []byte("[]"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	err := imageReport(imageReportOptions{matrixFile: matrixFile, chartVersion: "13.4.0", chartName: "camunda-platform"}, fakeResolve, &bytes.Buffer{}, &bytes.Buffer{})
+	if err == nil || !strings.Contains(err.Error(), "run update-matrix first") {
+		t.Fatalf("expected a missing-entry error, got %v", err)
+	}
+}
+
+func TestImageReportDryRunWritesNothing(t *testing.T) {
+	dir := t.TempDir()
+	matrixFile := filepath.Join(dir, "version-matrix.json")
+	matrix := `[{"chart_version": "13.4.0", "chart_images": ["docker.io/camunda/zeebe:8.8.1"]}]`
+	if err := os.WriteFile(matrixFile, []byte(matrix), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	o := imageReportOptions{matrixFile: matrixFile, chartVersion: "13.4.0", chartName: "camunda-platform", dryRun: true}
+	if err := imageReport(o, fakeResolve, &bytes.Buffer{}, &bytes.Buffer{}); err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := os.ReadFile(matrixFile); string(data) != matrix {
+		t.Errorf("dry run must not touch the matrix file:\n%s", data)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "sbom")); !os.IsNotExist(err) {
+		t.Errorf("dry run must not write the SBOM (stat err %v)", err)
+	}
+}
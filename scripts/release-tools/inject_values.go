@@ -17,30 +17,30 @@ package main
 import (
 	"fmt"
 	"os"
+	"strings"
+	"unicode"
 
 	"scripts/camunda-core/pkg/valuesinjector"
 )
 
-// runInjectValues overrides component image tags in a chart's values.yaml in
-// place, from the *_IMAGE_TAG environment variables. CHART_VERSION selects the
-// chart (and the version-gated component set).
+// runInjectValues overrides component images in a chart's values.yaml in
+// place, from environment variables. CHART_VERSION selects the chart; any
+// charts/camunda-platform-<version> works, since components come from
+// chartmeta.ComponentSpecs rather than a per-version list.
 //
 //	CHART_VERSION=8.10 ORCHESTRATION_IMAGE_TAG=... release-tools inject-values
 //
-// Image-tag inputs (set only the ones to override):
-//
-//	CONSOLE_IMAGE_TAG, ZEEBE_IMAGE_TAG, ZEEBE_GATEWAY_IMAGE_TAG, OPERATE_IMAGE_TAG,
-//	TASKLIST_IMAGE_TAG, OPTIMIZE_IMAGE_TAG, IDENTITY_IMAGE_TAG, WEB_MODELER_IMAGE_TAG,
-//	CONNECTORS_IMAGE_TAG (8.6/8.7) plus ORCHESTRATION_IMAGE_TAG (8.8+).
+// Each component path maps to an UPPER_SNAKE prefix (orchestration →
+// ORCHESTRATION, zeebeGateway → ZEEBE_GATEWAY, webModeler.restapi →
+// WEB_MODELER_RESTAPI) read with the suffixes _IMAGE_REGISTRY,
+// _IMAGE_REPOSITORY, _IMAGE_TAG and _IMAGE_DIGEST; set only the ones to
+// override. An override for a component the chart does not declare (e.g.
+// CONSOLE_IMAGE_TAG on 8.10) is skipped with a note.
 func runInjectValues(args []string) error {
 	chartVersion := os.Getenv("CHART_VERSION")
 	if chartVersion == "" {
 		return fmt.Errorf("CHART_VERSION environment variable is required")
 	}
-	validVersions := map[string]bool{"8.6": true, "8.7": true, "8.8": true, "8.9": true, "8.10": true}
-	if !validVersions[chartVersion] {
-		return fmt.Errorf("unsupported CHART_VERSION: %s (supported: 8.6, 8.7, 8.8, 8.9, 8.10)", chartVersion)
-	}
 
 	valuesFile := fmt.Sprintf("charts/camunda-platform-%s/values.yaml", chartVersion)
 	content, err := os.ReadFile(valuesFile)
@@ -48,21 +48,22 @@ func runInjectValues(args []string) error {
 		return fmt.Errorf("read %s: %w", valuesFile, err)
 	}
 
-	var result string
-	switch chartVersion {
-	case "8.6":
-		result, err = valuesinjector.MergeImageTags86(string(content), buildOverridesClassic())
-	case "8.7":
-		result, err = valuesinjector.MergeImageTags87(string(content), buildOverridesClassic())
-	case "8.8":
-		result, err = valuesinjector.MergeImageTags88(string(content), buildOverridesOrchestration())
-	case "8.9":
-		result, err = valuesinjector.MergeImageTags89(string(content), buildOverridesOrchestration())
-	case "8.10":
-		result, err = valuesinjector.MergeImageTags810(string(content), buildOverridesOrchestration())
+	var overrides []valuesinjector.Override
+	for _, o := range buildOverrides() {
+		declared, err := valuesinjector.Declares(string(content), o.Component)
+		if err != nil {
+			return fmt.Errorf("merge image overrides: %w", err)
+		}
+		if !declared {
+			fmt.Fprintf(os.Stderr, "skipping %s overrides: %s declares no %s.image\n", envPrefix(o.Component), valuesFile, o.Component)
+			continue
+		}
+		overrides = append(overrides, o)
 	}
+
+	result, err := valuesinjector.Inject(string(content), overrides)
 	if err != nil {
-		return fmt.Errorf("merge image tags: %w", err)
+		return fmt.Errorf("merge image overrides: %w", err)
 	}
 
 	if err := os.WriteFile(valuesFile, []byte(result), 0o644); err != nil {
@@ -72,42 +73,44 @@ func runInjectValues(args []string) error {
 	return nil
 }
 
-func envImage(envVar string) *valuesinjector.ComponentImage {
-	if tag := os.Getenv(envVar); tag != "" {
-		return &valuesinjector.ComponentImage{Image: valuesinjector.ImageTag{Tag: tag}}
-	}
-	return nil
-}
-
-// buildOverridesClassic reads the 8.6/8.7 image-tag overrides from the
+// buildOverrides reads the image overrides for every known component from the
 // environment. When ZEEBE_IMAGE_TAG is set but ZEEBE_GATEWAY_IMAGE_TAG is not,
 // the gateway inherits the zeebe tag (they share the camunda/zeebe image).
-func buildOverridesClassic() *valuesinjector.ValuesYAML86 {
-	o := &valuesinjector.ValuesYAML86{
-		Console:      envImage("CONSOLE_IMAGE_TAG"),
-		Zeebe:        envImage("ZEEBE_IMAGE_TAG"),
-		ZeebeGateway: envImage("ZEEBE_GATEWAY_IMAGE_TAG"),
-		Operate:      envImage("OPERATE_IMAGE_TAG"),
-		Tasklist:     envImage("TASKLIST_IMAGE_TAG"),
-		Optimize:     envImage("OPTIMIZE_IMAGE_TAG"),
-		Identity:     envImage("IDENTITY_IMAGE_TAG"),
-		WebModeler:   envImage("WEB_MODELER_IMAGE_TAG"),
-		Connectors:   envImage("CONNECTORS_IMAGE_TAG"),
-	}
-	if o.ZeebeGateway == nil && o.Zeebe != nil {
-		o.ZeebeGateway = o.Zeebe
+func buildOverrides() []valuesinjector.Override {
+	var overrides []valuesinjector.Override
+	for _, component := range valuesinjector.Components() {
+		prefix := envPrefix(component)
+		o := valuesinjector.Override{
+			Component:  component,
+			Registry:   os.Getenv(prefix + "_IMAGE_REGISTRY"),
+			Repository: os.Getenv(prefix + "_IMAGE_REPOSITORY"),
+			Tag:        os.Getenv(prefix + "_IMAGE_TAG"),
+			Digest:     os.Getenv(prefix + "_IMAGE_DIGEST"),
+		}
+		if component == "zeebeGateway" && o.Tag == "" {
+			o.Tag = os.Getenv("ZEEBE_IMAGE_TAG")
+		}
+		if o != (valuesinjector.Override{Component: component}) {
+			overrides = append(overrides, o)
+		}
 	}
-	return o
+	return overrides
 }
 
-// buildOverridesOrchestration reads the 8.8+ image-tag overrides from the environment.
-func buildOverridesOrchestration() *valuesinjector.ValuesYAML88 {
-	return &valuesinjector.ValuesYAML88{
-		Identity:      envImage("IDENTITY_IMAGE_TAG"),
-		Console:       envImage("CONSOLE_IMAGE_TAG"),
-		WebModeler:    envImage("WEB_MODELER_IMAGE_TAG"),
-		Connectors:    envImage("CONNECTORS_IMAGE_TAG"),
-		Orchestration: envImage("ORCHESTRATION_IMAGE_TAG"),
-		Optimize:      envImage("OPTIMIZE_IMAGE_TAG"),
+// envPrefix converts a component path to its environment-variable prefix:
+// camelCase words and path segments become UPPER_SNAKE.
+func envPrefix(component string) string {
+	var b strings.Builder
+	for i, r := range component {
+		switch {
+		case r == '.':
+			b.WriteByte('_')
+		case unicode.IsUpper(r) && i > 0:
+			b.WriteByte('_')
+			b.WriteRune(r)
+		default:
+			b.WriteRune(unicode.ToUpper(r))
+		}
 	}
+	return b.String()
 }
@@ -14,49 +14,115 @@
 
 package main
 
-import "testing"
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
 
-func TestBuildOverridesClassic_ZeebeLinksToGateway(t *testing.T) {
+	"scripts/camunda-core/pkg/valuesinjector"
+)
+
+func findOverride(overrides []valuesinjector.Override, component string) *valuesinjector.Override {
+	for i := range overrides {
+		if overrides[i].Component == component {
+			return &overrides[i]
+		}
+	}
+	return nil
+}
+
+func TestEnvPrefix(t *testing.T) {
+	cases := map[string]string{
+		"orchestration":         "ORCHESTRATION",
+		"zeebeGateway":          "ZEEBE_GATEWAY",
+		"webModeler":            "WEB_MODELER",
+		"webModeler.restapi":    "WEB_MODELER_RESTAPI",
+		"webModeler.websockets": "WEB_MODELER_WEBSOCKETS",
+	}
+	for component, want := range cases {
+		if got := envPrefix(component); got != want {
+			t.Errorf("envPrefix(%s) = %s, want %s", component, got, want)
+		}
+	}
+}
+
+func TestBuildOverrides_ZeebeLinksToGateway(t *testing.T) {
 	t.Setenv("ZEEBE_IMAGE_TAG", "8.7.99")
 	t.Setenv("ZEEBE_GATEWAY_IMAGE_TAG", "")
 
-	o := buildOverridesClassic()
+	o := buildOverrides()
 
-	if o.Zeebe == nil {
+	if findOverride(o, "zeebe") == nil {
 		t.Fatal("expected Zeebe override to be set")
 	}
-	if o.ZeebeGateway == nil {
+	gateway := findOverride(o, "zeebeGateway")
+	if gateway == nil {
 		t.Fatal("expected ZeebeGateway override to be set when ZEEBE_IMAGE_TAG is provided")
 	}
-	if o.ZeebeGateway.Image.Tag != "8.7.99" {
-		t.Errorf("expected ZeebeGateway tag 8.7.99, got %s", o.ZeebeGateway.Image.Tag)
+	if gateway.Tag != "8.7.99" {
+		t.Errorf("expected ZeebeGateway tag 8.7.99, got %s", gateway.Tag)
 	}
 }
 
-func TestBuildOverridesClassic_ExplicitGatewayOverridesLink(t *testing.T) {
+func TestBuildOverrides_ExplicitGatewayOverridesLink(t *testing.T) {
 	t.Setenv("ZEEBE_IMAGE_TAG", "8.7.99")
 	t.Setenv("ZEEBE_GATEWAY_IMAGE_TAG", "8.7.50")
 
-	o := buildOverridesClassic()
+	gateway := findOverride(buildOverrides(), "zeebeGateway")
 
-	if o.ZeebeGateway == nil {
+	if gateway == nil {
 		t.Fatal("expected ZeebeGateway override to be set")
 	}
-	if o.ZeebeGateway.Image.Tag != "8.7.50" {
-		t.Errorf("expected explicit gateway tag 8.7.50, got %s", o.ZeebeGateway.Image.Tag)
+	if gateway.Tag != "8.7.50" {
+		t.Errorf("expected explicit gateway tag 8.7.50, got %s", gateway.Tag)
 	}
 }
 
-func TestBuildOverridesClassic_NoZeebeNoGateway(t *testing.T) {
+func TestBuildOverrides_NoZeebeNoGateway(t *testing.T) {
 	t.Setenv("ZEEBE_IMAGE_TAG", "")
 	t.Setenv("ZEEBE_GATEWAY_IMAGE_TAG", "")
 
-	o := buildOverridesClassic()
+	o := buildOverrides()
 
-	if o.Zeebe != nil {
+	if findOverride(o, "zeebe") != nil {
 		t.Error("expected Zeebe override to be nil")
 	}
-	if o.ZeebeGateway != nil {
+	if findOverride(o, "zeebeGateway") != nil {
 		t.Error("expected ZeebeGateway override to be nil")
 	}
 }
+
+func TestRunInjectValues_UnlistedVersionAndUndeclaredComponent(t *testing.T) {
+	dir := t.TempDir()
+	chartDir := filepath.Join(dir, "charts", "camunda-platform-8.11")
+	if err := os.MkdirAll(chartDir, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	values := "# Orchestration.\norchestration:\n  image:\n    registry: \"\"\n    repository: camunda/camunda\n    tag: 8.11.0\n"
+	if err := os.WriteFile(filepath.Join(chartDir, "values.yaml"), []byte(values), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	wd, _ := os.Getwd()
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	t.Setenv("CHART_VERSION", "8.11")
+	t.Setenv("ORCHESTRATION_IMAGE_TAG", "SNAPSHOT")
+	t.Setenv("ORCHESTRATION_IMAGE_DIGEST", "sha256:abc")
+	t.Setenv("CONSOLE_IMAGE_TAG", "8.11.1") // not declared by this chart: skipped
+	if err := runInjectValues(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := os.ReadFile(filepath.Join(chartDir, "values.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := strings.Replace(values, "tag: 8.11.0\n", "tag: SNAPSHOT\n    digest: sha256:abc\n", 1)
+	if string(got) != want {
+		t.Errorf("unexpected values.yaml:\n%s", got)
+	}
+}
@@ -40,6 +40,8 @@ func main() {
 		err = runResolveTag(os.Args[2:])
 	case "harbor-tag":
 		err = runHarborTag(os.Args[2:])
+	case "image-report":
+		err = runImageReport(os.Args[2:])
 	case "component-image-versions":
 		err = runComponentImageVersions(os.Args[2:])
 	case "image-overrides":
@@ -81,13 +83,14 @@ Subcommands:
   update-matrix   Update a version-matrix.json entry from the chart's recorded camunda.io/chart-images annotation
   resolve-tag     Resolve a rolling Harbor tag to concrete, validate, and emit its parts to $GITHUB_OUTPUT
   harbor-tag      Idempotent Harbor artifact tag operations (digest|add|delete|ensure)
+  image-report    Write a chart's CycloneDX SBOM (digest-pinned images + licenses), record chart_sbom, print the CVE delta
   component-image-versions  Build the human-readable component-image-versions annotation block
   image-overrides Collect *-image-tag override inputs into the imageOverrides annotation + HAS_IMAGE_OVERRIDES
   chart-metadata  Read a pulled artifact's Chart.yaml and emit its metadata to $GITHUB_OUTPUT
   release-version Compute the dev-build release version + dev tag from release-please trace → $GITHUB_ENV
   release-notes   Generate RELEASE-NOTES.md + Chart.yaml release annotations (--main / --footer)
-  inject-values   Override component image tags in a chart's values.yaml from *_IMAGE_TAG env
-  version-matrix  Render version-matrix/camunda-<app>/README.md (--readme <app>) or version-matrix/README.md (--index)
+  inject-values   Override component images in a chart's values.yaml from *_IMAGE_{REGISTRY,REPOSITORY,TAG,DIGEST} env
+  version-matrix  Render version-matrix/camunda-<app>/README.md (--readme <app>) or version-matrix/README.md (--index), or diff two chart versions' images (--diff <from> <to> [--json])
   backfill-matrix One-time backfill of release_date/helm_cli/release_tag for historical version-matrix entries
   stamp-release   Stamp a published GitHub release's date onto its version-matrix.json entry
 `)
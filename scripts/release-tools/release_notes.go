@@ -16,6 +16,7 @@ package main
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -34,14 +35,17 @@ const cliffConfigFile = ".github/config/cliff.toml"
 // annotations. git/git-cliff/yq run as exec calls; the Chart.yaml writes go
 // through yq to preserve formatting and comments.
 //
-//	release-tools release-notes --main   <chart-dir>
+//	release-tools release-notes --main   <chart-dir> [--image-diff <diff.json>]
 //	release-tools release-notes --footer <chart-dir> [--images-chart-dir <dir>]
 //
+// --image-diff takes the `version-matrix --diff <prev> <new> --json` output;
+// its image changes are appended to the artifacthub.io/changes annotation.
+//
 // Run from the repository root (paths like .tool-versions and cliff.toml are
 // repo-root-relative).
 func runReleaseNotes(args []string) error {
 	if len(args) < 2 {
-		return fmt.Errorf("usage: release-notes --main|--footer <chart-dir> [--images-chart-dir <dir>]")
+		return fmt.Errorf("usage: release-notes --main|--footer <chart-dir> [--image-diff <diff.json> | --images-chart-dir <dir>]")
 	}
 	mode, chartDir := args[0], args[1]
 	if chartDir == "" {
@@ -50,10 +54,17 @@ func runReleaseNotes(args []string) error {
 	ctx := context.Background()
 	switch mode {
 	case "--main":
-		if len(args) != 2 {
-			return fmt.Errorf("usage: release-notes --main <chart-dir>")
+		var imageChanges []versionmatrix.ImageChange
+		if len(args) == 4 && args[2] == "--image-diff" && args[3] != "" {
+			diff, err := readImageDiff(args[3])
+			if err != nil {
+				return err
+			}
+			imageChanges = diff.Changes
+		} else if len(args) != 2 {
+			return fmt.Errorf("usage: release-notes --main <chart-dir> [--image-diff <diff.json>]")
 		}
-		return releaseNotesMain(ctx, chartDir)
+		return releaseNotesMain(ctx, chartDir, imageChanges)
 	case "--footer":
 		imagesChartDir := chartDir
 		useRecordedImages := false
@@ -78,7 +89,20 @@ func capture(ctx context.Context, name string, args ...string) (string, error) {
 	return strings.TrimSpace(string(out)), nil
 }
 
-func releaseNotesMain(ctx context.Context, chartDir string) error {
+// readImageDiff loads a `version-matrix --diff --json` document.
+func readImageDiff(path string) (versionmatrix.ImageDiff, error) {
+	var diff versionmatrix.ImageDiff
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return diff, fmt.Errorf("read image diff: %w", err)
+	}
+	if err := json.Unmarshal(data, &diff); err != nil {
+		return diff, fmt.Errorf("parse image diff %s: %w", path, err)
+	}
+	return diff, nil
+}
+
+func releaseNotesMain(ctx context.Context, chartDir string, imageChanges []versionmatrix.ImageChange) error {
 	// Fetch main unless already on it (git-cliff diffs from the last release tag).
 	if branch, _ := capture(ctx, "git", "branch", "--show-current"); branch != "main" {
 		if err := executil.RunCommand(ctx, "git", []string{"fetch", "origin", "main:main"}, nil, ""); err != nil {
@@ -157,7 +181,7 @@ func releaseNotesMain(ctx context.Context, chartDir string) error {
 	if err != nil {
 		return err
 	}
-	block, _ := releasenotes.ArtifactHubChanges(string(notesMD), releasenotes.CliffGroups(string(cliffTOML)))
+	block, _ := releasenotes.ArtifactHubChanges(string(notesMD), releasenotes.CliffGroups(string(cliffTOML)), imageChanges...)
 
 	// Seed an empty literal block (yq can't create one directly), then merge.
 	if err := executil.RunCommand(ctx, "yq",
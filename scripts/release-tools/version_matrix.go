@@ -19,6 +19,7 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -29,25 +30,51 @@ import (
 
 // runVersionMatrix renders version-matrix README files. Modes (exactly one):
 //
-//	--readme <app>  regenerate version-matrix/camunda-<app>/README.md in full
-//	                from its version-matrix.json (summary table + per-version
-//	                sections). --chart-version refreshes one published section.
-//	--index         scan version-matrix/ dirs, write version-matrix/README.md.
+//	--readme <app>       regenerate version-matrix/camunda-<app>/README.md in
+//	                     full from its version-matrix.json (summary table,
+//	                     "What changed", per-version sections). --chart-version
+//	                     refreshes one published section.
+//	--index              scan version-matrix/ dirs, write version-matrix/README.md.
+//	--diff <from> <to>   print the image-set diff between two chart versions
+//	                     (markdown, or the JSON release-notes consumes with --json).
 //
-// Both modes read the lifecycle classification from charts/chart-versions.yaml
-// and fail loudly when a minor is missing from it.
+// --readme and --index read the lifecycle classification from
+// charts/chart-versions.yaml and fail loudly when a minor is missing from it.
 func runVersionMatrix(args []string) error {
 	fs := flag.NewFlagSet("version-matrix", flag.ContinueOnError)
 	var app string
 	var chartVersion string
 	var index bool
+	var diffFrom string
+	var asJSON bool
 	fs.StringVar(&app, "readme", "", "app version (e.g. 8.7) — regenerates version-matrix/camunda-<app>/README.md")
 	fs.StringVar(&chartVersion, "chart-version", "", "published chart version whose preserved README section should be refreshed from JSON")
 	fs.BoolVar(&index, "index", false, "render version-matrix/README.md from all camunda-* dirs")
+	fs.StringVar(&diffFrom, "diff", "", "chart version to diff from; the chart version to diff to follows as an argument")
+	fs.BoolVar(&asJSON, "json", false, "with --diff: emit the diff as JSON")
 	if err := fs.Parse(args); err != nil {
 		return err
 	}
+	var diffTo string
+	if diffFrom != "" && fs.NArg() > 0 {
+		// flag stops at the positional <to>; parse any flags after it.
+		diffTo = fs.Arg(0)
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return err
+		}
+	}
+	if fs.NArg() > 0 {
+		return fmt.Errorf("unexpected arguments: %v", fs.Args())
+	}
 	switch {
+	case diffFrom != "" && (app != "" || index):
+		return fmt.Errorf("--diff is mutually exclusive with --readme and --index")
+	case diffFrom != "" && diffTo == "":
+		return fmt.Errorf("--diff requires two chart versions: --diff <from> <to>")
+	case diffFrom != "":
+		return diffVersionMatrix(diffFrom, diffTo, asJSON, os.Stdout)
+	case asJSON:
+		return fmt.Errorf("--json requires --diff")
 	case app != "" && index:
 		return fmt.Errorf("--readme and --index are mutually exclusive")
 	case chartVersion != "" && app == "":
@@ -57,8 +84,53 @@ func runVersionMatrix(args []string) error {
 	case index:
 		return renderVersionMatrixIndex()
 	default:
-		return fmt.Errorf("--readme <app> or --index is required")
+		return fmt.Errorf("--readme <app>, --index, or --diff <from> <to> is required")
+	}
+}
+
+// diffVersionMatrix writes the image diff between two chart versions. The
+// versions may belong to different minors (an upgrade across minors), so
+// every version-matrix/camunda-*/version-matrix.json is searched.
+func diffVersionMatrix(from, to string, asJSON bool, w io.Writer) error {
+	fromEntry, err := findChartEntry(from)
+	if err != nil {
+		return err
+	}
+	toEntry, err := findChartEntry(to)
+	if err != nil {
+		return err
+	}
+	diff := versionmatrix.DiffImages(fromEntry, toEntry)
+	if !asJSON {
+		_, err := io.WriteString(w, diff.Markdown())
+		return err
+	}
+	enc := json.NewEncoder(w)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	return enc.Encode(diff)
+}
+
+// findChartEntry looks a chart version up across all minors' matrices.
+func findChartEntry(chartVersion string) (versionmatrix.ChartEntry, error) {
+	files, err := filepath.Glob(filepath.Join("version-matrix", "camunda-*", "version-matrix.json"))
+	if err != nil {
+		return versionmatrix.ChartEntry{}, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			return versionmatrix.ChartEntry{}, fmt.Errorf("read %s: %w", file, err)
+		}
+		entry, ok, err := versionmatrix.FindEntry(data, chartVersion)
+		if err != nil {
+			return versionmatrix.ChartEntry{}, fmt.Errorf("%s: %w", file, err)
+		}
+		if ok {
+			return entry, nil
+		}
 	}
+	return versionmatrix.ChartEntry{}, fmt.Errorf("chart version %s is not present in any version-matrix/camunda-*/version-matrix.json", chartVersion)
 }
 
 // loadMatrixEntries reads and parses one app's version-matrix.json.
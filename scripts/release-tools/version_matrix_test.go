@@ -15,6 +15,11 @@
 package main
 
 import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 
 	"scripts/camunda-core/pkg/versionmatrix"
@@ -55,3 +60,48 @@ func TestReadmeSectionsForRenderRejectsUnknownVersion(t *testing.T) {
 		t.Fatal("expected unknown chart version error, got nil")
 	}
 }
+
+// TestDiffVersionMatrixAcrossMinors diffs an upgrade hop whose versions live in
+// two different minors' matrices.
+func TestDiffVersionMatrixAcrossMinors(t *testing.T) {
+	root := t.TempDir()
+	for app, matrix := range map[string]string{
+		"8.8": `[{"chart_version":"13.4.0","chart_images":["docker.io/camunda/camunda:8.8.5"]}]`,
+		"8.9": `[{"chart_version":"14.0.0","chart_images":["docker.io/camunda/camunda:8.9.0"]}]`,
+	} {
+		dir := filepath.Join(root, "version-matrix", "camunda-"+app)
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "version-matrix.json"), []byte(matrix), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	var out bytes.Buffer
+	if err := diffVersionMatrix("13.4.0", "14.0.0", true, &out); err != nil {
+		t.Fatal(err)
+	}
+	var diff versionmatrix.ImageDiff
+	if err := json.Unmarshal(out.Bytes(), &diff); err != nil {
+		t.Fatalf("--json output must be an ImageDiff: %v\n%s", err, out.String())
+	}
+	if len(diff.Changes) != 1 || diff.Changes[0].Kind != versionmatrix.ImageTagBump {
+		t.Errorf("unexpected diff %+v", diff)
+	}
+
+	if err := diffVersionMatrix("13.4.0", "99.0.0", false, &out); err == nil || !strings.Contains(err.Error(), "99.0.0") {
+		t.Errorf("expected an unknown-version error, got %v", err)
+	}
+	if err := runVersionMatrix([]string{"--diff", "13.4.0"}); err == nil || !strings.Contains(err.Error(), "two chart versions") {
+		t.Errorf("expected a missing <to> error, got %v", err)
+	}
+}
@@ -0,0 +1,45 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command golden-unreferenced lists the golden files under each given chart
+// test/unit directory that no test references any more. It only reports them:
+// delete a file once its test is gone for good, or restore the test.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"test/golden"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprintln(os.Stderr, "usage: golden-unreferenced <chart>/test/unit...")
+		os.Exit(2)
+	}
+	count := 0
+	for _, unitDir := range os.Args[1:] {
+		files, err := golden.UnreferencedGoldenFiles(unitDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %v\n", unitDir, err)
+			os.Exit(1)
+		}
+		for _, f := range files {
+			fmt.Printf("warning: %s is not referenced by any test\n", f)
+		}
+		count += len(files)
+	}
+	fmt.Printf("%d unreferenced golden file(s)\n", count)
+}
@@ -0,0 +1,594 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package golden holds the structured golden-file comparison shared by the
+// chart versions' unit tests. Rendered output and golden file are both
+// parsed into Kubernetes objects, matched by kind/namespace/name and compared
+// field by field, so a failure names the object and field path that changed
+// instead of dumping two manifests.
+//
+// Field paths are dot-separated map keys; keys containing '.', '[' or ']' are
+// written in brackets and quotes (metadata.labels["helm.sh/chart"]). List
+// items are [name=<name>] when every item of the list has a unique name, else
+// [<index>]. Ignore patterns use the same syntax plus globs: '*' inside a
+// segment (checksum/*, *-secret), [*] for any list item and ** for any number
+// of segments.
+package golden
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// DefaultIgnoredFields are ignored by every golden test: the chart label
+// changes with each chart version bump.
+var DefaultIgnoredFields = []string{`**.labels["helm.sh/chart"]`}
+
+// CompareManifests compares two multi-document manifests and returns one line
+// per difference, in object order. Fields matching ignoredFields are skipped.
+func CompareManifests(expected, actual string, ignoredFields []string) ([]string, error) {
+	patterns, err := parseFieldPatterns(ignoredFields)
+	if err != nil {
+		return nil, err
+	}
+	want, wantIDs, err := parseObjects(expected)
+	if err != nil {
+		return nil, fmt.Errorf("parse golden file: %w", err)
+	}
+	got, gotIDs, err := parseObjects(actual)
+	if err != nil {
+		return nil, fmt.Errorf("parse rendered output: %w", err)
+	}
+
+	var diffs []string
+	for _, id := range wantIDs {
+		if _, ok := got[id]; !ok {
+			diffs = append(diffs, id+": object missing from rendered output")
+			continue
+		}
+		var objectDiffs []string
+		diffValues(nil, want[id], got[id], patterns, &objectDiffs)
+		for _, d := range objectDiffs {
+			diffs = append(diffs, id+" "+d)
+		}
+	}
+	for _, id := range gotIDs {
+		if _, ok := want[id]; !ok {
+			diffs = append(diffs, id+": object not in golden file")
+		}
+	}
+	return diffs, nil
+}
+
+// parseObjects decodes every non-empty document and keys it by
+// Kind/namespace/name (Kind/name without a namespace). Repeated IDs get a
+// #<n> suffix so nothing is dropped.
+func parseObjects(manifest string) (map[string]any, []string, error) {
+	objects := map[string]any{}
+	var ids []string
+	dec := yaml.NewDecoder(strings.NewReader(manifest))
+	for {
+		var doc any
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		if doc == nil {
+			continue
+		}
+		id := objectID(doc)
+		for n := 2; objects[id] != nil; n++ {
+			id = fmt.Sprintf("%s#%d", objectID(doc), n)
+		}
+		objects[id] = doc
+		ids = append(ids, id)
+	}
+	return objects, ids, nil
+}
+
+func objectID(doc any) string {
+	m, _ := doc.(map[string]any)
+	meta, _ := m["metadata"].(map[string]any)
+	kind, name, namespace := fmt.Sprint(m["kind"]), fmt.Sprint(meta["name"]), meta["namespace"]
+	if namespace != nil {
+		return kind + "/" + fmt.Sprint(namespace) + "/" + name
+	}
+	return kind + "/" + name
+}
+
+// diffValues appends "<path>: <change>" lines for every difference between
+// two decoded values.
+func diffValues(segments []string, expected, actual any, ignored [][]string, out *[]string) {
+	if matchesAny(segments, ignored) {
+		return
+	}
+	at := formatPath(segments)
+	wantMap, wantIsMap := asMap(expected)
+	gotMap, gotIsMap := asMap(actual)
+	if wantIsMap && gotIsMap {
+		keys := make([]string, 0, len(wantMap)+len(gotMap))
+		for k := range wantMap {
+			keys = append(keys, k)
+		}
+		for k := range gotMap {
+			if _, ok := wantMap[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			child := append(slices.Clone(segments), k)
+			w, inWant := wantMap[k]
+			g, inGot := gotMap[k]
+			switch {
+			case matchesAny(child, ignored):
+			case !inGot:
+				*out = append(*out, fmt.Sprintf("%s: removed (was %s)", formatPath(child), formatValue(w)))
+			case !inWant:
+				*out = append(*out, fmt.Sprintf("%s: added %s", formatPath(child), formatValue(g)))
+			default:
+				diffValues(child, w, g, ignored, out)
+			}
+		}
+		return
+	}
+
+	wantList, wantIsList := expected.([]any)
+	gotList, gotIsList := actual.([]any)
+	if wantIsList && gotIsList {
+		wantSegs, gotSegs := itemSegments(listNames(wantList)), itemSegments(listNames(gotList))
+		gotIndex := map[string]int{}
+		for i, s := range gotSegs {
+			gotIndex[s] = i
+		}
+		matched := map[string]bool{}
+		for i, s := range wantSegs {
+			child := append(slices.Clone(segments), s)
+			j, ok := gotIndex[s]
+			switch {
+			case matchesAny(child, ignored):
+			case !ok:
+				*out = append(*out, fmt.Sprintf("%s: removed (was %s)", formatPath(child), formatValue(wantList[i])))
+			default:
+				diffValues(child, wantList[i], gotList[j], ignored, out)
+			}
+			matched[s] = true
+		}
+		for j, s := range gotSegs {
+			child := append(slices.Clone(segments), s)
+			if !matched[s] && !matchesAny(child, ignored) {
+				*out = append(*out, fmt.Sprintf("%s: added %s", formatPath(child), formatValue(gotList[j])))
+			}
+		}
+		return
+	}
+
+	if reflect.DeepEqual(expected, actual) {
+		return
+	}
+	wantStr, wantIsStr := expected.(string)
+	gotStr, gotIsStr := actual.(string)
+	if wantIsStr && gotIsStr && (strings.Contains(wantStr, "\n") || strings.Contains(gotStr, "\n")) {
+		*out = append(*out, fmt.Sprintf("%s: text changed\n%s", at, lineDiff(wantStr, gotStr)))
+		return
+	}
+	*out = append(*out, fmt.Sprintf("%s: %s -> %s", at, formatValue(expected), formatValue(actual)))
+}
+
+// asMap treats null as an empty map, so a parent whose only children were
+// stripped as ignored still compares equal.
+func asMap(v any) (map[string]any, bool) {
+	if v == nil {
+		return map[string]any{}, true
+	}
+	m, ok := v.(map[string]any)
+	return m, ok
+}
+
+// listNames returns each item's name field ("" when the item has none).
+func listNames(items []any) []string {
+	names := make([]string, len(items))
+	for i, item := range items {
+		if m, ok := item.(map[string]any); ok {
+			if name, ok := m["name"].(string); ok {
+				names[i] = name
+			}
+		}
+	}
+	return names
+}
+
+// itemSegments returns the path segment of each list item: [name=<name>]
+// when every item has a unique name, else [<index>].
+func itemSegments(names []string) []string {
+	segs := make([]string, len(names))
+	seen := map[string]bool{}
+	byName := true
+	for _, name := range names {
+		if name == "" || seen[name] {
+			byName = false
+			break
+		}
+		seen[name] = true
+	}
+	for i, name := range names {
+		if byName {
+			segs[i] = "[name=" + name + "]"
+		} else {
+			segs[i] = "[" + strconv.Itoa(i) + "]"
+		}
+	}
+	return segs
+}
+
+func formatPath(segments []string) string {
+	if len(segments) == 0 {
+		return "(root)"
+	}
+	var b strings.Builder
+	for _, s := range segments {
+		switch {
+		case strings.HasPrefix(s, "["):
+			b.WriteString(s)
+		case strings.ContainsAny(s, ".[]"):
+			b.WriteString("[" + strconv.Quote(s) + "]")
+		default:
+			if b.Len() > 0 {
+				b.WriteByte('.')
+			}
+			b.WriteString(s)
+		}
+	}
+	return b.String()
+}
+
+func formatValue(v any) string {
+	var s string
+	if str, ok := v.(string); ok {
+		s = strconv.Quote(str)
+	} else if data, err := json.Marshal(v); err == nil {
+		s = string(data)
+	} else {
+		s = fmt.Sprint(v)
+	}
+	if len(s) > 120 {
+		s = s[:117] + "..."
+	}
+	return s
+}
+
+// lineDiff renders a minimal line diff of two texts ("-" golden, "+"
+// rendered), keeping only changed lines.
+func lineDiff(expected, actual string) string {
+	a, b := strings.Split(expected, "\n"), strings.Split(actual, "\n")
+	// lcs[i][j] is the longest common subsequence of a[i:] and b[j:].
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+	var out strings.Builder
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case i < len(a) && j < len(b) && a[i] == b[j]:
+			i++
+			j++
+		case i < len(a) && (j == len(b) || lcs[i+1][j] >= lcs[i][j+1]):
+			fmt.Fprintf(&out, "    - %s\n", a[i])
+			i++
+		default:
+			fmt.Fprintf(&out, "    + %s\n", b[j])
+			j++
+		}
+	}
+	return strings.TrimSuffix(out.String(), "\n")
+}
+
+// parseFieldPatterns splits ignore patterns into segments.
+func parseFieldPatterns(patterns []string) ([][]string, error) {
+	parsed := make([][]string, 0, len(patterns))
+	for _, p := range patterns {
+		segs, err := splitFieldPath(p)
+		if err != nil {
+			return nil, fmt.Errorf("ignored field %q: %w", p, err)
+		}
+		parsed = append(parsed, segs)
+	}
+	return parsed, nil
+}
+
+// splitFieldPath parses a field path into its segments; bracketed items stay
+// bracketed unless they are a quoted map key.
+func splitFieldPath(p string) ([]string, error) {
+	var segs []string
+	for len(p) > 0 {
+		switch p[0] {
+		case '.':
+			p = p[1:]
+		case '[':
+			end := strings.IndexByte(p, ']')
+			if strings.HasPrefix(p, `["`) {
+				key, err := strconv.QuotedPrefix(p[1:])
+				if err != nil {
+					return nil, err
+				}
+				if !strings.HasPrefix(p[1+len(key):], "]") {
+					return nil, fmt.Errorf("missing ] after %s", key)
+				}
+				unquoted, _ := strconv.Unquote(key)
+				segs = append(segs, unquoted)
+				p = p[len(key)+2:]
+				continue
+			}
+			if end < 0 {
+				return nil, fmt.Errorf("missing ]")
+			}
+			segs = append(segs, p[:end+1])
+			p = p[end+1:]
+		default:
+			end := strings.IndexAny(p, ".[")
+			if end < 0 {
+				end = len(p)
+			}
+			segs = append(segs, p[:end])
+			p = p[end:]
+		}
+	}
+	if len(segs) == 0 {
+		return nil, fmt.Errorf("empty path")
+	}
+	return segs, nil
+}
+
+func matchesAny(segments []string, patterns [][]string) bool {
+	for _, p := range patterns {
+		if matchSegments(p, segments) {
+			return true
+		}
+	}
+	return false
+}
+
+func matchSegments(pattern, segments []string) bool {
+	if len(pattern) == 0 {
+		return len(segments) == 0
+	}
+	if pattern[0] == "**" {
+		for i := 0; i <= len(segments); i++ {
+			if matchSegments(pattern[1:], segments[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(segments) == 0 || !matchSegment(pattern[0], segments[0]) {
+		return false
+	}
+	return matchSegments(pattern[1:], segments[1:])
+}
+
+func matchSegment(pattern, segment string) bool {
+	patItem, segItem := strings.HasPrefix(pattern, "["), strings.HasPrefix(segment, "[")
+	if patItem != segItem {
+		return false
+	}
+	if patItem {
+		if pattern == "[*]" {
+			return true
+		}
+		pattern, segment = strings.Trim(pattern, "[]"), strings.Trim(segment, "[]")
+	}
+	ok, err := path.Match(pattern, segment)
+	return err == nil && ok
+}
+
+// StripIgnoredFields removes the lines of every field matching ignoredFields
+// from a rendered manifest, leaving all other lines byte-for-byte intact. It
+// is what the golden update writes, so golden files carry no volatile values.
+// Fields inside flow-style maps or lists are left in place (comparison still
+// ignores them).
+func StripIgnoredFields(manifest string, ignoredFields []string) (string, error) {
+	patterns, err := parseFieldPatterns(ignoredFields)
+	if err != nil {
+		return "", err
+	}
+	lines := strings.SplitAfter(manifest, "\n")
+	// Document boundaries: a field never extends past the next "---".
+	var separators []int
+	for i, l := range lines {
+		if strings.HasPrefix(l, "---") {
+			separators = append(separators, i+1)
+		}
+	}
+	drop := make([]bool, len(lines)+1)
+
+	dec := yaml.NewDecoder(strings.NewReader(manifest))
+	for {
+		var doc yaml.Node
+		err := dec.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return "", err
+		}
+		if len(doc.Content) == 0 {
+			continue
+		}
+		root := doc.Content[0]
+		end := len(lines)
+		for _, s := range separators {
+			if s > root.Line {
+				end = s - 1
+				break
+			}
+		}
+		markIgnored(root, nil, end, lines, patterns, drop)
+	}
+
+	var out bytes.Buffer
+	for i, l := range lines {
+		if !drop[i+1] {
+			out.WriteString(l)
+		}
+	}
+	return out.String(), nil
+}
+
+// markIgnored walks a block-style node; every ignored child's lines, from its
+// key (or item) line to just before the next sibling, are marked for removal.
+// end is the last line the node may occupy.
+func markIgnored(node *yaml.Node, segments []string, end int, lines []string, patterns [][]string, drop []bool) {
+	if node.Style&yaml.FlowStyle != 0 {
+		return
+	}
+	type child struct {
+		seg   string
+		start int
+		value *yaml.Node
+	}
+	var children []child
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			children = append(children, child{node.Content[i].Value, node.Content[i].Line, node.Content[i+1]})
+		}
+	case yaml.SequenceNode:
+		names := make([]string, len(node.Content))
+		for i, item := range node.Content {
+			for j := 0; item.Kind == yaml.MappingNode && j+1 < len(item.Content); j += 2 {
+				if item.Content[j].Value == "name" {
+					names[i] = item.Content[j+1].Value
+				}
+			}
+		}
+		for i, seg := range itemSegments(names) {
+			children = append(children, child{seg, node.Content[i].Line, node.Content[i]})
+		}
+	default:
+		return
+	}
+
+	for i, c := range children {
+		childEnd := end
+		if i+1 < len(children) {
+			childEnd = children[i+1].start - 1
+		}
+		childPath := append(slices.Clone(segments), c.seg)
+		if !matchesAny(childPath, patterns) {
+			markIgnored(c.value, childPath, childEnd, lines, patterns, drop)
+			continue
+		}
+		// Keep trailing blank lines and comments at or left of the field's
+		// indent: they belong to whatever follows.
+		indent := len(lines[c.start-1]) - len(strings.TrimLeft(lines[c.start-1], " "))
+		for childEnd > c.start {
+			l := lines[childEnd-1]
+			trimmed := strings.TrimSpace(l)
+			if trimmed != "" && !(strings.HasPrefix(trimmed, "#") && len(l)-len(strings.TrimLeft(l, " ")) <= indent) {
+				break
+			}
+			childEnd--
+		}
+		for l := c.start; l <= childEnd; l++ {
+			drop[l] = true
+		}
+	}
+}
+
+// UnreferencedGoldenFiles lists the golden files under unitDir (a chart's
+// test/unit directory) that no test can load: golden/<name>.golden.yaml is
+// referenced when a _test.go file of the same package has the string literal
+// "<name>" (as GoldenFileName or in the list a loop builds it from).
+func UnreferencedGoldenFiles(unitDir string) ([]string, error) {
+	goldenDirs, err := filepath.Glob(filepath.Join(unitDir, "*", "golden"))
+	if err != nil {
+		return nil, err
+	}
+	var unreferenced []string
+	for _, goldenDir := range goldenDirs {
+		literals, err := stringLiterals(filepath.Dir(goldenDir))
+		if err != nil {
+			return nil, err
+		}
+		files, err := filepath.Glob(filepath.Join(goldenDir, "*.golden.yaml"))
+		if err != nil {
+			return nil, err
+		}
+		for _, f := range files {
+			if !literals[strings.TrimSuffix(filepath.Base(f), ".golden.yaml")] {
+				unreferenced = append(unreferenced, f)
+			}
+		}
+	}
+	sort.Strings(unreferenced)
+	return unreferenced, nil
+}
+
+// stringLiterals collects every string literal in a directory's _test.go files.
+func stringLiterals(dir string) (map[string]bool, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*_test.go"))
+	if err != nil {
+		return nil, err
+	}
+	literals := map[string]bool{}
+	fset := token.NewFileSet()
+	for _, f := range files {
+		src, err := os.ReadFile(f)
+		if err != nil {
+			return nil, err
+		}
+		file, err := parser.ParseFile(fset, f, src, 0)
+		if err != nil {
+			return nil, err
+		}
+		ast.Inspect(file, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.BasicLit); ok && lit.Kind == token.STRING {
+				if s, err := strconv.Unquote(lit.Value); err == nil {
+					literals[s] = true
+				}
+			}
+			return true
+		})
+	}
+	return literals, nil
+}
@@ -0,0 +1,246 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package golden
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+const goldenManifest = `---
+# Source: camunda-platform/templates/orchestration/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: camunda-platform-test-zeebe
+  labels:
+    app: camunda-platform
+    helm.sh/chart: camunda-platform-14.0.0
+data:
+  application.yaml: |
+    server:
+      port: 8080
+    # comment inside the block
+    client-secret: ${SECRET}
+
+  log4j2.xml: "<xml/>"
+---
+# Source: camunda-platform/templates/orchestration/statefulset.yaml
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: camunda-platform-test-zeebe
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: abc
+        checksum/secret: def
+        team: orchestration
+    spec:
+      containers:
+        - name: orchestration
+          image: camunda/camunda:8.10.0
+          env:
+            - name: A
+              value: "1"
+        - name: sidecar
+          image: busybox:1.36
+`
+
+func ignoring(fields ...string) []string {
+	return append(fields, DefaultIgnoredFields...)
+}
+
+func TestCompareManifestsReportsFieldPaths(t *testing.T) {
+	t.Parallel()
+
+	rendered := `apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: camunda-platform-test-zeebe
+spec:
+  template:
+    metadata:
+      annotations:
+        checksum/config: changed
+        team: orchestration
+    spec:
+      containers:
+        - name: sidecar
+          image: busybox:1.37
+        - name: orchestration
+          image: camunda/camunda:8.10.0
+          env:
+            - name: A
+              value: "2"
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: camunda-platform-test-zeebe
+  labels:
+    app: camunda-platform
+    helm.sh/chart: camunda-platform-14.1.0
+data:
+  application.yaml: |
+    server:
+      port: 9090
+    # comment inside the block
+    client-secret: ${SECRET}
+  log4j2.xml: "<xml/>"
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: camunda-platform-test-zeebe
+`
+
+	diffs, err := CompareManifests(goldenManifest, rendered, ignoring(`**.annotations["checksum/*"]`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"ConfigMap/camunda-platform-test-zeebe data[\"application.yaml\"]: text changed\n    -   port: 8080\n    +   port: 9090",
+		`StatefulSet/camunda-platform-test-zeebe spec.template.spec.containers[name=orchestration].env[name=A].value: "1" -> "2"`,
+		`StatefulSet/camunda-platform-test-zeebe spec.template.spec.containers[name=sidecar].image: "busybox:1.36" -> "busybox:1.37"`,
+		"Service/camunda-platform-test-zeebe: object not in golden file",
+	}
+	if !slices.Equal(diffs, want) {
+		t.Errorf("CompareManifests() =\n%q\nwant\n%q", diffs, want)
+	}
+}
+
+func TestCompareManifestsIgnoresStrippedFields(t *testing.T) {
+	t.Parallel()
+
+	ignored := ignoring(`**.annotations["checksum/*"]`, `spec.template.spec.containers[*].image`)
+	stripped, err := StripIgnoredFields(goldenManifest, ignored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	diffs, err := CompareManifests(stripped, goldenManifest, ignored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 0 {
+		t.Errorf("diffs = %q, want none", diffs)
+	}
+
+	diffs, err = CompareManifests(stripped, goldenManifest, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(diffs) != 5 {
+		t.Errorf("without ignore rules the stripped fields show up: got %d diffs %q, want 5", len(diffs), diffs)
+	}
+}
+
+func TestStripIgnoredFieldsKeepsOtherLines(t *testing.T) {
+	t.Parallel()
+
+	stripped, err := StripIgnoredFields(goldenManifest, []string{
+		`**.labels["helm.sh/chart"]`,
+		`data["application.yaml"]`,
+		`**.annotations["checksum/*"]`,
+		`spec.template.spec.containers[name=sidecar]`,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `---
+# Source: camunda-platform/templates/orchestration/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: camunda-platform-test-zeebe
+  labels:
+    app: camunda-platform
+data:
+
+  log4j2.xml: "<xml/>"
+---
+# Source: camunda-platform/templates/orchestration/statefulset.yaml
+apiVersion: apps/v1
+kind: StatefulSet
+metadata:
+  name: camunda-platform-test-zeebe
+spec:
+  template:
+    metadata:
+      annotations:
+        team: orchestration
+    spec:
+      containers:
+        - name: orchestration
+          image: camunda/camunda:8.10.0
+          env:
+            - name: A
+              value: "1"
+`
+	if stripped != want {
+		t.Errorf("StripIgnoredFields() =\n%s\nwant\n%s", stripped, want)
+	}
+}
+
+func TestSplitFieldPath(t *testing.T) {
+	t.Parallel()
+
+	segs, err := splitFieldPath(`**.metadata.labels["helm.sh/chart"].containers[*].env[name=A]`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"**", "metadata", "labels", "helm.sh/chart", "containers", "[*]", "env", "[name=A]"}
+	if !slices.Equal(segs, want) {
+		t.Errorf("splitFieldPath() = %q, want %q", segs, want)
+	}
+
+	if _, err := splitFieldPath(`labels["unterminated`); err == nil {
+		t.Error("splitFieldPath() of an unterminated key: want an error")
+	}
+}
+
+func TestUnreferencedGoldenFiles(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	golden := filepath.Join(dir, "orchestration", "golden")
+	if err := os.MkdirAll(golden, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"service", "statefulset", "stale"} {
+		if err := os.WriteFile(filepath.Join(golden, name+".golden.yaml"), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(filepath.Join(dir, "orchestration", "goldenfiles_test.go"), []byte(`package orchestration
+
+var templateNames = []string{"service"}
+var goldenFileName = "statefulset"
+`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	unreferenced, err := UnreferencedGoldenFiles(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := []string{filepath.Join(golden, "stale.golden.yaml")}; !slices.Equal(unreferenced, want) {
+		t.Errorf("UnreferencedGoldenFiles() = %q, want %q", unreferenced, want)
+	}
+}
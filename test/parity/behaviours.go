@@ -0,0 +1,123 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parity
+
+import "maps"
+
+// Values that render each component's workload on its own.
+var (
+	orchestrationValues = map[string]string{}
+	connectorsValues    = map[string]string{"connectors.enabled": "true"}
+	identityValues      = map[string]string{"identity.enabled": "true"}
+	optimizeValues      = map[string]string{"identity.enabled": "true", "optimize.enabled": "true"}
+	consoleValues       = map[string]string{"identity.enabled": "true", "console.enabled": "true"}
+	webModelerValues    = map[string]string{
+		"identity.enabled":                    "true",
+		"webModeler.enabled":                  "true",
+		"webModeler.restapi.mail.fromAddress": "example@example.com",
+	}
+)
+
+// withValues returns base plus extra, leaving both untouched.
+func withValues(base, extra map[string]string) map[string]string {
+	out := make(map[string]string, len(base)+len(extra))
+	maps.Copy(out, base)
+	maps.Copy(out, extra)
+	return out
+}
+
+// Behaviours returns the catalogue, in report order.
+func Behaviours() []Behaviour {
+	return []Behaviour{
+		imagePullSecrets(),
+		customCABundle(),
+	}
+}
+
+func imagePullSecrets() Behaviour {
+	global := map[string]string{"global.image.pullSecrets[0].name": "SecretName"}
+	exp := Expectation{Expected: map[string]string{
+		"spec.template.spec.imagePullSecrets[0].name": "SecretName",
+	}}
+	check := func(name, template string, values map[string]string) Check {
+		return Check{
+			Name:     name,
+			Template: template,
+			Values:   withValues(values, global),
+			Versions: in(exp, Versions...),
+		}
+	}
+
+	console := check("console", "templates/console/deployment.yaml", consoleValues)
+	console.Versions = in(exp, "8.8", "8.9")
+	console.Gaps = map[string]string{"8.10": "no standalone Console deployment"}
+
+	webapp := check("web-modeler-webapp", "templates/web-modeler/deployment-webapp.yaml", webModelerValues)
+	webapp.Versions = in(exp, "8.8")
+	webapp.Gaps = map[string]string{
+		"8.9":  "no Web Modeler webapp deployment",
+		"8.10": "no Web Modeler webapp deployment",
+	}
+
+	return Behaviour{
+		Name:        "image-pull-secrets",
+		Description: "global.image.pullSecrets reaches the pod spec of every component",
+		Checks: []Check{
+			check("orchestration", "templates/orchestration/statefulset.yaml", orchestrationValues),
+			check("connectors", "templates/connectors/deployment.yaml", connectorsValues),
+			check("identity", "templates/identity/deployment.yaml", identityValues),
+			check("optimize", "templates/optimize/deployment.yaml", optimizeValues),
+			check("web-modeler-restapi", "templates/web-modeler/deployment-restapi.yaml", webModelerValues),
+			check("web-modeler-websockets", "templates/web-modeler/deployment-websockets.yaml", webModelerValues),
+			webapp,
+			console,
+		},
+	}
+}
+
+func customCABundle() Behaviour {
+	caBundle := map[string]string{
+		"global.tls.caBundle.secret.existingSecret":    "my-ca-bundle",
+		"global.tls.caBundle.secret.existingSecretKey": "ca.crt",
+	}
+	// The PEM bundle is mounted for SSL_CERT_FILE, and the init container's
+	// combined JKS truststore is mounted where the JVM flags point.
+	exp := Expectation{Expected: map[string]string{
+		"spec.template.spec.volumes[?(@.name=='ca-bundle')].secret.secretName":                       "my-ca-bundle",
+		"spec.template.spec.containers[0].volumeMounts[?(@.name=='ca-bundle')].mountPath":            "/etc/camunda/tls",
+		"spec.template.spec.containers[0].volumeMounts[?(@.name=='ca-bundle-truststore')].mountPath": "/var/camunda/tls-truststore",
+		"spec.template.spec.containers[0].env[?(@.name=='SSL_CERT_FILE')].value":                     "/etc/camunda/tls/ca.crt",
+	}}
+	check := func(name, template string, values map[string]string) Check {
+		return Check{
+			Name:     name,
+			Template: template,
+			Values:   withValues(values, caBundle),
+			Versions: in(exp, Versions...),
+		}
+	}
+
+	return Behaviour{
+		Name:        "custom-ca-java-components",
+		Description: "global.tls.caBundle is mounted into every Java component with the chart-built truststore",
+		Checks: []Check{
+			check("orchestration", "templates/orchestration/statefulset.yaml", orchestrationValues),
+			check("connectors", "templates/connectors/deployment.yaml", connectorsValues),
+			check("identity", "templates/identity/deployment.yaml", identityValues),
+			check("optimize", "templates/optimize/deployment.yaml", optimizeValues),
+			check("web-modeler-restapi", "templates/web-modeler/deployment-restapi.yaml", webModelerValues),
+		},
+	}
+}
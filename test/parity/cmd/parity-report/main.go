@@ -0,0 +1,58 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Command parity-report renders the chart behaviour parity matrix from the
+// <version>.json results the charts' test/unit/parity packages write to
+// $PARITY_RESULTS_DIR. With --versions it prints the active chart versions,
+// one per line, for scripts that run each chart.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"test/parity"
+)
+
+func main() {
+	results := flag.String("results", "", "directory holding the charts' <version>.json parity results")
+	out := flag.String("out", "", "write the markdown report here instead of stdout")
+	versions := flag.Bool("versions", false, "print the active chart versions and exit")
+	flag.Parse()
+
+	if *versions {
+		fmt.Println(strings.Join(parity.Versions, "\n"))
+		return
+	}
+	if *results == "" {
+		fmt.Fprintln(os.Stderr, "--results is required")
+		os.Exit(2)
+	}
+	runs, err := parity.ReadResults(*results)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	report := parity.Report(parity.Behaviours(), runs)
+	if *out == "" {
+		fmt.Print(report)
+		return
+	}
+	if err := os.WriteFile(*out, []byte(report), 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,3 @@
+module test/parity
+
+go 1.26.0
@@ -0,0 +1,178 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package parity declares chart behaviours once and runs them against every
+// active chart version.
+//
+// The unit suites of charts/camunda-platform-8.x are copies that drift apart.
+// A Behaviour ("image pull secrets propagate", "custom CA mounted into every
+// Java component") is split into Checks, one per component; each Check holds
+// per-version expectations in the declarative testhelpers form (rendered
+// template, --set values, jsonpath Expected/Unexpected). Each chart runs the
+// catalogue from test/unit/parity through its own testhelpers.RunTestCasesE,
+// and Report renders which versions pass, fail or lack a behaviour.
+//
+// The module is stdlib-only so every chart module can require it through a
+// local replace without touching its go.sum.
+package parity
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// Versions are the active chart versions the catalogue is declared for, in
+// report column order. A chart runs the catalogue from its test/unit/parity
+// package; adding a version here requires one there.
+var Versions = []string{"8.8", "8.9", "8.10"}
+
+// ResultsDirEnv names the directory Run writes <version>.json results to.
+// Unset, results are not written.
+const ResultsDirEnv = "PARITY_RESULTS_DIR"
+
+// Behaviour is one chart capability checked across versions.
+type Behaviour struct {
+	Name        string // stable identifier, e.g. "image-pull-secrets"
+	Description string
+	Checks      []Check
+}
+
+// Check is one component's part of a behaviour. Template and Values are the
+// defaults for every version; Versions holds what each version must render.
+// A version that lacks the check is listed in Gaps with the reason instead.
+type Check struct {
+	Name     string
+	Template string
+	Values   map[string]string
+	Versions map[string]Expectation
+	Gaps     map[string]string
+}
+
+// Expectation is what one version must render for a check. Template replaces
+// the check's template when set; Values are merged over the check's values.
+// Expected and Unexpected use testhelpers.TestCase path semantics: every
+// Expected path must resolve to exactly one scalar, every Unexpected path to
+// nothing.
+type Expectation struct {
+	Template   string
+	Values     map[string]string
+	Expected   map[string]string
+	Unexpected []string
+}
+
+// Case is a check resolved for one version, ready to become a
+// testhelpers.TestCase.
+type Case struct {
+	Behaviour  string
+	Check      string
+	Template   string
+	Values     map[string]string
+	Expected   map[string]string
+	Unexpected []string
+}
+
+// Resolve returns the check's case for version, or false when the version
+// declares no expectation for it.
+func (c Check) Resolve(behaviour, version string) (Case, bool) {
+	exp, ok := c.Versions[version]
+	if !ok {
+		return Case{}, false
+	}
+	tc := Case{
+		Behaviour:  behaviour,
+		Check:      c.Name,
+		Template:   c.Template,
+		Values:     maps.Clone(c.Values),
+		Expected:   maps.Clone(exp.Expected),
+		Unexpected: append([]string(nil), exp.Unexpected...),
+	}
+	if exp.Template != "" {
+		tc.Template = exp.Template
+	}
+	if tc.Values == nil {
+		tc.Values = map[string]string{}
+	}
+	maps.Copy(tc.Values, exp.Values)
+	return tc, true
+}
+
+// in declares the same expectation for several versions.
+func in(exp Expectation, versions ...string) map[string]Expectation {
+	out := make(map[string]Expectation, len(versions))
+	for _, v := range versions {
+		out[v] = exp
+	}
+	return out
+}
+
+// VersionFromChartPath returns the chart version of a charts/camunda-platform-<version>
+// directory, so a copied chart reports under its own version.
+func VersionFromChartPath(chartPath string) (string, error) {
+	abs, err := filepath.Abs(chartPath)
+	if err != nil {
+		return "", err
+	}
+	version, ok := strings.CutPrefix(filepath.Base(abs), "camunda-platform-")
+	if !ok {
+		return "", fmt.Errorf("%s is not a camunda-platform chart directory", abs)
+	}
+	return version, nil
+}
+
+// Run runs every catalogue check for version as a subtest
+// (<behaviour>/<check>) through run, which renders and asserts the case. A
+// gap is skipped with its reason. When ResultsDirEnv is set the outcomes are
+// written to <dir>/<version>.json for Report.
+func Run(t *testing.T, version string, run func(t *testing.T, c Case)) {
+	t.Helper()
+	var results []Result
+	for _, b := range Behaviours() {
+		t.Run(b.Name, func(t *testing.T) {
+			for _, check := range b.Checks {
+				t.Run(check.Name, func(t *testing.T) {
+					result := Result{Behaviour: b.Name, Check: check.Name, Status: StatusPass}
+					// Deferred so a t.Fatal or t.Skip below is still recorded.
+					defer func() {
+						if t.Failed() {
+							result.Status = StatusFail
+						}
+						results = append(results, result)
+					}()
+					c, ok := check.Resolve(b.Name, version)
+					if !ok {
+						reason, gap := check.Gaps[version]
+						if !gap {
+							t.Fatalf("%s/%s declares neither an expectation nor a gap for %s", b.Name, check.Name, version)
+						}
+						result.Status, result.Reason = StatusMissing, reason
+						t.Skipf("%s lacks %s/%s: %s", version, b.Name, check.Name, reason)
+					}
+					run(t, c)
+				})
+			}
+		})
+	}
+
+	dir := os.Getenv(ResultsDirEnv)
+	if dir == "" {
+		return
+	}
+	if err := WriteResults(dir, VersionResults{Version: version, Results: results}); err != nil {
+		t.Fatalf("write parity results: %v", err)
+	}
+}
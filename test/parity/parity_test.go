@@ -0,0 +1,170 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parity
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+)
+
+// TestCatalogueIsComplete keeps the catalogue honest: every check must either
+// expect something or name a gap on every active version, and only on active
+// versions.
+func TestCatalogueIsComplete(t *testing.T) {
+	seen := map[string]bool{}
+	for _, b := range Behaviours() {
+		if b.Name == "" || b.Description == "" || len(b.Checks) == 0 {
+			t.Errorf("behaviour %q needs a name, a description and checks", b.Name)
+		}
+		for _, c := range b.Checks {
+			id := b.Name + "/" + c.Name
+			if seen[id] {
+				t.Errorf("%s is declared twice", id)
+			}
+			seen[id] = true
+			for _, v := range Versions {
+				exp, declared := c.Versions[v]
+				_, gap := c.Gaps[v]
+				switch {
+				case declared && gap:
+					t.Errorf("%s: %s has both an expectation and a gap", id, v)
+				case !declared && !gap:
+					t.Errorf("%s: %s has neither an expectation nor a gap", id, v)
+				case declared && len(exp.Expected) == 0 && len(exp.Unexpected) == 0:
+					t.Errorf("%s: %s expectation asserts nothing", id, v)
+				case declared && c.Template == "" && exp.Template == "":
+					t.Errorf("%s: %s has no template", id, v)
+				case gap && c.Gaps[v] == "":
+					t.Errorf("%s: %s gap needs a reason", id, v)
+				}
+			}
+			for v := range c.Versions {
+				if !slices.Contains(Versions, v) {
+					t.Errorf("%s: expectation for inactive version %s", id, v)
+				}
+			}
+			for v := range c.Gaps {
+				if !slices.Contains(Versions, v) {
+					t.Errorf("%s: gap for inactive version %s", id, v)
+				}
+			}
+		}
+	}
+}
+
+// TestEveryVersionRunsTheCatalogue fails when an active version has no chart
+// package running the catalogue.
+func TestEveryVersionRunsTheCatalogue(t *testing.T) {
+	for _, v := range Versions {
+		path := filepath.Join("..", "..", "charts", "camunda-platform-"+v, "test", "unit", "parity", "parity_test.go")
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("chart %s does not run the parity catalogue: %v", v, err)
+		}
+	}
+}
+
+func TestResolveMergesVersionValues(t *testing.T) {
+	check := Check{
+		Name:     "c",
+		Template: "templates/a.yaml",
+		Values:   map[string]string{"a": "1", "b": "1"},
+		Versions: map[string]Expectation{
+			"8.9":  {Expected: map[string]string{"p": "v"}},
+			"8.10": {Template: "templates/b.yaml", Values: map[string]string{"b": "2"}, Expected: map[string]string{"p": "v"}},
+		},
+	}
+
+	got, ok := check.Resolve("beh", "8.10")
+	if !ok {
+		t.Fatal("8.10 should resolve")
+	}
+	if got.Template != "templates/b.yaml" || got.Values["a"] != "1" || got.Values["b"] != "2" {
+		t.Errorf("8.10 case = %+v", got)
+	}
+	got.Values["a"] = "changed"
+	if check.Values["a"] != "1" {
+		t.Error("Resolve must not share the check's values")
+	}
+
+	if got, _ := check.Resolve("beh", "8.9"); got.Template != "templates/a.yaml" || got.Values["b"] != "1" {
+		t.Errorf("8.9 case = %+v", got)
+	}
+	if _, ok := check.Resolve("beh", "8.8"); ok {
+		t.Error("8.8 declares nothing and should not resolve")
+	}
+}
+
+func TestVersionFromChartPath(t *testing.T) {
+	got, err := VersionFromChartPath(filepath.Join("charts", "camunda-platform-8.10") + string(filepath.Separator))
+	if err != nil || got != "8.10" {
+		t.Errorf("got %q, %v", got, err)
+	}
+	if _, err := VersionFromChartPath("charts/other"); err == nil {
+		t.Error("expected an error for a non-chart directory")
+	}
+}
+
+func TestReportMarksGapsFailuresAndMissingRuns(t *testing.T) {
+	behaviours := []Behaviour{{
+		Name:        "pull-secrets",
+		Description: "pull secrets propagate",
+		Checks: []Check{
+			{Name: "orchestration", Versions: in(Expectation{}, "8.8", "8.9", "8.10")},
+			{Name: "console", Versions: in(Expectation{}, "8.8", "8.9"), Gaps: map[string]string{"8.10": "no console"}},
+		},
+	}}
+	runs := []VersionResults{
+		{Version: "8.8", Results: []Result{
+			{Behaviour: "pull-secrets", Check: "orchestration", Status: StatusPass},
+			{Behaviour: "pull-secrets", Check: "console", Status: StatusFail},
+		}},
+		{Version: "8.10", Results: []Result{
+			{Behaviour: "pull-secrets", Check: "orchestration", Status: StatusPass},
+		}},
+	}
+
+	got := Report(behaviours, runs)
+	for _, want := range []string{
+		"| Behaviour | Check | 8.8 | 8.9 | 8.10 |",
+		"| `pull-secrets` | orchestration | ✅ | not run | ✅ |",
+		"| `pull-secrets` | console | ❌ fail | not run | ➖ missing |",
+		"- 8.10 lacks `pull-secrets/console`: no console",
+		"**1 check(s) failed.**",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("report lacks %q:\n%s", want, got)
+		}
+	}
+}
+
+func TestResultsRoundTrip(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "results")
+	want := VersionResults{Version: "8.9", Results: []Result{
+		{Behaviour: "b", Check: "c", Status: StatusMissing, Reason: "r"},
+	}}
+	if err := WriteResults(dir, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := ReadResults(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].Version != "8.9" || got[0].Results[0] != want.Results[0] {
+		t.Errorf("got %+v", got)
+	}
+}
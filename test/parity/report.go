@@ -0,0 +1,153 @@
+// Copyright 2026 Camunda Services GmbH
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//      http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package parity
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Status is the outcome of one check on one chart version.
+type Status string
+
+const (
+	StatusPass    Status = "pass"
+	StatusFail    Status = "fail"
+	StatusMissing Status = "missing" // the version declares a gap instead of an expectation
+	StatusNotRun  Status = "not run" // no result recorded (report only)
+)
+
+// Result is one check's outcome.
+type Result struct {
+	Behaviour string `json:"behaviour"`
+	Check     string `json:"check"`
+	Status    Status `json:"status"`
+	Reason    string `json:"reason,omitempty"`
+}
+
+// VersionResults are the outcomes of one chart version's run; Run writes them
+// to <dir>/<version>.json.
+type VersionResults struct {
+	Version string   `json:"version"`
+	Results []Result `json:"results"`
+}
+
+// WriteResults writes r to <dir>/<version>.json, creating dir.
+func WriteResults(dir string, r VersionResults) error {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return fmt.Errorf("create results dir: %w", err)
+	}
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(dir, r.Version+".json")
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("write %s: %w", path, err)
+	}
+	return nil
+}
+
+// ReadResults reads every <version>.json in dir. A missing dir reads as no
+// results.
+func ReadResults(dir string) ([]VersionResults, error) {
+	paths, err := filepath.Glob(filepath.Join(dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var out []VersionResults
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read %s: %w", path, err)
+		}
+		var r VersionResults
+		if err := json.Unmarshal(data, &r); err != nil {
+			return nil, fmt.Errorf("parse %s: %w", path, err)
+		}
+		out = append(out, r)
+	}
+	return out, nil
+}
+
+// Report renders the parity matrix for the catalogue as markdown: one row per
+// check, one column per version in Versions, and a list of the gaps (the
+// versions lacking a behaviour) with their reasons. Gaps come from the
+// catalogue, so they show even for versions with no recorded run; a declared
+// check without a result is "not run".
+func Report(behaviours []Behaviour, runs []VersionResults) string {
+	recorded := map[string]map[[2]string]Status{}
+	for _, run := range runs {
+		byCheck := map[[2]string]Status{}
+		for _, r := range run.Results {
+			byCheck[[2]string{r.Behaviour, r.Check}] = r.Status
+		}
+		recorded[run.Version] = byCheck
+	}
+
+	var b strings.Builder
+	b.WriteString("# Chart behaviour parity\n\n")
+	b.WriteString("| Behaviour | Check | " + strings.Join(Versions, " | ") + " |\n")
+	b.WriteString("|---|---|" + strings.Repeat("---|", len(Versions)) + "\n")
+	var gaps []string
+	failed := 0
+	for _, behaviour := range behaviours {
+		for _, check := range behaviour.Checks {
+			cells := make([]string, len(Versions))
+			for i, version := range Versions {
+				status := StatusNotRun
+				if reason, ok := check.Gaps[version]; ok {
+					status = StatusMissing
+					gaps = append(gaps, fmt.Sprintf("- %s lacks `%s/%s`: %s", version, behaviour.Name, check.Name, reason))
+				} else if s, ok := recorded[version][[2]string{behaviour.Name, check.Name}]; ok {
+					status = s
+				}
+				if status == StatusFail {
+					failed++
+				}
+				cells[i] = statusCell(status)
+			}
+			fmt.Fprintf(&b, "| `%s` | %s | %s |\n", behaviour.Name, check.Name, strings.Join(cells, " | "))
+		}
+	}
+
+	b.WriteString("\n")
+	for _, behaviour := range behaviours {
+		fmt.Fprintf(&b, "- `%s`: %s\n", behaviour.Name, behaviour.Description)
+	}
+	if len(gaps) > 0 {
+		b.WriteString("\n## Gaps\n\n" + strings.Join(gaps, "\n") + "\n")
+	}
+	if failed > 0 {
+		fmt.Fprintf(&b, "\n**%d check(s) failed.** Run `go test ./test/unit/parity/` in the failing chart for details.\n", failed)
+	}
+	return b.String()
+}
+
+func statusCell(s Status) string {
+	switch s {
+	case StatusPass:
+		return "✅"
+	case StatusFail:
+		return "❌ fail"
+	case StatusMissing:
+		return "➖ missing"
+	default:
+		return "not run"
+	}
+}